@@ -0,0 +1,270 @@
+// Package app runs the components of an application, e.g. servers, workers,
+// schedulers and NATS consumers, until the application is interrupted or one
+// of them fails, then shuts them all down gracefully.
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/joshjon/kit/log"
+)
+
+const defaultShutdownTimeout = 30 * time.Second
+
+// DefaultSignals are the signals that shut down an App by default.
+var DefaultSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// StartFunc starts a component and blocks until it is stopped, e.g.
+// server.Server.Start.
+type StartFunc func(ctx context.Context) error
+
+// StopFunc stops a component started by a StartFunc, e.g. server.Server.Stop.
+// It should return once in-flight work drained or ctx is done.
+type StopFunc func(ctx context.Context) error
+
+// Option optionally configures an App.
+type Option func(opts *options)
+
+// WithLogger sets the Logger used to log the lifecycle of components.
+// Defaults to log.NewLogger.
+func WithLogger(logger log.Logger) Option {
+	return func(opts *options) {
+		opts.logger = logger
+	}
+}
+
+// WithShutdownTimeout sets how long components are given to stop once the
+// App shuts down. Defaults to 30s.
+func WithShutdownTimeout(d time.Duration) Option {
+	return func(opts *options) {
+		opts.shutdownTimeout = d
+	}
+}
+
+// WithSignals sets the signals that shut down the App. Defaults to
+// DefaultSignals. Without signals only the context passed to Run shuts down
+// the App.
+func WithSignals(signals ...os.Signal) Option {
+	return func(opts *options) {
+		opts.signals = signals
+	}
+}
+
+type options struct {
+	logger          log.Logger
+	shutdownTimeout time.Duration
+	signals         []os.Signal
+}
+
+// ComponentOption optionally configures a component of an App.
+type ComponentOption func(c *component)
+
+// WithReady sets a func that blocks until the component is ready, e.g. a
+// server is healthy. Components added after it are only started once it
+// returns, and the App fails to start if it returns an error.
+func WithReady(ready func(ctx context.Context) error) ComponentOption {
+	return func(c *component) {
+		c.ready = ready
+	}
+}
+
+type component struct {
+	name  string
+	start StartFunc
+	stop  StopFunc
+	ready func(ctx context.Context) error
+}
+
+// App runs components.
+type App struct {
+	opts       options
+	components []component
+}
+
+// New creates a new App.
+func New(opts ...Option) *App {
+	options := options{
+		logger:          log.NewLogger(),
+		shutdownTimeout: defaultShutdownTimeout,
+		signals:         DefaultSignals,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &App{opts: options}
+}
+
+// Add adds a component started with start and stopped with stop, which may be
+// nil if the component stops once the context passed to start is done.
+// Components are started in the order they are added.
+//
+// Example:
+//
+//	a.Add("server", func(context.Context) error { return srv.Start() }, srv.Stop,
+//		app.WithReady(func(context.Context) error { return srv.WaitHealthy(15, time.Second) }))
+func (a *App) Add(name string, start StartFunc, stop StopFunc, opts ...ComponentOption) {
+	c := component{
+		name:  name,
+		start: start,
+		stop:  stop,
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	a.components = append(a.components, c)
+}
+
+// AddRunner adds a component that runs until its context is done, e.g.
+// worker.Worker.Run.
+func (a *App) AddRunner(name string, run func(ctx context.Context) error, opts ...ComponentOption) {
+	a.Add(name, run, nil, opts...)
+}
+
+type result struct {
+	name string
+	err  error
+}
+
+// Run starts the components in order and blocks until ctx is done, a signal
+// is received or a component returns, then stops all components in parallel,
+// giving them the shutdown timeout to return. A component returning without
+// an error, e.g. a one-off task, shuts down the App without failing it. The
+// errors of components that failed to start, run or stop are returned.
+func (a *App) Run(ctx context.Context) error {
+	if len(a.components) == 0 {
+		return errors.New("app: no components to run")
+	}
+	if len(a.opts.signals) > 0 {
+		var stop context.CancelFunc
+		ctx, stop = signal.NotifyContext(ctx, a.opts.signals...)
+		defer stop()
+	}
+	logger := a.opts.logger
+
+	// Components run until shutdown rather than until ctx is done, so they
+	// are stopped in parallel with their StopFunc.
+	runCtx, cancelRun := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelRun()
+
+	results := make(chan result, len(a.components))
+	var errs []error
+	started, running := 0, 0
+	failed := false
+	for _, c := range a.components {
+		logger.Info("starting component", "component", c.name)
+		go func() {
+			err := c.start(runCtx)
+			if err != nil {
+				err = fmt.Errorf("%s: %w", c.name, err)
+			}
+			results <- result{name: c.name, err: err}
+		}()
+		started++
+		running++
+		if c.ready == nil {
+			continue
+		}
+		if res, err := a.waitReady(ctx, c, results); err != nil {
+			if res != nil {
+				running--
+			}
+			errs = append(errs, err)
+			failed = true
+			break
+		}
+		logger.Info("component ready", "component", c.name)
+	}
+
+	if !failed {
+		select {
+		case <-ctx.Done():
+			logger.Info("shutting down", "cause", context.Cause(ctx))
+		case res := <-results:
+			running--
+			if res.err != nil {
+				logger.Error("component failed, shutting down", "component", res.name, "error", res.err)
+				errs = append(errs, res.err)
+			} else {
+				logger.Info("component returned, shutting down", "component", res.name)
+			}
+		}
+	}
+
+	errs = append(errs, a.shutdown(ctx, started, cancelRun, results, running)...)
+	return errors.Join(errs...)
+}
+
+// waitReady waits for c to be ready, failing if a started component returns
+// first, in which case its result is returned.
+func (a *App) waitReady(ctx context.Context, c component, results <-chan result) (*result, error) {
+	readyCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	ready := make(chan error, 1)
+	go func() {
+		ready <- c.ready(readyCtx)
+	}()
+
+	select {
+	case err := <-ready:
+		if err != nil {
+			return nil, fmt.Errorf("%s not ready: %w", c.name, err)
+		}
+		return nil, nil
+	case res := <-results:
+		if res.err != nil {
+			return &res, res.err
+		}
+		return &res, fmt.Errorf("%s returned before %s was ready", res.name, c.name)
+	}
+}
+
+// shutdown stops the first started components and waits for the running ones
+// to return.
+func (a *App) shutdown(ctx context.Context, started int, cancelRun context.CancelFunc, results <-chan result, running int) []error {
+	stopCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), a.opts.shutdownTimeout)
+	defer cancel()
+
+	stopErrs := make(chan error, started)
+	for _, c := range a.components[:started] {
+		go func() {
+			var err error
+			if c.stop != nil {
+				if err = c.stop(stopCtx); err != nil {
+					err = fmt.Errorf("stop %s: %w", c.name, err)
+				}
+			}
+			stopErrs <- err
+		}()
+	}
+	cancelRun()
+
+	var errs []error
+	for range started {
+		select {
+		case err := <-stopErrs:
+			if err != nil {
+				errs = append(errs, err)
+			}
+		case <-stopCtx.Done():
+			return append(errs, fmt.Errorf("shutdown: %w", stopCtx.Err()))
+		}
+	}
+	for ; running > 0; running-- {
+		select {
+		case res := <-results:
+			if res.err != nil {
+				errs = append(errs, res.err)
+			}
+		case <-stopCtx.Done():
+			return append(errs, fmt.Errorf("shutdown: %w", stopCtx.Err()))
+		}
+	}
+	a.opts.logger.Info("shut down")
+	return errs
+}
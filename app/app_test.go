@@ -0,0 +1,177 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/log"
+)
+
+func newTestApp(opts ...Option) *App {
+	return New(append([]Option{WithLogger(log.NewLogger(log.WithNop())), WithSignals()}, opts...)...)
+}
+
+type recorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *recorder) record(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *recorder) get() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.events...)
+}
+
+// blockingComponent returns start and stop funcs of a component that runs
+// until stopped.
+func blockingComponent(name string, rec *recorder) (StartFunc, StopFunc) {
+	stopped := make(chan struct{})
+	start := func(context.Context) error {
+		rec.record("start " + name)
+		<-stopped
+		return nil
+	}
+	stop := func(context.Context) error {
+		rec.record("stop " + name)
+		close(stopped)
+		return nil
+	}
+	return start, stop
+}
+
+func TestApp_Run_ctxDone(t *testing.T) {
+	rec := &recorder{}
+	a := newTestApp()
+
+	ready := make(chan struct{})
+	started := make(chan struct{})
+	stopped := make(chan struct{})
+	a.Add("server", func(context.Context) error {
+		rec.record("start server")
+		close(started)
+		<-stopped
+		return nil
+	}, func(context.Context) error {
+		rec.record("stop server")
+		close(stopped)
+		return nil
+	}, WithReady(func(context.Context) error {
+		<-started
+		rec.record("ready server")
+		close(ready)
+		return nil
+	}))
+	a.AddRunner("worker", func(ctx context.Context) error {
+		rec.record("start worker")
+		<-ctx.Done()
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() { errs <- a.Run(ctx) }()
+
+	<-ready
+	require.Eventually(t, func() bool { return len(rec.get()) == 3 }, time.Second, time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-errs:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for Run to return")
+	}
+	events := rec.get()
+	assert.Equal(t, []string{"start server", "ready server", "start worker"}, events[:3])
+	assert.ElementsMatch(t, []string{"stop server"}, events[3:])
+}
+
+func TestApp_Run_componentFails(t *testing.T) {
+	rec := &recorder{}
+	a := newTestApp()
+
+	start, stop := blockingComponent("server", rec)
+	a.Add("server", start, stop)
+	wantErr := errors.New("boom")
+	a.AddRunner("worker", func(context.Context) error {
+		return wantErr
+	})
+
+	err := a.Run(context.Background())
+	require.ErrorIs(t, err, wantErr)
+	assert.ErrorContains(t, err, "worker: boom")
+	assert.Contains(t, rec.get(), "stop server")
+}
+
+func TestApp_Run_componentReturns(t *testing.T) {
+	a := newTestApp()
+	a.AddRunner("task", func(context.Context) error {
+		return nil
+	})
+	require.NoError(t, a.Run(context.Background()))
+}
+
+func TestApp_Run_notReady(t *testing.T) {
+	rec := &recorder{}
+	a := newTestApp()
+
+	start, stop := blockingComponent("server", rec)
+	wantErr := errors.New("unhealthy")
+	a.Add("server", start, stop, WithReady(func(context.Context) error {
+		return wantErr
+	}))
+	a.AddRunner("worker", func(context.Context) error {
+		rec.record("start worker")
+		return nil
+	})
+
+	err := a.Run(context.Background())
+	require.ErrorIs(t, err, wantErr)
+	assert.NotContains(t, rec.get(), "start worker")
+	assert.Contains(t, rec.get(), "stop server")
+}
+
+func TestApp_Run_failsBeforeReady(t *testing.T) {
+	a := newTestApp()
+	wantErr := errors.New("address in use")
+	a.Add("server", func(context.Context) error {
+		return wantErr
+	}, nil, WithReady(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}))
+
+	err := a.Run(context.Background())
+	require.ErrorIs(t, err, wantErr)
+}
+
+func TestApp_Run_shutdownTimeout(t *testing.T) {
+	a := newTestApp(WithShutdownTimeout(10 * time.Millisecond))
+	a.Add("stuck", func(context.Context) error {
+		select {}
+	}, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := a.Run(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestApp_Run_noComponents(t *testing.T) {
+	assert.Error(t, newTestApp().Run(context.Background()))
+}
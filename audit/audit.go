@@ -0,0 +1,123 @@
+// Package audit records who did what to which resource, e.g. to satisfy
+// compliance requirements. Events are written to a Sink, such as a
+// PostgresSink, LogSink or NATSSink, either explicitly or by Middleware
+// capturing mutating requests.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/joshjon/kit/id"
+	"github.com/joshjon/kit/jwt"
+)
+
+// Outcome is the outcome of an audited action.
+type Outcome string
+
+const (
+	OutcomeSuccess Outcome = "success"
+	OutcomeFailure Outcome = "failure"
+)
+
+// Actor is who performed an action.
+type Actor struct {
+	ID    string `json:"id"`
+	Email string `json:"email,omitempty"`
+}
+
+// Resource is what an action was performed on.
+type Resource struct {
+	Type string `json:"type"`
+	ID   string `json:"id,omitempty"`
+}
+
+// Event is an audit event of an Actor performing an action on a Resource.
+type Event struct {
+	ID       string    `json:"id"`
+	Time     time.Time `json:"time"`
+	Actor    Actor     `json:"actor"`
+	Action   string    `json:"action"`
+	Resource Resource  `json:"resource"`
+	Outcome  Outcome   `json:"outcome"`
+	// Before and After are the states of the resource before and after the
+	// action, encoded as JSON, and Changes the differences between them. See
+	// SetStates.
+	Before   json.RawMessage   `json:"before,omitempty"`
+	After    json.RawMessage   `json:"after,omitempty"`
+	Changes  []Change          `json:"changes,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// New creates a successful Event of actor performing action on resource.
+func New(actor Actor, action string, resource Resource) Event {
+	return Event{
+		ID:       id.NewUUIDv7().String(),
+		Time:     time.Now().UTC(),
+		Actor:    actor,
+		Action:   action,
+		Resource: resource,
+		Outcome:  OutcomeSuccess,
+	}
+}
+
+// SetStates sets the states of the resource before and after the action,
+// either of which may be nil, e.g. before a resource is created, and the
+// changes between them.
+func (e *Event) SetStates(before any, after any) error {
+	var err error
+	if e.Before, err = marshalState(before); err != nil {
+		return fmt.Errorf("marshal before: %w", err)
+	}
+	if e.After, err = marshalState(after); err != nil {
+		return fmt.Errorf("marshal after: %w", err)
+	}
+	e.Changes, err = diffJSON(e.Before, e.After)
+	return err
+}
+
+func marshalState(v any) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if raw, ok := v.(json.RawMessage); ok {
+		return raw, nil
+	}
+	return json.Marshal(v)
+}
+
+// Sink writes audit events.
+type Sink interface {
+	Write(ctx context.Context, events ...Event) error
+}
+
+// MultiSink writes events to every sink, returning their errors joined.
+type MultiSink []Sink
+
+var _ Sink = MultiSink(nil)
+
+func (s MultiSink) Write(ctx context.Context, events ...Event) error {
+	var errs []error
+	for _, sink := range s {
+		if err := sink.Write(ctx, events...); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ActorFromContext returns the Actor authenticated by
+// jwt.ValidateMiddleware, or false if the request is not authenticated.
+func ActorFromContext(c echo.Context) (Actor, bool) {
+	userID, err := jwt.AuthUserIDFromContext(c)
+	if err != nil || userID == "" {
+		return Actor{}, false
+	}
+	email, _ := jwt.EmailFromContext(c)
+	return Actor{ID: userID, Email: email}, true
+}
@@ -0,0 +1,111 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type project struct {
+	Name    string            `json:"name"`
+	Tags    []string          `json:"tags"`
+	Owner   *owner            `json:"owner,omitempty"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	Version int               `json:"version"`
+}
+
+type owner struct {
+	ID   string `json:"id"`
+	Team string `json:"team"`
+}
+
+func TestDiff(t *testing.T) {
+	before := project{Name: "a", Tags: []string{"x"}, Owner: &owner{ID: "1", Team: "core"}, Version: 1}
+	after := project{Name: "b", Tags: []string{"x"}, Owner: &owner{ID: "1", Team: "infra"}, Labels: map[string]string{"env": "prod"}, Version: 1}
+
+	tests := []struct {
+		name   string
+		before any
+		after  any
+		want   []Change
+	}{
+		{
+			name:   "update",
+			before: before,
+			after:  after,
+			want: []Change{
+				{Field: "labels.env", After: json.RawMessage(`"prod"`)},
+				{Field: "name", Before: json.RawMessage(`"a"`), After: json.RawMessage(`"b"`)},
+				{Field: "owner.team", Before: json.RawMessage(`"core"`), After: json.RawMessage(`"infra"`)},
+			},
+		},
+		{
+			name:   "create",
+			before: nil,
+			after:  owner{ID: "1", Team: "core"},
+			want: []Change{
+				{Field: "id", After: json.RawMessage(`"1"`)},
+				{Field: "team", After: json.RawMessage(`"core"`)},
+			},
+		},
+		{
+			name:   "delete",
+			before: owner{ID: "1"},
+			after:  nil,
+			want: []Change{
+				{Field: "id", Before: json.RawMessage(`"1"`)},
+				{Field: "team", Before: json.RawMessage(`""`)},
+			},
+		},
+		{
+			name:   "unchanged",
+			before: before,
+			after:  before,
+			want:   nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Diff(tt.before, tt.after)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestEvent_SetStates(t *testing.T) {
+	event := New(Actor{ID: "user1"}, "update", Resource{Type: "project", ID: "1"})
+	assert.NotEmpty(t, event.ID)
+	assert.Equal(t, OutcomeSuccess, event.Outcome)
+
+	require.NoError(t, event.SetStates(owner{ID: "1", Team: "core"}, json.RawMessage(`{"id":"1","team":"infra"}`)))
+	assert.JSONEq(t, `{"id":"1","team":"core"}`, string(event.Before))
+	assert.JSONEq(t, `{"id":"1","team":"infra"}`, string(event.After))
+	assert.Equal(t, []Change{{Field: "team", Before: json.RawMessage(`"core"`), After: json.RawMessage(`"infra"`)}}, event.Changes)
+}
+
+type sinkFunc func(ctx context.Context, events ...Event) error
+
+func (f sinkFunc) Write(ctx context.Context, events ...Event) error {
+	return f(ctx, events...)
+}
+
+func TestMultiSink(t *testing.T) {
+	var written []Event
+	wantErr := errors.New("boom")
+	sink := MultiSink{
+		sinkFunc(func(_ context.Context, events ...Event) error {
+			written = append(written, events...)
+			return nil
+		}),
+		sinkFunc(func(context.Context, ...Event) error { return wantErr }),
+	}
+
+	event := New(Actor{ID: "user1"}, "delete", Resource{Type: "project"})
+	assert.ErrorIs(t, sink.Write(context.Background(), event), wantErr)
+	assert.Equal(t, []Event{event}, written)
+}
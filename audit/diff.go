@@ -0,0 +1,104 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+)
+
+// Change is a field that differs between the states of a resource before and
+// after an action. Fields of nested objects are separated by dots, e.g.
+// address.city, and arrays are compared as a whole.
+type Change struct {
+	Field  string          `json:"field"`
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+// Diff returns the changes between before and after, encoded as JSON.
+func Diff(before any, after any) ([]Change, error) {
+	b, err := marshalState(before)
+	if err != nil {
+		return nil, fmt.Errorf("marshal before: %w", err)
+	}
+	a, err := marshalState(after)
+	if err != nil {
+		return nil, fmt.Errorf("marshal after: %w", err)
+	}
+	return diffJSON(b, a)
+}
+
+func diffJSON(before json.RawMessage, after json.RawMessage) ([]Change, error) {
+	b, err := decodeState(before)
+	if err != nil {
+		return nil, fmt.Errorf("decode before: %w", err)
+	}
+	a, err := decodeState(after)
+	if err != nil {
+		return nil, fmt.Errorf("decode after: %w", err)
+	}
+	var changes []Change
+	if err = diffValues("", b, a, &changes); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+func decodeState(raw json.RawMessage) (any, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v any
+	err := dec.Decode(&v)
+	return v, err
+}
+
+func diffValues(field string, before any, after any, changes *[]Change) error {
+	bm, bok := before.(map[string]any)
+	am, aok := after.(map[string]any)
+	if (bok && aok) || (bok && after == nil) || (aok && before == nil) {
+		keys := slices.Collect(maps.Keys(bm))
+		for k := range am {
+			if _, ok := bm[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			if err := diffValues(joinField(field, k), bm[k], am[k], changes); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if reflect.DeepEqual(before, after) {
+		return nil
+	}
+
+	change := Change{Field: field}
+	var err error
+	if before != nil {
+		if change.Before, err = json.Marshal(before); err != nil {
+			return err
+		}
+	}
+	if after != nil {
+		if change.After, err = json.Marshal(after); err != nil {
+			return err
+		}
+	}
+	*changes = append(*changes, change)
+	return nil
+}
+
+func joinField(parent string, field string) string {
+	if parent == "" {
+		return field
+	}
+	return parent + "." + field
+}
@@ -0,0 +1,42 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/joshjon/kit/log"
+)
+
+// LogSink is a Sink logging events at info level, e.g. to ship them with the
+// logs of a service.
+type LogSink struct {
+	logger log.Logger
+}
+
+var _ Sink = (*LogSink)(nil)
+
+// NewLogSink creates a new LogSink.
+func NewLogSink(logger log.Logger) *LogSink {
+	return &LogSink{logger: logger}
+}
+
+func (s *LogSink) Write(_ context.Context, events ...Event) error {
+	for _, event := range events {
+		args := []any{
+			"id", event.ID,
+			"time", event.Time,
+			"actor_id", event.Actor.ID,
+			"action", event.Action,
+			"resource_type", event.Resource.Type,
+			"resource_id", event.Resource.ID,
+			"outcome", event.Outcome,
+		}
+		if len(event.Changes) > 0 {
+			args = append(args, "changes", event.Changes)
+		}
+		if len(event.Metadata) > 0 {
+			args = append(args, "metadata", event.Metadata)
+		}
+		s.logger.Info("audit event", args...)
+	}
+	return nil
+}
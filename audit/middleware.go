@@ -0,0 +1,176 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+
+	"github.com/joshjon/kit/clock"
+	"github.com/joshjon/kit/log"
+)
+
+const (
+	eventContextKey = "audit_event"
+	writeTimeout    = 10 * time.Second
+)
+
+// MiddlewareOption optionally configures Middleware.
+type MiddlewareOption func(opts *middlewareOptions)
+
+// WithSkipper sets a skipper of requests that are not audited.
+func WithSkipper(skipper middleware.Skipper) MiddlewareOption {
+	return func(opts *middlewareOptions) {
+		opts.skipper = skipper
+	}
+}
+
+// WithResourceFunc sets the func returning the resource of a request, unless
+// set by the handler with SetResource. Defaults to the route path as the type
+// and the id path param as the ID, e.g. /projects/:id and 123.
+func WithResourceFunc(fn func(c echo.Context) Resource) MiddlewareOption {
+	return func(opts *middlewareOptions) {
+		opts.resourceFunc = fn
+	}
+}
+
+// WithMiddlewareLogger sets the Logger used to log failures to write events.
+// Defaults to log.NewLogger.
+func WithMiddlewareLogger(logger log.Logger) MiddlewareOption {
+	return func(opts *middlewareOptions) {
+		opts.logger = logger
+	}
+}
+
+// WithClock sets the clock used to timestamp events. Defaults to clock.Real.
+func WithClock(clk clock.Clock) MiddlewareOption {
+	return func(opts *middlewareOptions) {
+		opts.clock = clk
+	}
+}
+
+type middlewareOptions struct {
+	skipper      middleware.Skipper
+	resourceFunc func(c echo.Context) Resource
+	logger       log.Logger
+	clock        clock.Clock
+}
+
+// Middleware returns an echo middleware writing an Event to sink for every
+// mutating request (POST, PUT, PATCH and DELETE), successful or not. The
+// actor is taken from ActorFromContext, so the middleware must run after
+// jwt.ValidateMiddleware, and the action is the method and route path, e.g.
+// PATCH /projects/:id. Handlers add the states of the resource with SetStates
+// and metadata with SetMetadata. Failures to write events are logged rather
+// than failing the request.
+func Middleware(sink Sink, opts ...MiddlewareOption) echo.MiddlewareFunc {
+	options := middlewareOptions{
+		skipper:      middleware.DefaultSkipper,
+		resourceFunc: defaultResource,
+		logger:       log.NewLogger(),
+		clock:        clock.Real,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if options.skipper(c) || !isMutating(c.Request().Method) {
+				return next(c)
+			}
+
+			event := New(Actor{}, c.Request().Method+" "+c.Path(), Resource{})
+			event.Metadata = map[string]string{}
+			c.Set(eventContextKey, &event)
+
+			err := next(c)
+
+			event.Time = options.clock.Now().UTC()
+			if actor, ok := ActorFromContext(c); ok {
+				event.Actor = actor
+			}
+			if event.Resource == (Resource{}) {
+				event.Resource = options.resourceFunc(c)
+			}
+			status := responseStatus(c, err)
+			if err != nil || status >= http.StatusBadRequest {
+				event.Outcome = OutcomeFailure
+			}
+			event.Metadata["status"] = strconv.Itoa(status)
+			event.Metadata["remote_ip"] = c.RealIP()
+
+			// Write even if the client has gone away.
+			ctx, cancel := context.WithTimeout(context.WithoutCancel(c.Request().Context()), writeTimeout)
+			defer cancel()
+			if werr := sink.Write(ctx, event); werr != nil {
+				options.logger.Error("failed to write audit event", "action", event.Action, "error", werr)
+			}
+			return err
+		}
+	}
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func defaultResource(c echo.Context) Resource {
+	return Resource{Type: c.Path(), ID: c.Param("id")}
+}
+
+// responseStatus returns the status of the response, which is not written
+// yet if the handler returned an error.
+func responseStatus(c echo.Context, err error) int {
+	if err == nil || c.Response().Committed {
+		return c.Response().Status
+	}
+	var coder interface{ HTTPStatus() int }
+	if errors.As(err, &coder) {
+		return coder.HTTPStatus()
+	}
+	var herr *echo.HTTPError
+	if errors.As(err, &herr) {
+		return herr.Code
+	}
+	return http.StatusInternalServerError
+}
+
+func eventFromContext(c echo.Context) (*Event, bool) {
+	event, ok := c.Get(eventContextKey).(*Event)
+	return event, ok
+}
+
+// SetResource sets the resource of the event captured by Middleware for the
+// request. It is a no-op for requests not audited by Middleware.
+func SetResource(c echo.Context, resource Resource) {
+	if event, ok := eventFromContext(c); ok {
+		event.Resource = resource
+	}
+}
+
+// SetStates sets the states of the resource before and after the request on
+// the event captured by Middleware, see Event.SetStates. It is a no-op for
+// requests not audited by Middleware.
+func SetStates(c echo.Context, before any, after any) error {
+	if event, ok := eventFromContext(c); ok {
+		return event.SetStates(before, after)
+	}
+	return nil
+}
+
+// SetMetadata sets metadata of the event captured by Middleware for the
+// request. It is a no-op for requests not audited by Middleware.
+func SetMetadata(c echo.Context, key string, value string) {
+	if event, ok := eventFromContext(c); ok {
+		event.Metadata[key] = value
+	}
+}
@@ -0,0 +1,76 @@
+package audit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/errtag"
+	"github.com/joshjon/kit/log"
+	"github.com/joshjon/kit/testutil"
+)
+
+// authenticate sets the user as jwt.ValidateMiddleware does.
+func authenticate(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		c.Set("jwt-auth-user-id", "user1")
+		c.Set("jwt-auth-email", "user1@example.com")
+		return next(c)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	var written []Event
+	sink := sinkFunc(func(_ context.Context, events ...Event) error {
+		written = append(written, events...)
+		return nil
+	})
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	e := echo.New()
+	e.Use(authenticate, Middleware(sink,
+		WithClock(testutil.NewFakeClock(now)),
+		WithMiddlewareLogger(log.NewLogger(log.WithNop())),
+	))
+	e.PATCH("/projects/:id", func(c echo.Context) error {
+		if err := SetStates(c, owner{Team: "core"}, owner{Team: "infra"}); err != nil {
+			return err
+		}
+		SetMetadata(c, "reason", "reorg")
+		return c.NoContent(http.StatusNoContent)
+	})
+	e.DELETE("/projects/:id", func(c echo.Context) error {
+		SetResource(c, Resource{Type: "project", ID: c.Param("id")})
+		return errtag.NewTagged[errtag.Forbidden]("not owner")
+	})
+	e.GET("/projects/:id", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	for _, method := range []string{http.MethodPatch, http.MethodDelete, http.MethodGet} {
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, "/projects/123", nil))
+	}
+	require.Len(t, written, 2)
+
+	patched := written[0]
+	assert.Equal(t, now, patched.Time)
+	assert.Equal(t, Actor{ID: "user1", Email: "user1@example.com"}, patched.Actor)
+	assert.Equal(t, "PATCH /projects/:id", patched.Action)
+	assert.Equal(t, Resource{Type: "/projects/:id", ID: "123"}, patched.Resource)
+	assert.Equal(t, OutcomeSuccess, patched.Outcome)
+	assert.Equal(t, []Change{{Field: "team", Before: []byte(`"core"`), After: []byte(`"infra"`)}}, patched.Changes)
+	assert.Equal(t, "reorg", patched.Metadata["reason"])
+	assert.Equal(t, "204", patched.Metadata["status"])
+
+	deleted := written[1]
+	assert.Equal(t, "DELETE /projects/:id", deleted.Action)
+	assert.Equal(t, Resource{Type: "project", ID: "123"}, deleted.Resource)
+	assert.Equal(t, OutcomeFailure, deleted.Outcome)
+	assert.Equal(t, "403", deleted.Metadata["status"])
+}
@@ -0,0 +1,18 @@
+CREATE TABLE audit_events
+(
+    id            TEXT PRIMARY KEY,
+    event_time    TIMESTAMPTZ NOT NULL,
+    actor_id      TEXT        NOT NULL,
+    actor_email   TEXT        NOT NULL DEFAULT '',
+    action        TEXT        NOT NULL,
+    resource_type TEXT        NOT NULL,
+    resource_id   TEXT        NOT NULL DEFAULT '',
+    outcome       TEXT        NOT NULL,
+    before        JSONB,
+    after         JSONB,
+    changes       JSONB,
+    metadata      JSONB
+);
+
+CREATE INDEX audit_events_resource_idx ON audit_events (resource_type, resource_id, event_time);
+CREATE INDEX audit_events_actor_idx ON audit_events (actor_id, event_time);
@@ -0,0 +1,34 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/nats-io/nats.go/jetstream"
+
+	"github.com/joshjon/kit/natsutil"
+)
+
+// NATSSink is a Sink publishing events as JSON to a NATS JetStream subject,
+// e.g. to collect the events of all services in one stream. Events are
+// published with their ID as the message ID, so events written again within
+// the duplicate window of the stream are deduplicated.
+type NATSSink struct {
+	publisher *natsutil.Publisher[Event]
+}
+
+var _ Sink = (*NATSSink)(nil)
+
+// NewNATSSink creates a new NATSSink publishing to subject, which must be
+// captured by a stream.
+func NewNATSSink(js jetstream.JetStream, subject string, opts ...natsutil.MsgOption) *NATSSink {
+	return &NATSSink{publisher: natsutil.NewPublisher[Event](js, subject, opts...)}
+}
+
+func (s *NATSSink) Write(ctx context.Context, events ...Event) error {
+	for _, event := range events {
+		if _, err := s.publisher.Publish(ctx, event, jetstream.WithMsgID(event.ID)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package audit
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/joshjon/kit/pgdb"
+	"github.com/joshjon/kit/tx"
+)
+
+const postgresMigrationsTable = "audit_schema_migrations"
+
+//go:embed migrations/postgres/*.sql
+var postgresMigrations embed.FS
+
+// MigratePostgres applies the migrations of the audit_events table used by a
+// PostgresSink, recording them separately from the migrations of the service.
+func MigratePostgres(pool *pgxpool.Pool) error {
+	fsys, err := fs.Sub(postgresMigrations, "migrations/postgres")
+	if err != nil {
+		return err
+	}
+	return pgdb.Migrate(pool, fsys, pgdb.WithMigrationsTable(postgresMigrationsTable))
+}
+
+// pgxDB is implemented by both pgxpool.Pool and pgx.Tx.
+type pgxDB interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// PostgresSink is a Sink writing events to the audit_events table in
+// Postgres. Bind it to the transaction of the audited changes with WithTx, so
+// events are only recorded if the transaction commits. Apply its migrations
+// with MigratePostgres.
+type PostgresSink struct {
+	db pgxDB
+}
+
+var _ Sink = (*PostgresSink)(nil)
+
+// NewPostgresSink creates a new PostgresSink.
+func NewPostgresSink(pool *pgxpool.Pool) *PostgresSink {
+	return &PostgresSink{db: pool}
+}
+
+// WithTx returns a copy of the sink bound to the provided transaction.
+//
+// Panics if tx does not implement pgx.Tx.
+func (s *PostgresSink) WithTx(txn tx.Tx) *PostgresSink {
+	pgxTx, ok := txn.(pgx.Tx)
+	if !ok {
+		panic("audit.PostgresSink.WithTx: expected pgx.Tx")
+	}
+	return &PostgresSink{db: pgxTx}
+}
+
+func (s *PostgresSink) Write(ctx context.Context, events ...Event) error {
+	for _, event := range events {
+		var changes, metadata []byte
+		var err error
+		if len(event.Changes) > 0 {
+			if changes, err = json.Marshal(event.Changes); err != nil {
+				return fmt.Errorf("marshal changes of audit event %s: %w", event.ID, err)
+			}
+		}
+		if len(event.Metadata) > 0 {
+			if metadata, err = json.Marshal(event.Metadata); err != nil {
+				return fmt.Errorf("marshal metadata of audit event %s: %w", event.ID, err)
+			}
+		}
+		_, err = s.db.Exec(ctx, `
+			INSERT INTO audit_events (id, event_time, actor_id, actor_email, action, resource_type, resource_id,
+			                          outcome, before, after, changes, metadata)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
+			event.ID, event.Time, event.Actor.ID, event.Actor.Email, event.Action, event.Resource.Type,
+			event.Resource.ID, string(event.Outcome), nullableJSON(event.Before), nullableJSON(event.After),
+			changes, metadata,
+		)
+		if err != nil {
+			return fmt.Errorf("write audit event %s: %w", event.ID, err)
+		}
+	}
+	return nil
+}
+
+// ListByResource returns the events of resource, oldest first.
+func (s *PostgresSink) ListByResource(ctx context.Context, resource Resource) ([]Event, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT id, event_time, actor_id, actor_email, action, resource_type, resource_id, outcome, before, after,
+		       changes, metadata
+		FROM audit_events
+		WHERE resource_type = $1 AND resource_id = $2
+		ORDER BY event_time, id`,
+		resource.Type, resource.ID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query audit events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []Event
+	for rows.Next() {
+		var event Event
+		var outcome string
+		var before, after, changes, metadata []byte
+		err = rows.Scan(&event.ID, &event.Time, &event.Actor.ID, &event.Actor.Email, &event.Action,
+			&event.Resource.Type, &event.Resource.ID, &outcome, &before, &after, &changes, &metadata)
+		if err != nil {
+			return nil, fmt.Errorf("scan audit event: %w", err)
+		}
+		event.Outcome = Outcome(outcome)
+		event.Before = before
+		event.After = after
+		if changes != nil {
+			if err = json.Unmarshal(changes, &event.Changes); err != nil {
+				return nil, fmt.Errorf("unmarshal changes of audit event %s: %w", event.ID, err)
+			}
+		}
+		if metadata != nil {
+			if err = json.Unmarshal(metadata, &event.Metadata); err != nil {
+				return nil, fmt.Errorf("unmarshal metadata of audit event %s: %w", event.ID, err)
+			}
+		}
+		events = append(events, event)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("query audit events: %w", err)
+	}
+	return events, nil
+}
+
+func nullableJSON(raw json.RawMessage) []byte {
+	if len(raw) == 0 {
+		return nil
+	}
+	return raw
+}
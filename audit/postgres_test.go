@@ -0,0 +1,52 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/testutil"
+	"github.com/joshjon/kit/tx"
+)
+
+func TestPostgresSink(t *testing.T) {
+	ctx := context.Background()
+	pool := testutil.StartPostgres(t, nil)
+	require.NoError(t, MigratePostgres(pool))
+	sink := NewPostgresSink(pool)
+
+	resource := Resource{Type: "project", ID: "1"}
+	created := New(Actor{ID: "user1", Email: "user1@example.com"}, "create", resource)
+	require.NoError(t, created.SetStates(nil, owner{ID: "1", Team: "core"}))
+	created.Metadata = map[string]string{"status": "201"}
+	deleted := New(Actor{ID: "user1"}, "delete", resource)
+	deleted.Outcome = OutcomeFailure
+	require.NoError(t, sink.Write(ctx, created, deleted))
+
+	// events written in a rolled back transaction are discarded
+	txn, err := pool.BeginTx(ctx, pgx.TxOptions{})
+	require.NoError(t, err)
+	err = tx.Do(ctx, txn, func(ctx context.Context) error {
+		if err := sink.WithTx(txn).Write(ctx, New(Actor{ID: "user1"}, "update", resource)); err != nil {
+			return err
+		}
+		return errors.New("failed")
+	})
+	require.Error(t, err)
+
+	got, err := sink.ListByResource(ctx, resource)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, created.ID, got[0].ID)
+	assert.Equal(t, created.Actor, got[0].Actor)
+	assert.JSONEq(t, string(created.After), string(got[0].After))
+	assert.Nil(t, got[0].Before)
+	assert.Equal(t, created.Changes, got[0].Changes)
+	assert.Equal(t, created.Metadata, got[0].Metadata)
+	assert.Equal(t, deleted.ID, got[1].ID)
+	assert.Equal(t, OutcomeFailure, got[1].Outcome)
+}
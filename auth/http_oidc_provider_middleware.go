@@ -11,7 +11,10 @@ import (
 	"github.com/joshjon/kit/valgoutil"
 )
 
-const oidcProviderContextKey = "auth-oidc-provider"
+const (
+	oidcProviderContextKey = "auth-oidc-provider"
+	sessionContextKey      = "auth-session"
+)
 
 type OIDCProviderAudience struct {
 	Name   string   `yaml:"name" env:"NAME"`
@@ -58,6 +61,7 @@ func OIDCProviderMiddleware(cfg OIDCProviderConfig, opts ...SessionStorageOption
 			s := &session{cfg.SessionName, c.Request(), cfg.SessionStore, nil, false, c.Response().Writer}
 			p := cfg.OIDCInitializer(NewSessionStorage(s, opts...))
 			c.Set(oidcProviderContextKey, p)
+			c.Set(sessionContextKey, s)
 			return next(c)
 		}
 	}
@@ -74,3 +78,19 @@ func GetOIDCProvider(c echo.Context) (OIDCProvider, error) {
 	}
 	return p, nil
 }
+
+// SessionIDFromContext returns the ID of the session loaded by
+// OIDCProviderMiddleware. It returns an empty string if the request has no
+// valid session, e.g. its cookie was forged, or the session store does not
+// assign IDs, e.g. a cookie store.
+func SessionIDFromContext(c echo.Context) string {
+	s, ok := c.Get(sessionContextKey).(*session)
+	if !ok {
+		return ""
+	}
+	gs := s.Session()
+	if gs == nil || gs.IsNew {
+		return ""
+	}
+	return gs.ID
+}
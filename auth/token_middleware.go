@@ -9,7 +9,22 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
+// BearerTokenMiddleware injects a session-derived bearer token for the audience
+// whose path prefix matches the request path. The audPaths map is keyed by
+// audience with the path prefix as the value.
 func BearerTokenMiddleware(audPaths map[string]string, skipPathPrefixes ...string) echo.MiddlewareFunc {
+	pathAuds := make(map[string]string, len(audPaths))
+	for aud, path := range audPaths {
+		pathAuds[path] = aud
+	}
+	return PathBearerTokenMiddleware(pathAuds, skipPathPrefixes...)
+}
+
+// PathBearerTokenMiddleware is like BearerTokenMiddleware but the pathAuds map
+// is keyed by path prefix with the audience as the value, which allows an
+// audience to be served under multiple path prefixes. The longest matching
+// prefix wins.
+func PathBearerTokenMiddleware(pathAuds map[string]string, skipPathPrefixes ...string) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			reqPath := c.Request().URL.Path
@@ -25,13 +40,7 @@ func BearerTokenMiddleware(audPaths map[string]string, skipPathPrefixes ...strin
 				return next(c)
 			}
 
-			var resource string
-			for aud, path := range audPaths {
-				if strings.HasPrefix(reqPath, path) {
-					resource = aud
-					break
-				}
-			}
+			resource := audienceForPath(pathAuds, reqPath)
 
 			p, err := GetOIDCProvider(c)
 			if err != nil {
@@ -48,3 +57,16 @@ func BearerTokenMiddleware(audPaths map[string]string, skipPathPrefixes ...strin
 		}
 	}
 }
+
+func audienceForPath(pathAuds map[string]string, reqPath string) string {
+	var longestPrefixMatch string
+	for prefix := range pathAuds {
+		if strings.HasPrefix(reqPath, prefix) && len(prefix) > len(longestPrefixMatch) {
+			longestPrefixMatch = prefix
+		}
+	}
+	if longestPrefixMatch == "" {
+		return ""
+	}
+	return pathAuds[longestPrefixMatch]
+}
@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAudienceForPath(t *testing.T) {
+	pathAuds := map[string]string{
+		"/api":         "https://api.example.com",
+		"/api/billing": "https://billing.example.com",
+		"/reports":     "https://reports.example.com",
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/api/users", want: "https://api.example.com"},
+		{path: "/api/billing/invoices", want: "https://billing.example.com"},
+		{path: "/api/billing", want: "https://billing.example.com"},
+		{path: "/reports", want: "https://reports.example.com"},
+		{path: "/other", want: ""},
+		{path: "/", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.want, audienceForPath(pathAuds, tt.path))
+		})
+	}
+
+	assert.Empty(t, audienceForPath(nil, "/api"))
+}
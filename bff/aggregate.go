@@ -0,0 +1,179 @@
+package bff
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/joshjon/kit/errtag"
+	"github.com/joshjon/kit/server"
+)
+
+// AggregatePolicy determines how failed downstream requests affect the
+// aggregated response.
+type AggregatePolicy int
+
+const (
+	// AggregateFailAll fails the aggregate if any downstream request fails.
+	AggregateFailAll AggregatePolicy = iota
+	// AggregatePartial omits failed downstream responses and reports them under
+	// AggregateResponse.Errors. Requests marked as Required still fail the
+	// aggregate.
+	AggregatePartial
+)
+
+// AggregateRequest describes a single downstream request whose JSON response
+// is merged into the aggregate under Key.
+type AggregateRequest struct {
+	Key      string
+	Method   string // defaults to GET
+	URL      string
+	Required bool // fail the aggregate if this request fails regardless of policy
+}
+
+// AggregateResponse is the merged result of an aggregate.
+type AggregateResponse struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors map[string]string          `json:"errors,omitempty"`
+}
+
+// AggregateOption configures an aggregate.
+type AggregateOption func(opts *aggregateOptions)
+
+// WithAggregatePolicy sets the partial failure policy. Defaults to
+// AggregateFailAll.
+func WithAggregatePolicy(policy AggregatePolicy) AggregateOption {
+	return func(opts *aggregateOptions) {
+		opts.policy = policy
+	}
+}
+
+// WithAggregateHeaders sets headers sent with every downstream request.
+func WithAggregateHeaders(header http.Header) AggregateOption {
+	return func(opts *aggregateOptions) {
+		opts.header = header
+	}
+}
+
+type aggregateOptions struct {
+	policy AggregatePolicy
+	header http.Header
+}
+
+// Aggregate concurrently sends the requests to their downstreams and merges
+// the JSON responses under their keys. Non-2xx responses are treated as
+// failures.
+func Aggregate(ctx context.Context, client *http.Client, reqs []AggregateRequest, opts ...AggregateOption) (AggregateResponse, error) {
+	var options aggregateOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	type result struct {
+		body json.RawMessage
+		err  error
+	}
+
+	results := make([]result, len(reqs))
+	var wg sync.WaitGroup
+	for i, req := range reqs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			body, err := doAggregateRequest(ctx, client, req, options.header)
+			results[i] = result{body: body, err: err}
+		}()
+	}
+	wg.Wait()
+
+	res := AggregateResponse{
+		Data: make(map[string]json.RawMessage, len(reqs)),
+	}
+	for i, req := range reqs {
+		r := results[i]
+		if r.err == nil {
+			res.Data[req.Key] = r.body
+			continue
+		}
+		if options.policy == AggregateFailAll || req.Required {
+			return AggregateResponse{}, tagAggregateErr(fmt.Errorf("aggregate %s: %w", req.Key, r.err))
+		}
+		if res.Errors == nil {
+			res.Errors = map[string]string{}
+		}
+		res.Errors[req.Key] = r.err.Error()
+	}
+
+	return res, nil
+}
+
+// AggregateHandler returns a handler that aggregates the requests and responds
+// with the AggregateResponse. The Authorization header of the incoming request
+// (e.g. injected by the bearer token middleware) and its request ID are
+// forwarded downstream.
+func AggregateHandler(client *http.Client, reqs []AggregateRequest, opts ...AggregateOption) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		header := http.Header{}
+		if authz := c.Request().Header.Get(echo.HeaderAuthorization); authz != "" {
+			header.Set(echo.HeaderAuthorization, authz)
+		}
+		if requestID := server.RequestIDFromContext(c); requestID != "" {
+			header.Set(echo.HeaderXRequestID, requestID)
+		}
+		opts := append([]AggregateOption{WithAggregateHeaders(header)}, opts...)
+
+		res, err := Aggregate(c.Request().Context(), client, reqs, opts...)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+func doAggregateRequest(ctx context.Context, client *http.Client, req AggregateRequest, header http.Header) (json.RawMessage, error) {
+	method := req.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, method, req.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, vals := range header {
+		httpReq.Header[key] = vals
+	}
+	httpReq.Header.Set(echo.HeaderAccept, echo.MIMEApplicationJSON)
+
+	httpRes, err := client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer httpRes.Body.Close()
+
+	body, err := io.ReadAll(httpRes.Body)
+	if err != nil {
+		return nil, err
+	}
+	if httpRes.StatusCode < 200 || httpRes.StatusCode >= 300 {
+		return nil, fmt.Errorf("downstream responded with status %d", httpRes.StatusCode)
+	}
+	if !json.Valid(body) {
+		return nil, errors.New("downstream responded with invalid json")
+	}
+
+	return body, nil
+}
+
+func tagAggregateErr(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return errtag.Tag[errtag.GatewayTimeout](err)
+	}
+	return errtag.Tag[errtag.BadGateway](err)
+}
@@ -0,0 +1,137 @@
+package bff
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/errtag"
+)
+
+func startAggregateDownstream(t *testing.T) string {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"id":1}]`))
+	})
+	mux.HandleFunc("/projects", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"id":2}]`))
+	})
+	mux.HandleFunc("/fail", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+	mux.HandleFunc("/headers", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"authorization": r.Header.Get(echo.HeaderAuthorization),
+			"request_id":    r.Header.Get(echo.HeaderXRequestID),
+		})
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func assertTag(t *testing.T, err error, wantCode int) {
+	t.Helper()
+	tag, ok := errtag.Primary(err)
+	require.True(t, ok, "want tagged error, got %v", err)
+	assert.Equal(t, wantCode, tag.Code())
+}
+
+func TestAggregate(t *testing.T) {
+	url := startAggregateDownstream(t)
+	ctx := context.Background()
+
+	t.Run("all success", func(t *testing.T) {
+		res, err := Aggregate(ctx, http.DefaultClient, []AggregateRequest{
+			{Key: "users", URL: url + "/users"},
+			{Key: "projects", URL: url + "/projects"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]json.RawMessage{
+			"users":    json.RawMessage(`[{"id":1}]`),
+			"projects": json.RawMessage(`[{"id":2}]`),
+		}, res.Data)
+		assert.Empty(t, res.Errors)
+	})
+
+	t.Run("fail all", func(t *testing.T) {
+		_, err := Aggregate(ctx, http.DefaultClient, []AggregateRequest{
+			{Key: "users", URL: url + "/users"},
+			{Key: "projects", URL: url + "/fail"},
+		})
+		assertTag(t, err, http.StatusBadGateway)
+	})
+
+	t.Run("partial failure", func(t *testing.T) {
+		res, err := Aggregate(ctx, http.DefaultClient, []AggregateRequest{
+			{Key: "users", URL: url + "/users"},
+			{Key: "projects", URL: url + "/fail"},
+		}, WithAggregatePolicy(AggregatePartial))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]json.RawMessage{"users": json.RawMessage(`[{"id":1}]`)}, res.Data)
+		assert.Equal(t, map[string]string{"projects": "downstream responded with status 500"}, res.Errors)
+	})
+
+	t.Run("required failure", func(t *testing.T) {
+		_, err := Aggregate(ctx, http.DefaultClient, []AggregateRequest{
+			{Key: "users", URL: url + "/fail", Required: true},
+			{Key: "projects", URL: url + "/projects"},
+		}, WithAggregatePolicy(AggregatePartial))
+		assertTag(t, err, http.StatusBadGateway)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		_, err := Aggregate(ctx, http.DefaultClient, []AggregateRequest{
+			{Key: "users", URL: url + "/users"},
+			{Key: "slow", URL: url + "/slow"},
+		})
+		assertTag(t, err, http.StatusGatewayTimeout)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		time.AfterFunc(50*time.Millisecond, cancel)
+		_, err := Aggregate(ctx, http.DefaultClient, []AggregateRequest{
+			{Key: "users", URL: url + "/users"},
+			{Key: "slow", URL: url + "/slow"},
+		})
+		assertTag(t, err, http.StatusBadGateway)
+		assert.ErrorIs(t, err, context.Canceled)
+
+		res, err := Aggregate(ctx, http.DefaultClient, []AggregateRequest{
+			{Key: "slow", URL: url + "/slow"},
+		}, WithAggregatePolicy(AggregatePartial))
+		require.NoError(t, err)
+		assert.Contains(t, res.Errors["slow"], context.Canceled.Error())
+	})
+}
+
+func TestAggregateHandler(t *testing.T) {
+	url := startAggregateDownstream(t)
+	h := AggregateHandler(http.DefaultClient, []AggregateRequest{{Key: "headers", URL: url + "/headers"}})
+
+	req := httptest.NewRequest(http.MethodGet, "/dashboard", nil)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer token")
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	c.Set("request_id", "req-1") // set by the request ID middleware of server.Server
+	require.NoError(t, h(c))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var res AggregateResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	assert.JSONEq(t, `{"authorization":"Bearer token","request_id":"req-1"}`, string(res.Data["headers"]))
+}
@@ -0,0 +1,263 @@
+package bff
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cohesivestack/valgo"
+
+	"github.com/joshjon/kit/cache"
+	"github.com/joshjon/kit/clock"
+)
+
+// cacheMaxEntries is the maximum number of responses cached per downstream,
+// evicting the least recently used when exceeded.
+const cacheMaxEntries = 1024
+
+// CacheRouteConfig enables response caching of GET requests to a downstream
+// for paths matching PathPrefix. Responses are cached per user (keyed by the
+// Authorization header) for TTLSeconds, after which they are served stale for
+// up to StaleWhileRevalidateSeconds while being refreshed in the background.
+//
+// Requests without an Authorization header are not cached. Responses are not
+// cached if their Cache-Control header has a no-store, no-cache or private
+// directive, or a Vary header of *, and a max-age directive shortens the TTL.
+// Responses with a Vary header are cached per value of the varied request
+// headers.
+type CacheRouteConfig struct {
+	PathPrefix                  string `yaml:"pathPrefix" env:"PATH_PREFIX"`
+	TTLSeconds                  int    `yaml:"ttlSeconds" env:"TTL_SECONDS"`
+	StaleWhileRevalidateSeconds int    `yaml:"staleWhileRevalidateSeconds" env:"STALE_WHILE_REVALIDATE_SECONDS"`
+}
+
+func (c *CacheRouteConfig) Validation() *valgo.Validation {
+	return valgo.Is(
+		valgo.String(c.PathPrefix, "pathPrefix").Not().Blank(),
+		valgo.Int(c.TTLSeconds, "ttlSeconds").GreaterThan(0),
+		valgo.Int(c.StaleWhileRevalidateSeconds, "staleWhileRevalidateSeconds").GreaterOrEqualTo(0),
+	)
+}
+
+func (c *CacheRouteConfig) ttl() time.Duration {
+	return time.Duration(c.TTLSeconds) * time.Second
+}
+
+func (c *CacheRouteConfig) staleTTL() time.Duration {
+	return time.Duration(c.StaleWhileRevalidateSeconds) * time.Second
+}
+
+type cachedResponse struct {
+	status   int
+	header   http.Header
+	body     []byte
+	storedAt time.Time
+	ttl      time.Duration // fresh for ttl after storedAt
+}
+
+// cachingTransport is an http.RoundTripper that caches successful GET
+// responses for the configured routes.
+type cachingTransport struct {
+	base    http.RoundTripper
+	routes  []CacheRouteConfig
+	clock   clock.Clock
+	entries *cache.Cache[string, cachedResponse]
+	varies  *cache.Cache[string, []string] // Vary header names by request key
+
+	mu           sync.Mutex
+	revalidating map[string]struct{}
+}
+
+func newCachingTransport(base http.RoundTripper, routes []CacheRouteConfig, clk clock.Clock) *cachingTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &cachingTransport{
+		base:         base,
+		routes:       routes,
+		clock:        clk,
+		entries:      cache.New[string, cachedResponse](cache.WithMaxEntries(cacheMaxEntries), cache.WithClock(clk)),
+		varies:       cache.New[string, []string](cache.WithMaxEntries(cacheMaxEntries), cache.WithClock(clk)),
+		revalidating: map[string]struct{}{},
+	}
+}
+
+func (t *cachingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	route, ok := t.matchRoute(req)
+	if !ok || req.Header.Get("Authorization") == "" {
+		return t.base.RoundTrip(req)
+	}
+
+	reqKey := requestCacheKey(req)
+	varyNames, _ := t.varies.Get(reqKey)
+	key := entryCacheKey(reqKey, varyNames, req.Header)
+
+	if entry, found := t.entries.Get(key); found {
+		age := t.clock.Since(entry.storedAt)
+		switch {
+		case age < entry.ttl:
+			return entry.response(req), nil
+		case age < entry.ttl+route.staleTTL():
+			t.revalidate(key, route, req)
+			return entry.response(req), nil
+		}
+	}
+
+	return t.fetch(key, route, req)
+}
+
+func (t *cachingTransport) fetch(key string, route CacheRouteConfig, req *http.Request) (*http.Response, error) {
+	res, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	ttl, varyNames, cacheable := responseCachePolicy(res.Header, route.ttl())
+	if res.StatusCode != http.StatusOK || !cacheable {
+		return res, nil
+	}
+
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	entry := cachedResponse{
+		status:   res.StatusCode,
+		header:   res.Header.Clone(),
+		body:     body,
+		storedAt: t.clock.Now(),
+		ttl:      ttl,
+	}
+	t.store(req, varyNames, entry, route.staleTTL())
+
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	return res, nil
+}
+
+// revalidate refreshes the entry in the background. At most one revalidation
+// runs per key.
+func (t *cachingTransport) revalidate(key string, route CacheRouteConfig, req *http.Request) {
+	t.mu.Lock()
+	if _, ok := t.revalidating[key]; ok {
+		t.mu.Unlock()
+		return
+	}
+	t.revalidating[key] = struct{}{}
+	t.mu.Unlock()
+
+	// The incoming request context is canceled once the client response is
+	// written, so detach the refresh from it.
+	bgReq := req.Clone(context.WithoutCancel(req.Context()))
+
+	go func() {
+		defer func() {
+			t.mu.Lock()
+			delete(t.revalidating, key)
+			t.mu.Unlock()
+		}()
+		res, err := t.fetch(key, route, bgReq)
+		if err == nil {
+			res.Body.Close()
+		}
+	}()
+}
+
+// store caches entry until it is no longer served stale. The key of the entry
+// is derived from the Vary header of the response, which may differ from the
+// key it was fetched for.
+func (t *cachingTransport) store(req *http.Request, varyNames []string, entry cachedResponse, staleTTL time.Duration) {
+	reqKey := requestCacheKey(req)
+	ttl := entry.ttl + staleTTL
+	t.varies.SetWithTTL(reqKey, varyNames, ttl)
+	t.entries.SetWithTTL(entryCacheKey(reqKey, varyNames, req.Header), entry, ttl)
+}
+
+func (t *cachingTransport) matchRoute(req *http.Request) (CacheRouteConfig, bool) {
+	if req.Method != http.MethodGet {
+		return CacheRouteConfig{}, false
+	}
+	var match CacheRouteConfig
+	for _, route := range t.routes {
+		if strings.HasPrefix(req.URL.Path, route.PathPrefix) && len(route.PathPrefix) > len(match.PathPrefix) {
+			match = route
+		}
+	}
+	return match, match.PathPrefix != ""
+}
+
+func (e cachedResponse) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        http.StatusText(e.status),
+		StatusCode:    e.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(e.body)),
+		ContentLength: int64(len(e.body)),
+		Request:       req,
+	}
+}
+
+// requestCacheKey identifies a request by path, query and user. The
+// Authorization header is hashed so tokens are not retained in memory as
+// keys.
+func requestCacheKey(req *http.Request) string {
+	user := sha256.Sum256([]byte(req.Header.Get("Authorization")))
+	return req.URL.Path + "?" + req.URL.RawQuery + "#" + hex.EncodeToString(user[:])
+}
+
+// entryCacheKey identifies a response to the request of reqKey, which varies
+// by the request headers varyNames.
+func entryCacheKey(reqKey string, varyNames []string, header http.Header) string {
+	var b strings.Builder
+	b.WriteString(reqKey)
+	for _, name := range varyNames {
+		b.WriteString("\x00")
+		b.WriteString(strings.Join(header.Values(name), ","))
+	}
+	return b.String()
+}
+
+// responseCachePolicy returns how long a response with header is fresh for,
+// at most ttl, the request header names it varies by, and whether it may be
+// cached at all.
+func responseCachePolicy(header http.Header, ttl time.Duration) (time.Duration, []string, bool) {
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		name, value, _ := strings.Cut(strings.ToLower(strings.TrimSpace(directive)), "=")
+		switch name {
+		case "no-store", "no-cache", "private":
+			return 0, nil, false
+		case "max-age":
+			seconds, err := strconv.Atoi(strings.Trim(value, `"`))
+			if err != nil || seconds <= 0 {
+				return 0, nil, false
+			}
+			ttl = min(ttl, time.Duration(seconds)*time.Second)
+		}
+	}
+
+	var names []string
+	for _, value := range header.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+			if name == "*" {
+				return 0, nil, false
+			}
+			if name != "" && !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	slices.Sort(names)
+	return ttl, names, true
+}
@@ -0,0 +1,215 @@
+package bff
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/clock"
+	"github.com/joshjon/kit/testutil"
+)
+
+func TestCachingTransport(t *testing.T) {
+	var hits atomic.Int32
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		_, _ = w.Write([]byte(r.Header.Get("Authorization")))
+	}))
+	defer downstream.Close()
+
+	client := &http.Client{
+		Transport: newCachingTransport(nil, []CacheRouteConfig{
+			{PathPrefix: "/cached", TTLSeconds: 60},
+		}, clock.Real),
+	}
+
+	get := func(path string, token string) string {
+		req, err := http.NewRequest(http.MethodGet, downstream.URL+path, nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", token)
+		res, err := client.Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return string(body)
+	}
+
+	assert.Equal(t, "user-a", get("/cached/items", "user-a"))
+	assert.Equal(t, "user-a", get("/cached/items", "user-a"))
+	assert.Equal(t, int32(1), hits.Load())
+
+	// cache is keyed per user
+	assert.Equal(t, "user-b", get("/cached/items", "user-b"))
+	assert.Equal(t, int32(2), hits.Load())
+
+	// non-matching routes are not cached
+	get("/other", "user-a")
+	get("/other", "user-a")
+	assert.Equal(t, int32(4), hits.Load())
+}
+
+func TestCachingTransport_expires(t *testing.T) {
+	downstream := testutil.StartStubServer(t)
+	route := downstream.Handle(http.MethodGet, "/cached")
+
+	clk := testutil.NewFakeClock(time.Now())
+	client := &http.Client{
+		Transport: newCachingTransport(nil, []CacheRouteConfig{
+			{PathPrefix: "/cached", TTLSeconds: 60},
+		}, clk),
+	}
+	get := func() {
+		req, err := http.NewRequest(http.MethodGet, downstream.URL()+"/cached", nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "user-a")
+		res, err := client.Do(req)
+		require.NoError(t, err)
+		res.Body.Close()
+	}
+
+	get()
+	clk.Advance(59 * time.Second)
+	get()
+	route.AssertCount(t, 1)
+
+	clk.Advance(time.Second)
+	get()
+	route.AssertCount(t, 2)
+}
+
+func TestCachingTransport_anonymous(t *testing.T) {
+	downstream := testutil.StartStubServer(t)
+	route := downstream.Handle(http.MethodGet, "/cached")
+	client := &http.Client{
+		Transport: newCachingTransport(nil, []CacheRouteConfig{
+			{PathPrefix: "/cached", TTLSeconds: 60},
+		}, clock.Real),
+	}
+
+	for range 2 {
+		res, err := client.Get(downstream.URL() + "/cached")
+		require.NoError(t, err)
+		res.Body.Close()
+	}
+	route.AssertCount(t, 2)
+}
+
+func TestCachingTransport_cacheControl(t *testing.T) {
+	tests := []struct {
+		name         string
+		cacheControl string
+		vary         string
+		wantHits     int
+	}{
+		{name: "public", cacheControl: "public, max-age=120", wantHits: 1},
+		{name: "private", cacheControl: "private, max-age=120", wantHits: 2},
+		{name: "no-cache", cacheControl: "no-cache", wantHits: 2},
+		{name: "no-store", cacheControl: "no-store", wantHits: 2},
+		{name: "max-age zero", cacheControl: "max-age=0", wantHits: 2},
+		{name: "vary all", vary: "*", wantHits: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			downstream := testutil.StartStubServer(t)
+			route := downstream.Handle(http.MethodGet, "/cached").
+				Header("Cache-Control", tt.cacheControl).
+				Header("Vary", tt.vary)
+			client := &http.Client{
+				Transport: newCachingTransport(nil, []CacheRouteConfig{
+					{PathPrefix: "/cached", TTLSeconds: 60},
+				}, clock.Real),
+			}
+
+			for range 2 {
+				getCached(t, client, downstream.URL()+"/cached", nil)
+			}
+			route.AssertCount(t, tt.wantHits)
+		})
+	}
+}
+
+func TestCachingTransport_maxAge(t *testing.T) {
+	downstream := testutil.StartStubServer(t)
+	route := downstream.Handle(http.MethodGet, "/cached").Header("Cache-Control", "max-age=10")
+
+	clk := testutil.NewFakeClock(time.Now())
+	client := &http.Client{
+		Transport: newCachingTransport(nil, []CacheRouteConfig{
+			{PathPrefix: "/cached", TTLSeconds: 60},
+		}, clk),
+	}
+
+	getCached(t, client, downstream.URL()+"/cached", nil)
+	clk.Advance(9 * time.Second)
+	getCached(t, client, downstream.URL()+"/cached", nil)
+	route.AssertCount(t, 1)
+
+	clk.Advance(time.Second)
+	getCached(t, client, downstream.URL()+"/cached", nil)
+	route.AssertCount(t, 2)
+}
+
+func TestCachingTransport_vary(t *testing.T) {
+	var hits atomic.Int32
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Vary", "Accept-Language")
+		_, _ = w.Write([]byte(r.Header.Get("Accept-Language")))
+	}))
+	defer downstream.Close()
+
+	client := &http.Client{
+		Transport: newCachingTransport(nil, []CacheRouteConfig{
+			{PathPrefix: "/cached", TTLSeconds: 60},
+		}, clock.Real),
+	}
+
+	en := http.Header{"Accept-Language": {"en"}}
+	fr := http.Header{"Accept-Language": {"fr"}}
+	assert.Equal(t, "en", getCached(t, client, downstream.URL+"/cached", en))
+	assert.Equal(t, "fr", getCached(t, client, downstream.URL+"/cached", fr))
+	assert.Equal(t, "en", getCached(t, client, downstream.URL+"/cached", en))
+	assert.Equal(t, "fr", getCached(t, client, downstream.URL+"/cached", fr))
+	assert.Equal(t, int32(2), hits.Load())
+}
+
+func TestCachingTransport_maxEntries(t *testing.T) {
+	downstream := testutil.StartStubServer(t)
+	downstream.Handle(http.MethodGet, "/cached/{id}")
+
+	transport := newCachingTransport(nil, []CacheRouteConfig{
+		{PathPrefix: "/cached", TTLSeconds: 60},
+	}, clock.Real)
+	client := &http.Client{Transport: transport}
+
+	for i := range cacheMaxEntries + 10 {
+		getCached(t, client, downstream.URL()+"/cached/"+strconv.Itoa(i), nil)
+	}
+	assert.Equal(t, cacheMaxEntries, transport.entries.Len())
+}
+
+// getCached sends an authorized GET request to url with header and returns
+// the response body.
+func getCached(t *testing.T, client *http.Client, url string, header http.Header) string {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Authorization", "user-a")
+	res, err := client.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	return string(body)
+}
@@ -1,26 +1,212 @@
 package bff
 
 import (
+	"slices"
+	"time"
+
 	"github.com/cohesivestack/valgo"
 
 	"github.com/joshjon/kit/auth"
+	"github.com/joshjon/kit/proxy"
 	"github.com/joshjon/kit/valgoutil"
 )
 
 type RegisterConfig struct {
-	DownstreamURL string             `yaml:"downstreamURL" env:"DOWNSTREAM_URL"`
-	OIDCProvider  OIDCProviderConfig `yaml:"oidcProvider" envPrefix:"OIDC_PROVIDER_"`
+	Downstreams  []DownstreamConfig `yaml:"downstreams" envPrefix:"DOWNSTREAMS_"`
+	OIDCProvider OIDCProviderConfig `yaml:"oidcProvider" envPrefix:"OIDC_PROVIDER_"`
+	RateLimit    *RateLimitConfig   `yaml:"rateLimit" envPrefix:"RATE_LIMIT_"` // optional per-user rate limiting
+
+	// Deprecated: Use Downstreams. DownstreamURL adds a downstream named
+	// default serving the paths of the OIDC provider audiences, or all paths
+	// if there are none.
+	DownstreamURL string `yaml:"downstreamURL" env:"DOWNSTREAM_URL"`
 }
 
 func (c *RegisterConfig) InitDefaults() {}
 
 func (c *RegisterConfig) Validation() *valgo.Validation {
 	v := valgo.New()
-	v.Is(valgoutil.URLValidator(c.DownstreamURL, "downstreamURL"))
+	if c.DownstreamURL != "" {
+		v.Is(valgoutil.URLValidator(c.DownstreamURL, "downstreamURL"))
+	} else {
+		v.Is(valgoutil.NonEmptySliceValidator(c.Downstreams, "downstreams"))
+	}
+
+	audiences := map[string]struct{}{}
+	for _, aud := range c.OIDCProvider.Audiences {
+		audiences[aud.Name] = struct{}{}
+	}
+	for i, ds := range c.Downstreams {
+		dv := ds.Validation()
+		if ds.Audience != "" {
+			_, ok := audiences[ds.Audience]
+			dv.Is(valgo.Bool(ok, "audience").True("must match the name of an oidcProvider audience"))
+		}
+		v.InRow("downstreams", i, dv)
+	}
+
 	v.In("oidcProvider", c.OIDCProvider.Validation())
+	if c.RateLimit != nil {
+		v.In("rateLimit", c.RateLimit.Validation())
+	}
+	return v
+}
+
+// DownstreamConfigs returns the downstreams of the config, including the
+// downstream of the deprecated DownstreamURL.
+func (c *RegisterConfig) DownstreamConfigs() []DownstreamConfig {
+	if c.DownstreamURL == "" {
+		return c.Downstreams
+	}
+	var pathPrefixes []string
+	for _, aud := range c.OIDCProvider.Audiences {
+		pathPrefixes = append(pathPrefixes, aud.Path)
+	}
+	if len(pathPrefixes) == 0 {
+		pathPrefixes = []string{"/"}
+	}
+	return append(slices.Clone(c.Downstreams), DownstreamConfig{
+		Name:         defaultDownstreamName,
+		URL:          c.DownstreamURL,
+		PathPrefixes: pathPrefixes,
+	})
+}
+
+// AudiencePaths returns the path prefixes of all OIDC provider audiences and
+// downstreams mapped to their audience name, for use with
+// auth.PathBearerTokenMiddleware.
+func (c *RegisterConfig) AudiencePaths() map[string]string {
+	pathAuds := map[string]string{}
+	for _, aud := range c.OIDCProvider.Audiences {
+		pathAuds[aud.Path] = aud.Name
+	}
+	for _, ds := range c.DownstreamConfigs() {
+		if ds.Audience == "" {
+			continue
+		}
+		for _, prefix := range ds.PathPrefixes {
+			pathAuds[prefix] = ds.Audience
+		}
+	}
+	return pathAuds
+}
+
+// DownstreamConfig configures a backend API proxied by the BFF. Requests
+// matching any of the path prefixes are forwarded to the downstream URL.
+type DownstreamConfig struct {
+	Name          string                  `yaml:"name" env:"NAME"`
+	URL           string                  `yaml:"url" env:"URL"`
+	PathPrefixes  []string                `yaml:"pathPrefixes" env:"PATH_PREFIXES"`
+	Audience      string                  `yaml:"audience" env:"AUDIENCE"`            // optional OIDC audience name
+	TLS           *DownstreamTLSConfig    `yaml:"tls" envPrefix:"TLS_"`               // optional mTLS
+	Cache         []CacheRouteConfig      `yaml:"cache" envPrefix:"CACHE_"`           // optional GET response caching
+	Health        *DownstreamHealthConfig `yaml:"health" envPrefix:"HEALTH_"`         // optional health gating
+	StripPrefix   bool                    `yaml:"stripPrefix" env:"STRIP_PREFIX"`     // strip the matched path prefix before proxying
+	Rewrites      []RewriteConfig         `yaml:"rewrites" envPrefix:"REWRITES_"`     // optional path rewrites
+	Retry         *RetryConfig            `yaml:"retry" envPrefix:"RETRY_"`           // optional retries of idempotent requests
+	Targets       []string                `yaml:"targets" env:"TARGETS"`              // optional replica URLs balanced with URL
+	Balance       string                  `yaml:"balance" env:"BALANCE"`              // roundRobin (default) or leastConnections
+	TimeoutMillis int                     `yaml:"timeoutMillis" env:"TIMEOUT_MILLIS"` // optional upstream request timeout
+}
+
+func (c *DownstreamConfig) Validation() *valgo.Validation {
+	v := valgo.New()
+	v.Is(
+		valgo.String(c.Name, "name").Not().Blank(),
+		valgoutil.URLValidator(c.URL, "url"),
+		valgoutil.NonEmptySliceValidator(c.PathPrefixes, "pathPrefixes"),
+	)
+	for i, prefix := range c.PathPrefixes {
+		v.InRow("pathPrefixes", i, valgo.Is(valgo.String(prefix, "pathPrefix").Not().Blank()))
+	}
+	if c.TLS != nil {
+		v.In("tls", c.TLS.Validation())
+	}
+	for i, route := range c.Cache {
+		v.InRow("cache", i, route.Validation())
+	}
+	if c.Health != nil {
+		v.In("health", c.Health.Validation())
+	}
+	for i, rewrite := range c.Rewrites {
+		v.InRow("rewrites", i, rewrite.Validation())
+	}
+	if c.Retry != nil {
+		v.In("retry", c.Retry.Validation())
+	}
+	for i, target := range c.Targets {
+		v.InRow("targets", i, valgo.Is(valgoutil.URLValidator(target, "target")))
+	}
+	v.Is(valgo.Int(c.TimeoutMillis, "timeoutMillis").GreaterOrEqualTo(0))
+	if c.Balance != "" {
+		v.Is(valgoutil.OneOfValidator(c.Balance, []string{balanceRoundRobin, balanceLeastConnections}, "balance"))
+	}
 	return v
 }
 
+const (
+	// defaultDownstreamName is the name of the downstream of the deprecated
+	// RegisterConfig.DownstreamURL.
+	defaultDownstreamName = "default"
+
+	balanceRoundRobin       = "roundRobin"
+	balanceLeastConnections = "leastConnections"
+)
+
+func (c *DownstreamConfig) proxyOptions() []proxy.ReverseProxyOption {
+	var opts []proxy.ReverseProxyOption
+	if c.StripPrefix {
+		opts = append(opts, proxy.WithStripPrefix())
+	}
+	if len(c.Targets) > 0 {
+		opts = append(opts, proxy.WithTargets(c.Targets...))
+	}
+	if c.Balance == balanceLeastConnections {
+		opts = append(opts, proxy.WithBalanceStrategy(proxy.LeastConnections))
+	}
+	if c.TimeoutMillis > 0 {
+		opts = append(opts, proxy.WithTimeout(time.Duration(c.TimeoutMillis)*time.Millisecond))
+	}
+	for _, rewrite := range c.Rewrites {
+		if rewrite.Regex {
+			opts = append(opts, proxy.WithRegexRewrite(rewrite.From, rewrite.To))
+		} else {
+			opts = append(opts, proxy.WithRewrite(rewrite.From, rewrite.To))
+		}
+	}
+	return opts
+}
+
+// RewriteConfig rewrites downstream request paths matching From to To. By
+// default From and To are wildcard templates (e.g. /api/v1/* → /v1/*). With
+// Regex, From is a regular expression and To may reference its capture
+// groups (e.g. $1).
+type RewriteConfig struct {
+	From  string `yaml:"from" env:"FROM"`
+	To    string `yaml:"to" env:"TO"`
+	Regex bool   `yaml:"regex" env:"REGEX"`
+}
+
+func (c *RewriteConfig) Validation() *valgo.Validation {
+	return valgo.Is(valgo.String(c.From, "from").Not().Blank())
+}
+
+// DownstreamTLSConfig configures the client certificate and CA used to
+// connect to a downstream over mTLS.
+type DownstreamTLSConfig struct {
+	CertFile   string `yaml:"certFile" env:"CERT_FILE"`
+	KeyFile    string `yaml:"keyFile" env:"KEY_FILE"`
+	CACertFile string `yaml:"caCertFile" env:"CA_CERT_FILE"`
+}
+
+func (c *DownstreamTLSConfig) Validation() *valgo.Validation {
+	return valgo.Is(
+		valgoutil.FilePathValidator(c.CertFile, true, "certFile"),
+		valgoutil.FilePathValidator(c.KeyFile, true, "keyFile"),
+		valgoutil.FilePathValidator(c.CACertFile, true, "caCertFile"),
+	)
+}
+
 type OIDCProviderConfig struct {
 	Endpoint  string                         `yaml:"endpoint" env:"ENDPOINT"`
 	AppID     string                         `yaml:"appId" env:"APP_ID"`
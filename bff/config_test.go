@@ -0,0 +1,51 @@
+package bff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterConfig_AudiencePaths(t *testing.T) {
+	cfg := RegisterConfig{
+		OIDCProvider: OIDCProviderConfig{
+			Audiences: []OIDCProviderAudienceScopes{{Name: "https://api.example.com", Path: "/api"}},
+		},
+		Downstreams: []DownstreamConfig{
+			{Name: "billing", PathPrefixes: []string{"/api/billing", "/invoices"}, Audience: "https://billing.example.com"},
+			{Name: "public", PathPrefixes: []string{"/public"}},
+		},
+	}
+	assert.Equal(t, map[string]string{
+		"/api":         "https://api.example.com",
+		"/api/billing": "https://billing.example.com",
+		"/invoices":    "https://billing.example.com",
+	}, cfg.AudiencePaths())
+}
+
+func TestRegisterConfig_DownstreamURL(t *testing.T) {
+	cfg := RegisterConfig{
+		DownstreamURL: "http://api:8080",
+		OIDCProvider: OIDCProviderConfig{
+			Endpoint:  "http://logto:3001",
+			AppID:     "app",
+			AppSecret: "secret",
+			Audiences: []OIDCProviderAudienceScopes{{Name: "https://api.example.com", Path: "/api"}},
+		},
+	}
+	assert.Equal(t, []DownstreamConfig{{
+		Name:         defaultDownstreamName,
+		URL:          "http://api:8080",
+		PathPrefixes: []string{"/api"},
+	}}, cfg.DownstreamConfigs())
+	errs := cfg.Validation().Errors()
+	assert.NotContains(t, errs, "downstreams")
+	assert.NotContains(t, errs, "downstreamURL")
+
+	cfg.OIDCProvider.Audiences = nil
+	assert.Equal(t, []string{"/"}, cfg.DownstreamConfigs()[0].PathPrefixes)
+
+	cfg.DownstreamURL = ""
+	assert.Empty(t, cfg.DownstreamConfigs())
+	assert.Contains(t, cfg.Validation().Errors(), "downstreams")
+}
@@ -0,0 +1,209 @@
+package bff
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/cohesivestack/valgo"
+	"github.com/labstack/echo/v4"
+
+	"github.com/joshjon/kit/clock"
+	"github.com/joshjon/kit/errtag"
+	"github.com/joshjon/kit/health"
+	"github.com/joshjon/kit/log"
+	"github.com/joshjon/kit/retry"
+)
+
+const (
+	defaultHealthPath            = "/healthz"
+	defaultHealthMaxRetries      = 15
+	defaultHealthIntervalSeconds = 1
+	healthProbeTimeout           = 5 * time.Second
+)
+
+// DownstreamHealthConfig configures health gating of a downstream. By default
+// registration blocks until the downstream is healthy. When Lazy is set routes
+// are registered immediately and respond with 503 until the downstream becomes
+// healthy, which allows the BFF to boot before its backends. Lazy downstreams
+// are probed in the background until they become healthy, starting over once
+// the retries are exhausted.
+//
+// Zero values use the defaults: path /healthz, 15 retries and a 1s interval.
+type DownstreamHealthConfig struct {
+	Path                   string `yaml:"path" env:"PATH"`
+	MaxRetries             int    `yaml:"maxRetries" env:"MAX_RETRIES"` // -1 disables retries
+	IntervalSeconds        int    `yaml:"intervalSeconds" env:"INTERVAL_SECONDS"`
+	MaxIntervalSeconds     int    `yaml:"maxIntervalSeconds" env:"MAX_INTERVAL_SECONDS"` // enables exponential backoff when greater than IntervalSeconds
+	Lazy                   bool   `yaml:"lazy" env:"LAZY"`
+	RecheckIntervalSeconds int    `yaml:"recheckIntervalSeconds" env:"RECHECK_INTERVAL_SECONDS"` // 0 disables background re-checks
+}
+
+func (c *DownstreamHealthConfig) Validation() *valgo.Validation {
+	return valgo.Is(
+		valgo.Int(c.MaxRetries, "maxRetries").GreaterOrEqualTo(-1),
+		valgo.Int(c.IntervalSeconds, "intervalSeconds").GreaterOrEqualTo(0),
+		valgo.Int(c.MaxIntervalSeconds, "maxIntervalSeconds").GreaterOrEqualTo(0),
+		valgo.Int(c.RecheckIntervalSeconds, "recheckIntervalSeconds").GreaterOrEqualTo(0),
+	)
+}
+
+func (c DownstreamHealthConfig) withDefaults() DownstreamHealthConfig {
+	if c.Path == "" {
+		c.Path = defaultHealthPath
+	}
+	switch {
+	case c.MaxRetries == 0:
+		c.MaxRetries = defaultHealthMaxRetries
+	case c.MaxRetries < 0:
+		c.MaxRetries = 0
+	}
+	if c.IntervalSeconds == 0 {
+		c.IntervalSeconds = defaultHealthIntervalSeconds
+	}
+	return c
+}
+
+func (c *DownstreamHealthConfig) retryPolicy(clk clock.Clock) retry.Policy {
+	interval := time.Duration(c.IntervalSeconds) * time.Second
+	maxInterval := time.Duration(c.MaxIntervalSeconds) * time.Second
+
+	policy := retry.Policy{
+		MaxAttempts:     c.MaxRetries + 1,
+		InitialInterval: interval,
+		MaxInterval:     maxInterval,
+		Clock:           clk,
+	}
+	if maxInterval <= interval {
+		policy.MaxInterval = interval
+		policy.Jitter = -1 // constant interval
+	}
+	return policy
+}
+
+// downstreamHealth tracks the health of a single downstream.
+type downstreamHealth struct {
+	name    string
+	cfg     DownstreamHealthConfig
+	checker health.Checker
+	logger  log.Logger
+	clock   clock.Clock
+	healthy atomic.Bool
+}
+
+func newDownstreamHealth(ds DownstreamConfig, transport http.RoundTripper, logger log.Logger, clk clock.Clock) *downstreamHealth {
+	cfg := ds.Health.withDefaults()
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   healthProbeTimeout,
+	}
+	return &downstreamHealth{
+		name:    ds.Name,
+		cfg:     cfg,
+		checker: health.HTTPChecker(strings.TrimSuffix(ds.URL, "/")+cfg.Path, client),
+		logger:  logger.With("downstream", ds.Name),
+		clock:   clk,
+	}
+}
+
+// start gates the downstream according to its config. It blocks until the
+// downstream is healthy unless the config is lazy, in which case waiting
+// continues in the background until it is healthy.
+func (h *downstreamHealth) start(ctx context.Context) error {
+	if !h.cfg.Lazy {
+		if err := h.wait(ctx); err != nil {
+			return err
+		}
+		go h.recheck(ctx)
+		return nil
+	}
+
+	go func() {
+		if h.waitUntilHealthy(ctx) {
+			h.recheck(ctx)
+		}
+	}()
+	return nil
+}
+
+// waitUntilHealthy waits until the downstream is healthy, starting over once
+// the retries are exhausted, so a lazy downstream never stays gated if re-checks
+// are disabled. It returns false once ctx is done.
+func (h *downstreamHealth) waitUntilHealthy(ctx context.Context) bool {
+	for {
+		err := h.wait(ctx)
+		if err == nil {
+			return true
+		}
+		if ctx.Err() != nil {
+			return false
+		}
+		h.logger.Error("downstream unhealthy", "error", err)
+
+		timer := h.clock.NewTimer(time.Duration(h.cfg.IntervalSeconds) * time.Second)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return false
+		case <-timer.C():
+		}
+	}
+}
+
+func (h *downstreamHealth) wait(ctx context.Context) error {
+	h.logger.Info("waiting for downstream to be healthy")
+	policy := h.cfg.retryPolicy(h.clock)
+	policy.OnRetry = func(attempt int, err error, backoff time.Duration) {
+		h.logger.Debug("downstream not healthy yet", "attempt", attempt, "error", err, "backoff", backoff)
+	}
+	if err := retry.DoErr(ctx, policy, h.probe); err != nil {
+		return fmt.Errorf("downstream %s unhealthy: %w", h.name, err)
+	}
+	h.healthy.Store(true)
+	h.logger.Info("downstream healthy")
+	return nil
+}
+
+func (h *downstreamHealth) recheck(ctx context.Context) {
+	if h.cfg.RecheckIntervalSeconds <= 0 {
+		return
+	}
+	ticker := h.clock.NewTicker(time.Duration(h.cfg.RecheckIntervalSeconds) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+			err := h.probe(ctx)
+			healthy := err == nil
+			if h.healthy.Swap(healthy) != healthy {
+				if healthy {
+					h.logger.Info("downstream recovered")
+				} else {
+					h.logger.Warn("downstream became unhealthy", "error", err)
+				}
+			}
+		}
+	}
+}
+
+func (h *downstreamHealth) probe(ctx context.Context) error {
+	if err := h.checker.Check(ctx); err != nil {
+		return fmt.Errorf("health check: %w", err)
+	}
+	return nil
+}
+
+// middleware responds with 503 while the downstream is unhealthy.
+func (h *downstreamHealth) middleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if !h.healthy.Load() {
+			return errtag.NewTagged[errtag.Unavailable]("downstream unhealthy: " + h.name)
+		}
+		return next(c)
+	}
+}
@@ -0,0 +1,137 @@
+package bff
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/errtag"
+	"github.com/joshjon/kit/log"
+	"github.com/joshjon/kit/testutil"
+)
+
+type healthTestDownstream struct {
+	healthy atomic.Bool
+	url     string
+}
+
+func startHealthTestDownstream(t *testing.T, healthy bool) *healthTestDownstream {
+	ds := &healthTestDownstream{}
+	ds.healthy.Store(healthy)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !ds.healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+	ds.url = srv.URL
+	return ds
+}
+
+func newTestDownstreamHealth(ds *healthTestDownstream, cfg DownstreamHealthConfig, clk *testutil.FakeClock) *downstreamHealth {
+	return newDownstreamHealth(
+		DownstreamConfig{Name: "api", URL: ds.url, Health: &cfg},
+		http.DefaultTransport,
+		log.NewLogger(log.WithNop()),
+		clk,
+	)
+}
+
+// gated reports whether the health middleware rejects requests with
+// errtag.Unavailable.
+func gated(t *testing.T, h *downstreamHealth) bool {
+	t.Helper()
+	err := h.middleware(func(c echo.Context) error {
+		return nil
+	})(echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder()))
+	if err == nil {
+		return false
+	}
+	tag, ok := errtag.Primary(err)
+	require.True(t, ok)
+	require.Equal(t, http.StatusServiceUnavailable, tag.Code())
+	return true
+}
+
+// advanceUntil advances clk by a second until cond is true.
+func advanceUntil(t *testing.T, clk *testutil.FakeClock, cond func() bool) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		clk.Advance(time.Second)
+		return cond()
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestDownstreamHealth_eager(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	clk := testutil.NewFakeClock(time.Now())
+
+	h := newTestDownstreamHealth(startHealthTestDownstream(t, true), DownstreamHealthConfig{}, clk)
+	require.NoError(t, h.start(ctx))
+	assert.False(t, gated(t, h))
+
+	// Without retries start fails on the first failed probe.
+	h = newTestDownstreamHealth(startHealthTestDownstream(t, false), DownstreamHealthConfig{MaxRetries: -1}, clk)
+	require.Error(t, h.start(ctx))
+	assert.True(t, gated(t, h))
+}
+
+func TestDownstreamHealth_lazy(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	clk := testutil.NewFakeClock(time.Now())
+
+	ds := startHealthTestDownstream(t, false)
+	h := newTestDownstreamHealth(ds, DownstreamHealthConfig{Lazy: true, MaxRetries: 1}, clk)
+	require.NoError(t, h.start(ctx))
+	assert.True(t, gated(t, h))
+
+	// Probing continues after the retries are exhausted, even without
+	// re-checks.
+	advanceUntil(t, clk, func() bool { return clk.Waiters() > 0 })
+	for range 5 {
+		clk.Advance(time.Second)
+	}
+	assert.True(t, gated(t, h))
+
+	ds.healthy.Store(true)
+	advanceUntil(t, clk, func() bool { return !gated(t, h) })
+}
+
+func TestDownstreamHealth_recheck(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	clk := testutil.NewFakeClock(time.Now())
+
+	ds := startHealthTestDownstream(t, true)
+	h := newTestDownstreamHealth(ds, DownstreamHealthConfig{RecheckIntervalSeconds: 1}, clk)
+	require.NoError(t, h.start(ctx))
+	assert.False(t, gated(t, h))
+
+	ds.healthy.Store(false)
+	advanceUntil(t, clk, func() bool { return gated(t, h) })
+
+	ds.healthy.Store(true)
+	advanceUntil(t, clk, func() bool { return !gated(t, h) })
+}
+
+func TestDownstreamHealthConfig_withDefaults(t *testing.T) {
+	cfg := DownstreamHealthConfig{}.withDefaults()
+	assert.Equal(t, defaultHealthPath, cfg.Path)
+	assert.Equal(t, defaultHealthMaxRetries, cfg.MaxRetries)
+	assert.Equal(t, defaultHealthIntervalSeconds, cfg.IntervalSeconds)
+
+	cfg = DownstreamHealthConfig{MaxRetries: -1}.withDefaults()
+	assert.Equal(t, 0, cfg.MaxRetries)
+	assert.Equal(t, 1, cfg.retryPolicy(nil).MaxAttempts)
+}
@@ -0,0 +1,148 @@
+package bff
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/joshjon/kit/log"
+)
+
+// DownstreamMetrics records metrics of requests proxied to downstreams.
+// Implementations must be safe for concurrent use.
+type DownstreamMetrics interface {
+	// ObserveRequest records a completed downstream request. Status is 0 when
+	// the request failed without a response.
+	ObserveRequest(downstream string, method string, status int, latency time.Duration)
+	// ObserveRetry records a retried downstream request.
+	ObserveRetry(downstream string)
+}
+
+// DownstreamStats is an in-memory DownstreamMetrics implementation.
+type DownstreamStats struct {
+	mu    sync.Mutex
+	stats map[string]*DownstreamStat
+}
+
+// DownstreamStat holds the recorded metrics of a single downstream.
+type DownstreamStat struct {
+	Requests     int64
+	Retries      int64
+	StatusCounts map[string]int64 // keyed by status class (e.g. 2xx) or "error"
+	TotalLatency time.Duration
+	MaxLatency   time.Duration
+}
+
+var _ DownstreamMetrics = (*DownstreamStats)(nil)
+
+func NewDownstreamStats() *DownstreamStats {
+	return &DownstreamStats{
+		stats: map[string]*DownstreamStat{},
+	}
+}
+
+func (s *DownstreamStats) ObserveRequest(downstream string, _ string, status int, latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stat := s.getLocked(downstream)
+	stat.Requests++
+	stat.StatusCounts[statusClass(status)]++
+	stat.TotalLatency += latency
+	stat.MaxLatency = max(stat.MaxLatency, latency)
+}
+
+func (s *DownstreamStats) ObserveRetry(downstream string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.getLocked(downstream).Retries++
+}
+
+// Snapshot returns a copy of the recorded metrics keyed by downstream name.
+func (s *DownstreamStats) Snapshot() map[string]DownstreamStat {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[string]DownstreamStat, len(s.stats))
+	for name, stat := range s.stats {
+		cpy := *stat
+		cpy.StatusCounts = make(map[string]int64, len(stat.StatusCounts))
+		for class, count := range stat.StatusCounts {
+			cpy.StatusCounts[class] = count
+		}
+		out[name] = cpy
+	}
+	return out
+}
+
+func (s *DownstreamStats) getLocked(downstream string) *DownstreamStat {
+	stat, ok := s.stats[downstream]
+	if !ok {
+		stat = &DownstreamStat{StatusCounts: map[string]int64{}}
+		s.stats[downstream] = stat
+	}
+	return stat
+}
+
+func statusClass(status int) string {
+	if status <= 0 {
+		return "error"
+	}
+	return strconv.Itoa(status/100) + "xx"
+}
+
+// instrumentedTransport logs and records metrics for every request sent to a
+// downstream.
+type instrumentedTransport struct {
+	name    string
+	base    http.RoundTripper
+	logger  log.Logger
+	metrics DownstreamMetrics
+}
+
+func newInstrumentedTransport(name string, base http.RoundTripper, logger log.Logger, metrics DownstreamMetrics) *instrumentedTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &instrumentedTransport{
+		name:    name,
+		base:    base,
+		logger:  logger.With("downstream", name),
+		metrics: metrics,
+	}
+}
+
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	res, err := t.base.RoundTrip(req)
+	latency := time.Since(start)
+
+	status := 0
+	if res != nil {
+		status = res.StatusCode
+	}
+
+	if t.metrics != nil {
+		t.metrics.ObserveRequest(t.name, req.Method, status, latency)
+	}
+
+	args := []any{
+		"method", req.Method,
+		"uri", req.URL.RequestURI(),
+		"status", status,
+		"latency_ms", latency.Milliseconds(),
+	}
+	level := slog.LevelInfo
+	message := "downstream request"
+	switch {
+	case err != nil:
+		level = slog.LevelError
+		message = "downstream request error"
+		args = append(args, "error", err.Error())
+	case status >= http.StatusInternalServerError:
+		level = slog.LevelWarn
+	}
+	t.logger.Log(req.Context(), level, message, args...)
+
+	return res, err
+}
@@ -0,0 +1,113 @@
+package bff
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/log"
+)
+
+type observedRequest struct {
+	downstream string
+	method     string
+	status     int
+}
+
+type recordingMetrics struct {
+	mu       sync.Mutex
+	requests []observedRequest
+	retries  []string
+}
+
+func (m *recordingMetrics) ObserveRequest(downstream string, method string, status int, _ time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests = append(m.requests, observedRequest{downstream: downstream, method: method, status: status})
+}
+
+func (m *recordingMetrics) ObserveRetry(downstream string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.retries = append(m.retries, downstream)
+}
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestInstrumentedTransport(t *testing.T) {
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer downstream.Close()
+
+	metrics := &recordingMetrics{}
+	client := &http.Client{Transport: newInstrumentedTransport("users", nil, log.NewLogger(log.WithNop()), metrics)}
+
+	res, err := client.Post(downstream.URL+"/users", "application/json", nil)
+	require.NoError(t, err)
+	res.Body.Close()
+	res, err = client.Get(downstream.URL + "/fail")
+	require.NoError(t, err)
+	res.Body.Close()
+
+	errTransport := roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	})
+	client.Transport = newInstrumentedTransport("projects", errTransport, log.NewLogger(log.WithNop()), metrics)
+	_, err = client.Get(downstream.URL + "/projects")
+	require.Error(t, err)
+
+	assert.Equal(t, []observedRequest{
+		{downstream: "users", method: http.MethodPost, status: http.StatusCreated},
+		{downstream: "users", method: http.MethodGet, status: http.StatusBadGateway},
+		{downstream: "projects", method: http.MethodGet, status: 0},
+	}, metrics.requests)
+}
+
+func TestRetryConfig_policyObservesRetries(t *testing.T) {
+	metrics := &recordingMetrics{}
+	cfg := RetryConfig{}
+	policy := cfg.policy("users", log.NewLogger(log.WithNop()), metrics)
+
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	policy.OnRetry(req, 1, errors.New("status 503"))
+	policy.OnRetry(req, 2, errors.New("status 503"))
+	assert.Equal(t, []string{"users", "users"}, metrics.retries)
+}
+
+func TestDownstreamStats(t *testing.T) {
+	stats := NewDownstreamStats()
+	stats.ObserveRequest("users", http.MethodGet, http.StatusOK, 10*time.Millisecond)
+	stats.ObserveRequest("users", http.MethodGet, http.StatusNoContent, 30*time.Millisecond)
+	stats.ObserveRequest("users", http.MethodPost, http.StatusServiceUnavailable, 20*time.Millisecond)
+	stats.ObserveRequest("projects", http.MethodGet, 0, time.Millisecond)
+	stats.ObserveRetry("users")
+
+	snapshot := stats.Snapshot()
+	assert.Equal(t, DownstreamStat{
+		Requests:     3,
+		Retries:      1,
+		StatusCounts: map[string]int64{"2xx": 2, "5xx": 1},
+		TotalLatency: 60 * time.Millisecond,
+		MaxLatency:   30 * time.Millisecond,
+	}, snapshot["users"])
+	assert.Equal(t, map[string]int64{"error": 1}, snapshot["projects"].StatusCounts)
+
+	// Snapshots are copies.
+	snapshot["users"].StatusCounts["2xx"] = 100
+	assert.Equal(t, int64(2), stats.Snapshot()["users"].StatusCounts["2xx"])
+}
@@ -19,5 +19,6 @@ func NewLogtoOIDCProviderInitializer(oidcCfg OIDCProviderConfig) auth.OIDCProvid
 		ltCfg.Scopes = append(ltCfg.Scopes, aud.Scopes...)
 	}
 
-	return logto.OIDCProviderInitializer(ltCfg)
+	// The cache is shared by the clients of all requests.
+	return logto.OIDCProviderInitializer(ltCfg, logto.WithTokenCache(logto.NewTokenCache()))
 }
@@ -0,0 +1,79 @@
+package bff
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/cohesivestack/valgo"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"golang.org/x/time/rate"
+
+	"github.com/joshjon/kit/auth"
+	"github.com/joshjon/kit/errtag"
+	"github.com/joshjon/kit/jwt"
+)
+
+// RateLimitConfig configures per-user rate limiting of proxied requests using
+// a token bucket per user. Users are identified by their verified subject or
+// their session, falling back to their IP address.
+type RateLimitConfig struct {
+	RequestsPerSecond float64 `yaml:"requestsPerSecond" env:"REQUESTS_PER_SECOND"`
+	Burst             int     `yaml:"burst" env:"BURST"`                         // at least 1
+	ExpiresInSeconds  int     `yaml:"expiresInSeconds" env:"EXPIRES_IN_SECONDS"` // idle time before a user's bucket is discarded
+}
+
+func (c *RateLimitConfig) Validation() *valgo.Validation {
+	return valgo.Is(
+		valgo.Float64(c.RequestsPerSecond, "requestsPerSecond").GreaterThan(0),
+		// A zero burst would reject every request.
+		valgo.Int(c.Burst, "burst").GreaterOrEqualTo(1),
+		valgo.Int(c.ExpiresInSeconds, "expiresInSeconds").GreaterOrEqualTo(0),
+	)
+}
+
+// RateLimitMiddleware limits requests per user. It must run after the OIDC
+// provider middleware so the session of the user can be used to identify
+// them. Bearer tokens sent by clients are not verified by the BFF, so they
+// are never used to identify users. Limited requests fail with
+// errtag.TooManyRequests and a Retry-After header.
+func RateLimitMiddleware(cfg RateLimitConfig) echo.MiddlewareFunc {
+	store := middleware.NewRateLimiterMemoryStoreWithConfig(middleware.RateLimiterMemoryStoreConfig{
+		Rate:      rate.Limit(cfg.RequestsPerSecond),
+		Burst:     cfg.Burst,
+		ExpiresIn: time.Duration(cfg.ExpiresInSeconds) * time.Second,
+	})
+	retryAfter := strconv.Itoa(int(math.Ceil(1 / cfg.RequestsPerSecond)))
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			allow, err := store.Allow(rateLimitIdentifier(c))
+			if err != nil {
+				return err
+			}
+			if !allow {
+				c.Response().Header().Set(echo.HeaderRetryAfter, retryAfter)
+				return errtag.NewTagged[errtag.TooManyRequests]("rate limit exceeded")
+			}
+			return next(c)
+		}
+	}
+}
+
+// rateLimitIdentifier identifies the user of a request by the subject
+// verified by jwt.ValidateMiddleware, their session ID, or their IP address.
+// Session IDs are only set for sessions the session store could load, so
+// forged session cookies fall back to the IP address too.
+func rateLimitIdentifier(c echo.Context) string {
+	if sub, err := jwt.AuthUserIDFromContext(c); err == nil && sub != "" {
+		return "sub:" + sub
+	}
+	if id := auth.SessionIDFromContext(c); id != "" {
+		sum := sha256.Sum256([]byte(id))
+		return "session:" + hex.EncodeToString(sum[:8])
+	}
+	return "ip:" + c.RealIP()
+}
@@ -0,0 +1,133 @@
+package bff
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/auth"
+	"github.com/joshjon/kit/errtag"
+	"github.com/joshjon/kit/session"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	const sessionName = "bff-session"
+	store, err := session.NewMemStore([]byte("01234567890123456789012345678901"))
+	require.NoError(t, err)
+
+	newSessionCookie := func(t *testing.T) *http.Cookie {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		s, err := store.Get(req, sessionName)
+		require.NoError(t, err)
+		s.Values["user"] = "user"
+		rec := httptest.NewRecorder()
+		require.NoError(t, s.Save(req, rec))
+		cookies := rec.Result().Cookies()
+		require.Len(t, cookies, 1)
+		return cookies[0]
+	}
+
+	h := auth.OIDCProviderMiddleware(auth.OIDCProviderConfig{
+		SessionName:     sessionName,
+		SessionStore:    store,
+		OIDCInitializer: func(*auth.SessionStorage) auth.OIDCProvider { return nil },
+	})(RateLimitMiddleware(RateLimitConfig{RequestsPerSecond: 0.1, Burst: 1})(func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}))
+
+	do := func(remoteAddr string, setup func(req *http.Request)) (*httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest(http.MethodGet, "/api/items", nil)
+		req.RemoteAddr = remoteAddr
+		if setup != nil {
+			setup(req)
+		}
+		rec := httptest.NewRecorder()
+		return rec, h(echo.New().NewContext(req, rec))
+	}
+
+	assertLimited := func(t *testing.T, rec *httptest.ResponseRecorder, err error) {
+		t.Helper()
+		tag, ok := errtag.Primary(err)
+		require.True(t, ok, "want tagged error, got %v", err)
+		assert.Equal(t, http.StatusTooManyRequests, tag.Code())
+		assert.Equal(t, "10", rec.Header().Get(echo.HeaderRetryAfter))
+	}
+
+	t.Run("ip", func(t *testing.T) {
+		_, err := do("10.0.0.1:1234", nil)
+		require.NoError(t, err)
+		rec, err := do("10.0.0.1:5678", nil)
+		assertLimited(t, rec, err)
+
+		_, err = do("10.0.0.2:1234", nil)
+		require.NoError(t, err)
+	})
+
+	t.Run("session", func(t *testing.T) {
+		userA, userB := newSessionCookie(t), newSessionCookie(t)
+		_, err := do("10.0.1.1:1234", func(req *http.Request) { req.AddCookie(userA) })
+		require.NoError(t, err)
+		// Users behind the same IP have their own buckets.
+		_, err = do("10.0.1.1:1234", func(req *http.Request) { req.AddCookie(userB) })
+		require.NoError(t, err)
+
+		rec, err := do("10.0.1.2:1234", func(req *http.Request) { req.AddCookie(userA) })
+		assertLimited(t, rec, err)
+	})
+
+	t.Run("forged bearer subjects share the ip bucket", func(t *testing.T) {
+		for i, sub := range []string{"user-a", "user-b"} {
+			rec, err := do("10.0.2.1:1234", func(req *http.Request) {
+				req.Header.Set(echo.HeaderAuthorization, "Bearer "+unsignedJWT(sub))
+			})
+			if i == 0 {
+				require.NoError(t, err)
+			} else {
+				assertLimited(t, rec, err)
+			}
+		}
+	})
+
+	t.Run("forged session cookies share the ip bucket", func(t *testing.T) {
+		for i, value := range []string{"forged-a", "forged-b"} {
+			rec, err := do("10.0.3.1:1234", func(req *http.Request) {
+				req.AddCookie(&http.Cookie{Name: sessionName, Value: value})
+			})
+			if i == 0 {
+				require.NoError(t, err)
+			} else {
+				assertLimited(t, rec, err)
+			}
+		}
+	})
+}
+
+func TestRateLimitIdentifier(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set(echo.HeaderAuthorization, "Bearer "+unsignedJWT("forged"))
+	c := echo.New().NewContext(req, httptest.NewRecorder())
+	assert.Equal(t, "ip:10.0.0.1", rateLimitIdentifier(c))
+
+	// Set by jwt.ValidateMiddleware once the token is verified.
+	c.Set("jwt-auth-user-id", "user-1")
+	assert.Equal(t, "sub:user-1", rateLimitIdentifier(c))
+}
+
+func TestRateLimitConfig_Validation(t *testing.T) {
+	cfg := RateLimitConfig{RequestsPerSecond: 1, Burst: 1}
+	assert.True(t, cfg.Validation().Valid())
+
+	cfg.Burst = 0
+	assert.False(t, cfg.Validation().Valid())
+}
+
+func unsignedJWT(sub string) string {
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString([]byte(`{"alg":"none"}`)) + "." + enc.EncodeToString([]byte(`{"sub":"`+sub+`"}`)) + ".sig"
+}
@@ -1,14 +1,20 @@
 package bff
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"slices"
 
 	"github.com/gin-contrib/sessions"
 	"github.com/labstack/echo/v4"
 
 	"github.com/joshjon/kit/auth"
+	"github.com/joshjon/kit/clock"
+	"github.com/joshjon/kit/log"
 	"github.com/joshjon/kit/proxy"
 	"github.com/joshjon/kit/server"
+	"github.com/joshjon/kit/trace"
 )
 
 type Registerer interface {
@@ -19,12 +25,137 @@ func RegisterAuthHandler(cfg OIDCProviderConfig, srv Registerer, sessionName str
 	srv.Register("/auth", auth.NewOIDCHandler(sessionName, "/auth", cfg.Redirects), middleware...)
 }
 
-func RegisterReverseProxyHandler(srv Registerer, client *http.Client, downstreamURL string, pathPrefixes []string, middleware ...echo.MiddlewareFunc) {
+func RegisterReverseProxyHandler(srv Registerer, client *http.Client, downstreamURL string, pathPrefixes []string, middleware ...echo.MiddlewareFunc) error {
+	h, err := proxy.NewReverseProxyHandler(client, downstreamURL)
+	if err != nil {
+		return err
+	}
+	registerPathPrefixes(srv, h, pathPrefixes, middleware...)
+	return nil
+}
+
+func registerPathPrefixes(srv Registerer, h server.Handler, pathPrefixes []string, middleware ...echo.MiddlewareFunc) {
 	for _, pathPrefix := range pathPrefixes {
-		srv.Register(pathPrefix, proxy.NewReverseProxyHandler(client, downstreamURL), middleware...)
+		srv.Register(pathPrefix, h, middleware...)
+	}
+}
+
+// DownstreamOption optionally configures RegisterDownstreams.
+type DownstreamOption func(opts *downstreamOptions)
+
+// WithDownstreamMiddleware adds middleware to every downstream route.
+func WithDownstreamMiddleware(middleware ...echo.MiddlewareFunc) DownstreamOption {
+	return func(opts *downstreamOptions) {
+		opts.middleware = append(opts.middleware, middleware...)
+	}
+}
+
+// WithDownstreamLogger sets the Logger used to log proxied requests.
+func WithDownstreamLogger(logger log.Logger) DownstreamOption {
+	return func(opts *downstreamOptions) {
+		opts.logger = logger
 	}
 }
 
+// WithDownstreamMetrics sets the DownstreamMetrics used to record proxied
+// requests.
+func WithDownstreamMetrics(metrics DownstreamMetrics) DownstreamOption {
+	return func(opts *downstreamOptions) {
+		opts.metrics = metrics
+	}
+}
+
+// WithProxyMetrics sets the proxy.Metrics used to record proxied requests
+// end-to-end, including retries and in-flight requests, whereas
+// DownstreamMetrics record each request attempt sent to a downstream.
+func WithProxyMetrics(metrics proxy.Metrics) DownstreamOption {
+	return func(opts *downstreamOptions) {
+		opts.proxyMetrics = metrics
+	}
+}
+
+// WithDownstreamClock sets the clock used for response cache expiry, health
+// checks and by the reverse proxies. Defaults to clock.Real.
+func WithDownstreamClock(clk clock.Clock) DownstreamOption {
+	return func(opts *downstreamOptions) {
+		opts.clock = clk
+	}
+}
+
+// WithDownstreamTracing traces requests sent to downstreams and propagates
+// the trace context to them. See trace.NewTransport.
+func WithDownstreamTracing() DownstreamOption {
+	return func(opts *downstreamOptions) {
+		opts.tracing = true
+	}
+}
+
+type downstreamOptions struct {
+	middleware   []echo.MiddlewareFunc
+	logger       log.Logger
+	metrics      DownstreamMetrics
+	proxyMetrics proxy.Metrics
+	clock        clock.Clock
+	tracing      bool
+}
+
+// RegisterDownstreams registers a reverse proxy handler for each path prefix of
+// every downstream. Each downstream gets its own HTTP client so TLS and cache
+// settings are not shared between downstreams. Proxied requests are logged
+// with the downstream name.
+//
+// Downstreams with health gating configured are waited on before registration
+// (or gated with 503 responses when lazy). Background health checks run until
+// ctx is done.
+func RegisterDownstreams(ctx context.Context, srv Registerer, downstreams []DownstreamConfig, opts ...DownstreamOption) error {
+	options := downstreamOptions{
+		logger: log.NewLogger(),
+		clock:  clock.Real,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	for _, ds := range downstreams {
+		client, err := createHTTPClient(ds.TLS)
+		if err != nil {
+			return fmt.Errorf("create http client for downstream %s: %w", ds.Name, err)
+		}
+
+		middleware := options.middleware
+		if ds.Health != nil {
+			health := newDownstreamHealth(ds, client.Transport, options.logger, options.clock)
+			if err = health.start(ctx); err != nil {
+				return err
+			}
+			middleware = append(slices.Clone(middleware), health.middleware)
+		}
+
+		// Instrument below the cache so only requests that actually reach the
+		// downstream are recorded.
+		if options.tracing {
+			client.Transport = trace.NewTransport(client.Transport)
+		}
+		client.Transport = newInstrumentedTransport(ds.Name, client.Transport, options.logger, options.metrics)
+		if len(ds.Cache) > 0 {
+			client.Transport = newCachingTransport(client.Transport, ds.Cache, options.clock)
+		}
+		proxyOpts := append(ds.proxyOptions(), proxy.WithName(ds.Name), proxy.WithLogger(options.logger), proxy.WithClock(options.clock))
+		if ds.Retry != nil {
+			proxyOpts = append(proxyOpts, proxy.WithRetry(ds.Retry.policy(ds.Name, options.logger, options.metrics)))
+		}
+		if options.proxyMetrics != nil {
+			proxyOpts = append(proxyOpts, proxy.WithMetrics(options.proxyMetrics))
+		}
+		h, err := proxy.NewReverseProxyHandler(client, ds.URL, proxyOpts...)
+		if err != nil {
+			return fmt.Errorf("create proxy for downstream %s: %w", ds.Name, err)
+		}
+		registerPathPrefixes(srv, h, ds.PathPrefixes, middleware...)
+	}
+	return nil
+}
+
 func NewMiddleware(
 	audScopes []OIDCProviderAudienceScopes,
 	provInit auth.OIDCProviderInitializer,
@@ -32,17 +163,39 @@ func NewMiddleware(
 	sessionStore sessions.Store,
 	sessionStorageOpts ...auth.SessionStorageOption,
 ) []echo.MiddlewareFunc {
-	audPaths := map[string]string{}
+	pathAuds := map[string]string{}
 	for _, aud := range audScopes {
-		audPaths[aud.Name] = aud.Path
+		pathAuds[aud.Path] = aud.Name
 	}
+	return newMiddleware(pathAuds, provInit, sessionName, sessionStore, sessionStorageOpts...)
+}
+
+// NewDownstreamMiddleware is like NewMiddleware but resolves the token audience
+// from both the OIDC provider audiences and the downstream audience mappings
+// in the config.
+func NewDownstreamMiddleware(
+	cfg RegisterConfig,
+	provInit auth.OIDCProviderInitializer,
+	sessionName string,
+	sessionStore sessions.Store,
+	sessionStorageOpts ...auth.SessionStorageOption,
+) []echo.MiddlewareFunc {
+	return newMiddleware(cfg.AudiencePaths(), provInit, sessionName, sessionStore, sessionStorageOpts...)
+}
 
+func newMiddleware(
+	pathAuds map[string]string,
+	provInit auth.OIDCProviderInitializer,
+	sessionName string,
+	sessionStore sessions.Store,
+	sessionStorageOpts ...auth.SessionStorageOption,
+) []echo.MiddlewareFunc {
 	return []echo.MiddlewareFunc{
 		auth.OIDCProviderMiddleware(auth.OIDCProviderConfig{
 			SessionName:     sessionName,
 			SessionStore:    sessionStore,
 			OIDCInitializer: provInit,
 		}, sessionStorageOpts...),
-		auth.BearerTokenMiddleware(audPaths, "/healthz", "/auth"),
+		auth.PathBearerTokenMiddleware(pathAuds, "/healthz", "/auth"),
 	}
 }
@@ -0,0 +1,59 @@
+package bff
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/cohesivestack/valgo"
+
+	"github.com/joshjon/kit/log"
+	"github.com/joshjon/kit/proxy"
+)
+
+// RetryConfig configures retries of downstream requests that fail with a
+// connection error or a 502, 503 or 504 response, to smooth over downstream
+// rolling restarts. GET and HEAD requests are retried along with any
+// additional Methods opted in.
+//
+// Zero values use the defaults: 2 retries, backoff from 100ms up to 1s and a
+// retry budget of 20% of requests.
+type RetryConfig struct {
+	MaxRetries       int      `yaml:"maxRetries" env:"MAX_RETRIES"`
+	BackoffMillis    int      `yaml:"backoffMillis" env:"BACKOFF_MILLIS"`
+	MaxBackoffMillis int      `yaml:"maxBackoffMillis" env:"MAX_BACKOFF_MILLIS"`
+	Methods          []string `yaml:"methods" env:"METHODS"`
+	BudgetRatio      float64  `yaml:"budgetRatio" env:"BUDGET_RATIO"`
+}
+
+func (c *RetryConfig) Validation() *valgo.Validation {
+	return valgo.Is(
+		valgo.Int(c.MaxRetries, "maxRetries").GreaterOrEqualTo(0),
+		valgo.Int(c.BackoffMillis, "backoffMillis").GreaterOrEqualTo(0),
+		valgo.Int(c.MaxBackoffMillis, "maxBackoffMillis").GreaterOrEqualTo(0),
+		valgo.Float64(c.BudgetRatio, "budgetRatio").Between(0, 1),
+	)
+}
+
+// policy returns the proxy.RetryPolicy of the config, logging and recording
+// every retry.
+func (c *RetryConfig) policy(downstream string, logger log.Logger, metrics DownstreamMetrics) proxy.RetryPolicy {
+	logger = logger.With("downstream", downstream)
+	return proxy.RetryPolicy{
+		MaxRetries:      c.MaxRetries,
+		InitialInterval: time.Duration(c.BackoffMillis) * time.Millisecond,
+		MaxInterval:     time.Duration(c.MaxBackoffMillis) * time.Millisecond,
+		Methods:         c.Methods,
+		BudgetRatio:     c.BudgetRatio,
+		OnRetry: func(req *http.Request, attempt int, cause error) {
+			if metrics != nil {
+				metrics.ObserveRetry(downstream)
+			}
+			logger.Warn("retrying downstream request",
+				"method", req.Method,
+				"uri", req.URL.RequestURI(),
+				"attempt", attempt,
+				"cause", cause.Error(),
+			)
+		},
+	}
+}
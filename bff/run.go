@@ -0,0 +1,197 @@
+package bff
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"slices"
+
+	"github.com/cohesivestack/valgo"
+	"github.com/gin-contrib/sessions"
+
+	"github.com/joshjon/kit/auth"
+	"github.com/joshjon/kit/log"
+	"github.com/joshjon/kit/proxy"
+	"github.com/joshjon/kit/server"
+	"github.com/joshjon/kit/session"
+	"github.com/joshjon/kit/trace"
+	"github.com/joshjon/kit/valgoutil"
+)
+
+const (
+	defaultPort        = 8080
+	defaultSessionName = "bff_session"
+	sessionKeyBytes    = 32
+)
+
+// Config is the complete configuration of a BFF service. It is intended to be
+// populated by config.Load and passed to Run.
+type Config struct {
+	Port           int      `yaml:"port" env:"PORT"`
+	SessionName    string   `yaml:"sessionName" env:"SESSION_NAME"`
+	SessionKey     string   `yaml:"sessionKey" env:"SESSION_KEY"` // hex-encoded 32 bytes
+	CORSOrigins    []string `yaml:"corsOrigins" env:"CORS_ORIGINS"`
+	RegisterConfig `yaml:",inline"`
+}
+
+func (c *Config) InitDefaults() {
+	c.Port = defaultPort
+	c.SessionName = defaultSessionName
+	c.RegisterConfig.InitDefaults()
+}
+
+func (c *Config) Validation() *valgo.Validation {
+	v := c.RegisterConfig.Validation()
+	v.Is(
+		valgoutil.PortValidator(c.Port, "port"),
+		valgo.String(c.SessionName, "sessionName").Not().Blank(),
+		valgoutil.HexBytesLen(c.SessionKey, sessionKeyBytes, "sessionKey"),
+	)
+	for i, origin := range c.CORSOrigins {
+		v.InRow("corsOrigins", i, valgo.Is(valgoutil.CORSValidator(origin, "origin")))
+	}
+	return v
+}
+
+// RunOption optionally configures Run.
+type RunOption func(opts *runOptions)
+
+// WithRunLogger sets a custom Logger.
+func WithRunLogger(logger log.Logger) RunOption {
+	return func(opts *runOptions) {
+		opts.logger = logger
+	}
+}
+
+// WithServerOptions adds options to the underlying server.
+func WithServerOptions(opts ...server.Option) RunOption {
+	return func(o *runOptions) {
+		o.serverOpts = append(o.serverOpts, opts...)
+	}
+}
+
+// WithSessionStore sets the session store. Defaults to an in-memory store
+// keyed by Config.SessionKey.
+func WithSessionStore(store sessions.Store) RunOption {
+	return func(opts *runOptions) {
+		opts.sessionStore = store
+	}
+}
+
+// WithOIDCProviderInitializer sets the OIDC provider. Defaults to Logto.
+func WithOIDCProviderInitializer(provInit auth.OIDCProviderInitializer) RunOption {
+	return func(opts *runOptions) {
+		opts.provInit = provInit
+	}
+}
+
+// WithRunDownstreamMetrics sets the DownstreamMetrics used to record proxied
+// requests.
+func WithRunDownstreamMetrics(metrics DownstreamMetrics) RunOption {
+	return func(opts *runOptions) {
+		opts.metrics = metrics
+	}
+}
+
+// WithRunProxyMetrics sets the proxy.Metrics used to record proxied requests
+// end-to-end.
+func WithRunProxyMetrics(metrics proxy.Metrics) RunOption {
+	return func(opts *runOptions) {
+		opts.proxyMetrics = metrics
+	}
+}
+
+// WithRunTracing traces requests to the BFF and to its downstreams,
+// propagating the trace context to them. Tracing must be set up with
+// trace.Setup.
+func WithRunTracing() RunOption {
+	return func(opts *runOptions) {
+		opts.tracing = true
+	}
+}
+
+type runOptions struct {
+	logger       log.Logger
+	serverOpts   []server.Option
+	sessionStore sessions.Store
+	provInit     auth.OIDCProviderInitializer
+	metrics      DownstreamMetrics
+	proxyMetrics proxy.Metrics
+	tracing      bool
+}
+
+// Run starts a BFF server with the auth handler and a reverse proxy for every
+// downstream, and blocks until ctx is done, an interrupt or termination
+// signal is received or the server fails. The server is gracefully shut down
+// before Run returns.
+//
+// Example:
+//
+//	var cfg bff.Config
+//	config.Load(os.Getenv("CONFIG_FILE"), &cfg)
+//	if err := bff.Run(context.Background(), cfg); err != nil {
+//		log.Fatal(err)
+//	}
+func Run(ctx context.Context, cfg Config, opts ...RunOption) error {
+	options := runOptions{
+		logger: log.NewLogger(),
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	logger := options.logger
+
+	if options.sessionStore == nil {
+		key, err := hex.DecodeString(cfg.SessionKey)
+		if err != nil {
+			return fmt.Errorf("decode session key: %w", err)
+		}
+		if options.sessionStore, err = session.NewMemStore(key); err != nil {
+			return fmt.Errorf("create session store: %w", err)
+		}
+	}
+
+	if options.provInit == nil {
+		options.provInit = NewLogtoOIDCProviderInitializer(cfg.OIDCProvider)
+	}
+
+	srvOpts := []server.Option{server.WithLogger(logger)}
+	if len(cfg.CORSOrigins) > 0 {
+		srvOpts = append(srvOpts, server.WithCORS(cfg.CORSOrigins...))
+	}
+	if options.tracing {
+		srvOpts = append(srvOpts, server.WithMiddleware(trace.Middleware()))
+	}
+	srv, err := server.NewServer(cfg.Port, append(srvOpts, options.serverOpts...)...)
+	if err != nil {
+		return fmt.Errorf("create server: %w", err)
+	}
+
+	middleware := NewDownstreamMiddleware(cfg.RegisterConfig, options.provInit, cfg.SessionName, options.sessionStore,
+		auth.WithErrHandler(func(err error) {
+			logger.Warn("session storage error", "error", err)
+		}),
+	)
+
+	RegisterAuthHandler(cfg.OIDCProvider, srv, cfg.SessionName, middleware...)
+
+	proxyMiddleware := middleware
+	if cfg.RateLimit != nil {
+		proxyMiddleware = append(slices.Clone(middleware), RateLimitMiddleware(*cfg.RateLimit))
+	}
+	downstreamOpts := []DownstreamOption{
+		WithDownstreamMiddleware(proxyMiddleware...),
+		WithDownstreamLogger(logger),
+		WithDownstreamMetrics(options.metrics),
+		WithProxyMetrics(options.proxyMetrics),
+	}
+	if options.tracing {
+		downstreamOpts = append(downstreamOpts, WithDownstreamTracing())
+	}
+	if err = RegisterDownstreams(ctx, srv, cfg.DownstreamConfigs(), downstreamOpts...); err != nil {
+		return err
+	}
+
+	return srv.Run(ctx)
+}
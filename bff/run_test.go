@@ -0,0 +1,144 @@
+package bff
+
+import (
+	"context"
+	"encoding/hex"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/auth"
+	"github.com/joshjon/kit/log"
+	"github.com/joshjon/kit/server"
+)
+
+type runTestOIDCProvider struct{}
+
+func (runTestOIDCProvider) SignInWithRedirectUri(redirectUri string) (string, error) {
+	return "https://idp.example.com/sign-in?redirect_uri=" + redirectUri, nil
+}
+
+func (runTestOIDCProvider) HandleSignInCallback(*http.Request) error {
+	return nil
+}
+
+func (runTestOIDCProvider) SignOut(postLogoutRedirectUri string) (string, error) {
+	return postLogoutRedirectUri, nil
+}
+
+func (runTestOIDCProvider) GetAccessToken(resource string) (auth.AccessToken, error) {
+	return auth.AccessToken{Token: "token-for-" + resource}, nil
+}
+
+func newRunTestConfig(t *testing.T) Config {
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.URL.Path + " " + r.Header.Get("Authorization")))
+	}))
+	t.Cleanup(downstream.Close)
+
+	var cfg Config
+	cfg.InitDefaults()
+	cfg.SessionKey = hex.EncodeToString([]byte(strings.Repeat("k", sessionKeyBytes)))
+	cfg.OIDCProvider.Audiences = []OIDCProviderAudienceScopes{{Name: "https://api.example.com", Path: "/api"}}
+	cfg.Downstreams = []DownstreamConfig{{Name: "api", URL: downstream.URL, PathPrefixes: []string{"/api"}}}
+	return cfg
+}
+
+// startRun runs the BFF until the test ends and returns its address.
+func startRun(t *testing.T, cfg Config) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- Run(ctx, cfg,
+			WithRunLogger(log.NewLogger(log.WithNop())),
+			WithServerOptions(server.WithListener(l)),
+			WithOIDCProviderInitializer(func(*auth.SessionStorage) auth.OIDCProvider { return runTestOIDCProvider{} }),
+		)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		select {
+		case err := <-done:
+			assert.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Error("Run did not return after the context was cancelled")
+		}
+	})
+
+	addr := "http://" + l.Addr().String()
+	require.Eventually(t, func() bool {
+		res, err := http.Get(addr + server.HealthPath)
+		if err != nil {
+			return false
+		}
+		res.Body.Close()
+		return res.StatusCode == http.StatusOK
+	}, 5*time.Second, 10*time.Millisecond)
+	return addr
+}
+
+func TestRun(t *testing.T) {
+	addr := startRun(t, newRunTestConfig(t))
+
+	res, err := http.Get(addr + "/api/items")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assertBody(t, res, "/api/items Bearer token-for-https://api.example.com")
+	res.Body.Close()
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	res, err = client.Get(addr + "/auth/login")
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusTemporaryRedirect, res.StatusCode)
+	assert.True(t, strings.HasPrefix(res.Header.Get("Location"), "https://idp.example.com/sign-in"))
+}
+
+func TestRun_rateLimit(t *testing.T) {
+	cfg := newRunTestConfig(t)
+	cfg.RateLimit = &RateLimitConfig{RequestsPerSecond: 0.1, Burst: 1}
+	addr := startRun(t, cfg)
+
+	res, err := http.Get(addr + "/api/items")
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	res, err = http.Get(addr + "/api/items")
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+
+	// Only the downstream routes are rate limited.
+	res, err = http.Get(addr + server.HealthPath)
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+}
+
+func TestRun_invalidSessionKey(t *testing.T) {
+	cfg := newRunTestConfig(t)
+	cfg.SessionKey = "not-hex"
+	err := Run(context.Background(), cfg, WithRunLogger(log.NewLogger(log.WithNop())))
+	require.ErrorContains(t, err, "decode session key")
+}
+
+func assertBody(t *testing.T, res *http.Response, want string) {
+	t.Helper()
+	var body strings.Builder
+	_, err := io.Copy(&body, res.Body)
+	require.NoError(t, err)
+	assert.Equal(t, want, body.String())
+}
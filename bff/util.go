@@ -1,41 +1,31 @@
 package bff
 
 import (
-	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"errors"
-	"fmt"
 	"net/http"
 	"os"
 	"time"
-
-	"github.com/joshjon/kit/log"
-	"github.com/joshjon/kit/server"
 )
 
 const clientTimeout = 60 * time.Second
 
-type httpTLSConfig struct {
-	certFile   string
-	keyFile    string
-	caCertFile string
-}
-
-func createHTTPClient(tlsCfg *httpTLSConfig) (*http.Client, error) {
-	client := http.DefaultClient
-	client.Timeout = clientTimeout
+func createHTTPClient(tlsCfg *DownstreamTLSConfig) (*http.Client, error) {
+	client := &http.Client{
+		Timeout: clientTimeout,
+	}
 
 	if tlsCfg == nil {
 		return client, nil
 	}
 
-	cert, err := tls.LoadX509KeyPair(tlsCfg.certFile, tlsCfg.keyFile)
+	cert, err := tls.LoadX509KeyPair(tlsCfg.CertFile, tlsCfg.KeyFile)
 	if err != nil {
 		return nil, err
 	}
 
-	caCert, err := os.ReadFile(tlsCfg.caCertFile)
+	caCert, err := os.ReadFile(tlsCfg.CACertFile)
 	if err != nil {
 		return nil, err
 	}
@@ -55,30 +45,3 @@ func createHTTPClient(tlsCfg *httpTLSConfig) (*http.Client, error) {
 	client.Transport = transport
 	return client, nil
 }
-
-func serve(ctx context.Context, srv *server.Server, logger log.Logger) error {
-	errs := make(chan error)
-
-	logger.Info("starting server", "address", srv.Address())
-	go func() {
-		defer close(errs)
-		if err := srv.Start(); err != nil {
-			errs <- fmt.Errorf("start server: %w", err)
-		}
-	}()
-	defer srv.Stop(ctx) //nolint:errcheck
-
-	logger.Info("waiting for server to be healthy")
-	if err := srv.WaitHealthy(15, time.Second); err != nil {
-		return err
-	}
-	logger.Info("server healthy")
-
-	select {
-	case err := <-errs:
-		return err
-	case <-ctx.Done():
-		logger.Info("server stopped")
-		return nil
-	}
-}
@@ -0,0 +1,327 @@
+// Package cache provides a generic in-memory cache with TTL expiry and LRU
+// eviction.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/joshjon/kit/clock"
+)
+
+// EvictReason is the reason an entry was evicted.
+type EvictReason int
+
+const (
+	// EvictReasonExpired means the TTL of the entry expired.
+	EvictReasonExpired EvictReason = iota + 1
+	// EvictReasonCapacity means the entry was the least recently used when the
+	// cache was full.
+	EvictReasonCapacity
+	// EvictReasonDeleted means the entry was deleted with Delete or Clear.
+	EvictReasonDeleted
+)
+
+func (r EvictReason) String() string {
+	switch r {
+	case EvictReasonExpired:
+		return "expired"
+	case EvictReasonCapacity:
+		return "capacity"
+	case EvictReasonDeleted:
+		return "deleted"
+	}
+	return fmt.Sprintf("EvictReason(%d)", int(r))
+}
+
+// Option optionally configures a Cache.
+type Option func(opts *options)
+
+// WithTTL sets how long entries are cached for unless set with SetWithTTL.
+// Defaults to 0, which caches entries until they are evicted.
+func WithTTL(ttl time.Duration) Option {
+	return func(opts *options) {
+		opts.ttl = ttl
+	}
+}
+
+// WithMaxEntries sets the maximum number of entries, evicting the least
+// recently used entry when exceeded. Defaults to 0, which is unbounded.
+func WithMaxEntries(n int) Option {
+	return func(opts *options) {
+		opts.maxEntries = n
+	}
+}
+
+// WithOnEvict sets a func called with every evicted entry. The key and value
+// types of fn must match those of the cache. It is called without holding the
+// lock of the cache, so it may use the cache.
+func WithOnEvict[K comparable, V any](fn func(key K, value V, reason EvictReason)) Option {
+	return func(opts *options) {
+		opts.onEvict = fn
+	}
+}
+
+// WithClock sets the clock used to expire entries. Defaults to clock.Real.
+func WithClock(clk clock.Clock) Option {
+	return func(opts *options) {
+		opts.clock = clk
+	}
+}
+
+type options struct {
+	ttl        time.Duration
+	maxEntries int
+	onEvict    any
+	clock      clock.Clock
+}
+
+// Cache is an in-memory cache of values by key. Expired entries are evicted
+// lazily when accessed or by DeleteExpired. A Cache is safe for concurrent
+// use.
+type Cache[K comparable, V any] struct {
+	ttl        time.Duration
+	maxEntries int
+	onEvict    func(K, V, EvictReason)
+	clock      clock.Clock
+
+	mu      sync.Mutex
+	entries map[K]*list.Element
+	lru     *list.List // front is most recently used
+	loads   map[K]*load[V]
+}
+
+type entry[K comparable, V any] struct {
+	key       K
+	value     V
+	expiresAt time.Time // zero if the entry does not expire
+}
+
+type eviction[K comparable, V any] struct {
+	key    K
+	value  V
+	reason EvictReason
+}
+
+// New creates a new Cache. It panics if the func set with WithOnEvict does not
+// match the key and value types of the cache.
+func New[K comparable, V any](opts ...Option) *Cache[K, V] {
+	options := options{
+		clock: clock.Real,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	c := &Cache[K, V]{
+		ttl:        options.ttl,
+		maxEntries: options.maxEntries,
+		clock:      options.clock,
+		entries:    map[K]*list.Element{},
+		lru:        list.New(),
+		loads:      map[K]*load[V]{},
+	}
+	if options.onEvict != nil {
+		onEvict, ok := options.onEvict.(func(K, V, EvictReason))
+		if !ok {
+			panic(fmt.Sprintf("cache: OnEvict func %T does not match cache types", options.onEvict))
+		}
+		c.onEvict = onEvict
+	}
+	return c
+}
+
+// Get returns the value of key, and whether it was found and not expired.
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	value, ok, evicted := c.getLocked(key)
+	c.mu.Unlock()
+	c.notify(evicted)
+	return value, ok
+}
+
+// Set sets the value of key with the TTL of the cache.
+func (c *Cache[K, V]) Set(key K, value V) {
+	c.SetWithTTL(key, value, c.ttl)
+}
+
+// SetWithTTL sets the value of key, expiring after ttl. A ttl of 0 caches the
+// entry until it is evicted.
+func (c *Cache[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	c.mu.Lock()
+	evicted := c.setLocked(key, value, ttl)
+	c.mu.Unlock()
+	c.notify(evicted)
+}
+
+// Delete deletes the entry of key, reporting whether it was found.
+func (c *Cache[K, V]) Delete(key K) bool {
+	c.mu.Lock()
+	elem, ok := c.entries[key]
+	var evicted []eviction[K, V]
+	if ok {
+		evicted = append(evicted, c.removeLocked(elem, EvictReasonDeleted))
+	}
+	c.mu.Unlock()
+	c.notify(evicted)
+	return ok
+}
+
+// Clear deletes all entries.
+func (c *Cache[K, V]) Clear() {
+	c.mu.Lock()
+	evicted := make([]eviction[K, V], 0, c.lru.Len())
+	for c.lru.Len() > 0 {
+		evicted = append(evicted, c.removeLocked(c.lru.Back(), EvictReasonDeleted))
+	}
+	c.mu.Unlock()
+	c.notify(evicted)
+}
+
+// DeleteExpired evicts all expired entries, e.g. periodically to release the
+// memory of entries that are no longer accessed.
+func (c *Cache[K, V]) DeleteExpired() {
+	c.mu.Lock()
+	now := c.clock.Now()
+	var evicted []eviction[K, V]
+	for elem := c.lru.Back(); elem != nil; {
+		prev := elem.Prev()
+		if elem.Value.(*entry[K, V]).expired(now) {
+			evicted = append(evicted, c.removeLocked(elem, EvictReasonExpired))
+		}
+		elem = prev
+	}
+	c.mu.Unlock()
+	c.notify(evicted)
+}
+
+// Len returns the number of entries, including expired entries not yet
+// evicted.
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+func (c *Cache[K, V]) getLocked(key K) (V, bool, []eviction[K, V]) {
+	var zero V
+	elem, ok := c.entries[key]
+	if !ok {
+		return zero, false, nil
+	}
+	e := elem.Value.(*entry[K, V])
+	if e.expired(c.clock.Now()) {
+		return zero, false, []eviction[K, V]{c.removeLocked(elem, EvictReasonExpired)}
+	}
+	c.lru.MoveToFront(elem)
+	return e.value, true, nil
+}
+
+func (c *Cache[K, V]) setLocked(key K, value V, ttl time.Duration) []eviction[K, V] {
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = c.clock.Now().Add(ttl)
+	}
+	if elem, ok := c.entries[key]; ok {
+		e := elem.Value.(*entry[K, V])
+		e.value = value
+		e.expiresAt = expiresAt
+		c.lru.MoveToFront(elem)
+		return nil
+	}
+
+	c.entries[key] = c.lru.PushFront(&entry[K, V]{key: key, value: value, expiresAt: expiresAt})
+	var evicted []eviction[K, V]
+	if c.maxEntries > 0 {
+		now := c.clock.Now()
+		for c.lru.Len() > c.maxEntries {
+			oldest := c.lru.Back()
+			reason := EvictReasonCapacity
+			if oldest.Value.(*entry[K, V]).expired(now) {
+				reason = EvictReasonExpired
+			}
+			evicted = append(evicted, c.removeLocked(oldest, reason))
+		}
+	}
+	return evicted
+}
+
+func (c *Cache[K, V]) removeLocked(elem *list.Element, reason EvictReason) eviction[K, V] {
+	e := c.lru.Remove(elem).(*entry[K, V])
+	delete(c.entries, e.key)
+	return eviction[K, V]{key: e.key, value: e.value, reason: reason}
+}
+
+func (c *Cache[K, V]) notify(evicted []eviction[K, V]) {
+	if c.onEvict == nil {
+		return
+	}
+	for _, ev := range evicted {
+		c.onEvict(ev.key, ev.value, ev.reason)
+	}
+}
+
+func (e *entry[K, V]) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+var errLoadPanicked = errors.New("cache: load panicked")
+
+type load[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+// GetOrLoad returns the value of key, or loads and caches it with the TTL of
+// the cache if not found. Concurrent calls for the same key share a single
+// load, which runs with the context of the first caller. Errors are returned
+// to all callers of the load and not cached. Callers waiting on a load return
+// early with the error of their context when it is done.
+func (c *Cache[K, V]) GetOrLoad(ctx context.Context, key K, loadFn func(ctx context.Context) (V, error)) (V, error) {
+	c.mu.Lock()
+	value, ok, evicted := c.getLocked(key)
+	if ok {
+		c.mu.Unlock()
+		return value, nil
+	}
+	if l, ok := c.loads[key]; ok {
+		c.mu.Unlock()
+		c.notify(evicted)
+		select {
+		case <-l.done:
+			return l.value, l.err
+		case <-ctx.Done():
+			var zero V
+			return zero, ctx.Err()
+		}
+	}
+	l := &load[V]{done: make(chan struct{})}
+	c.loads[key] = l
+	c.mu.Unlock()
+	c.notify(evicted)
+
+	completed := false
+	defer func() {
+		if !completed {
+			// The load panicked, so fail waiting callers rather than caching
+			// the zero value.
+			l.err = errLoadPanicked
+		}
+		c.mu.Lock()
+		delete(c.loads, key)
+		if l.err == nil {
+			evicted = c.setLocked(key, l.value, c.ttl)
+		}
+		c.mu.Unlock()
+		close(l.done)
+		c.notify(evicted)
+	}()
+	l.value, l.err = loadFn(ctx)
+	completed = true
+	return l.value, l.err
+}
@@ -0,0 +1,205 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/testutil"
+)
+
+type evicted struct {
+	key    string
+	value  int
+	reason EvictReason
+}
+
+func newTestCache(t *testing.T, opts ...Option) (*Cache[string, int], *testutil.FakeClock, *[]evicted) {
+	t.Helper()
+	clk := testutil.NewFakeClock(time.Now())
+	var got []evicted
+	opts = append([]Option{
+		WithClock(clk),
+		WithOnEvict(func(key string, value int, reason EvictReason) {
+			got = append(got, evicted{key, value, reason})
+		}),
+	}, opts...)
+	return New[string, int](opts...), clk, &got
+}
+
+func TestCache_ttl(t *testing.T) {
+	c, clk, got := newTestCache(t, WithTTL(time.Minute))
+
+	c.Set("a", 1)
+	c.SetWithTTL("b", 2, time.Hour)
+	c.SetWithTTL("c", 3, 0)
+
+	clk.Advance(59 * time.Second)
+	v, ok := c.Get("a")
+	require.True(t, ok)
+	assert.Equal(t, 1, v)
+
+	clk.Advance(time.Second)
+	_, ok = c.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, []evicted{{"a", 1, EvictReasonExpired}}, *got)
+
+	clk.Advance(24 * time.Hour)
+	assert.Equal(t, 2, c.Len())
+	c.DeleteExpired()
+	assert.Equal(t, 1, c.Len())
+	v, ok = c.Get("c")
+	require.True(t, ok)
+	assert.Equal(t, 3, v)
+	assert.Equal(t, evicted{"b", 2, EvictReasonExpired}, (*got)[1])
+}
+
+func TestCache_lru(t *testing.T) {
+	c, _, got := newTestCache(t, WithMaxEntries(2))
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Get("a") // b is now the least recently used
+	c.Set("c", 3)
+
+	_, ok := c.Get("b")
+	assert.False(t, ok)
+	_, ok = c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, []evicted{{"b", 2, EvictReasonCapacity}}, *got)
+
+	// replacing an entry does not evict
+	c.Set("a", 10)
+	assert.Equal(t, 2, c.Len())
+	v, _ := c.Get("a")
+	assert.Equal(t, 10, v)
+}
+
+func TestCache_Delete(t *testing.T) {
+	c, _, got := newTestCache(t)
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	assert.True(t, c.Delete("a"))
+	assert.False(t, c.Delete("a"))
+	c.Clear()
+	assert.Zero(t, c.Len())
+	assert.Equal(t, []evicted{
+		{"a", 1, EvictReasonDeleted},
+		{"b", 2, EvictReasonDeleted},
+	}, *got)
+}
+
+func TestCache_GetOrLoad(t *testing.T) {
+	c := New[string, int](WithTTL(time.Minute))
+
+	var loads atomic.Int32
+	release := make(chan struct{})
+	load := func(context.Context) (int, error) {
+		loads.Add(1)
+		<-release
+		return 42, nil
+	}
+
+	// concurrent loads of the same key are shared
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := c.GetOrLoad(context.Background(), "a", load)
+			assert.NoError(t, err)
+			assert.Equal(t, 42, v)
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), loads.Load())
+
+	// cached
+	v, err := c.GetOrLoad(context.Background(), "a", load)
+	require.NoError(t, err)
+	assert.Equal(t, 42, v)
+	assert.Equal(t, int32(1), loads.Load())
+}
+
+func TestCache_GetOrLoad_error(t *testing.T) {
+	c := New[string, int]()
+
+	errLoad := errors.New("load failed")
+	_, err := c.GetOrLoad(context.Background(), "a", func(context.Context) (int, error) {
+		return 0, errLoad
+	})
+	assert.ErrorIs(t, err, errLoad)
+	assert.Zero(t, c.Len())
+
+	// errors are not cached
+	v, err := c.GetOrLoad(context.Background(), "a", func(context.Context) (int, error) {
+		return 1, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, v)
+}
+
+func TestCache_GetOrLoad_waiterCanceled(t *testing.T) {
+	c := New[string, int]()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	go func() {
+		_, _ = c.GetOrLoad(context.Background(), "a", func(context.Context) (int, error) {
+			close(started)
+			<-release
+			return 1, nil
+		})
+	}()
+	<-started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := c.GetOrLoad(ctx, "a", func(context.Context) (int, error) {
+		t.Fatal("unexpected load")
+		return 0, nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	close(release)
+}
+
+func TestNew_onEvictTypeMismatch(t *testing.T) {
+	assert.Panics(t, func() {
+		New[string, string](WithOnEvict(func(string, int, EvictReason) {}))
+	})
+}
+
+func TestAsStore(t *testing.T) {
+	ctx := context.Background()
+	clk := testutil.NewFakeClock(time.Now())
+	store := AsStore(New[string, int](WithClock(clk)))
+
+	require.NoError(t, store.Set(ctx, "a", 1, time.Minute))
+	v, ok, err := store.Get(ctx, "a")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, 1, v)
+
+	clk.Advance(time.Minute)
+	_, ok, err = store.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	v, err = GetOrLoad(ctx, store, "a", 0, func(context.Context) (int, error) {
+		return 2, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, v)
+	require.NoError(t, store.Delete(ctx, "a"))
+	_, ok, _ = store.Get(ctx, "a")
+	assert.False(t, ok)
+}
@@ -0,0 +1,116 @@
+// Package rediscache provides a Redis implementation of cache.Store, for
+// caches shared by multiple instances of a service.
+package rediscache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/joshjon/kit/cache"
+)
+
+// Codec encodes and decodes cached values.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// JSONCodec is a Codec using encoding/json.
+type JSONCodec struct{}
+
+func (JSONCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (JSONCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+// Option optionally configures a Store.
+type Option func(opts *options)
+
+// WithCodec sets the codec used to encode values. Defaults to JSONCodec.
+func WithCodec(codec Codec) Option {
+	return func(opts *options) {
+		opts.codec = codec
+	}
+}
+
+// WithKeyPrefix sets a prefix prepended to every key, e.g. to share a Redis
+// database between multiple caches. Defaults to no prefix.
+func WithKeyPrefix(prefix string) Option {
+	return func(opts *options) {
+		opts.keyPrefix = prefix
+	}
+}
+
+type options struct {
+	codec     Codec
+	keyPrefix string
+}
+
+// Store is a cache.Store of values in Redis.
+type Store[V any] struct {
+	client    redis.UniversalClient
+	codec     Codec
+	keyPrefix string
+}
+
+var _ cache.Store[any] = (*Store[any])(nil)
+
+// New creates a new Store using client, which may be a single node, sentinel
+// or cluster client.
+func New[V any](client redis.UniversalClient, opts ...Option) *Store[V] {
+	options := options{
+		codec: JSONCodec{},
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &Store[V]{
+		client:    client,
+		codec:     options.codec,
+		keyPrefix: options.keyPrefix,
+	}
+}
+
+// Get returns the value of key, and whether it was found and not expired.
+func (s *Store[V]) Get(ctx context.Context, key string) (V, bool, error) {
+	var value V
+	data, err := s.client.Get(ctx, s.keyPrefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return value, false, nil
+	} else if err != nil {
+		return value, false, fmt.Errorf("get %s: %w", key, err)
+	}
+	if err = s.codec.Unmarshal(data, &value); err != nil {
+		return value, false, fmt.Errorf("decode %s: %w", key, err)
+	}
+	return value, true, nil
+}
+
+// Set sets the value of key, expiring after ttl. A ttl of 0 caches the entry
+// until it is deleted or evicted by Redis.
+func (s *Store[V]) Set(ctx context.Context, key string, value V, ttl time.Duration) error {
+	data, err := s.codec.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", key, err)
+	}
+	if err = s.client.Set(ctx, s.keyPrefix+key, data, ttl).Err(); err != nil {
+		return fmt.Errorf("set %s: %w", key, err)
+	}
+	return nil
+}
+
+// Delete deletes the entry of key.
+func (s *Store[V]) Delete(ctx context.Context, key string) error {
+	if err := s.client.Del(ctx, s.keyPrefix+key).Err(); err != nil {
+		return fmt.Errorf("delete %s: %w", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package rediscache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/cache"
+)
+
+type item struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+func newTestStore(t *testing.T, opts ...Option) (*Store[item], *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() {
+		_ = client.Close()
+	})
+	return New[item](client, opts...), mr
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	store, mr := newTestStore(t, WithKeyPrefix("items:"))
+
+	_, ok, err := store.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	want := item{Name: "a", Count: 1}
+	require.NoError(t, store.Set(ctx, "a", want, time.Minute))
+	assert.True(t, mr.Exists("items:a"))
+
+	got, ok, err := store.Get(ctx, "a")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, want, got)
+
+	mr.FastForward(time.Minute)
+	_, ok, err = store.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, store.Set(ctx, "b", want, 0))
+	assert.Zero(t, mr.TTL("items:b"))
+	require.NoError(t, store.Delete(ctx, "b"))
+	assert.False(t, mr.Exists("items:b"))
+}
+
+func TestStore_decodeError(t *testing.T) {
+	store, mr := newTestStore(t)
+	require.NoError(t, mr.Set("a", "not json"))
+
+	_, _, err := store.Get(context.Background(), "a")
+	assert.Error(t, err)
+}
+
+func TestGetOrLoad(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newTestStore(t)
+
+	loads := 0
+	load := func(context.Context) (item, error) {
+		loads++
+		return item{Name: "a"}, nil
+	}
+	for range 2 {
+		v, err := cache.GetOrLoad[item](ctx, store, "a", time.Minute, load)
+		require.NoError(t, err)
+		assert.Equal(t, "a", v.Name)
+	}
+	assert.Equal(t, 1, loads)
+}
@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Store is a cache of values by string key, implemented in-memory by
+// AsStore and by Redis in package rediscache. Components written against a
+// Store can use the in-memory implementation when running as a single
+// instance and Redis when running as a cluster.
+type Store[V any] interface {
+	// Get returns the value of key, and whether it was found and not expired.
+	Get(ctx context.Context, key string) (V, bool, error)
+	// Set sets the value of key, expiring after ttl. A ttl of 0 caches the
+	// entry until it is evicted.
+	Set(ctx context.Context, key string, value V, ttl time.Duration) error
+	// Delete deletes the entry of key.
+	Delete(ctx context.Context, key string) error
+}
+
+// AsStore returns a Store backed by c.
+func AsStore[V any](c *Cache[string, V]) Store[V] {
+	return memoryStore[V]{cache: c}
+}
+
+type memoryStore[V any] struct {
+	cache *Cache[string, V]
+}
+
+func (s memoryStore[V]) Get(_ context.Context, key string) (V, bool, error) {
+	value, ok := s.cache.Get(key)
+	return value, ok, nil
+}
+
+func (s memoryStore[V]) Set(_ context.Context, key string, value V, ttl time.Duration) error {
+	s.cache.SetWithTTL(key, value, ttl)
+	return nil
+}
+
+func (s memoryStore[V]) Delete(_ context.Context, key string) error {
+	s.cache.Delete(key)
+	return nil
+}
+
+// GetOrLoad returns the value of key from s, or loads and sets it with ttl if
+// not found. Unlike Cache.GetOrLoad, concurrent loads of the same key are not
+// shared.
+func GetOrLoad[V any](ctx context.Context, s Store[V], key string, ttl time.Duration, load func(ctx context.Context) (V, error)) (V, error) {
+	value, ok, err := s.Get(ctx, key)
+	if err != nil || ok {
+		return value, err
+	}
+	if value, err = load(ctx); err != nil {
+		return value, err
+	}
+	return value, s.Set(ctx, key, value, ttl)
+}
@@ -0,0 +1,68 @@
+package conc
+
+import (
+	"context"
+	"sync"
+)
+
+// KeyedSerializer runs work for the same key one at a time while work for
+// different keys runs concurrently, e.g. to avoid conflicting updates to an
+// entity. It is safe for concurrent use.
+type KeyedSerializer[K comparable] struct {
+	mu    sync.Mutex
+	locks map[K]*keyLock
+}
+
+type keyLock struct {
+	ch   chan struct{}
+	refs int
+}
+
+// NewKeyedSerializer creates a new KeyedSerializer.
+func NewKeyedSerializer[K comparable]() *KeyedSerializer[K] {
+	return &KeyedSerializer[K]{locks: map[K]*keyLock{}}
+}
+
+// Do calls fn once no other work for key is running and returns its error.
+// If ctx is done while waiting, fn is not called and the error of ctx is
+// returned.
+func (s *KeyedSerializer[K]) Do(ctx context.Context, key K, fn func(ctx context.Context) error) error {
+	lock := s.acquire(key)
+	defer s.release(key, lock)
+
+	select {
+	case lock.ch <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-lock.ch }()
+	return fn(ctx)
+}
+
+// Len returns the number of keys with running or waiting work.
+func (s *KeyedSerializer[K]) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.locks)
+}
+
+func (s *KeyedSerializer[K]) acquire(key K) *keyLock {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lock, ok := s.locks[key]
+	if !ok {
+		lock = &keyLock{ch: make(chan struct{}, 1)}
+		s.locks[key] = lock
+	}
+	lock.refs++
+	return lock
+}
+
+func (s *KeyedSerializer[K]) release(key K, lock *keyLock) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lock.refs--
+	if lock.refs == 0 {
+		delete(s.locks, key)
+	}
+}
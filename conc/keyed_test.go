@@ -0,0 +1,67 @@
+package conc
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyedSerializer_Do(t *testing.T) {
+	s := NewKeyedSerializer[string]()
+	var running [2]atomic.Int32
+	var overlapped atomic.Bool
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			key := i % 2
+			err := s.Do(context.Background(), []string{"a", "b"}[key], func(context.Context) error {
+				if running[key].Add(1) > 1 {
+					overlapped.Store(true)
+				}
+				time.Sleep(time.Millisecond)
+				running[key].Add(-1)
+				return nil
+			})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.False(t, overlapped.Load())
+	assert.Zero(t, s.Len())
+}
+
+func TestKeyedSerializer_Do_ctxDone(t *testing.T) {
+	s := NewKeyedSerializer[string]()
+	started, release := make(chan struct{}), make(chan struct{})
+	go func() {
+		_ = s.Do(context.Background(), "a", func(context.Context) error {
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	called := false
+	err := s.Do(ctx, "a", func(context.Context) error {
+		called = true
+		return nil
+	})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.False(t, called)
+
+	// Other keys aren't blocked.
+	require.NoError(t, s.Do(context.Background(), "b", func(context.Context) error { return nil }))
+	close(release)
+}
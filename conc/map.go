@@ -0,0 +1,45 @@
+package conc
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Map calls fn with every item, running at most limit calls at a time, and
+// returns the results in the order of items. Once a call fails the context
+// passed to the others is cancelled and the errors are returned. If ctx is
+// done before every item is mapped its error is returned.
+func Map[T any, R any](ctx context.Context, items []T, limit int, fn func(ctx context.Context, item T) (R, error)) ([]R, error) {
+	results := make([]R, len(items))
+	var mapped atomic.Int64
+	pool := NewPool(ctx, limit, WithCancelOnError())
+	for i, item := range items {
+		pool.Go(func(ctx context.Context) error {
+			res, err := fn(ctx, item)
+			if err != nil {
+				return err
+			}
+			results[i] = res
+			mapped.Add(1)
+			return nil
+		})
+	}
+	if err := pool.Wait(); err != nil {
+		return nil, err
+	}
+	if mapped.Load() < int64(len(items)) {
+		return nil, ctx.Err()
+	}
+	return results, nil
+}
+
+// ForEach calls fn with every item, running at most limit calls at a time.
+// Once a call fails the context passed to the others is cancelled and the
+// errors are returned. If ctx is done before every item is processed its
+// error is returned.
+func ForEach[T any](ctx context.Context, items []T, limit int, fn func(ctx context.Context, item T) error) error {
+	_, err := Map(ctx, items, limit, func(ctx context.Context, item T) (struct{}, error) {
+		return struct{}{}, fn(ctx, item)
+	})
+	return err
+}
@@ -0,0 +1,50 @@
+package conc
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMap(t *testing.T) {
+	got, err := Map(context.Background(), []int{1, 2, 3, 4}, 2, func(_ context.Context, item int) (string, error) {
+		return strconv.Itoa(item * 2), nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2", "4", "6", "8"}, got)
+}
+
+func TestMap_error(t *testing.T) {
+	wantErr := errors.New("boom")
+	got, err := Map(context.Background(), []int{1, 2, 3}, 1, func(ctx context.Context, item int) (int, error) {
+		if item == 2 {
+			return 0, wantErr
+		}
+		return item, ctx.Err()
+	})
+	assert.ErrorIs(t, err, wantErr)
+	assert.Nil(t, got)
+}
+
+func TestMap_ctxDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := Map(ctx, []int{1, 2}, 1, func(context.Context, int) (int, error) {
+		return 0, nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestForEach(t *testing.T) {
+	results := make([]int, 3)
+	err := ForEach(context.Background(), []int{0, 1, 2}, 0, func(_ context.Context, item int) error {
+		results[item] = item + 1
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, results)
+}
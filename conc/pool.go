@@ -0,0 +1,117 @@
+// Package conc provides concurrency primitives for fanning out work, e.g. to
+// databases or downstream services: a bounded Pool, the Map and ForEach
+// helpers built on it, and a KeyedSerializer running work for the same key
+// one at a time.
+package conc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/joshjon/kit/errtag"
+)
+
+// PoolOption optionally configures a Pool.
+type PoolOption func(opts *poolOptions)
+
+// WithCancelOnError cancels the context passed to tasks once a task fails, so
+// remaining tasks can stop early and queued tasks are not started.
+func WithCancelOnError() PoolOption {
+	return func(opts *poolOptions) {
+		opts.cancelOnError = true
+	}
+}
+
+type poolOptions struct {
+	cancelOnError bool
+}
+
+// Pool runs tasks in goroutines, at most limit at a time. Panics in tasks are
+// recovered and returned by Wait as errtag.Internal errors with the stack of
+// the panic.
+type Pool struct {
+	opts   poolOptions
+	ctx    context.Context
+	cancel context.CancelFunc
+	sem    chan struct{}
+	wg     sync.WaitGroup
+	mu     sync.Mutex
+	errs   []error
+}
+
+// NewPool creates a Pool running at most limit tasks at a time, or an
+// unlimited number if limit <= 0. Tasks are passed a context derived from ctx.
+func NewPool(ctx context.Context, limit int, opts ...PoolOption) *Pool {
+	var options poolOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	p := &Pool{
+		opts:   options,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	if limit > 0 {
+		p.sem = make(chan struct{}, limit)
+	}
+	return p
+}
+
+// Go runs task in a goroutine once fewer than limit tasks are running,
+// blocking until then. The task is skipped if the context of the Pool is
+// done before it starts.
+func (p *Pool) Go(task func(ctx context.Context) error) {
+	if p.sem != nil {
+		select {
+		case p.sem <- struct{}{}:
+		case <-p.ctx.Done():
+			return
+		}
+	}
+	if p.ctx.Err() != nil {
+		p.release()
+		return
+	}
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		defer p.release()
+		if err := p.run(task); err != nil {
+			p.mu.Lock()
+			p.errs = append(p.errs, err)
+			p.mu.Unlock()
+			if p.opts.cancelOnError {
+				p.cancel()
+			}
+		}
+	}()
+}
+
+func (p *Pool) run(task func(ctx context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errtag.Tag[errtag.Internal](fmt.Errorf("panic: %v", r), errtag.WithStack())
+		}
+	}()
+	return task(p.ctx)
+}
+
+func (p *Pool) release() {
+	if p.sem != nil {
+		<-p.sem
+	}
+}
+
+// Wait waits for the running tasks to return and returns their errors
+// joined. The Pool must not be used after Wait.
+func (p *Pool) Wait() error {
+	p.wg.Wait()
+	p.cancel()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return errors.Join(p.errs...)
+}
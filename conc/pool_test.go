@@ -0,0 +1,72 @@
+package conc
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/errtag"
+)
+
+func TestPool_limit(t *testing.T) {
+	pool := NewPool(context.Background(), 2)
+	var running, maxRunning atomic.Int32
+	for range 10 {
+		pool.Go(func(context.Context) error {
+			n := running.Add(1)
+			for {
+				m := maxRunning.Load()
+				if n <= m || maxRunning.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			running.Add(-1)
+			return nil
+		})
+	}
+	require.NoError(t, pool.Wait())
+	assert.LessOrEqual(t, maxRunning.Load(), int32(2))
+}
+
+func TestPool_errors(t *testing.T) {
+	pool := NewPool(context.Background(), 0)
+	err1, err2 := errors.New("one"), errors.New("two")
+	pool.Go(func(context.Context) error { return err1 })
+	pool.Go(func(context.Context) error { return err2 })
+	pool.Go(func(context.Context) error { return nil })
+
+	err := pool.Wait()
+	assert.ErrorIs(t, err, err1)
+	assert.ErrorIs(t, err, err2)
+}
+
+func TestPool_panic(t *testing.T) {
+	pool := NewPool(context.Background(), 1)
+	pool.Go(func(context.Context) error { panic("boom") })
+
+	err := pool.Wait()
+	require.Error(t, err)
+	assert.True(t, errtag.HasTag[errtag.Internal](err))
+	assert.ErrorContains(t, err, "panic: boom")
+}
+
+func TestPool_WithCancelOnError(t *testing.T) {
+	pool := NewPool(context.Background(), 1, WithCancelOnError())
+	wantErr := errors.New("boom")
+	pool.Go(func(context.Context) error { return wantErr })
+
+	var started atomic.Bool
+	pool.Go(func(context.Context) error {
+		started.Store(true)
+		return nil
+	})
+
+	assert.ErrorIs(t, pool.Wait(), wantErr)
+	assert.False(t, started.Load())
+}
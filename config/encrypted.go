@@ -0,0 +1,229 @@
+package config
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/joshjon/kit/encrypt"
+	"github.com/joshjon/kit/secrets"
+)
+
+const (
+	// EncryptedPrefix marks a config value as encrypted. The remainder of the
+	// value is the base64 encoded ciphertext.
+	EncryptedPrefix = "enc:"
+	// EncryptionKeyEnvVar is the environment variable holding comma separated
+	// hex encoded AES keys used to decrypt config values. The first key is the
+	// primary and the others are tried when decrypting to support rotation.
+	EncryptionKeyEnvVar = "CONFIG_ENCRYPTION_KEY"
+)
+
+// WithDecrypter sets the Encrypter used to decrypt `enc:` prefixed values.
+// Defaults to an AES keyring using the keys in CONFIG_ENCRYPTION_KEY.
+func WithDecrypter(dec encrypt.Encrypter) LoadConfigOption {
+	return func(o *loadConfigOptions) {
+		o.decrypter = dec
+	}
+}
+
+// KeyringFromEnv creates an AES keyring from the keys in
+// CONFIG_ENCRYPTION_KEY.
+func KeyringFromEnv() (*encrypt.Keyring, error) {
+	return parseKeyring(os.Getenv(EncryptionKeyEnvVar))
+}
+
+// EncryptValue encrypts a plaintext config value, returning an `enc:`
+// prefixed value that Load decrypts.
+func EncryptValue(ctx context.Context, enc encrypt.Encrypter, plaintext string) (string, error) {
+	ciphertext, err := enc.Encrypt(ctx, []byte(plaintext))
+	if err != nil {
+		return "", fmt.Errorf("encrypt value: %w", err)
+	}
+	return EncryptedPrefix + base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// EncryptCommand returns a cli command that encrypts a value for use in config
+// files. The value is read from the first argument or otherwise stdin.
+//
+// Example:
+//
+//	app.Commands = append(app.Commands, config.EncryptCommand())
+//
+//	$ CONFIG_ENCRYPTION_KEY=<hex key> myapp encrypt-config "s3cret"
+//	enc:Zm9v...
+func EncryptCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "encrypt-config",
+		Usage:     "encrypts a config value",
+		ArgsUsage: "[value]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "key",
+				Usage:   "[required] comma separated hex encoded AES keys, the first is used to encrypt",
+				EnvVars: []string{EncryptionKeyEnvVar},
+			},
+		},
+		Action: func(c *cli.Context) error {
+			keyring, err := parseKeyring(c.String("key"))
+			if err != nil {
+				return err
+			}
+			plaintext := c.Args().First()
+			if plaintext == "" {
+				scanner := bufio.NewScanner(c.App.Reader)
+				if scanner.Scan() {
+					plaintext = scanner.Text()
+				}
+				if err = scanner.Err(); err != nil {
+					return fmt.Errorf("read value: %w", err)
+				}
+			}
+			if plaintext == "" {
+				return errors.New("value is required")
+			}
+			value, err := EncryptValue(c.Context, keyring, plaintext)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(c.App.Writer, value)
+			return err
+		},
+	}
+}
+
+func parseKeyring(keys string) (*encrypt.Keyring, error) {
+	if keys == "" {
+		return nil, fmt.Errorf("%s is not set", EncryptionKeyEnvVar)
+	}
+	var decoded [][]byte
+	for key := range strings.SplitSeq(keys, ",") {
+		b, err := hex.DecodeString(strings.TrimSpace(key))
+		if err != nil {
+			return nil, fmt.Errorf("decode %s: %w", EncryptionKeyEnvVar, err)
+		}
+		decoded = append(decoded, b)
+	}
+	keyring, err := encrypt.NewAESKeyring(decoded...)
+	if err != nil {
+		return nil, fmt.Errorf("create keyring: %w", err)
+	}
+	return keyring, nil
+}
+
+// resolveValues replaces all `enc:` prefixed strings in out with their
+// plaintext and all `secret:` prefixed strings with the secret they reference.
+// The decrypter is only resolved once an encrypted value is found so a key is
+// not required for configs without encrypted values.
+func resolveValues(ctx context.Context, dec encrypt.Encrypter, secrets secrets.Provider, out any) error {
+	d := &valueResolver{ctx: ctx, dec: dec, secrets: secrets}
+	return d.walk(reflect.ValueOf(out).Elem(), "")
+}
+
+type valueResolver struct {
+	ctx     context.Context
+	dec     encrypt.Encrypter
+	secrets secrets.Provider
+}
+
+func (d *valueResolver) walk(v reflect.Value, path string) error {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return d.walk(v.Elem(), path)
+	case reflect.Struct:
+		t := v.Type()
+		for i := range t.NumField() {
+			if !t.Field(i).IsExported() {
+				continue
+			}
+			if err := d.walk(v.Field(i), joinPath(path, t.Field(i).Name)); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			if err := d.walk(v.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		// Map values are not addressable so resolved strings are set back.
+		iter := v.MapRange()
+		for iter.Next() {
+			val := iter.Value()
+			elemPath := fmt.Sprintf("%s[%v]", path, iter.Key())
+			if val.Kind() != reflect.String {
+				if err := d.walk(val, elemPath); err != nil {
+					return err
+				}
+				continue
+			}
+			plaintext, ok, err := d.resolve(val.String(), elemPath)
+			if err != nil {
+				return err
+			}
+			if ok {
+				v.SetMapIndex(iter.Key(), reflect.ValueOf(plaintext).Convert(val.Type()))
+			}
+		}
+	case reflect.String:
+		plaintext, ok, err := d.resolve(v.String(), path)
+		if err != nil {
+			return err
+		}
+		if ok && v.CanSet() {
+			v.SetString(plaintext)
+		}
+	default:
+	}
+	return nil
+}
+
+func (d *valueResolver) resolve(value string, path string) (string, bool, error) {
+	if name, ok := strings.CutPrefix(value, SecretPrefix); ok {
+		secret, err := d.getSecret(name, path)
+		return secret, err == nil, err
+	}
+	return d.decrypt(value, path)
+}
+
+func (d *valueResolver) decrypt(value string, path string) (string, bool, error) {
+	encoded, ok := strings.CutPrefix(value, EncryptedPrefix)
+	if !ok {
+		return "", false, nil
+	}
+	if d.dec == nil {
+		keyring, err := KeyringFromEnv()
+		if err != nil {
+			return "", false, fmt.Errorf("decrypt %s: %w", path, err)
+		}
+		d.dec = keyring
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", false, fmt.Errorf("decrypt %s: decode value: %w", path, err)
+	}
+	plaintext, err := d.dec.Decrypt(d.ctx, ciphertext)
+	if err != nil {
+		return "", false, fmt.Errorf("decrypt %s: %w", path, err)
+	}
+	return string(plaintext), true, nil
+}
+
+func joinPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
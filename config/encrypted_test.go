@@ -0,0 +1,64 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestLoad_encryptedValues(t *testing.T) {
+	key := hex.EncodeToString([]byte("12345678901234567890123456789012"))
+	t.Setenv(EncryptionKeyEnvVar, key)
+
+	keyring, err := KeyringFromEnv()
+	require.NoError(t, err)
+	name, err := EncryptValue(context.Background(), keyring, "secret-name")
+	require.NoError(t, err)
+	label, err := EncryptValue(context.Background(), keyring, "secret-label")
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+	content := "name: " + name + "\nhosts: [plain]\nlabels:\n  token: " + label + "\n"
+	require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+
+	var cfg testConfig
+	require.NoError(t, load(file, &cfg, WithProfile("")))
+	assert.Equal(t, "secret-name", cfg.Name)
+	assert.Equal(t, []string{"plain"}, cfg.Hosts)
+	assert.Equal(t, map[string]string{"token": "secret-label"}, cfg.Labels)
+
+	// a key is required once an encrypted value is present
+	t.Setenv(EncryptionKeyEnvVar, "")
+	cfg = testConfig{}
+	err = load(file, &cfg, WithProfile(""))
+	require.ErrorContains(t, err, "decrypt Name")
+}
+
+func TestEncryptCommand(t *testing.T) {
+	key := hex.EncodeToString([]byte("1234567890123456"))
+
+	var out bytes.Buffer
+	app := cli.NewApp()
+	app.Writer = &out
+	app.Reader = strings.NewReader("from-stdin\n")
+	app.Commands = []*cli.Command{EncryptCommand()}
+	require.NoError(t, app.Run([]string{"app", "encrypt-config", "--key", key}))
+
+	value := strings.TrimSpace(out.String())
+	require.True(t, strings.HasPrefix(value, EncryptedPrefix))
+
+	keyring, err := parseKeyring(key)
+	require.NoError(t, err)
+	cfg := testConfig{Name: value}
+	require.NoError(t, resolveValues(context.Background(), keyring, nil, &cfg))
+	assert.Equal(t, "from-stdin", cfg.Name)
+}
@@ -0,0 +1,205 @@
+package config
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/urfave/cli/v2"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// WithFlags applies the flags set in the cli context after environment
+// variables are parsed, giving flags > env > file precedence. Flags are
+// expected to be generated by Flags for the same config type.
+func WithFlags(c *cli.Context) LoadConfigOption {
+	return func(o *loadConfigOptions) {
+		o.cliCtx = c
+	}
+}
+
+// Flags generates cli flags for the fields of a config struct. Flag names are
+// taken from the `flag` tag, otherwise the kebab-cased `yaml` tag or field
+// name. Nested struct fields are prefixed with the parent name (e.g.
+// db-host), except for inline fields. A `usage` tag sets the flag usage and
+// `flag:"-"` skips a field.
+//
+// The current values of cfg are shown as flag defaults. Supported field types
+// are strings, bools, ints, uints, floats, durations and string slices.
+//
+// Example:
+//
+//	var cfg AppConfig
+//	app.Flags = config.Flags(&cfg)
+//	app.Action = func(c *cli.Context) error {
+//		config.Load(c.String("config"), &cfg, config.WithFlags(c))
+//		...
+//	}
+func Flags(cfg Configurable) []cli.Flag {
+	// Walk a shallow copy so nil struct pointers of cfg are left untouched.
+	v := reflect.New(reflect.TypeOf(cfg).Elem()).Elem()
+	v.Set(reflect.ValueOf(cfg).Elem())
+
+	var flags []cli.Flag
+	walkFlagFields(v.Type(), func() reflect.Value { return v }, "", func(name string, usage string, field func() reflect.Value) {
+		if flag := newFlag(name, usage, field()); flag != nil {
+			flags = append(flags, flag)
+		}
+	})
+	return flags
+}
+
+func applyFlags(c *cli.Context, out Configurable) error {
+	v := reflect.ValueOf(out).Elem()
+	var err error
+	walkFlagFields(v.Type(), func() reflect.Value { return v }, "", func(name string, _ string, field func() reflect.Value) {
+		if err != nil || !c.IsSet(name) {
+			return
+		}
+		if setErr := setFlagValue(c, name, field()); setErr != nil {
+			err = fmt.Errorf("apply flag %s: %w", name, setErr)
+		}
+	})
+	return err
+}
+
+// walkFlagFields calls fn for every field of struct type t with a getter for
+// the field value. Nil struct pointers are only allocated once a getter below
+// them is called, so unset optional sections stay nil.
+func walkFlagFields(t reflect.Type, get func() reflect.Value, prefix string, fn func(name string, usage string, field func() reflect.Value)) {
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		flagTag := sf.Tag.Get("flag")
+		if flagTag == "-" {
+			continue
+		}
+		yamlName, yamlOpts, _ := strings.Cut(sf.Tag.Get("yaml"), ",")
+		if yamlName == "-" {
+			continue
+		}
+
+		ft := sf.Type
+		field := func() reflect.Value { return get().Field(i) }
+		if ft.Kind() == reflect.Pointer && ft.Elem().Kind() == reflect.Struct {
+			ptr := field
+			field = func() reflect.Value {
+				f := ptr()
+				if f.IsNil() {
+					f.Set(reflect.New(f.Type().Elem()))
+				}
+				return f.Elem()
+			}
+			ft = ft.Elem()
+		}
+
+		name := cmp.Or(flagTag, kebabCase(cmp.Or(yamlName, sf.Name)))
+		if ft.Kind() == reflect.Struct && ft != durationType {
+			if sf.Anonymous || strings.Contains(yamlOpts, "inline") {
+				walkFlagFields(ft, field, prefix, fn)
+			} else {
+				walkFlagFields(ft, field, prefix+name+"-", fn)
+			}
+			continue
+		}
+		if !isFlagType(ft) {
+			continue
+		}
+		fn(prefix+name, sf.Tag.Get("usage"), field)
+	}
+}
+
+func isFlagType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.String
+	default:
+		return false
+	}
+}
+
+func newFlag(name string, usage string, field reflect.Value) cli.Flag {
+	if field.Type() == durationType {
+		return &cli.DurationFlag{Name: name, Usage: usage, Value: time.Duration(field.Int())}
+	}
+	switch field.Kind() {
+	case reflect.String:
+		return &cli.StringFlag{Name: name, Usage: usage, Value: field.String()}
+	case reflect.Bool:
+		return &cli.BoolFlag{Name: name, Usage: usage, Value: field.Bool()}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &cli.Int64Flag{Name: name, Usage: usage, Value: field.Int()}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &cli.Uint64Flag{Name: name, Usage: usage, Value: field.Uint()}
+	case reflect.Float32, reflect.Float64:
+		return &cli.Float64Flag{Name: name, Usage: usage, Value: field.Float()}
+	case reflect.Slice:
+		if field.Type().Elem().Kind() == reflect.String {
+			return &cli.StringSliceFlag{Name: name, Usage: usage, Value: cli.NewStringSlice(field.Interface().([]string)...)}
+		}
+	default:
+	}
+	return nil
+}
+
+func setFlagValue(c *cli.Context, name string, field reflect.Value) error {
+	if field.Type() == durationType {
+		field.SetInt(int64(c.Duration(name)))
+		return nil
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(c.String(name))
+	case reflect.Bool:
+		field.SetBool(c.Bool(name))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		val := c.Int64(name)
+		if field.OverflowInt(val) {
+			return fmt.Errorf("value %d overflows %s", val, field.Type())
+		}
+		field.SetInt(val)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		val := c.Uint64(name)
+		if field.OverflowUint(val) {
+			return fmt.Errorf("value %d overflows %s", val, field.Type())
+		}
+		field.SetUint(val)
+	case reflect.Float32, reflect.Float64:
+		field.SetFloat(c.Float64(name))
+	case reflect.Slice:
+		field.Set(reflect.ValueOf(c.StringSlice(name)).Convert(field.Type()))
+	default:
+	}
+	return nil
+}
+
+func kebabCase(s string) string {
+	var b strings.Builder
+	runes := []rune(s)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			// Start a new word at a lower-to-upper boundary or at the last upper
+			// rune of an acronym (e.g. "CORSOrigins" -> "cors-origins").
+			if i > 0 && (unicode.IsLower(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+				b.WriteByte('-')
+			}
+			r = unicode.ToLower(r)
+		} else if r == '_' {
+			r = '-'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
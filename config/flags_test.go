@@ -0,0 +1,79 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cohesivestack/valgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+type flagConfig struct {
+	Name        string          `yaml:"name" env:"NAME" usage:"service name"`
+	CORSOrigins []string        `yaml:"corsOrigins"`
+	Timeout     time.Duration   `yaml:"timeout"`
+	Secret      string          `flag:"-"`
+	DB          flagDBConfig    `yaml:"db"`
+	Limit       *flagLimit      `yaml:"limit"`
+	Inline      flagInline      `yaml:",inline"`
+	Unsupported map[string]bool `yaml:"unsupported"`
+}
+
+type flagDBConfig struct {
+	Host string `yaml:"host"`
+	Port int    `yaml:"port"`
+}
+
+type flagLimit struct {
+	RPS float64 `yaml:"rps"`
+}
+
+type flagInline struct {
+	Debug bool `flag:"debug-mode"`
+}
+
+func (c *flagConfig) InitDefaults() {
+	c.DB.Port = 5432
+}
+
+func (c *flagConfig) Validation() *valgo.Validation {
+	return valgo.Is(valgo.String(c.Name, "name").Not().Blank())
+}
+
+func TestFlags(t *testing.T) {
+	cfg := flagConfig{}
+	cfg.InitDefaults()
+
+	var names []string
+	for _, flag := range Flags(&cfg) {
+		names = append(names, flag.Names()[0])
+	}
+	assert.Equal(t, []string{"name", "cors-origins", "timeout", "db-host", "db-port", "limit-rps", "debug-mode"}, names)
+	assert.Nil(t, cfg.Limit)
+}
+
+func TestWithFlags(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(file, []byte("name: file\ndb:\n  host: file-host\n"), 0o600))
+	t.Setenv("NAME", "env")
+
+	var cfg flagConfig
+	app := cli.NewApp()
+	app.Flags = Flags(&cfg)
+	app.Action = func(c *cli.Context) error {
+		return load(file, &cfg, WithProfile(""), WithFlags(c))
+	}
+	err := app.Run([]string{"app", "--name", "flag", "--db-port", "6543", "--timeout", "5s", "--cors-origins", "a,b"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "flag", cfg.Name)
+	assert.Equal(t, flagDBConfig{Host: "file-host", Port: 6543}, cfg.DB)
+	assert.Equal(t, 5*time.Second, cfg.Timeout)
+	assert.Equal(t, []string{"a", "b"}, cfg.CORSOrigins)
+	assert.Nil(t, cfg.Limit)
+}
@@ -1,20 +1,47 @@
 package config
 
 import (
+	"context"
 	"embed"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
+	"io/fs"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/caarlos0/env/v11"
 	"github.com/cohesivestack/valgo"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/urfave/cli/v2"
 	"gopkg.in/yaml.v3"
+
+	"github.com/joshjon/kit/encrypt"
+	"github.com/joshjon/kit/secrets"
+)
+
+// ProfileEnvVar is the environment variable used to select the config
+// profile when WithProfile is not provided.
+const ProfileEnvVar = "APP_ENV"
+
+// Format is a config file format.
+type Format string
+
+const (
+	FormatYAML Format = "yaml"
+	FormatJSON Format = "json"
+	FormatTOML Format = "toml"
 )
 
 type loadConfigOptions struct {
-	fs *embed.FS
+	fs        *embed.FS
+	overlays  []string
+	profile   *string
+	format    Format
+	cliCtx    *cli.Context
+	decrypter encrypt.Encrypter
+	secrets   secrets.Provider
 }
 
 type LoadConfigOption func(*loadConfigOptions)
@@ -25,6 +52,30 @@ func WithFS(fs embed.FS) LoadConfigOption {
 	}
 }
 
+// WithOverlays adds YAML files that are deeply merged over the base file in
+// the order provided. Unlike profile files, overlays must exist.
+func WithOverlays(yamlFiles ...string) LoadConfigOption {
+	return func(o *loadConfigOptions) {
+		o.overlays = append(o.overlays, yamlFiles...)
+	}
+}
+
+// WithProfile sets the config profile, overriding the APP_ENV environment
+// variable. An empty profile disables profile files.
+func WithProfile(profile string) LoadConfigOption {
+	return func(o *loadConfigOptions) {
+		o.profile = &profile
+	}
+}
+
+// WithFormat sets the format of all config files. By default the format is
+// detected from each file extension (.json, .toml, otherwise YAML).
+func WithFormat(format Format) LoadConfigOption {
+	return func(o *loadConfigOptions) {
+		o.format = format
+	}
+}
+
 type Configurable interface {
 	InitDefaults()
 	Validation() *valgo.Validation
@@ -32,57 +83,147 @@ type Configurable interface {
 
 // Load reads configuration from a YAML file and/or environment variables.
 // Param `yamlFile` can be left empty if environment variables are being
-// exclusively used.
+// exclusively used. JSON and TOML files are also supported and decoded using
+// `json` and `toml` struct tags respectively, falling back to case-insensitive
+// field names.
+//
+// When a profile is set (via APP_ENV or WithProfile), the profile file next
+// to `yamlFile` is merged over it if present, e.g. config.prod.yaml for
+// config.yaml and profile "prod". Overlays are merged last. Mappings are
+// merged deeply while scalars and sequences are replaced. Environment
+// variables take precedence over all files, and flags provided with WithFlags
+// take precedence over environment variables. Values prefixed with `enc:` are
+// decrypted after all sources are applied (see EncryptValue), and values
+// prefixed with `secret:` are replaced with the secret they reference (see
+// WithSecrets).
 func Load(yamlFile string, out Configurable, opts ...LoadConfigOption) {
-	var options loadConfigOptions
+	if err := load(yamlFile, out, opts...); err != nil {
+		fmt.Fprintln(os.Stderr, "Config errors:")
+		var verr *valgo.Error
+		if errors.As(err, &verr) {
+			for _, valErr := range verr.Errors() {
+				fmt.Fprintf(os.Stderr, "  %s: %s\n", valErr.Name(), strings.Join(valErr.Messages(), ","))
+			}
+		} else {
+			fmt.Fprintln(os.Stderr, fmt.Errorf("  %s", err.Error()))
+		}
+		os.Exit(1)
+	}
+}
+
+// MustLoad is like Load but returns a new populated config value of type T.
+// Like Load, it exits the process if the config cannot be loaded.
+//
+// Example:
+//
+//	cfg := config.MustLoad[AppConfig]("config.yaml")
+func MustLoad[T any, PT interface {
+	*T
+	Configurable
+}](yamlFile string, opts ...LoadConfigOption) T {
+	var out T
+	Load(yamlFile, PT(&out), opts...)
+	return out
+}
+
+func load(yamlFile string, out Configurable, opts ...LoadConfigOption) error {
+	options := loadConfigOptions{}
 	for _, opt := range opts {
 		opt(&options)
 	}
 
-	err := func() error {
-		out.InitDefaults()
-
-		if yamlFile != "" {
-			var file io.ReadCloser
-			var err error
+	profile := os.Getenv(ProfileEnvVar)
+	if options.profile != nil {
+		profile = *options.profile
+	}
 
-			if options.fs != nil {
-				file, err = options.fs.Open(yamlFile)
-			} else {
-				file, err = os.Open(yamlFile)
-			}
-			if err != nil {
-				return fmt.Errorf("open config file: %w", err)
-			}
-			defer file.Close()
+	out.InitDefaults()
 
-			decoder := yaml.NewDecoder(file)
-			if err = decoder.Decode(out); err != nil {
-				return fmt.Errorf("decode config file: %w", err)
+	if yamlFile != "" {
+		if err := decodeFile(options.fs, yamlFile, options.format, out); err != nil {
+			return err
+		}
+		if profile != "" {
+			err := decodeFile(options.fs, profileFile(yamlFile, profile), options.format, out)
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
 			}
 		}
+	}
 
-		if err := env.Parse(out); err != nil {
-			return fmt.Errorf("parse config environment variables: %w", err)
+	for _, overlay := range options.overlays {
+		if err := decodeFile(options.fs, overlay, options.format, out); err != nil {
+			return err
 		}
+	}
+
+	if err := env.Parse(out); err != nil {
+		return fmt.Errorf("parse config environment variables: %w", err)
+	}
 
-		if err := out.Validation().ToError(); err != nil {
+	if options.cliCtx != nil {
+		if err := applyFlags(options.cliCtx, out); err != nil {
 			return err
 		}
+	}
 
-		return nil
-	}()
+	if err := resolveValues(context.Background(), options.decrypter, options.secrets, out); err != nil {
+		return err
+	}
 
+	return out.Validation().ToError()
+}
+
+// decodeFile decodes a config file over out. Decoding into an already
+// populated value only replaces the keys present in the file, which deeply
+// merges successive files.
+func decodeFile(efs *embed.FS, name string, format Format, out any) error {
+	var data []byte
+	var err error
+	if efs != nil {
+		data, err = efs.ReadFile(name)
+	} else {
+		data, err = os.ReadFile(name)
+	}
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "Config errors:")
-		var verr *valgo.Error
-		if errors.As(err, &verr) {
-			for _, valErr := range verr.Errors() {
-				fmt.Fprintf(os.Stderr, "  %s: %s\n", valErr.Name(), strings.Join(valErr.Messages(), ","))
-			}
-		} else {
-			fmt.Fprintln(os.Stderr, fmt.Errorf("  %s", err.Error()))
-		}
-		os.Exit(1)
+		return fmt.Errorf("open config file: %w", err)
+	}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return nil
+	}
+	if format == "" {
+		format = formatFromExt(name)
 	}
+	switch format {
+	case FormatYAML:
+		err = yaml.Unmarshal(data, out)
+	case FormatJSON:
+		err = json.Unmarshal(data, out)
+	case FormatTOML:
+		err = toml.Unmarshal(data, out)
+	default:
+		return fmt.Errorf("unsupported config file format: %s", format)
+	}
+	if err != nil {
+		return fmt.Errorf("decode config file %s: %w", name, err)
+	}
+	return nil
+}
+
+func formatFromExt(name string) Format {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		return FormatJSON
+	case ".toml":
+		return FormatTOML
+	default:
+		return FormatYAML
+	}
+}
+
+// profileFile returns the profile variant of a config file name, e.g.
+// config.prod.yaml for config.yaml.
+func profileFile(yamlFile string, profile string) string {
+	ext := filepath.Ext(yamlFile)
+	return strings.TrimSuffix(yamlFile, ext) + "." + profile + ext
 }
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cohesivestack/valgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testConfig struct {
+	Name   string            `yaml:"name" env:"NAME"`
+	Port   int               `yaml:"port"`
+	DB     testDBConfig      `yaml:"db"`
+	Hosts  []string          `yaml:"hosts"`
+	Labels map[string]string `yaml:"labels"`
+}
+
+type testDBConfig struct {
+	Host string `yaml:"host"`
+	User string `yaml:"user"`
+}
+
+func (c *testConfig) InitDefaults() {
+	c.Port = 8080
+}
+
+func (c *testConfig) Validation() *valgo.Validation {
+	return valgo.Is(valgo.String(c.Name, "name").Not().Blank())
+}
+
+func TestLoad_profile(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "config.yaml", `
+name: base
+db:
+  host: localhost
+  user: app
+hosts: [a, b]
+labels:
+  team: core
+`)
+	writeFile(t, dir, "config.prod.yaml", `
+db:
+  host: prod-db
+hosts: [c]
+labels:
+  env: prod
+`)
+	writeFile(t, dir, "extra.yaml", `
+name: overlay
+`)
+
+	var cfg testConfig
+	err := load(filepath.Join(dir, "config.yaml"), &cfg,
+		WithProfile("prod"),
+		WithOverlays(filepath.Join(dir, "extra.yaml")),
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, testConfig{
+		Name:   "overlay",
+		Port:   8080,
+		DB:     testDBConfig{Host: "prod-db", User: "app"},
+		Hosts:  []string{"c"},
+		Labels: map[string]string{"team": "core", "env": "prod"},
+	}, cfg)
+}
+
+func TestLoad_profileFromEnv(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "config.yaml", "name: base\n")
+	writeFile(t, dir, "config.dev.yaml", "name: dev\n")
+
+	t.Setenv(ProfileEnvVar, "dev")
+	var cfg testConfig
+	require.NoError(t, load(filepath.Join(dir, "config.yaml"), &cfg))
+	assert.Equal(t, "dev", cfg.Name)
+
+	// missing profile files are ignored
+	t.Setenv(ProfileEnvVar, "staging")
+	cfg = testConfig{}
+	require.NoError(t, load(filepath.Join(dir, "config.yaml"), &cfg))
+	assert.Equal(t, "base", cfg.Name)
+
+	// env vars take precedence over files
+	t.Setenv("NAME", "env")
+	cfg = testConfig{}
+	require.NoError(t, load(filepath.Join(dir, "config.yaml"), &cfg))
+	assert.Equal(t, "env", cfg.Name)
+}
+
+func TestLoad_missingOverlay(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "config.yaml", "name: base\n")
+
+	var cfg testConfig
+	err := load(filepath.Join(dir, "config.yaml"), &cfg, WithOverlays(filepath.Join(dir, "missing.yaml")))
+	require.Error(t, err)
+}
+
+func writeFile(t *testing.T, dir string, name string, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+}
+
+func TestMustLoad(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "config.yaml", "name: base\n")
+
+	cfg := MustLoad[testConfig](filepath.Join(dir, "config.yaml"), WithProfile(""))
+	assert.Equal(t, "base", cfg.Name)
+	assert.Equal(t, 8080, cfg.Port)
+}
+
+func TestLoad_formats(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "config.json", `{"name": "json", "db": {"host": "localhost", "user": "app"}}`)
+	writeFile(t, dir, "config.prod.json", `{"db": {"host": "prod-db"}}`)
+	writeFile(t, dir, "config.toml", "name = \"toml\"\nport = 9090\n\n[db]\nhost = \"localhost\"\n")
+	writeFile(t, dir, "config.conf", "name = \"conf\"\n")
+
+	var cfg testConfig
+	require.NoError(t, load(filepath.Join(dir, "config.json"), &cfg, WithProfile("prod")))
+	assert.Equal(t, "json", cfg.Name)
+	assert.Equal(t, testDBConfig{Host: "prod-db", User: "app"}, cfg.DB)
+
+	cfg = testConfig{}
+	require.NoError(t, load(filepath.Join(dir, "config.toml"), &cfg, WithProfile("")))
+	assert.Equal(t, "toml", cfg.Name)
+	assert.Equal(t, 9090, cfg.Port)
+	assert.Equal(t, "localhost", cfg.DB.Host)
+
+	cfg = testConfig{}
+	require.NoError(t, load(filepath.Join(dir, "config.conf"), &cfg, WithProfile(""), WithFormat(FormatTOML)))
+	assert.Equal(t, "conf", cfg.Name)
+}
@@ -0,0 +1,34 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/joshjon/kit/secrets"
+)
+
+// SecretPrefix marks a config value as a reference to a secret. The remainder
+// of the value is the name of the secret, e.g. `secret:db-password`.
+const SecretPrefix = "secret:"
+
+// WithSecrets sets the Provider used to resolve `secret:` prefixed values.
+// Loading a config with secret references fails without it.
+//
+// Example:
+//
+//	config.Load("config.yaml", &cfg, config.WithSecrets(secrets.NewFileProvider("/var/run/secrets/app")))
+func WithSecrets(provider secrets.Provider) LoadConfigOption {
+	return func(o *loadConfigOptions) {
+		o.secrets = provider
+	}
+}
+
+func (d *valueResolver) getSecret(name string, path string) (string, error) {
+	if d.secrets == nil {
+		return "", fmt.Errorf("resolve secret %s of %s: no secrets provider set with WithSecrets", name, path)
+	}
+	secret, err := d.secrets.GetSecret(d.ctx, name)
+	if err != nil {
+		return "", fmt.Errorf("resolve secret %s of %s: %w", name, path, err)
+	}
+	return string(secret), nil
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/secrets"
+)
+
+func TestLoad_secretValues(t *testing.T) {
+	secretsDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(secretsDir, "db-user"), []byte("admin\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(secretsDir, "token"), []byte("s3cret"), 0o600))
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+	content := "name: plain\ndb:\n  user: secret:db-user\nlabels:\n  token: secret:token\n"
+	require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+
+	var cfg testConfig
+	require.NoError(t, load(file, &cfg, WithProfile(""), WithSecrets(secrets.NewFileProvider(secretsDir))))
+	assert.Equal(t, "plain", cfg.Name)
+	assert.Equal(t, "admin", cfg.DB.User)
+	assert.Equal(t, map[string]string{"token": "s3cret"}, cfg.Labels)
+
+	// a provider is required once a secret reference is present
+	cfg = testConfig{}
+	err := load(file, &cfg, WithProfile(""))
+	require.ErrorContains(t, err, "resolve secret db-user of DB.User")
+
+	// missing secrets fail loading
+	require.NoError(t, os.Remove(filepath.Join(secretsDir, "token")))
+	cfg = testConfig{}
+	err = load(file, &cfg, WithProfile(""), WithSecrets(secrets.NewFileProvider(secretsDir)))
+	require.ErrorContains(t, err, "resolve secret token of Labels[token]")
+}
@@ -0,0 +1,59 @@
+package encrypt
+
+import (
+	"context"
+	"errors"
+)
+
+var errorKeyringEmpty = errors.New("keyring has no encrypters")
+
+var _ Encrypter = (*Keyring)(nil)
+
+// Keyring supports key rotation by encrypting with the primary (first)
+// Encrypter and decrypting with whichever Encrypter succeeds first.
+type Keyring struct {
+	encrypters []Encrypter
+}
+
+// NewKeyring creates a new Keyring. The first encrypter is the primary.
+func NewKeyring(primary Encrypter, others ...Encrypter) *Keyring {
+	return &Keyring{
+		encrypters: append([]Encrypter{primary}, others...),
+	}
+}
+
+// NewAESKeyring creates a Keyring of AES encrypters, using the first key as
+// the primary.
+func NewAESKeyring(keys ...[]byte) (*Keyring, error) {
+	if len(keys) == 0 {
+		return nil, errorKeyringEmpty
+	}
+	encrypters := make([]Encrypter, len(keys))
+	for i, key := range keys {
+		aes, err := NewAES(key)
+		if err != nil {
+			return nil, err
+		}
+		encrypters[i] = aes
+	}
+	return NewKeyring(encrypters[0], encrypters[1:]...), nil
+}
+
+// Encrypt encrypts the given plaintext using the primary Encrypter.
+func (k *Keyring) Encrypt(ctx context.Context, plaintext []byte) ([]byte, error) {
+	return k.encrypters[0].Encrypt(ctx, plaintext)
+}
+
+// Decrypt decrypts the given ciphertext by trying each Encrypter in order.
+// The error of every failed attempt is returned if none succeed.
+func (k *Keyring) Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	var errs []error
+	for _, enc := range k.encrypters {
+		plaintext, err := enc.Decrypt(ctx, ciphertext)
+		if err == nil {
+			return plaintext, nil
+		}
+		errs = append(errs, err)
+	}
+	return nil, errors.Join(errs...)
+}
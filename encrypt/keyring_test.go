@@ -0,0 +1,42 @@
+package encrypt
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyring_EncryptDecrypt(t *testing.T) {
+	ctx := context.Background()
+	oldKey := []byte("1234567890123456")
+	newKey := []byte("6543210987654321")
+
+	old, err := NewAESKeyring(oldKey)
+	require.NoError(t, err)
+	ciphertext, err := old.Encrypt(ctx, []byte("secret"))
+	require.NoError(t, err)
+
+	rotated, err := NewAESKeyring(newKey, oldKey)
+	require.NoError(t, err)
+
+	// decrypts values encrypted with a previous key
+	plaintext, err := rotated.Decrypt(ctx, ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", string(plaintext))
+
+	// encrypts with the primary key
+	ciphertext, err = rotated.Encrypt(ctx, []byte("secret"))
+	require.NoError(t, err)
+	_, err = old.Decrypt(ctx, ciphertext)
+	assert.Error(t, err)
+}
+
+func TestNewAESKeyring_invalid(t *testing.T) {
+	_, err := NewAESKeyring()
+	assert.ErrorIs(t, err, errorKeyringEmpty)
+
+	_, err = NewAESKeyring([]byte("short"))
+	assert.ErrorIs(t, err, errorAESKeyLength)
+}
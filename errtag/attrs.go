@@ -0,0 +1,77 @@
+package errtag
+
+import (
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/joshjon/kit/fname"
+)
+
+const pkgPrefix = "github.com/joshjon/kit/errtag."
+
+// WithStack captures the stack trace where the tag is created, which is
+// included by LogAttrs.
+func WithStack() Option {
+	return func(t *tagMeta) {
+		t.stack = trimInternalFrames(fname.Stack(1, 0))
+	}
+}
+
+// LogAttrs returns the structured attributes of the primary tag in err (see
+// Primary) for logging: code, error_code, msg, details, fields (including
+// those added with WithLogField) and stack. Empty attributes are omitted. Returns nil if err has no tag.
+//
+// Example:
+//
+//	logger.Error("create project", slog.Group("error_tag", errtag.LogAttrs(err)...))
+func LogAttrs(err error) []slog.Attr {
+	tag, ok := Primary(err)
+	if !ok {
+		return nil
+	}
+
+	attrs := []slog.Attr{
+		slog.Int("code", tag.Code()),
+	}
+	if code := ErrorCode(tag); code != "" {
+		attrs = append(attrs, slog.String("error_code", code))
+	}
+	attrs = append(attrs, slog.String("msg", tag.Msg()))
+	if details := tag.Details(); len(details) > 0 {
+		attrs = append(attrs, slog.Any("details", details))
+	}
+	fields := Fields(tag)
+	if lf, ok := tag.(interface{ logOnlyFields() map[string]any }); ok && len(lf.logOnlyFields()) > 0 {
+		fields = maps.Clone(fields)
+		if fields == nil {
+			fields = map[string]any{}
+		}
+		maps.Copy(fields, lf.logOnlyFields())
+	}
+	if len(fields) > 0 {
+		fieldAttrs := make([]any, 0, len(fields))
+		for _, key := range slices.Sorted(maps.Keys(fields)) {
+			fieldAttrs = append(fieldAttrs, slog.Any(key, fields[key]))
+		}
+		attrs = append(attrs, slog.Group("fields", fieldAttrs...))
+	}
+	if st, ok := tag.(interface{ stackFrames() fname.Frames }); ok {
+		if stack := st.stackFrames(); len(stack) > 0 {
+			attrs = append(attrs, slog.Any("stack", stack.Strings()))
+		}
+	}
+	return attrs
+}
+
+// trimInternalFrames removes the leading frames of this package where the
+// stack was captured.
+func trimInternalFrames(frames fname.Frames) fname.Frames {
+	for i, f := range frames {
+		if !strings.HasPrefix(f.Func, pkgPrefix) || strings.HasSuffix(f.File, "_test.go") {
+			return frames[i:]
+		}
+	}
+	return nil
+}
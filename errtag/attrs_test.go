@@ -0,0 +1,66 @@
+package errtag
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogAttrs(t *testing.T) {
+	err := Tag[NotFound](errors.New("cause"),
+		WithCode("project_not_found"),
+		WithDetails("detail"),
+		WithField("project_id", "p1"),
+		WithStack(),
+	)
+
+	attrs := map[string]slog.Value{}
+	for _, attr := range LogAttrs(err) {
+		attrs[attr.Key] = attr.Value
+	}
+
+	assert.Equal(t, int64(http.StatusNotFound), attrs["code"].Int64())
+	assert.Equal(t, "project_not_found", attrs["error_code"].String())
+	assert.Equal(t, "Not Found", attrs["msg"].String())
+	assert.Equal(t, []string{"detail"}, attrs["details"].Any())
+	assert.Equal(t, []slog.Attr{slog.String("project_id", "p1")}, attrs["fields"].Group())
+
+	stack, ok := attrs["stack"].Any().([]string)
+	require.True(t, ok)
+	require.NotEmpty(t, stack)
+	assert.True(t, strings.HasPrefix(stack[0], pkgPrefix+"TestLogAttrs "), stack[0])
+}
+
+func TestLogAttrs_logFields(t *testing.T) {
+	tag := Tag[Conflict](errors.New("cause"),
+		WithField("project_id", "p1"),
+		WithLogField("constraint", "projects_name_key"),
+	)
+	assert.Equal(t, map[string]any{"project_id": "p1"}, tag.Fields())
+
+	var fields []slog.Attr
+	for _, attr := range LogAttrs(tag) {
+		if attr.Key == "fields" {
+			fields = attr.Value.Group()
+		}
+	}
+	assert.Equal(t, []slog.Attr{
+		slog.String("constraint", "projects_name_key"),
+		slog.String("project_id", "p1"),
+	}, fields)
+}
+
+func TestLogAttrs_minimal(t *testing.T) {
+	attrs := LogAttrs(NewTagged[Conflict]("cause"))
+	assert.Equal(t, []slog.Attr{
+		slog.Int("code", http.StatusConflict),
+		slog.String("msg", "Conflict"),
+	}, attrs)
+
+	assert.Nil(t, LogAttrs(errors.New("untagged")))
+}
@@ -33,3 +33,11 @@ func (gatewayTimeout) Code() int { return http.StatusGatewayTimeout }
 type badGateway struct{}
 
 func (badGateway) Code() int { return http.StatusBadGateway }
+
+type tooManyRequests struct{}
+
+func (tooManyRequests) Code() int { return http.StatusTooManyRequests }
+
+type unavailable struct{}
+
+func (unavailable) Code() int { return http.StatusServiceUnavailable }
@@ -0,0 +1,50 @@
+package errtag
+
+// Collect returns all tags present in the error tree of err in depth-first
+// order, traversing both wrapped and joined errors (errors.Join or
+// fmt.Errorf with multiple %w verbs). Tags wrapped by another tag are
+// included after the tag wrapping them.
+func Collect(err error) []Tagger {
+	var tags []Tagger
+	walkTags(err, false, func(tag Tagger, _ bool) {
+		tags = append(tags, tag)
+	})
+	return tags
+}
+
+// Primary returns the tag that determines how err is rendered when it
+// contains multiple tags, such as joined errors. The rules are:
+//
+//   - A tag wrapping another tag takes precedence over the tag it wraps.
+//   - A server error (5xx) takes precedence over a client error (4xx).
+//   - Otherwise the first tag in depth-first order wins.
+func Primary(err error) (Tagger, bool) {
+	var primary Tagger
+	walkTags(err, false, func(tag Tagger, nested bool) {
+		if nested {
+			return
+		}
+		if primary == nil || HTTPStatus(tag)/100 > HTTPStatus(primary)/100 {
+			primary = tag
+		}
+	})
+	return primary, primary != nil
+}
+
+func walkTags(err error, nested bool, fn func(tag Tagger, nested bool)) {
+	if err == nil {
+		return
+	}
+	if tag, ok := err.(Tagger); ok {
+		fn(tag, nested)
+		nested = true
+	}
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		walkTags(e.Unwrap(), nested, fn)
+	case interface{ Unwrap() []error }:
+		for _, err := range e.Unwrap() {
+			walkTags(err, nested, fn)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package errtag
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollect(t *testing.T) {
+	notFound := Tag[NotFound](errors.New("not found"))
+	invalid := Tag[InvalidArgument](errors.New("invalid"))
+	internal := Tag[Internal](notFound)
+
+	err := errors.Join(
+		fmt.Errorf("get project: %w", invalid),
+		errors.New("untagged"),
+		internal,
+	)
+
+	tags := Collect(err)
+	require.Len(t, tags, 3)
+	assert.Equal(t, http.StatusBadRequest, tags[0].Code())
+	assert.Equal(t, http.StatusInternalServerError, tags[1].Code())
+	assert.Equal(t, http.StatusNotFound, tags[2].Code())
+
+	assert.Empty(t, Collect(errors.New("untagged")))
+	assert.Empty(t, Collect(nil))
+}
+
+func TestPrimary(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode int
+		wantOK   bool
+	}{
+		{
+			name:     "server error wins over client error",
+			err:      errors.Join(Tag[NotFound](errors.New("a")), Tag[BadGateway](errors.New("b"))),
+			wantCode: http.StatusBadGateway,
+			wantOK:   true,
+		},
+		{
+			name:     "first wins within same class",
+			err:      errors.Join(Tag[NotFound](errors.New("a")), Tag[Conflict](errors.New("b"))),
+			wantCode: http.StatusNotFound,
+			wantOK:   true,
+		},
+		{
+			name:     "wrapping tag wins over wrapped tag",
+			err:      Tag[NotFound](Tag[Internal](errors.New("a"))),
+			wantCode: http.StatusNotFound,
+			wantOK:   true,
+		},
+		{
+			name:     "multiple wrapped errors",
+			err:      fmt.Errorf("%w; %w", errors.New("a"), Tag[Forbidden](errors.New("b"))),
+			wantCode: http.StatusForbidden,
+			wantOK:   true,
+		},
+		{
+			name: "untagged",
+			err:  errors.New("a"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tag, ok := Primary(tt.err)
+			require.Equal(t, tt.wantOK, ok)
+			if ok {
+				assert.Equal(t, tt.wantCode, tag.Code())
+			}
+		})
+	}
+}
+
+func TestAsTag_joined(t *testing.T) {
+	err := errors.Join(errors.New("untagged"), fmt.Errorf("wrapped: %w", Tag[Conflict](errors.New("conflict"))))
+	assert.True(t, HasTag[Conflict](err))
+	assert.False(t, HasTag[NotFound](err))
+}
@@ -0,0 +1,44 @@
+package errtag
+
+import "fmt"
+
+// Wrapf tags err after wrapping it with a formatted message, equivalent to
+// Tag[T](fmt.Errorf(format+": %w", args..., err)). Options may be passed in
+// args and are applied to the tag rather than formatted. Returns nil if err
+// is nil.
+//
+// Example:
+//
+//	return errtag.Wrapf[errtag.NotFound](err, "get project %s", id, errtag.WithCode("project_not_found"))
+func Wrapf[T Tagger, TP TaggerPtr[T]](err error, format string, args ...any) error {
+	if err == nil {
+		return nil
+	}
+	fmtArgs, opts := splitOptions(args)
+	return Tag[T, TP](fmt.Errorf(format+": %w", append(fmtArgs, err)...), opts...)
+}
+
+// Errorf creates a tagged error from a formatted cause, equivalent to
+// Tag[T](fmt.Errorf(format, args...)) so %w is supported. Options may be
+// passed in args and are applied to the tag rather than formatted.
+//
+// Example:
+//
+//	return errtag.Errorf[errtag.InvalidArgument]("invalid page size %d", size, errtag.WithMsg("Invalid page size"))
+func Errorf[T Tagger, TP TaggerPtr[T]](format string, args ...any) error {
+	fmtArgs, opts := splitOptions(args)
+	return Tag[T, TP](fmt.Errorf(format, fmtArgs...), opts...)
+}
+
+func splitOptions(args []any) ([]any, []Option) {
+	fmtArgs := make([]any, 0, len(args))
+	var opts []Option
+	for _, arg := range args {
+		if opt, ok := arg.(Option); ok {
+			opts = append(opts, opt)
+			continue
+		}
+		fmtArgs = append(fmtArgs, arg)
+	}
+	return fmtArgs, opts
+}
@@ -0,0 +1,36 @@
+package errtag
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrapf(t *testing.T) {
+	cause := errors.New("no rows")
+	err := Wrapf[NotFound](cause, "get project %s", "p1", WithCode("project_not_found"), WithMsg("Project not found"))
+
+	tag, ok := AsTag[NotFound](err)
+	require.True(t, ok)
+	assert.Equal(t, "get project p1: no rows", tag.Error())
+	assert.Equal(t, "project_not_found", tag.ErrorCode())
+	assert.Equal(t, "Project not found", tag.Msg())
+	assert.ErrorIs(t, err, cause)
+
+	assert.NoError(t, Wrapf[NotFound](nil, "get project %s", "p1"))
+}
+
+func TestErrorf(t *testing.T) {
+	cause := errors.New("too large")
+	err := Errorf[InvalidArgument]("invalid page size %d: %w", 500, cause, WithField("max", 100))
+
+	tag, ok := AsTag[InvalidArgument](err)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusBadRequest, tag.Code())
+	assert.Equal(t, "invalid page size 500: too large", tag.Error())
+	assert.Equal(t, map[string]any{"max": 100}, tag.Fields())
+	assert.ErrorIs(t, err, cause)
+}
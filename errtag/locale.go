@@ -0,0 +1,70 @@
+package errtag
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+)
+
+// MessageResolver resolves localized user-facing error messages.
+type MessageResolver interface {
+	// ResolveMessage returns the message for a code in the given language
+	// (a BCP 47 tag such as "de" or "pt-BR"), and false if none exists. The
+	// code is the tag ErrorCode if set, otherwise its numeric code (e.g.
+	// "404").
+	ResolveMessage(code string, lang string) (string, bool)
+}
+
+// MessageResolverFunc is a function adapter for MessageResolver.
+type MessageResolverFunc func(code string, lang string) (string, bool)
+
+func (f MessageResolverFunc) ResolveMessage(code string, lang string) (string, bool) {
+	return f(code, lang)
+}
+
+var messageResolver atomic.Pointer[MessageResolver]
+
+// SetMessageResolver sets the MessageResolver consulted by MsgContext. A nil
+// resolver disables localization.
+func SetMessageResolver(r MessageResolver) {
+	if r == nil {
+		messageResolver.Store(nil)
+		return
+	}
+	messageResolver.Store(&r)
+}
+
+type localeCtxKey struct{}
+
+// WithLocale returns a copy of ctx carrying the preferred language used to
+// localize error messages.
+func WithLocale(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, localeCtxKey{}, lang)
+}
+
+// LocaleFromContext returns the preferred language stored in ctx.
+func LocaleFromContext(ctx context.Context) (string, bool) {
+	lang, ok := ctx.Value(localeCtxKey{}).(string)
+	return lang, ok && lang != ""
+}
+
+// MsgContext returns the message localized by the MessageResolver when ctx
+// carries a locale, falling back to Msg.
+func (t ErrorTag[C]) MsgContext(ctx context.Context) string {
+	lang, ok := LocaleFromContext(ctx)
+	if !ok {
+		return t.Msg()
+	}
+	r := messageResolver.Load()
+	if r == nil {
+		return t.Msg()
+	}
+	code := t.ErrorCode()
+	if code == "" {
+		code = strconv.Itoa(t.Code())
+	}
+	if msg, ok := (*r).ResolveMessage(code, lang); ok {
+		return msg
+	}
+	return t.Msg()
+}
@@ -0,0 +1,37 @@
+package errtag
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorTag_MsgContext(t *testing.T) {
+	SetMessageResolver(MessageResolverFunc(func(code string, lang string) (string, bool) {
+		messages := map[string]map[string]string{
+			"de": {
+				"404":               "Nicht gefunden",
+				"project_not_found": "Projekt nicht gefunden",
+			},
+		}
+		msg, ok := messages[lang][code]
+		return msg, ok
+	}))
+	t.Cleanup(func() { SetMessageResolver(nil) })
+
+	ctx := WithLocale(context.Background(), "de")
+
+	notFound := Tag[NotFound](errors.New("cause"))
+	assert.Equal(t, "Nicht gefunden", notFound.MsgContext(ctx))
+	assert.Equal(t, "Not Found", notFound.MsgContext(context.Background()))
+
+	withCode := Tag[NotFound](errors.New("cause"), WithCode("project_not_found"))
+	assert.Equal(t, "Projekt nicht gefunden", withCode.MsgContext(ctx))
+
+	// falls back to Msg when unresolved
+	conflict := Tag[Conflict](errors.New("cause"), WithMsg("Already exists"))
+	assert.Equal(t, "Already exists", conflict.MsgContext(ctx))
+	assert.Equal(t, "Already exists", conflict.MsgContext(WithLocale(context.Background(), "fr")))
+}
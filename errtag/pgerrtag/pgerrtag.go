@@ -0,0 +1,53 @@
+// Package pgerrtag tags Postgres errors with errtag tags, keeping the pgx
+// dependency out of the errtag package.
+package pgerrtag
+
+import (
+	"errors"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/joshjon/kit/errtag"
+)
+
+// FromPgError tags a Postgres error based on its SQLSTATE code:
+//
+//   - 23505 unique_violation: Conflict
+//   - 23503 foreign_key_violation, 23514 check_violation and 23502
+//     not_null_violation: InvalidArgument
+//   - 57014 query_canceled (e.g. statement_timeout): GatewayTimeout
+//
+// The violated constraint, table and column are added as log-only fields (see
+// errtag.WithLogField) when present, so schema details are not exposed to
+// clients.
+// Returns err unchanged if it is not a Postgres error or its code is not
+// mapped, and nil if err is nil.
+func FromPgError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	var opts []errtag.Option
+	for key, val := range map[string]string{
+		"constraint": pgErr.ConstraintName,
+		"table":      pgErr.TableName,
+		"column":     pgErr.ColumnName,
+	} {
+		if val != "" {
+			opts = append(opts, errtag.WithLogField(key, val))
+		}
+	}
+
+	switch pgErr.Code {
+	case pgerrcode.UniqueViolation:
+		return errtag.Tag[errtag.Conflict](err, opts...)
+	case pgerrcode.ForeignKeyViolation, pgerrcode.CheckViolation, pgerrcode.NotNullViolation:
+		return errtag.Tag[errtag.InvalidArgument](err, opts...)
+	case pgerrcode.QueryCanceled:
+		return errtag.Tag[errtag.GatewayTimeout](err, opts...)
+	default:
+		return err
+	}
+}
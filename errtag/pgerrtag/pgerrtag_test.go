@@ -0,0 +1,61 @@
+package pgerrtag
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/errtag"
+)
+
+func TestFromPgError(t *testing.T) {
+	tests := []struct {
+		name     string
+		code     string
+		wantCode int
+	}{
+		{name: "unique violation", code: "23505", wantCode: http.StatusConflict},
+		{name: "foreign key violation", code: "23503", wantCode: http.StatusBadRequest},
+		{name: "check violation", code: "23514", wantCode: http.StatusBadRequest},
+		{name: "not null violation", code: "23502", wantCode: http.StatusBadRequest},
+		{name: "query canceled", code: "57014", wantCode: http.StatusGatewayTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pgErr := &pgconn.PgError{Code: tt.code, ConstraintName: "projects_name_key", TableName: "projects"}
+			err := FromPgError(fmt.Errorf("insert project: %w", pgErr))
+
+			tag, ok := errtag.Primary(err)
+			require.True(t, ok)
+			assert.Equal(t, tt.wantCode, tag.Code())
+			assert.ErrorIs(t, err, pgErr)
+
+			// Schema details are logged but not exposed to clients.
+			assert.Nil(t, errtag.Fields(tag))
+			var fields []slog.Attr
+			for _, attr := range errtag.LogAttrs(err) {
+				if attr.Key == "fields" {
+					fields = attr.Value.Group()
+				}
+			}
+			assert.Equal(t, []slog.Attr{
+				slog.String("constraint", "projects_name_key"),
+				slog.String("table", "projects"),
+			}, fields)
+		})
+	}
+
+	unmapped := &pgconn.PgError{Code: "42P01"}
+	assert.Equal(t, unmapped, FromPgError(unmapped))
+
+	plain := errors.New("plain")
+	assert.Equal(t, plain, FromPgError(plain))
+	assert.NoError(t, FromPgError(nil))
+}
@@ -0,0 +1,116 @@
+package errtag
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"sync"
+)
+
+// MinCustomCode is the lowest code an application defined Coder may use.
+// Lower codes are reserved for HTTP status codes.
+const MinCustomCode = 1000
+
+// CodeInfo describes a registered code.
+type CodeInfo struct {
+	Code        int    `json:"code"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	HTTPStatus  int    `json:"http_status"`
+}
+
+var registry = struct {
+	mu    sync.RWMutex
+	codes map[int]CodeInfo
+}{
+	codes: map[int]CodeInfo{},
+}
+
+func init() {
+	for _, info := range []CodeInfo{
+		{Code: codeInternal{}.Code(), Name: "internal", Description: "An unexpected internal error occurred."},
+		{Code: codeUnauthorized{}.Code(), Name: "unauthorized", Description: "The request is not authenticated."},
+		{Code: codeBadRequest{}.Code(), Name: "invalid_argument", Description: "The request contains an invalid argument."},
+		{Code: codeNotFound{}.Code(), Name: "not_found", Description: "The requested resource was not found."},
+		{Code: codeConflict{}.Code(), Name: "conflict", Description: "The request conflicts with the current state of the resource."},
+		{Code: forbidden{}.Code(), Name: "forbidden", Description: "The request is not permitted."},
+		{Code: gatewayTimeout{}.Code(), Name: "gateway_timeout", Description: "An upstream service timed out."},
+		{Code: badGateway{}.Code(), Name: "bad_gateway", Description: "An upstream service failed."},
+		{Code: tooManyRequests{}.Code(), Name: "too_many_requests", Description: "The rate limit was exceeded."},
+		{Code: unavailable{}.Code(), Name: "unavailable", Description: "The service is temporarily unavailable."},
+	} {
+		info.HTTPStatus = info.Code
+		registry.codes[info.Code] = info
+	}
+}
+
+// Register registers an application defined Coder C with a code of at least
+// MinCustomCode, allowing the tag taxonomy to be extended. The code is
+// rendered with the given HTTP status, and the name and description are used
+// as the default error code and message of tags.
+//
+// Example:
+//
+//	type quotaExceeded struct{}
+//
+//	func (quotaExceeded) Code() int { return 1001 }
+//
+//	type QuotaExceeded struct{ errtag.ErrorTag[quotaExceeded] }
+//
+//	func init() {
+//		errtag.MustRegister[quotaExceeded]("quota_exceeded", "The quota was exceeded.", http.StatusTooManyRequests)
+//	}
+func Register[C Coder](name string, description string, httpStatus int) error {
+	var c C
+	code := c.Code()
+	if code < MinCustomCode {
+		return fmt.Errorf("register code %d: custom codes must be at least %d", code, MinCustomCode)
+	}
+	if name == "" {
+		return fmt.Errorf("register code %d: name is required", code)
+	}
+	if http.StatusText(httpStatus) == "" {
+		return fmt.Errorf("register code %d: invalid http status %d", code, httpStatus)
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	if existing, ok := registry.codes[code]; ok {
+		return fmt.Errorf("register code %d: already registered as %s", code, existing.Name)
+	}
+	registry.codes[code] = CodeInfo{
+		Code:        code,
+		Name:        name,
+		Description: description,
+		HTTPStatus:  httpStatus,
+	}
+	return nil
+}
+
+// MustRegister is like Register but panics on error.
+func MustRegister[C Coder](name string, description string, httpStatus int) {
+	if err := Register[C](name, description, httpStatus); err != nil {
+		panic(err)
+	}
+}
+
+// Lookup returns the registered info of a code.
+func Lookup(code int) (CodeInfo, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	info, ok := registry.codes[code]
+	return info, ok
+}
+
+// List returns all registered codes, including the built-in codes, sorted by
+// code. Useful for generating error documentation.
+func List() []CodeInfo {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	out := make([]CodeInfo, 0, len(registry.codes))
+	for _, code := range slices.Sorted(maps.Keys(registry.codes)) {
+		out = append(out, registry.codes[code])
+	}
+	return out
+}
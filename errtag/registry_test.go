@@ -0,0 +1,66 @@
+package errtag
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type quotaExceeded struct{}
+
+func (quotaExceeded) Code() int { return 1001 }
+
+type QuotaExceeded struct{ ErrorTag[quotaExceeded] }
+
+type unregisteredCode struct{}
+
+func (unregisteredCode) Code() int { return 1999 }
+
+type lowCode struct{}
+
+func (lowCode) Code() int { return 999 }
+
+func TestRegister(t *testing.T) {
+	require.NoError(t, Register[quotaExceeded]("quota_exceeded", "The quota was exceeded.", http.StatusTooManyRequests))
+	t.Cleanup(func() { unregister(quotaExceeded{}.Code()) })
+
+	err := Register[quotaExceeded]("quota_exceeded", "", http.StatusTooManyRequests)
+	assert.ErrorContains(t, err, "already registered")
+
+	tag := Tag[QuotaExceeded](errors.New("cause"))
+	assert.Equal(t, 1001, tag.Code())
+	assert.Equal(t, http.StatusTooManyRequests, tag.HTTPStatus())
+	assert.Equal(t, "quota_exceeded", tag.ErrorCode())
+	assert.Equal(t, "The quota was exceeded.", tag.Msg())
+
+	info, ok := Lookup(1001)
+	require.True(t, ok)
+	assert.Equal(t, CodeInfo{Code: 1001, Name: "quota_exceeded", Description: "The quota was exceeded.", HTTPStatus: 429}, info)
+
+	list := List()
+	assert.Equal(t, 1001, list[len(list)-1].Code)
+	assert.Equal(t, CodeInfo{Code: 400, Name: "invalid_argument", Description: "The request contains an invalid argument.", HTTPStatus: 400}, list[0])
+}
+
+func TestRegister_invalid(t *testing.T) {
+	assert.ErrorContains(t, Register[lowCode]("low", "", http.StatusBadRequest), "at least 1000")
+	assert.ErrorContains(t, Register[unregisteredCode]("", "", http.StatusBadRequest), "name is required")
+	assert.ErrorContains(t, Register[unregisteredCode]("bad_status", "", 42), "invalid http status")
+	assert.Panics(t, func() { MustRegister[lowCode]("low", "", http.StatusBadRequest) })
+}
+
+func TestErrorTag_unregisteredCustomCode(t *testing.T) {
+	tag := Tag[ErrorTag[unregisteredCode]](errors.New("cause"))
+	assert.Equal(t, http.StatusInternalServerError, tag.HTTPStatus())
+	assert.Equal(t, "Internal Server Error", tag.Msg())
+	assert.Empty(t, tag.ErrorCode())
+}
+
+func unregister(code int) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	delete(registry.codes, code)
+}
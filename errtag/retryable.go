@@ -0,0 +1,21 @@
+package errtag
+
+import "net/http"
+
+// Retryable reports whether the primary tag of err classifies it as transient,
+// meaning the operation that returned it may succeed when retried. Errors
+// tagged GatewayTimeout, BadGateway, TooManyRequests or Unavailable, or with
+// a registered code of the same HTTP status, are retryable. Untagged errors
+// are not classified and report false.
+func Retryable(err error) bool {
+	tag, ok := Primary(err)
+	if !ok {
+		return false
+	}
+	switch HTTPStatus(tag) {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package errtag
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "unavailable", err: Tag[Unavailable](errors.New("down")), want: true},
+		{name: "gateway timeout", err: Tag[GatewayTimeout](errors.New("timeout")), want: true},
+		{name: "bad gateway", err: Tag[BadGateway](errors.New("bad gateway")), want: true},
+		{name: "too many requests", err: Tag[TooManyRequests](errors.New("slow down")), want: true},
+		{name: "wrapped", err: fmt.Errorf("call: %w", Tag[Unavailable](errors.New("down"))), want: true},
+		{name: "not found", err: Tag[NotFound](errors.New("not found")), want: false},
+		{name: "internal", err: Tag[Internal](errors.New("bug")), want: false},
+		{name: "untagged", err: errors.New("untagged"), want: false},
+		{name: "nil", err: nil, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Retryable(tt.err))
+		})
+	}
+}
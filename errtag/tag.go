@@ -1,9 +1,13 @@
 package errtag
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"net/http"
+
+	"github.com/joshjon/kit/fname"
 )
 
 type Option func(m *tagMeta)
@@ -25,6 +29,38 @@ func WithDetails(details ...string) Option {
 	}
 }
 
+// WithCode sets a stable machine-readable error code (e.g.
+// "project_not_found") that clients can branch on instead of the message.
+func WithCode(code string) Option {
+	return func(t *tagMeta) {
+		t.errCode = code
+	}
+}
+
+// WithField adds a key/value metadata field (e.g. a resource ID or limit).
+// Fields accumulate across options, with later values replacing earlier ones
+// of the same key.
+func WithField(key string, value any) Option {
+	return func(t *tagMeta) {
+		if t.fields == nil {
+			t.fields = map[string]any{}
+		}
+		t.fields[key] = value
+	}
+}
+
+// WithLogField adds a key/value metadata field that is only logged (see
+// LogAttrs) and not returned by Fields, e.g. internal details such as a
+// database constraint that must not be exposed to clients.
+func WithLogField(key string, value any) Option {
+	return func(t *tagMeta) {
+		if t.logFields == nil {
+			t.logFields = map[string]any{}
+		}
+		t.logFields[key] = value
+	}
+}
+
 type Tagger interface {
 	error
 	Code() int
@@ -32,6 +68,66 @@ type Tagger interface {
 	Details() []string
 }
 
+// HTTPStatuser is optionally implemented by a Tagger whose code is not an HTTP
+// status, e.g. a custom code (see Register). See HTTPStatus.
+type HTTPStatuser interface {
+	HTTPStatus() int
+}
+
+// ContextMessager is optionally implemented by a Tagger whose message depends
+// on the context, e.g. its locale (see WithLocale). See MsgContext.
+type ContextMessager interface {
+	MsgContext(ctx context.Context) string
+}
+
+// ErrorCoder is optionally implemented by a Tagger with a machine-readable
+// error code (see WithCode). See ErrorCode.
+type ErrorCoder interface {
+	ErrorCode() string
+}
+
+// Fielder is optionally implemented by a Tagger with metadata fields (see
+// WithField). See Fields.
+type Fielder interface {
+	Fields() map[string]any
+}
+
+// HTTPStatus returns the HTTP status used to render tag, which is its code
+// unless it implements HTTPStatuser.
+func HTTPStatus(tag Tagger) int {
+	if s, ok := tag.(HTTPStatuser); ok {
+		return s.HTTPStatus()
+	}
+	return tag.Code()
+}
+
+// MsgContext returns the message of tag for ctx, which is its Msg unless it
+// implements ContextMessager.
+func MsgContext(ctx context.Context, tag Tagger) string {
+	if m, ok := tag.(ContextMessager); ok {
+		return m.MsgContext(ctx)
+	}
+	return tag.Msg()
+}
+
+// ErrorCode returns the machine-readable error code of tag, or an empty string
+// if it does not implement ErrorCoder.
+func ErrorCode(tag Tagger) string {
+	if c, ok := tag.(ErrorCoder); ok {
+		return c.ErrorCode()
+	}
+	return ""
+}
+
+// Fields returns the metadata fields of tag, or nil if it does not implement
+// Fielder.
+func Fields(tag Tagger) map[string]any {
+	if f, ok := tag.(Fielder); ok {
+		return f.Fields()
+	}
+	return nil
+}
+
 type TaggerPtr[T any] interface {
 	*T
 	init(cause error, opts ...Option)
@@ -58,9 +154,13 @@ type ErrorTag[C Coder] struct {
 }
 
 type tagMeta struct {
-	cause   error
-	msg     string
-	details []string
+	cause     error
+	msg       string
+	details   []string
+	errCode   string
+	fields    map[string]any
+	logFields map[string]any
+	stack     fname.Frames
 }
 
 func (t ErrorTag[C]) Error() string {
@@ -83,17 +183,63 @@ func (t ErrorTag[C]) Code() int {
 	return c.Code()
 }
 
+// HTTPStatus returns the HTTP status used to render the tag. Custom codes
+// (see Register) use their registered status, or 500 if unregistered.
+func (t ErrorTag[C]) HTTPStatus() int {
+	code := t.Code()
+	if code < MinCustomCode {
+		return code
+	}
+	if info, ok := Lookup(code); ok {
+		return info.HTTPStatus
+	}
+	return http.StatusInternalServerError
+}
+
 func (t ErrorTag[C]) Msg() string {
-	if t.msg == "" {
-		return http.StatusText(t.Code())
+	if t.msg != "" {
+		return t.msg
 	}
-	return t.msg
+	if text := http.StatusText(t.Code()); text != "" {
+		return text
+	}
+	if info, ok := Lookup(t.Code()); ok && info.Description != "" {
+		return info.Description
+	}
+	return http.StatusText(t.HTTPStatus())
 }
 
 func (t ErrorTag[C]) Details() []string {
 	return t.details
 }
 
+// ErrorCode returns the machine-readable error code set with WithCode. Custom
+// codes default to their registered name, otherwise it is empty if not set.
+func (t ErrorTag[C]) ErrorCode() string {
+	if t.errCode == "" && t.Code() >= MinCustomCode {
+		if info, ok := Lookup(t.Code()); ok {
+			return info.Name
+		}
+	}
+	return t.errCode
+}
+
+// Fields returns a copy of the metadata fields set with WithField.
+func (t ErrorTag[C]) Fields() map[string]any {
+	if len(t.fields) == 0 {
+		return nil
+	}
+	return maps.Clone(t.fields)
+}
+
+func (t ErrorTag[C]) logOnlyFields() map[string]any {
+	return t.logFields
+}
+
+func (t ErrorTag[C]) stackFrames() fname.Frames {
+	return t.stack
+}
+
 func (t *ErrorTag[C]) init(cause error, opts ...Option) {
 	t.cause = cause
 	for _, opt := range opts {
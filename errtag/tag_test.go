@@ -1,7 +1,9 @@
 package errtag
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"testing"
 
@@ -54,3 +56,54 @@ func TestNewTagged(t *testing.T) {
 	assert.Equal(t, "unauthorized", asUnauthorized.Msg())
 	assert.Equal(t, "unauthorized access", asUnauthorized.Error())
 }
+
+func TestWithCode(t *testing.T) {
+	tag := Tag[NotFound](errors.New("cause error"), WithCode("project_not_found"))
+	assert.Equal(t, "project_not_found", tag.ErrorCode())
+
+	tag = Tag[NotFound](errors.New("cause error"))
+	assert.Empty(t, tag.ErrorCode())
+}
+
+func TestWithField(t *testing.T) {
+	tag := Tag[TooManyRequests](errors.New("cause error"),
+		WithField("limit", 10),
+		WithField("user_id", "u1"),
+		WithField("limit", 20),
+	)
+	assert.Equal(t, map[string]any{"limit": 20, "user_id": "u1"}, tag.Fields())
+
+	// returned fields are a copy
+	tag.Fields()["limit"] = 0
+	assert.Equal(t, 20, tag.Fields()["limit"])
+
+	assert.Nil(t, Tag[NotFound](errors.New("cause error")).Fields())
+}
+
+// legacyTag implements only the methods required by Tagger.
+type legacyTag struct{}
+
+func (legacyTag) Error() string     { return "legacy" }
+func (legacyTag) Code() int         { return http.StatusConflict }
+func (legacyTag) Msg() string       { return "legacy message" }
+func (legacyTag) Details() []string { return nil }
+
+func TestOptionalTaggerInterfaces(t *testing.T) {
+	var legacy Tagger = legacyTag{}
+	assert.Equal(t, http.StatusConflict, HTTPStatus(legacy))
+	assert.Equal(t, "legacy message", MsgContext(WithLocale(context.Background(), "de"), legacy))
+	assert.Empty(t, ErrorCode(legacy))
+	assert.Nil(t, Fields(legacy))
+
+	tag, ok := Primary(fmt.Errorf("wrapped: %w", legacy))
+	require.True(t, ok)
+	assert.Equal(t, legacy, tag)
+
+	var full Tagger = Tag[QuotaExceeded](errors.New("cause"), WithCode("quota"), WithField("limit", 10))
+	require.NoError(t, Register[quotaExceeded]("quota_exceeded", "", http.StatusTooManyRequests))
+	t.Cleanup(func() { unregister(quotaExceeded{}.Code()) })
+	assert.Equal(t, http.StatusTooManyRequests, HTTPStatus(full))
+	assert.Equal(t, "quota", ErrorCode(full))
+	assert.Equal(t, map[string]any{"limit": 10}, Fields(full))
+	assert.Equal(t, full.Msg(), MsgContext(context.Background(), full))
+}
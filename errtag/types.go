@@ -15,3 +15,7 @@ type Forbidden struct{ ErrorTag[forbidden] }
 type GatewayTimeout struct{ ErrorTag[gatewayTimeout] }
 
 type BadGateway struct{ ErrorTag[badGateway] }
+
+type TooManyRequests struct{ ErrorTag[tooManyRequests] }
+
+type Unavailable struct{ ErrorTag[unavailable] }
@@ -0,0 +1,103 @@
+// Package events publishes domain events at least once. Events are written to
+// an outbox in the same transaction as the changes they describe, e.g. with a
+// PostgresOutbox, and a Relay publishes them to a Bus, e.g. NATS JetStream
+// with a NATSBus, or a MemoryBus in tests.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/joshjon/kit/id"
+)
+
+// Event is a domain event. Events are delivered at least once, so handlers
+// should be idempotent, e.g. by deduplicating on ID.
+type Event struct {
+	ID string `json:"id"`
+	// Subject is the subject the event is published to, e.g. orders.created.
+	Subject string          `json:"subject"`
+	Type    string          `json:"type"`
+	Data    json.RawMessage `json:"data"`
+	Time    time.Time       `json:"time"`
+}
+
+// New creates an Event of eventType with data encoded as JSON, to be
+// published to subject.
+func New(subject string, eventType string, data any) (Event, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, fmt.Errorf("marshal event data: %w", err)
+	}
+	return Event{
+		ID:      id.NewUUIDv7().String(),
+		Subject: subject,
+		Type:    eventType,
+		Data:    b,
+		Time:    time.Now().UTC(),
+	}, nil
+}
+
+// Decode decodes the JSON data of the event into v.
+func (e Event) Decode(v any) error {
+	if err := json.Unmarshal(e.Data, v); err != nil {
+		return fmt.Errorf("decode data of event %s: %w", e.ID, err)
+	}
+	return nil
+}
+
+// Publisher publishes events.
+type Publisher interface {
+	// Publish publishes events in order. Events may have been published even
+	// if an error is returned, so publishing them again may duplicate them.
+	Publish(ctx context.Context, events ...Event) error
+}
+
+// Handler processes an event. Returning an error redelivers the event, unless
+// wrapped with Permanent.
+type Handler func(ctx context.Context, event Event) error
+
+// Subscriber subscribes to published events.
+type Subscriber interface {
+	// Subscribe calls handler with the events published to subjects matching
+	// subject, which may contain the NATS wildcards * and >, until the
+	// returned Subscription is stopped.
+	Subscribe(ctx context.Context, subject string, handler Handler) (Subscription, error)
+}
+
+// Subscription is a subscription created by a Subscriber.
+type Subscription interface {
+	// Stop stops the subscription and waits for the events being handled to
+	// return.
+	Stop()
+}
+
+// Bus is a Publisher and Subscriber.
+type Bus interface {
+	Publisher
+	Subscriber
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err returned by a Handler so the event is dropped rather
+// than redelivered.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+func isPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
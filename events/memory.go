@@ -0,0 +1,106 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// MemoryBus is an in-memory Bus for tests and single process services.
+// Publish calls the handlers of matching subscriptions synchronously,
+// returning their errors so the events are published again, e.g. by a Relay.
+type MemoryBus struct {
+	mu     sync.RWMutex
+	nextID int
+	subs   map[int]*memorySubscription
+}
+
+var _ Bus = (*MemoryBus)(nil)
+
+// NewMemoryBus creates a new MemoryBus.
+func NewMemoryBus() *MemoryBus {
+	return &MemoryBus{subs: map[int]*memorySubscription{}}
+}
+
+func (b *MemoryBus) Publish(ctx context.Context, events ...Event) error {
+	b.mu.RLock()
+	subs := make([]*memorySubscription, 0, len(b.subs))
+	for _, sub := range b.subs {
+		subs = append(subs, sub)
+	}
+	b.mu.RUnlock()
+
+	var errs []error
+	for _, event := range events {
+		for _, sub := range subs {
+			if !matchSubject(sub.subject, event.Subject) {
+				continue
+			}
+			if err := sub.handle(ctx, event); err != nil && !isPermanent(err) {
+				errs = append(errs, fmt.Errorf("handle event %s: %w", event.ID, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (b *MemoryBus) Subscribe(_ context.Context, subject string, handler Handler) (Subscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.nextID++
+	sub := &memorySubscription{
+		bus:     b,
+		id:      b.nextID,
+		subject: subject,
+		handler: handler,
+	}
+	b.subs[sub.id] = sub
+	return sub, nil
+}
+
+type memorySubscription struct {
+	bus     *MemoryBus
+	id      int
+	subject string
+	handler Handler
+
+	mu      sync.RWMutex // held for reading while handling events
+	stopped bool
+}
+
+func (s *memorySubscription) handle(ctx context.Context, event Event) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.stopped {
+		return nil
+	}
+	return s.handler(ctx, event)
+}
+
+func (s *memorySubscription) Stop() {
+	s.bus.mu.Lock()
+	delete(s.bus.subs, s.id)
+	s.bus.mu.Unlock()
+
+	s.mu.Lock()
+	s.stopped = true
+	s.mu.Unlock()
+}
+
+// matchSubject reports whether subject matches pattern, where the token *
+// matches any single token and a trailing > matches one or more tokens.
+func matchSubject(pattern string, subject string) bool {
+	patternTokens := strings.Split(pattern, ".")
+	subjectTokens := strings.Split(subject, ".")
+	for i, pt := range patternTokens {
+		if pt == ">" {
+			return i == len(patternTokens)-1 && len(subjectTokens) > i
+		}
+		if i >= len(subjectTokens) || (pt != "*" && pt != subjectTokens[i]) {
+			return false
+		}
+	}
+	return len(patternTokens) == len(subjectTokens)
+}
@@ -0,0 +1,86 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type orderCreated struct {
+	OrderID string `json:"order_id"`
+}
+
+func newTestEvent(t *testing.T, subject string) Event {
+	t.Helper()
+	event, err := New(subject, "order.created", orderCreated{OrderID: "1"})
+	require.NoError(t, err)
+	return event
+}
+
+func TestMemoryBus(t *testing.T) {
+	ctx := context.Background()
+	bus := NewMemoryBus()
+
+	var got []Event
+	sub, err := bus.Subscribe(ctx, "orders.*", func(_ context.Context, event Event) error {
+		got = append(got, event)
+		return nil
+	})
+	require.NoError(t, err)
+
+	want := newTestEvent(t, "orders.created")
+	require.NoError(t, bus.Publish(ctx, want, newTestEvent(t, "payments.created")))
+	require.Len(t, got, 1)
+	assert.Equal(t, want, got[0])
+
+	var data orderCreated
+	require.NoError(t, got[0].Decode(&data))
+	assert.Equal(t, "1", data.OrderID)
+
+	sub.Stop()
+	require.NoError(t, bus.Publish(ctx, want))
+	assert.Len(t, got, 1)
+}
+
+func TestMemoryBus_handlerErrors(t *testing.T) {
+	ctx := context.Background()
+	bus := NewMemoryBus()
+
+	_, err := bus.Subscribe(ctx, "orders.>", func(context.Context, Event) error {
+		return errors.New("unavailable")
+	})
+	require.NoError(t, err)
+	require.Error(t, bus.Publish(ctx, newTestEvent(t, "orders.created")))
+
+	bus = NewMemoryBus()
+	_, err = bus.Subscribe(ctx, "orders.>", func(context.Context, Event) error {
+		return Permanent(errors.New("invalid"))
+	})
+	require.NoError(t, err)
+	require.NoError(t, bus.Publish(ctx, newTestEvent(t, "orders.created")))
+}
+
+func TestMatchSubject(t *testing.T) {
+	tests := []struct {
+		pattern string
+		subject string
+		want    bool
+	}{
+		{pattern: "orders.created", subject: "orders.created", want: true},
+		{pattern: "orders.created", subject: "orders.deleted", want: false},
+		{pattern: "orders.*", subject: "orders.created", want: true},
+		{pattern: "orders.*", subject: "orders.created.v1", want: false},
+		{pattern: "orders.>", subject: "orders.created.v1", want: true},
+		{pattern: "orders.>", subject: "orders", want: false},
+		{pattern: "*.created", subject: "orders.created", want: true},
+		{pattern: "orders.created.v1", subject: "orders.created", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.subject, func(t *testing.T) {
+			assert.Equal(t, tt.want, matchSubject(tt.pattern, tt.subject))
+		})
+	}
+}
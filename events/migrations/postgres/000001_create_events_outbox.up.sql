@@ -0,0 +1,10 @@
+CREATE TABLE events_outbox
+(
+    id         BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    event_id   TEXT        NOT NULL,
+    subject    TEXT        NOT NULL,
+    type       TEXT        NOT NULL,
+    data       JSONB       NOT NULL,
+    event_time TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
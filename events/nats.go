@@ -0,0 +1,101 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+
+	"github.com/joshjon/kit/natsutil"
+)
+
+// NATSBus is a Bus of events published to NATS JetStream. Events are
+// published with their ID as the message ID, so events published again
+// within the duplicate window of the stream are deduplicated.
+type NATSBus struct {
+	js      jetstream.JetStream
+	stream  string
+	durable string
+	msgOpts []natsutil.MsgOption
+}
+
+var _ Bus = (*NATSBus)(nil)
+
+// NewNATSBus creates a new NATSBus publishing to and subscribing to the
+// subjects of stream, which must exist, e.g. created with
+// natsutil.EnsureStream. Subscriptions are durable consumers named after
+// durable and their subject, so the events of a service are processed once
+// across its replicas and resumed after restarts.
+func NewNATSBus(js jetstream.JetStream, stream string, durable string, opts ...natsutil.MsgOption) *NATSBus {
+	return &NATSBus{
+		js:      js,
+		stream:  stream,
+		durable: durable,
+		msgOpts: opts,
+	}
+}
+
+func (b *NATSBus) Publish(ctx context.Context, events ...Event) error {
+	for _, event := range events {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("marshal event %s: %w", event.ID, err)
+		}
+		msg := &nats.Msg{
+			Subject: event.Subject,
+			Data:    data,
+			Header:  nats.Header{"Content-Type": []string{"application/json"}},
+		}
+		if _, err = b.js.PublishMsg(ctx, msg, jetstream.WithMsgID(event.ID)); err != nil {
+			return fmt.Errorf("publish event %s: %w", event.ID, err)
+		}
+	}
+	return nil
+}
+
+func (b *NATSBus) Subscribe(ctx context.Context, subject string, handler Handler) (Subscription, error) {
+	consumer, err := natsutil.EnsureConsumer(ctx, b.js, b.stream, jetstream.ConsumerConfig{
+		Durable:       consumerName(b.durable, subject),
+		FilterSubject: subject,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = natsutil.Consume(ctx, consumer, func(ctx context.Context, msg *natsutil.Msg[Event]) error {
+			if err := handler(ctx, msg.Data); err != nil {
+				if isPermanent(err) {
+					return natsutil.Permanent(err)
+				}
+				return err
+			}
+			return nil
+		}, b.msgOpts...)
+	}()
+	return &natsSubscription{cancel: cancel, done: done}, nil
+}
+
+type natsSubscription struct {
+	cancel context.CancelFunc
+	done   <-chan struct{}
+}
+
+func (s *natsSubscription) Stop() {
+	s.cancel()
+	<-s.done
+}
+
+// consumerName returns a valid consumer name for the durable subscription of
+// subject, e.g. billing_orders_all for orders.>.
+func consumerName(durable string, subject string) string {
+	r := strings.NewReplacer(".", "_", "*", "any", ">", "all")
+	return durable + "_" + r.Replace(subject)
+}
@@ -0,0 +1,55 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/log"
+	"github.com/joshjon/kit/natsutil"
+	"github.com/joshjon/kit/testutil"
+)
+
+func TestNATSBus(t *testing.T) {
+	ctx := context.Background()
+	nc, err := nats.Connect(testutil.StartNATS(t))
+	require.NoError(t, err)
+	t.Cleanup(nc.Close)
+	js, err := jetstream.New(nc)
+	require.NoError(t, err)
+	_, err = natsutil.EnsureStream(ctx, js, jetstream.StreamConfig{Name: "orders", Subjects: []string{"orders.>"}})
+	require.NoError(t, err)
+
+	bus := NewNATSBus(js, "orders", "billing", natsutil.WithMsgLogger(log.NewLogger(log.WithNop())))
+	received := make(chan Event, 2)
+	sub, err := bus.Subscribe(ctx, "orders.*", func(_ context.Context, event Event) error {
+		received <- event
+		return nil
+	})
+	require.NoError(t, err)
+	defer sub.Stop()
+
+	// published twice, e.g. by a relay retrying, and deduplicated
+	event := newTestEvent(t, "orders.created")
+	require.NoError(t, bus.Publish(ctx, event))
+	require.NoError(t, bus.Publish(ctx, event))
+
+	got := testutil.AssertReceiveChan(t, received, 5*time.Second)
+	assert.Equal(t, event.ID, got.ID)
+	assert.Equal(t, event.Type, got.Type)
+	select {
+	case dup := <-received:
+		t.Fatalf("duplicate event delivered: %s", dup.ID)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestConsumerName(t *testing.T) {
+	assert.Equal(t, "billing_orders_any", consumerName("billing", "orders.*"))
+	assert.Equal(t, "billing_orders_all", consumerName("billing", "orders.>"))
+}
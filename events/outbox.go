@@ -0,0 +1,210 @@
+package events
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/joshjon/kit/log"
+	"github.com/joshjon/kit/pgdb"
+	"github.com/joshjon/kit/tx"
+)
+
+const (
+	postgresMigrationsTable = "events_schema_migrations"
+	defaultPollInterval     = time.Second
+	defaultBatchSize        = 100
+)
+
+//go:embed migrations/postgres/*.sql
+var postgresMigrations embed.FS
+
+// MigratePostgres applies the migrations of the outbox table used by a
+// PostgresOutbox, recording them separately from the migrations of the
+// service.
+func MigratePostgres(pool *pgxpool.Pool) error {
+	fsys, err := fs.Sub(postgresMigrations, "migrations/postgres")
+	if err != nil {
+		return err
+	}
+	return pgdb.Migrate(pool, fsys, pgdb.WithMigrationsTable(postgresMigrationsTable))
+}
+
+// pgxDB is implemented by both pgxpool.Pool and pgx.Tx.
+type pgxDB interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// PostgresOutbox is a Publisher writing events to an outbox table in
+// Postgres, to be published by a Relay. Bind it to the transaction of the
+// changes the events describe with WithTx, so events are only published if
+// the transaction commits. Apply its migrations with MigratePostgres.
+type PostgresOutbox struct {
+	db pgxDB
+}
+
+var _ Publisher = (*PostgresOutbox)(nil)
+
+// NewPostgresOutbox creates a new PostgresOutbox.
+func NewPostgresOutbox(pool *pgxpool.Pool) *PostgresOutbox {
+	return &PostgresOutbox{db: pool}
+}
+
+// WithTx returns a copy of the outbox bound to the provided transaction.
+//
+// Panics if tx does not implement pgx.Tx.
+func (o *PostgresOutbox) WithTx(txn tx.Tx) *PostgresOutbox {
+	pgxTx, ok := txn.(pgx.Tx)
+	if !ok {
+		panic("events.PostgresOutbox.WithTx: expected pgx.Tx")
+	}
+	return &PostgresOutbox{db: pgxTx}
+}
+
+func (o *PostgresOutbox) Publish(ctx context.Context, events ...Event) error {
+	for _, event := range events {
+		_, err := o.db.Exec(ctx, `
+			INSERT INTO events_outbox (event_id, subject, type, data, event_time)
+			VALUES ($1, $2, $3, $4, $5)`,
+			event.ID, event.Subject, event.Type, []byte(event.Data), event.Time,
+		)
+		if err != nil {
+			return fmt.Errorf("write event %s to outbox: %w", event.ID, err)
+		}
+	}
+	return nil
+}
+
+// RelayOption optionally configures a Relay.
+type RelayOption func(opts *relayOptions)
+
+// WithPollInterval sets how often the outbox is polled for events while it
+// is empty. Defaults to 1s.
+func WithPollInterval(d time.Duration) RelayOption {
+	return func(opts *relayOptions) {
+		opts.pollInterval = d
+	}
+}
+
+// WithBatchSize sets the maximum number of events published at once.
+// Defaults to 100.
+func WithBatchSize(n int) RelayOption {
+	return func(opts *relayOptions) {
+		opts.batchSize = n
+	}
+}
+
+// WithLogger sets the Logger used to log failures to relay events.
+func WithLogger(logger log.Logger) RelayOption {
+	return func(opts *relayOptions) {
+		opts.logger = logger
+	}
+}
+
+type relayOptions struct {
+	pollInterval time.Duration
+	batchSize    int
+	logger       log.Logger
+}
+
+// Relay publishes the events of a PostgresOutbox to a Publisher, e.g. a
+// NATSBus, in the order they were written. Events are deleted from the outbox
+// once published, and published again if publishing fails or the relay
+// crashes before deleting them. Outbox rows are locked while being published,
+// so any number of relays can run concurrently.
+type Relay struct {
+	pool   *pgxpool.Pool
+	target Publisher
+	opts   relayOptions
+}
+
+// NewRelay creates a new Relay publishing the events of the outbox in pool to
+// target.
+func NewRelay(pool *pgxpool.Pool, target Publisher, opts ...RelayOption) *Relay {
+	options := relayOptions{
+		pollInterval: defaultPollInterval,
+		batchSize:    defaultBatchSize,
+		logger:       log.NewLogger(),
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &Relay{
+		pool:   pool,
+		target: target,
+		opts:   options,
+	}
+}
+
+// Run relays events until ctx is done.
+func (r *Relay) Run(ctx context.Context) error {
+	for ctx.Err() == nil {
+		n, err := r.RelayBatch(ctx)
+		if err != nil && ctx.Err() == nil {
+			r.opts.logger.Error("failed to relay events", "error", err)
+		}
+		if n > 0 && err == nil {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(r.opts.pollInterval):
+		}
+	}
+	return nil
+}
+
+// RelayBatch publishes the next batch of events of the outbox, returning the
+// number of events published.
+func (r *Relay) RelayBatch(ctx context.Context) (int, error) {
+	var n int
+	err := pgx.BeginFunc(ctx, r.pool, func(pgxTx pgx.Tx) error {
+		rows, err := pgxTx.Query(ctx, `
+			SELECT id, event_id, subject, type, data, event_time
+			FROM events_outbox
+			ORDER BY id
+			LIMIT $1 FOR UPDATE SKIP LOCKED`,
+			r.opts.batchSize,
+		)
+		if err != nil {
+			return fmt.Errorf("query outbox: %w", err)
+		}
+		var ids []int64
+		var events []Event
+		for rows.Next() {
+			var id int64
+			var event Event
+			var data []byte
+			if err = rows.Scan(&id, &event.ID, &event.Subject, &event.Type, &data, &event.Time); err != nil {
+				rows.Close()
+				return fmt.Errorf("scan outbox: %w", err)
+			}
+			event.Data = data
+			ids = append(ids, id)
+			events = append(events, event)
+		}
+		if err = rows.Err(); err != nil {
+			return fmt.Errorf("query outbox: %w", err)
+		}
+		if len(events) == 0 {
+			return nil
+		}
+
+		if err = r.target.Publish(ctx, events...); err != nil {
+			return err
+		}
+		if _, err = pgxTx.Exec(ctx, `DELETE FROM events_outbox WHERE id = ANY($1)`, ids); err != nil {
+			return fmt.Errorf("delete relayed events: %w", err)
+		}
+		n = len(events)
+		return nil
+	})
+	return n, err
+}
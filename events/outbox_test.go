@@ -0,0 +1,94 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/log"
+	"github.com/joshjon/kit/testutil"
+	"github.com/joshjon/kit/tx"
+)
+
+func TestRelay(t *testing.T) {
+	ctx := context.Background()
+	pool := testutil.StartPostgres(t, nil)
+	require.NoError(t, MigratePostgres(pool))
+	outbox := NewPostgresOutbox(pool)
+
+	publishTx := func(ctx context.Context, event Event, fail bool) error {
+		txn, err := pool.BeginTx(ctx, pgx.TxOptions{})
+		require.NoError(t, err)
+		return tx.Do(ctx, txn, func(ctx context.Context) error {
+			if err := outbox.WithTx(txn).Publish(ctx, event); err != nil {
+				return err
+			}
+			if fail {
+				return errors.New("failed")
+			}
+			return nil
+		})
+	}
+
+	bus := NewMemoryBus()
+	var got []Event
+	_, err := bus.Subscribe(ctx, ">", func(_ context.Context, event Event) error {
+		got = append(got, event)
+		return nil
+	})
+	require.NoError(t, err)
+	relay := NewRelay(pool, bus, WithLogger(log.NewLogger(log.WithNop())))
+
+	rolledBack := newTestEvent(t, "orders.created")
+	committed := newTestEvent(t, "orders.created")
+	require.Error(t, publishTx(ctx, rolledBack, true))
+	require.NoError(t, publishTx(ctx, committed, false))
+
+	n, err := relay.RelayBatch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	require.Len(t, got, 1)
+	assert.Equal(t, committed.ID, got[0].ID)
+	assert.JSONEq(t, string(committed.Data), string(got[0].Data))
+	assert.WithinDuration(t, committed.Time, got[0].Time, time.Millisecond)
+
+	// relayed events are deleted
+	n, err = relay.RelayBatch(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+func TestRelay_publishFailure(t *testing.T) {
+	ctx := context.Background()
+	pool := testutil.StartPostgres(t, nil)
+	require.NoError(t, MigratePostgres(pool))
+	require.NoError(t, NewPostgresOutbox(pool).Publish(ctx, newTestEvent(t, "orders.created")))
+
+	bus := NewMemoryBus()
+	fail := true
+	var delivered int
+	_, err := bus.Subscribe(ctx, ">", func(context.Context, Event) error {
+		delivered++
+		if fail {
+			return errors.New("unavailable")
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	relay := NewRelay(pool, bus, WithLogger(log.NewLogger(log.WithNop())))
+
+	_, err = relay.RelayBatch(ctx)
+	require.Error(t, err)
+
+	// the event is kept and published again
+	fail = false
+	n, err := relay.RelayBatch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, 2, delivered)
+}
@@ -0,0 +1,45 @@
+package featureflag
+
+import (
+	"context"
+	"os"
+	"strings"
+)
+
+// EnvProvider is a Provider resolving flags from environment variables named
+// after the flag with a prefix, upper cased and with characters other than
+// letters and digits replaced by underscores, e.g. FF_NEW_CHECKOUT for flag
+// new-checkout and prefix FF_. Flags are not targeted.
+type EnvProvider struct {
+	prefix string
+}
+
+var _ Provider = (*EnvProvider)(nil)
+
+// NewEnvProvider creates a new EnvProvider reading environment variables
+// with prefix.
+func NewEnvProvider(prefix string) *EnvProvider {
+	return &EnvProvider{prefix: prefix}
+}
+
+func (p *EnvProvider) Resolve(_ context.Context, flag string, _ EvalContext) (any, bool, error) {
+	value, ok := os.LookupEnv(p.EnvVar(flag))
+	if !ok {
+		return nil, false, nil
+	}
+	return value, true, nil
+}
+
+// EnvVar returns the name of the environment variable of flag.
+func (p *EnvProvider) EnvVar(flag string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, flag)
+	return p.prefix + name
+}
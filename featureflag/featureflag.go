@@ -0,0 +1,178 @@
+// Package featureflag evaluates feature flags resolved by a Provider, such as
+// flags defined in config files, environment variables or a remote service.
+package featureflag
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/joshjon/kit/log"
+)
+
+// EvalContext is the context flags are evaluated in.
+type EvalContext struct {
+	// TargetingKey identifies the subject of the evaluation, such as a user
+	// ID, so flags can be targeted at specific subjects.
+	TargetingKey string
+	// Attributes are additional attributes of the subject.
+	Attributes map[string]any
+}
+
+type evalCtxKey struct{}
+
+// WithEvalContext returns a copy of ctx carrying evalCtx, which is used to
+// evaluate flags with ctx.
+func WithEvalContext(ctx context.Context, evalCtx EvalContext) context.Context {
+	return context.WithValue(ctx, evalCtxKey{}, evalCtx)
+}
+
+// EvalContextFromContext returns the EvalContext carried by ctx.
+func EvalContextFromContext(ctx context.Context) (EvalContext, bool) {
+	evalCtx, ok := ctx.Value(evalCtxKey{}).(EvalContext)
+	return evalCtx, ok
+}
+
+// Provider resolves the values of flags.
+type Provider interface {
+	// Resolve returns the value of flag for evalCtx, reporting false if the
+	// flag is not defined.
+	Resolve(ctx context.Context, flag string, evalCtx EvalContext) (value any, ok bool, err error)
+}
+
+// Chain returns a Provider resolving flags with the first of providers that
+// defines them, e.g. to override flags in config files with environment
+// variables.
+func Chain(providers ...Provider) Provider {
+	return chain(providers)
+}
+
+type chain []Provider
+
+func (c chain) Resolve(ctx context.Context, flag string, evalCtx EvalContext) (any, bool, error) {
+	for _, p := range c {
+		value, ok, err := p.Resolve(ctx, flag, evalCtx)
+		if err != nil || ok {
+			return value, ok, err
+		}
+	}
+	return nil, false, nil
+}
+
+// Option optionally configures a Client or RemoteProvider.
+type Option func(opts *options)
+
+// WithLogger sets the Logger used to log evaluation and refresh failures.
+func WithLogger(logger log.Logger) Option {
+	return func(opts *options) {
+		opts.logger = logger
+	}
+}
+
+type options struct {
+	logger          log.Logger
+	refreshInterval time.Duration
+}
+
+func newOptions(opts []Option) options {
+	options := options{
+		logger:          log.NewLogger(),
+		refreshInterval: defaultRefreshInterval,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// Client evaluates flags resolved by a Provider.
+type Client struct {
+	provider Provider
+	logger   log.Logger
+}
+
+// NewClient creates a new Client evaluating flags resolved by provider.
+func NewClient(provider Provider, opts ...Option) *Client {
+	options := newOptions(opts)
+	return &Client{
+		provider: provider,
+		logger:   options.logger,
+	}
+}
+
+// Bool evaluates a bool flag with the EvalContext of ctx. It returns def if
+// the flag is not defined or cannot be evaluated as a bool. Strings are
+// parsed with strconv.ParseBool.
+func (c *Client) Bool(ctx context.Context, flag string, def bool) bool {
+	return evaluate(ctx, c, flag, def, toBool)
+}
+
+// String evaluates a string flag with the EvalContext of ctx. It returns def
+// if the flag is not defined or is not a string.
+func (c *Client) String(ctx context.Context, flag string, def string) string {
+	return evaluate(ctx, c, flag, def, toString)
+}
+
+// Int evaluates an int flag with the EvalContext of ctx. It returns def if
+// the flag is not defined or cannot be evaluated as an int. Strings are
+// parsed with strconv.Atoi.
+func (c *Client) Int(ctx context.Context, flag string, def int) int {
+	return evaluate(ctx, c, flag, def, toInt)
+}
+
+func evaluate[T any](ctx context.Context, c *Client, flag string, def T, convert func(v any) (T, error)) T {
+	evalCtx, _ := EvalContextFromContext(ctx)
+	value, ok, err := c.provider.Resolve(ctx, flag, evalCtx)
+	if err != nil {
+		c.logger.Warn("failed to resolve feature flag", "flag", flag, "error", err)
+		return def
+	}
+	if !ok {
+		return def
+	}
+	v, err := convert(value)
+	if err != nil {
+		c.logger.Warn("failed to evaluate feature flag", "flag", flag, "error", err)
+		return def
+	}
+	return v
+}
+
+func toBool(v any) (bool, error) {
+	switch v := v.(type) {
+	case bool:
+		return v, nil
+	case string:
+		return strconv.ParseBool(v)
+	}
+	return false, fmt.Errorf("%T is not a bool", v)
+}
+
+func toString(v any) (string, error) {
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	return "", fmt.Errorf("%T is not a string", v)
+}
+
+func toInt(v any) (int, error) {
+	switch v := v.(type) {
+	case int:
+		return v, nil
+	case int64:
+		return int(v), nil
+	case uint64:
+		return int(v), nil
+	case float64:
+		// Numbers decoded from JSON are floats.
+		if v != math.Trunc(v) {
+			return 0, fmt.Errorf("%v is not an int", v)
+		}
+		return int(v), nil
+	case string:
+		return strconv.Atoi(v)
+	}
+	return 0, fmt.Errorf("%T is not an int", v)
+}
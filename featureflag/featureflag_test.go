@@ -0,0 +1,105 @@
+package featureflag
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/joshjon/kit/log"
+)
+
+const testFlagsYAML = `
+new-checkout:
+  value: false
+  targets:
+    user-1: true
+theme:
+  value: dark
+max-items:
+  value: 10
+`
+
+func newTestClient(t *testing.T, provider Provider) *Client {
+	t.Helper()
+	return NewClient(provider, WithLogger(log.NewLogger(log.WithNop())))
+}
+
+func testFlags(t *testing.T) Flags {
+	t.Helper()
+	var flags Flags
+	require.NoError(t, yaml.Unmarshal([]byte(testFlagsYAML), &flags))
+	require.NoError(t, flags.Validation().Error())
+	return flags
+}
+
+func TestClient(t *testing.T) {
+	client := newTestClient(t, NewStaticProvider(testFlags(t)))
+	ctx := context.Background()
+
+	assert.False(t, client.Bool(ctx, "new-checkout", true))
+	assert.Equal(t, "dark", client.String(ctx, "theme", "light"))
+	assert.Equal(t, 10, client.Int(ctx, "max-items", 5))
+
+	// not defined
+	assert.True(t, client.Bool(ctx, "undefined", true))
+	assert.Equal(t, "light", client.String(ctx, "undefined", "light"))
+	assert.Equal(t, 5, client.Int(ctx, "undefined", 5))
+
+	// wrong type
+	assert.True(t, client.Bool(ctx, "theme", true))
+	assert.Equal(t, "light", client.String(ctx, "max-items", "light"))
+	assert.Equal(t, 5, client.Int(ctx, "theme", 5))
+}
+
+func TestClient_targeting(t *testing.T) {
+	client := newTestClient(t, NewStaticProvider(testFlags(t)))
+
+	ctx := WithEvalContext(context.Background(), EvalContext{TargetingKey: "user-1"})
+	assert.True(t, client.Bool(ctx, "new-checkout", false))
+
+	ctx = WithEvalContext(context.Background(), EvalContext{TargetingKey: "user-2"})
+	assert.False(t, client.Bool(ctx, "new-checkout", true))
+}
+
+func TestClient_providerError(t *testing.T) {
+	client := newTestClient(t, providerFunc(func(ctx context.Context, flag string, evalCtx EvalContext) (any, bool, error) {
+		return nil, false, errors.New("unavailable")
+	}))
+	assert.True(t, client.Bool(context.Background(), "new-checkout", true))
+}
+
+func TestChain(t *testing.T) {
+	t.Setenv("FF_THEME", "light")
+	t.Setenv("FF_NEW_CHECKOUT", "true")
+	client := newTestClient(t, Chain(NewEnvProvider("FF_"), NewStaticProvider(testFlags(t))))
+	ctx := context.Background()
+
+	assert.True(t, client.Bool(ctx, "new-checkout", false))
+	assert.Equal(t, "light", client.String(ctx, "theme", "dark"))
+	assert.Equal(t, 10, client.Int(ctx, "max-items", 5))
+	assert.Equal(t, 5, client.Int(ctx, "undefined", 5))
+}
+
+func TestEnvProvider(t *testing.T) {
+	provider := NewEnvProvider("FF_")
+	assert.Equal(t, "FF_NEW_CHECKOUT_V2", provider.EnvVar("new-checkout.v2"))
+
+	t.Setenv("FF_MAX_ITEMS", "20")
+	client := newTestClient(t, provider)
+	assert.Equal(t, 20, client.Int(context.Background(), "max-items", 5))
+}
+
+func TestFlags_Validation(t *testing.T) {
+	flags := Flags{"new-checkout": {}}
+	assert.Error(t, flags.Validation().Error())
+}
+
+type providerFunc func(ctx context.Context, flag string, evalCtx EvalContext) (any, bool, error)
+
+func (f providerFunc) Resolve(ctx context.Context, flag string, evalCtx EvalContext) (any, bool, error) {
+	return f(ctx, flag, evalCtx)
+}
@@ -0,0 +1,29 @@
+package featureflag
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/joshjon/kit/jwt"
+)
+
+// Middleware injects the EvalContext returned by evalContext for each request
+// into the request context, so handlers evaluate flags for the subject of the
+// request. If evalContext is nil the targeting key is the user ID set by
+// jwt.ValidateMiddleware, so it must run before this middleware.
+func Middleware(evalContext func(c echo.Context) EvalContext) echo.MiddlewareFunc {
+	if evalContext == nil {
+		evalContext = jwtEvalContext
+	}
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			c.SetRequest(req.WithContext(WithEvalContext(req.Context(), evalContext(c))))
+			return next(c)
+		}
+	}
+}
+
+func jwtEvalContext(c echo.Context) EvalContext {
+	userID, _ := jwt.AuthUserIDFromContext(c)
+	return EvalContext{TargetingKey: userID}
+}
@@ -0,0 +1,62 @@
+package featureflag
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name        string
+		evalContext func(c echo.Context) EvalContext
+		setup       func(c echo.Context)
+		want        EvalContext
+	}{
+		{
+			name: "custom",
+			evalContext: func(c echo.Context) EvalContext {
+				return EvalContext{
+					TargetingKey: c.Request().Header.Get("X-User-ID"),
+					Attributes:   map[string]any{"plan": "pro"},
+				}
+			},
+			want: EvalContext{TargetingKey: "user-1", Attributes: map[string]any{"plan": "pro"}},
+		},
+		{
+			name: "jwt user id",
+			setup: func(c echo.Context) {
+				c.Set("jwt-auth-user-id", "user-2")
+			},
+			want: EvalContext{TargetingKey: "user-2"},
+		},
+		{
+			name: "anonymous",
+			want: EvalContext{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("X-User-ID", "user-1")
+			c := echo.New().NewContext(req, httptest.NewRecorder())
+			if tt.setup != nil {
+				tt.setup(c)
+			}
+
+			var got EvalContext
+			err := Middleware(tt.evalContext)(func(c echo.Context) error {
+				var ok bool
+				got, ok = EvalContextFromContext(c.Request().Context())
+				assert.True(t, ok)
+				return nil
+			})(c)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
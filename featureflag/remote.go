@@ -0,0 +1,116 @@
+package featureflag
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+const defaultRefreshInterval = 30 * time.Second
+
+// WithRefreshInterval sets how often a RemoteProvider fetches flags.
+// Defaults to 30s.
+func WithRefreshInterval(d time.Duration) Option {
+	return func(opts *options) {
+		opts.refreshInterval = d
+	}
+}
+
+// Fetcher fetches flags from a remote service.
+type Fetcher interface {
+	Fetch(ctx context.Context) (Flags, error)
+}
+
+// FetcherFunc is a func implementing Fetcher.
+type FetcherFunc func(ctx context.Context) (Flags, error)
+
+func (f FetcherFunc) Fetch(ctx context.Context) (Flags, error) {
+	return f(ctx)
+}
+
+// HTTPFetcher returns a Fetcher fetching flags encoded as JSON from url,
+// e.g. {"new-checkout": {"value": true}}. The default client is used if
+// client is nil.
+func HTTPFetcher(url string, client *http.Client) Fetcher {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return FetcherFunc(func(ctx context.Context) (Flags, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		res, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer res.Body.Close() //nolint:errcheck
+		if res.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("fetch flags: responded with status %d", res.StatusCode)
+		}
+		var flags Flags
+		if err = json.NewDecoder(res.Body).Decode(&flags); err != nil {
+			return nil, fmt.Errorf("decode flags: %w", err)
+		}
+		return flags, nil
+	})
+}
+
+// RemoteProvider is a Provider resolving flags fetched from a remote service
+// by a Fetcher. Flags are refreshed periodically by Run and the last fetched
+// flags are kept while fetching fails. No flags are defined until they are
+// first fetched.
+type RemoteProvider struct {
+	fetcher Fetcher
+	opts    options
+	static  atomic.Pointer[StaticProvider]
+}
+
+var _ Provider = (*RemoteProvider)(nil)
+
+// NewRemoteProvider creates a new RemoteProvider fetching flags with
+// fetcher.
+func NewRemoteProvider(fetcher Fetcher, opts ...Option) *RemoteProvider {
+	return &RemoteProvider{
+		fetcher: fetcher,
+		opts:    newOptions(opts),
+	}
+}
+
+func (p *RemoteProvider) Resolve(ctx context.Context, flag string, evalCtx EvalContext) (any, bool, error) {
+	static := p.static.Load()
+	if static == nil {
+		return nil, false, nil
+	}
+	return static.Resolve(ctx, flag, evalCtx)
+}
+
+// Refresh fetches the flags.
+func (p *RemoteProvider) Refresh(ctx context.Context) error {
+	flags, err := p.fetcher.Fetch(ctx)
+	if err != nil {
+		return err
+	}
+	p.static.Store(NewStaticProvider(flags))
+	return nil
+}
+
+// Run fetches the flags every refresh interval until ctx is done, logging
+// failed fetches. The first fetch is immediate.
+func (p *RemoteProvider) Run(ctx context.Context) error {
+	ticker := time.NewTicker(p.opts.refreshInterval)
+	defer ticker.Stop()
+	for {
+		if err := p.Refresh(ctx); err != nil && ctx.Err() == nil {
+			p.opts.logger.Error("failed to refresh feature flags", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
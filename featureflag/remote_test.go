@@ -0,0 +1,68 @@
+package featureflag
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/log"
+)
+
+func TestRemoteProvider(t *testing.T) {
+	var body atomic.Value
+	body.Store(`{"new-checkout": {"value": true, "targets": {"user-1": false}}, "max-items": {"value": 20}}`)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if body.Load() == "" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(body.Load().(string)))
+	}))
+	t.Cleanup(srv.Close)
+
+	provider := NewRemoteProvider(HTTPFetcher(srv.URL, nil))
+	client := newTestClient(t, provider)
+	ctx := context.Background()
+
+	// not fetched yet
+	assert.False(t, client.Bool(ctx, "new-checkout", false))
+
+	require.NoError(t, provider.Refresh(ctx))
+	assert.True(t, client.Bool(ctx, "new-checkout", false))
+	assert.Equal(t, 20, client.Int(ctx, "max-items", 5))
+	userCtx := WithEvalContext(ctx, EvalContext{TargetingKey: "user-1"})
+	assert.False(t, client.Bool(userCtx, "new-checkout", true))
+
+	// keeps the last flags while fetching fails
+	body.Store("")
+	assert.EqualError(t, provider.Refresh(ctx), "fetch flags: responded with status 503")
+	assert.True(t, client.Bool(ctx, "new-checkout", false))
+}
+
+func TestRemoteProvider_Run(t *testing.T) {
+	var fetches atomic.Int32
+	provider := NewRemoteProvider(FetcherFunc(func(ctx context.Context) (Flags, error) {
+		fetches.Add(1)
+		return Flags{"new-checkout": {Value: true}}, nil
+	}), WithRefreshInterval(10*time.Millisecond), WithLogger(log.NewLogger(log.WithNop())))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- provider.Run(ctx)
+	}()
+
+	assert.Eventually(t, func() bool {
+		return fetches.Load() >= 2
+	}, time.Second, time.Millisecond)
+	assert.True(t, newTestClient(t, provider).Bool(ctx, "new-checkout", false))
+
+	cancel()
+	assert.NoError(t, <-done)
+}
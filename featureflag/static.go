@@ -0,0 +1,59 @@
+package featureflag
+
+import (
+	"context"
+
+	"github.com/cohesivestack/valgo"
+)
+
+// FlagConfig configures a flag.
+type FlagConfig struct {
+	// Value is the value of the flag, a bool, string or int.
+	Value any `yaml:"value" json:"value"`
+	// Targets overrides Value for specific targeting keys, e.g. to enable a
+	// flag for internal users before rolling it out.
+	Targets map[string]any `yaml:"targets" json:"targets"`
+}
+
+// Flags configures flags by name, e.g. as a field of a service config loaded
+// with config.Load:
+//
+//	flags:
+//	  new-checkout:
+//	    value: false
+//	    targets:
+//	      user-1: true
+type Flags map[string]FlagConfig
+
+func (f Flags) Validation() *valgo.Validation {
+	v := valgo.New()
+	for name, flag := range f {
+		v.In(name, valgo.Is(valgo.Any(flag.Value, "value").Not().Nil()))
+	}
+	return v
+}
+
+// StaticProvider is a Provider resolving a fixed set of flags.
+type StaticProvider struct {
+	flags Flags
+}
+
+var _ Provider = (*StaticProvider)(nil)
+
+// NewStaticProvider creates a new StaticProvider resolving flags.
+func NewStaticProvider(flags Flags) *StaticProvider {
+	return &StaticProvider{flags: flags}
+}
+
+func (p *StaticProvider) Resolve(_ context.Context, flag string, evalCtx EvalContext) (any, bool, error) {
+	f, ok := p.flags[flag]
+	if !ok {
+		return nil, false, nil
+	}
+	if evalCtx.TargetingKey != "" {
+		if value, ok := f.Targets[evalCtx.TargetingKey]; ok {
+			return value, true, nil
+		}
+	}
+	return f.Value, true, nil
+}
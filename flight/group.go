@@ -0,0 +1,93 @@
+// Package flight deduplicates concurrent calls for the same key, e.g. JWKS
+// fetches, downstream health probes and expensive read queries under burst
+// load. A Group coalesces concurrent calls into one and a Memo additionally
+// memoizes results for a TTL.
+package flight
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Group coalesces concurrent calls with the same key into one call, whose
+// result is shared by all callers. The zero Group is ready to use.
+type Group[T any] struct {
+	sf    singleflight.Group
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+// call is the context shared by the callers of an in-flight call.
+type call struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	waiters int
+}
+
+// Do calls fn unless a call with key is already in flight, in which case it
+// waits for that call and returns its result. If ctx is done first, Do
+// returns its error without waiting. The context passed to fn is not
+// cancelled when the caller that started the call gives up, only once every
+// caller waiting for the call did, so one cancelled request doesn't fail the
+// others.
+func (g *Group[T]) Do(ctx context.Context, key string, fn func(ctx context.Context) (T, error)) (T, error) {
+	c := g.join(ctx, key)
+	ch := g.sf.DoChan(key, func() (any, error) {
+		return fn(c.ctx)
+	})
+
+	var zero T
+	select {
+	case res := <-ch:
+		g.leave(key, c, false)
+		if res.Err != nil {
+			return zero, res.Err
+		}
+		return res.Val.(T), nil
+	case <-ctx.Done():
+		g.leave(key, c, true)
+		return zero, ctx.Err()
+	}
+}
+
+// Forget forgets the in-flight call with key, so the next call with key
+// calls fn rather than waiting for it.
+func (g *Group[T]) Forget(key string) {
+	g.sf.Forget(key)
+}
+
+func (g *Group[T]) join(ctx context.Context, key string) *call {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.calls == nil {
+		g.calls = map[string]*call{}
+	}
+	c, ok := g.calls[key]
+	if !ok {
+		callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		c = &call{ctx: callCtx, cancel: cancel}
+		g.calls[key] = c
+	}
+	c.waiters++
+	return c
+}
+
+func (g *Group[T]) leave(key string, c *call, abandoned bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	c.waiters--
+	if c.waiters > 0 {
+		return
+	}
+	if g.calls[key] == c {
+		delete(g.calls, key)
+	}
+	if abandoned {
+		// Nobody is waiting for the call anymore, so later callers must not
+		// join it as it is being cancelled.
+		g.sf.Forget(key)
+	}
+	c.cancel()
+}
@@ -0,0 +1,133 @@
+package flight
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/testutil"
+)
+
+func TestGroup_Do(t *testing.T) {
+	var g Group[int]
+	var calls atomic.Int32
+	release := make(chan struct{})
+	fn := func(ctx context.Context) (int, error) {
+		calls.Add(1)
+		<-release
+		return 42, nil
+	}
+
+	const n = 10
+	results := make(chan int, n)
+	var wg sync.WaitGroup
+	for range n {
+		wg.Go(func() {
+			v, err := g.Do(context.Background(), "key", fn)
+			assert.NoError(t, err)
+			results <- v
+		})
+	}
+	require.Eventually(t, func() bool { return waiters(&g, "key") == n }, time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(results)
+
+	assert.Equal(t, int32(1), calls.Load())
+	for v := range results {
+		assert.Equal(t, 42, v)
+	}
+}
+
+func TestGroup_Do_error(t *testing.T) {
+	var g Group[int]
+	wantErr := errors.New("boom")
+	_, err := g.Do(context.Background(), "key", func(ctx context.Context) (int, error) {
+		return 0, wantErr
+	})
+	assert.ErrorIs(t, err, wantErr)
+
+	v, err := g.Do(context.Background(), "key", func(ctx context.Context) (int, error) {
+		return 1, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, v)
+}
+
+func TestGroup_Do_callerCancelled(t *testing.T) {
+	var g Group[int]
+	release := make(chan struct{})
+	fnCtx := make(chan context.Context, 1)
+	fn := func(ctx context.Context) (int, error) {
+		fnCtx <- ctx
+		<-release
+		return 42, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := g.Do(ctx, "key", fn)
+		firstErr <- err
+	}()
+	callCtx := testutil.AssertReceiveChan(t, fnCtx, time.Second)
+
+	second := make(chan int, 1)
+	go func() {
+		v, err := g.Do(context.Background(), "key", fn)
+		assert.NoError(t, err)
+		second <- v
+	}()
+	require.Eventually(t, func() bool { return waiters(&g, "key") == 2 }, time.Second, time.Millisecond)
+
+	// The caller that started the call gives up, but the call carries on for
+	// the other caller.
+	cancel()
+	assert.ErrorIs(t, testutil.AssertReceiveChan(t, firstErr, time.Second), context.Canceled)
+	assert.NoError(t, callCtx.Err())
+
+	close(release)
+	assert.Equal(t, 42, testutil.AssertReceiveChan(t, second, time.Second))
+}
+
+func TestGroup_Do_allCallersCancelled(t *testing.T) {
+	var g Group[int]
+	fnCtx := make(chan context.Context, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() {
+		_, err := g.Do(ctx, "key", func(ctx context.Context) (int, error) {
+			fnCtx <- ctx
+			<-ctx.Done()
+			return 0, ctx.Err()
+		})
+		errs <- err
+	}()
+	callCtx := testutil.AssertReceiveChan(t, fnCtx, time.Second)
+
+	cancel()
+	assert.ErrorIs(t, testutil.AssertReceiveChan(t, errs, time.Second), context.Canceled)
+	testutil.AssertReceiveChan(t, callCtx.Done(), time.Second)
+
+	// The abandoned call is forgotten, so a new caller doesn't join it.
+	v, err := g.Do(context.Background(), "key", func(ctx context.Context) (int, error) {
+		return 1, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, v)
+}
+
+func waiters[T any](g *Group[T], key string) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if c, ok := g.calls[key]; ok {
+		return c.waiters
+	}
+	return 0
+}
@@ -0,0 +1,117 @@
+package flight
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/joshjon/kit/clock"
+)
+
+// MemoOption optionally configures a Memo.
+type MemoOption func(opts *memoOptions)
+
+// WithClock sets the clock used to expire results. Defaults to clock.Real.
+func WithClock(clk clock.Clock) MemoOption {
+	return func(opts *memoOptions) {
+		opts.clock = clk
+	}
+}
+
+type memoOptions struct {
+	clock clock.Clock
+}
+
+// Memo is a Group that memoizes successful results for a TTL, so calls with
+// the same key within the TTL return the memoized result without calling
+// fn. Errors are not memoized. It is safe for concurrent use.
+type Memo[T any] struct {
+	group Group[T]
+	ttl   time.Duration
+	clock clock.Clock
+
+	mu        sync.Mutex
+	entries   map[string]memoEntry[T]
+	lastSweep time.Time
+}
+
+type memoEntry[T any] struct {
+	val       T
+	expiresAt time.Time
+}
+
+// NewMemo creates a Memo memoizing results for ttl.
+func NewMemo[T any](ttl time.Duration, opts ...MemoOption) *Memo[T] {
+	options := memoOptions{
+		clock: clock.Real,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &Memo[T]{
+		ttl:       ttl,
+		clock:     options.clock,
+		entries:   map[string]memoEntry[T]{},
+		lastSweep: options.clock.Now(),
+	}
+}
+
+// Do returns the memoized result of key if it has not expired, or otherwise
+// calls fn as Group.Do does and memoizes its result if it succeeds.
+func (m *Memo[T]) Do(ctx context.Context, key string, fn func(ctx context.Context) (T, error)) (T, error) {
+	if val, ok := m.get(key); ok {
+		return val, nil
+	}
+	return m.group.Do(ctx, key, func(ctx context.Context) (T, error) {
+		val, err := fn(ctx)
+		if err == nil {
+			m.set(key, val)
+		}
+		return val, err
+	})
+}
+
+// Forget forgets the memoized result and in-flight call of key, so the next
+// call with key calls fn.
+func (m *Memo[T]) Forget(key string) {
+	m.mu.Lock()
+	delete(m.entries, key)
+	m.mu.Unlock()
+	m.group.Forget(key)
+}
+
+func (m *Memo[T]) get(key string) (T, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry, ok := m.entries[key]
+	if !ok || !m.clock.Now().Before(entry.expiresAt) {
+		var zero T
+		return zero, false
+	}
+	return entry.val, true
+}
+
+func (m *Memo[T]) set(key string, val T) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.clock.Now()
+	// Sweep expired entries at most once per TTL, so keys that are not
+	// called again don't accumulate.
+	if now.Sub(m.lastSweep) >= m.ttl {
+		for k, entry := range m.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(m.entries, k)
+			}
+		}
+		m.lastSweep = now
+	}
+	m.entries[key] = memoEntry[T]{val: val, expiresAt: now.Add(m.ttl)}
+}
+
+// Len returns the number of memoized results, including expired results
+// that have not been swept yet.
+func (m *Memo[T]) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.entries)
+}
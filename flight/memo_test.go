@@ -0,0 +1,87 @@
+package flight
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/testutil"
+)
+
+func TestMemo_Do(t *testing.T) {
+	clk := testutil.NewFakeClock(time.Now())
+	m := NewMemo[int](time.Minute, WithClock(clk))
+	calls := 0
+	fn := func(ctx context.Context) (int, error) {
+		calls++
+		return calls, nil
+	}
+
+	v, err := m.Do(context.Background(), "key", fn)
+	require.NoError(t, err)
+	assert.Equal(t, 1, v)
+
+	clk.Advance(59 * time.Second)
+	v, err = m.Do(context.Background(), "key", fn)
+	require.NoError(t, err)
+	assert.Equal(t, 1, v, "expected memoized result")
+
+	clk.Advance(time.Second)
+	v, err = m.Do(context.Background(), "key", fn)
+	require.NoError(t, err)
+	assert.Equal(t, 2, v, "expected expired result to be refreshed")
+}
+
+func TestMemo_Do_errorNotMemoized(t *testing.T) {
+	m := NewMemo[int](time.Minute)
+	wantErr := errors.New("boom")
+	_, err := m.Do(context.Background(), "key", func(ctx context.Context) (int, error) {
+		return 0, wantErr
+	})
+	assert.ErrorIs(t, err, wantErr)
+	assert.Equal(t, 0, m.Len())
+
+	v, err := m.Do(context.Background(), "key", func(ctx context.Context) (int, error) {
+		return 1, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, v)
+}
+
+func TestMemo_Forget(t *testing.T) {
+	m := NewMemo[int](time.Minute)
+	_, err := m.Do(context.Background(), "key", func(ctx context.Context) (int, error) {
+		return 1, nil
+	})
+	require.NoError(t, err)
+
+	m.Forget("key")
+	v, err := m.Do(context.Background(), "key", func(ctx context.Context) (int, error) {
+		return 2, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, v)
+}
+
+func TestMemo_sweep(t *testing.T) {
+	clk := testutil.NewFakeClock(time.Now())
+	m := NewMemo[int](time.Minute, WithClock(clk))
+	for _, key := range []string{"a", "b"} {
+		_, err := m.Do(context.Background(), key, func(ctx context.Context) (int, error) {
+			return 1, nil
+		})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, m.Len())
+
+	clk.Advance(time.Minute)
+	_, err := m.Do(context.Background(), "c", func(ctx context.Context) (int, error) {
+		return 1, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, m.Len())
+}
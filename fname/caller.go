@@ -0,0 +1,125 @@
+package fname
+
+import (
+	"log/slog"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+const defaultStackDepth = 32
+
+// Frame describes a single stack frame.
+type Frame struct {
+	Func    string // full function name, e.g. github.com/joshjon/kit/fname.Caller
+	File    string
+	Line    int
+	Package string // package path, e.g. github.com/joshjon/kit/fname
+}
+
+var _ slog.LogValuer = Frame{}
+
+// Caller returns the frame of the caller at the given stack depth, where 0 is
+// the function calling Caller, 1 is its parent and so on. The zero Frame is
+// returned if the frame cannot be determined.
+func Caller(skip int) Frame {
+	pc, file, line, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return Frame{}
+	}
+	var name string
+	if fn := runtime.FuncForPC(pc); fn != nil {
+		name = strings.TrimSuffix(fn.Name(), "-fm")
+	}
+	return newFrame(name, file, line)
+}
+
+func newFrame(fn string, file string, line int) Frame {
+	return Frame{
+		Func:    fn,
+		File:    file,
+		Line:    line,
+		Package: PackageName(fn),
+	}
+}
+
+// PackageName returns the package path of a full function name.
+func PackageName(full string) string {
+	slash := strings.LastIndex(full, "/")
+	if dot := strings.Index(full[slash+1:], "."); dot >= 0 {
+		return full[:slash+1+dot]
+	}
+	return full
+}
+
+// String returns the frame in the form <func> <file>:<line>.
+func (f Frame) String() string {
+	return f.Func + " " + f.File + ":" + strconv.Itoa(f.Line)
+}
+
+// LogValue implements slog.LogValuer.
+func (f Frame) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("func", f.Func),
+		slog.String("file", f.File),
+		slog.Int("line", f.Line),
+	)
+}
+
+// Frames is a stack trace of frames, innermost first.
+type Frames []Frame
+
+// Stack captures up to max frames of the current goroutine's stack, starting
+// at the function calling Stack when skip is 0. A max of 0 or less captures up
+// to 32 frames.
+func Stack(skip int, max int) Frames {
+	if max <= 0 {
+		max = defaultStackDepth
+	}
+	pcs := make([]uintptr, max)
+	n := runtime.Callers(skip+2, pcs)
+	if n == 0 {
+		return nil
+	}
+
+	frames := make(Frames, 0, n)
+	iter := runtime.CallersFrames(pcs[:n])
+	for {
+		f, more := iter.Next()
+		if f.Function != "runtime.goexit" {
+			frames = append(frames, newFrame(strings.TrimSuffix(f.Function, "-fm"), f.File, f.Line))
+		}
+		if !more {
+			break
+		}
+	}
+	return frames
+}
+
+// Strings returns the frames in their string form.
+func (fs Frames) Strings() []string {
+	out := make([]string, len(fs))
+	for i, f := range fs {
+		out[i] = f.String()
+	}
+	return out
+}
+
+// String returns a compact single line form of the frames in the form
+// <short func> (<file base>:<line>) separated by " < ".
+func (fs Frames) String() string {
+	var b strings.Builder
+	for i, f := range fs {
+		if i > 0 {
+			b.WriteString(" < ")
+		}
+		b.WriteString(ShortFuncName(f.Func))
+		b.WriteString(" (")
+		b.WriteString(filepath.Base(f.File))
+		b.WriteByte(':')
+		b.WriteString(strconv.Itoa(f.Line))
+		b.WriteByte(')')
+	}
+	return b.String()
+}
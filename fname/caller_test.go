@@ -0,0 +1,50 @@
+package fname
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCaller(t *testing.T) {
+	frame := Caller(0)
+	assert.Equal(t, "github.com/joshjon/kit/fname.TestCaller", frame.Func)
+	assert.Equal(t, "github.com/joshjon/kit/fname", frame.Package)
+	assert.True(t, strings.HasSuffix(frame.File, "fname/caller_test.go"))
+	assert.Positive(t, frame.Line)
+
+	assert.Equal(t, Frame{}, Caller(1000))
+}
+
+func TestFrame_LogValue(t *testing.T) {
+	frame := Frame{Func: "pkg.Fn", File: "/src/pkg/fn.go", Line: 10, Package: "pkg"}
+	assert.Equal(t, "pkg.Fn /src/pkg/fn.go:10", frame.String())
+	assert.Equal(t, []slog.Attr{
+		slog.String("func", "pkg.Fn"),
+		slog.String("file", "/src/pkg/fn.go"),
+		slog.Int("line", 10),
+	}, frame.LogValue().Group())
+}
+
+func TestPackageName(t *testing.T) {
+	assert.Equal(t, "github.com/joshjon/kit/errtag", PackageName("github.com/joshjon/kit/errtag.(*ErrorTag[...]).init"))
+	assert.Equal(t, "main", PackageName("main.main"))
+	assert.Equal(t, "unknown", PackageName("unknown"))
+}
+
+func TestStack(t *testing.T) {
+	stack := nestedStack()
+	require.GreaterOrEqual(t, len(stack), 2)
+	assert.Equal(t, "github.com/joshjon/kit/fname.nestedStack", stack[0].Func)
+	assert.Equal(t, "github.com/joshjon/kit/fname.TestStack", stack[1].Func)
+
+	assert.Len(t, Stack(0, 1), 1)
+	assert.Regexp(t, `^TestStack \(caller_test.go:\d+\)$`, Stack(0, 1).String())
+}
+
+func nestedStack() Frames {
+	return Stack(0, 0)
+}
@@ -4,16 +4,19 @@ import (
 	"reflect"
 	"runtime"
 	"strings"
+	"sync"
 )
 
+// funcNames caches function names keyed by PC since resolving them is
+// relatively expensive and they are used in per-request logging paths.
+var funcNames sync.Map // map[uintptr]string
+
 // FuncName returns the full function name in the form <prefix>.(*<type>).<function>
 func FuncName(fn any) string {
 	if fullName, ok := fn.(string); ok {
 		return fullName
 	}
-	fullName := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()
-	// Compiler adds -fm suffix to a function name which has a receiver.
-	return strings.TrimSuffix(fullName, "-fm")
+	return funcNameForPC(reflect.ValueOf(fn).Pointer())
 }
 
 // CurrentFuncName returns the name of the function that calls CurrentFuncName.
@@ -34,12 +37,21 @@ func CallerFuncName(skip int) string {
 	if !ok {
 		return "unknown"
 	}
+	return funcNameForPC(pc)
+}
 
-	if fn := runtime.FuncForPC(pc); fn != nil {
-		return strings.TrimSuffix(fn.Name(), "-fm")
+func funcNameForPC(pc uintptr) string {
+	if name, ok := funcNames.Load(pc); ok {
+		return name.(string)
 	}
-
-	return "unknown"
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return "unknown"
+	}
+	// Compiler adds -fm suffix to a function name which has a receiver.
+	name := strings.TrimSuffix(fn.Name(), "-fm")
+	funcNames.Store(pc, name)
+	return name
 }
 
 // ShortFuncName returns just the function/method name without package or type path.
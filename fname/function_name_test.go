@@ -0,0 +1,38 @@
+package fname
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testType struct{}
+
+func (testType) method() {}
+
+func TestFuncName(t *testing.T) {
+	assert.Equal(t, "github.com/joshjon/kit/fname.TestFuncName", FuncName(TestFuncName))
+	assert.Equal(t, "github.com/joshjon/kit/fname.testType.method", FuncName(testType{}.method))
+	assert.Equal(t, "custom", FuncName("custom"))
+	// cached
+	assert.Equal(t, "github.com/joshjon/kit/fname.TestFuncName", FuncName(TestFuncName))
+}
+
+func TestCallerFuncName(t *testing.T) {
+	assert.Equal(t, "github.com/joshjon/kit/fname.TestCallerFuncName", CurrentFuncName())
+	assert.Equal(t, "unknown", CallerFuncName(1000))
+}
+
+func BenchmarkFuncName(b *testing.B) {
+	b.ReportAllocs()
+	for b.Loop() {
+		FuncName(BenchmarkFuncName)
+	}
+}
+
+func BenchmarkCallerFuncName(b *testing.B) {
+	b.ReportAllocs()
+	for b.Loop() {
+		CallerFuncName(0)
+	}
+}
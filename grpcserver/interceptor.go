@@ -0,0 +1,192 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/cohesivestack/valgo"
+	"golang.org/x/text/language"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+
+	"github.com/joshjon/kit/errtag"
+	"github.com/joshjon/kit/log"
+	"github.com/joshjon/kit/valgoutil"
+)
+
+func unaryInterceptor(logger log.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (res any, err error) {
+		start := time.Now()
+		func() {
+			defer recoverHandler(&err)
+			res, err = handler(ctx, req)
+		}()
+		return res, finishRPC(ctx, logger, info.FullMethod, start, err)
+	}
+}
+
+func streamInterceptor(logger log.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		start := time.Now()
+		func() {
+			defer recoverHandler(&err)
+			err = handler(srv, ss)
+		}()
+		return finishRPC(ss.Context(), logger, info.FullMethod, start, err)
+	}
+}
+
+func recoverHandler(err *error) {
+	if r := recover(); r != nil {
+		*err = fmt.Errorf("panic: %v", r)
+	}
+}
+
+// finishRPC logs the RPC and returns err converted to a status error.
+func finishRPC(ctx context.Context, logger log.Logger, method string, start time.Time, err error) error {
+	latency := time.Since(start)
+	st := Status(ctx, err)
+
+	args := []any{
+		"method", method,
+		"code", st.Code().String(),
+		"latency_ms", latency.Milliseconds(),
+		"latency_human", latency.String(),
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		args = append(args, "remote_ip", p.Addr.String())
+	}
+
+	level := slog.LevelInfo
+	message := "rpc"
+	if err != nil {
+		message = "rpc error"
+		level = slog.LevelError
+		args = append(args, "error", err.Error())
+		if attrs := errtag.LogAttrs(err); len(attrs) > 0 {
+			args = append(args, "error_tag", slog.GroupValue(attrs...))
+		}
+	}
+	logger.Log(ctx, level, message, args...)
+
+	if err == nil {
+		return nil
+	}
+	return st.Err()
+}
+
+// Status returns the status the server responds with for err. Status errors
+// are returned as is, valgo errors are invalid arguments, errtag errors use
+// the code matching their HTTP status and context errors their own codes.
+// Any other error is internal, without exposing its message.
+func Status(ctx context.Context, err error) *status.Status {
+	if err == nil {
+		return status.New(codes.OK, "")
+	}
+	if st, ok := status.FromError(err); ok {
+		return st
+	}
+
+	var verr *valgo.Error
+	if errors.As(err, &verr) {
+		badRequest := &errdetails.BadRequest{}
+		for _, fieldErr := range valgoutil.GetFieldErrors(verr) {
+			for _, msg := range fieldErr.Messages {
+				badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+					Field:       fieldErr.Field,
+					Description: msg,
+				})
+			}
+		}
+		return withDetails(status.New(codes.InvalidArgument, http.StatusText(http.StatusBadRequest)), badRequest)
+	}
+
+	if tag, ok := errtag.Primary(err); ok {
+		st := status.New(codeFromHTTPStatus(errtag.HTTPStatus(tag)), errtag.MsgContext(localeContext(ctx), tag))
+		errCode, fields := errtag.ErrorCode(tag), errtag.Fields(tag)
+		if errCode == "" && len(fields) == 0 {
+			return st
+		}
+		info := &errdetails.ErrorInfo{Reason: errCode}
+		if len(fields) > 0 {
+			info.Metadata = make(map[string]string, len(fields))
+			for _, key := range slices.Sorted(maps.Keys(fields)) {
+				info.Metadata[key] = fmt.Sprint(fields[key])
+			}
+		}
+		return withDetails(st, info)
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.New(codes.Canceled, context.Canceled.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.New(codes.DeadlineExceeded, context.DeadlineExceeded.Error())
+	}
+	return status.New(codes.Internal, http.StatusText(http.StatusInternalServerError))
+}
+
+func withDetails(st *status.Status, details ...protoadapt.MessageV1) *status.Status {
+	if withDetails, err := st.WithDetails(details...); err == nil {
+		return withDetails
+	}
+	return st
+}
+
+func codeFromHTTPStatus(code int) codes.Code {
+	switch code {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	}
+	if code < http.StatusInternalServerError {
+		return codes.FailedPrecondition
+	}
+	return codes.Internal
+}
+
+// localeContext returns ctx with the preferred language from the
+// accept-language metadata, unless a locale was already set.
+func localeContext(ctx context.Context) context.Context {
+	if _, ok := errtag.LocaleFromContext(ctx); ok {
+		return ctx
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("accept-language")
+	if len(values) == 0 {
+		return ctx
+	}
+	tags, _, err := language.ParseAcceptLanguage(values[0])
+	if err != nil || len(tags) == 0 {
+		return ctx
+	}
+	return errtag.WithLocale(ctx, tags[0].String())
+}
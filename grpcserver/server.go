@@ -0,0 +1,224 @@
+// Package grpcserver provides a gRPC server with the logging, error handling
+// and TLS of package server.
+package grpcserver
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+
+	"github.com/joshjon/kit/log"
+	"github.com/joshjon/kit/retry"
+	"github.com/joshjon/kit/server"
+)
+
+// Option optionally configures a Server.
+type Option func(opts *options) error
+
+// WithLogger sets a custom Logger.
+func WithLogger(logger log.Logger) Option {
+	return func(opts *options) error {
+		opts.logger = logger
+		return nil
+	}
+}
+
+// WithTLS configures the server to use TLS with the specified certificate, key,
+// and optional CA certificate for mTLS. If caCertFile is provided, the server
+// requires client certificates and validates them against the CA.
+func WithTLS(certFile string, keyFile string, caCertFile string) Option {
+	return func(opts *options) error {
+		tlsCfg, err := server.NewTLSConfig(certFile, keyFile, caCertFile)
+		if err != nil {
+			return err
+		}
+		opts.serverOpts = append(opts.serverOpts, grpc.Creds(credentials.NewTLS(tlsCfg)))
+		opts.tls = true
+		return nil
+	}
+}
+
+// WithUnaryInterceptors adds custom unary interceptors to the server, run
+// after the built-in logging, recovery and error interceptor.
+func WithUnaryInterceptors(interceptors ...grpc.UnaryServerInterceptor) Option {
+	return func(opts *options) error {
+		opts.unaryInterceptors = append(opts.unaryInterceptors, interceptors...)
+		return nil
+	}
+}
+
+// WithStreamInterceptors adds custom stream interceptors to the server, run
+// after the built-in logging, recovery and error interceptor.
+func WithStreamInterceptors(interceptors ...grpc.StreamServerInterceptor) Option {
+	return func(opts *options) error {
+		opts.streamInterceptors = append(opts.streamInterceptors, interceptors...)
+		return nil
+	}
+}
+
+// WithServerOptions adds custom options to the underlying grpc.Server.
+func WithServerOptions(serverOpts ...grpc.ServerOption) Option {
+	return func(opts *options) error {
+		opts.serverOpts = append(opts.serverOpts, serverOpts...)
+		return nil
+	}
+}
+
+// WithReflection registers the reflection service, e.g. for grpcurl.
+func WithReflection() Option {
+	return func(opts *options) error {
+		opts.reflection = true
+		return nil
+	}
+}
+
+type options struct {
+	logger             log.Logger
+	unaryInterceptors  []grpc.UnaryServerInterceptor
+	streamInterceptors []grpc.StreamServerInterceptor
+	serverOpts         []grpc.ServerOption
+	tls                bool
+	reflection         bool
+}
+
+// Server is a gRPC server. It implements grpc.ServiceRegistrar, so generated
+// RegisterXServer funcs register services with it directly.
+type Server struct {
+	port   int
+	grpc   *grpc.Server
+	health *health.Server
+	tls    bool
+}
+
+var _ grpc.ServiceRegistrar = (*Server)(nil)
+
+// NewServer creates a new Server with the given options.
+func NewServer(port int, opts ...Option) (*Server, error) {
+	srvOpts := options{
+		logger: log.NewLogger(),
+	}
+
+	for _, opt := range opts {
+		if err := opt(&srvOpts); err != nil {
+			return nil, err
+		}
+	}
+
+	unary := append([]grpc.UnaryServerInterceptor{unaryInterceptor(srvOpts.logger)}, srvOpts.unaryInterceptors...)
+	stream := append([]grpc.StreamServerInterceptor{streamInterceptor(srvOpts.logger)}, srvOpts.streamInterceptors...)
+	grpcOpts := append([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	}, srvOpts.serverOpts...)
+
+	srv := &Server{
+		port:   port,
+		grpc:   grpc.NewServer(grpcOpts...),
+		health: health.NewServer(),
+		tls:    srvOpts.tls,
+	}
+
+	healthpb.RegisterHealthServer(srv.grpc, srv.health)
+	if srvOpts.reflection {
+		reflection.Register(srv.grpc)
+	}
+
+	return srv, nil
+}
+
+// RegisterService registers a service and its implementation.
+func (s *Server) RegisterService(desc *grpc.ServiceDesc, impl any) {
+	s.grpc.RegisterService(desc, impl)
+}
+
+// Health returns the health service, e.g. to set the serving status of
+// individual services. The overall status is serving until Stop is called.
+func (s *Server) Health() *health.Server {
+	return s.health
+}
+
+// Start begins serving on the configured port.
+func (s *Server) Start() error {
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", s.port))
+	if err != nil {
+		return err
+	}
+	err = s.grpc.Serve(lis)
+	if errors.Is(err, grpc.ErrServerStopped) {
+		return nil
+	}
+	return err
+}
+
+// Stop gracefully shuts down the server, waiting for pending RPCs to finish
+// until ctx is done, after which they are canceled.
+func (s *Server) Stop(ctx context.Context) error {
+	s.health.Shutdown()
+	done := make(chan struct{})
+	go func() {
+		s.grpc.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.grpc.Stop()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// WaitHealthy waits until the health service reports the server is serving.
+// It cannot check servers requiring client certificates (mTLS).
+func (s *Server) WaitHealthy(maxRetries int, interval time.Duration) error {
+	creds := insecure.NewCredentials()
+	if s.tls {
+		// Only the health of the server is checked, not its identity.
+		creds = credentials.NewTLS(&tls.Config{InsecureSkipVerify: true}) //nolint:gosec
+	}
+	conn, err := grpc.NewClient(s.Address(), grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return fmt.Errorf("server unhealthy: %w", err)
+	}
+	defer conn.Close() //nolint:errcheck
+
+	client := healthpb.NewHealthClient(conn)
+	policy := retry.Policy{
+		MaxAttempts:     max(maxRetries, 1),
+		InitialInterval: interval,
+		MaxInterval:     interval,
+		Jitter:          -1,
+	}
+	err = retry.DoErr(context.Background(), policy, func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		res, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+		if err != nil {
+			return err
+		}
+		if res.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+			return errors.New(res.GetStatus().String())
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("server unhealthy: %w", err)
+	}
+	return nil
+}
+
+// Address returns the server address which clients can connect to.
+func (s *Server) Address() string {
+	return fmt.Sprintf("localhost:%d", s.port)
+}
@@ -0,0 +1,277 @@
+package grpcserver
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/cohesivestack/valgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/joshjon/kit/errtag"
+	"github.com/joshjon/kit/log"
+	"github.com/joshjon/kit/testutil"
+)
+
+func TestServer_NewServer(t *testing.T) {
+	srv := startTestServer(t, func(ctx context.Context, in *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+		return in, nil
+	}, WithReflection())
+
+	res, err := echo(t, dial(t, srv, insecure.NewCredentials()), "hello")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", res.GetValue())
+}
+
+func TestServer_errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode codes.Code
+		wantMsg  string
+	}{
+		{
+			name:     "errtag",
+			err:      errtag.Tag[errtag.NotFound](errors.New("project 1 not found"), errtag.WithMsg("Project not found")),
+			wantCode: codes.NotFound,
+			wantMsg:  "Project not found",
+		},
+		{
+			name:     "status",
+			err:      status.Error(codes.Aborted, "aborted"),
+			wantCode: codes.Aborted,
+			wantMsg:  "aborted",
+		},
+		{
+			name:     "internal",
+			err:      errors.New("connection refused"),
+			wantCode: codes.Internal,
+			wantMsg:  "Internal Server Error",
+		},
+		{
+			name:     "deadline",
+			err:      context.DeadlineExceeded,
+			wantCode: codes.DeadlineExceeded,
+			wantMsg:  "context deadline exceeded",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := startTestServer(t, func(ctx context.Context, in *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+				return nil, tt.err
+			})
+			_, err := echo(t, dial(t, srv, insecure.NewCredentials()), "hello")
+			st := status.Convert(err)
+			assert.Equal(t, tt.wantCode, st.Code())
+			assert.Equal(t, tt.wantMsg, st.Message())
+		})
+	}
+}
+
+func TestServer_errorDetails(t *testing.T) {
+	srv := startTestServer(t, func(ctx context.Context, in *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+		if in.GetValue() == "" {
+			return nil, valgo.Is(valgo.String(in.GetValue(), "value").Not().Blank()).Error()
+		}
+		return nil, errtag.Tag[errtag.Conflict](errors.New("exists"), errtag.WithCode("project_exists"), errtag.WithField("id", 1))
+	})
+	conn := dial(t, srv, insecure.NewCredentials())
+
+	_, err := echo(t, conn, "")
+	st := status.Convert(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	require.Len(t, st.Details(), 1)
+	badRequest := st.Details()[0].(*errdetails.BadRequest)
+	require.Len(t, badRequest.GetFieldViolations(), 1)
+	assert.Equal(t, "value", badRequest.GetFieldViolations()[0].GetField())
+
+	_, err = echo(t, conn, "hello")
+	st = status.Convert(err)
+	assert.Equal(t, codes.AlreadyExists, st.Code())
+	require.Len(t, st.Details(), 1)
+	info := st.Details()[0].(*errdetails.ErrorInfo)
+	assert.Equal(t, "project_exists", info.GetReason())
+	assert.Equal(t, map[string]string{"id": "1"}, info.GetMetadata())
+}
+
+func TestServer_recover(t *testing.T) {
+	srv := startTestServer(t, func(ctx context.Context, in *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+		panic("boom")
+	})
+	conn := dial(t, srv, insecure.NewCredentials())
+
+	_, err := echo(t, conn, "hello")
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	// the server keeps serving
+	_, err = echo(t, conn, "hello")
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestServer_interceptors(t *testing.T) {
+	var gotMD metadata.MD
+	srv := startTestServer(t, func(ctx context.Context, in *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+		return in, nil
+	}, WithUnaryInterceptors(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		gotMD, _ = metadata.FromIncomingContext(ctx)
+		return handler(ctx, req)
+	}))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-request-id", "1")
+	res := new(wrapperspb.StringValue)
+	err := dial(t, srv, insecure.NewCredentials()).Invoke(ctx, "/test.Test/Echo", wrapperspb.String("hello"), res)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1"}, gotMD.Get("x-request-id"))
+}
+
+func TestServer_TLS(t *testing.T) {
+	ca := testutil.GenerateCA(t)
+	certFile, keyFile := testutil.GenerateCert(t, ca).WriteFiles(t)
+	srv := startTestServer(t, func(ctx context.Context, in *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+		return in, nil
+	}, WithTLS(certFile, keyFile, ""))
+
+	creds := credentials.NewTLS(&tls.Config{RootCAs: ca.CertPool()})
+	res, err := echo(t, dial(t, srv, creds), "hello")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", res.GetValue())
+}
+
+func TestServer_mTLS(t *testing.T) {
+	ca := testutil.GenerateCA(t)
+	caCertFile, _ := ca.WriteFiles(t)
+	certFile, keyFile := testutil.GenerateCert(t, ca).WriteFiles(t)
+	client := testutil.GenerateCert(t, ca, testutil.WithCommonName("client"), testutil.WithClientAuth())
+
+	srv, err := NewServer(testutil.GetFreePort(t),
+		WithLogger(log.NewLogger(log.WithNop())),
+		WithTLS(certFile, keyFile, caCertFile),
+	)
+	require.NoError(t, err)
+	srv.RegisterService(&testServiceDesc, echoFunc(func(ctx context.Context, in *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+		return in, nil
+	}))
+	go srv.Start()
+	t.Cleanup(func() {
+		require.NoError(t, srv.Stop(context.Background()))
+	})
+
+	creds := credentials.NewTLS(&tls.Config{
+		RootCAs:      ca.CertPool(),
+		Certificates: []tls.Certificate{client.TLSCertificate(t)},
+	})
+	require.Eventually(t, func() bool {
+		_, err := echo(t, dial(t, srv, creds), "hello")
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// without a client certificate
+	creds = credentials.NewTLS(&tls.Config{RootCAs: ca.CertPool()})
+	_, err = echo(t, dial(t, srv, creds), "hello")
+	assert.Error(t, err)
+}
+
+func TestServer_Stop(t *testing.T) {
+	started := make(chan struct{})
+	srv := startTestServer(t, func(ctx context.Context, in *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	conn := dial(t, srv, insecure.NewCredentials())
+
+	errs := make(chan error, 1)
+	go func() {
+		_, err := echo(t, conn, "hello")
+		errs <- err
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, srv.Stop(ctx), context.DeadlineExceeded)
+	assert.Error(t, <-errs)
+}
+
+type testService interface {
+	Echo(ctx context.Context, in *wrapperspb.StringValue) (*wrapperspb.StringValue, error)
+}
+
+type echoFunc func(ctx context.Context, in *wrapperspb.StringValue) (*wrapperspb.StringValue, error)
+
+func (f echoFunc) Echo(ctx context.Context, in *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+	return f(ctx, in)
+}
+
+// testServiceDesc is the descriptor protoc would generate for:
+//
+//	service Test { rpc Echo(google.protobuf.StringValue) returns (google.protobuf.StringValue); }
+var testServiceDesc = grpc.ServiceDesc{
+	ServiceName: "test.Test",
+	HandlerType: (*testService)(nil),
+	Methods: []grpc.MethodDesc{{
+		MethodName: "Echo",
+		Handler: func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+			in := new(wrapperspb.StringValue)
+			if err := dec(in); err != nil {
+				return nil, err
+			}
+			handler := func(ctx context.Context, req any) (any, error) {
+				return srv.(testService).Echo(ctx, req.(*wrapperspb.StringValue))
+			}
+			info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/test.Test/Echo"}
+			return interceptor(ctx, in, info, handler)
+		},
+	}},
+}
+
+func startTestServer(t *testing.T, fn echoFunc, opts ...Option) *Server {
+	t.Helper()
+	opts = append([]Option{WithLogger(log.NewLogger(log.WithNop()))}, opts...)
+	srv, err := NewServer(testutil.GetFreePort(t), opts...)
+	require.NoError(t, err)
+	srv.RegisterService(&testServiceDesc, fn)
+
+	go srv.Start()
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = srv.Stop(ctx)
+	})
+	require.NoError(t, srv.WaitHealthy(100, 10*time.Millisecond))
+	return srv
+}
+
+func dial(t *testing.T, srv *Server, creds credentials.TransportCredentials) *grpc.ClientConn {
+	t.Helper()
+	// Dial the IP rather than srv.Address to not depend on resolving localhost.
+	addr := net.JoinHostPort(testutil.LocalIP, strconv.Itoa(srv.port))
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return conn
+}
+
+func echo(t *testing.T, conn *grpc.ClientConn, value string) (*wrapperspb.StringValue, error) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	res := new(wrapperspb.StringValue)
+	err := conn.Invoke(ctx, "/test.Test/Echo", wrapperspb.String(value), res)
+	return res, err
+}
@@ -0,0 +1,262 @@
+// Package health aggregates the health of the dependencies of a service, such
+// as databases, message brokers and downstream services, into liveness and
+// readiness reports.
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/joshjon/kit/clock"
+)
+
+const (
+	defaultCacheTTL     = time.Second
+	defaultCheckTimeout = 5 * time.Second
+)
+
+// Checker checks the health of a dependency.
+type Checker interface {
+	// Check returns an error if the dependency is unhealthy.
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc is a func implementing Checker.
+type CheckerFunc func(ctx context.Context) error
+
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// Group groups checks by what their failure means for the service.
+type Group string
+
+const (
+	// Liveness checks fail when the service is broken and must be restarted.
+	Liveness Group = "liveness"
+	// Readiness checks fail when the service cannot serve traffic, e.g.
+	// because a dependency is unavailable.
+	Readiness Group = "readiness"
+)
+
+// Status is the status of a check or report.
+type Status string
+
+const (
+	StatusUp   Status = "up"
+	StatusDown Status = "down"
+)
+
+// Result is the result of a check.
+type Result struct {
+	Status     Status    `json:"status"`
+	Error      string    `json:"error,omitempty"`
+	DurationMS int64     `json:"duration_ms"`
+	CheckedAt  time.Time `json:"checked_at"`
+}
+
+// Report is the aggregated result of the checks of a group. Its status is
+// down if any check is down.
+type Report struct {
+	Status Status            `json:"status"`
+	Checks map[string]Result `json:"checks,omitempty"`
+}
+
+// Err returns an error listing the failed checks of the report, or nil if
+// the report is up.
+func (r Report) Err() error {
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(r.Checks)) {
+		if res := r.Checks[name]; res.Status == StatusDown {
+			errs = append(errs, fmt.Errorf("%s: %s", name, res.Error))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Option optionally configures a Registry.
+type Option func(opts *options)
+
+// WithCacheTTL sets how long results are cached, so frequent probes do not
+// overload dependencies. Defaults to 1s. A negative value disables caching.
+func WithCacheTTL(ttl time.Duration) Option {
+	return func(opts *options) {
+		opts.cacheTTL = ttl
+	}
+}
+
+// WithClock sets the clock used to expire cached results. Defaults to
+// clock.Real.
+func WithClock(clk clock.Clock) Option {
+	return func(opts *options) {
+		opts.clock = clk
+	}
+}
+
+type options struct {
+	cacheTTL time.Duration
+	clock    clock.Clock
+}
+
+// CheckOption optionally configures a check.
+type CheckOption func(opts *checkOptions)
+
+// WithTimeout sets the timeout of the check, after which it is down.
+// Defaults to 5s.
+func WithTimeout(d time.Duration) CheckOption {
+	return func(opts *checkOptions) {
+		opts.timeout = d
+	}
+}
+
+// WithGroups sets the groups of the check. Defaults to Readiness.
+func WithGroups(groups ...Group) CheckOption {
+	return func(opts *checkOptions) {
+		opts.groups = groups
+	}
+}
+
+type checkOptions struct {
+	timeout time.Duration
+	groups  []Group
+}
+
+type check struct {
+	name    string
+	checker Checker
+	opts    checkOptions
+
+	mu     sync.Mutex // held while checking so concurrent callers share a result
+	result Result
+}
+
+// Registry is a registry of named checks.
+type Registry struct {
+	opts options
+
+	mu     sync.RWMutex
+	checks []*check
+}
+
+// NewRegistry creates a new Registry.
+func NewRegistry(opts ...Option) *Registry {
+	options := options{
+		cacheTTL: defaultCacheTTL,
+		clock:    clock.Real,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &Registry{opts: options}
+}
+
+// Register registers checker under name. It panics if a check with the same
+// name is already registered.
+func (r *Registry) Register(name string, checker Checker, opts ...CheckOption) {
+	options := checkOptions{
+		timeout: defaultCheckTimeout,
+		groups:  []Group{Readiness},
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, c := range r.checks {
+		if c.name == name {
+			panic(fmt.Sprintf("health: check %q already registered", name))
+		}
+	}
+	r.checks = append(r.checks, &check{name: name, checker: checker, opts: options})
+}
+
+// Check runs the checks of group concurrently and reports their results.
+// Results cached within the cache TTL are reported without checking again.
+func (r *Registry) Check(ctx context.Context, group Group) Report {
+	return r.check(ctx, group, r.opts.cacheTTL)
+}
+
+func (r *Registry) check(ctx context.Context, group Group, cacheTTL time.Duration) Report {
+	r.mu.RLock()
+	var checks []*check
+	for _, c := range r.checks {
+		if slices.Contains(c.opts.groups, group) {
+			checks = append(checks, c)
+		}
+	}
+	r.mu.RUnlock()
+
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Go(func() {
+			results[i] = r.run(ctx, c, cacheTTL)
+		})
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusUp}
+	if len(checks) > 0 {
+		report.Checks = make(map[string]Result, len(checks))
+	}
+	for i, c := range checks {
+		report.Checks[c.name] = results[i]
+		if results[i].Status == StatusDown {
+			report.Status = StatusDown
+		}
+	}
+	return report
+}
+
+func (r *Registry) run(ctx context.Context, c *check, cacheTTL time.Duration) Result {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.result.CheckedAt.IsZero() && r.opts.clock.Since(c.result.CheckedAt) < cacheTTL {
+		return c.result
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, c.opts.timeout)
+	defer cancel()
+
+	start := r.opts.clock.Now()
+	err := runCheck(checkCtx, c.checker)
+	res := Result{
+		Status:     StatusUp,
+		DurationMS: r.opts.clock.Since(start).Milliseconds(),
+		CheckedAt:  start,
+	}
+	if err != nil {
+		res.Status = StatusDown
+		res.Error = err.Error()
+	}
+	if ctx.Err() == nil {
+		// Results of checks canceled by the caller are not cached.
+		c.result = res
+	}
+	return res
+}
+
+// runCheck runs checker, returning once ctx is done even if checker does not.
+func runCheck(ctx context.Context, checker Checker) error {
+	errc := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				errc <- fmt.Errorf("check panicked: %v", r)
+			}
+		}()
+		errc <- checker.Check(ctx)
+	}()
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,99 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/health"
+	"github.com/joshjon/kit/testutil"
+)
+
+func TestRegistry_Check(t *testing.T) {
+	registry := health.NewRegistry(health.WithCacheTTL(-1))
+	registry.Register("db", health.CheckerFunc(func(ctx context.Context) error {
+		return nil
+	}))
+	registry.Register("queue", health.CheckerFunc(func(ctx context.Context) error {
+		return errors.New("connection refused")
+	}))
+	registry.Register("deadlock", health.CheckerFunc(func(ctx context.Context) error {
+		return nil
+	}), health.WithGroups(health.Liveness, health.Readiness))
+
+	report := registry.Check(context.Background(), health.Readiness)
+	assert.Equal(t, health.StatusDown, report.Status)
+	require.Len(t, report.Checks, 3)
+	assert.Equal(t, health.StatusUp, report.Checks["db"].Status)
+	assert.Equal(t, health.StatusUp, report.Checks["deadlock"].Status)
+	assert.Equal(t, health.StatusDown, report.Checks["queue"].Status)
+	assert.Equal(t, "connection refused", report.Checks["queue"].Error)
+	assert.EqualError(t, report.Err(), "queue: connection refused")
+
+	report = registry.Check(context.Background(), health.Liveness)
+	assert.Equal(t, health.StatusUp, report.Status)
+	require.Len(t, report.Checks, 1)
+	assert.Equal(t, health.StatusUp, report.Checks["deadlock"].Status)
+	assert.NoError(t, report.Err())
+}
+
+func TestRegistry_Check_noChecks(t *testing.T) {
+	report := health.NewRegistry().Check(context.Background(), health.Liveness)
+	assert.Equal(t, health.StatusUp, report.Status)
+	assert.Empty(t, report.Checks)
+}
+
+func TestRegistry_Check_cache(t *testing.T) {
+	clk := testutil.NewFakeClock(time.Now())
+	registry := health.NewRegistry(health.WithCacheTTL(time.Second), health.WithClock(clk))
+
+	var calls atomic.Int32
+	registry.Register("db", health.CheckerFunc(func(ctx context.Context) error {
+		calls.Add(1)
+		return nil
+	}))
+
+	registry.Check(context.Background(), health.Readiness)
+	registry.Check(context.Background(), health.Readiness)
+	assert.Equal(t, int32(1), calls.Load())
+
+	clk.Advance(time.Second)
+	registry.Check(context.Background(), health.Readiness)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestRegistry_Check_timeout(t *testing.T) {
+	registry := health.NewRegistry()
+	registry.Register("slow", health.CheckerFunc(func(ctx context.Context) error {
+		time.Sleep(time.Second) // ignores ctx
+		return nil
+	}), health.WithTimeout(10*time.Millisecond))
+
+	report := registry.Check(context.Background(), health.Readiness)
+	assert.Equal(t, health.StatusDown, report.Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["slow"].Error)
+}
+
+func TestRegistry_Check_panic(t *testing.T) {
+	registry := health.NewRegistry()
+	registry.Register("panics", health.CheckerFunc(func(ctx context.Context) error {
+		panic("boom")
+	}))
+
+	report := registry.Check(context.Background(), health.Readiness)
+	assert.Equal(t, health.StatusDown, report.Status)
+	assert.Equal(t, "check panicked: boom", report.Checks["panics"].Error)
+}
+
+func TestRegistry_Register_duplicate(t *testing.T) {
+	registry := health.NewRegistry()
+	registry.Register("db", health.CheckerFunc(func(ctx context.Context) error { return nil }))
+	assert.Panics(t, func() {
+		registry.Register("db", health.CheckerFunc(func(ctx context.Context) error { return nil }))
+	})
+}
@@ -0,0 +1,48 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Handler returns a handler responding with the Report of group, with status
+// 200 if it is up and 503 if it is down. Add it to a server.Server, e.g.:
+//
+//	srv.Add(http.MethodGet, "/readyz", health.Handler(registry, health.Readiness))
+func Handler(registry *Registry, group Group) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		report := registry.Check(c.Request().Context(), group)
+		status := http.StatusOK
+		if report.Status == StatusDown {
+			status = http.StatusServiceUnavailable
+		}
+		return c.JSON(status, report)
+	}
+}
+
+// HTTPChecker checks the health of a downstream service with a GET request
+// to url, which is healthy if it responds with a 2xx status. The default
+// client is used if client is nil.
+func HTTPChecker(url string, client *http.Client) Checker {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return CheckerFunc(func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		res, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		res.Body.Close() //nolint:errcheck
+		if res.StatusCode < 200 || res.StatusCode >= 300 {
+			return fmt.Errorf("responded with status %d", res.StatusCode)
+		}
+		return nil
+	})
+}
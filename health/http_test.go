@@ -0,0 +1,61 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	var healthy bool
+	registry := NewRegistry(WithCacheTTL(-1))
+	registry.Register("db", CheckerFunc(func(ctx context.Context) error {
+		if !healthy {
+			return errors.New("connection refused")
+		}
+		return nil
+	}))
+	handler := Handler(registry, Readiness)
+
+	serve := func() (*httptest.ResponseRecorder, Report) {
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/readyz", nil), rec)
+		require.NoError(t, handler(c))
+		var report Report
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+		return rec, report
+	}
+
+	rec, report := serve()
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, StatusDown, report.Status)
+	assert.Equal(t, "connection refused", report.Checks["db"].Error)
+
+	healthy = true
+	rec, report = serve()
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, StatusUp, report.Status)
+	assert.Equal(t, StatusUp, report.Checks["db"].Status)
+}
+
+func TestHTTPChecker(t *testing.T) {
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/healthz", r.URL.Path)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+
+	checker := HTTPChecker(srv.URL+"/healthz", nil)
+	assert.NoError(t, checker.Check(context.Background()))
+
+	status = http.StatusServiceUnavailable
+	assert.EqualError(t, checker.Check(context.Background()), "responded with status 503")
+}
@@ -0,0 +1,67 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/joshjon/kit/retry"
+)
+
+const (
+	defaultWaitTimeout  = time.Minute
+	defaultWaitInterval = time.Second
+)
+
+// Wait waits until the checks of group are up, checking again according to
+// policy. Cached results are not used. It returns the failed checks of the
+// last report if they are still down when policy stops retrying.
+func Wait(ctx context.Context, registry *Registry, group Group, policy retry.Policy) error {
+	return retry.DoErr(ctx, policy, func(ctx context.Context) error {
+		return registry.check(ctx, group, 0).Err()
+	})
+}
+
+// WaitCommand returns a CLI command waiting until the readiness checks of
+// registry are up, e.g. to run before a service or its migrations in
+// deployments where dependencies start concurrently.
+func WaitCommand(registry *Registry) *cli.Command {
+	return &cli.Command{
+		Name:  "wait",
+		Usage: "waits until dependencies are healthy",
+		Flags: []cli.Flag{
+			&cli.DurationFlag{
+				Name:  "timeout",
+				Value: defaultWaitTimeout,
+				Usage: "maximum duration to wait",
+			},
+			&cli.DurationFlag{
+				Name:  "interval",
+				Value: defaultWaitInterval,
+				Usage: "interval between checks",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			ctx, cancel := context.WithTimeout(c.Context, c.Duration("timeout"))
+			defer cancel()
+
+			interval := c.Duration("interval")
+			policy := retry.Policy{
+				MaxAttempts:     -1,
+				InitialInterval: interval,
+				MaxInterval:     interval,
+				Jitter:          -1,
+				OnRetry: func(attempt int, err error, _ time.Duration) {
+					fmt.Fprintf(c.App.ErrWriter, "dependencies unhealthy (attempt %d): %v\n", attempt, err)
+				},
+			}
+			if err := Wait(ctx, registry, Readiness, policy); err != nil {
+				return fmt.Errorf("dependencies unhealthy: %w", err)
+			}
+			fmt.Fprintln(c.App.Writer, "dependencies healthy")
+			return nil
+		},
+	}
+}
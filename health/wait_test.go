@@ -0,0 +1,80 @@
+package health
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+
+	"github.com/joshjon/kit/retry"
+)
+
+func TestWait(t *testing.T) {
+	var calls atomic.Int32
+	// Caching would report the first result on every attempt.
+	registry := NewRegistry(WithCacheTTL(time.Hour))
+	registry.Register("db", CheckerFunc(func(ctx context.Context) error {
+		if calls.Add(1) < 3 {
+			return errors.New("connection refused")
+		}
+		return nil
+	}))
+
+	policy := retry.Policy{MaxAttempts: 5, InitialInterval: time.Millisecond}
+	require.NoError(t, Wait(context.Background(), registry, Readiness, policy))
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestWait_unhealthy(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("db", CheckerFunc(func(ctx context.Context) error {
+		return errors.New("connection refused")
+	}))
+
+	policy := retry.Policy{MaxAttempts: 2, InitialInterval: time.Millisecond}
+	err := Wait(context.Background(), registry, Readiness, policy)
+	assert.EqualError(t, err, "db: connection refused")
+}
+
+func TestWaitCommand(t *testing.T) {
+	var healthy atomic.Bool
+	registry := NewRegistry()
+	registry.Register("db", CheckerFunc(func(ctx context.Context) error {
+		if !healthy.Load() {
+			healthy.Store(true)
+			return errors.New("connection refused")
+		}
+		return nil
+	}))
+
+	var stdout, stderr bytes.Buffer
+	app := &cli.App{
+		Commands:  []*cli.Command{WaitCommand(registry)},
+		Writer:    &stdout,
+		ErrWriter: &stderr,
+	}
+	require.NoError(t, app.Run([]string{"app", "wait", "--interval", "1ms"}))
+	assert.Equal(t, "dependencies healthy\n", stdout.String())
+	assert.Equal(t, "dependencies unhealthy (attempt 1): db: connection refused\n", stderr.String())
+}
+
+func TestWaitCommand_timeout(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("db", CheckerFunc(func(ctx context.Context) error {
+		return errors.New("connection refused")
+	}))
+
+	app := &cli.App{
+		Commands:  []*cli.Command{WaitCommand(registry)},
+		Writer:    &bytes.Buffer{},
+		ErrWriter: &bytes.Buffer{},
+	}
+	err := app.Run([]string{"app", "wait", "--timeout", "20ms", "--interval", "1ms"})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -1,3 +1,8 @@
+// Package id generates and parses identifiers. Public IDs are typeids
+// prefixed with the type of the entity they identify, e.g.
+// user_01h455vb4pex5vsknk084sn02q, created with New for a typeid subtype.
+// Internal IDs are UUIDv7s created with NewUUIDv7, optionally typed with
+// Typed, or ULIDs created with NewULID. All of them sort by creation time.
 package id
 
 import "go.jetify.com/typeid"
@@ -26,3 +31,15 @@ func Parse[I ID, PI SubtypePtr[I]](id string) (I, error) {
 func MustParse[I ID, PI SubtypePtr[I]](id string) I {
 	return typeid.Must(Parse[I, PI](id))
 }
+
+// Validate returns an error if id is not a valid ID of the specified type,
+// including its prefix.
+func Validate[I ID, PI SubtypePtr[I]](id string) error {
+	_, err := Parse[I, PI](id)
+	return err
+}
+
+// IsValid reports whether id is a valid ID of the specified type.
+func IsValid[I ID, PI SubtypePtr[I]](id string) bool {
+	return Validate[I, PI](id) == nil
+}
@@ -0,0 +1,102 @@
+package id
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.jetify.com/typeid"
+)
+
+type userPrefix struct{}
+
+func (userPrefix) Prefix() string { return "user" }
+
+type userID struct {
+	typeid.TypeID[userPrefix]
+}
+
+func TestValidate(t *testing.T) {
+	valid := New[userID]().String()
+	assert.True(t, strings.HasPrefix(valid, "user_"))
+	assert.NoError(t, Validate[userID](valid))
+	assert.True(t, IsValid[userID](valid))
+
+	assert.Error(t, Validate[userID]("order_01h455vb4pex5vsknk084sn02q"))
+	assert.Error(t, Validate[userID]("user_invalid"))
+	assert.False(t, IsValid[userID](""))
+}
+
+type user struct{}
+
+func TestTyped(t *testing.T) {
+	want := NewTyped[user]()
+	assert.False(t, want.IsZero())
+	assert.Equal(t, uuid.Version(7), want.UUID().Version())
+
+	b, err := json.Marshal(want)
+	require.NoError(t, err)
+	var got Typed[user]
+	require.NoError(t, json.Unmarshal(b, &got))
+	assert.Equal(t, want, got)
+
+	var scanned Typed[user]
+	v, err := want.Value()
+	require.NoError(t, err)
+	require.NoError(t, scanned.Scan(v))
+	assert.Equal(t, want, scanned)
+
+	var zero Typed[user]
+	v, err = zero.Value()
+	require.NoError(t, err)
+	assert.Nil(t, v)
+	require.NoError(t, scanned.Scan(nil))
+	assert.True(t, scanned.IsZero())
+
+	_, err = ParseTyped[user]("invalid")
+	assert.Error(t, err)
+}
+
+func TestULID(t *testing.T) {
+	before := time.Now().Truncate(time.Millisecond)
+	want := NewULID()
+	assert.Len(t, want.String(), ulidLen)
+	assert.False(t, want.Time().Before(before))
+
+	got, err := ParseULID(want.String())
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	got, err = ParseULID(strings.ToLower(want.String()))
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	b, err := json.Marshal(want)
+	require.NoError(t, err)
+	var unmarshaled ULID
+	require.NoError(t, json.Unmarshal(b, &unmarshaled))
+	assert.Equal(t, want, unmarshaled)
+}
+
+func TestParseULID(t *testing.T) {
+	u, err := ParseULID("01ARZ3NDEKTSV4RRFFQ69G5FAV")
+	require.NoError(t, err)
+	assert.Equal(t, "01ARZ3NDEKTSV4RRFFQ69G5FAV", u.String())
+	assert.Equal(t, int64(1469922850259), u.Time().UnixMilli())
+
+	for _, invalid := range []string{"", "01ARZ3NDEKTSV4RRFFQ69G5FA", "81ARZ3NDEKTSV4RRFFQ69G5FAV", "01ARZ3NDEKTSV4RRFFQ69G5FAU"} {
+		_, err = ParseULID(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestULID_sortable(t *testing.T) {
+	a := NewULID()
+	time.Sleep(2 * time.Millisecond)
+	b := NewULID()
+	assert.Less(t, a.String(), b.String())
+}
@@ -0,0 +1,131 @@
+package id
+
+import (
+	"crypto/rand"
+	"database/sql/driver"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	ulidLen      = 26
+	ulidEncoding = "0123456789ABCDEFGHJKMNPQRSTVWXYZ" // Crockford's base32
+)
+
+var errInvalidULID = errors.New("invalid ulid")
+
+// ULID is a Universally Unique Lexicographically Sortable Identifier: a 48 bit
+// millisecond timestamp followed by 80 random bits, encoded as 26 characters
+// of Crockford's base32, e.g. 01ARZ3NDEKTSV4RRFFQ69G5FAV. It is encoded as a
+// string in JSON, text and SQL, where the zero ULID is encoded as an empty
+// string and NULL.
+type ULID [16]byte
+
+// NewULID creates a new ULID with the current time.
+func NewULID() ULID {
+	var u ULID
+	ms := uint64(time.Now().UnixMilli())
+	binary.BigEndian.PutUint16(u[0:2], uint16(ms>>32))
+	binary.BigEndian.PutUint32(u[2:6], uint32(ms))
+	_, _ = rand.Read(u[6:]) // never returns an error
+	return u
+}
+
+// ParseULID parses a ULID string, ignoring case.
+func ParseULID(s string) (ULID, error) {
+	var u ULID
+	if len(s) != ulidLen {
+		return u, fmt.Errorf("parse %q: %w", s, errInvalidULID)
+	}
+	var hi, lo uint64
+	for i := 0; i < ulidLen; i++ {
+		v := decodeULIDChar(s[i])
+		if v < 0 || (i == 0 && v > 7) { // 26 chars encode 130 bits
+			return u, fmt.Errorf("parse %q: %w", s, errInvalidULID)
+		}
+		hi = hi<<5 | lo>>59
+		lo = lo<<5 | uint64(v)
+	}
+	binary.BigEndian.PutUint64(u[:8], hi)
+	binary.BigEndian.PutUint64(u[8:], lo)
+	return u, nil
+}
+
+func decodeULIDChar(c byte) int {
+	if c >= 'a' && c <= 'z' {
+		c -= 'a' - 'A'
+	}
+	for i := 0; i < len(ulidEncoding); i++ {
+		if ulidEncoding[i] == c {
+			return i
+		}
+	}
+	return -1
+}
+
+// Time returns the time the ULID was created, with millisecond precision.
+func (u ULID) Time() time.Time {
+	ms := uint64(binary.BigEndian.Uint16(u[0:2]))<<32 | uint64(binary.BigEndian.Uint32(u[2:6]))
+	return time.UnixMilli(int64(ms))
+}
+
+func (u ULID) IsZero() bool {
+	return u == ULID{}
+}
+
+func (u ULID) String() string {
+	if u.IsZero() {
+		return ""
+	}
+	hi := binary.BigEndian.Uint64(u[:8])
+	lo := binary.BigEndian.Uint64(u[8:])
+	var b [ulidLen]byte
+	for i := ulidLen - 1; i >= 0; i-- {
+		b[i] = ulidEncoding[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(b[:])
+}
+
+func (u ULID) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+func (u *ULID) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*u = ULID{}
+		return nil
+	}
+	parsed, err := ParseULID(string(text))
+	if err != nil {
+		return err
+	}
+	*u = parsed
+	return nil
+}
+
+// Value implements driver.Valuer.
+func (u ULID) Value() (driver.Value, error) {
+	if u.IsZero() {
+		return nil, nil
+	}
+	return u.String(), nil
+}
+
+// Scan implements sql.Scanner.
+func (u *ULID) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*u = ULID{}
+		return nil
+	case string:
+		return u.UnmarshalText([]byte(v))
+	case []byte:
+		return u.UnmarshalText(v)
+	default:
+		return fmt.Errorf("scan ulid: unsupported type %T", src)
+	}
+}
@@ -0,0 +1,96 @@
+package id
+
+import (
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// NewUUIDv7 creates a new UUIDv7, which sorts by creation time. It panics if
+// the UUID cannot be generated.
+func NewUUIDv7() uuid.UUID {
+	return uuid.Must(uuid.NewV7())
+}
+
+// Typed is a UUIDv7 identifying an entity of type T, so IDs of different
+// entities can't be mixed up, e.g. Typed[User] and Typed[Order]. It is
+// encoded as a UUID string in JSON, text and SQL, where the zero ID is
+// encoded as an empty string and NULL.
+type Typed[T any] struct {
+	uuid uuid.UUID
+}
+
+// NewTyped creates a new Typed ID.
+func NewTyped[T any]() Typed[T] {
+	return Typed[T]{uuid: NewUUIDv7()}
+}
+
+// ParseTyped parses a UUID string into a Typed ID.
+func ParseTyped[T any](s string) (Typed[T], error) {
+	u, err := uuid.Parse(s)
+	if err != nil {
+		return Typed[T]{}, fmt.Errorf("parse id: %w", err)
+	}
+	return Typed[T]{uuid: u}, nil
+}
+
+// UUID returns the UUID of the ID.
+func (i Typed[T]) UUID() uuid.UUID {
+	return i.uuid
+}
+
+func (i Typed[T]) String() string {
+	if i.IsZero() {
+		return ""
+	}
+	return i.uuid.String()
+}
+
+func (i Typed[T]) IsZero() bool {
+	return i.uuid == uuid.Nil
+}
+
+func (i Typed[T]) MarshalText() ([]byte, error) {
+	return []byte(i.String()), nil
+}
+
+func (i *Typed[T]) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*i = Typed[T]{}
+		return nil
+	}
+	parsed, err := ParseTyped[T](string(text))
+	if err != nil {
+		return err
+	}
+	*i = parsed
+	return nil
+}
+
+// Value implements driver.Valuer.
+func (i Typed[T]) Value() (driver.Value, error) {
+	if i.IsZero() {
+		return nil, nil
+	}
+	return i.uuid.String(), nil
+}
+
+// Scan implements sql.Scanner.
+func (i *Typed[T]) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*i = Typed[T]{}
+		return nil
+	case string:
+		return i.UnmarshalText([]byte(v))
+	case []byte:
+		if len(v) == 16 {
+			copy(i.uuid[:], v)
+			return nil
+		}
+		return i.UnmarshalText(v)
+	default:
+		return fmt.Errorf("scan id: unsupported type %T", src)
+	}
+}
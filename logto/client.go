@@ -6,9 +6,24 @@ import (
 	"github.com/joshjon/kit/auth"
 )
 
-func OIDCProviderInitializer(cfg *client.LogtoConfig) auth.OIDCProviderInitializer {
+// ClientOption optionally configures a Client.
+type ClientOption func(opts *clientOptions)
+
+// WithTokenCache sets a TokenCache shared across Clients to cache access
+// tokens and refresh them before they expire.
+func WithTokenCache(cache *TokenCache) ClientOption {
+	return func(opts *clientOptions) {
+		opts.cache = cache
+	}
+}
+
+type clientOptions struct {
+	cache *TokenCache
+}
+
+func OIDCProviderInitializer(cfg *client.LogtoConfig, opts ...ClientOption) auth.OIDCProviderInitializer {
 	return func(storage *auth.SessionStorage) auth.OIDCProvider {
-		return NewClient(cfg, storage)
+		return NewClient(cfg, storage, opts...)
 	}
 }
 
@@ -16,23 +31,45 @@ var _ auth.OIDCProvider = (*Client)(nil)
 
 type Client struct {
 	*client.LogtoClient
-	cfg *client.LogtoConfig
+	cfg   *client.LogtoConfig
+	cache *TokenCache
 }
 
-func NewClient(cfg *client.LogtoConfig, storage *auth.SessionStorage) *Client {
+func NewClient(cfg *client.LogtoConfig, storage *auth.SessionStorage, opts ...ClientOption) *Client {
+	var options clientOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	var store client.Storage = storage
+	if options.cache != nil {
+		store = &earlyExpiryStorage{
+			Storage: storage,
+			leeway:  int64(options.cache.opts.leeway.Seconds()),
+		}
+	}
+
 	return &Client{
-		LogtoClient: client.NewLogtoClient(cfg, storage),
+		LogtoClient: client.NewLogtoClient(cfg, store),
 		cfg:         cfg,
+		cache:       options.cache,
 	}
 }
 
 func (c *Client) GetAccessToken(resource string) (auth.AccessToken, error) {
-	// Debug: Check if token is in session before calling Logto SDK
-	// The Logto SDK should be checking session storage internally, but let's verify
+	refreshToken := c.GetRefreshToken()
+	if c.cache == nil || refreshToken == "" {
+		return c.fetchAccessToken(resource)
+	}
+	return c.cache.getAccessToken(refreshToken, resource, func() (auth.AccessToken, error) {
+		return c.fetchAccessToken(resource)
+	})
+}
+
+func (c *Client) fetchAccessToken(resource string) (auth.AccessToken, error) {
 	tkn, err := c.LogtoClient.GetAccessToken(resource)
 	if err != nil {
 		return auth.AccessToken{}, err
 	}
-	// TODO: Add logging to see if this is fetching from cache or making network call
 	return auth.AccessToken(tkn), nil
 }
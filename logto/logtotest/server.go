@@ -0,0 +1,461 @@
+// Package logtotest provides an in-process Logto compatible OIDC issuer for
+// integration testing sign-in flows without a real Logto instance.
+package logtotest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/logto-io/go/v2/client"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	DefaultAppID     = "test-app"
+	DefaultAppSecret = "test-app-secret"
+
+	defaultTokenTTL = time.Hour
+	keyID           = "logtotest"
+)
+
+// User is the user signed in by the server. Its fields are issued as ID token
+// and userinfo claims.
+type User struct {
+	ID            string
+	Username      string
+	Name          string
+	Email         string
+	Roles         []string
+	Organizations []string
+}
+
+// DefaultUser is signed in when no user is configured.
+var DefaultUser = User{
+	ID:       "test-user",
+	Username: "test",
+	Name:     "Test User",
+	Email:    "test@example.com",
+}
+
+// Option optionally configures a Server.
+type Option func(opts *options)
+
+// WithApp sets the credentials of the registered application. Defaults to
+// DefaultAppID and DefaultAppSecret.
+func WithApp(appID string, appSecret string) Option {
+	return func(opts *options) {
+		opts.appID = appID
+		opts.appSecret = appSecret
+	}
+}
+
+// WithUser sets the user signed in by the authorization endpoint. Defaults to
+// DefaultUser.
+func WithUser(user User) Option {
+	return func(opts *options) {
+		opts.user = user
+	}
+}
+
+// WithTokenTTL sets the lifetime of issued access tokens. Defaults to 1 hour.
+func WithTokenTTL(ttl time.Duration) Option {
+	return func(opts *options) {
+		opts.tokenTTL = ttl
+	}
+}
+
+type options struct {
+	appID     string
+	appSecret string
+	user      User
+	tokenTTL  time.Duration
+}
+
+// Server is a fake Logto server implementing the OIDC endpoints used by the
+// Logto client: discovery, JWKS, authorization, token, userinfo, revocation
+// and end session. The authorization endpoint signs in the configured user
+// without any interaction and redirects straight back to the client.
+type Server struct {
+	*httptest.Server
+	opts   options
+	key    *ecdsa.PrivateKey
+	signer jose.Signer
+
+	mu            sync.Mutex
+	user          User
+	codes         map[string]authCode
+	refreshTokens map[string]User
+	tokenRequests int
+}
+
+type authCode struct {
+	user          User
+	redirectURI   string
+	codeChallenge string
+}
+
+// NewServer starts a Server that is closed when the test finishes.
+func NewServer(t testing.TB, opts ...Option) *Server {
+	t.Helper()
+
+	options := options{
+		appID:     DefaultAppID,
+		appSecret: DefaultAppSecret,
+		user:      DefaultUser,
+		tokenTTL:  defaultTokenTTL,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.ES384, Key: key},
+		(&jose.SignerOptions{}).WithType("JWT").WithHeader(jose.HeaderKey("kid"), keyID),
+	)
+	require.NoError(t, err)
+
+	s := &Server{
+		opts:          options,
+		key:           key,
+		signer:        signer,
+		user:          options.user,
+		codes:         map[string]authCode{},
+		refreshTokens: map[string]User{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /oidc/.well-known/openid-configuration", s.handleDiscovery)
+	mux.HandleFunc("GET /oidc/jwks", s.handleJWKS)
+	mux.HandleFunc("GET /oidc/auth", s.handleAuth)
+	mux.HandleFunc("POST /oidc/token", s.handleToken)
+	mux.HandleFunc("GET /oidc/me", s.handleUserInfo)
+	mux.HandleFunc("POST /oidc/token/revocation", s.handleRevocation)
+	mux.HandleFunc("GET /oidc/session/end", s.handleEndSession)
+
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Issuer returns the issuer of tokens signed by the server.
+func (s *Server) Issuer() string {
+	return s.URL + "/oidc"
+}
+
+// Config returns a Logto client config for the registered application.
+func (s *Server) Config(resources ...string) *client.LogtoConfig {
+	return &client.LogtoConfig{
+		Endpoint:  s.URL,
+		AppId:     s.opts.appID,
+		AppSecret: s.opts.appSecret,
+		Resources: resources,
+	}
+}
+
+// SignInAs changes the user signed in by subsequent authorization requests.
+func (s *Server) SignInAs(user User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.user = user
+}
+
+// TokenRequests returns the number of successful token endpoint requests.
+func (s *Server) TokenRequests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tokenRequests
+}
+
+// SignIn performs the sign-in redirect flow for signInURI, as a browser would,
+// and returns the callback URI the client should handle.
+func (s *Server) SignIn(t testing.TB, signInURI string) string {
+	t.Helper()
+	httpClient := s.Client()
+	httpClient.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	// The Logto client leaves the query of sign-in URIs unescaped, which
+	// browsers tolerate by escaping spaces themselves.
+	res, err := httpClient.Get(strings.ReplaceAll(signInURI, " ", "%20"))
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusFound, res.StatusCode)
+	return res.Header.Get("Location")
+}
+
+func (s *Server) handleDiscovery(w http.ResponseWriter, _ *http.Request) {
+	issuer := s.Issuer()
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                 issuer,
+		"authorization_endpoint": issuer + "/auth",
+		"token_endpoint":         issuer + "/token",
+		"userinfo_endpoint":      issuer + "/me",
+		"end_session_endpoint":   issuer + "/session/end",
+		"revocation_endpoint":    issuer + "/token/revocation",
+		"jwks_uri":               issuer + "/jwks",
+	})
+}
+
+func (s *Server) handleJWKS(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{
+		Key:       &s.key.PublicKey,
+		KeyID:     keyID,
+		Algorithm: string(jose.ES384),
+		Use:       "sig",
+	}}})
+}
+
+func (s *Server) handleAuth(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	redirectURI := q.Get("redirect_uri")
+	if q.Get("client_id") != s.opts.appID || redirectURI == "" {
+		writeError(w, http.StatusBadRequest, "invalid_client", "unknown client or missing redirect_uri")
+		return
+	}
+	if q.Get("response_type") != "code" || q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "authorization code flow with S256 PKCE is required")
+		return
+	}
+
+	code := randomString()
+	s.mu.Lock()
+	s.codes[code] = authCode{
+		user:          s.user,
+		redirectURI:   redirectURI,
+		codeChallenge: q.Get("code_challenge"),
+	}
+	s.mu.Unlock()
+
+	callback, err := url.Parse(redirectURI)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "invalid redirect_uri")
+		return
+	}
+	cq := callback.Query()
+	cq.Set("code", code)
+	cq.Set("state", q.Get("state"))
+	callback.RawQuery = cq.Encode()
+	http.Redirect(w, r, callback.String(), http.StatusFound)
+}
+
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	if !s.authenticateClient(r) {
+		writeError(w, http.StatusUnauthorized, "invalid_client", "invalid client credentials")
+		return
+	}
+
+	resource := r.PostForm.Get("resource")
+	var user User
+	var includeRefresh bool
+
+	switch r.PostForm.Get("grant_type") {
+	case "authorization_code":
+		s.mu.Lock()
+		code, ok := s.codes[r.PostForm.Get("code")]
+		delete(s.codes, r.PostForm.Get("code"))
+		s.mu.Unlock()
+		if !ok || code.redirectURI != r.PostForm.Get("redirect_uri") || !verifyPKCE(code.codeChallenge, r.PostForm.Get("code_verifier")) {
+			writeError(w, http.StatusBadRequest, "invalid_grant", "invalid authorization code")
+			return
+		}
+		user = code.user
+		includeRefresh = true
+	case "refresh_token":
+		s.mu.Lock()
+		u, ok := s.refreshTokens[r.PostForm.Get("refresh_token")]
+		s.mu.Unlock()
+		if !ok {
+			writeError(w, http.StatusBadRequest, "invalid_grant", "invalid refresh token")
+			return
+		}
+		user = u
+		includeRefresh = true
+	case "client_credentials":
+		user = User{ID: s.opts.appID}
+	default:
+		writeError(w, http.StatusBadRequest, "unsupported_grant_type", "unsupported grant type")
+		return
+	}
+
+	res, err := s.tokenResponse(user, resource, includeRefresh)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+	if includeRefresh {
+		// Refresh tokens are not rotated, so previously issued tokens remain valid.
+		s.mu.Lock()
+		s.refreshTokens[res["refresh_token"].(string)] = user
+		s.mu.Unlock()
+	}
+	s.mu.Lock()
+	s.tokenRequests++
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (s *Server) tokenResponse(user User, resource string, includeRefresh bool) (map[string]any, error) {
+	now := time.Now()
+	aud := resource
+	if aud == "" {
+		aud = s.opts.appID
+	}
+	accessToken, err := s.sign(map[string]any{
+		"iss":       s.Issuer(),
+		"sub":       user.ID,
+		"aud":       aud,
+		"client_id": s.opts.appID,
+		"iat":       now.Unix(),
+		"exp":       now.Add(s.opts.tokenTTL).Unix(),
+		"jti":       randomString(),
+		"scope":     "openid offline_access profile",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := map[string]any{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   int(s.opts.tokenTTL.Seconds()),
+		"scope":        "openid offline_access profile",
+	}
+	if !includeRefresh {
+		return res, nil
+	}
+
+	idToken, err := s.sign(map[string]any{
+		"iss":           s.Issuer(),
+		"sub":           user.ID,
+		"aud":           s.opts.appID,
+		"iat":           now.Unix(),
+		"exp":           now.Add(s.opts.tokenTTL).Unix(),
+		"name":          user.Name,
+		"username":      user.Username,
+		"email":         user.Email,
+		"roles":         user.Roles,
+		"organizations": user.Organizations,
+	})
+	if err != nil {
+		return nil, err
+	}
+	res["id_token"] = idToken
+	res["refresh_token"] = randomString()
+	return res, nil
+}
+
+func (s *Server) handleUserInfo(w http.ResponseWriter, r *http.Request) {
+	claims, err := s.verify(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, "invalid_token", err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	var user User
+	for _, u := range s.refreshTokens {
+		if u.ID == claims.Subject {
+			user = u
+			break
+		}
+	}
+	s.mu.Unlock()
+	if user.ID == "" {
+		writeError(w, http.StatusUnauthorized, "invalid_token", "unknown subject")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"sub":           user.ID,
+		"name":          user.Name,
+		"username":      user.Username,
+		"email":         user.Email,
+		"roles":         user.Roles,
+		"organizations": user.Organizations,
+	})
+}
+
+func (s *Server) handleRevocation(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	s.mu.Lock()
+	delete(s.refreshTokens, r.PostForm.Get("token"))
+	s.mu.Unlock()
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) handleEndSession(w http.ResponseWriter, r *http.Request) {
+	redirectURI := r.URL.Query().Get("post_logout_redirect_uri")
+	if redirectURI == "" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	http.Redirect(w, r, redirectURI, http.StatusFound)
+}
+
+func (s *Server) authenticateClient(r *http.Request) bool {
+	if id, secret, ok := r.BasicAuth(); ok {
+		return id == s.opts.appID && secret == s.opts.appSecret
+	}
+	// Public clients authenticate with the client_id only.
+	return s.opts.appSecret == "" && r.PostForm.Get("client_id") == s.opts.appID
+}
+
+func (s *Server) sign(claims map[string]any) (string, error) {
+	return jwt.Signed(s.signer).Claims(claims).Serialize()
+}
+
+func (s *Server) verify(token string) (jwt.Claims, error) {
+	var claims jwt.Claims
+	parsed, err := jwt.ParseSigned(token, []jose.SignatureAlgorithm{jose.ES384})
+	if err != nil {
+		return claims, err
+	}
+	if err = parsed.Claims(&s.key.PublicKey, &claims); err != nil {
+		return claims, err
+	}
+	return claims, claims.ValidateWithLeeway(jwt.Expected{Issuer: s.Issuer(), Time: time.Now()}, 0)
+}
+
+func verifyPKCE(challenge string, verifier string) bool {
+	sum := sha256.Sum256([]byte(verifier))
+	return verifier != "" && base64.RawURLEncoding.EncodeToString(sum[:]) == challenge
+}
+
+func randomString() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, code string, description string) {
+	writeJSON(w, status, map[string]string{"error": code, "error_description": description})
+}
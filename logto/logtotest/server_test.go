@@ -0,0 +1,90 @@
+package logtotest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/logto-io/go/v2/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const redirectURI = "http://app.test/callback"
+
+func TestServer_signInFlow(t *testing.T) {
+	const resource = "https://api.test"
+	srv := NewServer(t, WithUser(User{ID: "u1", Username: "alice", Email: "alice@example.com", Roles: []string{"admin"}}))
+
+	storage := mapStorage{}
+	lc := client.NewLogtoClient(srv.Config(resource), storage)
+
+	signInURI, err := lc.SignIn(&client.SignInOptions{RedirectUri: redirectURI})
+	require.NoError(t, err)
+	callbackURI := srv.SignIn(t, signInURI)
+	require.NoError(t, lc.HandleSignInCallback(callbackRequest(callbackURI)))
+	require.True(t, lc.IsAuthenticated())
+
+	claims, err := lc.GetIdTokenClaims()
+	require.NoError(t, err)
+	assert.Equal(t, "u1", claims.Sub)
+	assert.Equal(t, "alice", claims.Username)
+	assert.Equal(t, []string{"admin"}, claims.Roles)
+
+	tkn, err := lc.GetAccessToken(resource)
+	require.NoError(t, err)
+	assert.NotEmpty(t, tkn.Token)
+	assert.Equal(t, 2, srv.TokenRequests())
+
+	info, err := lc.FetchUserInfo()
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", info.Email)
+
+	signOutURI, err := lc.SignOut("http://app.test/")
+	require.NoError(t, err)
+	assert.Contains(t, signOutURI, srv.Issuer()+"/session/end")
+	assert.False(t, lc.IsAuthenticated())
+}
+
+func TestServer_signInAs(t *testing.T) {
+	srv := NewServer(t)
+	srv.SignInAs(User{ID: "u2"})
+
+	lc := client.NewLogtoClient(srv.Config(), mapStorage{})
+	signInURI, err := lc.SignIn(&client.SignInOptions{RedirectUri: redirectURI})
+	require.NoError(t, err)
+	require.NoError(t, lc.HandleSignInCallback(callbackRequest(srv.SignIn(t, signInURI))))
+
+	claims, err := lc.GetIdTokenClaims()
+	require.NoError(t, err)
+	assert.Equal(t, "u2", claims.Sub)
+}
+
+func TestServer_invalidClient(t *testing.T) {
+	srv := NewServer(t)
+	cfg := srv.Config()
+	cfg.AppSecret = "wrong"
+
+	lc := client.NewLogtoClient(cfg, mapStorage{})
+	signInURI, err := lc.SignIn(&client.SignInOptions{RedirectUri: redirectURI})
+	require.NoError(t, err)
+	err = lc.HandleSignInCallback(callbackRequest(srv.SignIn(t, signInURI)))
+	assert.ErrorContains(t, err, "invalid_client")
+}
+
+// callbackRequest returns the request a server receives for a callback URI.
+func callbackRequest(uri string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, uri, nil)
+	r.RequestURI = r.URL.RequestURI()
+	return r
+}
+
+type mapStorage map[string]string
+
+func (s mapStorage) GetItem(key string) string {
+	return s[key]
+}
+
+func (s mapStorage) SetItem(key string, value string) {
+	s[key] = value
+}
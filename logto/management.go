@@ -0,0 +1,375 @@
+package logto
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joshjon/kit/clock"
+	"github.com/joshjon/kit/errtag"
+)
+
+const (
+	// DefaultManagementResource is the management API resource of self-hosted
+	// Logto instances. Logto Cloud uses https://<tenant-id>.logto.app/api.
+	DefaultManagementResource = "https://default.logto.app/api"
+
+	managementTimeout  = 10 * time.Second
+	tokenExpiryLeeway  = 30 * time.Second
+	applicationTypeM2M = "MachineToMachine"
+)
+
+// ManagementOption optionally configures a Management client.
+type ManagementOption func(opts *managementOptions)
+
+// WithManagementResource sets the management API resource indicator. Defaults
+// to DefaultManagementResource.
+func WithManagementResource(resource string) ManagementOption {
+	return func(opts *managementOptions) {
+		opts.resource = resource
+	}
+}
+
+// WithManagementHTTPClient sets the HTTP client used for all requests.
+func WithManagementHTTPClient(client *http.Client) ManagementOption {
+	return func(opts *managementOptions) {
+		opts.httpClient = client
+	}
+}
+
+// WithManagementClock sets the clock used to check access token expiry.
+// Defaults to clock.Real.
+func WithManagementClock(clk clock.Clock) ManagementOption {
+	return func(opts *managementOptions) {
+		opts.clock = clk
+	}
+}
+
+type managementOptions struct {
+	resource   string
+	httpClient *http.Client
+	clock      clock.Clock
+}
+
+// Management is a client for the Logto management API, authenticated with
+// the client credentials of a machine-to-machine application that has the
+// management API role.
+type Management struct {
+	endpoint  string
+	appID     string
+	appSecret string
+	opts      managementOptions
+
+	mu          sync.Mutex
+	token       string
+	tokenExpiry time.Time
+}
+
+// NewManagement creates a new Management client for the Logto instance at
+// endpoint.
+func NewManagement(endpoint string, appID string, appSecret string, opts ...ManagementOption) *Management {
+	options := managementOptions{
+		resource:   DefaultManagementResource,
+		httpClient: &http.Client{Timeout: managementTimeout},
+		clock:      clock.Real,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &Management{
+		endpoint:  strings.TrimSuffix(endpoint, "/"),
+		appID:     appID,
+		appSecret: appSecret,
+		opts:      options,
+	}
+}
+
+// User is a Logto user.
+type User struct {
+	ID           string         `json:"id"`
+	Username     string         `json:"username,omitempty"`
+	PrimaryEmail string         `json:"primaryEmail,omitempty"`
+	PrimaryPhone string         `json:"primaryPhone,omitempty"`
+	Name         string         `json:"name,omitempty"`
+	Avatar       string         `json:"avatar,omitempty"`
+	CustomData   map[string]any `json:"customData,omitempty"`
+	IsSuspended  bool           `json:"isSuspended,omitempty"`
+	CreatedAt    int64          `json:"createdAt,omitempty"`    // unix milliseconds
+	LastSignInAt int64          `json:"lastSignInAt,omitempty"` // unix milliseconds
+}
+
+// CreateUserRequest holds the fields of a new user. At least one identifier
+// (username, email or phone) is required.
+type CreateUserRequest struct {
+	Username     string         `json:"username,omitempty"`
+	PrimaryEmail string         `json:"primaryEmail,omitempty"`
+	PrimaryPhone string         `json:"primaryPhone,omitempty"`
+	Password     string         `json:"password,omitempty"`
+	Name         string         `json:"name,omitempty"`
+	CustomData   map[string]any `json:"customData,omitempty"`
+}
+
+// Organization is a Logto organization.
+type Organization struct {
+	ID          string         `json:"id"`
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	CustomData  map[string]any `json:"customData,omitempty"`
+	CreatedAt   int64          `json:"createdAt,omitempty"` // unix milliseconds
+}
+
+// CreateOrganizationRequest holds the fields of a new organization.
+type CreateOrganizationRequest struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	CustomData  map[string]any `json:"customData,omitempty"`
+}
+
+// Application is a Logto application.
+type Application struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Type        string `json:"type"`
+	Secret      string `json:"secret,omitempty"`
+	CreatedAt   int64  `json:"createdAt,omitempty"` // unix milliseconds
+}
+
+// CreateUser creates a user.
+func (m *Management) CreateUser(ctx context.Context, req CreateUserRequest) (User, error) {
+	var user User
+	err := m.do(ctx, http.MethodPost, "/api/users", nil, req, &user)
+	return user, err
+}
+
+// GetUser returns a user by ID.
+func (m *Management) GetUser(ctx context.Context, userID string) (User, error) {
+	var user User
+	err := m.do(ctx, http.MethodGet, "/api/users/"+url.PathEscape(userID), nil, nil, &user)
+	return user, err
+}
+
+// SearchUsers returns the users whose identifiers or name contain search.
+// Page numbers start at 1.
+func (m *Management) SearchUsers(ctx context.Context, search string, page int, pageSize int) ([]User, error) {
+	query := url.Values{
+		"search":    {"%" + search + "%"},
+		"page":      {strconv.Itoa(page)},
+		"page_size": {strconv.Itoa(pageSize)},
+	}
+	var users []User
+	err := m.do(ctx, http.MethodGet, "/api/users", query, nil, &users)
+	return users, err
+}
+
+// DeleteUser deletes a user.
+func (m *Management) DeleteUser(ctx context.Context, userID string) error {
+	return m.do(ctx, http.MethodDelete, "/api/users/"+url.PathEscape(userID), nil, nil, nil)
+}
+
+// AssignUserRoles assigns API roles to a user.
+func (m *Management) AssignUserRoles(ctx context.Context, userID string, roleIDs ...string) error {
+	body := map[string][]string{"roleIds": roleIDs}
+	return m.do(ctx, http.MethodPost, "/api/users/"+url.PathEscape(userID)+"/roles", nil, body, nil)
+}
+
+// CreateOrganization creates an organization.
+func (m *Management) CreateOrganization(ctx context.Context, req CreateOrganizationRequest) (Organization, error) {
+	var org Organization
+	err := m.do(ctx, http.MethodPost, "/api/organizations", nil, req, &org)
+	return org, err
+}
+
+// GetOrganization returns an organization by ID.
+func (m *Management) GetOrganization(ctx context.Context, orgID string) (Organization, error) {
+	var org Organization
+	err := m.do(ctx, http.MethodGet, "/api/organizations/"+url.PathEscape(orgID), nil, nil, &org)
+	return org, err
+}
+
+// DeleteOrganization deletes an organization.
+func (m *Management) DeleteOrganization(ctx context.Context, orgID string) error {
+	return m.do(ctx, http.MethodDelete, "/api/organizations/"+url.PathEscape(orgID), nil, nil, nil)
+}
+
+// AddOrganizationUsers adds users as members of an organization.
+func (m *Management) AddOrganizationUsers(ctx context.Context, orgID string, userIDs ...string) error {
+	body := map[string][]string{"userIds": userIDs}
+	return m.do(ctx, http.MethodPost, "/api/organizations/"+url.PathEscape(orgID)+"/users", nil, body, nil)
+}
+
+// AssignOrganizationRoles assigns organization roles to a member of an
+// organization.
+func (m *Management) AssignOrganizationRoles(ctx context.Context, orgID string, userID string, roleIDs ...string) error {
+	body := map[string][]string{"organizationRoleIds": roleIDs}
+	path := "/api/organizations/" + url.PathEscape(orgID) + "/users/" + url.PathEscape(userID) + "/roles"
+	return m.do(ctx, http.MethodPost, path, nil, body, nil)
+}
+
+// CreateM2MApplication creates a machine-to-machine application. The returned
+// application includes its secret.
+func (m *Management) CreateM2MApplication(ctx context.Context, name string, description string) (Application, error) {
+	body := map[string]string{
+		"name":        name,
+		"description": description,
+		"type":        applicationTypeM2M,
+	}
+	var app Application
+	err := m.do(ctx, http.MethodPost, "/api/applications", nil, body, &app)
+	return app, err
+}
+
+// DeleteApplication deletes an application.
+func (m *Management) DeleteApplication(ctx context.Context, appID string) error {
+	return m.do(ctx, http.MethodDelete, "/api/applications/"+url.PathEscape(appID), nil, nil, nil)
+}
+
+// AssignApplicationRoles assigns machine-to-machine roles to an application.
+func (m *Management) AssignApplicationRoles(ctx context.Context, appID string, roleIDs ...string) error {
+	body := map[string][]string{"roleIds": roleIDs}
+	return m.do(ctx, http.MethodPost, "/api/applications/"+url.PathEscape(appID)+"/roles", nil, body, nil)
+}
+
+// apiError is the error response body of the management API.
+type apiError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (m *Management) do(ctx context.Context, method string, path string, query url.Values, body any, out any) error {
+	token, err := m.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("marshal request: %w", err)
+		}
+		reqBody = bytes.NewReader(b)
+	}
+
+	u := m.endpoint + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, reqBody)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := m.opts.httpClient.Do(req)
+	if err != nil {
+		return errtag.Tag[errtag.BadGateway](fmt.Errorf("logto management %s %s: %w", method, path, err))
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusBadRequest {
+		return statusError(res, fmt.Sprintf("logto management %s %s", method, path))
+	}
+	if out == nil || res.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err = json.NewDecoder(res.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
+func (m *Management) accessToken(ctx context.Context) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.token != "" && m.opts.clock.Now().Before(m.tokenExpiry) {
+		return m.token, nil
+	}
+
+	form := url.Values{
+		"grant_type": {"client_credentials"},
+		"resource":   {m.opts.resource},
+		"scope":      {"all"},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.endpoint+"/oidc/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("create token request: %w", err)
+	}
+	req.SetBasicAuth(m.appID, m.appSecret)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := m.opts.httpClient.Do(req)
+	if err != nil {
+		return "", errtag.Tag[errtag.BadGateway](fmt.Errorf("logto management token: %w", err))
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusBadRequest {
+		return "", statusError(res, "logto management token")
+	}
+
+	var tokenRes struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err = json.NewDecoder(res.Body).Decode(&tokenRes); err != nil {
+		return "", fmt.Errorf("decode token response: %w", err)
+	}
+	if tokenRes.AccessToken == "" {
+		return "", errors.New("logto management token: empty access token")
+	}
+
+	m.token = tokenRes.AccessToken
+	m.tokenExpiry = m.opts.clock.Now().Add(time.Duration(tokenRes.ExpiresIn)*time.Second - tokenExpiryLeeway)
+	return m.token, nil
+}
+
+// statusError tags a management API error response based on its status code,
+// using the Logto error code (e.g. user.username_already_in_use) as the
+// error code.
+func statusError(res *http.Response, op string) error {
+	var apiErr apiError
+	b, _ := io.ReadAll(io.LimitReader(res.Body, 64<<10))
+	_ = json.Unmarshal(b, &apiErr)
+
+	msg := apiErr.Message
+	if msg == "" {
+		msg = strings.TrimSpace(string(b))
+	}
+	err := fmt.Errorf("%s: status %d: %s", op, res.StatusCode, msg)
+
+	var opts []errtag.Option
+	if apiErr.Code != "" {
+		opts = append(opts, errtag.WithCode(apiErr.Code))
+	}
+
+	switch res.StatusCode {
+	case http.StatusBadRequest:
+		return errtag.Tag[errtag.InvalidArgument](err, opts...)
+	case http.StatusUnauthorized:
+		return errtag.Tag[errtag.Unauthorized](err, opts...)
+	case http.StatusForbidden:
+		return errtag.Tag[errtag.Forbidden](err, opts...)
+	case http.StatusNotFound:
+		return errtag.Tag[errtag.NotFound](err, opts...)
+	case http.StatusConflict, http.StatusUnprocessableEntity:
+		return errtag.Tag[errtag.Conflict](err, opts...)
+	case http.StatusTooManyRequests:
+		return errtag.Tag[errtag.TooManyRequests](err, opts...)
+	default:
+		return errtag.Tag[errtag.BadGateway](err, opts...)
+	}
+}
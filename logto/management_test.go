@@ -0,0 +1,107 @@
+package logto
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/errtag"
+)
+
+func TestManagement(t *testing.T) {
+	var tokenRequests atomic.Int32
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /oidc/token", func(w http.ResponseWriter, r *http.Request) {
+		tokenRequests.Add(1)
+		id, secret, ok := r.BasicAuth()
+		require.True(t, ok)
+		assert.Equal(t, "app-id", id)
+		assert.Equal(t, "app-secret", secret)
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+		assert.Equal(t, DefaultManagementResource, r.PostForm.Get("resource"))
+		writeJSON(w, http.StatusOK, map[string]any{"access_token": "mgmt-token", "expires_in": 3600})
+	})
+	mux.HandleFunc("POST /api/users", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer mgmt-token", r.Header.Get("Authorization"))
+		var req CreateUserRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		if req.Username == "taken" {
+			writeJSON(w, http.StatusUnprocessableEntity, apiError{Code: "user.username_already_in_use", Message: "already in use"})
+			return
+		}
+		writeJSON(w, http.StatusOK, User{ID: "u1", Username: req.Username, PrimaryEmail: req.PrimaryEmail})
+	})
+	mux.HandleFunc("GET /api/users", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "%jane%", r.URL.Query().Get("search"))
+		writeJSON(w, http.StatusOK, []User{{ID: "u1", Username: "jane"}})
+	})
+	mux.HandleFunc("GET /api/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusNotFound, apiError{Code: "entity.not_found", Message: "not found"})
+	})
+	mux.HandleFunc("POST /api/users/{id}/roles", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string][]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, []string{"r1", "r2"}, body["roleIds"])
+		w.WriteHeader(http.StatusCreated)
+	})
+	mux.HandleFunc("POST /api/organizations", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, Organization{ID: "o1", Name: "Acme"})
+	})
+	mux.HandleFunc("POST /api/organizations/{id}/users", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+	mux.HandleFunc("POST /api/applications", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, applicationTypeM2M, body["type"])
+		writeJSON(w, http.StatusOK, Application{ID: "a1", Name: body["name"], Type: body["type"], Secret: "s"})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	ctx := context.Background()
+	mgmt := NewManagement(srv.URL, "app-id", "app-secret")
+
+	user, err := mgmt.CreateUser(ctx, CreateUserRequest{Username: "jane", PrimaryEmail: "jane@example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, User{ID: "u1", Username: "jane", PrimaryEmail: "jane@example.com"}, user)
+
+	_, err = mgmt.CreateUser(ctx, CreateUserRequest{Username: "taken"})
+	tag, ok := errtag.AsTag[errtag.Conflict](err)
+	require.True(t, ok)
+	assert.Equal(t, "user.username_already_in_use", tag.ErrorCode())
+
+	users, err := mgmt.SearchUsers(ctx, "jane", 1, 20)
+	require.NoError(t, err)
+	assert.Len(t, users, 1)
+
+	_, err = mgmt.GetUser(ctx, "missing")
+	assert.True(t, errtag.HasTag[errtag.NotFound](err))
+
+	require.NoError(t, mgmt.AssignUserRoles(ctx, "u1", "r1", "r2"))
+
+	org, err := mgmt.CreateOrganization(ctx, CreateOrganizationRequest{Name: "Acme"})
+	require.NoError(t, err)
+	require.NoError(t, mgmt.AddOrganizationUsers(ctx, org.ID, "u1"))
+
+	app, err := mgmt.CreateM2MApplication(ctx, "worker", "")
+	require.NoError(t, err)
+	assert.Equal(t, "s", app.Secret)
+
+	// token is cached across requests
+	assert.Equal(t, int32(1), tokenRequests.Load())
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
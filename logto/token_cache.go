@@ -0,0 +1,191 @@
+package logto
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/logto-io/go/v2/client"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/joshjon/kit/auth"
+	"github.com/joshjon/kit/clock"
+	"github.com/joshjon/kit/log"
+)
+
+const defaultRefreshLeeway = 30 * time.Second
+
+// TokenCacheOption optionally configures a TokenCache.
+type TokenCacheOption func(opts *tokenCacheOptions)
+
+// WithRefreshLeeway sets how long before expiry access tokens are refreshed,
+// so tokens don't expire while a request is in flight. Defaults to 30s.
+func WithRefreshLeeway(leeway time.Duration) TokenCacheOption {
+	return func(opts *tokenCacheOptions) {
+		opts.leeway = leeway
+	}
+}
+
+// WithTokenCacheLogger sets a Logger used to log cache hits and token fetches
+// at debug level.
+func WithTokenCacheLogger(logger log.Logger) TokenCacheOption {
+	return func(opts *tokenCacheOptions) {
+		opts.logger = logger
+	}
+}
+
+// WithTokenCacheClock sets the clock used to check token expiry. Defaults to
+// clock.Real.
+func WithTokenCacheClock(clk clock.Clock) TokenCacheOption {
+	return func(opts *tokenCacheOptions) {
+		opts.clock = clk
+	}
+}
+
+type tokenCacheOptions struct {
+	leeway time.Duration
+	logger log.Logger
+	clock  clock.Clock
+}
+
+// TokenCache caches access tokens per session and resource across requests.
+// Tokens are refreshed slightly before they expire, and concurrent requests
+// of the same session share a single refresh, which avoids failed refreshes
+// due to refresh token rotation. A TokenCache is safe for concurrent use and
+// should be shared by all Clients.
+type TokenCache struct {
+	opts   tokenCacheOptions
+	mu     sync.Mutex
+	tokens map[string]auth.AccessToken
+	group  singleflight.Group
+}
+
+// NewTokenCache creates a new TokenCache.
+func NewTokenCache(opts ...TokenCacheOption) *TokenCache {
+	options := tokenCacheOptions{
+		leeway: defaultRefreshLeeway,
+		logger: log.NewLogger(log.WithNop()),
+		clock:  clock.Real,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &TokenCache{
+		opts:   options,
+		tokens: map[string]auth.AccessToken{},
+	}
+}
+
+// getAccessToken returns the cached token of a session for a resource, or
+// fetches it with a single in-flight fetch per session and resource.
+func (c *TokenCache) getAccessToken(refreshToken string, resource string, fetch func() (auth.AccessToken, error)) (auth.AccessToken, error) {
+	key := cacheKey(refreshToken, resource)
+	if tkn, ok := c.get(key); ok {
+		c.opts.logger.Debug("access token cache hit", "resource", resource)
+		return tkn, nil
+	}
+
+	v, err, shared := c.group.Do(key, func() (any, error) {
+		tkn, err := fetch()
+		if err != nil {
+			return auth.AccessToken{}, err
+		}
+		c.set(key, tkn)
+		return tkn, nil
+	})
+	if err != nil {
+		return auth.AccessToken{}, err
+	}
+	c.opts.logger.Debug("access token fetched", "resource", resource, "shared", shared)
+	return v.(auth.AccessToken), nil
+}
+
+func (c *TokenCache) get(key string) (auth.AccessToken, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	tkn, ok := c.tokens[key]
+	if !ok || !c.valid(tkn, c.opts.clock.Now()) {
+		return auth.AccessToken{}, false
+	}
+	return tkn, true
+}
+
+func (c *TokenCache) set(key string, tkn auth.AccessToken) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.opts.clock.Now()
+	for k, t := range c.tokens {
+		if !c.valid(t, now) {
+			delete(c.tokens, k)
+		}
+	}
+	c.tokens[key] = tkn
+}
+
+func (c *TokenCache) valid(tkn auth.AccessToken, now time.Time) bool {
+	return time.Unix(tkn.ExpiresAt, 0).Add(-c.opts.leeway).After(now)
+}
+
+// cacheKey identifies a session by a hash of its refresh token, so raw
+// tokens are not kept as map keys.
+func cacheKey(refreshToken string, resource string) string {
+	sum := sha256.Sum256([]byte(refreshToken))
+	return hex.EncodeToString(sum[:]) + "@" + resource
+}
+
+// earlyExpiryStorage shifts the expiry of access tokens read by the Logto
+// client earlier by leeway, so the client refreshes tokens before they
+// actually expire. The actual expiry of previously read tokens is restored
+// when the client stores its token map, while newly fetched tokens are stored
+// as is.
+type earlyExpiryStorage struct {
+	client.Storage
+	leeway  int64
+	expires map[string]int64 // actual expiry by token
+}
+
+func (s *earlyExpiryStorage) GetItem(key string) string {
+	val := s.Storage.GetItem(key)
+	if key != client.StorageKeyAccessTokenMap || val == "" {
+		return val
+	}
+	var tokens map[string]client.AccessToken
+	if err := json.Unmarshal([]byte(val), &tokens); err != nil {
+		return val
+	}
+	if s.expires == nil {
+		s.expires = map[string]int64{}
+	}
+	for k, tkn := range tokens {
+		s.expires[tkn.Token] = tkn.ExpiresAt
+		tkn.ExpiresAt -= s.leeway
+		tokens[k] = tkn
+	}
+	return marshalTokens(tokens, val)
+}
+
+func (s *earlyExpiryStorage) SetItem(key string, value string) {
+	if key == client.StorageKeyAccessTokenMap && value != "" {
+		var tokens map[string]client.AccessToken
+		if err := json.Unmarshal([]byte(value), &tokens); err == nil {
+			for k, tkn := range tokens {
+				if expiresAt, ok := s.expires[tkn.Token]; ok {
+					tkn.ExpiresAt = expiresAt
+					tokens[k] = tkn
+				}
+			}
+			value = marshalTokens(tokens, value)
+		}
+	}
+	s.Storage.SetItem(key, value)
+}
+
+func marshalTokens(tokens map[string]client.AccessToken, fallback string) string {
+	b, err := json.Marshal(tokens)
+	if err != nil {
+		return fallback
+	}
+	return string(b)
+}
@@ -0,0 +1,129 @@
+package logto
+
+import (
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/logto-io/go/v2/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/auth"
+	"github.com/joshjon/kit/testutil"
+)
+
+func TestTokenCache(t *testing.T) {
+	cache := NewTokenCache(WithRefreshLeeway(time.Minute))
+
+	var fetches atomic.Int32
+	release := make(chan struct{})
+	fetch := func() (auth.AccessToken, error) {
+		fetches.Add(1)
+		<-release
+		return auth.AccessToken{Token: "tkn", ExpiresAt: time.Now().Add(time.Hour).Unix()}, nil
+	}
+
+	// concurrent fetches for the same session and resource are shared
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tkn, err := cache.getAccessToken("refresh", "https://api", fetch)
+			assert.NoError(t, err)
+			assert.Equal(t, "tkn", tkn.Token)
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), fetches.Load())
+
+	// cached
+	_, err := cache.getAccessToken("refresh", "https://api", fetch)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), fetches.Load())
+
+	// keyed per resource and session
+	_, err = cache.getAccessToken("refresh", "https://other", fetch)
+	require.NoError(t, err)
+	_, err = cache.getAccessToken("other-refresh", "https://api", fetch)
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), fetches.Load())
+}
+
+func TestTokenCache_refreshesEarly(t *testing.T) {
+	cache := NewTokenCache(WithRefreshLeeway(time.Minute))
+
+	var fetches atomic.Int32
+	fetch := func() (auth.AccessToken, error) {
+		fetches.Add(1)
+		// expires within the leeway
+		return auth.AccessToken{Token: "tkn", ExpiresAt: time.Now().Add(30 * time.Second).Unix()}, nil
+	}
+
+	for range 2 {
+		_, err := cache.getAccessToken("refresh", "https://api", fetch)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(2), fetches.Load())
+}
+
+func TestTokenCache_expires(t *testing.T) {
+	clk := testutil.NewFakeClock(time.Now())
+	cache := NewTokenCache(WithRefreshLeeway(time.Minute), WithTokenCacheClock(clk))
+
+	var fetches atomic.Int32
+	fetch := func() (auth.AccessToken, error) {
+		fetches.Add(1)
+		return auth.AccessToken{Token: "tkn", ExpiresAt: clk.Now().Add(time.Hour).Unix()}, nil
+	}
+
+	_, err := cache.getAccessToken("refresh", "https://api", fetch)
+	require.NoError(t, err)
+	clk.Advance(58 * time.Minute)
+	_, err = cache.getAccessToken("refresh", "https://api", fetch)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), fetches.Load())
+
+	// within the leeway of expiry
+	clk.Advance(time.Minute)
+	_, err = cache.getAccessToken("refresh", "https://api", fetch)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), fetches.Load())
+}
+
+func TestEarlyExpiryStorage(t *testing.T) {
+	tokens, err := json.Marshal(map[string]client.AccessToken{"@https://api": {Token: "old", ExpiresAt: 1000}})
+	require.NoError(t, err)
+	backing := mapStorage{client.StorageKeyAccessTokenMap: string(tokens)}
+	storage := &earlyExpiryStorage{Storage: backing, leeway: 60}
+
+	// read tokens expire early
+	var got map[string]client.AccessToken
+	require.NoError(t, json.Unmarshal([]byte(storage.GetItem(client.StorageKeyAccessTokenMap)), &got))
+	assert.Equal(t, int64(940), got["@https://api"].ExpiresAt)
+
+	// previously read tokens are stored with their actual expiry and new
+	// tokens as is
+	got["@https://other"] = client.AccessToken{Token: "new", ExpiresAt: 2000}
+	tokens, err = json.Marshal(got)
+	require.NoError(t, err)
+	storage.SetItem(client.StorageKeyAccessTokenMap, string(tokens))
+
+	var stored map[string]client.AccessToken
+	require.NoError(t, json.Unmarshal([]byte(backing[client.StorageKeyAccessTokenMap]), &stored))
+	assert.Equal(t, int64(1000), stored["@https://api"].ExpiresAt)
+	assert.Equal(t, int64(2000), stored["@https://other"].ExpiresAt)
+
+	storage.SetItem(client.StorageKeyRefreshToken, "refresh")
+	assert.Equal(t, "refresh", storage.GetItem(client.StorageKeyRefreshToken))
+}
+
+type mapStorage map[string]string
+
+func (s mapStorage) GetItem(key string) string        { return s[key] }
+func (s mapStorage) SetItem(key string, value string) { s[key] = value }
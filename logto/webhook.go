@@ -0,0 +1,197 @@
+package logto
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/joshjon/kit/errtag"
+)
+
+// WebhookSignatureHeader is the header containing the hex encoded
+// HMAC-SHA256 signature of a webhook request body.
+const WebhookSignatureHeader = "logto-signature-sha-256"
+
+const maxWebhookBodySize = 1 << 20 // 1 MiB
+
+// WebhookEvent is the name of a Logto webhook event.
+type WebhookEvent string
+
+const (
+	EventPostRegister               WebhookEvent = "PostRegister"
+	EventPostSignIn                 WebhookEvent = "PostSignIn"
+	EventPostResetPassword          WebhookEvent = "PostResetPassword"
+	EventUserCreated                WebhookEvent = "User.Created"
+	EventUserDeleted                WebhookEvent = "User.Deleted"
+	EventUserDataUpdated            WebhookEvent = "User.Data.Updated"
+	EventUserSuspensionUpdated      WebhookEvent = "User.SuspensionStatus.Updated"
+	EventOrganizationCreated        WebhookEvent = "Organization.Created"
+	EventOrganizationDeleted        WebhookEvent = "Organization.Deleted"
+	EventOrganizationDataUpdated    WebhookEvent = "Organization.Data.Updated"
+	EventOrganizationMembersUpdated WebhookEvent = "Organization.Membership.Updated"
+)
+
+// WebhookPayload is the body of a Logto webhook request. Interaction events
+// (PostRegister, PostSignIn, PostResetPassword) populate User, while data
+// mutation events (e.g. User.Created) populate Data with the affected entity
+// and Params with the path parameters of the management API request.
+type WebhookPayload struct {
+	HookID           string            `json:"hookId"`
+	Event            WebhookEvent      `json:"event"`
+	CreatedAt        time.Time         `json:"createdAt"`
+	InteractionEvent string            `json:"interactionEvent,omitempty"`
+	SessionID        string            `json:"sessionId,omitempty"`
+	UserAgent        string            `json:"userAgent,omitempty"`
+	UserIP           string            `json:"userIp,omitempty"`
+	UserID           string            `json:"userId,omitempty"`
+	User             *User             `json:"user,omitempty"`
+	Params           map[string]string `json:"params,omitempty"`
+	Data             json.RawMessage   `json:"data,omitempty"`
+}
+
+// DecodeData decodes the payload data into v.
+func (p WebhookPayload) DecodeData(v any) error {
+	if len(p.Data) == 0 {
+		return fmt.Errorf("logto webhook %s: no data", p.Event)
+	}
+	if err := json.Unmarshal(p.Data, v); err != nil {
+		return fmt.Errorf("logto webhook %s: decode data: %w", p.Event, err)
+	}
+	return nil
+}
+
+// WebhookFunc handles a webhook event. Returning an error responds with an
+// error status, causing Logto to retry the delivery.
+type WebhookFunc func(ctx context.Context, payload WebhookPayload) error
+
+// WebhookHandler is a server.Handler that verifies the signature of Logto
+// webhook requests and dispatches them to the callbacks registered for their
+// event. Events without callbacks are acknowledged and ignored.
+type WebhookHandler struct {
+	signingKey []byte
+	callbacks  map[WebhookEvent][]WebhookFunc
+}
+
+// NewWebhookHandler returns a WebhookHandler verifying requests with the
+// signing key of a Logto webhook.
+func NewWebhookHandler(signingKey string) *WebhookHandler {
+	return &WebhookHandler{
+		signingKey: []byte(signingKey),
+		callbacks:  map[WebhookEvent][]WebhookFunc{},
+	}
+}
+
+// On registers a callback for an event. Callbacks are invoked in the order
+// they are registered. On is not safe for use once the handler is serving
+// requests.
+func (h *WebhookHandler) On(event WebhookEvent, fn WebhookFunc) *WebhookHandler {
+	h.callbacks[event] = append(h.callbacks[event], fn)
+	return h
+}
+
+// OnUserCreated registers a callback for users created through registration
+// or the management API.
+func (h *WebhookHandler) OnUserCreated(fn func(ctx context.Context, user User) error) *WebhookHandler {
+	return h.On(EventUserCreated, userCallback(fn))
+}
+
+// OnUserUpdated registers a callback for user data and suspension status
+// updates.
+func (h *WebhookHandler) OnUserUpdated(fn func(ctx context.Context, user User) error) *WebhookHandler {
+	h.On(EventUserDataUpdated, userCallback(fn))
+	return h.On(EventUserSuspensionUpdated, userCallback(fn))
+}
+
+// OnUserDeleted registers a callback for deleted users.
+func (h *WebhookHandler) OnUserDeleted(fn func(ctx context.Context, user User) error) *WebhookHandler {
+	return h.On(EventUserDeleted, userCallback(fn))
+}
+
+// OnSignIn registers a callback for successful user sign-ins.
+func (h *WebhookHandler) OnSignIn(fn func(ctx context.Context, user User) error) *WebhookHandler {
+	return h.On(EventPostSignIn, userCallback(fn))
+}
+
+// OnRegister registers a callback for successful user registrations via the
+// sign-in experience.
+func (h *WebhookHandler) OnRegister(fn func(ctx context.Context, user User) error) *WebhookHandler {
+	return h.On(EventPostRegister, userCallback(fn))
+}
+
+func (h *WebhookHandler) Register(g *echo.Group) {
+	g.POST("", h.Handle)
+}
+
+// Handle verifies and dispatches a webhook request.
+func (h *WebhookHandler) Handle(c echo.Context) error {
+	body, err := io.ReadAll(io.LimitReader(c.Request().Body, maxWebhookBodySize+1))
+	if err != nil {
+		return errtag.Tag[errtag.InvalidArgument](fmt.Errorf("read logto webhook body: %w", err))
+	}
+	if len(body) > maxWebhookBodySize {
+		return errtag.NewTagged[errtag.InvalidArgument]("logto webhook body too large")
+	}
+	if !h.verify(body, c.Request().Header.Get(WebhookSignatureHeader)) {
+		return errtag.NewTagged[errtag.Unauthorized]("invalid logto webhook signature")
+	}
+
+	var payload WebhookPayload
+	if err = json.Unmarshal(body, &payload); err != nil {
+		return errtag.Tag[errtag.InvalidArgument](fmt.Errorf("decode logto webhook: %w", err))
+	}
+
+	ctx := c.Request().Context()
+	for _, fn := range h.callbacks[payload.Event] {
+		if err = fn(ctx, payload); err != nil {
+			return fmt.Errorf("logto webhook %s: %w", payload.Event, err)
+		}
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *WebhookHandler) verify(body []byte, signature string) bool {
+	got, err := hex.DecodeString(signature)
+	if err != nil || len(got) == 0 {
+		return false
+	}
+	return hmac.Equal(got, webhookMAC(h.signingKey, body))
+}
+
+// userCallback adapts a user callback to a WebhookFunc, taking the user from
+// the interaction user, the payload data, or the user ID path parameter for
+// events without data (e.g. User.Deleted), in that order.
+func userCallback(fn func(ctx context.Context, user User) error) WebhookFunc {
+	return func(ctx context.Context, payload WebhookPayload) error {
+		if payload.User != nil {
+			return fn(ctx, *payload.User)
+		}
+		if (len(payload.Data) == 0 || string(payload.Data) == "null") && payload.Params["userId"] != "" {
+			return fn(ctx, User{ID: payload.Params["userId"]})
+		}
+		var user User
+		if err := payload.DecodeData(&user); err != nil {
+			return errtag.Tag[errtag.InvalidArgument](err)
+		}
+		return fn(ctx, user)
+	}
+}
+
+// SignWebhook returns the hex encoded signature of a webhook body, for
+// testing webhook handlers.
+func SignWebhook(signingKey string, body []byte) string {
+	return hex.EncodeToString(webhookMAC([]byte(signingKey), body))
+}
+
+func webhookMAC(key []byte, body []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(body)
+	return mac.Sum(nil)
+}
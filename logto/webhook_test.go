@@ -0,0 +1,106 @@
+package logto
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/errtag"
+)
+
+func TestWebhookHandler(t *testing.T) {
+	const signingKey = "signing-key"
+
+	var created, signedIn, deleted []User
+	var failUpdate bool
+	h := NewWebhookHandler(signingKey).
+		OnUserCreated(func(_ context.Context, user User) error {
+			created = append(created, user)
+			return nil
+		}).
+		OnSignIn(func(_ context.Context, user User) error {
+			signedIn = append(signedIn, user)
+			return nil
+		}).
+		OnUserDeleted(func(_ context.Context, user User) error {
+			deleted = append(deleted, user)
+			return nil
+		}).
+		OnUserUpdated(func(context.Context, User) error {
+			if failUpdate {
+				return errors.New("boom")
+			}
+			return nil
+		})
+
+	e := echo.New()
+	h.Register(e.Group("/webhooks/logto"))
+
+	send := func(body string, signature string) (*httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest(http.MethodPost, "/webhooks/logto", strings.NewReader(body))
+		req.Header.Set(WebhookSignatureHeader, signature)
+		rec := httptest.NewRecorder()
+		return rec, h.Handle(e.NewContext(req, rec))
+	}
+	sendSigned := func(body string) (*httptest.ResponseRecorder, error) {
+		return send(body, SignWebhook(signingKey, []byte(body)))
+	}
+
+	t.Run("user created", func(t *testing.T) {
+		rec, err := sendSigned(`{"hookId":"h1","event":"User.Created","createdAt":"2024-01-01T00:00:00.000Z","data":{"id":"u1","primaryEmail":"a@example.com"}}`)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		require.Len(t, created, 1)
+		assert.Equal(t, "u1", created[0].ID)
+		assert.Equal(t, "a@example.com", created[0].PrimaryEmail)
+	})
+
+	t.Run("sign in", func(t *testing.T) {
+		_, err := sendSigned(`{"hookId":"h1","event":"PostSignIn","interactionEvent":"SignIn","userId":"u2","user":{"id":"u2","username":"bob"}}`)
+		require.NoError(t, err)
+		require.Len(t, signedIn, 1)
+		assert.Equal(t, "bob", signedIn[0].Username)
+	})
+
+	t.Run("user deleted", func(t *testing.T) {
+		_, err := sendSigned(`{"hookId":"h1","event":"User.Deleted","params":{"userId":"u3"},"data":null}`)
+		require.NoError(t, err)
+		require.Len(t, deleted, 1)
+		assert.Equal(t, "u3", deleted[0].ID)
+	})
+
+	t.Run("unhandled event", func(t *testing.T) {
+		rec, err := sendSigned(`{"hookId":"h1","event":"Organization.Created","data":{"id":"o1"}}`)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+	})
+
+	t.Run("callback error", func(t *testing.T) {
+		failUpdate = true
+		defer func() { failUpdate = false }()
+		_, err := sendSigned(`{"hookId":"h1","event":"User.Data.Updated","data":{"id":"u1"}}`)
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "boom")
+	})
+
+	t.Run("invalid signature", func(t *testing.T) {
+		body := `{"hookId":"h1","event":"User.Created","data":{"id":"u1"}}`
+		for _, sig := range []string{"", "not-hex", SignWebhook("other-key", []byte(body))} {
+			_, err := send(body, sig)
+			assert.True(t, errtag.HasTag[errtag.Unauthorized](err), sig)
+		}
+		assert.Len(t, created, 1)
+	})
+
+	t.Run("malformed body", func(t *testing.T) {
+		_, err := sendSigned(`{`)
+		assert.True(t, errtag.HasTag[errtag.InvalidArgument](err))
+	})
+}
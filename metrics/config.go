@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"strings"
+
+	"github.com/cohesivestack/valgo"
+
+	"github.com/joshjon/kit/valgoutil"
+)
+
+const (
+	defaultPrometheusPath      = "/metrics"
+	defaultOTLPIntervalSeconds = 60
+)
+
+// Config configures a Provider. Metrics are exported to each of the
+// configured exporters, or recorded without being exported when none are.
+type Config struct {
+	ServiceName string            `yaml:"serviceName" env:"SERVICE_NAME"`
+	Prometheus  *PrometheusConfig `yaml:"prometheus" envPrefix:"PROMETHEUS_"` // optional Prometheus pull exporter
+	OTLP        *OTLPConfig       `yaml:"otlp" envPrefix:"OTLP_"`             // optional OTLP push exporter
+}
+
+func (c *Config) Validation() *valgo.Validation {
+	v := valgo.Is(valgo.String(c.ServiceName, "serviceName").Not().Blank())
+	if c.Prometheus != nil {
+		v.In("prometheus", c.Prometheus.Validation())
+	}
+	if c.OTLP != nil {
+		v.In("otlp", c.OTLP.Validation())
+	}
+	return v
+}
+
+// PrometheusConfig enables the Prometheus exporter, serving metrics with
+// Provider.Handler for scraping.
+//
+// Zero values use the defaults: path /metrics.
+type PrometheusConfig struct {
+	Path string `yaml:"path" env:"PATH"`
+}
+
+func (c *PrometheusConfig) Validation() *valgo.Validation {
+	return valgo.Is(
+		valgo.String(c.Path, "path").Empty().Or().Passing(func(path string) bool {
+			return strings.HasPrefix(path, "/")
+		}, "Must start with /"),
+	)
+}
+
+func (c PrometheusConfig) withDefaults() PrometheusConfig {
+	if c.Path == "" {
+		c.Path = defaultPrometheusPath
+	}
+	return c
+}
+
+// OTLPConfig enables the OTLP exporter, periodically pushing metrics over
+// HTTP to EndpointURL (e.g. http://otel-collector:4318). Plain HTTP is used
+// for http URLs.
+//
+// Zero values use the defaults: a 60s interval.
+type OTLPConfig struct {
+	EndpointURL     string            `yaml:"endpointURL" env:"ENDPOINT_URL"`
+	IntervalSeconds int               `yaml:"intervalSeconds" env:"INTERVAL_SECONDS"`
+	Headers         map[string]string `yaml:"headers" env:"HEADERS"` // e.g. for authentication
+}
+
+func (c *OTLPConfig) Validation() *valgo.Validation {
+	return valgo.Is(
+		valgoutil.URLValidator(c.EndpointURL, "endpointURL"),
+		valgo.Int(c.IntervalSeconds, "intervalSeconds").GreaterOrEqualTo(0),
+	)
+}
+
+func (c OTLPConfig) withDefaults() OTLPConfig {
+	if c.IntervalSeconds == 0 {
+		c.IntervalSeconds = defaultOTLPIntervalSeconds
+	}
+	return c
+}
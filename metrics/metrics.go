@@ -0,0 +1,208 @@
+// Package metrics provides an OpenTelemetry meter provider exporting to
+// Prometheus and OTLP, instrument helpers with sane defaults and server
+// middleware, so services record consistent metrics.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	otelprom "go.opentelemetry.io/otel/exporters/prometheus"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// DefaultDurationBuckets are the histogram bucket boundaries, in seconds, used
+// by Histogram unless set with WithBuckets. They range from 5ms to 10s, which
+// suits request latencies.
+var DefaultDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Option optionally configures a Provider.
+type Option func(opts *options)
+
+// WithReader adds a reader that metrics are also exported to, e.g. an
+// sdkmetric.ManualReader to collect metrics in tests.
+func WithReader(reader sdkmetric.Reader) Option {
+	return func(opts *options) {
+		opts.readers = append(opts.readers, reader)
+	}
+}
+
+// WithResourceAttributes adds attributes describing the service to all
+// metrics, e.g. its version or deployment environment.
+func WithResourceAttributes(attrs ...attribute.KeyValue) Option {
+	return func(opts *options) {
+		opts.attrs = append(opts.attrs, attrs...)
+	}
+}
+
+type options struct {
+	readers []sdkmetric.Reader
+	attrs   []attribute.KeyValue
+}
+
+// Provider provides meters exporting to the configured exporters.
+type Provider struct {
+	provider       *sdkmetric.MeterProvider
+	meter          metric.Meter
+	promHandler    http.Handler
+	prometheusPath string
+}
+
+// New creates a new Provider. Shutdown must be called to flush pending
+// metrics and stop exporting.
+func New(ctx context.Context, cfg Config, opts ...Option) (*Provider, error) {
+	options := options{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		append([]attribute.KeyValue{attribute.String("service.name", cfg.ServiceName)}, options.attrs...)...,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("create metrics resource: %w", err)
+	}
+
+	p := &Provider{}
+	providerOpts := []sdkmetric.Option{sdkmetric.WithResource(res)}
+	for _, reader := range options.readers {
+		providerOpts = append(providerOpts, sdkmetric.WithReader(reader))
+	}
+
+	if cfg.Prometheus != nil {
+		promCfg := cfg.Prometheus.withDefaults()
+		registry := prometheus.NewRegistry()
+		exporter, err := otelprom.New(otelprom.WithRegisterer(registry))
+		if err != nil {
+			return nil, fmt.Errorf("create prometheus exporter: %w", err)
+		}
+		providerOpts = append(providerOpts, sdkmetric.WithReader(exporter))
+		p.promHandler = promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+		p.prometheusPath = promCfg.Path
+	}
+
+	if cfg.OTLP != nil {
+		otlpCfg := cfg.OTLP.withDefaults()
+		exporter, err := otlpmetrichttp.New(ctx,
+			otlpmetrichttp.WithEndpointURL(otlpCfg.EndpointURL),
+			otlpmetrichttp.WithHeaders(otlpCfg.Headers),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("create otlp exporter: %w", err)
+		}
+		interval := time.Duration(otlpCfg.IntervalSeconds) * time.Second
+		providerOpts = append(providerOpts, sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter, sdkmetric.WithInterval(interval))))
+	}
+
+	p.provider = sdkmetric.NewMeterProvider(providerOpts...)
+	p.meter = p.provider.Meter(cfg.ServiceName)
+	return p, nil
+}
+
+// MeterProvider returns the underlying meter provider, e.g. to instrument
+// libraries or to set as the global meter provider.
+func (p *Provider) MeterProvider() metric.MeterProvider {
+	return p.provider
+}
+
+// Meter returns the meter of the service.
+func (p *Provider) Meter() metric.Meter {
+	return p.meter
+}
+
+// Handler returns the handler serving metrics to Prometheus, or nil if the
+// Prometheus exporter is not configured.
+func (p *Provider) Handler() http.Handler {
+	return p.promHandler
+}
+
+// Router registers routes. It is implemented by server.Server, and by
+// server.AdminRouter to serve metrics on the admin port.
+type Router interface {
+	Add(method string, path string, handler echo.HandlerFunc)
+}
+
+// Register registers the Prometheus handler at the configured path. It does
+// nothing if the Prometheus exporter is not configured.
+func (p *Provider) Register(r Router) {
+	if p.promHandler == nil {
+		return
+	}
+	r.Add(http.MethodGet, p.prometheusPath, echo.WrapHandler(p.promHandler))
+}
+
+// Shutdown flushes pending metrics and stops exporting.
+func (p *Provider) Shutdown(ctx context.Context) error {
+	if err := p.provider.Shutdown(ctx); err != nil && !errors.Is(err, sdkmetric.ErrReaderShutdown) {
+		return fmt.Errorf("shutdown meter provider: %w", err)
+	}
+	return nil
+}
+
+// InstrumentOption optionally configures an instrument created by Counter,
+// UpDownCounter or Histogram.
+type InstrumentOption func(opts *instrumentOptions)
+
+// WithUnit sets the unit of an instrument, e.g. s or By.
+func WithUnit(unit string) InstrumentOption {
+	return func(opts *instrumentOptions) {
+		opts.unit = unit
+	}
+}
+
+// WithBuckets sets the bucket boundaries of a histogram. Defaults to
+// DefaultDurationBuckets.
+func WithBuckets(buckets ...float64) InstrumentOption {
+	return func(opts *instrumentOptions) {
+		opts.buckets = buckets
+	}
+}
+
+type instrumentOptions struct {
+	unit    string
+	buckets []float64
+}
+
+func newInstrumentOptions(opts []InstrumentOption) instrumentOptions {
+	options := instrumentOptions{
+		buckets: DefaultDurationBuckets,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// Counter creates a counter of the service meter.
+func (p *Provider) Counter(name string, description string, opts ...InstrumentOption) (metric.Int64Counter, error) {
+	options := newInstrumentOptions(opts)
+	return p.meter.Int64Counter(name, metric.WithDescription(description), metric.WithUnit(options.unit))
+}
+
+// UpDownCounter creates a counter of the service meter that can also be
+// decremented, e.g. to track in-flight work.
+func (p *Provider) UpDownCounter(name string, description string, opts ...InstrumentOption) (metric.Int64UpDownCounter, error) {
+	options := newInstrumentOptions(opts)
+	return p.meter.Int64UpDownCounter(name, metric.WithDescription(description), metric.WithUnit(options.unit))
+}
+
+// Histogram creates a histogram of the service meter with
+// DefaultDurationBuckets unless set with WithBuckets.
+func (p *Provider) Histogram(name string, description string, opts ...InstrumentOption) (metric.Float64Histogram, error) {
+	options := newInstrumentOptions(opts)
+	return p.meter.Float64Histogram(name,
+		metric.WithDescription(description),
+		metric.WithUnit(options.unit),
+		metric.WithExplicitBucketBoundaries(options.buckets...),
+	)
+}
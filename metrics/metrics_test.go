@@ -0,0 +1,175 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/joshjon/kit/errtag"
+	"github.com/joshjon/kit/server"
+)
+
+var (
+	_ Router = (*server.Server)(nil)
+	_ Router = (*server.AdminRouter)(nil)
+)
+
+func TestProvider_prometheus(t *testing.T) {
+	p, err := New(context.Background(), Config{
+		ServiceName: "test",
+		Prometheus:  &PrometheusConfig{},
+	})
+	require.NoError(t, err)
+	defer p.Shutdown(context.Background())
+
+	counter, err := p.Counter("jobs.processed", "Number of processed jobs.")
+	require.NoError(t, err)
+	counter.Add(context.Background(), 3)
+
+	e := echo.New()
+	p.Register(echoRouter{e})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "jobs_processed_total")
+	assert.Contains(t, rec.Body.String(), `service_name="test"`)
+}
+
+func TestProvider_noPrometheus(t *testing.T) {
+	p, err := New(context.Background(), Config{ServiceName: "test"})
+	require.NoError(t, err)
+	defer p.Shutdown(context.Background())
+
+	assert.Nil(t, p.Handler())
+	e := echo.New()
+	p.Register(echoRouter{e})
+	assert.Empty(t, e.Routes())
+}
+
+func TestProvider_Middleware(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	p, err := New(context.Background(), Config{
+		ServiceName: "test",
+		Prometheus:  &PrometheusConfig{},
+	}, WithReader(reader))
+	require.NoError(t, err)
+	defer p.Shutdown(context.Background())
+
+	mw, err := p.Middleware("/healthz")
+	require.NoError(t, err)
+	e := echo.New()
+	e.Use(mw)
+	p.Register(echoRouter{e})
+	e.GET("/items/:id", func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	})
+	e.GET("/missing", func(c echo.Context) error {
+		return errtag.NewTagged[errtag.NotFound]("missing")
+	})
+	e.GET("/broken", func(c echo.Context) error {
+		return errors.New("broken")
+	})
+	e.GET("/healthz", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	for _, path := range []string{"/items/1", "/items/2", "/missing", "/broken", "/healthz", "/metrics"} {
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	hist := findMetric(t, rm, requestDurationName).Data.(metricdata.Histogram[float64])
+
+	counts := map[string]uint64{}
+	for _, dp := range hist.DataPoints {
+		route, _ := dp.Attributes.Value("http.route")
+		status, _ := dp.Attributes.Value("http.response.status_code")
+		counts[route.AsString()+" "+status.Emit()] += dp.Count
+		assert.Equal(t, DefaultDurationBuckets, dp.Bounds)
+	}
+	assert.Equal(t, map[string]uint64{
+		"/items/:id 204": 2,
+		"/missing 404":   1,
+		"/broken 500":    1,
+	}, counts)
+
+	active := findMetric(t, rm, activeRequestsName).Data.(metricdata.Sum[int64])
+	for _, dp := range active.DataPoints {
+		assert.Zero(t, dp.Value)
+	}
+}
+
+func TestProvider_Histogram(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	p, err := New(context.Background(), Config{ServiceName: "test"}, WithReader(reader))
+	require.NoError(t, err)
+	defer p.Shutdown(context.Background())
+
+	hist, err := p.Histogram("payload.size", "Size of payloads.", WithUnit("By"), WithBuckets(100, 1000))
+	require.NoError(t, err)
+	hist.Record(context.Background(), 500)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	m := findMetric(t, rm, "payload.size")
+	assert.Equal(t, "By", m.Unit)
+	dps := m.Data.(metricdata.Histogram[float64]).DataPoints
+	require.Len(t, dps, 1)
+	assert.Equal(t, []float64{100, 1000}, dps[0].Bounds)
+	assert.Equal(t, []uint64{0, 1, 0}, dps[0].BucketCounts)
+
+	service, ok := rm.Resource.Set().Value("service.name")
+	require.True(t, ok)
+	assert.Equal(t, attribute.StringValue("test"), service)
+}
+
+func TestConfig_Validation(t *testing.T) {
+	valid := Config{
+		ServiceName: "test",
+		Prometheus:  &PrometheusConfig{Path: "/metrics"},
+		OTLP:        &OTLPConfig{EndpointURL: "http://localhost:4318"},
+	}
+	assert.True(t, valid.Validation().Valid())
+
+	invalid := Config{
+		Prometheus: &PrometheusConfig{Path: "metrics"},
+		OTLP:       &OTLPConfig{EndpointURL: "::", IntervalSeconds: -1},
+	}
+	errs := invalid.Validation().Errors()
+	assert.Contains(t, errs, "serviceName")
+	assert.Contains(t, errs, "prometheus.path")
+	assert.Contains(t, errs, "otlp.endpointURL")
+	assert.Contains(t, errs, "otlp.intervalSeconds")
+}
+
+func findMetric(t *testing.T, rm metricdata.ResourceMetrics, name string) metricdata.Metrics {
+	t.Helper()
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == name {
+				return m
+			}
+		}
+	}
+	require.FailNow(t, "metric not found", name)
+	return metricdata.Metrics{}
+}
+
+type echoRouter struct {
+	e *echo.Echo
+}
+
+func (r echoRouter) Add(method string, path string, handler echo.HandlerFunc) {
+	r.e.Add(method, path, handler)
+}
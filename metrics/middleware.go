@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"slices"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/joshjon/kit/server"
+)
+
+const (
+	requestDurationName = "http.server.request.duration"
+	activeRequestsName  = "http.server.active_requests"
+)
+
+// Middleware returns echo middleware recording the duration of requests in
+// the http.server.request.duration histogram and the number of in-flight
+// requests in http.server.active_requests, labeled by method, route and
+// status code. Requests to the Prometheus path and skipPaths are not
+// recorded.
+func (p *Provider) Middleware(skipPaths ...string) (echo.MiddlewareFunc, error) {
+	duration, err := p.Histogram(requestDurationName, "Duration of HTTP server requests.", WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+	active, err := p.UpDownCounter(activeRequestsName, "Number of in-flight HTTP server requests.", WithUnit("{request}"))
+	if err != nil {
+		return nil, err
+	}
+	if p.prometheusPath != "" {
+		skipPaths = append(skipPaths, p.prometheusPath)
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if slices.Contains(skipPaths, c.Path()) {
+				return next(c)
+			}
+
+			ctx := c.Request().Context()
+			method := attribute.String("http.request.method", c.Request().Method)
+			route := attribute.String("http.route", c.Path())
+			active.Add(ctx, 1, metric.WithAttributes(method, route))
+			defer active.Add(ctx, -1, metric.WithAttributes(method, route))
+
+			start := time.Now()
+			err := next(c)
+			duration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(
+				method,
+				route,
+				attribute.Int("http.response.status_code", server.ResponseStatus(c, err)),
+			))
+			return err
+		}
+	}, nil
+}
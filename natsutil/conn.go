@@ -0,0 +1,210 @@
+// Package natsutil connects to NATS and provides JetStream stream and
+// consumer helpers and a typed API for publishing and consuming JSON
+// messages.
+package natsutil
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cohesivestack/valgo"
+	"github.com/nats-io/nats.go"
+
+	"github.com/joshjon/kit/log"
+	"github.com/joshjon/kit/retry"
+	"github.com/joshjon/kit/valgoutil"
+)
+
+const (
+	defaultMaxReconnectWaitSeconds = 30
+	reconnectInitialInterval       = 500 * time.Millisecond
+)
+
+// Config configures a connection to NATS. URL is a comma separated list of
+// server URLs, e.g. nats://nats-1:4222,nats://nats-2:4222. CredsFile is the
+// path to a user credentials file containing the JWT and seed of the user.
+//
+// Connections are reconnected with exponential backoff and jitter up to
+// MaxReconnectWaitSeconds between attempts. MaxReconnects limits the
+// reconnect attempts to each server, where a negative value disables
+// reconnecting.
+//
+// Zero values use the defaults: unlimited reconnects and a max reconnect
+// wait of 30s.
+type Config struct {
+	URL                     string     `yaml:"url" env:"URL"`
+	Name                    string     `yaml:"name" env:"NAME"` // client name shown in server monitoring
+	CredsFile               string     `yaml:"credsFile" env:"CREDS_FILE"`
+	TLS                     *TLSConfig `yaml:"tls" envPrefix:"TLS_"`
+	MaxReconnects           int        `yaml:"maxReconnects" env:"MAX_RECONNECTS"`
+	MaxReconnectWaitSeconds int        `yaml:"maxReconnectWaitSeconds" env:"MAX_RECONNECT_WAIT_SECONDS"`
+}
+
+func (c *Config) Validation() *valgo.Validation {
+	v := valgo.Is(
+		valgo.String(c.URL, "url").Not().Blank().Passing(isValidServerURLs, "Must be comma separated nats, tls, ws or wss URLs"),
+		valgo.Int(c.MaxReconnectWaitSeconds, "maxReconnectWaitSeconds").GreaterOrEqualTo(0),
+	)
+	if c.CredsFile != "" {
+		v.Is(valgoutil.FilePathValidator(c.CredsFile, true, "credsFile"))
+	}
+	if c.TLS != nil {
+		v.In("tls", c.TLS.Validation())
+	}
+	return v
+}
+
+func (c Config) withDefaults() Config {
+	switch {
+	case c.MaxReconnects == 0:
+		c.MaxReconnects = -1 // unlimited
+	case c.MaxReconnects < 0:
+		c.MaxReconnects = 0
+	}
+	if c.MaxReconnectWaitSeconds == 0 {
+		c.MaxReconnectWaitSeconds = defaultMaxReconnectWaitSeconds
+	}
+	return c
+}
+
+// TLSConfig configures TLS for connections to NATS. The client certificate
+// is optional and only required when servers verify clients.
+type TLSConfig struct {
+	CertFile   string `yaml:"certFile" env:"CERT_FILE"`      // path to the client certificate file
+	KeyFile    string `yaml:"keyFile" env:"KEY_FILE"`        // path to the client key file
+	CACertFile string `yaml:"caCertFile" env:"CA_CERT_FILE"` // path to the CA certificate file
+}
+
+func (c *TLSConfig) Validation() *valgo.Validation {
+	v := valgo.Is(
+		valgoutil.RequiredIf(c.CertFile, c.KeyFile != "", "certFile"),
+		valgoutil.RequiredIf(c.KeyFile, c.CertFile != "", "keyFile"),
+	)
+	if c.CertFile != "" {
+		v.Is(valgoutil.FilePathValidator(c.CertFile, true, "certFile"))
+	}
+	if c.KeyFile != "" {
+		v.Is(valgoutil.FilePathValidator(c.KeyFile, true, "keyFile"))
+	}
+	if c.CACertFile != "" {
+		v.Is(valgoutil.FilePathValidator(c.CACertFile, true, "caCertFile"))
+	}
+	return v
+}
+
+// ConnectOption optionally configures Connect.
+type ConnectOption func(opts *connectOptions)
+
+// WithLogger sets the Logger used to log connection events. Defaults to
+// log.NewLogger.
+func WithLogger(logger log.Logger) ConnectOption {
+	return func(opts *connectOptions) {
+		opts.logger = logger
+	}
+}
+
+// WithNATSOptions adds nats.Options applied after the options of the Config,
+// e.g. nats.Token for token authentication.
+func WithNATSOptions(natsOpts ...nats.Option) ConnectOption {
+	return func(opts *connectOptions) {
+		opts.natsOpts = append(opts.natsOpts, natsOpts...)
+	}
+}
+
+type connectOptions struct {
+	logger   log.Logger
+	natsOpts []nats.Option
+}
+
+// Connect connects to NATS, retrying failed connection attempts until ctx is
+// done. Disconnects, reconnects and asynchronous errors are logged.
+func Connect(ctx context.Context, cfg Config, opts ...ConnectOption) (*nats.Conn, error) {
+	options := connectOptions{
+		logger: log.NewLogger(),
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	cfg = cfg.withDefaults()
+	logger := options.logger.With("nats_name", cfg.Name)
+
+	reconnectPolicy := retry.Policy{
+		InitialInterval: reconnectInitialInterval,
+		MaxInterval:     time.Duration(cfg.MaxReconnectWaitSeconds) * time.Second,
+	}
+
+	natsOpts := []nats.Option{
+		nats.Name(cfg.Name),
+		nats.MaxReconnects(cfg.MaxReconnects),
+		nats.CustomReconnectDelay(func(attempts int) time.Duration {
+			return reconnectPolicy.Backoff(attempts)
+		}),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			if err != nil {
+				logger.Warn("disconnected from nats", "error", err)
+				return
+			}
+			logger.Info("disconnected from nats")
+		}),
+		nats.ReconnectHandler(func(nc *nats.Conn) {
+			logger.Info("reconnected to nats", "url", nc.ConnectedUrlRedacted())
+		}),
+		nats.ClosedHandler(func(_ *nats.Conn) {
+			logger.Info("nats connection closed")
+		}),
+		nats.ErrorHandler(func(_ *nats.Conn, sub *nats.Subscription, err error) {
+			if sub != nil {
+				logger.Error("nats subscription error", "subject", sub.Subject, "error", err)
+				return
+			}
+			logger.Error("nats error", "error", err)
+		}),
+	}
+	if cfg.CredsFile != "" {
+		natsOpts = append(natsOpts, nats.UserCredentials(cfg.CredsFile))
+	}
+	if cfg.TLS != nil {
+		if cfg.TLS.CertFile != "" {
+			natsOpts = append(natsOpts, nats.ClientCert(cfg.TLS.CertFile, cfg.TLS.KeyFile))
+		}
+		if cfg.TLS.CACertFile != "" {
+			natsOpts = append(natsOpts, nats.RootCAs(cfg.TLS.CACertFile))
+		}
+		natsOpts = append(natsOpts, nats.Secure())
+	}
+	natsOpts = append(natsOpts, options.natsOpts...)
+
+	connectPolicy := retry.Policy{
+		MaxAttempts: -1,
+		MaxInterval: reconnectPolicy.MaxInterval,
+		OnRetry: func(attempt int, err error, backoff time.Duration) {
+			logger.Warn("failed to connect to nats, retrying", "attempt", attempt, "backoff", backoff, "error", err)
+		},
+	}
+	nc, err := retry.Do(ctx, connectPolicy, func(context.Context) (*nats.Conn, error) {
+		return nats.Connect(cfg.URL, natsOpts...)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("connect to nats: %w", err)
+	}
+	logger.Info("connected to nats", "url", nc.ConnectedUrlRedacted())
+	return nc, nil
+}
+
+func isValidServerURLs(urls string) bool {
+	for rawURL := range strings.SplitSeq(urls, ",") {
+		u, err := url.Parse(strings.TrimSpace(rawURL))
+		if err != nil || u.Host == "" {
+			return false
+		}
+		switch u.Scheme {
+		case "nats", "tls", "ws", "wss":
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,62 @@
+package natsutil
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/log"
+	"github.com/joshjon/kit/testutil"
+)
+
+func TestConnect(t *testing.T) {
+	url := testutil.StartNATS(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	nc, err := Connect(ctx, Config{URL: url, Name: "test"}, WithLogger(log.NewLogger(log.WithNop())))
+	require.NoError(t, err)
+	defer nc.Close()
+	assert.True(t, nc.IsConnected())
+}
+
+func TestConnect_contextDone(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	cfg := Config{URL: "nats://" + testutil.GetFreeHostPort(t)}
+	_, err := Connect(ctx, cfg, WithLogger(log.NewLogger(log.WithNop())))
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestConfig_Validation(t *testing.T) {
+	tests := []struct {
+		name  string
+		cfg   Config
+		valid bool
+	}{
+		{name: "single url", cfg: Config{URL: "nats://localhost:4222"}, valid: true},
+		{name: "multiple urls", cfg: Config{URL: "nats://a:4222, tls://b:4222"}, valid: true},
+		{name: "blank url", cfg: Config{}, valid: false},
+		{name: "unsupported scheme", cfg: Config{URL: "http://localhost:4222"}, valid: false},
+		{name: "negative max reconnect wait", cfg: Config{URL: "nats://localhost:4222", MaxReconnectWaitSeconds: -1}, valid: false},
+		{name: "tls key without cert", cfg: Config{URL: "nats://localhost:4222", TLS: &TLSConfig{KeyFile: "key.pem"}}, valid: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.valid, tt.cfg.Validation().Valid())
+		})
+	}
+}
+
+func TestConfig_withDefaults(t *testing.T) {
+	cfg := Config{}.withDefaults()
+	assert.Equal(t, -1, cfg.MaxReconnects)
+	assert.Equal(t, defaultMaxReconnectWaitSeconds, cfg.MaxReconnectWaitSeconds)
+
+	cfg = Config{MaxReconnects: -1}.withDefaults()
+	assert.Equal(t, 0, cfg.MaxReconnects)
+}
@@ -0,0 +1,32 @@
+package natsutil
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// EnsureStream creates the stream of cfg, or updates it if it exists with a
+// different config. It is safe to call on every startup of every replica.
+func EnsureStream(ctx context.Context, js jetstream.JetStream, cfg jetstream.StreamConfig) (jetstream.Stream, error) {
+	stream, err := js.CreateOrUpdateStream(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("ensure stream %s: %w", cfg.Name, err)
+	}
+	return stream, nil
+}
+
+// EnsureConsumer creates the durable consumer of cfg on stream, or updates it
+// if it exists with a different config. Consumers without a Durable name are
+// rejected, as ephemeral consumers can't be ensured across restarts.
+func EnsureConsumer(ctx context.Context, js jetstream.JetStream, stream string, cfg jetstream.ConsumerConfig) (jetstream.Consumer, error) {
+	if cfg.Durable == "" {
+		return nil, fmt.Errorf("ensure consumer on stream %s: durable name required", stream)
+	}
+	consumer, err := js.CreateOrUpdateConsumer(ctx, stream, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("ensure consumer %s on stream %s: %w", cfg.Durable, stream, err)
+	}
+	return consumer, nil
+}
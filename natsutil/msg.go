@@ -0,0 +1,200 @@
+package natsutil
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+
+	"github.com/joshjon/kit/log"
+	"github.com/joshjon/kit/preview"
+)
+
+const (
+	contentTypeHeader = "Content-Type"
+	contentTypeJSON   = "application/json"
+	ackTimeout        = 10 * time.Second
+)
+
+// MsgOption optionally configures a Publisher or Consume.
+type MsgOption func(opts *msgOptions)
+
+// WithMsgLogger sets the Logger used to log messages. Published and received
+// messages are logged at debug level with a preview of their data, and failed
+// messages at warn and error level. Defaults to log.NewLogger.
+func WithMsgLogger(logger log.Logger) MsgOption {
+	return func(opts *msgOptions) {
+		opts.logger = logger
+	}
+}
+
+// WithPreviewer sets the Previewer used to preview the data of logged
+// messages. Defaults to preview.Default.
+func WithPreviewer(p *preview.Previewer) MsgOption {
+	return func(opts *msgOptions) {
+		opts.previewer = p
+	}
+}
+
+type msgOptions struct {
+	logger    log.Logger
+	previewer *preview.Previewer
+}
+
+func newMsgOptions(opts []MsgOption) msgOptions {
+	options := msgOptions{
+		logger:    log.NewLogger(),
+		previewer: preview.Default(),
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// Publisher publishes messages of type T encoded as JSON to a JetStream
+// subject.
+type Publisher[T any] struct {
+	js      jetstream.JetStream
+	subject string
+	opts    msgOptions
+}
+
+// NewPublisher creates a Publisher of messages to subject, which must be
+// captured by a stream.
+func NewPublisher[T any](js jetstream.JetStream, subject string, opts ...MsgOption) *Publisher[T] {
+	return &Publisher[T]{
+		js:      js,
+		subject: subject,
+		opts:    newMsgOptions(opts),
+	}
+}
+
+// Publish publishes msg and waits for the stream to acknowledge it. Use
+// jetstream.WithMsgID to deduplicate messages that are published more than
+// once, e.g. when retrying.
+func (p *Publisher[T]) Publish(ctx context.Context, msg T, opts ...jetstream.PublishOpt) (*jetstream.PubAck, error) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("marshal message: %w", err)
+	}
+	natsMsg := &nats.Msg{
+		Subject: p.subject,
+		Data:    data,
+		Header:  nats.Header{contentTypeHeader: []string{contentTypeJSON}},
+	}
+	ack, err := p.js.PublishMsg(ctx, natsMsg, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("publish to %s: %w", p.subject, err)
+	}
+	p.opts.logger.Debug("published message", "subject", p.subject, "stream", ack.Stream, "seq", ack.Sequence,
+		"data", p.opts.previewer.Value(data))
+	return ack, nil
+}
+
+// Msg is a received message with its data decoded as T.
+type Msg[T any] struct {
+	Data T
+	Raw  jetstream.Msg
+}
+
+// Handler processes a message. Returning an error naks the message so it is
+// redelivered according to the consumer config, e.g. after its BackOff,
+// unless wrapped with Permanent.
+type Handler[T any] func(ctx context.Context, msg *Msg[T]) error
+
+// Consume processes the messages of consumer with handler until ctx is done,
+// then drains the messages already fetched and waits for them to be
+// processed. Messages are acked once handler returns without an error.
+// Messages whose data can't be decoded as T and messages failing with a
+// permanent error are terminated, so they are not redelivered.
+func Consume[T any](ctx context.Context, consumer jetstream.Consumer, handler Handler[T], opts ...MsgOption) error {
+	options := newMsgOptions(opts)
+	logger := options.logger.With("consumer", consumer.CachedInfo().Name)
+
+	cc, err := consumer.Consume(func(raw jetstream.Msg) {
+		processMsg(ctx, logger, options.previewer, handler, raw)
+	}, jetstream.ConsumeErrHandler(func(_ jetstream.ConsumeContext, err error) {
+		logger.Warn("nats consume error", "error", err)
+	}))
+	if err != nil {
+		return fmt.Errorf("consume: %w", err)
+	}
+
+	<-ctx.Done()
+	cc.Drain()
+	<-cc.Closed()
+	return nil
+}
+
+func processMsg[T any](ctx context.Context, logger log.Logger, previewer *preview.Previewer, handler Handler[T], raw jetstream.Msg) {
+	logger = logger.With("subject", raw.Subject())
+	if md, err := raw.Metadata(); err == nil {
+		logger = logger.With("stream_seq", md.Sequence.Stream, "delivered", md.NumDelivered)
+	}
+	data := raw.Data()
+	logger.Debug("received message", "data", previewer.Value(data))
+
+	msg := &Msg[T]{Raw: raw}
+	if err := json.Unmarshal(data, &msg.Data); err != nil {
+		logger.Error("failed to decode message, terminating", "error", err, "data", previewer.Value(data))
+		ackMsg(logger, raw.Term)
+		return
+	}
+
+	err := runHandler(ctx, handler, msg)
+	switch {
+	case err == nil:
+		ackMsg(logger, func() error {
+			ackCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), ackTimeout)
+			defer cancel()
+			return raw.DoubleAck(ackCtx)
+		})
+	case isPermanent(err):
+		logger.Error("message failed, terminating", "error", err, "data", previewer.Value(data))
+		ackMsg(logger, raw.Term)
+	default:
+		logger.Warn("message failed and will be redelivered", "error", err)
+		ackMsg(logger, raw.Nak)
+	}
+}
+
+func ackMsg(logger log.Logger, ack func() error) {
+	if err := ack(); err != nil {
+		logger.Error("failed to acknowledge message", "error", err)
+	}
+}
+
+func runHandler[T any](ctx context.Context, handler Handler[T], msg *Msg[T]) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panicked: %v", r)
+		}
+	}()
+	return handler(ctx, msg)
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err returned by a Handler so the message is terminated
+// rather than redelivered.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+func isPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
@@ -0,0 +1,168 @@
+package natsutil
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/log"
+	"github.com/joshjon/kit/testutil"
+)
+
+type testEvent struct {
+	Name string `json:"name"`
+}
+
+func newTestJetStream(t *testing.T) jetstream.JetStream {
+	t.Helper()
+	nc, err := nats.Connect(testutil.StartNATS(t))
+	require.NoError(t, err)
+	t.Cleanup(nc.Close)
+	js, err := jetstream.New(nc)
+	require.NoError(t, err)
+	return js
+}
+
+func newTestConsumer(t *testing.T, js jetstream.JetStream) jetstream.Consumer {
+	t.Helper()
+	ctx := context.Background()
+	_, err := EnsureStream(ctx, js, jetstream.StreamConfig{Name: "events", Subjects: []string{"events.>"}})
+	require.NoError(t, err)
+	consumer, err := EnsureConsumer(ctx, js, "events", jetstream.ConsumerConfig{
+		Durable:    "test",
+		AckPolicy:  jetstream.AckExplicitPolicy,
+		AckWait:    time.Second,
+		MaxDeliver: 3,
+	})
+	require.NoError(t, err)
+	return consumer
+}
+
+func TestEnsureStream_idempotent(t *testing.T) {
+	js := newTestJetStream(t)
+	ctx := context.Background()
+	cfg := jetstream.StreamConfig{Name: "events", Subjects: []string{"events.>"}}
+
+	_, err := EnsureStream(ctx, js, cfg)
+	require.NoError(t, err)
+	cfg.MaxMsgs = 100
+	stream, err := EnsureStream(ctx, js, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), stream.CachedInfo().Config.MaxMsgs)
+}
+
+func TestEnsureConsumer_durableRequired(t *testing.T) {
+	js := newTestJetStream(t)
+	_, err := EnsureConsumer(context.Background(), js, "events", jetstream.ConsumerConfig{})
+	require.Error(t, err)
+}
+
+func TestPublishConsume(t *testing.T) {
+	js := newTestJetStream(t)
+	consumer := newTestConsumer(t, js)
+	logger := log.NewLogger(log.WithNop())
+
+	pub := NewPublisher[testEvent](js, "events.created", WithMsgLogger(logger))
+	ack, err := pub.Publish(context.Background(), testEvent{Name: "a"})
+	require.NoError(t, err)
+	assert.Equal(t, "events", ack.Stream)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	received := make(chan *Msg[testEvent], 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- Consume(ctx, consumer, func(_ context.Context, msg *Msg[testEvent]) error {
+			received <- msg
+			return nil
+		}, WithMsgLogger(logger))
+	}()
+
+	msg := testutil.AssertReceiveChan(t, received, 5*time.Second)
+	assert.Equal(t, "a", msg.Data.Name)
+	assert.Equal(t, contentTypeJSON, msg.Raw.Headers().Get(contentTypeHeader))
+
+	cancel()
+	require.NoError(t, testutil.AssertReceiveChan(t, done, 5*time.Second))
+
+	info, err := consumer.Info(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, info.NumAckPending)
+	assert.Zero(t, info.NumPending)
+}
+
+func TestConsume_redeliversFailed(t *testing.T) {
+	js := newTestJetStream(t)
+	consumer := newTestConsumer(t, js)
+	logger := log.NewLogger(log.WithNop())
+
+	_, err := NewPublisher[testEvent](js, "events.created", WithMsgLogger(logger)).Publish(context.Background(), testEvent{Name: "a"})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var attempts atomic.Int32
+	acked := make(chan struct{})
+	go Consume(ctx, consumer, func(_ context.Context, _ *Msg[testEvent]) error {
+		if attempts.Add(1) == 1 {
+			return errors.New("transient")
+		}
+		close(acked)
+		return nil
+	}, WithMsgLogger(logger))
+
+	testutil.AssertReceiveChan(t, acked, 5*time.Second)
+	assert.Equal(t, int32(2), attempts.Load())
+}
+
+func TestConsume_terminates(t *testing.T) {
+	tests := []struct {
+		name    string
+		publish func(t *testing.T, js jetstream.JetStream)
+		handler Handler[testEvent]
+	}{
+		{
+			name: "permanent error",
+			publish: func(t *testing.T, js jetstream.JetStream) {
+				_, err := NewPublisher[testEvent](js, "events.created").Publish(context.Background(), testEvent{Name: "a"})
+				require.NoError(t, err)
+			},
+			handler: func(context.Context, *Msg[testEvent]) error {
+				return Permanent(errors.New("invalid event"))
+			},
+		},
+		{
+			name: "undecodable data",
+			publish: func(t *testing.T, js jetstream.JetStream) {
+				_, err := js.Publish(context.Background(), "events.created", []byte("not json"))
+				require.NoError(t, err)
+			},
+			handler: func(context.Context, *Msg[testEvent]) error {
+				return errors.New("handler should not be called")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			js := newTestJetStream(t)
+			consumer := newTestConsumer(t, js)
+			tt.publish(t, js)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go Consume(ctx, consumer, tt.handler, WithMsgLogger(log.NewLogger(log.WithNop())))
+
+			require.Eventually(t, func() bool {
+				info, err := consumer.Info(context.Background())
+				return err == nil && info.AckFloor.Stream == 1 && info.NumAckPending == 0
+			}, 5*time.Second, 10*time.Millisecond)
+		})
+	}
+}
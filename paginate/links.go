@@ -0,0 +1,91 @@
+package paginate
+
+import (
+	"encoding/base64"
+	"net/url"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+const HeaderLink = "Link"
+
+// Links contains URLs to neighbouring pages. It can be embedded in response
+// bodies (JSON:API style) or rendered as an RFC 5988 Link header.
+type Links struct {
+	Self string `json:"self"`
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
+}
+
+// NewLinks builds page links from the URL of the current request. Existing
+// query params are preserved and the page cursor query param is replaced with
+// the encoded cursor. Empty cursors omit the respective link.
+func NewLinks(reqURL *url.URL, nextCursor string, prevCursor string) Links {
+	links := Links{
+		Self: reqURL.String(),
+	}
+	if nextCursor != "" {
+		links.Next = withCursor(reqURL, nextCursor)
+	}
+	if prevCursor != "" {
+		links.Prev = withCursor(reqURL, prevCursor)
+	}
+	return links
+}
+
+// LinkHeader renders the next and prev links as an RFC 5988 Link header value.
+// An empty string is returned if there are no neighbouring pages.
+func (l Links) LinkHeader() string {
+	var parts []string
+	if l.Next != "" {
+		parts = append(parts, `<`+l.Next+`>; rel="next"`)
+	}
+	if l.Prev != "" {
+		parts = append(parts, `<`+l.Prev+`>; rel="prev"`)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// EchoLinks builds absolute page links for the request in the echo context.
+func EchoLinks(c echo.Context, nextCursor string, prevCursor string) Links {
+	req := c.Request()
+	u := *req.URL
+	u.Scheme = c.Scheme()
+	u.Host = req.Host
+	return NewLinks(&u, nextCursor, prevCursor)
+}
+
+// SetLinkHeader sets the Link response header for the request in the echo
+// context. No header is set if there are no neighbouring pages.
+func SetLinkHeader(c echo.Context, nextCursor string, prevCursor string) {
+	if header := EchoLinks(c, nextCursor, prevCursor).LinkHeader(); header != "" {
+		c.Response().Header().Set(HeaderLink, header)
+	}
+}
+
+// EncodeCursor encodes a raw cursor into the form expected by the page cursor
+// query param.
+func EncodeCursor(rawCursor string) string {
+	return base64.URLEncoding.EncodeToString([]byte(rawCursor))
+}
+
+// DecodeCursor decodes a cursor produced by EncodeCursor. Standard base64
+// encoded cursors are also accepted.
+func DecodeCursor(cursor string) (string, error) {
+	raw, err := base64.URLEncoding.DecodeString(cursor)
+	if err != nil {
+		if raw, err = base64.StdEncoding.DecodeString(cursor); err != nil {
+			return "", err
+		}
+	}
+	return string(raw), nil
+}
+
+func withCursor(reqURL *url.URL, cursor string) string {
+	u := *reqURL
+	query := u.Query()
+	query.Set(PageCursorQueryParam, EncodeCursor(cursor))
+	u.RawQuery = query.Encode()
+	return u.String()
+}
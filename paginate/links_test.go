@@ -0,0 +1,30 @@
+package paginate
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLinks(t *testing.T) {
+	u, err := url.Parse("https://api.example.com/projects?page_size=10&status=active&page_cursor=b2xk")
+	require.NoError(t, err)
+
+	links := NewLinks(u, "next-id", "")
+
+	assert.Equal(t, u.String(), links.Self)
+	assert.Empty(t, links.Prev)
+
+	next, err := url.Parse(links.Next)
+	require.NoError(t, err)
+	assert.Equal(t, "10", next.Query().Get(PageSizeQueryParam))
+	assert.Equal(t, "active", next.Query().Get("status"))
+
+	cursor, err := DecodeCursor(next.Query().Get(PageCursorQueryParam))
+	require.NoError(t, err)
+	assert.Equal(t, "next-id", cursor)
+
+	assert.Equal(t, `<`+links.Next+`>; rel="next"`, links.LinkHeader())
+}
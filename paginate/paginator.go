@@ -1,7 +1,7 @@
 package paginate
 
 import (
-	"encoding/base64"
+	"net/url"
 	"strconv"
 
 	"github.com/cohesivestack/valgo"
@@ -15,6 +15,8 @@ const (
 	PageCursorQueryParam = "page_cursor"
 )
 
+const queryParamsTitle = "query_params"
+
 type PageFilter[C comparable] struct {
 	Size   int32
 	Cursor *C
@@ -32,8 +34,30 @@ type Config[T any, C comparable] struct {
 	Lister       ListFunc[T, C]
 }
 
+// PageRequest holds the transport-agnostic pagination params of a request.
+type PageRequest struct {
+	// Size is the requested page size. Zero uses DefaultPageSize.
+	Size int32
+	// Cursor is the encoded cursor returned with the previous page (see
+	// EncodeCursor). Empty requests the first page.
+	Cursor string
+}
+
+// Paginate lists a page of items using the pagination query params of the
+// echo request.
 func Paginate[T any, C comparable](c echo.Context, config Config[T, C]) ([]T, string, error) {
-	filter, err := pageFilterFromQueryParams[C](c, config.CursorParser)
+	req, err := PageRequestFromQuery(c.QueryParams())
+	if err != nil {
+		return nil, "", err
+	}
+	return PaginateRequest(req, config)
+}
+
+// PaginateRequest lists a page of items for the given PageRequest. It returns
+// the items and the raw cursor of the next page, which is empty when there are
+// no more pages.
+func PaginateRequest[T any, C comparable](req PageRequest, config Config[T, C]) ([]T, string, error) {
+	filter, err := NewPageFilter(req, config.CursorParser)
 	if err != nil {
 		return nil, "", err
 	}
@@ -53,39 +77,52 @@ func Paginate[T any, C comparable](c echo.Context, config Config[T, C]) ([]T, st
 	return items, cursor, nil
 }
 
-func pageFilterFromQueryParams[C comparable](c echo.Context, cursorGetter CursorParserFunc[C]) (PageFilter[C], error) {
-	const queryParamsTitle = "query_params"
-
-	filter := PageFilter[C]{
-		Size: DefaultPageSize,
+// PageRequestFromQuery parses a PageRequest from URL query params. Only the
+// format of the params is checked, the page size and cursor are validated by
+// NewPageFilter.
+func PageRequestFromQuery(query url.Values) (PageRequest, error) {
+	req := PageRequest{
+		Cursor: query.Get(PageCursorQueryParam),
 	}
 
-	sizeStr := c.QueryParam(PageSizeQueryParam)
+	sizeStr := query.Get(PageSizeQueryParam)
 	if sizeStr != "" {
 		size64, err := strconv.ParseInt(sizeStr, 10, 32)
 		if err != nil {
-			return filter, err
+			return req, valgo.In(queryParamsTitle, valgo.AddErrorMessage(PageSizeQueryParam, "Must be a valid page size")).Error()
 		}
+		req.Size = int32(size64)
+	}
 
-		filter.Size = int32(size64)
-		verr := valgo.In(queryParamsTitle, valgo.Is(valgo.Int32(filter.Size, PageSizeQueryParam).Between(int32(1), MaxPageSize))).Error()
-		if verr != nil {
-			return filter, verr
+	return req, nil
+}
+
+// NewPageFilter validates a PageRequest and converts it into a PageFilter. The
+// returned filter size is one more than requested so the lister can report
+// whether another page exists.
+func NewPageFilter[C comparable](req PageRequest, cursorParser CursorParserFunc[C]) (PageFilter[C], error) {
+	filter := PageFilter[C]{
+		Size: DefaultPageSize,
+	}
+
+	if req.Size != 0 {
+		if err := validatePageSize(req.Size); err != nil {
+			return filter, err
 		}
+		filter.Size = req.Size
 	}
 
 	filter.Size++ // add one more so we can check if there is another page to return
 
-	b64Cursor := c.QueryParam(PageCursorQueryParam)
-	if b64Cursor != "" {
+	if req.Cursor != "" {
 		verr := valgo.In(queryParamsTitle, valgo.AddErrorMessage(PageCursorQueryParam, "Must be a valid cursor")).Error()
 
-		cursor, err := base64.StdEncoding.DecodeString(b64Cursor)
+		cursor, err := DecodeCursor(req.Cursor)
 		if err != nil {
 			return filter, verr
 		}
 
-		filter.Cursor, err = cursorGetter(string(cursor))
+		filter.Cursor, err = cursorParser(cursor)
 		if err != nil {
 			return filter, err
 		}
@@ -93,3 +130,7 @@ func pageFilterFromQueryParams[C comparable](c echo.Context, cursorGetter Cursor
 
 	return filter, nil
 }
+
+func validatePageSize(size int32) error {
+	return valgo.In(queryParamsTitle, valgo.Is(valgo.Int32(size, PageSizeQueryParam).Between(int32(1), MaxPageSize))).Error()
+}
@@ -0,0 +1,135 @@
+package paginate
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/cohesivestack/valgo"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func assertQueryParamError(t *testing.T, err error, param string) {
+	t.Helper()
+	var verr *valgo.Error
+	require.True(t, errors.As(err, &verr), "want validation error, got %v", err)
+	assert.Contains(t, verr.Errors(), queryParamsTitle+"."+param)
+}
+
+func TestPageRequestFromQuery(t *testing.T) {
+	req, err := PageRequestFromQuery(url.Values{})
+	require.NoError(t, err)
+	assert.Equal(t, PageRequest{}, req)
+
+	req, err = PageRequestFromQuery(url.Values{
+		PageSizeQueryParam:   {"10"},
+		PageCursorQueryParam: {EncodeCursor("42")},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, PageRequest{Size: 10, Cursor: EncodeCursor("42")}, req)
+
+	for _, size := range []string{"ten", "1.5", "9999999999"} {
+		_, err = PageRequestFromQuery(url.Values{PageSizeQueryParam: {size}})
+		assertQueryParamError(t, err, PageSizeQueryParam)
+	}
+}
+
+func TestNewPageFilter(t *testing.T) {
+	filter, err := NewPageFilter(PageRequest{}, Int64CursorParser())
+	require.NoError(t, err)
+	assert.Equal(t, PageFilter[int64]{Size: DefaultPageSize + 1}, filter)
+
+	filter, err = NewPageFilter(PageRequest{Size: 10, Cursor: EncodeCursor("42")}, Int64CursorParser())
+	require.NoError(t, err)
+	assert.Equal(t, int32(11), filter.Size)
+	require.NotNil(t, filter.Cursor)
+	assert.Equal(t, int64(42), *filter.Cursor)
+
+	for _, size := range []int32{-1, MaxPageSize + 1} {
+		_, err = NewPageFilter(PageRequest{Size: size}, Int64CursorParser())
+		assertQueryParamError(t, err, PageSizeQueryParam)
+	}
+
+	_, err = NewPageFilter(PageRequest{Cursor: "%%%"}, Int64CursorParser())
+	assertQueryParamError(t, err, PageCursorQueryParam)
+
+	_, err = NewPageFilter(PageRequest{Cursor: EncodeCursor("not-a-number")}, Int64CursorParser())
+	require.Error(t, err)
+}
+
+func testPaginateConfig(rows []int64) Config[int64, int64] {
+	return Config[int64, int64]{
+		CursorParser: Int64CursorParser(),
+		CursorGetter: func(item int64) string {
+			return strconv.FormatInt(item, 10)
+		},
+		Lister: func(filter PageFilter[int64]) ([]int64, error) {
+			var page []int64
+			for _, row := range rows {
+				if filter.Cursor != nil && row < *filter.Cursor {
+					continue
+				}
+				if len(page) == int(filter.Size) {
+					break
+				}
+				page = append(page, row)
+			}
+			return page, nil
+		},
+	}
+}
+
+func TestPaginateRequest(t *testing.T) {
+	config := testPaginateConfig([]int64{1, 2, 3, 4, 5})
+
+	items, cursor, err := PaginateRequest(PageRequest{Size: 2}, config)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 2}, items)
+	assert.Equal(t, "3", cursor)
+
+	items, cursor, err = PaginateRequest(PageRequest{Size: 2, Cursor: EncodeCursor(cursor)}, config)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{3, 4}, items)
+	assert.Equal(t, "5", cursor)
+
+	items, cursor, err = PaginateRequest(PageRequest{Size: 2, Cursor: EncodeCursor(cursor)}, config)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{5}, items)
+	assert.Empty(t, cursor)
+
+	_, _, err = PaginateRequest(PageRequest{Size: -1}, config)
+	assertQueryParamError(t, err, PageSizeQueryParam)
+}
+
+func TestPaginate(t *testing.T) {
+	config := testPaginateConfig([]int64{1, 2, 3})
+
+	paginate := func(query url.Values) ([]int64, string, error) {
+		req := httptest.NewRequest(http.MethodGet, "/items?"+query.Encode(), nil)
+		return Paginate(echo.New().NewContext(req, httptest.NewRecorder()), config)
+	}
+
+	items, cursor, err := paginate(url.Values{})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 2, 3}, items)
+	assert.Empty(t, cursor)
+
+	items, cursor, err = paginate(url.Values{PageSizeQueryParam: {"1"}, PageCursorQueryParam: {EncodeCursor("2")}})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2}, items)
+	assert.Equal(t, "3", cursor)
+
+	_, _, err = paginate(url.Values{PageSizeQueryParam: {"abc"}})
+	assertQueryParamError(t, err, PageSizeQueryParam)
+
+	_, _, err = paginate(url.Values{PageSizeQueryParam: {strconv.Itoa(int(MaxPageSize) + 1)}})
+	assertQueryParamError(t, err, PageSizeQueryParam)
+
+	_, _, err = paginate(url.Values{PageCursorQueryParam: {"%%%"}})
+	assertQueryParamError(t, err, PageCursorQueryParam)
+}
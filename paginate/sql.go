@@ -0,0 +1,101 @@
+package paginate
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// CursorArgName is the named argument bound to the page cursor in the
+	// clauses returned by SQLClausesFor.
+	CursorArgName = "page_cursor"
+	// LimitArgName is the named argument bound to the page size in the clauses
+	// returned by SQLClausesFor.
+	LimitArgName = "page_limit"
+)
+
+// SortDirection is the direction rows are ordered in.
+type SortDirection string
+
+const (
+	SortAsc  SortDirection = "asc"
+	SortDesc SortDirection = "desc"
+)
+
+// SortFilter describes the column used for keyset pagination and the direction
+// it is ordered in. Column must uniquely and stably order rows (e.g. a primary
+// key or a time-ordered ID) for pagination to be correct.
+type SortFilter struct {
+	Column    string
+	Direction SortDirection
+}
+
+// SQLClauses holds the SQL fragments for a single keyset paginated query.
+type SQLClauses struct {
+	// Where is the keyset condition without the WHERE keyword. It is empty when
+	// there is no cursor (first page).
+	Where string
+	// OrderBy is the ORDER BY clause.
+	OrderBy string
+	// Limit is the LIMIT clause.
+	Limit string
+	// Args contains the named arguments referenced by the clauses.
+	Args pgx.NamedArgs
+}
+
+// WhereClause returns Where prefixed with the WHERE keyword, or an empty string
+// if there is no keyset condition.
+func (s SQLClauses) WhereClause() string {
+	if s.Where == "" {
+		return ""
+	}
+	return "WHERE " + s.Where
+}
+
+// String returns the clauses joined in the order they appear in a query.
+func (s SQLClauses) String() string {
+	parts := make([]string, 0, 3)
+	if w := s.WhereClause(); w != "" {
+		parts = append(parts, w)
+	}
+	parts = append(parts, s.OrderBy, s.Limit)
+	return strings.Join(parts, " ")
+}
+
+// SQLClausesFor translates a PageFilter and SortFilter into keyset pagination
+// clauses and pgx named arguments. The column is quoted as an identifier so it
+// is safe to interpolate, however it should still come from a fixed set of
+// columns rather than directly from user input.
+//
+// Example:
+//
+//	clauses := paginate.SQLClausesFor(filter, paginate.SortFilter{Column: "id"})
+//	query := "SELECT * FROM projects " + clauses.String()
+//	rows, err := pool.Query(ctx, query, clauses.Args)
+func SQLClausesFor[C comparable](filter PageFilter[C], sort SortFilter) SQLClauses {
+	column := pgx.Identifier(strings.Split(sort.Column, ".")).Sanitize()
+
+	// The cursor is the first item of the next page (see PaginateRequest), so
+	// it is included in the page.
+	op, dir := ">=", "ASC"
+	if sort.Direction == SortDesc {
+		op, dir = "<=", "DESC"
+	}
+
+	clauses := SQLClauses{
+		OrderBy: fmt.Sprintf("ORDER BY %s %s", column, dir),
+		Limit:   "LIMIT @" + LimitArgName,
+		Args: pgx.NamedArgs{
+			LimitArgName: filter.Size,
+		},
+	}
+
+	if filter.Cursor != nil {
+		clauses.Where = fmt.Sprintf("%s %s @%s", column, op, CursorArgName)
+		clauses.Args[CursorArgName] = *filter.Cursor
+	}
+
+	return clauses
+}
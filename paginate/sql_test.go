@@ -0,0 +1,127 @@
+package paginate
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/ref"
+)
+
+func TestSQLClausesFor(t *testing.T) {
+	tests := []struct {
+		name      string
+		filter    PageFilter[int64]
+		sort      SortFilter
+		wantQuery string
+		wantArgs  pgx.NamedArgs
+	}{
+		{
+			name:      "first page ascending",
+			filter:    PageFilter[int64]{Size: 11},
+			sort:      SortFilter{Column: "id"},
+			wantQuery: `ORDER BY "id" ASC LIMIT @page_limit`,
+			wantArgs:  pgx.NamedArgs{LimitArgName: int32(11)},
+		},
+		{
+			name:      "cursor ascending",
+			filter:    PageFilter[int64]{Size: 11, Cursor: ref.Ptr(int64(42))},
+			sort:      SortFilter{Column: "id", Direction: SortAsc},
+			wantQuery: `WHERE "id" >= @page_cursor ORDER BY "id" ASC LIMIT @page_limit`,
+			wantArgs:  pgx.NamedArgs{LimitArgName: int32(11), CursorArgName: int64(42)},
+		},
+		{
+			name:      "cursor descending qualified column",
+			filter:    PageFilter[int64]{Size: 6, Cursor: ref.Ptr(int64(7))},
+			sort:      SortFilter{Column: "p.id", Direction: SortDesc},
+			wantQuery: `WHERE "p"."id" <= @page_cursor ORDER BY "p"."id" DESC LIMIT @page_limit`,
+			wantArgs:  pgx.NamedArgs{LimitArgName: int32(6), CursorArgName: int64(7)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SQLClausesFor(tt.filter, tt.sort)
+			assert.Equal(t, tt.wantQuery, got.String())
+			assert.Equal(t, tt.wantArgs, got.Args)
+		})
+	}
+}
+
+// sqlFixtureLister lists rows like a database executing the clauses returned
+// by SQLClausesFor.
+func sqlFixtureLister(t *testing.T, rows []int64, sort SortFilter) ListFunc[int64, int64] {
+	return func(filter PageFilter[int64]) ([]int64, error) {
+		clauses := SQLClausesFor(filter, sort)
+		ordered := slices.Clone(rows)
+		slices.Sort(ordered)
+		if sort.Direction == SortDesc {
+			slices.Reverse(ordered)
+		}
+
+		var page []int64
+		for _, row := range ordered {
+			if cursor, ok := clauses.Args[CursorArgName].(int64); ok {
+				var include bool
+				switch {
+				case strings.Contains(clauses.Where, " >= "):
+					include = row >= cursor
+				case strings.Contains(clauses.Where, " <= "):
+					include = row <= cursor
+				case strings.Contains(clauses.Where, " > "):
+					include = row > cursor
+				case strings.Contains(clauses.Where, " < "):
+					include = row < cursor
+				default:
+					t.Fatalf("unexpected where clause: %s", clauses.Where)
+				}
+				if !include {
+					continue
+				}
+			}
+			if len(page) == int(clauses.Args[LimitArgName].(int32)) {
+				break
+			}
+			page = append(page, row)
+		}
+		return page, nil
+	}
+}
+
+func TestSQLClausesFor_paginatesAllRows(t *testing.T) {
+	rows := []int64{1, 2, 3, 5, 8, 13, 21}
+
+	for _, dir := range []SortDirection{SortAsc, SortDesc} {
+		t.Run(string(dir), func(t *testing.T) {
+			sort := SortFilter{Column: "id", Direction: dir}
+			config := Config[int64, int64]{
+				CursorParser: Int64CursorParser(),
+				CursorGetter: func(item int64) string { return strconv.FormatInt(item, 10) },
+				Lister:       sqlFixtureLister(t, rows, sort),
+			}
+
+			var got []int64
+			req := PageRequest{Size: 2}
+			for {
+				items, cursor, err := PaginateRequest(req, config)
+				require.NoError(t, err)
+				got = append(got, items...)
+				if cursor == "" {
+					break
+				}
+				req.Cursor = EncodeCursor(cursor)
+			}
+
+			want := slices.Clone(rows)
+			if dir == SortDesc {
+				slices.Reverse(want)
+			}
+			assert.Equal(t, want, got)
+		})
+	}
+}
@@ -6,9 +6,7 @@ import (
 	"fmt"
 	"io/fs"
 	"os"
-	"os/signal"
 	"strings"
-	"syscall"
 	"time"
 
 	"github.com/cohesivestack/valgo"
@@ -17,6 +15,7 @@ import (
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/urfave/cli/v2"
 
+	"github.com/joshjon/kit/app"
 	"github.com/joshjon/kit/log"
 	"github.com/joshjon/kit/pgdb"
 )
@@ -253,13 +252,14 @@ func (r *Runner) init(ctx context.Context, cfg config, c *cli.Context) error {
 
 func execCmd(cmd func(ctx context.Context, cfg config, c *cli.Context) error) func(c *cli.Context) error {
 	return func(c *cli.Context) error {
-		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
-		defer cancel()
-		ctx, tcancel := context.WithTimeout(ctx, 30*time.Second)
-		defer tcancel()
-
 		cfg := loadConfig(c)
-		return cmd(ctx, cfg, c)
+		a := app.New(app.WithLogger(log.NewLogger(log.WithNop())))
+		a.AddRunner(c.Command.Name, func(ctx context.Context) error {
+			ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+			defer cancel()
+			return cmd(ctx, cfg, c)
+		})
+		return a.Run(context.Background())
 	}
 }
 
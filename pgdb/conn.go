@@ -9,15 +9,19 @@ import (
 	"os"
 	"time"
 
-	"github.com/cenkalti/backoff/v4"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
-)
 
-const (
-	healthRetryInterval = time.Second
-	healthMaxRetries    = 5
+	"github.com/joshjon/kit/retry"
 )
 
+var healthRetryPolicy = retry.Policy{
+	MaxAttempts:     6,
+	InitialInterval: time.Second,
+	MaxInterval:     time.Second,
+	Jitter:          -1,
+}
+
 type DialOption func(opts *dialOpts)
 
 type TLSConfig struct {
@@ -33,8 +37,17 @@ func WithTLS(tls TLSConfig) DialOption {
 	}
 }
 
+// WithTracer sets a tracer of the queries of all connections, e.g. a
+// trace.PgxTracer.
+func WithTracer(tracer pgx.QueryTracer) DialOption {
+	return func(opts *dialOpts) {
+		opts.tracer = tracer
+	}
+}
+
 type dialOpts struct {
-	tls *TLSConfig
+	tls    *TLSConfig
+	tracer pgx.QueryTracer
 }
 
 func Dial(ctx context.Context, username string, password string, hostPort string, database string, opts ...DialOption) (*pgxpool.Pool, error) {
@@ -74,6 +87,10 @@ func Dial(ctx context.Context, username string, password string, hostPort string
 		cfg.ConnConfig.TLSConfig = tlsConfig
 	}
 
+	if options.tracer != nil {
+		cfg.ConnConfig.Tracer = options.tracer
+	}
+
 	pool, err := pgxpool.NewWithConfig(ctx, cfg)
 	if err != nil {
 		return nil, err
@@ -101,13 +118,12 @@ func loadCACert(caCertFile string) (*x509.CertPool, error) {
 }
 
 func waitHealthy(ctx context.Context, pool *pgxpool.Pool) error {
-	pingFn := func() error {
+	pingFn := func(ctx context.Context) error {
 		ctx, cancel := context.WithTimeout(ctx, time.Second)
 		defer cancel()
 		return pool.Ping(ctx)
 	}
-	bo := backoff.WithMaxRetries(backoff.NewConstantBackOff(healthRetryInterval), healthMaxRetries)
-	if err := backoff.Retry(pingFn, bo); err != nil {
+	if err := retry.DoErr(ctx, healthRetryPolicy, pingFn); err != nil {
 		return fmt.Errorf("postgres connection unhealthy: %w", err)
 	}
 	return nil
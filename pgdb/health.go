@@ -0,0 +1,12 @@
+package pgdb
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/joshjon/kit/health"
+)
+
+// HealthChecker returns a health.Checker pinging the database of pool.
+func HealthChecker(pool *pgxpool.Pool) health.Checker {
+	return health.CheckerFunc(pool.Ping)
+}
@@ -0,0 +1,42 @@
+package pgdb
+
+import (
+	"context"
+	"hash/fnv"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const advisoryUnlockTimeout = 5 * time.Second
+
+// AdvisoryLockKey returns the key of the advisory lock of name.
+func AdvisoryLockKey(name string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(name))
+	return int64(h.Sum64())
+}
+
+// TryAdvisoryLock tries to acquire the session advisory lock of key without
+// waiting, reporting whether it was acquired. An acquired lock holds a
+// connection of pool until released with the returned func.
+func TryAdvisoryLock(ctx context.Context, pool *pgxpool.Pool, key int64) (release func(), acquired bool, err error) {
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	if err = conn.QueryRow(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&acquired); err != nil || !acquired {
+		conn.Release()
+		return nil, false, err
+	}
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), advisoryUnlockTimeout)
+		defer cancel()
+		if _, err := conn.Exec(ctx, "SELECT pg_advisory_unlock($1)", key); err != nil {
+			// The session may still hold the lock, so close the connection
+			// rather than returning it to the pool.
+			_ = conn.Conn().Close(ctx)
+		}
+		conn.Release()
+	}, true, nil
+}
@@ -13,7 +13,8 @@ import (
 )
 
 type migrationOptions struct {
-	version *uint
+	version         *uint
+	migrationsTable string
 }
 
 type MigrateOption func(opts *migrationOptions)
@@ -24,6 +25,15 @@ func WithVersion(version uint) MigrateOption {
 	}
 }
 
+// WithMigrationsTable sets the table recording the applied migrations, e.g. so
+// a package can apply its own migrations alongside those of the service.
+// Defaults to schema_migrations.
+func WithMigrationsTable(table string) MigrateOption {
+	return func(opts *migrationOptions) {
+		opts.migrationsTable = table
+	}
+}
+
 func Migrate(pool *pgxpool.Pool, fsys fs.FS, opts ...MigrateOption) error {
 	var mopts migrationOptions
 	for _, opt := range opts {
@@ -39,7 +49,7 @@ func Migrate(pool *pgxpool.Pool, fsys fs.FS, opts ...MigrateOption) error {
 	db := stdlib.OpenDBFromPool(pool)
 	defer db.Close()
 
-	driver, err := postgres.WithInstance(db, new(postgres.Config))
+	driver, err := postgres.WithInstance(db, &postgres.Config{MigrationsTable: mopts.migrationsTable})
 	if err != nil {
 		return err
 	}
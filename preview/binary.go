@@ -0,0 +1,316 @@
+package preview
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	maxProtobufFieldNumber = 1<<29 - 1
+	maxMsgpackDepth        = 32
+)
+
+var (
+	errShort   = errors.New("short buffer")
+	errInvalid = errors.New("invalid encoding")
+)
+
+// structuredBinaryPreview summarizes the structure of b if it looks like a
+// msgpack map or array, or protobuf wire format. Truncated reports whether b
+// was cut short by the max inspect bytes, in which case an incomplete trailing
+// value is tolerated.
+func structuredBinaryPreview(b []byte, truncated bool, f format) (string, bool) {
+	if f != formatProtobuf {
+		if s, ok := msgpackSummary(b, truncated); ok {
+			return s, true
+		}
+	}
+	if f != formatMsgpack {
+		if s, ok := protobufSummary(b, truncated); ok {
+			return s, true
+		}
+	}
+	return "", false
+}
+
+// protobufSummary renders the field numbers and wire types of the top-level
+// fields of a protobuf message, e.g. "<protobuf 12B> 1:varint 2:bytes[5]".
+func protobufSummary(b []byte, truncated bool) (string, bool) {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "<protobuf %dB>", len(b))
+
+	fields := 0
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		if n <= 0 {
+			return protobufPartial(&sb, fields, truncated && n == 0)
+		}
+		b = b[n:]
+
+		num, wireType := tag>>3, tag&7
+		if num == 0 || num > maxProtobufFieldNumber {
+			return "", false
+		}
+		var desc string
+		switch wireType {
+		case 0:
+			if _, n = binary.Uvarint(b); n <= 0 {
+				return protobufPartial(&sb, fields, truncated && n == 0)
+			}
+			b = b[n:]
+			desc = "varint"
+		case 1:
+			if len(b) < 8 {
+				return protobufPartial(&sb, fields, truncated)
+			}
+			b = b[8:]
+			desc = "fixed64"
+		case 2:
+			l, n := binary.Uvarint(b)
+			if n <= 0 || l > uint64(len(b)-n) {
+				return protobufPartial(&sb, fields, truncated && n >= 0)
+			}
+			b = b[n+int(l):]
+			desc = "bytes[" + strconv.FormatUint(l, 10) + "]"
+		case 5:
+			if len(b) < 4 {
+				return protobufPartial(&sb, fields, truncated)
+			}
+			b = b[4:]
+			desc = "fixed32"
+		default:
+			// Groups (3, 4) are deprecated and rarely seen, so treat them as
+			// not protobuf to reduce false positives.
+			return "", false
+		}
+		fields++
+		sb.WriteByte(' ')
+		sb.WriteString(strconv.FormatUint(num, 10))
+		sb.WriteByte(':')
+		sb.WriteString(desc)
+	}
+	return sb.String(), fields > 0
+}
+
+func protobufPartial(sb *strings.Builder, fields int, ok bool) (string, bool) {
+	if !ok || fields == 0 {
+		return "", false
+	}
+	sb.WriteString(" …")
+	return sb.String(), true
+}
+
+// msgpackSummary renders the top-level map keys or array length of a msgpack
+// message, e.g. "<msgpack 12B map 2> {id, name}".
+func msgpackSummary(b []byte, truncated bool) (string, bool) {
+	size := len(b)
+	if len(b) == 0 {
+		return "", false
+	}
+	d := msgpackDecoder{b: b[1:]}
+	n, isMap, err := d.container(b[0])
+	if err != nil {
+		return "", false
+	}
+
+	if !isMap {
+		if err = d.skipN(n, 0); err != nil && !(truncated && errors.Is(err, errShort)) {
+			return "", false
+		}
+		if err == nil && len(d.b) > 0 {
+			return "", false
+		}
+		return fmt.Sprintf("<msgpack %dB array %d>", size, n), true
+	}
+
+	keys := make([]string, 0, min(n, 16))
+	for range n {
+		key, err := d.key()
+		if err == nil {
+			err = d.skip(0)
+		}
+		if err != nil {
+			if truncated && errors.Is(err, errShort) {
+				break
+			}
+			return "", false
+		}
+		keys = append(keys, key)
+	}
+	if len(d.b) > 0 {
+		return "", false
+	}
+	if len(keys) < n {
+		keys = append(keys, "…")
+	}
+	return fmt.Sprintf("<msgpack %dB map %d> {%s}", size, n, strings.Join(keys, ", ")), true
+}
+
+type msgpackDecoder struct {
+	b []byte
+}
+
+func (d *msgpackDecoder) next(n int) ([]byte, error) {
+	if n < 0 {
+		return nil, errInvalid
+	}
+	if len(d.b) < n {
+		return nil, errShort
+	}
+	out := d.b[:n]
+	d.b = d.b[n:]
+	return out, nil
+}
+
+func (d *msgpackDecoder) uint(size int) (int, error) {
+	b, err := d.next(size)
+	if err != nil {
+		return 0, err
+	}
+	switch size {
+	case 1:
+		return int(b[0]), nil
+	case 2:
+		return int(binary.BigEndian.Uint16(b)), nil
+	default:
+		return int(binary.BigEndian.Uint32(b)), nil
+	}
+}
+
+// container reads the rest of the header of a map or array starting with c,
+// returning its number of elements (pairs for maps).
+func (d *msgpackDecoder) container(c byte) (int, bool, error) {
+	switch {
+	case c >= 0x80 && c <= 0x8f:
+		return int(c & 0x0f), true, nil
+	case c >= 0x90 && c <= 0x9f:
+		return int(c & 0x0f), false, nil
+	case c == 0xdc:
+		n, err := d.uint(2)
+		return n, false, err
+	case c == 0xdd:
+		n, err := d.uint(4)
+		return n, false, err
+	case c == 0xde:
+		n, err := d.uint(2)
+		return n, true, err
+	case c == 0xdf:
+		n, err := d.uint(4)
+		return n, true, err
+	}
+	return 0, false, errInvalid
+}
+
+// key reads a map key, rendering string keys as is and other keys by type.
+func (d *msgpackDecoder) key() (string, error) {
+	if len(d.b) == 0 {
+		return "", errShort
+	}
+	var n int
+	var err error
+	switch c := d.b[0]; {
+	case c >= 0xa0 && c <= 0xbf:
+		d.b = d.b[1:]
+		n = int(c & 0x1f)
+	case c == 0xd9 || c == 0xda || c == 0xdb:
+		d.b = d.b[1:]
+		n, err = d.uint(1 << (c - 0xd9))
+	case c <= 0x7f:
+		d.b = d.b[1:]
+		return strconv.Itoa(int(c)), nil
+	default:
+		return "<" + msgpackTypeName(c) + ">", d.skip(0)
+	}
+	if err != nil {
+		return "", err
+	}
+	b, err := d.next(n)
+	return string(b), err
+}
+
+func (d *msgpackDecoder) skipN(n int, depth int) error {
+	for range n {
+		if err := d.skip(depth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skip skips a single value.
+func (d *msgpackDecoder) skip(depth int) error {
+	if depth > maxMsgpackDepth {
+		return errInvalid
+	}
+	b, err := d.next(1)
+	if err != nil {
+		return err
+	}
+	c := b[0]
+	var n int
+	switch {
+	case c <= 0x7f, c >= 0xe0, c == 0xc0, c == 0xc2, c == 0xc3:
+		return nil
+	case c >= 0x80 && c <= 0x9f, c >= 0xdc && c <= 0xdf:
+		size, isMap, err := d.container(c)
+		if err != nil {
+			return err
+		}
+		if isMap {
+			size *= 2
+		}
+		return d.skipN(size, depth+1)
+	case c >= 0xa0 && c <= 0xbf:
+		n = int(c & 0x1f)
+	case c == 0xc4 || c == 0xd9: // bin8, str8
+		n, err = d.uint(1)
+	case c == 0xc5 || c == 0xda: // bin16, str16
+		n, err = d.uint(2)
+	case c == 0xc6 || c == 0xdb: // bin32, str32
+		n, err = d.uint(4)
+	case c == 0xc7 || c == 0xc8 || c == 0xc9: // ext8, ext16, ext32
+		if n, err = d.uint(1 << (c - 0xc7)); err == nil {
+			n++ // type
+		}
+	case c == 0xca, c == 0xce, c == 0xd2: // float32, uint32, int32
+		n = 4
+	case c == 0xcb, c == 0xcf, c == 0xd3: // float64, uint64, int64
+		n = 8
+	case c == 0xcc, c == 0xd0: // uint8, int8
+		n = 1
+	case c == 0xcd, c == 0xd1: // uint16, int16
+		n = 2
+	case c >= 0xd4 && c <= 0xd8: // fixext
+		n = 1 + 1<<(c-0xd4)
+	default: // 0xc1 is never used
+		return errInvalid
+	}
+	if err != nil {
+		return err
+	}
+	_, err = d.next(n)
+	return err
+}
+
+func msgpackTypeName(c byte) string {
+	switch {
+	case c >= 0x80 && c <= 0x8f, c == 0xde, c == 0xdf:
+		return "map"
+	case c >= 0x90 && c <= 0x9f, c == 0xdc, c == 0xdd:
+		return "array"
+	case c == 0xc0:
+		return "nil"
+	case c == 0xc2, c == 0xc3:
+		return "bool"
+	case c == 0xca, c == 0xcb:
+		return "float"
+	case c >= 0xcc && c <= 0xd3, c >= 0xe0:
+		return "int"
+	case c >= 0xc4 && c <= 0xc6:
+		return "bin"
+	}
+	return "ext"
+}
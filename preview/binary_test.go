@@ -0,0 +1,58 @@
+package preview
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreview_Protobuf(t *testing.T) {
+	// field 1 varint 150, field 2 string "hello", field 3 fixed32, field 4 fixed64
+	in := []byte{
+		0x08, 0x96, 0x01,
+		0x12, 0x05, 'h', 'e', 'l', 'l', 'o',
+		0x1d, 0x01, 0x02, 0x03, 0x04,
+		0x21, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08,
+	}
+	out := New(WithMaxChars(100), WithBinaryHeuristics(true)).Preview(in)
+	require.Equal(t, "<protobuf 24B> 1:varint 2:bytes[5] 3:fixed32 4:fixed64", out)
+
+	t.Log("truncated by max inspect")
+	out = New(WithMaxChars(100), WithBinaryHeuristics(true), WithMaxInspect(8)).Preview(in)
+	require.Equal(t, "<protobuf 8B> 1:varint …", out)
+}
+
+func TestPreview_Msgpack(t *testing.T) {
+	// {"id": 1, "name": "ab", "tags": ["x"], "meta": {"k": nil}}
+	in := []byte{
+		0x84,
+		0xa2, 'i', 'd', 0x01,
+		0xa4, 'n', 'a', 'm', 'e', 0xa2, 'a', 'b',
+		0xa4, 't', 'a', 'g', 's', 0x91, 0xa1, 'x',
+		0xa4, 'm', 'e', 't', 'a', 0x81, 0xa1, 'k', 0xc0,
+	}
+	out := New(WithMaxChars(100), WithBinaryHeuristics(true)).Preview(in)
+	require.Equal(t, "<msgpack 30B map 4> {id, name, tags, meta}", out)
+
+	t.Log("truncated by max inspect")
+	out = New(WithMaxChars(100), WithBinaryHeuristics(true), WithMaxInspect(14)).Preview(in)
+	require.Equal(t, "<msgpack 14B map 4> {id, name, …}", out)
+
+	t.Log("array")
+	out = PreviewWithHint([]byte{0x92, 0x01, 0xc3}, "application/msgpack")
+	require.Equal(t, "<msgpack 3B array 2>", out)
+}
+
+func TestPreview_BinaryHeuristicsFallback(t *testing.T) {
+	t.Log("not protobuf or msgpack")
+	in := []byte{0xff, 0xfe, 0x00, 0x07}
+	out := New(WithBinaryHeuristics(true)).Preview(in)
+	require.True(t, strings.HasPrefix(out, "<binary 4B>"), "expected binary preview, got %q", out)
+
+	t.Log("heuristics disabled by default")
+	out = New().Preview([]byte{0x08, 0x96, 0x01})
+	require.Equal(t, "<binary 3B> 089601", out)
+	out = Preview([]byte{0x08, 0x96, 0x01}, testMaxChars, testMaxInspect)
+	require.Equal(t, "<binary 3B> 089601", out)
+}
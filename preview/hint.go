@@ -0,0 +1,70 @@
+package preview
+
+import (
+	"mime"
+	"strings"
+)
+
+// format is the preview strategy of a message.
+type format int
+
+const (
+	formatUnknown format = iota // guessed from the message bytes
+	formatText
+	formatJSON
+	formatXML
+	formatBinary
+	formatProtobuf
+	formatMsgpack
+)
+
+// PreviewWithHint is like Previewer.PreviewWithHint, using a Previewer
+// created with opts, or the default Previewer without opts.
+func PreviewWithHint(b []byte, contentType string, opts ...Option) string {
+	p := Default()
+	if len(opts) > 0 {
+		p = New(opts...)
+	}
+	return p.PreviewWithHint(b, contentType)
+}
+
+// PreviewWithHint generates a preview string for b using the MIME type
+// contentType (e.g. a Content-Type header) to choose the preview strategy
+// rather than guessing it from the bytes:
+//   - JSON (application/json, */*+json) is compacted onto a single line
+//   - XML (text/xml, application/xml, */*+xml) has whitespace between tags
+//     removed
+//   - Other text/* types are previewed as plain text
+//   - application/octet-stream, protobuf and msgpack types are previewed as
+//     binary, summarizing the structure of protobuf and msgpack types when
+//     recognized (and of application/octet-stream with WithBinaryHeuristics)
+//
+// Invalid UTF-8 in text messages is replaced with the Unicode replacement
+// character. Unknown or empty content types fall back to Preview.
+func (p *Previewer) PreviewWithHint(b []byte, contentType string) string {
+	return p.preview(b, formatOf(contentType))
+}
+
+func formatOf(contentType string) format {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return formatUnknown
+	}
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return formatJSON
+	case mediaType == "text/xml" || mediaType == "application/xml" || strings.HasSuffix(mediaType, "+xml"):
+		return formatXML
+	case strings.HasPrefix(mediaType, "text/"):
+		return formatText
+	}
+	switch mediaType {
+	case "application/octet-stream":
+		return formatBinary
+	case "application/x-protobuf", "application/protobuf", "application/vnd.google.protobuf":
+		return formatProtobuf
+	case "application/msgpack", "application/x-msgpack", "application/vnd.msgpack":
+		return formatMsgpack
+	}
+	return formatUnknown
+}
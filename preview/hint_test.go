@@ -0,0 +1,74 @@
+package preview
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreviewWithHint(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		in          []byte
+		want        string
+	}{
+		{
+			name:        "json",
+			contentType: "application/json; charset=utf-8",
+			in:          []byte("{\n  \"a\": 1\n}"),
+			want:        `{"a":1}`,
+		},
+		{
+			name:        "json suffix scalar",
+			contentType: "application/problem+json",
+			in:          []byte(`  "quoted"  `),
+			want:        `"quoted"`,
+		},
+		{
+			name:        "xml",
+			contentType: "text/xml",
+			in:          []byte("<a>\n  <b>text here</b>\n</a>"),
+			want:        "<a><b>text here</b></a>",
+		},
+		{
+			name:        "text not guessed as json",
+			contentType: "text/plain",
+			in:          []byte("[\n1]"),
+			want:        "[ 1]",
+		},
+		{
+			name:        "text with control characters",
+			contentType: "text/plain",
+			in:          []byte{0x01, 0x02, 'a', 0xff},
+			want:        "\x01\x02a�",
+		},
+		{
+			name:        "octet stream",
+			contentType: "application/octet-stream",
+			in:          []byte("hello"),
+			want:        "<binary 5B> 68656c6c6f",
+		},
+		{
+			name:        "protobuf",
+			contentType: "application/x-protobuf",
+			in:          []byte{0x08, 0x01},
+			want:        "<protobuf 2B> 1:varint",
+		},
+		{
+			name:        "unknown falls back to guessing",
+			contentType: "",
+			in:          []byte("{\n  \"a\": 1\n}"),
+			want:        `{"a":1}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, PreviewWithHint(tt.in, tt.contentType))
+		})
+	}
+}
+
+func TestPreviewWithHint_Options(t *testing.T) {
+	require.Equal(t, `{"a…`, PreviewWithHint([]byte(`{"a": 1}`), "application/json", WithMaxChars(4)))
+}
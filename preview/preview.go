@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"unicode"
 	"unicode/utf8"
 )
@@ -20,30 +21,182 @@ var bufPool = sync.Pool{
 	New: func() any { return new(bytes.Buffer) },
 }
 
+// Option configures a Previewer.
+type Option func(opts *options)
+
+// WithMaxChars sets the maximum number of runes of a preview, including the
+// trailing ellipsis of truncated previews. Defaults to DefaultMaxChars.
+func WithMaxChars(n int) Option {
+	return func(opts *options) {
+		opts.maxChars = n
+	}
+}
+
+// WithMaxInspect caps the number of bytes inspected per message. Zero or less
+// inspects entire messages. Defaults to DefaultMaxInspect.
+func WithMaxInspect(n int) Option {
+	return func(opts *options) {
+		opts.maxInspect = n
+	}
+}
+
+// WithJSONCompaction sets whether JSON objects and arrays are compacted onto a
+// single line. Enabled by default.
+func WithJSONCompaction(enabled bool) Option {
+	return func(opts *options) {
+		opts.compactJSON = enabled
+	}
+}
+
+// WithWhitespaceCollapse sets whether runs of whitespace (including newlines)
+// are collapsed into single spaces. Enabled by default.
+func WithWhitespaceCollapse(enabled bool) Option {
+	return func(opts *options) {
+		opts.collapseWhitespace = enabled
+	}
+}
+
+// WithBinaryDetection sets whether messages that are not valid UTF-8 or mostly
+// non-printable are previewed as binary (size and hex head). When disabled,
+// invalid UTF-8 is replaced with the Unicode replacement character. Enabled by
+// default.
+func WithBinaryDetection(enabled bool) Option {
+	return func(opts *options) {
+		opts.detectBinary = enabled
+	}
+}
+
+// WithBinaryHeuristics sets whether binary messages that look like protobuf
+// wire format or msgpack maps and arrays are previewed as a summary of their
+// structure (field numbers and wire types, or top-level map keys) rather than
+// a hex head. Detection is best-effort. Messages hinted as protobuf or msgpack
+// are always summarized. Disabled by default.
+func WithBinaryHeuristics(enabled bool) Option {
+	return func(opts *options) {
+		opts.binaryHeuristics = enabled
+	}
+}
+
+type options struct {
+	maxChars           int
+	maxInspect         int
+	compactJSON        bool
+	collapseWhitespace bool
+	detectBinary       bool
+	binaryHeuristics   bool
+}
+
+// Previewer generates human-friendly preview strings for arbitrary message
+// bytes. It is safe for concurrent use.
+type Previewer struct {
+	opts options
+}
+
+// New creates a Previewer.
+func New(opts ...Option) *Previewer {
+	options := options{
+		maxChars:           DefaultMaxChars,
+		maxInspect:         DefaultMaxInspect,
+		compactJSON:        true,
+		collapseWhitespace: true,
+		detectBinary:       true,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &Previewer{opts: options}
+}
+
+var defaultPreviewer atomic.Pointer[Previewer]
+
+func init() {
+	defaultPreviewer.Store(New())
+}
+
+// Default returns the default Previewer, which is used by Bytes and String.
+func Default() *Previewer {
+	return defaultPreviewer.Load()
+}
+
+// SetDefault makes p the default Previewer.
+func SetDefault(p *Previewer) {
+	defaultPreviewer.Store(p)
+}
+
+// Bytes previews b with the default Previewer.
+func Bytes(b []byte) string {
+	return Default().Preview(b)
+}
+
+// String previews s with the default Previewer.
+func String(s string) string {
+	return Default().Preview([]byte(s))
+}
+
 // Preview generates a human-friendly preview string for arbitrary message bytes.
 // It is designed for high-volume usage:
 //   - Caps work to maxInspect bytes (O(min(n, maxInspect)))
 //   - Avoids []rune allocations for truncation
 //   - Only compacts JSON that starts with '{' or '['
 //   - Uses a buffer pool for JSON compaction
+//
+// It uses the options of the default Previewer other than max chars and max
+// inspect.
+//
+// Deprecated: Use New(WithMaxChars(maxChars), WithMaxInspect(maxInspect))
+// and Previewer.Preview instead.
 func Preview(b []byte, maxChars int, maxInspect int) string {
+	p := *Default()
+	p.opts.maxChars = maxChars
+	p.opts.maxInspect = maxInspect
+	return p.Preview(b)
+}
+
+// Preview generates a preview string for b. It is designed for high-volume
+// usage:
+//   - Caps work to the max inspect bytes (O(min(n, maxInspect)))
+//   - Avoids []rune allocations for truncation
+//   - Only compacts JSON that starts with '{' or '['
+//   - Uses a buffer pool for JSON compaction
+func (p *Previewer) Preview(b []byte) string {
+	return p.preview(b, formatUnknown)
+}
+
+func (p *Previewer) preview(b []byte, f format) string {
+	truncated := p.opts.maxInspect > 0 && len(b) > p.opts.maxInspect
+	if truncated {
+		b = b[:p.opts.maxInspect]
+	}
+	return p.previewInspected(b, truncated, f)
+}
+
+// previewInspected previews b, which is at most max inspect bytes long.
+// Truncated reports whether b is a prefix of the message.
+func (p *Previewer) previewInspected(b []byte, truncated bool, f format) string {
+	maxChars := p.opts.maxChars
 	if maxChars <= 0 || len(b) == 0 {
 		return ""
 	}
-	if maxInspect > 0 && len(b) > maxInspect {
-		b = b[:maxInspect]
+	switch f {
+	case formatBinary, formatProtobuf, formatMsgpack:
+		return p.binaryPreview(b, truncated, f)
 	}
+	// Only guess whether messages are binary without a hint.
+	detectBinary := p.opts.detectBinary && f == formatUnknown
 
 	// If it's not valid UTF-8, treat as binary
 	if !utf8.Valid(b) {
-		return binaryPreview(b, maxChars)
+		if detectBinary {
+			return p.binaryPreview(b, truncated, f)
+		}
+		b = bytes.ToValidUTF8(b, []byte("\uFFFD"))
 	}
 
 	s := string(b)
 
 	// If it has lots of control/non-graphic chars, treat as binary
-	if !looksMostlyPrintable(s) {
-		return binaryPreview(b, maxChars)
+	if detectBinary && !looksMostlyPrintable(s) {
+		return p.binaryPreview(b, truncated, f)
 	}
 
 	// Trim (cheap) and early exit
@@ -54,14 +207,19 @@ func Preview(b []byte, maxChars int, maxInspect int) string {
 
 	// If it looks like JSON try compacting so multiline JSON becomes one line.
 	// Only attempt if it starts with '{' or '[' to reduce pointless work.
-	if looksLikeJSONStart(s) {
+	if p.opts.compactJSON && (f == formatJSON || f == formatUnknown && looksLikeJSONStart(s)) {
 		if compacted, ok := tryCompactJSON(s); ok {
 			s = compacted
 		}
 	}
 
 	// Collapse whitespace/newlines/tabs into single spaces
-	s = collapseWhitespace(s)
+	if p.opts.collapseWhitespace {
+		s = collapseWhitespace(s)
+		if f == formatXML {
+			s = strings.ReplaceAll(s, "> <", "><")
+		}
+	}
 
 	return truncateRunesNoAlloc(s, maxChars)
 }
@@ -193,6 +351,15 @@ func looksMostlyPrintable(s string) bool {
 	return float64(printable)/float64(total) >= 0.85
 }
 
+func (p *Previewer) binaryPreview(b []byte, truncated bool, f format) string {
+	if p.opts.binaryHeuristics || f == formatProtobuf || f == formatMsgpack {
+		if s, ok := structuredBinaryPreview(b, truncated, f); ok {
+			return truncateRunesNoAlloc(s, p.opts.maxChars)
+		}
+	}
+	return binaryPreview(b, p.opts.maxChars)
+}
+
 func binaryPreview(b []byte, maxChars int) string {
 	// Example: "<binary 123B> 0a1b2c3d…"
 	head := 12
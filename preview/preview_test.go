@@ -147,4 +147,63 @@ func TestPreview_RespectsMaxInspect(t *testing.T) {
 	require.NotEmpty(t, out)
 	require.NotEqual(t, "{", out)
 	require.Contains(t, out, `"a":`)
-}
\ No newline at end of file
+}
+
+func TestPreviewer_Options(t *testing.T) {
+	in := []byte("{\n  \"a\": 1\n}\n\nnot json")
+
+	tests := []struct {
+		name string
+		opts []Option
+		in   []byte
+		want string
+	}{
+		{
+			name: "defaults",
+			in:   []byte("{\n  \"a\": 1,\n  \"b\": [1, 2]\n}"),
+			want: `{"a":1,"b":[1,2]}`,
+		},
+		{
+			name: "max chars",
+			opts: []Option{WithMaxChars(5)},
+			in:   []byte("hello world"),
+			want: "hell…",
+		},
+		{
+			name: "no whitespace collapse",
+			opts: []Option{WithWhitespaceCollapse(false), WithJSONCompaction(false)},
+			in:   in,
+			want: "{\n  \"a\": 1\n}\n\nnot json",
+		},
+		{
+			name: "no json compaction",
+			opts: []Option{WithJSONCompaction(false)},
+			in:   []byte("{\n  \"a\": 1\n}"),
+			want: `{ "a": 1 }`,
+		},
+		{
+			name: "no binary detection",
+			opts: []Option{WithBinaryDetection(false)},
+			in:   []byte{'a', 0xff, 'b'},
+			want: "a�b",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, New(tt.opts...).Preview(tt.in))
+		})
+	}
+}
+
+func TestDefault(t *testing.T) {
+	require.Equal(t, "hello world", String("hello\nworld"))
+
+	orig := Default()
+	defer SetDefault(orig)
+	SetDefault(New(WithMaxChars(3)))
+	require.Equal(t, "he…", Bytes([]byte("hello")))
+
+	// Preview uses the options of the default other than its limits.
+	SetDefault(New(WithWhitespaceCollapse(false)))
+	require.Equal(t, "hello\nworld", Preview([]byte("hello\nworld"), testMaxChars, testMaxInspect))
+}
@@ -0,0 +1,45 @@
+package preview
+
+import (
+	"bytes"
+	"io"
+)
+
+// PreviewReader is like Preview but reads the message from r, reading at most
+// maxInspect bytes. Zero or less maxInspect reads r until EOF.
+func PreviewReader(r io.Reader, maxChars int, maxInspect int) (string, error) {
+	return New(WithMaxChars(maxChars), WithMaxInspect(maxInspect)).PreviewReader(r)
+}
+
+// PreviewReader generates a preview string for the message read from r
+// without buffering more than the max inspect bytes, e.g. to preview HTTP or
+// NATS bodies. Use io.TeeReader to preview a stream that is read elsewhere.
+// Messages filling the max inspect bytes are assumed to be truncated.
+func (p *Previewer) PreviewReader(r io.Reader) (string, error) {
+	return p.previewReader(r, formatUnknown)
+}
+
+// PreviewReaderWithHint is like PreviewReader, choosing the preview strategy
+// by contentType as in PreviewWithHint.
+func (p *Previewer) PreviewReaderWithHint(r io.Reader, contentType string) (string, error) {
+	return p.previewReader(r, formatOf(contentType))
+}
+
+func (p *Previewer) previewReader(r io.Reader, f format) (string, error) {
+	if p.opts.maxChars <= 0 {
+		return "", nil
+	}
+
+	buf := bufPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer bufPool.Put(buf)
+
+	if p.opts.maxInspect > 0 {
+		r = io.LimitReader(r, int64(p.opts.maxInspect))
+	}
+	if _, err := buf.ReadFrom(r); err != nil {
+		return "", err
+	}
+	truncated := p.opts.maxInspect > 0 && buf.Len() == p.opts.maxInspect
+	return p.previewInspected(buf.Bytes(), truncated, f), nil
+}
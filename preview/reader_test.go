@@ -0,0 +1,40 @@
+package preview
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreviewReader(t *testing.T) {
+	out, err := PreviewReader(strings.NewReader("{\n  \"a\": 1\n}"), testMaxChars, testMaxInspect)
+	require.NoError(t, err)
+	require.Equal(t, `{"a":1}`, out)
+}
+
+func TestPreviewReader_ReadsAtMostMaxInspect(t *testing.T) {
+	r := strings.NewReader("hello world")
+	out, err := PreviewReader(r, testMaxChars, 5)
+	require.NoError(t, err)
+	require.Equal(t, "hello", out)
+
+	rest, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, " world", string(rest))
+}
+
+func TestPreviewReader_Error(t *testing.T) {
+	errRead := errors.New("read failed")
+	_, err := PreviewReader(iotest.ErrReader(errRead), testMaxChars, testMaxInspect)
+	require.ErrorIs(t, err, errRead)
+}
+
+func TestPreviewer_PreviewReaderWithHint(t *testing.T) {
+	out, err := New().PreviewReaderWithHint(strings.NewReader("<a>\n  <b/>\n</a>"), "application/xml")
+	require.NoError(t, err)
+	require.Equal(t, "<a><b/></a>", out)
+}
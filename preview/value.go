@@ -0,0 +1,31 @@
+package preview
+
+import "log/slog"
+
+// Value returns a slog.LogValuer previewing b with the default Previewer. The
+// preview is only computed when a record with the value is emitted, so no work
+// is done for records filtered out by level or sampling. The default Previewer
+// is resolved when the value is logged.
+func Value(b []byte) slog.LogValuer {
+	return lazyValue{b: b}
+}
+
+// Value is like the package-level Value, previewing b with p.
+func (p *Previewer) Value(b []byte) slog.LogValuer {
+	return lazyValue{p: p, b: b}
+}
+
+// lazyValue must not outlive modifications of b, as handlers may resolve it
+// after the log call returns.
+type lazyValue struct {
+	p *Previewer // nil for the default Previewer
+	b []byte
+}
+
+func (v lazyValue) LogValue() slog.Value {
+	p := v.p
+	if p == nil {
+		p = Default()
+	}
+	return slog.StringValue(p.Preview(v.b))
+}
@@ -0,0 +1,25 @@
+package preview
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValue(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+
+	logger.Info("message", "preview", Value([]byte("{\n  \"a\": 1\n}")))
+	logger.Info("message", "preview", New(WithMaxChars(4)).Value([]byte("hello")))
+	require.Equal(t, "level=INFO msg=message preview=\"{\\\"a\\\":1}\"\nlevel=INFO msg=message preview=hel…\n", buf.String())
+}
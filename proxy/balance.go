@@ -0,0 +1,129 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	"github.com/joshjon/kit/clock"
+)
+
+const (
+	defaultEjectAfterFailures = 5
+	defaultEjectDuration      = 30 * time.Second
+)
+
+// BalanceStrategy selects the target of each request when proxying to
+// multiple targets.
+type BalanceStrategy int
+
+const (
+	// RoundRobin cycles through targets in order.
+	RoundRobin BalanceStrategy = iota
+	// LeastConnections picks the target with the fewest in-flight requests,
+	// falling back to round-robin order between equally loaded targets.
+	LeastConnections
+)
+
+// WithBalanceStrategy sets how requests are balanced across multiple targets.
+// Defaults to RoundRobin.
+func WithBalanceStrategy(strategy BalanceStrategy) ReverseProxyOption {
+	return func(opts *reverseProxyOptions) {
+		opts.strategy = strategy
+	}
+}
+
+// WithPassiveHealth ejects a target from balancing for ejectFor once
+// failures consecutive requests to it failed with a connection error or a
+// 502, 503 or 504 response. When every target is ejected requests are
+// balanced across all targets. Defaults to 5 failures and 30s. A zero
+// failures disables ejection.
+func WithPassiveHealth(failures int, ejectFor time.Duration) ReverseProxyOption {
+	return func(opts *reverseProxyOptions) {
+		opts.ejectAfter = failures
+		opts.ejectFor = ejectFor
+	}
+}
+
+// upstream is a single proxy target.
+type upstream struct {
+	target   *url.URL
+	proxy    *httputil.ReverseProxy
+	ws       *WebSocketProxyHandler
+	inflight atomic.Int64
+	failures atomic.Int64
+	ejected  atomic.Int64 // unix nanoseconds until which the target is ejected
+}
+
+func (u *upstream) available(now int64) bool {
+	return u.ejected.Load() <= now
+}
+
+// balancer picks upstreams for requests and tracks their passive health.
+type balancer struct {
+	upstreams  []*upstream
+	strategy   BalanceStrategy
+	ejectAfter int64
+	ejectFor   time.Duration
+	clock      clock.Clock
+	next       atomic.Uint64
+}
+
+func (b *balancer) pick() *upstream {
+	if len(b.upstreams) == 1 {
+		return b.upstreams[0]
+	}
+
+	now := b.clock.Now().UnixNano()
+	start := int(b.next.Add(1) % uint64(len(b.upstreams)))
+
+	var picked *upstream
+	for i := range b.upstreams {
+		u := b.upstreams[(start+i)%len(b.upstreams)]
+		if !u.available(now) {
+			continue
+		}
+		if b.strategy == RoundRobin {
+			return u
+		}
+		if picked == nil || u.inflight.Load() < picked.inflight.Load() {
+			picked = u
+		}
+	}
+	if picked == nil {
+		// Every target is ejected, so balance across all of them rather than
+		// failing every request.
+		return b.upstreams[start]
+	}
+	return picked
+}
+
+// observe records the outcome of a request sent to u.
+func (b *balancer) observe(u *upstream, failed bool) {
+	if !failed {
+		u.failures.Store(0)
+		return
+	}
+	if b.ejectAfter <= 0 || len(b.upstreams) == 1 {
+		return
+	}
+	if u.failures.Add(1) >= b.ejectAfter {
+		u.failures.Store(0)
+		u.ejected.Store(b.clock.Now().Add(b.ejectFor).UnixNano())
+	}
+}
+
+// healthTransport reports the outcome of every request sent to an upstream.
+type healthTransport struct {
+	base     http.RoundTripper
+	upstream *upstream
+	balancer *balancer
+}
+
+func (t *healthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := t.base.RoundTrip(req)
+	t.balancer.observe(t.upstream, retryCause(req.Context(), res, err) != nil)
+	return res, err
+}
@@ -0,0 +1,118 @@
+package proxy
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/clock"
+	"github.com/joshjon/kit/testutil"
+)
+
+func TestReverseProxyHandler_roundRobin(t *testing.T) {
+	a := namedServer("a", http.StatusOK)
+	defer a.Close()
+	b := namedServer("b", http.StatusOK)
+	defer b.Close()
+
+	h, err := NewReverseProxyHandler(http.DefaultClient, a.URL, WithTargets(b.URL))
+	require.NoError(t, err)
+	e := echo.New()
+	h.Register(e.Group("/api"))
+
+	got := map[string]int{}
+	for range 10 {
+		got[serve(e, "/api/x")]++
+	}
+	assert.Equal(t, map[string]int{"a": 5, "b": 5}, got)
+}
+
+func TestReverseProxyHandler_passiveHealth(t *testing.T) {
+	healthy := namedServer("healthy", http.StatusOK)
+	defer healthy.Close()
+	failing := namedServer("failing", http.StatusServiceUnavailable)
+	defer failing.Close()
+
+	h, err := NewReverseProxyHandler(http.DefaultClient, healthy.URL,
+		WithTargets(failing.URL),
+		WithPassiveHealth(2, time.Hour),
+	)
+	require.NoError(t, err)
+	e := echo.New()
+	h.Register(e.Group("/api"))
+
+	got := map[string]int{}
+	for range 20 {
+		got[serve(e, "/api/x")]++
+	}
+	// The failing target is ejected after 2 failures.
+	assert.Equal(t, 2, got["failing"])
+	assert.Equal(t, 18, got["healthy"])
+}
+
+func TestBalancer_allEjected(t *testing.T) {
+	b := &balancer{
+		upstreams:  []*upstream{{}, {}},
+		ejectAfter: 1,
+		ejectFor:   time.Hour,
+		clock:      clock.Real,
+	}
+	b.observe(b.upstreams[0], true)
+	b.observe(b.upstreams[1], true)
+	assert.NotNil(t, b.pick())
+}
+
+func TestBalancer_ejectionExpires(t *testing.T) {
+	clk := testutil.NewFakeClock(time.Now())
+	b := &balancer{
+		upstreams:  []*upstream{{}, {}},
+		ejectAfter: 1,
+		ejectFor:   time.Minute,
+		clock:      clk,
+	}
+	b.observe(b.upstreams[0], true)
+	for range 4 {
+		assert.Same(t, b.upstreams[1], b.pick())
+	}
+
+	clk.Advance(time.Minute)
+	picked := map[*upstream]int{}
+	for range 4 {
+		picked[b.pick()]++
+	}
+	assert.Equal(t, 2, picked[b.upstreams[0]])
+	assert.Equal(t, 2, picked[b.upstreams[1]])
+}
+
+func TestBalancer_leastConnections(t *testing.T) {
+	b := &balancer{
+		upstreams: []*upstream{{}, {}, {}},
+		strategy:  LeastConnections,
+		clock:     clock.Real,
+	}
+	b.upstreams[0].inflight.Store(3)
+	b.upstreams[1].inflight.Store(1)
+	b.upstreams[2].inflight.Store(2)
+	for range 5 {
+		assert.Same(t, b.upstreams[1], b.pick())
+	}
+}
+
+func namedServer(name string, status int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, name)
+	}))
+}
+
+func serve(e *echo.Echo, path string) string {
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	return rec.Body.String()
+}
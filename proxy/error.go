@@ -0,0 +1,79 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/joshjon/kit/errtag"
+	"github.com/joshjon/kit/log"
+)
+
+// ErrorHandlerFunc maps an error proxying a request to the named upstream to
+// the error returned from the handler, which the server error handler renders
+// as a response. Returning nil leaves the response as is.
+type ErrorHandlerFunc func(req *http.Request, upstream string, err error) error
+
+// WithErrorHandler sets how upstream errors are reported to clients. Defaults
+// to DefaultErrorHandler.
+func WithErrorHandler(fn ErrorHandlerFunc) ReverseProxyOption {
+	return func(opts *reverseProxyOptions) {
+		opts.errorHandler = fn
+	}
+}
+
+// WithName sets the upstream name used in logs and errors. Defaults to the
+// host of the apiURL of the handler.
+func WithName(name string) ReverseProxyOption {
+	return func(opts *reverseProxyOptions) {
+		opts.name = name
+	}
+}
+
+// WithLogger sets the Logger used to log upstream errors.
+func WithLogger(logger log.Logger) ReverseProxyOption {
+	return func(opts *reverseProxyOptions) {
+		opts.logger = logger
+	}
+}
+
+// DefaultErrorHandler tags upstream timeouts with errtag.GatewayTimeout and
+// any other upstream error with errtag.Unavailable, with the upstream name as
+// a field. Requests canceled by the client are not reported.
+func DefaultErrorHandler(req *http.Request, upstream string, err error) error {
+	if context.Cause(req.Context()) == context.Canceled {
+		return nil
+	}
+	err = fmt.Errorf("proxy to upstream %s: %w", upstream, err)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return errtag.Tag[errtag.GatewayTimeout](err, errtag.WithField("upstream", upstream))
+	}
+	return errtag.Tag[errtag.Unavailable](err, errtag.WithField("upstream", upstream))
+}
+
+func captureProxyError(w http.ResponseWriter, req *http.Request, err error) {
+	if state, ok := getRequestState(req); ok {
+		state.err = err
+		return
+	}
+	w.WriteHeader(http.StatusBadGateway)
+}
+
+func (h *ReverseProxyHandler) handleProxyError(req *http.Request, committed bool, u *upstream, err error) error {
+	level := slog.LevelError
+	if context.Cause(req.Context()) == context.Canceled {
+		level = slog.LevelDebug
+	}
+	h.logger.Log(req.Context(), level, "upstream request failed",
+		"target", u.target.Host,
+		"method", req.Method,
+		"uri", req.URL.RequestURI(),
+		"error", err.Error(),
+	)
+	if committed {
+		return nil
+	}
+	return h.opts.errorHandler(req, h.opts.name, err)
+}
@@ -0,0 +1,90 @@
+package proxy
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/errtag"
+	"github.com/joshjon/kit/log"
+	"github.com/joshjon/kit/testutil"
+)
+
+var nopLogger = log.NewLogger(log.WithNop())
+
+func TestReverseProxyHandler_errors(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer slow.Close()
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	t.Run("unavailable", func(t *testing.T) {
+		logger := testutil.NewRecordingLogger()
+		h, err := NewReverseProxyHandler(http.DefaultClient, closed.URL, WithName("users"), WithLogger(logger))
+		require.NoError(t, err)
+
+		rec := httptest.NewRecorder()
+		err = h.Handle(echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/a", nil), rec))
+		tag, ok := errtag.AsTag[errtag.Unavailable](err)
+		require.True(t, ok)
+		assert.Equal(t, map[string]any{"upstream": "users"}, tag.Fields())
+		assert.False(t, rec.Flushed)
+		assert.Empty(t, rec.Body.String(), "proxy must not write the default error response")
+		logger.AssertLogged(t, slog.LevelError, "upstream request failed",
+			"upstream", "users",
+			"target", closed.Listener.Addr().String(),
+			"method", http.MethodGet,
+		)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		h, err := NewReverseProxyHandler(&http.Client{}, slow.URL, WithLogger(nopLogger))
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		req := httptest.NewRequestWithContext(ctx, http.MethodGet, "/a", nil)
+		err = h.Handle(echo.New().NewContext(req, httptest.NewRecorder()))
+		assert.True(t, errtag.HasTag[errtag.GatewayTimeout](err))
+	})
+
+	t.Run("client canceled", func(t *testing.T) {
+		logger := testutil.NewRecordingLogger()
+		h, err := NewReverseProxyHandler(&http.Client{}, slow.URL, WithLogger(logger))
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(10*time.Millisecond, cancel)
+		req := httptest.NewRequestWithContext(ctx, http.MethodGet, "/a", nil)
+		assert.NoError(t, h.Handle(echo.New().NewContext(req, httptest.NewRecorder())))
+		logger.AssertLogged(t, slog.LevelDebug, "upstream request failed")
+		logger.AssertNotLogged(t, slog.LevelError, "upstream request failed")
+	})
+
+	t.Run("custom handler", func(t *testing.T) {
+		h, err := NewReverseProxyHandler(http.DefaultClient, closed.URL, WithLogger(nopLogger),
+			WithErrorHandler(func(_ *http.Request, upstream string, err error) error {
+				return echo.NewHTTPError(http.StatusTeapot, upstream)
+			}),
+		)
+		require.NoError(t, err)
+
+		err = h.Handle(echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/a", nil), httptest.NewRecorder()))
+		var herr *echo.HTTPError
+		require.ErrorAs(t, err, &herr)
+		assert.Equal(t, http.StatusTeapot, herr.Code)
+		assert.Equal(t, closed.Listener.Addr().String(), herr.Message)
+	})
+}
@@ -1,23 +1,206 @@
 package proxy
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"time"
 
 	"github.com/labstack/echo/v4"
+
+	"github.com/joshjon/kit/clock"
+	"github.com/joshjon/kit/log"
 )
 
+// ReverseProxyOption optionally configures a ReverseProxyHandler.
+type ReverseProxyOption func(opts *reverseProxyOptions)
+
+// WithStripPrefix strips the prefix the handler is registered under from
+// request paths, so a handler registered at /api proxies /api/users to
+// /users of the target URL.
+func WithStripPrefix() ReverseProxyOption {
+	return func(opts *reverseProxyOptions) {
+		opts.stripPrefix = true
+	}
+}
+
+// WithRewrite rewrites request paths matching the wildcard template from to
+// the template to, e.g. WithRewrite("/api/v1/*", "/v1/*"). Each * in from
+// matches any sequence of characters and each * in to is replaced with the
+// corresponding match. Rewrites are applied after WithStripPrefix, in the
+// order they are added, and only the first matching rewrite is applied.
+func WithRewrite(from string, to string) ReverseProxyOption {
+	return func(opts *reverseProxyOptions) {
+		opts.rewriteSpecs = append(opts.rewriteSpecs, rewriteSpec{from: from, to: to})
+	}
+}
+
+// WithRegexRewrite is like WithRewrite but matches request paths with a
+// regular expression, with replacement expanded as in
+// regexp.Regexp.ReplaceAllString.
+func WithRegexRewrite(pattern string, replacement string) ReverseProxyOption {
+	return func(opts *reverseProxyOptions) {
+		opts.rewriteSpecs = append(opts.rewriteSpecs, rewriteSpec{from: pattern, to: replacement, regex: true})
+	}
+}
+
+// WithTargets adds targets to balance requests across along with the apiURL
+// of the handler. Targets must be absolute http(s) URLs.
+func WithTargets(apiURLs ...string) ReverseProxyOption {
+	return func(opts *reverseProxyOptions) {
+		opts.targets = append(opts.targets, apiURLs...)
+	}
+}
+
+// WithClock sets the clock used for retry backoff and budgets, passive health
+// ejection and metrics. Defaults to clock.Real.
+func WithClock(clk clock.Clock) ReverseProxyOption {
+	return func(opts *reverseProxyOptions) {
+		opts.clock = clk
+	}
+}
+
+type reverseProxyOptions struct {
+	stripPrefix   bool
+	rewriteSpecs  []rewriteSpec
+	retry         *RetryPolicy
+	targets       []string
+	strategy      BalanceStrategy
+	ejectAfter    int
+	ejectFor      time.Duration
+	errorHandler  ErrorHandlerFunc
+	name          string
+	logger        log.Logger
+	flushInterval time.Duration
+	timeout       time.Duration
+
+	modifyRequest     ModifyRequestFunc
+	modifyResponse    ModifyResponseFunc
+	maxModifyBodySize int64
+
+	metrics Metrics
+	clock   clock.Clock
+
+	webSocketOpts []WebSocketOption
+}
+
+type rewriteSpec struct {
+	from  string
+	to    string
+	regex bool
+}
+
+// ReverseProxyHandler proxies requests to a downstream API, balancing them
+// across targets when configured with WithTargets. WebSocket upgrade requests
+// are proxied by a WebSocketProxyHandler.
 type ReverseProxyHandler struct {
-	client *http.Client
-	apiURL string
+	balancer *balancer
+	opts     reverseProxyOptions
+	rewrites []rewriteRule
+	logger   log.Logger
 }
 
-func NewReverseProxyHandler(client *http.Client, apiURL string) *ReverseProxyHandler {
-	return &ReverseProxyHandler{
-		client: client,
-		apiURL: apiURL,
+// NewReverseProxyHandler creates a ReverseProxyHandler for the downstream API
+// at apiURL, which must be an absolute http(s) URL.
+func NewReverseProxyHandler(client *http.Client, apiURL string, opts ...ReverseProxyOption) (*ReverseProxyHandler, error) {
+	options := reverseProxyOptions{
+		ejectAfter:        defaultEjectAfterFailures,
+		ejectFor:          defaultEjectDuration,
+		errorHandler:      DefaultErrorHandler,
+		logger:            log.NewLogger(),
+		maxModifyBodySize: defaultMaxModifyBodySize,
+		clock:             clock.Real,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	var rewrites []rewriteRule
+	for _, spec := range options.rewriteSpecs {
+		var rule rewriteRule
+		var err error
+		if spec.regex {
+			rule, err = newRegexRewrite(spec.from, spec.to)
+		} else {
+			rule, err = newWildcardRewrite(spec.from, spec.to)
+		}
+		if err != nil {
+			return nil, err
+		}
+		rewrites = append(rewrites, rule)
 	}
+
+	h := &ReverseProxyHandler{
+		opts:     options,
+		rewrites: rewrites,
+	}
+	b := &balancer{
+		strategy:   options.strategy,
+		ejectAfter: int64(options.ejectAfter),
+		ejectFor:   options.ejectFor,
+		clock:      options.clock,
+	}
+
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	var retry *retryTransport
+	if options.retry != nil {
+		retry = newRetryTransport(base, *options.retry, options.clock)
+	}
+
+	for _, rawURL := range append([]string{apiURL}, options.targets...) {
+		targetURL, err := parseTargetURL(rawURL)
+		if err != nil {
+			return nil, err
+		}
+		ws, err := NewWebSocketProxyHandler(client, rawURL, options.webSocketOpts...)
+		if err != nil {
+			return nil, err
+		}
+
+		u := &upstream{
+			target: targetURL,
+			proxy:  httputil.NewSingleHostReverseProxy(targetURL),
+			ws:     ws,
+		}
+		var transport http.RoundTripper = &healthTransport{base: base, upstream: u, balancer: b}
+		if retry != nil {
+			// Targets share the retry budget of the downstream.
+			transport = retry.withBase(transport)
+		}
+		u.proxy.Transport = transport
+		u.proxy.ErrorHandler = captureProxyError
+		u.proxy.FlushInterval = options.flushInterval
+		u.proxy.ModifyResponse = func(res *http.Response) error {
+			stopStreamingTimeout(res)
+			prepareStreamingResponse(res)
+			return h.modifyResponse(res)
+		}
+		b.upstreams = append(b.upstreams, u)
+	}
+	if options.name == "" {
+		options.name = b.upstreams[0].target.Host
+	}
+
+	h.balancer = b
+	h.opts.name = options.name
+	h.logger = options.logger.With("upstream", options.name)
+	return h, nil
+}
+
+func parseTargetURL(apiURL string) (*url.URL, error) {
+	targetURL, err := url.Parse(apiURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse target url: %w", err)
+	}
+	if (targetURL.Scheme != "http" && targetURL.Scheme != "https") || targetURL.Host == "" {
+		return nil, fmt.Errorf("invalid target url %q: must be an absolute http(s) url", apiURL)
+	}
+	return targetURL, nil
 }
 
 func (h *ReverseProxyHandler) Register(g *echo.Group) {
@@ -25,14 +208,61 @@ func (h *ReverseProxyHandler) Register(g *echo.Group) {
 }
 
 func (h *ReverseProxyHandler) Handle(c echo.Context) error {
-	targetURL, err := url.Parse(h.apiURL)
+	req := h.rewriteRequest(c)
+	u := h.balancer.pick()
+	u.inflight.Add(1)
+	defer u.inflight.Add(-1)
+
+	if h.opts.metrics == nil {
+		return h.serve(c, req, u, c.Response().Writer)
+	}
+
+	target := u.target.Host
+	h.opts.metrics.AddInFlight(h.opts.name, target, 1)
+	defer h.opts.metrics.AddInFlight(h.opts.name, target, -1)
+
+	start := h.opts.clock.Now()
+	w := &statusWriter{ResponseWriter: c.Response().Writer}
+	err := h.serve(c, req, u, w)
+	h.opts.metrics.ObserveRequest(h.opts.name, target, req.Method, w.status, h.opts.clock.Since(start))
+	return err
+}
+
+func (h *ReverseProxyHandler) serve(c echo.Context, req *http.Request, u *upstream, w http.ResponseWriter) error {
+	if c.IsWebSocket() {
+		orig := c.Request()
+		origWriter := c.Response().Writer
+		c.SetRequest(req)
+		c.Response().Writer = w
+		defer func() {
+			c.SetRequest(orig)
+			c.Response().Writer = origWriter
+		}()
+		return u.ws.Handle(c)
+	}
+	req, err := h.modifyRequest(req)
 	if err != nil {
-		return c.String(http.StatusInternalServerError, "Bad target URL")
+		return err
 	}
 
-	// Create a reverse proxy that directs requests to the downstream API
-	proxy := httputil.NewSingleHostReverseProxy(targetURL)
-	proxy.Transport = h.client.Transport
-	proxy.ServeHTTP(c.Response().Writer, c.Request())
+	req, state, cancel := withRequestState(req, h.opts.timeout)
+	defer func() {
+		if state.timeout != nil {
+			state.timeout.Stop()
+		}
+		cancel(nil)
+	}()
+
+	u.proxy.ServeHTTP(w, req)
+	if state.modifyErr != nil && !c.Response().Committed {
+		return state.modifyErr
+	}
+	if state.err != nil {
+		err := state.err
+		if cause := context.Cause(req.Context()); cause == ErrUpstreamTimeout {
+			err = fmt.Errorf("%w: %w", ErrUpstreamTimeout, err)
+		}
+		return h.handleProxyError(req, c.Response().Committed, u, err)
+	}
 	return nil
 }
@@ -0,0 +1,132 @@
+package proxy
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewReverseProxyHandler_invalidURL(t *testing.T) {
+	for _, apiURL := range []string{"", "::", "/relative", "ftp://host", "http://"} {
+		_, err := NewReverseProxyHandler(http.DefaultClient, apiURL)
+		assert.Error(t, err, apiURL)
+	}
+}
+
+func TestReverseProxyHandler(t *testing.T) {
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.Method+" "+r.URL.RequestURI())
+	}))
+	defer downstream.Close()
+
+	h, err := NewReverseProxyHandler(http.DefaultClient, downstream.URL+"/base")
+	require.NoError(t, err)
+
+	e := echo.New()
+	h.Register(e.Group("/api"))
+
+	for _, path := range []string{"/api/a?x=1", "/api/b"} {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "GET /base"+path, rec.Body.String())
+	}
+}
+
+func TestReverseProxyHandler_rewrites(t *testing.T) {
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.URL.RequestURI())
+	}))
+	defer downstream.Close()
+
+	tests := []struct {
+		name string
+		opts []ReverseProxyOption
+		path string
+		want string
+	}{
+		{
+			name: "strip prefix",
+			opts: []ReverseProxyOption{WithStripPrefix()},
+			path: "/api/users?x=1",
+			want: "/users?x=1",
+		},
+		{
+			name: "strip prefix root",
+			opts: []ReverseProxyOption{WithStripPrefix()},
+			path: "/api/",
+			want: "/",
+		},
+		{
+			name: "wildcard",
+			opts: []ReverseProxyOption{WithRewrite("/api/v1/*", "/v1/*")},
+			path: "/api/v1/users/1",
+			want: "/v1/users/1",
+		},
+		{
+			name: "multiple wildcards",
+			opts: []ReverseProxyOption{WithRewrite("/api/*/items/*", "/tenants/*/items/*")},
+			path: "/api/acme/items/7",
+			want: "/tenants/acme/items/7",
+		},
+		{
+			name: "regex",
+			opts: []ReverseProxyOption{WithRegexRewrite(`^/api/users/(\d+)$`, "/people/$1")},
+			path: "/api/users/42",
+			want: "/people/42",
+		},
+		{
+			name: "first match wins after strip prefix",
+			opts: []ReverseProxyOption{
+				WithStripPrefix(),
+				WithRewrite("/v1/*", "/legacy/*"),
+				WithRewrite("/*", "/other/*"),
+			},
+			path: "/api/v1/a",
+			want: "/legacy/a",
+		},
+		{
+			name: "no match",
+			opts: []ReverseProxyOption{WithRewrite("/api/v2/*", "/v2/*")},
+			path: "/api/v1/a",
+			want: "/api/v1/a",
+		},
+		{
+			name: "escaped path",
+			opts: []ReverseProxyOption{WithStripPrefix()},
+			path: "/api/files/a%2Fb",
+			want: "/files/a%2Fb",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, err := NewReverseProxyHandler(http.DefaultClient, downstream.URL, tt.opts...)
+			require.NoError(t, err)
+
+			e := echo.New()
+			h.Register(e.Group("/api"))
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			assert.Equal(t, tt.want, rec.Body.String())
+			assert.Equal(t, tt.path, req.URL.RequestURI(), "original request must not be modified")
+		})
+	}
+}
+
+func TestNewReverseProxyHandler_invalidRewrite(t *testing.T) {
+	for _, opt := range []ReverseProxyOption{
+		WithRewrite("api/*", "/*"),
+		WithRewrite("/api/*", "/*/*"),
+		WithRegexRewrite("(", "/"),
+	} {
+		_, err := NewReverseProxyHandler(http.DefaultClient, "http://localhost", opt)
+		assert.Error(t, err)
+	}
+}
@@ -0,0 +1,160 @@
+package proxy
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Metrics records metrics of requests proxied by a ReverseProxyHandler, from
+// when a target is picked until the response is copied to the client,
+// including retries. Implementations must be safe for concurrent use.
+type Metrics interface {
+	// ObserveRequest records a completed request to the target (host) of the
+	// named upstream. Status is 0 when the request failed without a response
+	// being returned to the client.
+	ObserveRequest(upstream string, target string, method string, status int, latency time.Duration)
+	// AddInFlight adds delta to the number of in-flight requests to the
+	// target of the named upstream.
+	AddInFlight(upstream string, target string, delta int)
+}
+
+// WithMetrics records metrics of proxied requests.
+func WithMetrics(metrics Metrics) ReverseProxyOption {
+	return func(opts *reverseProxyOptions) {
+		opts.metrics = metrics
+	}
+}
+
+// DefaultLatencyBuckets are the upper bounds of the latency histogram buckets
+// used by Stats.
+var DefaultLatencyBuckets = []time.Duration{
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// Stats is an in-memory Metrics implementation.
+type Stats struct {
+	buckets []time.Duration
+
+	mu    sync.Mutex
+	stats map[string]*UpstreamStat
+}
+
+// UpstreamStat holds the recorded metrics of a single upstream.
+type UpstreamStat struct {
+	Requests     int64
+	InFlight     int64
+	StatusCounts map[string]int64 // keyed by status class (e.g. 2xx) or "error"
+	TotalLatency time.Duration
+	// LatencyBuckets counts requests by latency, where LatencyBuckets[i]
+	// counts requests with a latency of at most Buckets[i] and above
+	// Buckets[i-1]. The last count is for latencies above every bucket.
+	LatencyBuckets []int64
+	Buckets        []time.Duration
+}
+
+var _ Metrics = (*Stats)(nil)
+
+// NewStats creates a Stats with a latency histogram of the given bucket upper
+// bounds. Defaults to DefaultLatencyBuckets.
+func NewStats(buckets ...time.Duration) *Stats {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	buckets = slices.Clone(buckets)
+	slices.Sort(buckets)
+	return &Stats{
+		buckets: buckets,
+		stats:   map[string]*UpstreamStat{},
+	}
+}
+
+func (s *Stats) ObserveRequest(upstream string, _ string, _ string, status int, latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stat := s.getLocked(upstream)
+	stat.Requests++
+	stat.StatusCounts[statusClass(status)]++
+	stat.TotalLatency += latency
+	i, _ := slices.BinarySearch(s.buckets, latency)
+	stat.LatencyBuckets[i]++
+}
+
+func (s *Stats) AddInFlight(upstream string, _ string, delta int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.getLocked(upstream).InFlight += int64(delta)
+}
+
+// Snapshot returns a copy of the recorded metrics keyed by upstream name.
+func (s *Stats) Snapshot() map[string]UpstreamStat {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[string]UpstreamStat, len(s.stats))
+	for name, stat := range s.stats {
+		cpy := *stat
+		cpy.StatusCounts = make(map[string]int64, len(stat.StatusCounts))
+		for class, count := range stat.StatusCounts {
+			cpy.StatusCounts[class] = count
+		}
+		cpy.LatencyBuckets = slices.Clone(stat.LatencyBuckets)
+		out[name] = cpy
+	}
+	return out
+}
+
+func (s *Stats) getLocked(upstream string) *UpstreamStat {
+	stat, ok := s.stats[upstream]
+	if !ok {
+		stat = &UpstreamStat{
+			StatusCounts:   map[string]int64{},
+			LatencyBuckets: make([]int64, len(s.buckets)+1),
+			Buckets:        s.buckets,
+		}
+		s.stats[upstream] = stat
+	}
+	return stat
+}
+
+func statusClass(status int) string {
+	if status <= 0 {
+		return "error"
+	}
+	return strconv.Itoa(status/100) + "xx"
+}
+
+// statusWriter records the status of the response written to the client.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if w.status < http.StatusOK {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,64 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReverseProxyHandler_metrics(t *testing.T) {
+	var inflight int64
+	stats := NewStats(time.Hour)
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		inflight = stats.Snapshot()["users"].InFlight
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer downstream.Close()
+
+	h, err := NewReverseProxyHandler(http.DefaultClient, downstream.URL, WithName("users"), WithMetrics(stats))
+	require.NoError(t, err)
+	e := echo.New()
+	h.Register(e.Group("/api"))
+
+	for range 3 {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/users", nil))
+		assert.Equal(t, http.StatusCreated, rec.Code)
+	}
+
+	stat := stats.Snapshot()["users"]
+	assert.Equal(t, int64(1), inflight)
+	assert.Equal(t, int64(0), stat.InFlight)
+	assert.Equal(t, int64(3), stat.Requests)
+	assert.Equal(t, map[string]int64{"2xx": 3}, stat.StatusCounts)
+	assert.Equal(t, []int64{3, 0}, stat.LatencyBuckets)
+}
+
+func TestReverseProxyHandler_metricsError(t *testing.T) {
+	downstream := httptest.NewServer(http.NotFoundHandler())
+	downstream.Close()
+
+	stats := NewStats()
+	h, err := NewReverseProxyHandler(http.DefaultClient, downstream.URL, WithName("users"), WithMetrics(stats), WithLogger(nopLogger))
+	require.NoError(t, err)
+
+	err = h.Handle(echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/a", nil), httptest.NewRecorder()))
+	require.Error(t, err)
+	assert.Equal(t, map[string]int64{"error": 1}, stats.Snapshot()["users"].StatusCounts)
+}
+
+func TestStats_latencyBuckets(t *testing.T) {
+	s := NewStats(100*time.Millisecond, 10*time.Millisecond)
+	for _, latency := range []time.Duration{time.Millisecond, 10 * time.Millisecond, 50 * time.Millisecond, time.Second} {
+		s.ObserveRequest("a", "host", http.MethodGet, http.StatusOK, latency)
+	}
+	stat := s.Snapshot()["a"]
+	assert.Equal(t, []time.Duration{10 * time.Millisecond, 100 * time.Millisecond}, stat.Buckets)
+	assert.Equal(t, []int64{2, 1, 1}, stat.LatencyBuckets)
+	assert.Equal(t, 1061*time.Millisecond, stat.TotalLatency)
+}
@@ -0,0 +1,289 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+
+	"github.com/joshjon/kit/clock"
+)
+
+const (
+	defaultRetryMaxRetries      = 2
+	defaultRetryInitialInterval = 100 * time.Millisecond
+	defaultRetryMaxInterval     = time.Second
+	defaultRetryBudgetRatio     = 0.2
+	defaultRetryBudgetMinimum   = 10
+	retryBudgetWindow           = 10 * time.Second
+	maxRetryBodySize            = 64 << 10 // 64KiB
+)
+
+// RetryPolicy configures retries of proxied requests that fail with a
+// connection error or a 502, 503 or 504 response. Zero values use the
+// defaults.
+type RetryPolicy struct {
+	// MaxRetries is the maximum number of retries per request. Defaults to 2.
+	MaxRetries int
+	// InitialInterval is the backoff before the first retry, doubling for
+	// every subsequent retry up to MaxInterval. Defaults to 100ms and 1s.
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	// Methods are retried in addition to GET and HEAD. Only opt in methods
+	// whose requests are idempotent downstream. Request bodies larger than
+	// 64KiB are never retried.
+	Methods []string
+	// BudgetRatio limits retries to a ratio of the requests sent in the last
+	// 10 seconds, on top of BudgetMinimum retries, so a failing downstream is
+	// not overwhelmed by retries. Defaults to 0.2 and 10.
+	BudgetRatio   float64
+	BudgetMinimum int
+	// OnRetry is called before each retry with the retry attempt (starting at
+	// 1) and the error or response status that caused it.
+	OnRetry func(req *http.Request, attempt int, cause error)
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxRetries == 0 {
+		p.MaxRetries = defaultRetryMaxRetries
+	}
+	if p.InitialInterval == 0 {
+		p.InitialInterval = defaultRetryInitialInterval
+	}
+	if p.MaxInterval == 0 {
+		p.MaxInterval = max(defaultRetryMaxInterval, p.InitialInterval)
+	}
+	if p.BudgetRatio == 0 {
+		p.BudgetRatio = defaultRetryBudgetRatio
+	}
+	if p.BudgetMinimum == 0 {
+		p.BudgetMinimum = defaultRetryBudgetMinimum
+	}
+	return p
+}
+
+// WithRetry retries requests to the downstream according to policy. WebSocket
+// connections are never retried.
+//
+// Retries are per target: when balancing across targets with WithTargets, a
+// request is retried against the target it was first sent to rather than the
+// next balanced target. Failed attempts count towards the passive health of
+// the target, so a target that keeps failing is ejected from balancing for
+// subsequent requests (see WithPassiveHealth).
+func WithRetry(policy RetryPolicy) ReverseProxyOption {
+	return func(opts *reverseProxyOptions) {
+		opts.retry = &policy
+	}
+}
+
+// errRetryableStatus is the retry cause reported for retryable responses.
+type errRetryableStatus int
+
+func (e errRetryableStatus) Error() string {
+	return fmt.Sprintf("downstream responded %d %s", int(e), http.StatusText(int(e)))
+}
+
+// retryTransport retries requests sent through base according to a
+// RetryPolicy. Every target of a ReverseProxyHandler has its own copy (see
+// withBase), so retries are sent to the same target.
+type retryTransport struct {
+	base    http.RoundTripper
+	policy  RetryPolicy
+	methods []string
+	budget  *retryBudget
+	clock   clock.Clock
+}
+
+func newRetryTransport(base http.RoundTripper, policy RetryPolicy, clk clock.Clock) *retryTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	policy = policy.withDefaults()
+	methods := []string{http.MethodGet, http.MethodHead}
+	for _, method := range policy.Methods {
+		methods = append(methods, strings.ToUpper(method))
+	}
+	return &retryTransport{
+		base:    base,
+		policy:  policy,
+		methods: methods,
+		budget:  newRetryBudget(policy.BudgetRatio, policy.BudgetMinimum, retryBudgetWindow, clk),
+		clock:   clk,
+	}
+}
+
+// withBase returns a copy of t sending requests through base, sharing the
+// retry budget of t.
+func (t *retryTransport) withBase(base http.RoundTripper) *retryTransport {
+	cp := *t
+	cp.base = base
+	return &cp
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.budget.recordRequest()
+	if !slices.Contains(t.methods, req.Method) {
+		return t.base.RoundTrip(req)
+	}
+
+	getBody, err := replayableBody(req)
+	if err != nil {
+		return nil, err
+	}
+	if getBody == nil {
+		return t.base.RoundTrip(req)
+	}
+
+	bo := backoff.NewExponentialBackOff()
+	bo.InitialInterval = t.policy.InitialInterval
+	bo.MaxInterval = t.policy.MaxInterval
+	bo.MaxElapsedTime = 0 // bounded by retries instead
+	bo.Reset()
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			if req, err = rewindRequest(req, getBody); err != nil {
+				return nil, err
+			}
+		}
+
+		res, err := t.base.RoundTrip(req)
+		cause := retryCause(req.Context(), res, err)
+		if cause == nil || attempt == t.policy.MaxRetries || !t.budget.tryRetry() {
+			return res, err
+		}
+
+		if res != nil {
+			// Drain so the connection can be reused by the retry.
+			_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, maxRetryBodySize))
+			res.Body.Close() //nolint:errcheck
+		}
+		if t.policy.OnRetry != nil {
+			t.policy.OnRetry(req, attempt+1, cause)
+		}
+
+		timer := t.clock.NewTimer(bo.NextBackOff())
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C():
+		}
+	}
+}
+
+// retryCause returns why a request should be retried, or nil if it should
+// not be.
+func retryCause(ctx context.Context, res *http.Response, err error) error {
+	if err != nil {
+		if ctx.Err() != nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return nil
+		}
+		return err
+	}
+	switch res.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return errRetryableStatus(res.StatusCode)
+	}
+	return nil
+}
+
+// replayableBody returns a function returning a fresh copy of the request
+// body for every attempt, buffering bodies of up to maxRetryBodySize. A nil
+// function is returned for bodies that cannot be replayed, in which case the
+// request body is left intact and the request must not be retried.
+func replayableBody(req *http.Request) (func() (io.ReadCloser, error), error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return func() (io.ReadCloser, error) { return http.NoBody, nil }, nil
+	}
+	if req.GetBody != nil {
+		return req.GetBody, nil
+	}
+
+	buf, err := io.ReadAll(io.LimitReader(req.Body, maxRetryBodySize+1))
+	if err != nil {
+		return nil, fmt.Errorf("read request body: %w", err)
+	}
+	if len(buf) > maxRetryBodySize {
+		req.Body = readCloser{io.MultiReader(bytes.NewReader(buf), req.Body), req.Body}
+		return nil, nil
+	}
+	req.Body.Close() //nolint:errcheck
+	req.Body = io.NopCloser(bytes.NewReader(buf))
+	return func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(buf)), nil
+	}, nil
+}
+
+func rewindRequest(req *http.Request, getBody func() (io.ReadCloser, error)) (*http.Request, error) {
+	body, err := getBody()
+	if err != nil {
+		return nil, fmt.Errorf("rewind request body: %w", err)
+	}
+	r := req.Clone(req.Context())
+	r.Body = body
+	return r, nil
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// retryBudget limits retries to a ratio of the requests within a fixed
+// window, plus a minimum number of retries per window.
+type retryBudget struct {
+	ratio   float64
+	minimum int
+	window  time.Duration
+	clock   clock.Clock
+
+	mu          sync.Mutex
+	windowStart time.Time
+	requests    int
+	retries     int
+}
+
+func newRetryBudget(ratio float64, minimum int, window time.Duration, clk clock.Clock) *retryBudget {
+	return &retryBudget{
+		ratio:       ratio,
+		minimum:     minimum,
+		window:      window,
+		clock:       clk,
+		windowStart: clk.Now(),
+	}
+}
+
+func (b *retryBudget) recordRequest() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rollLocked()
+	b.requests++
+}
+
+func (b *retryBudget) tryRetry() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rollLocked()
+	if float64(b.retries) >= float64(b.minimum)+b.ratio*float64(b.requests) {
+		return false
+	}
+	b.retries++
+	return true
+}
+
+func (b *retryBudget) rollLocked() {
+	if now := b.clock.Now(); now.Sub(b.windowStart) >= b.window {
+		b.windowStart = now
+		b.requests = 0
+		b.retries = 0
+	}
+}
@@ -0,0 +1,128 @@
+package proxy
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/errtag"
+	"github.com/joshjon/kit/testutil"
+)
+
+func TestReverseProxyHandler_retry(t *testing.T) {
+	var calls atomic.Int32
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if calls.Add(1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = io.WriteString(w, r.Method+" "+string(body))
+	}))
+	defer downstream.Close()
+
+	tests := []struct {
+		name      string
+		method    string
+		body      string
+		policy    RetryPolicy
+		wantCode  int
+		wantBody  string
+		wantCalls int32
+	}{
+		{
+			name:      "get retried",
+			method:    http.MethodGet,
+			wantCode:  http.StatusOK,
+			wantBody:  "GET ",
+			wantCalls: 3,
+		},
+		{
+			name:      "post not retried",
+			method:    http.MethodPost,
+			body:      "payload",
+			wantCode:  http.StatusServiceUnavailable,
+			wantCalls: 1,
+		},
+		{
+			name:      "opted in post retried with body",
+			method:    http.MethodPost,
+			body:      "payload",
+			policy:    RetryPolicy{Methods: []string{"post"}},
+			wantCode:  http.StatusOK,
+			wantBody:  "POST payload",
+			wantCalls: 3,
+		},
+		{
+			name:      "max retries",
+			method:    http.MethodGet,
+			policy:    RetryPolicy{MaxRetries: 1},
+			wantCode:  http.StatusServiceUnavailable,
+			wantCalls: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls.Store(0)
+			var retries []int
+			tt.policy.InitialInterval = time.Millisecond
+			tt.policy.OnRetry = func(_ *http.Request, attempt int, cause error) {
+				assert.ErrorContains(t, cause, "503")
+				retries = append(retries, attempt)
+			}
+
+			h, err := NewReverseProxyHandler(http.DefaultClient, downstream.URL, WithRetry(tt.policy))
+			require.NoError(t, err)
+			e := echo.New()
+			h.Register(e.Group("/api"))
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(tt.method, "/api/a", strings.NewReader(tt.body)))
+			assert.Equal(t, tt.wantCode, rec.Code)
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, rec.Body.String())
+			}
+			assert.Equal(t, tt.wantCalls, calls.Load())
+			assert.Len(t, retries, int(tt.wantCalls)-1)
+		})
+	}
+}
+
+func TestReverseProxyHandler_retryConnectionError(t *testing.T) {
+	downstream := httptest.NewServer(http.NotFoundHandler())
+	downstream.Close()
+
+	var retries atomic.Int32
+	h, err := NewReverseProxyHandler(http.DefaultClient, downstream.URL, WithLogger(nopLogger), WithRetry(RetryPolicy{
+		InitialInterval: time.Millisecond,
+		OnRetry:         func(*http.Request, int, error) { retries.Add(1) },
+	}))
+	require.NoError(t, err)
+
+	err = h.Handle(echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/a", nil), httptest.NewRecorder()))
+	assert.True(t, errtag.HasTag[errtag.Unavailable](err))
+	assert.Equal(t, int32(2), retries.Load())
+}
+
+func TestRetryBudget(t *testing.T) {
+	clk := testutil.NewFakeClock(time.Now())
+	b := newRetryBudget(0.5, 1, time.Hour, clk)
+	assert.True(t, b.tryRetry())
+	assert.False(t, b.tryRetry())
+
+	b.recordRequest()
+	b.recordRequest()
+	assert.True(t, b.tryRetry())
+	assert.False(t, b.tryRetry())
+
+	clk.Advance(time.Hour)
+	assert.True(t, b.tryRetry())
+}
@@ -0,0 +1,117 @@
+package proxy
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// rewriteRule rewrites request paths matching re to replacement, which may
+// reference capture groups of re using $1 or ${1} syntax.
+type rewriteRule struct {
+	re          *regexp.Regexp
+	replacement string
+}
+
+// newWildcardRewrite creates a rewriteRule from wildcard templates, where
+// each * in from captures any sequence of characters (including /) and each
+// * in to is replaced with the corresponding capture, e.g. /api/v1/* → /v1/*.
+func newWildcardRewrite(from string, to string) (rewriteRule, error) {
+	if !strings.HasPrefix(from, "/") {
+		return rewriteRule{}, fmt.Errorf("invalid rewrite %q: must start with /", from)
+	}
+	captures := strings.Count(from, "*")
+	if strings.Count(to, "*") > captures {
+		return rewriteRule{}, fmt.Errorf("invalid rewrite %q → %q: replacement has more wildcards than pattern", from, to)
+	}
+
+	pattern := "^" + strings.ReplaceAll(regexp.QuoteMeta(from), `\*`, "(.*)") + "$"
+	var replacement strings.Builder
+	for i, part := range strings.Split(to, "*") {
+		if i > 0 {
+			replacement.WriteString("${" + strconv.Itoa(i) + "}")
+		}
+		replacement.WriteString(strings.ReplaceAll(part, "$", "$$"))
+	}
+	return rewriteRule{
+		re:          regexp.MustCompile(pattern),
+		replacement: replacement.String(),
+	}, nil
+}
+
+func newRegexRewrite(pattern string, replacement string) (rewriteRule, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return rewriteRule{}, fmt.Errorf("invalid rewrite pattern %q: %w", pattern, err)
+	}
+	return rewriteRule{re: re, replacement: replacement}, nil
+}
+
+// apply rewrites the escaped path of u and reports whether the rule matched.
+func (r rewriteRule) apply(u *url.URL) bool {
+	escaped := u.EscapedPath()
+	if !r.re.MatchString(escaped) {
+		return false
+	}
+	setEscapedPath(u, r.re.ReplaceAllString(escaped, r.replacement))
+	return true
+}
+
+// rewriteRequest returns a shallow copy of req with its path rewritten, or req
+// itself when no rewrite applies. As with http.StripPrefix, the original
+// request is left untouched so upstream middleware (e.g. request logging)
+// still observes the path requested by the client.
+func (h *ReverseProxyHandler) rewriteRequest(c echo.Context) *http.Request {
+	req := c.Request()
+	if !h.opts.stripPrefix && len(h.rewrites) == 0 {
+		return req
+	}
+
+	u := *req.URL
+	if h.opts.stripPrefix {
+		stripRoutePrefix(&u, c.Path())
+	}
+	for _, rule := range h.rewrites {
+		if rule.apply(&u) {
+			break
+		}
+	}
+
+	r := new(http.Request)
+	*r = *req
+	r.URL = &u
+	return r
+}
+
+// stripRoutePrefix removes the group prefix of a wildcard route (e.g. /api
+// of /api/*) from the path of u. Paths are left untouched when the prefix is
+// not literally present, such as for routes with path parameters.
+func stripRoutePrefix(u *url.URL, route string) {
+	prefix := strings.TrimSuffix(strings.TrimSuffix(route, "*"), "/")
+	if prefix == "" {
+		return
+	}
+	escaped := u.EscapedPath()
+	rest, ok := strings.CutPrefix(escaped, prefix)
+	if !ok || (rest != "" && rest[0] != '/') {
+		return
+	}
+	if rest == "" {
+		rest = "/"
+	}
+	setEscapedPath(u, rest)
+}
+
+func setEscapedPath(u *url.URL, escaped string) {
+	path, err := url.PathUnescape(escaped)
+	if err != nil {
+		return
+	}
+	u.Path = path
+	u.RawPath = escaped
+}
@@ -0,0 +1,40 @@
+package proxy
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+type requestStateKey struct{}
+
+// requestState is shared between the handler and the httputil.ReverseProxy
+// callbacks of a single proxied request.
+type requestState struct {
+	// err captures the error of the request so it can be returned from the
+	// handler, rather than written as a plain-text response by the
+	// httputil.ReverseProxy.
+	err error
+	// modifyErr is the error returned by the ModifyResponseFunc, which is
+	// returned from the handler as is.
+	modifyErr error
+	// timeout cancels the request once the upstream timeout elapses. Nil
+	// without a timeout.
+	timeout *time.Timer
+}
+
+func withRequestState(req *http.Request, timeout time.Duration) (*http.Request, *requestState, context.CancelCauseFunc) {
+	state := &requestState{}
+	ctx, cancel := context.WithCancelCause(req.Context())
+	if timeout > 0 {
+		state.timeout = time.AfterFunc(timeout, func() {
+			cancel(ErrUpstreamTimeout)
+		})
+	}
+	return req.WithContext(context.WithValue(ctx, requestStateKey{}, state)), state, cancel
+}
+
+func getRequestState(req *http.Request) (*requestState, bool) {
+	state, ok := req.Context().Value(requestStateKey{}).(*requestState)
+	return state, ok
+}
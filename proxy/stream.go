@@ -0,0 +1,34 @@
+package proxy
+
+import (
+	"mime"
+	"net/http"
+	"time"
+)
+
+// WithFlushInterval sets the interval at which response bodies are flushed to
+// the client while being copied from the upstream. Server-sent events and
+// responses of unknown length (e.g. chunked) are always flushed immediately.
+// Defaults to 0, which flushes other responses only once fully copied.
+func WithFlushInterval(interval time.Duration) ReverseProxyOption {
+	return func(opts *reverseProxyOptions) {
+		opts.flushInterval = interval
+	}
+}
+
+// IsEventStream reports whether the Content-Type of header is
+// text/event-stream.
+func IsEventStream(header http.Header) bool {
+	mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	return mediaType == "text/event-stream"
+}
+
+// prepareStreamingResponse disables buffering of server-sent events by
+// intermediaries in front of the proxy (e.g. nginx) which would otherwise
+// stall events until their buffers fill up.
+func prepareStreamingResponse(res *http.Response) {
+	if IsEventStream(res.Header) {
+		res.Header.Set("X-Accel-Buffering", "no")
+		res.Header.Set("Cache-Control", "no-cache")
+	}
+}
@@ -0,0 +1,60 @@
+package proxy
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReverseProxyHandler_eventStream(t *testing.T) {
+	release := make(chan struct{})
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i := range 2 {
+			_, _ = fmt.Fprintf(w, "data: %d\n\n", i)
+			w.(http.Flusher).Flush()
+			if i == 0 {
+				// Block until the first event has been received through the
+				// proxy, which stalls if the proxy buffers the response.
+				select {
+				case <-release:
+				case <-r.Context().Done():
+					return
+				}
+			}
+		}
+	}))
+	defer downstream.Close()
+	defer close(release)
+
+	h, err := NewReverseProxyHandler(http.DefaultClient, downstream.URL, WithLogger(nopLogger))
+	require.NoError(t, err)
+	e := echo.New()
+	h.Register(e.Group("/events"))
+	srv := httptest.NewServer(e)
+	defer srv.Close()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	res, err := client.Get(srv.URL + "/events/stream")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, "no", res.Header.Get("X-Accel-Buffering"))
+
+	r := bufio.NewReader(res.Body)
+	line, err := r.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "data: 0\n", line)
+	release <- struct{}{}
+
+	_, _ = r.ReadString('\n')
+	line, err = r.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "data: 1\n", line)
+}
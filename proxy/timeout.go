@@ -0,0 +1,34 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ErrUpstreamTimeout is the cause of requests canceled by the upstream
+// timeout. It matches context.DeadlineExceeded with errors.Is.
+var ErrUpstreamTimeout = fmt.Errorf("upstream timeout: %w", context.DeadlineExceeded)
+
+// WithTimeout cancels upstream requests that have not completed within
+// timeout, including retries and reading the response body. Server-sent event
+// responses are only bounded until their headers are received. This is
+// independent of the http.Client timeout, which the proxy does not use.
+// Requests are always canceled as soon as the client disconnects.
+func WithTimeout(timeout time.Duration) ReverseProxyOption {
+	return func(opts *reverseProxyOptions) {
+		opts.timeout = timeout
+	}
+}
+
+// stopStreamingTimeout stops the upstream timeout of server-sent event
+// responses, which stream for as long as the client is connected.
+func stopStreamingTimeout(res *http.Response) {
+	if !IsEventStream(res.Header) {
+		return
+	}
+	if state, ok := getRequestState(res.Request); ok && state.timeout != nil {
+		state.timeout.Stop()
+	}
+}
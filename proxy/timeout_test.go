@@ -0,0 +1,87 @@
+package proxy
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/errtag"
+)
+
+func TestReverseProxyHandler_timeout(t *testing.T) {
+	canceled := make(chan struct{})
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		close(canceled)
+	}))
+	defer downstream.Close()
+
+	h, err := NewReverseProxyHandler(http.DefaultClient, downstream.URL, WithLogger(nopLogger), WithTimeout(50*time.Millisecond))
+	require.NoError(t, err)
+
+	err = h.Handle(echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/a", nil), httptest.NewRecorder()))
+	assert.True(t, errtag.HasTag[errtag.GatewayTimeout](err))
+	assert.ErrorIs(t, err, ErrUpstreamTimeout)
+	waitClosed(t, canceled)
+}
+
+func TestReverseProxyHandler_timeoutEventStream(t *testing.T) {
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		time.Sleep(100 * time.Millisecond)
+		_, _ = io.WriteString(w, "data: done\n\n")
+	}))
+	defer downstream.Close()
+
+	h, err := NewReverseProxyHandler(http.DefaultClient, downstream.URL, WithTimeout(50*time.Millisecond))
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	err = h.Handle(echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/a", nil), rec))
+	require.NoError(t, err)
+	assert.Equal(t, "data: done\n\n", rec.Body.String())
+}
+
+func TestReverseProxyHandler_clientDisconnect(t *testing.T) {
+	received := make(chan struct{})
+	canceled := make(chan struct{})
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(received)
+		<-r.Context().Done()
+		close(canceled)
+	}))
+	defer downstream.Close()
+
+	h, err := NewReverseProxyHandler(http.DefaultClient, downstream.URL, WithLogger(nopLogger))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodGet, "/a", nil).WithContext(ctx)
+	done := make(chan error, 1)
+	go func() {
+		done <- h.Handle(echo.New().NewContext(req, httptest.NewRecorder()))
+	}()
+
+	waitClosed(t, received)
+	cancel()
+	waitClosed(t, canceled)
+	assert.NoError(t, <-done)
+}
+
+func waitClosed(t *testing.T, ch <-chan struct{}) {
+	t.Helper()
+	select {
+	case <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for upstream")
+	}
+}
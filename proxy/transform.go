@@ -0,0 +1,144 @@
+package proxy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/joshjon/kit/errtag"
+)
+
+const defaultMaxModifyBodySize = 1 << 20 // 1MiB
+
+// ModifyRequestFunc is called with every request and its body before it is
+// proxied upstream. The returned body replaces the request body. Errors are
+// returned from the handler as is, so they should be tagged with errtag.
+type ModifyRequestFunc func(req *http.Request, body []byte) ([]byte, error)
+
+// ModifyResponseFunc is called with every upstream response and its body
+// before it is returned to the client. The returned body replaces the
+// response body. Errors are returned from the handler as is, so they should be
+// tagged with errtag.
+type ModifyResponseFunc func(res *http.Response, body []byte) ([]byte, error)
+
+// WithModifyRequest modifies requests before they are proxied upstream, e.g.
+// to adapt payloads for legacy downstreams. Request bodies larger than the
+// limit set with WithMaxModifyBodySize are rejected with 413 Request Entity
+// Too Large. WebSocket requests are not modified.
+func WithModifyRequest(fn ModifyRequestFunc) ReverseProxyOption {
+	return func(opts *reverseProxyOptions) {
+		opts.modifyRequest = fn
+	}
+}
+
+// WithModifyResponse modifies upstream responses before they are returned to
+// the client, e.g. to redact fields. Upstream responses are requested without
+// compression so bodies can be read as is. Response bodies larger than the
+// limit set with WithMaxModifyBodySize fail with errtag.BadGateway.
+// Server-sent event responses are streamed and not modified.
+func WithModifyResponse(fn ModifyResponseFunc) ReverseProxyOption {
+	return func(opts *reverseProxyOptions) {
+		opts.modifyResponse = fn
+	}
+}
+
+// WithMaxModifyBodySize sets the maximum size in bytes of the bodies passed to
+// WithModifyRequest and WithModifyResponse callbacks. Defaults to 1MiB.
+func WithMaxModifyBodySize(n int64) ReverseProxyOption {
+	return func(opts *reverseProxyOptions) {
+		opts.maxModifyBodySize = n
+	}
+}
+
+func (h *ReverseProxyHandler) modifyRequest(req *http.Request) (*http.Request, error) {
+	if h.opts.modifyRequest == nil && h.opts.modifyResponse == nil {
+		return req, nil
+	}
+	req = req.Clone(req.Context())
+	if h.opts.modifyResponse != nil {
+		// Transparent decompression by the transport only applies to requests
+		// without an Accept-Encoding header.
+		req.Header.Del("Accept-Encoding")
+	}
+	if h.opts.modifyRequest == nil {
+		return req, nil
+	}
+
+	body, err := readBody(req.Body, h.opts.maxModifyBodySize)
+	if err != nil {
+		if err == errBodyTooLarge {
+			return nil, fmt.Errorf("modify request: %w", echo.ErrStatusRequestEntityTooLarge)
+		}
+		return nil, fmt.Errorf("modify request: read body: %w", err)
+	}
+	body, err = h.opts.modifyRequest(req, body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	setContentLength(req.Header, &req.ContentLength, len(body))
+	return req, nil
+}
+
+func (h *ReverseProxyHandler) modifyResponse(res *http.Response) error {
+	if h.opts.modifyResponse == nil || IsEventStream(res.Header) {
+		return nil
+	}
+
+	body, err := readBody(res.Body, h.opts.maxModifyBodySize)
+	res.Body.Close() //nolint:errcheck
+	if err == errBodyTooLarge {
+		err = errtag.Tag[errtag.BadGateway](fmt.Errorf("modify response: %w", err))
+		return setModifyError(res.Request, err)
+	}
+	if err != nil {
+		return fmt.Errorf("modify response: read body: %w", err)
+	}
+	if body, err = h.opts.modifyResponse(res, body); err != nil {
+		return setModifyError(res.Request, err)
+	}
+
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	res.TransferEncoding = nil
+	setContentLength(res.Header, &res.ContentLength, len(body))
+	return nil
+}
+
+// setModifyError records err to be returned from the handler as is, rather
+// than being passed to the ErrorHandlerFunc.
+func setModifyError(req *http.Request, err error) error {
+	if state, ok := getRequestState(req); ok {
+		state.modifyErr = err
+	}
+	return err
+}
+
+var errBodyTooLarge = errors.New("body too large")
+
+func readBody(r io.Reader, limit int64) ([]byte, error) {
+	if r == nil || r == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > limit {
+		return nil, errBodyTooLarge
+	}
+	return body, nil
+}
+
+func setContentLength(header http.Header, contentLength *int64, n int) {
+	*contentLength = int64(n)
+	header.Set("Content-Length", strconv.Itoa(n))
+}
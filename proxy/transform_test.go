@@ -0,0 +1,106 @@
+package proxy
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/errtag"
+)
+
+func TestReverseProxyHandler_modifyBodies(t *testing.T) {
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, int64(len(body)), r.ContentLength)
+		_, _ = w.Write(body)
+	}))
+	defer downstream.Close()
+
+	h, err := NewReverseProxyHandler(http.DefaultClient, downstream.URL,
+		WithModifyRequest(func(_ *http.Request, body []byte) ([]byte, error) {
+			return bytes.ReplaceAll(body, []byte("legacy"), []byte("current")), nil
+		}),
+		WithModifyResponse(func(_ *http.Response, body []byte) ([]byte, error) {
+			return bytes.ReplaceAll(body, []byte("secret"), []byte("***")), nil
+		}),
+	)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/a", strings.NewReader(`{"name":"legacy","token":"secret"}`))
+	rec := httptest.NewRecorder()
+	require.NoError(t, h.Handle(echo.New().NewContext(req, rec)))
+	assert.Equal(t, `{"name":"current","token":"***"}`, rec.Body.String())
+	assert.Equal(t, "32", rec.Header().Get("Content-Length"))
+}
+
+func TestReverseProxyHandler_modifyErrors(t *testing.T) {
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "0123456789")
+	}))
+	defer downstream.Close()
+
+	errRejected := errtag.NewTagged[errtag.InvalidArgument]("rejected")
+	assertRejected := func(t *testing.T, err error) {
+		assert.True(t, errtag.HasTag[errtag.InvalidArgument](err))
+	}
+
+	tests := []struct {
+		name    string
+		opts    []ReverseProxyOption
+		body    string
+		wantErr func(t *testing.T, err error)
+	}{
+		{
+			name: "request too large",
+			opts: []ReverseProxyOption{
+				WithMaxModifyBodySize(4),
+				WithModifyRequest(func(_ *http.Request, body []byte) ([]byte, error) { return body, nil }),
+			},
+			body: "12345",
+			wantErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, echo.ErrStatusRequestEntityTooLarge)
+			},
+		},
+		{
+			name: "request rejected",
+			opts: []ReverseProxyOption{
+				WithModifyRequest(func(*http.Request, []byte) ([]byte, error) { return nil, errRejected }),
+			},
+			wantErr: assertRejected,
+		},
+		{
+			name: "response too large",
+			opts: []ReverseProxyOption{
+				WithMaxModifyBodySize(4),
+				WithModifyResponse(func(_ *http.Response, body []byte) ([]byte, error) { return body, nil }),
+			},
+			wantErr: func(t *testing.T, err error) {
+				assert.True(t, errtag.HasTag[errtag.BadGateway](err))
+			},
+		},
+		{
+			name: "response rejected",
+			opts: []ReverseProxyOption{
+				WithModifyResponse(func(*http.Response, []byte) ([]byte, error) { return nil, errRejected }),
+			},
+			wantErr: assertRejected,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, err := NewReverseProxyHandler(http.DefaultClient, downstream.URL, append(tt.opts, WithLogger(nopLogger))...)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "/a", strings.NewReader(tt.body))
+			err = h.Handle(echo.New().NewContext(req, httptest.NewRecorder()))
+			tt.wantErr(t, err)
+		})
+	}
+}
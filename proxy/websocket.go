@@ -0,0 +1,200 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/coder/websocket"
+	"github.com/labstack/echo/v4"
+)
+
+// forwardedWebSocketHeaders are copied from the client upgrade request to the
+// downstream upgrade request. Authorization carries the bearer token injected
+// from the session by the auth middleware, since browsers cannot set headers
+// on WebSocket connections themselves. Cookies are not forwarded so the
+// session cookie of the client is never exposed to the downstream.
+var forwardedWebSocketHeaders = []string{
+	echo.HeaderAuthorization,
+	echo.HeaderXForwardedFor,
+	echo.HeaderXRequestID,
+}
+
+type WebSocketOption func(opts *webSocketOptions)
+
+// WithOriginPatterns sets the host patterns of authorized origins for client
+// connections. By default only same-origin connections are accepted.
+func WithOriginPatterns(patterns ...string) WebSocketOption {
+	return func(opts *webSocketOptions) {
+		opts.originPatterns = patterns
+	}
+}
+
+// WithReadLimit sets the maximum message size in bytes read from either side
+// of the connection. A negative limit disables the limit.
+func WithReadLimit(limit int64) WebSocketOption {
+	return func(opts *webSocketOptions) {
+		opts.readLimit = limit
+	}
+}
+
+// WithWebSocketOriginPatterns is like WithOriginPatterns for the WebSocket
+// connections proxied by a ReverseProxyHandler.
+func WithWebSocketOriginPatterns(patterns ...string) ReverseProxyOption {
+	return func(opts *reverseProxyOptions) {
+		opts.webSocketOpts = append(opts.webSocketOpts, WithOriginPatterns(patterns...))
+	}
+}
+
+// WithWebSocketReadLimit is like WithReadLimit for the WebSocket connections
+// proxied by a ReverseProxyHandler.
+func WithWebSocketReadLimit(limit int64) ReverseProxyOption {
+	return func(opts *reverseProxyOptions) {
+		opts.webSocketOpts = append(opts.webSocketOpts, WithReadLimit(limit))
+	}
+}
+
+type webSocketOptions struct {
+	originPatterns []string
+	readLimit      int64
+}
+
+// WebSocketProxyHandler proxies WebSocket connections to a downstream API,
+// streaming frames in both directions until either side closes.
+type WebSocketProxyHandler struct {
+	client    *http.Client
+	targetURL *url.URL
+	opts      webSocketOptions
+}
+
+// NewWebSocketProxyHandler creates a WebSocketProxyHandler for the downstream
+// API. An http(s) apiURL is dialed using the equivalent ws(s) scheme.
+func NewWebSocketProxyHandler(client *http.Client, apiURL string, opts ...WebSocketOption) (*WebSocketProxyHandler, error) {
+	targetURL, err := url.Parse(apiURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse websocket target url: %w", err)
+	}
+	switch targetURL.Scheme {
+	case "http":
+		targetURL.Scheme = "ws"
+	case "https":
+		targetURL.Scheme = "wss"
+	case "ws", "wss":
+	default:
+		return nil, fmt.Errorf("unsupported websocket target url scheme: %s", targetURL.Scheme)
+	}
+
+	options := webSocketOptions{
+		readLimit: 1 << 20, // 1MiB
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return &WebSocketProxyHandler{
+		client:    client,
+		targetURL: targetURL,
+		opts:      options,
+	}, nil
+}
+
+func (h *WebSocketProxyHandler) Register(g *echo.Group) {
+	g.GET("/*", h.Handle)
+}
+
+func (h *WebSocketProxyHandler) Handle(c echo.Context) error {
+	req := c.Request()
+
+	header := http.Header{}
+	for _, key := range forwardedWebSocketHeaders {
+		if val := req.Header.Get(key); val != "" {
+			header.Set(key, val)
+		}
+	}
+
+	var subprotocols []string
+	if protos := req.Header.Get("Sec-WebSocket-Protocol"); protos != "" {
+		for _, proto := range strings.Split(protos, ",") {
+			subprotocols = append(subprotocols, strings.TrimSpace(proto))
+		}
+	}
+
+	// Dial downstream before accepting so handshake failures (e.g.
+	// unauthorized) can be returned to the client as a regular HTTP error.
+	downConn, downRes, err := websocket.Dial(req.Context(), h.target(req.URL).String(), &websocket.DialOptions{
+		HTTPClient:   h.client,
+		HTTPHeader:   header,
+		Subprotocols: subprotocols,
+	})
+	if err != nil {
+		if downRes != nil && downRes.StatusCode >= 400 {
+			return echo.NewHTTPError(downRes.StatusCode)
+		}
+		return echo.NewHTTPError(http.StatusBadGateway).SetInternal(err)
+	}
+	defer downConn.CloseNow() //nolint:errcheck
+
+	var acceptProtos []string
+	if proto := downConn.Subprotocol(); proto != "" {
+		acceptProtos = []string{proto}
+	}
+
+	upConn, err := websocket.Accept(c.Response(), req, &websocket.AcceptOptions{
+		Subprotocols:   acceptProtos,
+		OriginPatterns: h.opts.originPatterns,
+	})
+	if err != nil {
+		// Accept has already written the error response to the client.
+		downConn.Close(websocket.StatusInternalError, "client upgrade failed") //nolint:errcheck
+		return nil
+	}
+	defer upConn.CloseNow() //nolint:errcheck
+
+	upConn.SetReadLimit(h.opts.readLimit)
+	downConn.SetReadLimit(h.opts.readLimit)
+
+	ctx, cancel := context.WithCancel(req.Context())
+	defer cancel()
+
+	errs := make(chan error, 2)
+	go func() { errs <- pipeWebSocket(ctx, upConn, downConn) }()
+	go func() { errs <- pipeWebSocket(ctx, downConn, upConn) }()
+
+	// The first side to finish determines how the other is closed.
+	err = <-errs
+	cancel()
+
+	status := websocket.CloseStatus(err)
+	if status == -1 {
+		status = websocket.StatusGoingAway
+	}
+	upConn.Close(status, "")   //nolint:errcheck
+	downConn.Close(status, "") //nolint:errcheck
+
+	return nil
+}
+
+func (h *WebSocketProxyHandler) target(reqURL *url.URL) *url.URL {
+	u := *h.targetURL
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + strings.TrimPrefix(reqURL.Path, "/")
+	u.RawQuery = reqURL.RawQuery
+	return &u
+}
+
+func pipeWebSocket(ctx context.Context, dst *websocket.Conn, src *websocket.Conn) error {
+	for {
+		typ, data, err := src.Read(ctx)
+		if err != nil {
+			return err
+		}
+		if err = dst.Write(ctx, typ, data); err != nil {
+			if errors.Is(err, context.Canceled) {
+				return nil
+			}
+			return err
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package proxy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebSocketProxyHandler(t *testing.T) {
+	const wantToken = "Bearer session-token"
+
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != wantToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.CloseNow() //nolint:errcheck
+		for {
+			typ, data, err := conn.Read(r.Context())
+			if err != nil {
+				return
+			}
+			if err = conn.Write(r.Context(), typ, append([]byte("echo: "), data...)); err != nil {
+				return
+			}
+		}
+	}))
+	defer downstream.Close()
+
+	e := echo.New()
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			// simulate session-derived token injection
+			c.Request().Header.Set("Authorization", wantToken)
+			return next(c)
+		}
+	})
+	h, err := NewReverseProxyHandler(http.DefaultClient, downstream.URL)
+	require.NoError(t, err)
+	h.Register(e.Group("/ws"))
+	bff := httptest.NewServer(e)
+	defer bff.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, _, err := websocket.Dial(ctx, strings.Replace(bff.URL, "http", "ws", 1)+"/ws/stream", nil)
+	require.NoError(t, err)
+	defer conn.CloseNow() //nolint:errcheck
+
+	require.NoError(t, conn.Write(ctx, websocket.MessageText, []byte("hello")))
+	_, got, err := conn.Read(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "echo: hello", string(got))
+
+	require.NoError(t, conn.Close(websocket.StatusNormalClosure, ""))
+}
+
+func TestReverseProxyHandler_webSocketOptions(t *testing.T) {
+	cookies := make(chan string, 1)
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cookies <- r.Header.Get("Cookie")
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.CloseNow() //nolint:errcheck
+		for {
+			typ, data, err := conn.Read(r.Context())
+			if err != nil {
+				return
+			}
+			if err = conn.Write(r.Context(), typ, data); err != nil {
+				return
+			}
+		}
+	}))
+	defer downstream.Close()
+
+	e := echo.New()
+	h, err := NewReverseProxyHandler(http.DefaultClient, downstream.URL,
+		WithWebSocketOriginPatterns("app.example.com"),
+		WithWebSocketReadLimit(8),
+	)
+	require.NoError(t, err)
+	h.Register(e.Group("/ws"))
+	bff := httptest.NewServer(e)
+	defer bff.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	wsURL := strings.Replace(bff.URL, "http", "ws", 1) + "/ws/stream"
+	conn, _, err := websocket.Dial(ctx, wsURL, &websocket.DialOptions{
+		HTTPHeader: http.Header{
+			"Origin": {"https://app.example.com"},
+			"Cookie": {"session=secret"},
+		},
+	})
+	require.NoError(t, err)
+	defer conn.CloseNow() //nolint:errcheck
+	assert.Empty(t, <-cookies, "session cookie must not be forwarded downstream")
+
+	require.NoError(t, conn.Write(ctx, websocket.MessageText, []byte("hello")))
+	_, got, err := conn.Read(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(got))
+
+	// messages larger than the read limit close the connection
+	require.NoError(t, conn.Write(ctx, websocket.MessageText, []byte("too large message")))
+	_, _, err = conn.Read(ctx)
+	assert.Equal(t, websocket.StatusMessageTooBig, websocket.CloseStatus(err))
+
+	// origins not matching the patterns are rejected
+	_, res, err := websocket.Dial(ctx, wsURL, &websocket.DialOptions{
+		HTTPHeader: http.Header{"Origin": {"https://evil.example.com"}},
+	})
+	require.Error(t, err)
+	require.NotNil(t, res)
+	assert.Equal(t, http.StatusForbidden, res.StatusCode)
+}
@@ -0,0 +1,70 @@
+package pubsub
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// HeaderContentType is the header set to the content type of the Codec used
+// to encode a message published with Typed.
+const HeaderContentType = "Content-Type"
+
+// Codec encodes and decodes values of type T as message data.
+type Codec[T any] interface {
+	Encode(v T) ([]byte, error)
+	Decode(data []byte) (T, error)
+	ContentType() string
+}
+
+// JSONCodec returns a Codec encoding values as JSON.
+func JSONCodec[T any]() Codec[T] {
+	return jsonCodec[T]{}
+}
+
+type jsonCodec[T any] struct{}
+
+func (jsonCodec[T]) Encode(v T) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec[T]) Decode(data []byte) (T, error) {
+	var v T
+	err := json.Unmarshal(data, &v)
+	return v, err
+}
+
+func (jsonCodec[T]) ContentType() string {
+	return "application/json"
+}
+
+// ProtoCodec returns a Codec encoding protobuf messages in the protobuf wire
+// format, e.g. ProtoCodec[orderv1.Order]() for *orderv1.Order values.
+func ProtoCodec[T any, PT interface {
+	*T
+	proto.Message
+}]() Codec[PT] {
+	return protoCodec[T, PT]{}
+}
+
+type protoCodec[T any, PT interface {
+	*T
+	proto.Message
+}] struct{}
+
+func (protoCodec[T, PT]) Encode(v PT) ([]byte, error) {
+	return proto.Marshal(v)
+}
+
+func (protoCodec[T, PT]) Decode(data []byte) (PT, error) {
+	v := PT(new(T))
+	if err := proto.Unmarshal(data, v); err != nil {
+		return nil, fmt.Errorf("unmarshal proto: %w", err)
+	}
+	return v, nil
+}
+
+func (protoCodec[T, PT]) ContentType() string {
+	return "application/protobuf"
+}
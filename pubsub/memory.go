@@ -0,0 +1,121 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/joshjon/kit/id"
+)
+
+// MemoryTopic is an in-memory Topic for tests and single process services.
+// Publish calls the handler of one subscription of every group synchronously,
+// rotating between the subscriptions of a group, and returns their errors.
+type MemoryTopic struct {
+	name string
+
+	mu     sync.Mutex
+	groups map[string]*memoryGroup
+}
+
+var _ Topic = (*MemoryTopic)(nil)
+
+type memoryGroup struct {
+	subs []*memorySubscription
+	next int
+}
+
+// NewMemoryTopic creates a new MemoryTopic.
+func NewMemoryTopic(name string) *MemoryTopic {
+	return &MemoryTopic{
+		name:   name,
+		groups: map[string]*memoryGroup{},
+	}
+}
+
+func (t *MemoryTopic) Name() string {
+	return t.name
+}
+
+func (t *MemoryTopic) Publish(ctx context.Context, msgs ...*Message) error {
+	var errs []error
+	for _, msg := range msgs {
+		if msg.ID == "" {
+			msg.ID = id.NewUUIDv7().String()
+		}
+		for _, sub := range t.pick() {
+			if err := sub.handle(ctx, msg); err != nil && !isPermanent(err) {
+				errs = append(errs, fmt.Errorf("handle message %s: %w", msg.ID, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// pick returns the next subscription of every group.
+func (t *MemoryTopic) pick() []*memorySubscription {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	subs := make([]*memorySubscription, 0, len(t.groups))
+	for _, g := range t.groups {
+		subs = append(subs, g.subs[g.next%len(g.subs)])
+		g.next++
+	}
+	return subs
+}
+
+func (t *MemoryTopic) Subscribe(_ context.Context, group string, handler Handler) (Subscription, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	sub := &memorySubscription{
+		topic:   t,
+		group:   group,
+		handler: handler,
+	}
+	g, ok := t.groups[group]
+	if !ok {
+		g = &memoryGroup{}
+		t.groups[group] = g
+	}
+	g.subs = append(g.subs, sub)
+	return sub, nil
+}
+
+type memorySubscription struct {
+	topic   *MemoryTopic
+	group   string
+	handler Handler
+
+	mu      sync.RWMutex // held for reading while handling messages
+	stopped bool
+}
+
+func (s *memorySubscription) handle(ctx context.Context, msg *Message) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.stopped {
+		return nil
+	}
+	return s.handler(ctx, msg)
+}
+
+func (s *memorySubscription) Stop() {
+	s.topic.mu.Lock()
+	if g, ok := s.topic.groups[s.group]; ok {
+		for i, sub := range g.subs {
+			if sub == s {
+				g.subs = append(g.subs[:i], g.subs[i+1:]...)
+				break
+			}
+		}
+		if len(g.subs) == 0 {
+			delete(s.topic.groups, s.group)
+		}
+	}
+	s.topic.mu.Unlock()
+
+	s.mu.Lock()
+	s.stopped = true
+	s.mu.Unlock()
+}
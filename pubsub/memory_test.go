@@ -0,0 +1,59 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryTopic(t *testing.T) {
+	ctx := context.Background()
+	topic := NewMemoryTopic("orders")
+
+	var billing1, billing2, shipping []string
+	record := func(got *[]string) Handler {
+		return func(_ context.Context, msg *Message) error {
+			*got = append(*got, string(msg.Data))
+			return nil
+		}
+	}
+	_, err := topic.Subscribe(ctx, "billing", record(&billing1))
+	require.NoError(t, err)
+	sub, err := topic.Subscribe(ctx, "billing", record(&billing2))
+	require.NoError(t, err)
+	_, err = topic.Subscribe(ctx, "shipping", record(&shipping))
+	require.NoError(t, err)
+
+	msg := &Message{Data: []byte("a")}
+	require.NoError(t, topic.Publish(ctx, msg, &Message{Data: []byte("b")}))
+	assert.NotEmpty(t, msg.ID)
+	assert.Equal(t, []string{"a"}, billing1)
+	assert.Equal(t, []string{"b"}, billing2)
+	assert.Equal(t, []string{"a", "b"}, shipping)
+
+	sub.Stop()
+	require.NoError(t, topic.Publish(ctx, &Message{Data: []byte("c")}))
+	assert.Equal(t, []string{"a", "c"}, billing1)
+	assert.Equal(t, []string{"b"}, billing2)
+}
+
+func TestMemoryTopic_Publish_errors(t *testing.T) {
+	ctx := context.Background()
+	topic := NewMemoryTopic("orders")
+	wantErr := errors.New("boom")
+	_, err := topic.Subscribe(ctx, "billing", func(context.Context, *Message) error {
+		return wantErr
+	})
+	require.NoError(t, err)
+	_, err = topic.Subscribe(ctx, "shipping", func(context.Context, *Message) error {
+		return Permanent(errors.New("dropped"))
+	})
+	require.NoError(t, err)
+
+	err = topic.Publish(ctx, &Message{Data: []byte("a")})
+	assert.ErrorIs(t, err, wantErr)
+	assert.NotContains(t, err.Error(), "dropped")
+}
@@ -0,0 +1,185 @@
+package pubsub
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/joshjon/kit/log"
+	"github.com/joshjon/kit/preview"
+	"github.com/joshjon/kit/retry"
+)
+
+const (
+	// HeaderDeadLetterError is the header set to the error of a message
+	// published to a dead-letter topic by RetryMiddleware.
+	HeaderDeadLetterError = "Dead-Letter-Error"
+	// HeaderDeadLetterID is the header set to the ID of the original message
+	// of a message published to a dead-letter topic by RetryMiddleware.
+	HeaderDeadLetterID = "Dead-Letter-Id"
+)
+
+// Middleware wraps a Handler.
+type Middleware func(next Handler) Handler
+
+// Chain wraps h with middlewares, the first being the outermost.
+func Chain(h Handler, middlewares ...Middleware) Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+	return h
+}
+
+// LoggingMiddleware logs received messages at debug level with a preview of their data
+// (see preview.Value), and failed messages at warn level.
+func LoggingMiddleware(logger log.Logger) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg *Message) error {
+			logger.Debug("received message", "id", msg.ID, "data", preview.Value(msg.Data))
+			err := next(ctx, msg)
+			if err != nil {
+				logger.Warn("message failed", "id", msg.ID, "permanent", isPermanent(err), "error", err,
+					"data", preview.Value(msg.Data))
+			}
+			return err
+		}
+	}
+}
+
+// Outcome is the outcome of handling a message.
+type Outcome string
+
+const (
+	OutcomeOK    Outcome = "ok"
+	OutcomeError Outcome = "error"
+	// OutcomeDropped is the outcome of messages failing with a Permanent
+	// error.
+	OutcomeDropped Outcome = "dropped"
+)
+
+// Metrics records metrics of handled messages.
+type Metrics interface {
+	// ObserveMessage records a message of topic handled by a subscription of
+	// group.
+	ObserveMessage(topic string, group string, outcome Outcome, latency time.Duration)
+}
+
+// MetricsMiddleware records the outcome and latency of handled messages of the
+// subscriptions of group to topic with metrics.
+func MetricsMiddleware(metrics Metrics, topic string, group string) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg *Message) error {
+			start := time.Now()
+			err := next(ctx, msg)
+			outcome := OutcomeOK
+			switch {
+			case isPermanent(err):
+				outcome = OutcomeDropped
+			case err != nil:
+				outcome = OutcomeError
+			}
+			metrics.ObserveMessage(topic, group, outcome, time.Since(start))
+			return err
+		}
+	}
+}
+
+// StatKey identifies the subscriptions of a group to a topic.
+type StatKey struct {
+	Topic string
+	Group string
+}
+
+// Stat holds the recorded metrics of the subscriptions of a group to a
+// topic.
+type Stat struct {
+	Messages      int64
+	OutcomeCounts map[Outcome]int64
+	TotalLatency  time.Duration
+	MaxLatency    time.Duration
+}
+
+// Stats is an in-memory Metrics implementation.
+type Stats struct {
+	mu    sync.Mutex
+	stats map[StatKey]*Stat
+}
+
+var _ Metrics = (*Stats)(nil)
+
+func NewStats() *Stats {
+	return &Stats{
+		stats: map[StatKey]*Stat{},
+	}
+}
+
+func (s *Stats) ObserveMessage(topic string, group string, outcome Outcome, latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := StatKey{Topic: topic, Group: group}
+	stat, ok := s.stats[key]
+	if !ok {
+		stat = &Stat{OutcomeCounts: map[Outcome]int64{}}
+		s.stats[key] = stat
+	}
+	stat.Messages++
+	stat.OutcomeCounts[outcome]++
+	stat.TotalLatency += latency
+	stat.MaxLatency = max(stat.MaxLatency, latency)
+}
+
+// Snapshot returns a copy of the recorded metrics.
+func (s *Stats) Snapshot() map[StatKey]Stat {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[StatKey]Stat, len(s.stats))
+	for key, stat := range s.stats {
+		cpy := *stat
+		cpy.OutcomeCounts = maps.Clone(stat.OutcomeCounts)
+		out[key] = cpy
+	}
+	return out
+}
+
+// RetryMiddleware retries failed messages in process according to policy,
+// rather than waiting for the transport to redeliver them. Messages still
+// failing once policy stops retrying, and messages failing with a Permanent
+// error, are published to deadLetter with the HeaderDeadLetterError and
+// HeaderDeadLetterID headers and dropped. If deadLetter is nil, or publishing
+// to it fails, the error is returned so the message is redelivered.
+func RetryMiddleware(policy retry.Policy, deadLetter Topic) Middleware {
+	retryOn := policy.RetryOn
+	if retryOn == nil {
+		retryOn = retry.Retryable
+	}
+	policy.RetryOn = func(err error) bool {
+		return !isPermanent(err) && retryOn(err)
+	}
+
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg *Message) error {
+			err := retry.DoErr(ctx, policy, func(ctx context.Context) error {
+				return next(ctx, msg)
+			})
+			if err == nil || deadLetter == nil || ctx.Err() != nil {
+				return err
+			}
+
+			header := maps.Clone(msg.Header)
+			if header == nil {
+				header = map[string]string{}
+			}
+			header[HeaderDeadLetterError] = err.Error()
+			header[HeaderDeadLetterID] = msg.ID
+			dead := &Message{Data: msg.Data, Header: header}
+			if pubErr := deadLetter.Publish(ctx, dead); pubErr != nil {
+				// Not wrapping err, as the message must be redelivered even if
+				// it failed permanently.
+				return fmt.Errorf("publish to dead letter topic %s: %w (message error: %v)", deadLetter.Name(), pubErr, err)
+			}
+			return Permanent(err)
+		}
+	}
+}
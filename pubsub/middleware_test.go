@@ -0,0 +1,112 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/log"
+	"github.com/joshjon/kit/retry"
+)
+
+var testRetryPolicy = retry.Policy{MaxAttempts: 3, InitialInterval: time.Millisecond}
+
+func TestChain(t *testing.T) {
+	var order []string
+	mw := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, msg *Message) error {
+				order = append(order, name)
+				return next(ctx, msg)
+			}
+		}
+	}
+	h := Chain(func(context.Context, *Message) error {
+		order = append(order, "handler")
+		return nil
+	}, mw("first"), mw("second"), LoggingMiddleware(log.NewLogger(log.WithNop())))
+	require.NoError(t, h(context.Background(), &Message{}))
+	assert.Equal(t, []string{"first", "second", "handler"}, order)
+}
+
+func TestRetryMiddleware(t *testing.T) {
+	ctx := context.Background()
+	deadLetter := NewMemoryTopic("orders.dead")
+	var dead []*Message
+	_, err := deadLetter.Subscribe(ctx, "ops", func(_ context.Context, msg *Message) error {
+		dead = append(dead, msg)
+		return nil
+	})
+	require.NoError(t, err)
+
+	t.Run("succeeds after retry", func(t *testing.T) {
+		attempts := 0
+		h := Chain(func(context.Context, *Message) error {
+			attempts++
+			if attempts < 2 {
+				return errors.New("transient")
+			}
+			return nil
+		}, RetryMiddleware(testRetryPolicy, deadLetter))
+		require.NoError(t, h(ctx, &Message{ID: "1"}))
+		assert.Equal(t, 2, attempts)
+		assert.Empty(t, dead)
+	})
+
+	t.Run("dead letters after retries", func(t *testing.T) {
+		attempts := 0
+		h := Chain(func(context.Context, *Message) error {
+			attempts++
+			return errors.New("boom")
+		}, RetryMiddleware(testRetryPolicy, deadLetter))
+		err := h(ctx, &Message{ID: "2", Data: []byte("a"), Header: map[string]string{"k": "v"}})
+		assert.True(t, isPermanent(err))
+		assert.Equal(t, 3, attempts)
+		require.Len(t, dead, 1)
+		assert.Equal(t, []byte("a"), dead[0].Data)
+		assert.Equal(t, "v", dead[0].Header["k"])
+		assert.Equal(t, "2", dead[0].Header[HeaderDeadLetterID])
+		assert.Equal(t, "boom", dead[0].Header[HeaderDeadLetterError])
+	})
+
+	t.Run("dead letters permanent errors without retrying", func(t *testing.T) {
+		dead = nil
+		attempts := 0
+		h := Chain(func(context.Context, *Message) error {
+			attempts++
+			return Permanent(errors.New("invalid"))
+		}, RetryMiddleware(testRetryPolicy, deadLetter))
+		assert.True(t, isPermanent(h(ctx, &Message{ID: "3"})))
+		assert.Equal(t, 1, attempts)
+		assert.Len(t, dead, 1)
+	})
+
+	t.Run("without dead letter topic", func(t *testing.T) {
+		wantErr := errors.New("boom")
+		h := Chain(func(context.Context, *Message) error {
+			return wantErr
+		}, RetryMiddleware(testRetryPolicy, nil))
+		err := h(ctx, &Message{ID: "4"})
+		assert.ErrorIs(t, err, wantErr)
+		assert.False(t, isPermanent(err))
+	})
+}
+
+func TestMetricsMiddleware(t *testing.T) {
+	stats := NewStats()
+	errs := []error{nil, errors.New("boom"), Permanent(errors.New("invalid"))}
+	for _, err := range errs {
+		h := Chain(func(context.Context, *Message) error {
+			return err
+		}, MetricsMiddleware(stats, "orders", "billing"))
+		_ = h(context.Background(), &Message{})
+	}
+
+	got := stats.Snapshot()[StatKey{Topic: "orders", Group: "billing"}]
+	assert.Equal(t, int64(3), got.Messages)
+	assert.Equal(t, map[Outcome]int64{OutcomeOK: 1, OutcomeError: 1, OutcomeDropped: 1}, got.OutcomeCounts)
+}
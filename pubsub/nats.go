@@ -0,0 +1,171 @@
+package pubsub
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+
+	"github.com/joshjon/kit/id"
+	"github.com/joshjon/kit/log"
+	"github.com/joshjon/kit/natsutil"
+)
+
+const ackTimeout = 10 * time.Second
+
+// NATSOption optionally configures a NATSTopic.
+type NATSOption func(opts *natsOptions)
+
+// WithNATSLogger sets the Logger used to log failed messages. Defaults to
+// log.NewLogger.
+func WithNATSLogger(logger log.Logger) NATSOption {
+	return func(opts *natsOptions) {
+		opts.logger = logger
+	}
+}
+
+// WithConsumerConfig sets the base config of the consumers created for
+// subscriptions, e.g. to set MaxDeliver or BackOff. The durable name, filter
+// subject and ack policy are set by the NATSTopic.
+func WithConsumerConfig(cfg jetstream.ConsumerConfig) NATSOption {
+	return func(opts *natsOptions) {
+		opts.consumerCfg = cfg
+	}
+}
+
+type natsOptions struct {
+	logger      log.Logger
+	consumerCfg jetstream.ConsumerConfig
+}
+
+// NATSTopic is a Topic of the messages published to a NATS JetStream subject.
+// Messages are published with their ID as the message ID, so messages
+// published again within the duplicate window of the stream are
+// deduplicated. Every group is a durable consumer, so messages are processed
+// once across the subscriptions of a group and resumed after restarts.
+type NATSTopic struct {
+	js      jetstream.JetStream
+	stream  string
+	subject string
+	opts    natsOptions
+}
+
+var _ Topic = (*NATSTopic)(nil)
+
+// NewNATSTopic creates a new NATSTopic of subject, which must be captured by
+// stream, e.g. created with natsutil.EnsureStream.
+func NewNATSTopic(js jetstream.JetStream, stream string, subject string, opts ...NATSOption) *NATSTopic {
+	options := natsOptions{
+		logger: log.NewLogger(),
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &NATSTopic{
+		js:      js,
+		stream:  stream,
+		subject: subject,
+		opts:    options,
+	}
+}
+
+func (t *NATSTopic) Name() string {
+	return t.subject
+}
+
+func (t *NATSTopic) Publish(ctx context.Context, msgs ...*Message) error {
+	for _, msg := range msgs {
+		if msg.ID == "" {
+			msg.ID = id.NewUUIDv7().String()
+		}
+		natsMsg := &nats.Msg{
+			Subject: t.subject,
+			Data:    msg.Data,
+			Header:  nats.Header{},
+		}
+		for k, v := range msg.Header {
+			natsMsg.Header.Set(k, v)
+		}
+		if _, err := t.js.PublishMsg(ctx, natsMsg, jetstream.WithMsgID(msg.ID)); err != nil {
+			return fmt.Errorf("publish message %s to %s: %w", msg.ID, t.subject, err)
+		}
+	}
+	return nil
+}
+
+func (t *NATSTopic) Subscribe(ctx context.Context, group string, handler Handler) (Subscription, error) {
+	cfg := t.opts.consumerCfg
+	cfg.Durable = consumerName(group, t.subject)
+	cfg.FilterSubject = t.subject
+	cfg.AckPolicy = jetstream.AckExplicitPolicy
+	consumer, err := natsutil.EnsureConsumer(ctx, t.js, t.stream, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := t.opts.logger.With("consumer", cfg.Durable)
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	cc, err := consumer.Consume(func(raw jetstream.Msg) {
+		t.process(ctx, logger, handler, raw)
+	}, jetstream.ConsumeErrHandler(func(_ jetstream.ConsumeContext, err error) {
+		logger.Warn("nats consume error", "error", err)
+	}))
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("consume: %w", err)
+	}
+	return &natsSubscription{cc: cc, cancel: cancel}, nil
+}
+
+func (t *NATSTopic) process(ctx context.Context, logger log.Logger, handler Handler, raw jetstream.Msg) {
+	msg := &Message{
+		ID:     raw.Headers().Get(jetstream.MsgIDHeader),
+		Data:   raw.Data(),
+		Header: make(map[string]string, len(raw.Headers())),
+	}
+	for k := range raw.Headers() {
+		if k != jetstream.MsgIDHeader {
+			msg.Header[k] = raw.Headers().Get(k)
+		}
+	}
+
+	var ack func() error
+	switch err := handler(ctx, msg); {
+	case err == nil:
+		ack = func() error {
+			ackCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), ackTimeout)
+			defer cancel()
+			return raw.DoubleAck(ackCtx)
+		}
+	case isPermanent(err):
+		logger.Error("message failed, terminating", "id", msg.ID, "error", err)
+		ack = raw.Term
+	default:
+		logger.Warn("message failed and will be redelivered", "id", msg.ID, "error", err)
+		ack = raw.Nak
+	}
+	if err := ack(); err != nil {
+		logger.Error("failed to acknowledge message", "id", msg.ID, "error", err)
+	}
+}
+
+type natsSubscription struct {
+	cc     jetstream.ConsumeContext
+	cancel context.CancelFunc
+}
+
+func (s *natsSubscription) Stop() {
+	s.cc.Drain()
+	<-s.cc.Closed()
+	s.cancel()
+}
+
+// consumerName returns a valid consumer name for the group subscribing to
+// subject, e.g. billing_orders_created for orders.created.
+func consumerName(group string, subject string) string {
+	r := strings.NewReplacer(".", "_", "*", "any", ">", "all")
+	return group + "_" + r.Replace(subject)
+}
@@ -0,0 +1,59 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/log"
+	"github.com/joshjon/kit/natsutil"
+	"github.com/joshjon/kit/testutil"
+)
+
+func TestNATSTopic(t *testing.T) {
+	ctx := context.Background()
+	nc, err := nats.Connect(testutil.StartNATS(t))
+	require.NoError(t, err)
+	t.Cleanup(nc.Close)
+	js, err := jetstream.New(nc)
+	require.NoError(t, err)
+	_, err = natsutil.EnsureStream(ctx, js, jetstream.StreamConfig{Name: "orders", Subjects: []string{"orders.>"}})
+	require.NoError(t, err)
+
+	topic := NewNATSTopic(js, "orders", "orders.created", WithNATSLogger(log.NewLogger(log.WithNop())))
+	assert.Equal(t, "orders.created", topic.Name())
+
+	received := make(chan *Message, 3)
+	attempts := 0
+	sub, err := topic.Subscribe(ctx, "billing", func(_ context.Context, msg *Message) error {
+		attempts++
+		if attempts == 1 {
+			return errors.New("transient")
+		}
+		received <- msg
+		return nil
+	})
+	require.NoError(t, err)
+	defer sub.Stop()
+
+	// published twice and deduplicated
+	msg := &Message{ID: "1", Data: []byte("a"), Header: map[string]string{"k": "v"}}
+	require.NoError(t, topic.Publish(ctx, msg))
+	require.NoError(t, topic.Publish(ctx, msg))
+
+	got := testutil.AssertReceiveChan(t, received, 5*time.Second)
+	assert.Equal(t, "1", got.ID)
+	assert.Equal(t, []byte("a"), got.Data)
+	assert.Equal(t, "v", got.Header["k"])
+	select {
+	case dup := <-received:
+		t.Fatalf("duplicate message delivered: %s", dup.ID)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,67 @@
+// Package pubsub publishes messages to topics and subscribes to them
+// independently of the transport, so application code can be tested with a
+// MemoryTopic and run against NATS JetStream with a NATSTopic. Typed wraps a
+// Topic to publish and receive values encoded with a Codec, and Middleware
+// adds logging, metrics and retries with dead-lettering to handlers.
+package pubsub
+
+import (
+	"context"
+	"errors"
+)
+
+// Message is a message published to a Topic.
+type Message struct {
+	// ID identifies the message. It is set when the message is published if
+	// empty, and used by transports supporting it to deduplicate messages
+	// published more than once.
+	ID     string
+	Data   []byte
+	Header map[string]string
+}
+
+// Handler processes a message. Returning an error redelivers the message,
+// unless wrapped with Permanent.
+type Handler func(ctx context.Context, msg *Message) error
+
+// Topic is a named stream of messages.
+type Topic interface {
+	// Name returns the name of the topic.
+	Name() string
+	// Publish publishes msgs in order. Messages may have been published even
+	// if an error is returned.
+	Publish(ctx context.Context, msgs ...*Message) error
+	// Subscribe calls handler with the messages published to the topic until
+	// the returned Subscription is stopped. Each message is delivered to one
+	// subscription of every group, so replicas of a service sharing a group
+	// process it once.
+	Subscribe(ctx context.Context, group string, handler Handler) (Subscription, error)
+}
+
+// Subscription is a subscription to a Topic.
+type Subscription interface {
+	// Stop stops the subscription and waits for the messages being handled to
+	// return.
+	Stop()
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err returned by a Handler so the message is dropped rather
+// than redelivered.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+func isPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
@@ -0,0 +1,59 @@
+package pubsub
+
+import (
+	"context"
+	"fmt"
+)
+
+// Typed publishes and receives values of type T encoded with a Codec on a
+// Topic.
+type Typed[T any] struct {
+	topic Topic
+	codec Codec[T]
+}
+
+// NewTyped creates a Typed of values on topic encoded with codec.
+func NewTyped[T any](topic Topic, codec Codec[T]) *Typed[T] {
+	return &Typed[T]{
+		topic: topic,
+		codec: codec,
+	}
+}
+
+// Topic returns the underlying Topic.
+func (t *Typed[T]) Topic() Topic {
+	return t.topic
+}
+
+// Publish publishes values in order.
+func (t *Typed[T]) Publish(ctx context.Context, values ...T) error {
+	msgs := make([]*Message, len(values))
+	for i, v := range values {
+		data, err := t.codec.Encode(v)
+		if err != nil {
+			return fmt.Errorf("encode message: %w", err)
+		}
+		msgs[i] = &Message{
+			Data:   data,
+			Header: map[string]string{HeaderContentType: t.codec.ContentType()},
+		}
+	}
+	return t.topic.Publish(ctx, msgs...)
+}
+
+// TypedHandler processes a decoded value and the message it was decoded from.
+type TypedHandler[T any] func(ctx context.Context, v T, msg *Message) error
+
+// Subscribe subscribes handler to the values published to the topic, see
+// Topic.Subscribe. Messages that can't be decoded are dropped. Middlewares
+// wrap the handler of the raw messages, in order.
+func (t *Typed[T]) Subscribe(ctx context.Context, group string, handler TypedHandler[T], middlewares ...Middleware) (Subscription, error) {
+	h := func(ctx context.Context, msg *Message) error {
+		v, err := t.codec.Decode(msg.Data)
+		if err != nil {
+			return Permanent(fmt.Errorf("decode message %s: %w", msg.ID, err))
+		}
+		return handler(ctx, v, msg)
+	}
+	return t.topic.Subscribe(ctx, group, Chain(h, middlewares...))
+}
@@ -0,0 +1,66 @@
+package pubsub
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type order struct {
+	ID string `json:"id"`
+}
+
+func TestTyped_JSONCodec(t *testing.T) {
+	ctx := context.Background()
+	topic := NewTyped(NewMemoryTopic("orders"), JSONCodec[order]())
+
+	var got []order
+	_, err := topic.Subscribe(ctx, "billing", func(_ context.Context, v order, msg *Message) error {
+		assert.Equal(t, "application/json", msg.Header[HeaderContentType])
+		got = append(got, v)
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, topic.Publish(ctx, order{ID: "1"}, order{ID: "2"}))
+	assert.Equal(t, []order{{ID: "1"}, {ID: "2"}}, got)
+}
+
+func TestTyped_decodeError(t *testing.T) {
+	ctx := context.Background()
+	raw := NewMemoryTopic("orders")
+	topic := NewTyped(raw, JSONCodec[order]())
+
+	var dropped error
+	_, err := topic.Subscribe(ctx, "billing", func(context.Context, order, *Message) error {
+		t.Fatal("handler called")
+		return nil
+	}, func(next Handler) Handler {
+		return func(ctx context.Context, msg *Message) error {
+			dropped = next(ctx, msg)
+			return dropped
+		}
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, raw.Publish(ctx, &Message{Data: []byte("invalid")}))
+	assert.True(t, isPermanent(dropped))
+}
+
+func TestProtoCodec(t *testing.T) {
+	codec := ProtoCodec[wrapperspb.StringValue]()
+	data, err := codec.Encode(wrapperspb.String("hello"))
+	require.NoError(t, err)
+
+	got, err := codec.Decode(data)
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(wrapperspb.String("hello"), got))
+	assert.Equal(t, "application/protobuf", codec.ContentType())
+
+	_, err = codec.Decode([]byte{0xff})
+	assert.Error(t, err)
+}
@@ -0,0 +1,180 @@
+// Package retry retries operations with exponential backoff and jitter.
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"github.com/joshjon/kit/clock"
+	"github.com/joshjon/kit/errtag"
+)
+
+const (
+	defaultMaxAttempts     = 3
+	defaultInitialInterval = 100 * time.Millisecond
+	defaultMaxInterval     = 10 * time.Second
+	defaultMultiplier      = 2
+	defaultJitter          = 0.2
+)
+
+// Policy configures how an operation is retried. Zero values use the
+// defaults.
+type Policy struct {
+	// MaxAttempts is the maximum number of attempts, including the first.
+	// Defaults to 3. A negative value retries until MaxElapsedTime passes or
+	// the context is done.
+	MaxAttempts int
+	// InitialInterval is the backoff before the first retry, multiplied by
+	// Multiplier for every subsequent retry up to MaxInterval. Defaults to
+	// 100ms, 10s and 2. Set MaxInterval to InitialInterval for a constant
+	// backoff.
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	Multiplier      float64
+	// Jitter randomizes every backoff by up to ±Jitter of its duration, so
+	// clients retrying at the same time spread out. Defaults to 0.2. A
+	// negative value disables jitter.
+	Jitter float64
+	// MaxElapsedTime stops retrying once the next retry would start after it
+	// passed since the first attempt. Defaults to 0, which is no limit.
+	MaxElapsedTime time.Duration
+	// RetryOn reports whether an error is retried. Defaults to Retryable.
+	// Errors wrapped with Permanent are never retried.
+	RetryOn func(err error) bool
+	// OnRetry is called before waiting to retry with the number of the failed
+	// attempt (starting at 1), its error and the backoff until the retry,
+	// e.g. to log attempts.
+	OnRetry func(attempt int, err error, backoff time.Duration)
+	// Clock is used to wait between attempts. Defaults to clock.Real.
+	Clock clock.Clock
+}
+
+func (p Policy) withDefaults() Policy {
+	if p.MaxAttempts == 0 {
+		p.MaxAttempts = defaultMaxAttempts
+	}
+	if p.InitialInterval == 0 {
+		p.InitialInterval = defaultInitialInterval
+	}
+	if p.MaxInterval == 0 {
+		p.MaxInterval = max(defaultMaxInterval, p.InitialInterval)
+	}
+	if p.Multiplier == 0 {
+		p.Multiplier = defaultMultiplier
+	}
+	if p.Jitter == 0 {
+		p.Jitter = defaultJitter
+	}
+	if p.RetryOn == nil {
+		p.RetryOn = Retryable
+	}
+	if p.Clock == nil {
+		p.Clock = clock.Real
+	}
+	return p
+}
+
+// Backoff returns the backoff before the retry following the failed attempt,
+// starting at 1.
+func (p Policy) Backoff(attempt int) time.Duration {
+	p = p.withDefaults()
+	d := float64(p.InitialInterval) * math.Pow(p.Multiplier, float64(attempt-1))
+	d = min(d, float64(p.MaxInterval))
+	if p.Jitter > 0 {
+		d += d * p.Jitter * (2*rand.Float64() - 1) //nolint:gosec
+	}
+	return time.Duration(d)
+}
+
+// Do calls fn until it succeeds or policy stops retrying, returning the
+// result of the last attempt. Once ctx is done retrying stops, returning the
+// context error wrapped together with the last error.
+func Do[T any](ctx context.Context, policy Policy, fn func(ctx context.Context) (T, error)) (T, error) {
+	policy = policy.withDefaults()
+	start := policy.Clock.Now()
+
+	for attempt := 1; ; attempt++ {
+		res, err := fn(ctx)
+		if err == nil {
+			return res, nil
+		}
+
+		var permanent *permanentError
+		if errors.As(err, &permanent) {
+			return res, permanent.err
+		}
+		if ctx.Err() != nil {
+			return res, contextError(ctx, err)
+		}
+		if attempt == policy.MaxAttempts || !policy.RetryOn(err) {
+			return res, err
+		}
+
+		backoff := policy.Backoff(attempt)
+		if policy.MaxElapsedTime > 0 && policy.Clock.Since(start)+backoff > policy.MaxElapsedTime {
+			return res, err
+		}
+		if policy.OnRetry != nil {
+			policy.OnRetry(attempt, err, backoff)
+		}
+
+		timer := policy.Clock.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return res, contextError(ctx, err)
+		case <-timer.C():
+		}
+	}
+}
+
+func contextError(ctx context.Context, err error) error {
+	if errors.Is(err, ctx.Err()) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", ctx.Err(), err)
+}
+
+// DoErr is Do for operations without a result.
+func DoErr(ctx context.Context, policy Policy, fn func(ctx context.Context) error) error {
+	_, err := Do(ctx, policy, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	})
+	return err
+}
+
+// Retryable is the default classification of errors to retry. Errors tagged
+// with errtag are retried if errtag.Retryable classifies them as transient,
+// and any other error is retried, including timeouts of individual attempts.
+// Retrying always stops once the context passed to Do is done.
+func Retryable(err error) bool {
+	if _, ok := errtag.Primary(err); ok {
+		return errtag.Retryable(err)
+	}
+	return true
+}
+
+// Permanent wraps err so it is returned without retrying. Do returns err
+// unwrapped.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,178 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/errtag"
+)
+
+var errTransient = errors.New("transient")
+
+func testPolicy() Policy {
+	return Policy{
+		InitialInterval: time.Millisecond,
+		Jitter:          -1,
+	}
+}
+
+func TestDo(t *testing.T) {
+	calls := 0
+	res, err := Do(context.Background(), testPolicy(), func(ctx context.Context) (string, error) {
+		calls++
+		if calls < 3 {
+			return "", errTransient
+		}
+		return "ok", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ok", res)
+	assert.Equal(t, 3, calls)
+}
+
+func TestDo_maxAttempts(t *testing.T) {
+	policy := testPolicy()
+	policy.MaxAttempts = 5
+
+	calls := 0
+	err := DoErr(context.Background(), policy, func(ctx context.Context) error {
+		calls++
+		return errTransient
+	})
+	assert.ErrorIs(t, err, errTransient)
+	assert.Equal(t, 5, calls)
+}
+
+func TestDo_maxElapsedTime(t *testing.T) {
+	policy := testPolicy()
+	policy.MaxAttempts = -1
+	policy.InitialInterval = 10 * time.Millisecond
+	policy.MaxElapsedTime = 50 * time.Millisecond
+
+	calls := 0
+	err := DoErr(context.Background(), policy, func(ctx context.Context) error {
+		calls++
+		return errTransient
+	})
+	assert.ErrorIs(t, err, errTransient)
+	assert.Equal(t, 3, calls) // retries after 10ms and 20ms, the next after 40ms
+}
+
+func TestDo_retryOn(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantCalls int
+	}{
+		{name: "untagged", err: errTransient, wantCalls: 3},
+		{name: "per attempt timeout", err: fmt.Errorf("%w: %w", errTransient, context.DeadlineExceeded), wantCalls: 3},
+		{name: "retryable tag", err: errtag.Tag[errtag.Unavailable](errTransient), wantCalls: 3},
+		{name: "non retryable tag", err: errtag.Tag[errtag.NotFound](errTransient), wantCalls: 1},
+		{name: "permanent", err: Permanent(errTransient), wantCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := DoErr(context.Background(), testPolicy(), func(ctx context.Context) error {
+				calls++
+				return tt.err
+			})
+			assert.ErrorIs(t, err, errTransient)
+			assert.Equal(t, tt.wantCalls, calls)
+		})
+	}
+}
+
+func TestDo_permanentUnwrapped(t *testing.T) {
+	err := DoErr(context.Background(), testPolicy(), func(ctx context.Context) error {
+		return Permanent(errTransient)
+	})
+	assert.Equal(t, errTransient, err)
+}
+
+func TestDo_customRetryOn(t *testing.T) {
+	policy := testPolicy()
+	policy.RetryOn = func(err error) bool {
+		return !errors.Is(err, errTransient)
+	}
+
+	calls := 0
+	err := DoErr(context.Background(), policy, func(ctx context.Context) error {
+		calls++
+		return errTransient
+	})
+	assert.ErrorIs(t, err, errTransient)
+	assert.Equal(t, 1, calls)
+}
+
+func TestDo_onRetry(t *testing.T) {
+	policy := testPolicy()
+	policy.MaxAttempts = 4
+	policy.InitialInterval = time.Millisecond
+
+	var attempts []int
+	var backoffs []time.Duration
+	policy.OnRetry = func(attempt int, err error, backoff time.Duration) {
+		assert.ErrorIs(t, err, errTransient)
+		attempts = append(attempts, attempt)
+		backoffs = append(backoffs, backoff)
+	}
+
+	_ = DoErr(context.Background(), policy, func(ctx context.Context) error {
+		return errTransient
+	})
+	assert.Equal(t, []int{1, 2, 3}, attempts)
+	assert.Equal(t, []time.Duration{time.Millisecond, 2 * time.Millisecond, 4 * time.Millisecond}, backoffs)
+}
+
+func TestDo_contextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	policy := testPolicy()
+	policy.MaxAttempts = -1
+	policy.InitialInterval = time.Hour
+	policy.OnRetry = func(int, error, time.Duration) {
+		cancel()
+	}
+
+	calls := 0
+	err := DoErr(ctx, policy, func(ctx context.Context) error {
+		calls++
+		return errTransient
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorIs(t, err, errTransient)
+	assert.Equal(t, 1, calls)
+}
+
+func TestPolicy_Backoff(t *testing.T) {
+	policy := Policy{
+		InitialInterval: 100 * time.Millisecond,
+		MaxInterval:     time.Second,
+		Jitter:          -1,
+	}
+	assert.Equal(t, 100*time.Millisecond, policy.Backoff(1))
+	assert.Equal(t, 200*time.Millisecond, policy.Backoff(2))
+	assert.Equal(t, 800*time.Millisecond, policy.Backoff(4))
+	assert.Equal(t, time.Second, policy.Backoff(5))
+	assert.Equal(t, time.Second, policy.Backoff(50))
+
+	policy.Jitter = 0.5
+	for range 100 {
+		backoff := policy.Backoff(1)
+		assert.GreaterOrEqual(t, backoff, 50*time.Millisecond)
+		assert.LessOrEqual(t, backoff, 150*time.Millisecond)
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	assert.True(t, Retryable(errTransient))
+	assert.True(t, Retryable(context.DeadlineExceeded))
+	assert.True(t, Retryable(errtag.Tag[errtag.GatewayTimeout](errTransient)))
+	assert.False(t, Retryable(errtag.Tag[errtag.InvalidArgument](errTransient)))
+}
@@ -0,0 +1,28 @@
+package schedule
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/joshjon/kit/pgdb"
+)
+
+const lockKeyPrefix = "schedule:"
+
+// PostgresLocker is a Locker using Postgres advisory locks, holding a
+// connection of its pool while a job is locked.
+type PostgresLocker struct {
+	pool *pgxpool.Pool
+}
+
+var _ Locker = (*PostgresLocker)(nil)
+
+// NewPostgresLocker creates a new PostgresLocker.
+func NewPostgresLocker(pool *pgxpool.Pool) *PostgresLocker {
+	return &PostgresLocker{pool: pool}
+}
+
+func (l *PostgresLocker) TryLock(ctx context.Context, name string) (func(), bool, error) {
+	return pgdb.TryAdvisoryLock(ctx, l.pool, pgdb.AdvisoryLockKey(lockKeyPrefix+name))
+}
@@ -0,0 +1,36 @@
+package schedule
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/testutil"
+)
+
+func TestPostgresLocker(t *testing.T) {
+	ctx := context.Background()
+	locker := NewPostgresLocker(testutil.StartPostgres(t, nil))
+
+	release, acquired, err := locker.TryLock(ctx, "job")
+	require.NoError(t, err)
+	require.True(t, acquired)
+
+	// advisory locks are reentrant per session, so lock from another session
+	_, acquired, err = locker.TryLock(ctx, "job")
+	require.NoError(t, err)
+	assert.False(t, acquired)
+
+	other, acquired, err := locker.TryLock(ctx, "other")
+	require.NoError(t, err)
+	require.True(t, acquired)
+	other()
+
+	release()
+	release, acquired, err = locker.TryLock(ctx, "job")
+	require.NoError(t, err)
+	require.True(t, acquired)
+	release()
+}
@@ -0,0 +1,52 @@
+// Package schedule runs jobs on cron or interval schedules, optionally locked
+// so only one replica of a service runs each occurrence.
+package schedule
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Schedule returns the times a job runs.
+type Schedule interface {
+	// Next returns the next time after t, or the zero time if there is none.
+	Next(t time.Time) time.Time
+}
+
+// Cron parses a standard 5 field cron expression (minute, hour, day of month,
+// month and day of week), or a descriptor such as @hourly or @daily. Times
+// are in the local time zone unless the expression is prefixed with
+// CRON_TZ=<zone>, e.g. "CRON_TZ=UTC 0 3 * * *".
+func Cron(expr string) (Schedule, error) {
+	schedule, err := cron.ParseStandard(expr)
+	if err != nil {
+		return nil, fmt.Errorf("parse cron expression %q: %w", expr, err)
+	}
+	return schedule, nil
+}
+
+// MustCron is like Cron but panics if expr cannot be parsed.
+func MustCron(expr string) Schedule {
+	schedule, err := Cron(expr)
+	if err != nil {
+		panic(err)
+	}
+	return schedule
+}
+
+// Every returns a Schedule running every d, starting d after the scheduler
+// starts. Panics if d is not positive.
+func Every(d time.Duration) Schedule {
+	if d <= 0 {
+		panic("schedule: non-positive interval for Every")
+	}
+	return every(d)
+}
+
+type every time.Duration
+
+func (e every) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(e))
+}
@@ -0,0 +1,30 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCron(t *testing.T) {
+	s, err := Cron("CRON_TZ=UTC 30 3 * * 1-5")
+	require.NoError(t, err)
+	friday := time.Date(2026, 1, 2, 4, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2026, 1, 5, 3, 30, 0, 0, time.UTC), s.Next(friday).UTC())
+
+	s, err = Cron("@daily")
+	require.NoError(t, err)
+	assert.NotZero(t, s.Next(friday))
+
+	_, err = Cron("* * *")
+	assert.Error(t, err)
+	assert.Panics(t, func() { MustCron("invalid") })
+}
+
+func TestEvery(t *testing.T) {
+	now := time.Now()
+	assert.Equal(t, now.Add(time.Minute), Every(time.Minute).Next(now))
+	assert.Panics(t, func() { Every(0) })
+}
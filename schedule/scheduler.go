@@ -0,0 +1,263 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/joshjon/kit/clock"
+	"github.com/joshjon/kit/log"
+	"github.com/joshjon/kit/metrics"
+)
+
+const runDurationName = "schedule.run.duration"
+
+// Job is a func run on a schedule.
+type Job func(ctx context.Context) error
+
+// Locker locks jobs so only one replica runs each occurrence of a job.
+type Locker interface {
+	// TryLock tries to acquire the lock of the job name without waiting,
+	// reporting whether it was acquired. An acquired lock is held until
+	// released with the returned func.
+	TryLock(ctx context.Context, name string) (release func(), acquired bool, err error)
+}
+
+// Option optionally configures a Scheduler.
+type Option func(opts *options)
+
+// WithLogger sets the Logger used to log runs.
+func WithLogger(logger log.Logger) Option {
+	return func(opts *options) {
+		opts.logger = logger
+	}
+}
+
+// WithLocker locks every run of jobs with locker, e.g. a PostgresLocker, so
+// only one replica runs each occurrence. Replicas that fail to acquire the
+// lock skip the occurrence.
+func WithLocker(locker Locker) Option {
+	return func(opts *options) {
+		opts.locker = locker
+	}
+}
+
+// WithMetrics records the duration of runs in a schedule.run.duration
+// histogram of provider, by job and outcome.
+func WithMetrics(provider *metrics.Provider) Option {
+	return func(opts *options) {
+		opts.metrics = provider
+	}
+}
+
+// WithClock sets the clock used to schedule runs. Defaults to clock.Real.
+func WithClock(clk clock.Clock) Option {
+	return func(opts *options) {
+		opts.clock = clk
+	}
+}
+
+type options struct {
+	logger  log.Logger
+	locker  Locker
+	metrics *metrics.Provider
+	clock   clock.Clock
+}
+
+// JobOption optionally configures a job.
+type JobOption func(opts *jobOptions)
+
+// WithTimeout sets the timeout of each run of the job. Defaults to 0, which
+// is no timeout.
+func WithTimeout(d time.Duration) JobOption {
+	return func(opts *jobOptions) {
+		opts.timeout = d
+	}
+}
+
+// WithoutLock runs the job on every replica even if the Scheduler has a
+// Locker, e.g. for jobs cleaning up local state.
+func WithoutLock() JobOption {
+	return func(opts *jobOptions) {
+		opts.noLock = true
+	}
+}
+
+type jobOptions struct {
+	timeout time.Duration
+	noLock  bool
+}
+
+type job struct {
+	name     string
+	schedule Schedule
+	fn       Job
+	opts     jobOptions
+	running  atomic.Bool
+}
+
+// Scheduler runs jobs on their schedule. A run is skipped if the previous run
+// of the job is still running.
+type Scheduler struct {
+	opts     options
+	jobs     []*job
+	duration metric.Float64Histogram
+}
+
+// New creates a new Scheduler.
+func New(opts ...Option) *Scheduler {
+	options := options{
+		logger: log.NewLogger(),
+		clock:  clock.Real,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &Scheduler{opts: options}
+}
+
+// Add runs fn on schedule. The name identifies the job in logs, metrics and
+// locks, so it must be unique. It must be called before Run.
+func (s *Scheduler) Add(name string, schedule Schedule, fn Job, opts ...JobOption) {
+	var options jobOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	s.jobs = append(s.jobs, &job{
+		name:     name,
+		schedule: schedule,
+		fn:       fn,
+		opts:     options,
+	})
+}
+
+// Run runs jobs on their schedule until ctx is done, then waits for running
+// jobs to return. Running jobs have their context canceled.
+func (s *Scheduler) Run(ctx context.Context) error {
+	if len(s.jobs) == 0 {
+		return fmt.Errorf("schedule: no jobs to run")
+	}
+	if s.opts.metrics != nil {
+		var err error
+		s.duration, err = s.opts.metrics.Histogram(runDurationName, "Duration of scheduled job runs.", metrics.WithUnit("s"))
+		if err != nil {
+			return err
+		}
+	}
+
+	var wg sync.WaitGroup
+	for _, j := range s.jobs {
+		wg.Go(func() {
+			s.schedule(ctx, &wg, j)
+		})
+	}
+	wg.Wait()
+	return nil
+}
+
+func (s *Scheduler) schedule(ctx context.Context, wg *sync.WaitGroup, j *job) {
+	next := j.schedule.Next(s.opts.clock.Now())
+	for !next.IsZero() {
+		timer := s.opts.clock.NewTimer(next.Sub(s.opts.clock.Now()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C():
+		}
+
+		scheduled := next
+		now := s.opts.clock.Now()
+		// Skip occurrences missed while running behind, e.g. after the
+		// machine was suspended.
+		for next = j.schedule.Next(scheduled); !next.IsZero() && !next.After(now); {
+			next = j.schedule.Next(next)
+		}
+
+		if !j.running.CompareAndSwap(false, true) {
+			s.opts.logger.Warn("skipped job run since the previous run is still running", "job", j.name)
+			continue
+		}
+		following := next
+		wg.Go(func() {
+			defer j.running.Store(false)
+			s.run(ctx, j, scheduled, following)
+		})
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context, j *job, scheduled time.Time, next time.Time) {
+	logger := s.opts.logger.With("job", j.name)
+
+	if s.opts.locker != nil && !j.opts.noLock {
+		release, acquired, err := s.opts.locker.TryLock(ctx, j.name)
+		if err != nil {
+			logger.Error("failed to lock job", "error", err)
+			return
+		}
+		if !acquired {
+			logger.Debug("skipped job run locked by another replica")
+			return
+		}
+		defer func() {
+			// Hold the lock until halfway to the next run so replicas whose
+			// clocks are slightly behind do not run the same occurrence.
+			if !next.IsZero() {
+				s.sleepUntil(ctx, scheduled.Add(next.Sub(scheduled)/2))
+			}
+			release()
+		}()
+	}
+
+	runCtx := ctx
+	if j.opts.timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, j.opts.timeout)
+		defer cancel()
+	}
+
+	start := s.opts.clock.Now()
+	err := runJob(runCtx, j.fn)
+	duration := s.opts.clock.Since(start)
+
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+		logger.Error("job run failed", "error", err, "duration", duration)
+	} else {
+		logger.Info("job run succeeded", "duration", duration)
+	}
+	if s.duration != nil {
+		s.duration.Record(context.WithoutCancel(ctx), duration.Seconds(), metric.WithAttributes(
+			attribute.String("job", j.name),
+			attribute.String("outcome", outcome),
+		))
+	}
+}
+
+func (s *Scheduler) sleepUntil(ctx context.Context, t time.Time) {
+	d := t.Sub(s.opts.clock.Now())
+	if d <= 0 {
+		return
+	}
+	timer := s.opts.clock.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C():
+	}
+}
+
+func runJob(ctx context.Context, fn Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return fn(ctx)
+}
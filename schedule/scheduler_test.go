@@ -0,0 +1,254 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/joshjon/kit/log"
+	"github.com/joshjon/kit/metrics"
+	"github.com/joshjon/kit/testutil"
+)
+
+var start = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func newTestScheduler(clk *testutil.FakeClock, opts ...Option) *Scheduler {
+	opts = append([]Option{
+		WithClock(clk),
+		WithLogger(log.NewLogger(log.WithNop())),
+	}, opts...)
+	return New(opts...)
+}
+
+// runScheduler runs s until the test finishes.
+func runScheduler(t *testing.T, s *Scheduler) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		require.NoError(t, <-done)
+	})
+}
+
+// awaitRuns waits until runs is n.
+func awaitRuns(t *testing.T, runs *atomic.Int32, n int32) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		return runs.Load() == n
+	}, time.Second, time.Millisecond)
+}
+
+// awaitWaiters waits until n timers are pending on clk.
+func awaitWaiters(t *testing.T, clk *testutil.FakeClock, n int) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		return clk.Waiters() == n
+	}, time.Second, time.Millisecond)
+}
+
+func TestScheduler_every(t *testing.T) {
+	clk := testutil.NewFakeClock(start)
+	var runs atomic.Int32
+	s := newTestScheduler(clk)
+	s.Add("count", Every(time.Minute), func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	})
+	runScheduler(t, s)
+
+	for i := range int32(3) {
+		awaitWaiters(t, clk, 1)
+		clk.Advance(time.Minute)
+		awaitRuns(t, &runs, i+1)
+	}
+}
+
+func TestScheduler_cron(t *testing.T) {
+	clk := testutil.NewFakeClock(start.Add(30 * time.Minute))
+	ran := make(chan time.Time, 1)
+	s := newTestScheduler(clk)
+	s.Add("hourly", MustCron("CRON_TZ=UTC 0 * * * *"), func(ctx context.Context) error {
+		ran <- clk.Now()
+		return nil
+	})
+	runScheduler(t, s)
+
+	awaitWaiters(t, clk, 1)
+	clk.Advance(29 * time.Minute)
+	select {
+	case <-ran:
+		t.Fatal("ran before the hour")
+	case <-time.After(10 * time.Millisecond):
+	}
+	clk.Advance(time.Minute)
+	assert.Equal(t, start.Add(time.Hour), <-ran)
+}
+
+func TestScheduler_skipsOverlappingRuns(t *testing.T) {
+	clk := testutil.NewFakeClock(start)
+	var runs atomic.Int32
+	release := make(chan struct{})
+	s := newTestScheduler(clk)
+	s.Add("slow", Every(time.Minute), func(ctx context.Context) error {
+		runs.Add(1)
+		<-release
+		return nil
+	})
+	runScheduler(t, s)
+
+	awaitWaiters(t, clk, 1)
+	clk.Advance(time.Minute)
+	awaitRuns(t, &runs, 1)
+	awaitWaiters(t, clk, 1)
+	clk.Advance(time.Minute) // still running
+	awaitWaiters(t, clk, 1)
+	close(release)
+	assert.Equal(t, int32(1), runs.Load())
+}
+
+func TestScheduler_timeoutAndPanics(t *testing.T) {
+	clk := testutil.NewFakeClock(start)
+	reader := sdkmetric.NewManualReader()
+	provider, err := metrics.New(context.Background(), metrics.Config{ServiceName: "test"}, metrics.WithReader(reader))
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	wg.Add(3)
+	s := newTestScheduler(clk, WithMetrics(provider))
+	s.Add("timeout", Every(time.Minute), func(ctx context.Context) error {
+		defer wg.Done()
+		<-ctx.Done()
+		return ctx.Err()
+	}, WithTimeout(time.Millisecond))
+	s.Add("panic", Every(time.Minute), func(ctx context.Context) error {
+		defer wg.Done()
+		panic("boom")
+	})
+	s.Add("ok", Every(time.Minute), func(ctx context.Context) error {
+		defer wg.Done()
+		return nil
+	})
+	runScheduler(t, s)
+
+	awaitWaiters(t, clk, 3)
+	clk.Advance(time.Minute)
+	wg.Wait()
+
+	outcomes := map[string]string{}
+	require.Eventually(t, func() bool {
+		var rm metricdata.ResourceMetrics
+		require.NoError(t, reader.Collect(context.Background(), &rm))
+		for _, sm := range rm.ScopeMetrics {
+			for _, m := range sm.Metrics {
+				if m.Name != runDurationName {
+					continue
+				}
+				for _, dp := range m.Data.(metricdata.Histogram[float64]).DataPoints {
+					job, _ := dp.Attributes.Value(attribute.Key("job"))
+					outcome, _ := dp.Attributes.Value(attribute.Key("outcome"))
+					outcomes[job.AsString()] = outcome.AsString()
+				}
+			}
+		}
+		return len(outcomes) == 3
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, map[string]string{"timeout": "error", "panic": "error", "ok": "success"}, outcomes)
+}
+
+type memLocker struct {
+	mu     sync.Mutex
+	locked map[string]bool
+}
+
+func (l *memLocker) TryLock(_ context.Context, name string) (func(), bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.locked[name] {
+		return nil, false, nil
+	}
+	l.locked[name] = true
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		delete(l.locked, name)
+	}, true, nil
+}
+
+func (l *memLocker) isLocked(name string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.locked[name]
+}
+
+func TestScheduler_locker(t *testing.T) {
+	clk := testutil.NewFakeClock(start)
+	locker := &memLocker{locked: map[string]bool{}}
+
+	var runs, unlockedRuns atomic.Int32
+	for range 2 {
+		s := newTestScheduler(clk, WithLocker(locker))
+		s.Add("locked", Every(time.Minute), func(ctx context.Context) error {
+			runs.Add(1)
+			return nil
+		})
+		s.Add("unlocked", Every(time.Minute), func(ctx context.Context) error {
+			unlockedRuns.Add(1)
+			return nil
+		}, WithoutLock())
+		runScheduler(t, s)
+	}
+
+	awaitWaiters(t, clk, 4)
+	clk.Advance(time.Minute)
+	awaitRuns(t, &runs, 1)
+	awaitRuns(t, &unlockedRuns, 2)
+	// the lock is held until halfway to the next run
+	awaitWaiters(t, clk, 5)
+	assert.True(t, locker.isLocked("locked"))
+
+	clk.Advance(30 * time.Second)
+	assert.Eventually(t, func() bool {
+		return !locker.isLocked("locked")
+	}, time.Second, time.Millisecond)
+}
+
+func TestScheduler_lockError(t *testing.T) {
+	clk := testutil.NewFakeClock(start)
+	var runs atomic.Int32
+	s := newTestScheduler(clk, WithLocker(lockerFunc(func(context.Context, string) (func(), bool, error) {
+		return nil, false, errors.New("unavailable")
+	})))
+	s.Add("locked", Every(time.Minute), func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	})
+	runScheduler(t, s)
+
+	awaitWaiters(t, clk, 1)
+	clk.Advance(time.Minute)
+	awaitWaiters(t, clk, 1)
+	assert.Zero(t, runs.Load())
+}
+
+type lockerFunc func(ctx context.Context, name string) (func(), bool, error)
+
+func (f lockerFunc) TryLock(ctx context.Context, name string) (func(), bool, error) {
+	return f(ctx, name)
+}
+
+func TestScheduler_Run_noJobs(t *testing.T) {
+	assert.Error(t, New().Run(context.Background()))
+}
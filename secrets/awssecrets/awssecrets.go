@@ -0,0 +1,74 @@
+// Package awssecrets provides a secrets.Provider reading secrets from AWS
+// Secrets Manager.
+package awssecrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+
+	"github.com/joshjon/kit/errtag"
+	"github.com/joshjon/kit/secrets"
+)
+
+// Client is the subset of the *secretsmanager.Client API used by Provider.
+type Client interface {
+	GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error)
+}
+
+// Provider is a secrets.Provider reading the current version of secrets from
+// AWS Secrets Manager. The name of a secret is its ID or ARN, optionally
+// followed by # and a key to read from a secret stored as a JSON object, e.g.
+// prod/db#password.
+type Provider struct {
+	client Client
+}
+
+var _ secrets.Provider = (*Provider)(nil)
+
+// New creates a new Provider using client, e.g.
+// secretsmanager.NewFromConfig(cfg).
+func New(client Client) *Provider {
+	return &Provider{client: client}
+}
+
+func (p *Provider) GetSecret(ctx context.Context, name string) ([]byte, error) {
+	id, key, hasKey := strings.Cut(name, "#")
+	out, err := p.client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(id),
+	})
+	if err != nil {
+		var notFound *types.ResourceNotFoundException
+		if errors.As(err, &notFound) {
+			return nil, errtag.Wrapf[errtag.NotFound](err, "secret %s not found", name)
+		}
+		return nil, fmt.Errorf("get aws secret %s: %w", id, err)
+	}
+
+	value := out.SecretBinary
+	if out.SecretString != nil {
+		value = []byte(*out.SecretString)
+	}
+	if !hasKey {
+		return value, nil
+	}
+
+	var fields map[string]any
+	if err = json.Unmarshal(value, &fields); err != nil {
+		return nil, fmt.Errorf("decode aws secret %s: %w", id, err)
+	}
+	field, ok := fields[key]
+	if !ok {
+		return nil, errtag.Errorf[errtag.NotFound]("secret %s not found: aws secret %s has no key %s", name, id, key)
+	}
+	if s, ok := field.(string); ok {
+		return []byte(s), nil
+	}
+	return json.Marshal(field)
+}
@@ -0,0 +1,63 @@
+package awssecrets
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/errtag"
+)
+
+type fakeClient map[string]*secretsmanager.GetSecretValueOutput
+
+func (c fakeClient) GetSecretValue(_ context.Context, params *secretsmanager.GetSecretValueInput, _ ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error) {
+	out, ok := c[aws.ToString(params.SecretId)]
+	if !ok {
+		return nil, &types.ResourceNotFoundException{Message: aws.String("not found")}
+	}
+	if out == nil {
+		return nil, errors.New("access denied")
+	}
+	return out, nil
+}
+
+func TestProvider(t *testing.T) {
+	provider := New(fakeClient{
+		"api-key":   {SecretString: aws.String("s3cret")},
+		"cert":      {SecretBinary: []byte{0x01, 0x02}},
+		"prod/db":   {SecretString: aws.String(`{"username": "admin", "port": 5432}`)},
+		"forbidden": nil,
+	})
+	ctx := context.Background()
+
+	value, err := provider.GetSecret(ctx, "api-key")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", string(value))
+
+	value, err = provider.GetSecret(ctx, "cert")
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x01, 0x02}, value)
+
+	value, err = provider.GetSecret(ctx, "prod/db#username")
+	require.NoError(t, err)
+	assert.Equal(t, "admin", string(value))
+
+	value, err = provider.GetSecret(ctx, "prod/db#port")
+	require.NoError(t, err)
+	assert.Equal(t, "5432", string(value))
+
+	_, err = provider.GetSecret(ctx, "prod/db#password")
+	assert.True(t, errtag.HasTag[errtag.NotFound](err))
+
+	_, err = provider.GetSecret(ctx, "missing")
+	assert.True(t, errtag.HasTag[errtag.NotFound](err))
+
+	_, err = provider.GetSecret(ctx, "forbidden")
+	assert.EqualError(t, err, "get aws secret forbidden: access denied")
+}
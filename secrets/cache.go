@@ -0,0 +1,158 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/joshjon/kit/clock"
+	"github.com/joshjon/kit/log"
+)
+
+const defaultCacheTTL = 5 * time.Minute
+
+// CacheOption optionally configures a Cache.
+type CacheOption func(opts *cacheOptions)
+
+// WithTTL sets how long secrets are cached before they are fetched again,
+// which is also how often Run refreshes them. Defaults to 5m.
+func WithTTL(ttl time.Duration) CacheOption {
+	return func(opts *cacheOptions) {
+		opts.ttl = ttl
+	}
+}
+
+// WithLogger sets the Logger used to log failed refreshes.
+func WithLogger(logger log.Logger) CacheOption {
+	return func(opts *cacheOptions) {
+		opts.logger = logger
+	}
+}
+
+// WithClock sets the clock used to expire and refresh secrets. Defaults to
+// clock.Real.
+func WithClock(clk clock.Clock) CacheOption {
+	return func(opts *cacheOptions) {
+		opts.clock = clk
+	}
+}
+
+type cacheOptions struct {
+	ttl    time.Duration
+	logger log.Logger
+	clock  clock.Clock
+}
+
+// Cache is a Provider caching the secrets of another Provider, which detects
+// rotated secrets when they are fetched again. If fetching a cached secret
+// fails the cached value is returned.
+type Cache struct {
+	provider Provider
+	opts     cacheOptions
+
+	mu       sync.Mutex
+	entries  map[string]cacheEntry
+	onRotate map[string][]func(value []byte)
+}
+
+type cacheEntry struct {
+	value     []byte
+	fetchedAt time.Time
+}
+
+var _ Provider = (*Cache)(nil)
+
+// NewCache creates a new Cache of the secrets of provider.
+func NewCache(provider Provider, opts ...CacheOption) *Cache {
+	options := cacheOptions{
+		ttl:    defaultCacheTTL,
+		logger: log.NewLogger(),
+		clock:  clock.Real,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &Cache{
+		provider: provider,
+		opts:     options,
+		entries:  make(map[string]cacheEntry),
+		onRotate: make(map[string][]func(value []byte)),
+	}
+}
+
+func (c *Cache) GetSecret(ctx context.Context, name string) ([]byte, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[name]
+	c.mu.Unlock()
+	if ok && c.opts.clock.Since(entry.fetchedAt) < c.opts.ttl {
+		return entry.value, nil
+	}
+
+	value, err := c.fetch(ctx, name)
+	if err != nil {
+		if ok {
+			c.opts.logger.Warn("failed to fetch secret, using cached value", "secret", name, "error", err)
+			return entry.value, nil
+		}
+		return nil, err
+	}
+	return value, nil
+}
+
+// OnRotate registers fn to be called with the new value of the secret name
+// when it is fetched again and has changed, e.g. to reconnect to a database
+// with a rotated password. Secrets are only fetched again when they expire,
+// so use Run to detect rotations without waiting for the next GetSecret.
+func (c *Cache) OnRotate(name string, fn func(value []byte)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onRotate[name] = append(c.onRotate[name], fn)
+}
+
+// Run refreshes the cached secrets every TTL until ctx is done, calling the
+// OnRotate funcs of rotated secrets. Failed refreshes are logged and the
+// cached value is kept.
+func (c *Cache) Run(ctx context.Context) error {
+	ticker := c.opts.clock.NewTicker(c.opts.ttl)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C():
+		}
+
+		c.mu.Lock()
+		names := slices.Collect(maps.Keys(c.entries))
+		c.mu.Unlock()
+		for _, name := range names {
+			if _, err := c.fetch(ctx, name); err != nil && ctx.Err() == nil {
+				c.opts.logger.Error("failed to refresh secret", "secret", name, "error", err)
+			}
+		}
+	}
+}
+
+func (c *Cache) fetch(ctx context.Context, name string) ([]byte, error) {
+	value, err := c.provider.GetSecret(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	prev, cached := c.entries[name]
+	c.entries[name] = cacheEntry{value: value, fetchedAt: c.opts.clock.Now()}
+	var callbacks []func(value []byte)
+	if cached && !bytes.Equal(prev.value, value) {
+		callbacks = slices.Clone(c.onRotate[name])
+	}
+	c.mu.Unlock()
+
+	for _, fn := range callbacks {
+		fn(value)
+	}
+	return value, nil
+}
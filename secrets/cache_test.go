@@ -0,0 +1,118 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/log"
+	"github.com/joshjon/kit/testutil"
+)
+
+type fakeProvider struct {
+	mu      sync.Mutex
+	secrets map[string]string
+	err     error
+	calls   int
+}
+
+func (p *fakeProvider) GetSecret(_ context.Context, name string) ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return []byte(p.secrets[name]), nil
+}
+
+func (p *fakeProvider) set(name string, value string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.secrets[name] = value
+	p.err = err
+}
+
+func (p *fakeProvider) callCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.calls
+}
+
+func newTestCache(t *testing.T, provider Provider) (*Cache, *testutil.FakeClock) {
+	t.Helper()
+	clk := testutil.NewFakeClock(time.Now())
+	cache := NewCache(provider, WithTTL(time.Minute), WithClock(clk), WithLogger(log.NewLogger(log.WithNop())))
+	return cache, clk
+}
+
+func TestCache_GetSecret(t *testing.T) {
+	provider := &fakeProvider{secrets: map[string]string{"token": "v1"}}
+	cache, clk := newTestCache(t, provider)
+	ctx := context.Background()
+
+	value, err := cache.GetSecret(ctx, "token")
+	require.NoError(t, err)
+	assert.Equal(t, "v1", string(value))
+	_, _ = cache.GetSecret(ctx, "token")
+	assert.Equal(t, 1, provider.callCount())
+
+	// expired
+	provider.set("token", "v2", nil)
+	clk.Advance(time.Minute)
+	value, err = cache.GetSecret(ctx, "token")
+	require.NoError(t, err)
+	assert.Equal(t, "v2", string(value))
+	assert.Equal(t, 2, provider.callCount())
+
+	// the cached value is used while fetching fails
+	provider.set("token", "v3", errors.New("unavailable"))
+	clk.Advance(time.Minute)
+	value, err = cache.GetSecret(ctx, "token")
+	require.NoError(t, err)
+	assert.Equal(t, "v2", string(value))
+
+	_, err = cache.GetSecret(ctx, "uncached")
+	assert.EqualError(t, err, "unavailable")
+}
+
+func TestCache_Run(t *testing.T) {
+	provider := &fakeProvider{secrets: map[string]string{"token": "v1"}}
+	cache, clk := newTestCache(t, provider)
+
+	rotated := make(chan string, 1)
+	cache.OnRotate("token", func(value []byte) {
+		rotated <- string(value)
+	})
+	_, err := cache.GetSecret(context.Background(), "token")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- cache.Run(ctx)
+	}()
+	require.Eventually(t, func() bool { return clk.Waiters() == 1 }, time.Second, time.Millisecond)
+
+	// unchanged
+	clk.Advance(time.Minute)
+	require.Eventually(t, func() bool { return provider.callCount() == 2 }, time.Second, time.Millisecond)
+
+	provider.set("token", "v2", nil)
+	clk.Advance(time.Minute)
+	select {
+	case value := <-rotated:
+		assert.Equal(t, "v2", value)
+	case <-time.After(time.Second):
+		t.Fatal("rotation not detected")
+	}
+	assert.Empty(t, rotated)
+
+	cancel()
+	assert.NoError(t, <-done)
+}
@@ -0,0 +1,113 @@
+// Package secrets provides secrets from sources such as environment
+// variables, mounted files, Vault or AWS Secrets Manager (see package
+// awssecrets) behind a common Provider interface.
+package secrets
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/joshjon/kit/errtag"
+)
+
+// Provider provides secrets by name.
+type Provider interface {
+	// GetSecret returns the value of the secret name. It returns an
+	// errtag.NotFound error if the secret does not exist.
+	GetSecret(ctx context.Context, name string) ([]byte, error)
+}
+
+// ProviderFunc is a func implementing Provider.
+type ProviderFunc func(ctx context.Context, name string) ([]byte, error)
+
+func (f ProviderFunc) GetSecret(ctx context.Context, name string) ([]byte, error) {
+	return f(ctx, name)
+}
+
+// EnvProvider is a Provider reading secrets from environment variables named
+// after the secret with a prefix, upper cased and with characters other than
+// letters and digits replaced by underscores, e.g. APP_DB_PASSWORD for secret
+// db-password and prefix APP_.
+type EnvProvider struct {
+	prefix string
+}
+
+var _ Provider = (*EnvProvider)(nil)
+
+// NewEnvProvider creates a new EnvProvider reading environment variables
+// with prefix.
+func NewEnvProvider(prefix string) *EnvProvider {
+	return &EnvProvider{prefix: prefix}
+}
+
+func (p *EnvProvider) GetSecret(_ context.Context, name string) ([]byte, error) {
+	envVar := p.EnvVar(name)
+	value, ok := os.LookupEnv(envVar)
+	if !ok {
+		return nil, errtag.Errorf[errtag.NotFound]("secret %s not found: %s is not set", name, envVar)
+	}
+	return []byte(value), nil
+}
+
+// EnvVar returns the name of the environment variable of secret name.
+func (p *EnvProvider) EnvVar(name string) string {
+	return p.prefix + strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// FileProvider is a Provider reading secrets from files in a directory, such
+// as Kubernetes secrets or Docker secrets mounted as volumes. The name of a
+// secret is its path relative to the directory. Trailing newlines are trimmed.
+type FileProvider struct {
+	dir string
+}
+
+var _ Provider = (*FileProvider)(nil)
+
+// NewFileProvider creates a new FileProvider reading files in dir.
+func NewFileProvider(dir string) *FileProvider {
+	return &FileProvider{dir: dir}
+}
+
+func (p *FileProvider) GetSecret(_ context.Context, name string) ([]byte, error) {
+	if !filepath.IsLocal(name) {
+		return nil, errtag.Errorf[errtag.InvalidArgument]("secret %s is not a path within the secrets directory", name)
+	}
+	value, err := os.ReadFile(filepath.Join(p.dir, name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errtag.Wrapf[errtag.NotFound](err, "secret %s not found", name)
+		}
+		return nil, err
+	}
+	return []byte(strings.TrimRight(string(value), "\r\n")), nil
+}
+
+// Chain returns a Provider getting secrets from the first of providers that
+// has them, e.g. to override secrets in Vault with environment variables
+// during development.
+func Chain(providers ...Provider) Provider {
+	return ProviderFunc(func(ctx context.Context, name string) ([]byte, error) {
+		var err error
+		for _, p := range providers {
+			var value []byte
+			value, err = p.GetSecret(ctx, name)
+			if !errtag.HasTag[errtag.NotFound](err) {
+				return value, err
+			}
+		}
+		if err == nil {
+			err = errtag.Errorf[errtag.NotFound]("secret %s not found", name)
+		}
+		return nil, err
+	})
+}
@@ -0,0 +1,70 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/errtag"
+)
+
+func TestEnvProvider(t *testing.T) {
+	provider := NewEnvProvider("APP_")
+	assert.Equal(t, "APP_DB_PASSWORD", provider.EnvVar("db-password"))
+
+	t.Setenv("APP_DB_PASSWORD", "s3cret")
+	value, err := provider.GetSecret(context.Background(), "db-password")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", string(value))
+
+	_, err = provider.GetSecret(context.Background(), "missing")
+	assert.True(t, errtag.HasTag[errtag.NotFound](err))
+}
+
+func TestFileProvider(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "db"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "db", "password"), []byte("s3cret\n"), 0o600))
+	provider := NewFileProvider(dir)
+
+	value, err := provider.GetSecret(context.Background(), "db/password")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", string(value))
+
+	_, err = provider.GetSecret(context.Background(), "missing")
+	assert.True(t, errtag.HasTag[errtag.NotFound](err))
+
+	_, err = provider.GetSecret(context.Background(), "../password")
+	assert.True(t, errtag.HasTag[errtag.InvalidArgument](err))
+}
+
+func TestChain(t *testing.T) {
+	t.Setenv("APP_TOKEN", "from-env")
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "token"), []byte("from-file"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "password"), []byte("from-file"), 0o600))
+	provider := Chain(NewEnvProvider("APP_"), NewFileProvider(dir))
+
+	value, err := provider.GetSecret(context.Background(), "token")
+	require.NoError(t, err)
+	assert.Equal(t, "from-env", string(value))
+
+	value, err = provider.GetSecret(context.Background(), "password")
+	require.NoError(t, err)
+	assert.Equal(t, "from-file", string(value))
+
+	_, err = provider.GetSecret(context.Background(), "missing")
+	assert.True(t, errtag.HasTag[errtag.NotFound](err))
+
+	// errors other than not found are returned
+	failing := ProviderFunc(func(ctx context.Context, name string) ([]byte, error) {
+		return nil, errors.New("unavailable")
+	})
+	_, err = Chain(failing, NewFileProvider(dir)).GetSecret(context.Background(), "token")
+	assert.EqualError(t, err, "unavailable")
+}
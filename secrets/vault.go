@@ -0,0 +1,133 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/joshjon/kit/errtag"
+)
+
+const (
+	defaultVaultMount = "secret"
+	defaultVaultKey   = "value"
+)
+
+// VaultOption optionally configures a VaultProvider.
+type VaultOption func(opts *vaultOptions)
+
+// WithVaultMount sets the mount path of the KV v2 secrets engine. Defaults to
+// secret.
+func WithVaultMount(mount string) VaultOption {
+	return func(opts *vaultOptions) {
+		opts.mount = mount
+	}
+}
+
+// WithVaultNamespace sets the Vault Enterprise namespace.
+func WithVaultNamespace(namespace string) VaultOption {
+	return func(opts *vaultOptions) {
+		opts.namespace = namespace
+	}
+}
+
+// WithVaultHTTPClient sets the HTTP client used to call Vault. Defaults to
+// http.DefaultClient.
+func WithVaultHTTPClient(client *http.Client) VaultOption {
+	return func(opts *vaultOptions) {
+		opts.client = client
+	}
+}
+
+type vaultOptions struct {
+	mount     string
+	namespace string
+	client    *http.Client
+}
+
+// VaultProvider is a Provider reading secrets from the KV v2 secrets engine
+// of HashiCorp Vault. The name of a secret is the path of a Vault secret
+// followed by # and the key of the value within it, e.g. db/creds#password.
+// The key defaults to value when omitted.
+type VaultProvider struct {
+	addr  string
+	token string
+	opts  vaultOptions
+}
+
+var _ Provider = (*VaultProvider)(nil)
+
+// NewVaultProvider creates a new VaultProvider calling Vault at addr, e.g.
+// https://vault.example.com:8200, authenticated with token.
+func NewVaultProvider(addr string, token string, opts ...VaultOption) *VaultProvider {
+	options := vaultOptions{
+		mount:  defaultVaultMount,
+		client: http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &VaultProvider{
+		addr:  strings.TrimSuffix(addr, "/"),
+		token: token,
+		opts:  options,
+	}
+}
+
+func (p *VaultProvider) GetSecret(ctx context.Context, name string) ([]byte, error) {
+	path, key, ok := strings.Cut(name, "#")
+	if !ok {
+		key = defaultVaultKey
+	}
+
+	reqURL := fmt.Sprintf("%s/v1/%s/data/%s", p.addr, url.PathEscape(p.opts.mount), escapePath(path))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", p.token)
+	if p.opts.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.opts.namespace)
+	}
+
+	res, err := p.opts.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("get vault secret %s: %w", path, err)
+	}
+	defer res.Body.Close() //nolint:errcheck
+
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		return nil, errtag.Errorf[errtag.NotFound]("secret %s not found", name)
+	case res.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("get vault secret %s: responded with status %d", path, res.StatusCode)
+	}
+
+	var body struct {
+		Data struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}
+	if err = json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decode vault secret %s: %w", path, err)
+	}
+	value, ok := body.Data.Data[key]
+	if !ok {
+		return nil, errtag.Errorf[errtag.NotFound]("secret %s not found: vault secret %s has no key %s", name, path, key)
+	}
+	if s, ok := value.(string); ok {
+		return []byte(s), nil
+	}
+	return json.Marshal(value)
+}
+
+func escapePath(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
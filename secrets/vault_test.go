@@ -0,0 +1,48 @@
+package secrets
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/errtag"
+)
+
+func TestVaultProvider(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "token", r.Header.Get("X-Vault-Token"))
+		assert.Equal(t, "team", r.Header.Get("X-Vault-Namespace"))
+		switch r.URL.Path {
+		case "/v1/kv/data/db/creds":
+			_, _ = w.Write([]byte(`{"data": {"data": {"password": "s3cret", "value": "default", "port": 5432}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	provider := NewVaultProvider(srv.URL, "token", WithVaultMount("kv"), WithVaultNamespace("team"))
+	ctx := context.Background()
+
+	value, err := provider.GetSecret(ctx, "db/creds#password")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", string(value))
+
+	value, err = provider.GetSecret(ctx, "db/creds")
+	require.NoError(t, err)
+	assert.Equal(t, "default", string(value))
+
+	value, err = provider.GetSecret(ctx, "db/creds#port")
+	require.NoError(t, err)
+	assert.Equal(t, "5432", string(value))
+
+	_, err = provider.GetSecret(ctx, "db/creds#user")
+	assert.True(t, errtag.HasTag[errtag.NotFound](err))
+
+	_, err = provider.GetSecret(ctx, "missing")
+	assert.True(t, errtag.HasTag[errtag.NotFound](err))
+}
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/joshjon/kit/health"
+)
+
+const (
+	// PprofPath is the path prefix of the pprof endpoints of the admin
+	// server.
+	PprofPath = "/debug/pprof"
+)
+
+// WithAdminPort serves operational endpoints on a separate admin port, keeping
+// them off the public port: the health endpoints, the pprof endpoints under
+// /debug/pprof, and the routes added with Server.RegisterAdmin and
+// Server.Admin, e.g. /metrics. The admin server does not use TLS, so the port
+// should only be reachable from within the deployment. Port 0 listens on a
+// port assigned by the OS, see Server.AdminAddr.
+func WithAdminPort(port int) Option {
+	return func(opts *options) error {
+		if port < 0 {
+			return fmt.Errorf("admin port must not be negative: %d", port)
+		}
+		opts.adminPort = &port
+		return nil
+	}
+}
+
+// adminServer serves the operational endpoints of a Server on a separate
+// port.
+type adminServer struct {
+	port     int
+	echo     *echo.Echo
+	listener net.Listener // bound on start
+}
+
+func (s *Server) newAdminServer(port int, panicHandlers []PanicHandler) *adminServer {
+	e := echo.New()
+	e.HideBanner = true
+	e.HidePort = true
+	e.Use(requestIDMiddleware)
+	e.Use(errorTransformMiddleware)
+	e.Use(recoverMiddleware(s.logger, panicHandlers))
+	e.HTTPErrorHandler = httpErrorHandlerFunc(s.logger)
+
+	e.GET(LivenessPath, health.Handler(s.healthRegistry, health.Liveness))
+	e.GET(ReadinessPath, health.Handler(s.healthRegistry, health.Readiness))
+	e.GET(HealthPath, health.Handler(s.healthRegistry, health.Liveness))
+
+	e.GET(PprofPath, echo.WrapHandler(http.HandlerFunc(pprof.Index)))
+	e.GET(PprofPath+"/*", echo.WrapHandler(http.HandlerFunc(pprof.Index)))
+	e.GET(PprofPath+"/cmdline", echo.WrapHandler(http.HandlerFunc(pprof.Cmdline)))
+	e.GET(PprofPath+"/profile", echo.WrapHandler(http.HandlerFunc(pprof.Profile)))
+	e.Match([]string{http.MethodGet, http.MethodPost}, PprofPath+"/symbol", echo.WrapHandler(http.HandlerFunc(pprof.Symbol)))
+	e.GET(PprofPath+"/trace", echo.WrapHandler(http.HandlerFunc(pprof.Trace)))
+
+	return &adminServer{port: port, echo: e}
+}
+
+func (a *adminServer) listen() error {
+	if a.listener != nil {
+		return nil
+	}
+	l, err := net.Listen("tcp", fmt.Sprintf(":%d", a.port))
+	if err != nil {
+		return fmt.Errorf("listen admin: %w", err)
+	}
+	a.listener = l
+	return nil
+}
+
+func (a *adminServer) serve() error {
+	a.echo.Listener = a.listener
+	err := a.echo.StartServer(a.echo.Server)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// RegisterAdmin registers the routes of h under pathPrefix on the admin
+// server of WithAdminPort, or on the server itself without an admin port.
+func (s *Server) RegisterAdmin(pathPrefix string, h Handler, middleware ...echo.MiddlewareFunc) {
+	h.Register(s.adminEcho().Group(pathPrefix, middleware...))
+}
+
+// Admin returns a router adding routes to the admin server of WithAdminPort,
+// or to the server itself without an admin port, e.g. to serve metrics:
+//
+//	provider.Register(srv.Admin())
+func (s *Server) Admin() *AdminRouter {
+	return &AdminRouter{echo: s.adminEcho()}
+}
+
+// AdminRouter adds routes to the admin server of a Server.
+type AdminRouter struct {
+	echo *echo.Echo
+}
+
+// Add adds a route for method and path to the admin server.
+func (r *AdminRouter) Add(method string, path string, handler echo.HandlerFunc) {
+	r.echo.Add(method, path, handler)
+}
+
+// AdminAddr returns the address the admin server is bound to, or nil if it
+// has not started yet or WithAdminPort is not set.
+func (s *Server) AdminAddr() net.Addr {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.admin == nil || s.admin.listener == nil {
+		return nil
+	}
+	return s.admin.listener.Addr()
+}
+
+func (s *Server) adminEcho() *echo.Echo {
+	if s.admin == nil {
+		return s.echo
+	}
+	return s.admin.echo
+}
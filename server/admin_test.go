@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/log"
+)
+
+type adminHandler struct{}
+
+func (adminHandler) Register(g *echo.Group) {
+	g.GET("/flags", func(c echo.Context) error {
+		return c.String(http.StatusOK, "flags")
+	})
+}
+
+func TestServer_WithAdminPort(t *testing.T) {
+	srv, err := NewServer(0, WithLogger(log.NewLogger(log.WithNop())), WithAdminPort(0))
+	require.NoError(t, err)
+	srv.RegisterAdmin("/admin", adminHandler{})
+	srv.Admin().Add(http.MethodGet, "/metrics", func(c echo.Context) error {
+		return c.String(http.StatusOK, "metrics")
+	})
+	assert.Nil(t, srv.AdminAddr())
+
+	go srv.Start()
+	defer srv.Stop(context.Background())
+	require.NoError(t, srv.WaitHealthy(20, 50*time.Millisecond))
+	require.NotNil(t, srv.AdminAddr())
+	adminAddress := fmt.Sprintf("http://localhost:%d", srv.AdminAddr().(*net.TCPAddr).Port)
+	require.NotEqual(t, srv.Address(), adminAddress)
+
+	tests := []struct {
+		path           string
+		wantPublicCode int
+	}{
+		{path: LivenessPath, wantPublicCode: http.StatusOK},
+		{path: ReadinessPath, wantPublicCode: http.StatusOK},
+		{path: HealthPath, wantPublicCode: http.StatusOK},
+		{path: PprofPath + "/", wantPublicCode: http.StatusNotFound},
+		{path: PprofPath + "/cmdline", wantPublicCode: http.StatusNotFound},
+		{path: PprofPath + "/goroutine", wantPublicCode: http.StatusNotFound},
+		{path: "/admin/flags", wantPublicCode: http.StatusNotFound},
+		{path: "/metrics", wantPublicCode: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			res, err := http.Get(adminAddress + tt.path)
+			require.NoError(t, err)
+			res.Body.Close()
+			assert.Equal(t, http.StatusOK, res.StatusCode)
+
+			res, err = http.Get(srv.Address() + tt.path)
+			require.NoError(t, err)
+			res.Body.Close()
+			assert.Equal(t, tt.wantPublicCode, res.StatusCode)
+		})
+	}
+
+	require.NoError(t, srv.Stop(context.Background()))
+	_, err = http.Get(adminAddress + LivenessPath)
+	assert.Error(t, err)
+}
+
+func TestServer_RegisterAdmin_withoutAdminPort(t *testing.T) {
+	srv, err := NewServer(0, WithLogger(log.NewLogger(log.WithNop())))
+	require.NoError(t, err)
+	srv.RegisterAdmin("/admin", adminHandler{})
+	srv.Admin().Add(http.MethodGet, "/metrics", func(c echo.Context) error {
+		return c.String(http.StatusOK, "metrics")
+	})
+
+	for _, path := range []string{"/admin/flags", "/metrics"} {
+		rec := httptest.NewRecorder()
+		srv.echo.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusOK, rec.Code, path)
+	}
+
+	rec := httptest.NewRecorder()
+	srv.echo.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, PprofPath+"/cmdline", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Nil(t, srv.AdminAddr())
+}
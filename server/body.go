@@ -0,0 +1,106 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+
+	"github.com/joshjon/kit/errtag"
+)
+
+const (
+	// gzipMinLength is the minimum size of responses compressed by WithGzip,
+	// below which compression costs more than it saves.
+	gzipMinLength = 1024
+)
+
+var errRequestBodyTooLarge = errors.New("request body too large")
+
+// WithMaxRequestBody limits the size of request bodies to size bytes. Handlers
+// reading larger bodies fail with errtag.InvalidArgument. Use MaxRequestBody
+// to override the limit of a route group.
+func WithMaxRequestBody(size int64) Option {
+	return func(opts *options) error {
+		if size <= 0 {
+			return fmt.Errorf("max request body must be positive: %d", size)
+		}
+		opts.maxRequestBody = size
+		return nil
+	}
+}
+
+// WithGzip compresses responses of at least 1KB with gzip for clients that
+// accept it, and decompresses gzip encoded request bodies. Request body limits
+// apply to the decompressed body. WebSocket and server-sent event requests are
+// not compressed.
+func WithGzip() Option {
+	return func(opts *options) error {
+		opts.gzip = true
+		return nil
+	}
+}
+
+// MaxRequestBody returns a middleware limiting the size of request bodies to
+// size bytes, overriding the limit of WithMaxRequestBody, e.g. for a group of
+// upload routes:
+//
+//	srv.Register("/uploads", h, server.MaxRequestBody(100<<20))
+func MaxRequestBody(size int64) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if body, ok := req.Body.(*limitedBody); ok {
+				body.limit = size
+			} else if req.Body != nil && req.Body != http.NoBody {
+				req.Body = &limitedBody{ReadCloser: req.Body, limit: size, contentLength: req.ContentLength}
+			}
+			err := next(c)
+			if errors.Is(err, errRequestBodyTooLarge) {
+				return errtag.NewTagged[errtag.InvalidArgument](
+					errRequestBodyTooLarge.Error(),
+					errtag.WithDetails(fmt.Sprintf("request body must not exceed %d bytes", size)),
+				)
+			}
+			return err
+		}
+	}
+}
+
+// limitedBody fails reads once more than limit bytes are read. The limit is
+// checked on read rather than when the body is wrapped, so MaxRequestBody can
+// override it further down the middleware chain.
+type limitedBody struct {
+	io.ReadCloser
+	limit         int64
+	contentLength int64
+	read          int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.contentLength > b.limit {
+		return 0, errRequestBodyTooLarge
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+	if b.read > b.limit {
+		return n, errRequestBodyTooLarge
+	}
+	return n, err
+}
+
+func gzipMiddlewares() []echo.MiddlewareFunc {
+	skipper := func(c echo.Context) bool {
+		return c.IsWebSocket() || isEventStreamRequest(c.Request())
+	}
+	return []echo.MiddlewareFunc{
+		middleware.GzipWithConfig(middleware.GzipConfig{
+			Skipper:   skipper,
+			MinLength: gzipMinLength,
+		}),
+		middleware.Decompress(),
+	}
+}
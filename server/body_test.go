@@ -0,0 +1,98 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/log"
+)
+
+type uploadHandler struct{}
+
+func (uploadHandler) Register(g *echo.Group) {
+	g.POST("", echoBody)
+}
+
+func echoBody(c echo.Context) error {
+	b, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return err
+	}
+	return c.Blob(http.StatusOK, echo.MIMETextPlain, b)
+}
+
+func TestWithMaxRequestBody(t *testing.T) {
+	srv, err := NewServer(0, WithLogger(log.NewLogger(log.WithNop())), WithMaxRequestBody(10))
+	require.NoError(t, err)
+	srv.Add(http.MethodPost, "/echo", echoBody)
+	srv.Register("/uploads", uploadHandler{}, MaxRequestBody(20))
+
+	tests := []struct {
+		name     string
+		path     string
+		body     string
+		unknown  bool // unknown content length, e.g. chunked
+		wantCode int
+	}{
+		{name: "within limit", path: "/echo", body: strings.Repeat("a", 10), wantCode: http.StatusOK},
+		{name: "exceeds limit", path: "/echo", body: strings.Repeat("a", 11), wantCode: http.StatusBadRequest},
+		{name: "exceeds limit unknown length", path: "/echo", body: strings.Repeat("a", 11), unknown: true, wantCode: http.StatusBadRequest},
+		{name: "within group limit", path: "/uploads", body: strings.Repeat("a", 20), wantCode: http.StatusOK},
+		{name: "exceeds group limit", path: "/uploads", body: strings.Repeat("a", 21), wantCode: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			if tt.unknown {
+				req.ContentLength = -1
+			}
+			rec := httptest.NewRecorder()
+			srv.echo.ServeHTTP(rec, req)
+			assert.Equal(t, tt.wantCode, rec.Code)
+			if tt.wantCode == http.StatusOK {
+				assert.Equal(t, tt.body, rec.Body.String())
+				return
+			}
+			var res ResponseError
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+			assert.Equal(t, http.StatusText(http.StatusBadRequest), res.Error.Message)
+		})
+	}
+}
+
+func TestWithGzip(t *testing.T) {
+	srv, err := NewServer(0, WithLogger(log.NewLogger(log.WithNop())), WithGzip(), WithMaxRequestBody(2048))
+	require.NoError(t, err)
+	srv.Add(http.MethodPost, "/echo", echoBody)
+
+	body := strings.Repeat("a", 2048)
+	var compressed bytes.Buffer
+	gw := gzip.NewWriter(&compressed)
+	_, err = gw.Write([]byte(body))
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/echo", &compressed)
+	req.Header.Set(echo.HeaderContentEncoding, "gzip")
+	req.Header.Set(echo.HeaderAcceptEncoding, "gzip")
+	rec := httptest.NewRecorder()
+	srv.echo.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "gzip", rec.Header().Get(echo.HeaderContentEncoding))
+
+	gr, err := gzip.NewReader(rec.Body)
+	require.NoError(t, err)
+	got, err := io.ReadAll(gr)
+	require.NoError(t, err)
+	assert.Equal(t, body, string(got))
+}
@@ -0,0 +1,324 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/joshjon/kit/cache"
+)
+
+const (
+	// responseCacheMaxEntries is the maximum number of responses cached by
+	// WithResponseCache, evicting the least recently used when exceeded.
+	responseCacheMaxEntries = 1024
+)
+
+// WithResponseCache caches successful responses of GET routes for ttl, e.g.
+// for read-heavy routes backed by slow downstreams. Optional paths limit
+// caching to matching route paths, e.g. /projects/:id, otherwise all GET
+// routes but the health endpoints are cached.
+//
+// Responses are keyed by the path and query of the request, its Authorization
+// and Cookie headers so users never share responses, and the request headers
+// named by the Vary header of the response. Responses with a Set-Cookie header
+// or a Cache-Control of no-store, no-cache or private are not cached. Cached
+// responses have an ETag, and requests with a matching If-None-Match header
+// get 304 Not Modified. Use Server.InvalidateResponseCache to drop cached
+// responses once the resources they contain change.
+func WithResponseCache(ttl time.Duration, paths ...string) Option {
+	return func(opts *options) error {
+		if ttl <= 0 {
+			return fmt.Errorf("response cache ttl must be positive: %s", ttl)
+		}
+		opts.responseCache = newResponseCache(ttl, paths)
+		return nil
+	}
+}
+
+// InvalidateResponseCache drops the responses cached for the request paths,
+// e.g. /projects/123, for any query and user. It does nothing without
+// WithResponseCache.
+func (s *Server) InvalidateResponseCache(paths ...string) {
+	if s.responseCache != nil {
+		s.responseCache.invalidate(paths...)
+	}
+}
+
+// ClearResponseCache drops all cached responses. It does nothing without
+// WithResponseCache.
+func (s *Server) ClearResponseCache() {
+	if s.responseCache != nil {
+		s.responseCache.clear()
+	}
+}
+
+type responseCache struct {
+	routes  []string // empty for all routes
+	entries *cache.Cache[string, *cachedResponse]
+	varies  *cache.Cache[string, []string] // Vary header names by request key
+
+	// mu serializes stores with invalidations, so responses read before an
+	// invalidation are not cached after it.
+	mu           sync.Mutex
+	invalidation uint64
+
+	indexMu sync.Mutex
+	index   map[string]map[string]struct{} // entry keys by request path
+}
+
+type cachedResponse struct {
+	path   string
+	header http.Header
+	body   []byte
+	etag   string
+}
+
+func newResponseCache(ttl time.Duration, routes []string) *responseCache {
+	rc := &responseCache{
+		routes: routes,
+		varies: cache.New[string, []string](cache.WithTTL(ttl), cache.WithMaxEntries(responseCacheMaxEntries)),
+		index:  map[string]map[string]struct{}{},
+	}
+	rc.entries = cache.New[string, *cachedResponse](
+		cache.WithTTL(ttl),
+		cache.WithMaxEntries(responseCacheMaxEntries),
+		cache.WithOnEvict(rc.unindex),
+	)
+	return rc
+}
+
+func (rc *responseCache) middleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		if req.Method != http.MethodGet || !rc.caches(c.Path()) || isStreamingRequest(c) {
+			return next(c)
+		}
+
+		reqKey := requestCacheKey(req)
+		varyNames, _ := rc.varies.Get(reqKey)
+		if cached, ok := rc.entries.Get(entryCacheKey(reqKey, varyNames, req.Header)); ok {
+			return cached.write(c)
+		}
+
+		rc.mu.Lock()
+		invalidation := rc.invalidation
+		rc.mu.Unlock()
+
+		res := c.Response()
+		w := res.Writer
+		rec := &responseRecorder{ResponseWriter: w}
+		res.Writer = rec
+		err := next(c)
+		res.Writer = w
+		if !rec.wroteHeader {
+			return err
+		}
+
+		header := res.Header()
+		varyNames, cacheable := responseVary(header)
+		if err == nil && rec.status == http.StatusOK && cacheable {
+			if header.Get("ETag") == "" {
+				header.Set("ETag", etag(rec.body.Bytes()))
+			}
+			rc.store(invalidation, reqKey, varyNames, req, &cachedResponse{
+				path:   req.URL.Path,
+				header: cachedHeader(header),
+				body:   rec.body.Bytes(),
+				etag:   header.Get("ETag"),
+			})
+			if etagMatches(req.Header.Get("If-None-Match"), header.Get("ETag")) {
+				res.Status = http.StatusNotModified
+				w.WriteHeader(http.StatusNotModified)
+				return err
+			}
+		}
+		w.WriteHeader(rec.status)
+		_, writeErr := w.Write(rec.body.Bytes())
+		if err == nil {
+			err = writeErr
+		}
+		return err
+	}
+}
+
+// caches reports whether responses of the route path are cached. Health
+// endpoints are never cached.
+func (rc *responseCache) caches(route string) bool {
+	switch route {
+	case LivenessPath, ReadinessPath, HealthPath:
+		return false
+	}
+	return len(rc.routes) == 0 || slices.Contains(rc.routes, route)
+}
+
+func (rc *responseCache) store(invalidation uint64, reqKey string, varyNames []string, req *http.Request, res *cachedResponse) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if invalidation != rc.invalidation {
+		return
+	}
+	key := entryCacheKey(reqKey, varyNames, req.Header)
+	rc.indexMu.Lock()
+	keys, ok := rc.index[res.path]
+	if !ok {
+		keys = map[string]struct{}{}
+		rc.index[res.path] = keys
+	}
+	keys[key] = struct{}{}
+	rc.indexMu.Unlock()
+	rc.varies.Set(reqKey, varyNames)
+	rc.entries.Set(key, res)
+}
+
+func (rc *responseCache) invalidate(paths ...string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.invalidation++
+	var keys []string
+	rc.indexMu.Lock()
+	for _, p := range paths {
+		for key := range rc.index[p] {
+			keys = append(keys, key)
+		}
+		delete(rc.index, p)
+	}
+	rc.indexMu.Unlock()
+	for _, key := range keys {
+		rc.entries.Delete(key)
+	}
+}
+
+func (rc *responseCache) clear() {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.invalidation++
+	rc.entries.Clear()
+	rc.varies.Clear()
+}
+
+// unindex removes evicted entries from the index of request paths.
+func (rc *responseCache) unindex(key string, res *cachedResponse, _ cache.EvictReason) {
+	rc.indexMu.Lock()
+	defer rc.indexMu.Unlock()
+	if keys, ok := rc.index[res.path]; ok {
+		delete(keys, key)
+		if len(keys) == 0 {
+			delete(rc.index, res.path)
+		}
+	}
+}
+
+func (r *cachedResponse) write(c echo.Context) error {
+	header := c.Response().Header()
+	for name, values := range r.header {
+		header[name] = slices.Clone(values)
+	}
+	if etagMatches(c.Request().Header.Get("If-None-Match"), r.etag) {
+		return c.NoContent(http.StatusNotModified)
+	}
+	return c.Blob(http.StatusOK, r.header.Get(echo.HeaderContentType), r.body)
+}
+
+// requestCacheKey returns the key of a request by its path, query and
+// credentials. Credentials are hashed so they are not held in memory.
+func requestCacheKey(req *http.Request) string {
+	credentials := sha256.Sum256([]byte(req.Header.Get(echo.HeaderAuthorization) + "\x00" + req.Header.Get(echo.HeaderCookie)))
+	return req.URL.Path + "?" + req.URL.Query().Encode() + "\x00" + hex.EncodeToString(credentials[:])
+}
+
+// entryCacheKey returns the key of a response to the request of reqKey,
+// which varies by the request headers varyNames.
+func entryCacheKey(reqKey string, varyNames []string, header http.Header) string {
+	var b strings.Builder
+	b.WriteString(reqKey)
+	for _, name := range varyNames {
+		b.WriteString("\x00")
+		b.WriteString(strings.Join(header.Values(name), ","))
+	}
+	return b.String()
+}
+
+// responseVary returns the request header names of the Vary header, and
+// whether the response may be cached.
+func responseVary(header http.Header) ([]string, bool) {
+	if header.Get(echo.HeaderSetCookie) != "" {
+		return nil, false
+	}
+	for _, directive := range strings.Split(header.Get(echo.HeaderCacheControl), ",") {
+		switch strings.ToLower(strings.TrimSpace(directive)) {
+		case "no-store", "no-cache", "private":
+			return nil, false
+		}
+	}
+	var names []string
+	for _, value := range header.Values(echo.HeaderVary) {
+		for _, name := range strings.Split(value, ",") {
+			name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+			if name == "*" {
+				return nil, false
+			}
+			if name != "" && !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	slices.Sort(names)
+	return names, true
+}
+
+// cachedHeader returns the headers of a response to cache, without those
+// specific to its request.
+func cachedHeader(header http.Header) http.Header {
+	cached := header.Clone()
+	cached.Del(echo.HeaderXRequestID)
+	return cached
+}
+
+// etagMatches reports whether the If-None-Match header matches etag, using
+// the weak comparison of RFC 9110.
+func etagMatches(ifNoneMatch string, etag string) bool {
+	if ifNoneMatch == "" || etag == "" {
+		return false
+	}
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// responseRecorder buffers a response, sharing the headers of the
+// ResponseWriter it replaces, so it can be cached before it is written.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.WriteHeader(http.StatusOK)
+	return r.body.Write(b)
+}
+
+// Flush does nothing, as cached responses are buffered until the handler
+// returns.
+func (r *responseRecorder) Flush() {}
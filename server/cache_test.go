@@ -0,0 +1,133 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_WithResponseCache(t *testing.T) {
+	srv, err := NewServer(0, WithResponseCache(time.Minute, "/projects/:id", "/private", "/vary"))
+	require.NoError(t, err)
+
+	calls := map[string]int{}
+	handler := func(c echo.Context) error {
+		calls[c.Request().URL.Path]++
+		switch c.Path() {
+		case "/private":
+			c.Response().Header().Set(echo.HeaderCacheControl, "private")
+		case "/vary":
+			c.Response().Header().Set(echo.HeaderVary, "Accept-Language")
+			return c.String(http.StatusOK, c.Request().Header.Get("Accept-Language"))
+		}
+		if c.QueryParam("fail") != "" {
+			return echo.ErrBadRequest
+		}
+		return c.String(http.StatusOK, "project "+c.Param("id"))
+	}
+	srv.Add(http.MethodGet, "/projects/:id", handler)
+	srv.Add(http.MethodGet, "/private", handler)
+	srv.Add(http.MethodGet, "/vary", handler)
+	srv.Add(http.MethodGet, "/uncached", handler)
+
+	do := func(path string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		rec := httptest.NewRecorder()
+		srv.echo.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("cached", func(t *testing.T) {
+		first := do("/projects/1", nil)
+		second := do("/projects/1", nil)
+		assert.Equal(t, http.StatusOK, second.Code)
+		assert.Equal(t, "project 1", second.Body.String())
+		assert.Equal(t, 1, calls["/projects/1"])
+		assert.NotEmpty(t, second.Header().Get("ETag"))
+		assert.Equal(t, first.Header().Get("ETag"), second.Header().Get("ETag"))
+		assert.NotEqual(t, first.Header().Get(echo.HeaderXRequestID), second.Header().Get(echo.HeaderXRequestID))
+	})
+
+	t.Run("if none match", func(t *testing.T) {
+		etag := do("/projects/2", nil).Header().Get("ETag")
+		rec := do("/projects/2", http.Header{"If-None-Match": {etag}})
+		assert.Equal(t, http.StatusNotModified, rec.Code)
+		assert.Empty(t, rec.Body.String())
+	})
+
+	t.Run("keyed by query and credentials", func(t *testing.T) {
+		do("/projects/3", nil)
+		do("/projects/3?page=2", nil)
+		do("/projects/3", http.Header{echo.HeaderAuthorization: {"Bearer a"}})
+		do("/projects/3", http.Header{echo.HeaderCookie: {"session=a"}})
+		do("/projects/3", http.Header{echo.HeaderCookie: {"session=a"}})
+		assert.Equal(t, 4, calls["/projects/3"])
+	})
+
+	t.Run("vary", func(t *testing.T) {
+		en := http.Header{"Accept-Language": {"en"}}
+		de := http.Header{"Accept-Language": {"de"}}
+		assert.Equal(t, "en", do("/vary", en).Body.String())
+		assert.Equal(t, "de", do("/vary", de).Body.String())
+		assert.Equal(t, "en", do("/vary", en).Body.String())
+		assert.Equal(t, 2, calls["/vary"])
+	})
+
+	t.Run("not cached", func(t *testing.T) {
+		do("/private", nil)
+		do("/private", nil)
+		assert.Equal(t, 2, calls["/private"])
+
+		do("/uncached", nil)
+		do("/uncached", nil)
+		assert.Equal(t, 2, calls["/uncached"])
+
+		assert.Equal(t, http.StatusBadRequest, do("/projects/4?fail=true", nil).Code)
+		assert.Equal(t, http.StatusBadRequest, do("/projects/4?fail=true", nil).Code)
+		assert.Equal(t, 2, calls["/projects/4"])
+	})
+
+	t.Run("invalidate", func(t *testing.T) {
+		do("/projects/5", nil)
+		do("/projects/5?page=2", nil)
+		do("/projects/6", nil)
+		srv.InvalidateResponseCache("/projects/5")
+		do("/projects/5", nil)
+		do("/projects/5?page=2", nil)
+		do("/projects/6", nil)
+		assert.Equal(t, 4, calls["/projects/5"])
+		assert.Equal(t, 1, calls["/projects/6"])
+
+		srv.ClearResponseCache()
+		do("/projects/6", nil)
+		assert.Equal(t, 2, calls["/projects/6"])
+	})
+}
+
+func TestEtagMatches(t *testing.T) {
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		want        bool
+	}{
+		{name: "empty", ifNoneMatch: "", want: false},
+		{name: "match", ifNoneMatch: `"a"`, want: true},
+		{name: "weak match", ifNoneMatch: `W/"a"`, want: true},
+		{name: "list", ifNoneMatch: `"b", "a"`, want: true},
+		{name: "any", ifNoneMatch: "*", want: true},
+		{name: "no match", ifNoneMatch: `"b"`, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, etagMatches(tt.ifNoneMatch, `"a"`))
+		})
+	}
+}
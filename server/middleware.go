@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -11,6 +12,7 @@ import (
 	"github.com/cohesivestack/valgo"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"golang.org/x/text/language"
 
 	"github.com/joshjon/kit/errtag"
 	"github.com/joshjon/kit/log"
@@ -55,6 +57,9 @@ func logValuesFunc(logger log.Logger, keys ...string) func(c echo.Context, v mid
 			if errors.As(v.Error, &herr) {
 				meta["http_error"] = herr.Error()
 				meta["error"] = herr.Internal
+				if attrs := errtag.LogAttrs(herr.cause); len(attrs) > 0 {
+					meta["error_tag"] = slog.GroupValue(attrs...)
+				}
 			} else {
 				meta["error"] = v.Error.Error()
 			}
@@ -84,6 +89,9 @@ func getDefaultMeta(c echo.Context, v middleware.RequestLoggerValues, keys ...st
 		"response_size": v.ResponseSize,
 		"remote_ip":     v.RemoteIP,
 	}
+	if requestID := RequestIDFromContext(c); requestID != "" {
+		defaultMeta["request_id"] = requestID
+	}
 
 	for _, key := range keys {
 		if val := c.Get(key); val != nil {
@@ -115,28 +123,73 @@ func errorTransformMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 		}
 
 		var verr *valgo.Error
-		var herr errtag.Tagger
+		var fieldErrs []valgoutil.FieldError
+		herr, tagged := errtag.Primary(err)
 
 		switch {
 		case errors.As(err, &verr):
+			fieldErrs = valgoutil.GetFieldErrors(verr)
 			// Bad request
 			detailsStr := strings.Join(valgoutil.GetDetails(verr), "; ")
 			formattedErr := fmt.Errorf("validate %s: %s", "request", detailsStr)
 			herr = errtag.Tag[errtag.InvalidArgument](formattedErr, errtag.WithDetails(valgoutil.GetDetails(verr)...))
-		case !errors.As(err, &herr):
+		case !tagged:
 			// Internal server error
 			herr = errtag.Tag[errtag.Internal](err)
 		}
 
 		return HTTPError{
-			Code:     herr.Code(),
-			Internal: herr.Error(),
-			Message:  herr.Msg(),
-			Details:  herr.Details(),
+			Code:        errtag.HTTPStatus(herr),
+			Internal:    herr.Error(),
+			ErrorCode:   errtag.ErrorCode(herr),
+			Message:     errtag.MsgContext(localeContext(c), herr),
+			Details:     herr.Details(),
+			Fields:      errtag.Fields(herr),
+			FieldErrors: fieldErrs,
+			cause:       herr,
 		}
 	}
 }
 
+// ResponseStatus returns the status code of the response to c, or the status
+// code the server responds with for err when the response is not yet written,
+// e.g. for middleware recording requests.
+func ResponseStatus(c echo.Context, err error) int {
+	if err == nil || c.Response().Committed {
+		return c.Response().Status
+	}
+	var herr HTTPError
+	if errors.As(err, &herr) {
+		return herr.Code
+	}
+	var echoErr *echo.HTTPError
+	if errors.As(err, &echoErr) {
+		return echoErr.Code
+	}
+	var verr *valgo.Error
+	if errors.As(err, &verr) {
+		return http.StatusBadRequest
+	}
+	if tag, ok := errtag.Primary(err); ok {
+		return errtag.HTTPStatus(tag)
+	}
+	return http.StatusInternalServerError
+}
+
+// localeContext returns the request context with the preferred language from
+// the Accept-Language header, unless a locale was already set.
+func localeContext(c echo.Context) context.Context {
+	ctx := c.Request().Context()
+	if _, ok := errtag.LocaleFromContext(ctx); ok {
+		return ctx
+	}
+	tags, _, err := language.ParseAcceptLanguage(c.Request().Header.Get("Accept-Language"))
+	if err != nil || len(tags) == 0 {
+		return ctx
+	}
+	return errtag.WithLocale(ctx, tags[0].String())
+}
+
 func httpErrorHandlerFunc(logger log.Logger) echo.HTTPErrorHandler {
 	return func(err error, c echo.Context) {
 		if c.Response().Committed {
@@ -150,6 +203,7 @@ func httpErrorHandlerFunc(logger log.Logger) echo.HTTPErrorHandler {
 				herr.Internal = err.Error()
 			}
 		}
+		herr.RequestID = RequestIDFromContext(c)
 		if err = SetResponseError(c, herr.Code, herr); err != nil {
 			logger.Error("failed to set response error", "error", err, "http_error", herr)
 		}
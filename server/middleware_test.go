@@ -0,0 +1,144 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cohesivestack/valgo"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/errtag"
+	"github.com/joshjon/kit/log"
+	"github.com/joshjon/kit/valgoutil"
+)
+
+func TestErrorTransformMiddleware(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode int
+		wantBody ResponseError
+	}{
+		{
+			name:     "tagged error with code",
+			err:      errtag.Tag[errtag.NotFound](errors.New("project 1 not found"), errtag.WithCode("project_not_found")),
+			wantCode: http.StatusNotFound,
+			wantBody: ResponseError{Error: HTTPError{ErrorCode: "project_not_found", Message: "Not Found"}},
+		},
+		{
+			name:     "tagged error with fields",
+			err:      errtag.Tag[errtag.TooManyRequests](errors.New("limit exceeded"), errtag.WithField("limit", 10)),
+			wantCode: http.StatusTooManyRequests,
+			wantBody: ResponseError{Error: HTTPError{Message: "Too Many Requests", Fields: map[string]any{"limit": float64(10)}}},
+		},
+		{
+			name:     "validation error",
+			err:      valgo.Is(valgo.String("", "name").Not().Blank("name is required")).ToError(),
+			wantCode: http.StatusBadRequest,
+			wantBody: ResponseError{Error: HTTPError{
+				Message:     "Bad Request",
+				Details:     []string{"name: [name is required]"},
+				FieldErrors: []valgoutil.FieldError{{Field: "name", Messages: []string{"name is required"}}},
+			}},
+		},
+		{
+			name:     "untagged error",
+			err:      errors.New("boom"),
+			wantCode: http.StatusInternalServerError,
+			wantBody: ResponseError{Error: HTTPError{Message: "Internal Server Error"}},
+		},
+		{
+			name:     "echo error",
+			err:      echo.NewHTTPError(http.StatusTeapot, "short and stout"),
+			wantCode: http.StatusTeapot,
+			wantBody: ResponseError{Error: HTTPError{Message: "short and stout"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := serveError(t, tt.err)
+			assert.Equal(t, tt.wantCode, res.Code)
+
+			var got ResponseError
+			require.NoError(t, json.Unmarshal(res.Body.Bytes(), &got))
+			assert.Equal(t, tt.wantBody, got)
+		})
+	}
+}
+
+func TestResponseStatus(t *testing.T) {
+	newContext := func() echo.Context {
+		return echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+	}
+
+	c := newContext()
+	require.NoError(t, c.NoContent(http.StatusAccepted))
+	assert.Equal(t, http.StatusAccepted, ResponseStatus(c, nil))
+	// committed responses keep their status
+	assert.Equal(t, http.StatusAccepted, ResponseStatus(c, errors.New("boom")))
+
+	for _, tt := range []struct {
+		err  error
+		want int
+	}{
+		{err: errtag.NewTagged[errtag.NotFound]("missing"), want: http.StatusNotFound},
+		{err: valgo.Is(valgo.String("", "name").Not().Blank()).ToError(), want: http.StatusBadRequest},
+		{err: echo.NewHTTPError(http.StatusTeapot), want: http.StatusTeapot},
+		{err: HTTPError{Code: http.StatusConflict}, want: http.StatusConflict},
+		{err: errors.New("boom"), want: http.StatusInternalServerError},
+	} {
+		assert.Equal(t, tt.want, ResponseStatus(newContext(), tt.err), tt.err.Error())
+	}
+}
+
+func TestErrorTransformMiddleware_localized(t *testing.T) {
+	errtag.SetMessageResolver(errtag.MessageResolverFunc(func(code string, lang string) (string, bool) {
+		if code == "404" && lang == "de" {
+			return "Nicht gefunden", true
+		}
+		return "", false
+	}))
+	t.Cleanup(func() { errtag.SetMessageResolver(nil) })
+
+	res := serveError(t, errtag.NewTagged[errtag.NotFound]("not found"), "de-DE;q=0.8, de;q=0.9, en;q=0.5")
+
+	var got ResponseError
+	require.NoError(t, json.Unmarshal(res.Body.Bytes(), &got))
+	assert.Equal(t, "Nicht gefunden", got.Error.Message)
+}
+
+func serveError(t *testing.T, err error, acceptLanguage ...string) *httptest.ResponseRecorder {
+	t.Helper()
+	e := echo.New()
+	e.HTTPErrorHandler = httpErrorHandlerFunc(log.NewLogger(log.WithNop()))
+	e.Use(errorTransformMiddleware)
+	e.GET("/", func(echo.Context) error { return err })
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, lang := range acceptLanguage {
+		req.Header.Add("Accept-Language", lang)
+	}
+	res := httptest.NewRecorder()
+	e.ServeHTTP(res, req)
+	return res
+}
+
+func TestIsEventStreamRequest(t *testing.T) {
+	for accept, want := range map[string]bool{
+		"text/event-stream":                   true,
+		"application/json, text/event-stream": true,
+		"text/event-stream;q=0.9":             true,
+		"application/json":                    false,
+		"":                                    false,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(echo.HeaderAccept, accept)
+		assert.Equal(t, want, isEventStreamRequest(req), accept)
+	}
+}
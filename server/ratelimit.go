@@ -0,0 +1,134 @@
+package server
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/cohesivestack/valgo"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"golang.org/x/time/rate"
+
+	"github.com/joshjon/kit/errtag"
+)
+
+// RateLimitConfig configures rate limiting of requests using a token bucket
+// per client IP. Routes may be given their own limits, which are tracked in
+// separate buckets per client IP and replace the default limit for the route.
+type RateLimitConfig struct {
+	RequestsPerSecond float64                `yaml:"requestsPerSecond" env:"REQUESTS_PER_SECOND"`
+	Burst             int                    `yaml:"burst" env:"BURST"`                         // defaults to RequestsPerSecond rounded up
+	ExpiresInSeconds  int                    `yaml:"expiresInSeconds" env:"EXPIRES_IN_SECONDS"` // idle time before a client's bucket is discarded
+	Routes            []RouteRateLimitConfig `yaml:"routes"`
+}
+
+func (c *RateLimitConfig) Validation() *valgo.Validation {
+	v := valgo.Is(
+		valgo.Float64(c.RequestsPerSecond, "requestsPerSecond").GreaterThan(0),
+		valgo.Int(c.Burst, "burst").GreaterOrEqualTo(0),
+		valgo.Int(c.ExpiresInSeconds, "expiresInSeconds").GreaterOrEqualTo(0),
+	)
+	for i, route := range c.Routes {
+		v.InRow("routes", i, route.Validation())
+	}
+	return v
+}
+
+// RouteRateLimitConfig configures the rate limit of a route, matched by its
+// path, e.g. /users/:id, and optionally its method.
+type RouteRateLimitConfig struct {
+	Method            string  `yaml:"method"` // empty matches any method
+	Path              string  `yaml:"path"`
+	RequestsPerSecond float64 `yaml:"requestsPerSecond"`
+	Burst             int     `yaml:"burst"` // defaults to RequestsPerSecond rounded up
+}
+
+func (c *RouteRateLimitConfig) Validation() *valgo.Validation {
+	return valgo.Is(
+		valgo.String(c.Path, "path").Not().Blank(),
+		valgo.Float64(c.RequestsPerSecond, "requestsPerSecond").GreaterThan(0),
+		valgo.Int(c.Burst, "burst").GreaterOrEqualTo(0),
+	)
+}
+
+// WithRateLimit limits the rate of requests per client IP as configured by
+// cfg. Limited requests fail with errtag.TooManyRequests and a Retry-After
+// header. Health endpoints are not limited. Client IPs are taken from
+// echo.Context.RealIP, so configure echo.Echo.IPExtractor when running behind
+// a proxy.
+func WithRateLimit(cfg RateLimitConfig) Option {
+	return func(opts *options) error {
+		if err := cfg.Validation().Error(); err != nil {
+			return err
+		}
+		opts.rateLimit = &cfg
+		return nil
+	}
+}
+
+type rateLimiter struct {
+	store      *middleware.RateLimiterMemoryStore
+	retryAfter string
+}
+
+func newRateLimiter(requestsPerSecond float64, burst int, expiresIn time.Duration) rateLimiter {
+	if burst == 0 {
+		// A zero burst would reject every request.
+		burst = max(1, int(math.Ceil(requestsPerSecond)))
+	}
+	return rateLimiter{
+		store: middleware.NewRateLimiterMemoryStoreWithConfig(middleware.RateLimiterMemoryStoreConfig{
+			Rate:      rate.Limit(requestsPerSecond),
+			Burst:     burst,
+			ExpiresIn: expiresIn,
+		}),
+		retryAfter: strconv.Itoa(int(math.Ceil(1 / requestsPerSecond))),
+	}
+}
+
+type routeRateLimiter struct {
+	method  string
+	path    string
+	limiter rateLimiter
+}
+
+func rateLimitMiddleware(cfg RateLimitConfig) echo.MiddlewareFunc {
+	expiresIn := time.Duration(cfg.ExpiresInSeconds) * time.Second
+	defaultLimiter := newRateLimiter(cfg.RequestsPerSecond, cfg.Burst, expiresIn)
+	routeLimiters := make([]routeRateLimiter, len(cfg.Routes))
+	for i, route := range cfg.Routes {
+		routeLimiters[i] = routeRateLimiter{
+			method:  route.Method,
+			path:    route.Path,
+			limiter: newRateLimiter(route.RequestsPerSecond, route.Burst, expiresIn),
+		}
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			switch c.Path() {
+			case LivenessPath, ReadinessPath, HealthPath:
+				return next(c) // don't throttle probes
+			}
+
+			limiter := defaultLimiter
+			for _, route := range routeLimiters {
+				if route.path == c.Path() && (route.method == "" || route.method == c.Request().Method) {
+					limiter = route.limiter
+					break
+				}
+			}
+
+			allow, err := limiter.store.Allow(c.RealIP())
+			if err != nil {
+				return err
+			}
+			if !allow {
+				c.Response().Header().Set(echo.HeaderRetryAfter, limiter.retryAfter)
+				return errtag.NewTagged[errtag.TooManyRequests]("rate limit exceeded")
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/log"
+)
+
+func TestWithRateLimit(t *testing.T) {
+	srv, err := NewServer(0,
+		WithLogger(log.NewLogger(log.WithNop())),
+		WithRateLimit(RateLimitConfig{
+			RequestsPerSecond: 0.5,
+			Burst:             2,
+			Routes: []RouteRateLimitConfig{
+				{Method: http.MethodPost, Path: "/login", RequestsPerSecond: 0.1},
+			},
+		}),
+	)
+	require.NoError(t, err)
+	ok := func(c echo.Context) error { return c.NoContent(http.StatusOK) }
+	srv.Add(http.MethodGet, "/users/:id", ok)
+	srv.Add(http.MethodPost, "/login", ok)
+
+	do := func(method string, path string, ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.RemoteAddr = ip + ":1234"
+		rec := httptest.NewRecorder()
+		srv.echo.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// The default limit is shared by routes without their own limit.
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/users/1", "10.0.0.1").Code)
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/users/2", "10.0.0.1").Code)
+	rec := do(http.MethodGet, "/users/3", "10.0.0.1")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "2", rec.Header().Get(echo.HeaderRetryAfter))
+
+	// Each client IP has its own bucket.
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/users/1", "10.0.0.2").Code)
+
+	// Routes with their own limit have their own bucket, with a burst
+	// defaulting to one request.
+	assert.Equal(t, http.StatusOK, do(http.MethodPost, "/login", "10.0.0.1").Code)
+	rec = do(http.MethodPost, "/login", "10.0.0.1")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "10", rec.Header().Get(echo.HeaderRetryAfter))
+
+	// Health endpoints are not limited.
+	for range 3 {
+		assert.Equal(t, http.StatusOK, do(http.MethodGet, LivenessPath, "10.0.0.1").Code)
+	}
+}
+
+func TestWithRateLimit_invalidConfig(t *testing.T) {
+	_, err := NewServer(0, WithRateLimit(RateLimitConfig{
+		RequestsPerSecond: 1,
+		Routes:            []RouteRateLimitConfig{{Path: "/login"}},
+	}))
+	assert.Error(t, err)
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/joshjon/kit/errtag"
+	"github.com/joshjon/kit/fname"
+	"github.com/joshjon/kit/log"
+)
+
+// PanicReport describes a panic recovered while handling a request.
+type PanicReport struct {
+	Value     any
+	Stack     fname.Frames // innermost first, starting at the panic
+	Method    string
+	URI       string
+	Route     string
+	RemoteIP  string
+	UserAgent string
+}
+
+// PanicHandler is called with the report of every panic recovered while
+// handling a request, e.g. to forward it to an error tracker.
+type PanicHandler func(c echo.Context, report PanicReport)
+
+// WithPanicHandler adds a handler called when a panic is recovered while
+// handling a request, after the panic is logged.
+func WithPanicHandler(handler PanicHandler) Option {
+	return func(opts *options) error {
+		opts.panicHandlers = append(opts.panicHandlers, handler)
+		return nil
+	}
+}
+
+// recoverMiddleware recovers panics of handlers, logging them with the
+// request as a single record and failing the request with errtag.Internal.
+// http.ErrAbortHandler is re-panicked so the server aborts the response.
+func recoverMiddleware(logger log.Logger, handlers []PanicHandler) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			defer func() {
+				r := recover()
+				if r == nil {
+					return
+				}
+				if r == http.ErrAbortHandler { //nolint:errorlint
+					panic(r)
+				}
+
+				req := c.Request()
+				report := PanicReport{
+					Value:     r,
+					Stack:     panicStack(fname.Stack(1, 0)),
+					Method:    req.Method,
+					URI:       req.RequestURI,
+					Route:     c.Path(),
+					RemoteIP:  c.RealIP(),
+					UserAgent: req.UserAgent(),
+				}
+				logger.Error("panic recovered",
+					"panic", fmt.Sprint(r),
+					"stack", report.Stack.Strings(),
+					"method", report.Method,
+					"uri", report.URI,
+					"route", report.Route,
+					"remote_ip", report.RemoteIP,
+					"user_agent", report.UserAgent,
+				)
+				for _, handler := range handlers {
+					handler(c, report)
+				}
+				err = errtag.Tag[errtag.Internal](fmt.Errorf("panic: %v", r))
+			}()
+			return next(c)
+		}
+	}
+}
+
+// panicStack removes the leading runtime frames of a stack captured while
+// panicking, so it starts at the frame that panicked.
+func panicStack(frames fname.Frames) fname.Frames {
+	for i, f := range frames {
+		if !strings.HasPrefix(f.Func, "runtime.") {
+			return frames[i:]
+		}
+	}
+	return frames
+}
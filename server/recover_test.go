@@ -0,0 +1,59 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/testutil"
+)
+
+func TestServer_recoverPanic(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout bool // the timeout middleware runs the handler in another goroutine
+	}{
+		{name: "with timeout", timeout: true},
+		{name: "without timeout", timeout: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := testutil.NewRecordingLogger()
+			var reports []PanicReport
+			opts := []Option{
+				WithLogger(logger),
+				WithPanicHandler(func(c echo.Context, report PanicReport) {
+					reports = append(reports, report)
+				}),
+			}
+			if !tt.timeout {
+				opts = append(opts, WithRequestTimeout(0))
+			}
+			srv, err := NewServer(0, opts...)
+			require.NoError(t, err)
+			srv.Add(http.MethodGet, "/projects/:id", func(c echo.Context) error {
+				panic("boom")
+			})
+
+			rec := httptest.NewRecorder()
+			srv.echo.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/projects/1", nil))
+			assert.Equal(t, http.StatusInternalServerError, rec.Code)
+			var res ResponseError
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+			assert.Equal(t, http.StatusText(http.StatusInternalServerError), res.Error.Message)
+
+			logger.AssertLogged(t, slog.LevelError, "panic recovered", "panic", "boom", "route", "/projects/:id", "uri", "/projects/1")
+			require.Len(t, reports, 1)
+			assert.Equal(t, "boom", reports[0].Value)
+			assert.Equal(t, http.MethodGet, reports[0].Method)
+			require.NotEmpty(t, reports[0].Stack)
+			assert.Contains(t, reports[0].Stack[0].Func, "TestServer_recoverPanic")
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/joshjon/kit/id"
+)
+
+const (
+	requestIDContextKey = "request_id"
+	maxRequestIDLength  = 128
+)
+
+// requestIDMiddleware propagates the X-Request-ID header of requests, or
+// generates one if it is missing or invalid, and sets it on the response. The
+// header of the request is updated too, so requests proxied downstream carry
+// the same ID.
+func requestIDMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		requestID := req.Header.Get(echo.HeaderXRequestID)
+		if !validRequestID(requestID) {
+			requestID = id.NewUUIDv7().String()
+			req.Header.Set(echo.HeaderXRequestID, requestID)
+		}
+		c.Set(requestIDContextKey, requestID)
+		c.Response().Header().Set(echo.HeaderXRequestID, requestID)
+		return next(c)
+	}
+}
+
+// validRequestID reports whether a client provided request ID is safe to log
+// and forward.
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(requestID); i++ {
+		if c := requestID[i]; c < 0x21 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// RequestIDFromContext returns the ID of the request, taken from its
+// X-Request-ID header or generated by the server, e.g. to forward it with
+// downstream requests. It returns an empty string outside of a Server.
+func RequestIDFromContext(c echo.Context) string {
+	requestID, _ := c.Get(requestIDContextKey).(string)
+	return requestID
+}
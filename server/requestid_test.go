@@ -0,0 +1,73 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/errtag"
+	"github.com/joshjon/kit/testutil"
+)
+
+func TestServer_requestID(t *testing.T) {
+	logger := testutil.NewRecordingLogger()
+	srv, err := NewServer(0, WithLogger(logger))
+	require.NoError(t, err)
+	srv.Add(http.MethodGet, "/ok", func(c echo.Context) error {
+		// The request header is set too so proxied requests carry the ID.
+		assert.Equal(t, RequestIDFromContext(c), c.Request().Header.Get(echo.HeaderXRequestID))
+		return c.NoContent(http.StatusOK)
+	})
+	srv.Add(http.MethodGet, "/fail", func(c echo.Context) error {
+		return errtag.NewTagged[errtag.NotFound]("not found")
+	})
+
+	tests := []struct {
+		name          string
+		requestID     string
+		wantPropagate bool
+	}{
+		{name: "propagated", requestID: "abc-123", wantPropagate: true},
+		{name: "generated", requestID: ""},
+		{name: "invalid", requestID: "abc\n123"},
+		{name: "too long", requestID: strings.Repeat("a", maxRequestIDLength+1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger.Reset()
+			req := httptest.NewRequest(http.MethodGet, "/ok", nil)
+			req.Header.Set(echo.HeaderXRequestID, tt.requestID)
+			rec := httptest.NewRecorder()
+			srv.echo.ServeHTTP(rec, req)
+			require.Equal(t, http.StatusOK, rec.Code)
+
+			requestID := rec.Header().Get(echo.HeaderXRequestID)
+			if tt.wantPropagate {
+				assert.Equal(t, tt.requestID, requestID)
+			} else {
+				assert.NotEmpty(t, requestID)
+				assert.NotEqual(t, tt.requestID, requestID)
+			}
+			logger.AssertLogged(t, slog.LevelInfo, "request", "request_id", requestID)
+		})
+	}
+
+	t.Run("error response", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/fail", nil)
+		req.Header.Set(echo.HeaderXRequestID, "abc-123")
+		rec := httptest.NewRecorder()
+		srv.echo.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusNotFound, rec.Code)
+
+		var res ResponseError
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		assert.Equal(t, "abc-123", res.Error.RequestID)
+	})
+}
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// RouteOption optionally configures a route added with Server.AddRoute or
+// Server.AnyRoute.
+type RouteOption func(opts *routeOptions)
+
+// WithTimeout sets the timeout of the route handler, overriding the timeout
+// of WithRequestTimeout, e.g. to give report generation or uploads a larger
+// budget. Zero disables the timeout of the route. Handlers can read the time
+// remaining with TimeRemaining.
+func WithTimeout(timeout time.Duration) RouteOption {
+	return func(opts *routeOptions) {
+		opts.timeout = &timeout
+	}
+}
+
+type routeOptions struct {
+	timeout *time.Duration
+}
+
+// routeKey identifies a route by its method, or empty for any method, and
+// path.
+type routeKey struct {
+	method string
+	path   string
+}
+
+// routeMiddlewares returns the middlewares of a route configured by opts,
+// registering its timeout so the server timeout skips the route.
+func (s *Server) routeMiddlewares(key routeKey, opts []RouteOption) []echo.MiddlewareFunc {
+	var options routeOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.timeout == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	s.routeTimeouts[key] = *options.timeout
+	s.mu.Unlock()
+	return []echo.MiddlewareFunc{middleware.TimeoutWithConfig(middleware.TimeoutConfig{
+		Timeout: *options.timeout,
+		Skipper: isTimeoutExempt,
+	})}
+}
+
+// hasRouteTimeout reports whether the route of c has its own timeout.
+func (s *Server) hasRouteTimeout(c echo.Context) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if _, ok := s.routeTimeouts[routeKey{method: c.Request().Method, path: c.Path()}]; ok {
+		return true
+	}
+	_, ok := s.routeTimeouts[routeKey{path: c.Path()}]
+	return ok
+}
+
+// isTimeoutExempt reports whether c is a WebSocket request, which is exempt
+// from timeouts since the timeout buffers responses until the handler returns.
+// Server-sent event requests are not exempt, since the Accept header is
+// controlled by the client, so their routes must be exempt explicitly with
+// WithRequestTimeout skip paths or WithTimeout(0).
+func isTimeoutExempt(c echo.Context) bool {
+	return c.IsWebSocket()
+}
+
+// isStreamingRequest reports whether c is a WebSocket or server-sent event
+// request, whose responses must not be cached.
+func isStreamingRequest(c echo.Context) bool {
+	return c.IsWebSocket() || isEventStreamRequest(c.Request())
+}
+
+// TimeRemaining returns the time remaining until the request of c times out,
+// or false if it has no timeout.
+func TimeRemaining(c echo.Context) (time.Duration, bool) {
+	deadline, ok := c.Request().Context().Deadline()
+	if !ok {
+		return 0, false
+	}
+	return time.Until(deadline), true
+}
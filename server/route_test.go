@@ -0,0 +1,65 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/log"
+)
+
+func TestServer_AddRoute_WithTimeout(t *testing.T) {
+	srv, err := NewServer(0, WithLogger(log.NewLogger(log.WithNop())), WithRequestTimeout(50*time.Millisecond, "/events"))
+	require.NoError(t, err)
+
+	slow := func(c echo.Context) error {
+		select {
+		case <-time.After(200 * time.Millisecond):
+			return c.NoContent(http.StatusOK)
+		case <-c.Request().Context().Done():
+			return c.Request().Context().Err()
+		}
+	}
+	srv.Add(http.MethodGet, "/default", slow)
+	srv.AddRoute(http.MethodGet, "/reports", slow, WithTimeout(time.Minute))
+	srv.AnyRoute("/uploads", slow, WithTimeout(0))
+	srv.Add(http.MethodGet, "/events", slow)
+	srv.AddRoute(http.MethodGet, "/remaining", func(c echo.Context) error {
+		remaining, ok := TimeRemaining(c)
+		if !ok || remaining <= 50*time.Millisecond || remaining > time.Minute {
+			return c.NoContent(http.StatusInternalServerError)
+		}
+		return c.NoContent(http.StatusOK)
+	}, WithTimeout(time.Minute))
+
+	tests := []struct {
+		method   string
+		path     string
+		accept   string
+		wantCode int
+	}{
+		{method: http.MethodGet, path: "/default", wantCode: http.StatusServiceUnavailable},
+		// accepting server-sent events does not exempt a route
+		{method: http.MethodGet, path: "/default", accept: "text/event-stream", wantCode: http.StatusServiceUnavailable},
+		{method: http.MethodGet, path: "/events", accept: "text/event-stream", wantCode: http.StatusOK},
+		{method: http.MethodGet, path: "/reports", wantCode: http.StatusOK},
+		{method: http.MethodPost, path: "/uploads", wantCode: http.StatusOK},
+		{method: http.MethodGet, path: "/remaining", wantCode: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.accept != "" {
+				req.Header.Set(echo.HeaderAccept, tt.accept)
+			}
+			rec := httptest.NewRecorder()
+			srv.echo.ServeHTTP(rec, req)
+			assert.Equal(t, tt.wantCode, rec.Code)
+		})
+	}
+}
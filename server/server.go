@@ -6,17 +6,34 @@ import (
 	"crypto/x509"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 
+	"github.com/joshjon/kit/app"
+	"github.com/joshjon/kit/health"
 	"github.com/joshjon/kit/log"
+	"github.com/joshjon/kit/retry"
 )
 
-const DefaultRequestTimeout = 100 * time.Second
+const (
+	DefaultRequestTimeout  = 100 * time.Second
+	DefaultShutdownTimeout = 30 * time.Second
+	// DefaultTLSReloadInterval is how often certificate files are checked
+	// for changes.
+	DefaultTLSReloadInterval = time.Minute
+
+	LivenessPath  = "/livez"
+	ReadinessPath = "/readyz"
+	// HealthPath serves the liveness report for clients of the static health
+	// endpoint that preceded LivenessPath and ReadinessPath.
+	HealthPath = "/healthz"
+)
 
 // Option optionally configures a Server.
 type Option func(opts *options) error
@@ -49,7 +66,11 @@ func WithRequestLogSkipper(skipper middleware.Skipper) Option {
 }
 
 // WithRequestTimeout sets the timeout for request handlers. Optional
-// skipPaths exempt matching route paths from the timeout.
+// skipPaths exempt matching route paths (e.g. /events/* for a proxied group)
+// from the timeout. Routes added with the WithTimeout route option use their
+// own timeout instead. WebSocket requests are always exempt, since the timeout
+// buffers responses until the handler returns. Routes serving server-sent
+// events must be exempt with skipPaths or WithTimeout(0).
 func WithRequestTimeout(timeout time.Duration, skipPaths ...string) Option {
 	return func(opts *options) error {
 		opts.timeout = &timeout
@@ -75,15 +96,59 @@ func WithMiddleware(middlewares ...echo.MiddlewareFunc) Option {
 	}
 }
 
+// WithShutdownTimeout sets the grace period Run gives in-flight requests to
+// drain and shutdown hooks to run once the server shuts down. Defaults to
+// DefaultShutdownTimeout.
+func WithShutdownTimeout(timeout time.Duration) Option {
+	return func(opts *options) error {
+		opts.shutdownTimeout = timeout
+		return nil
+	}
+}
+
+// WithHealthRegistry sets the health.Registry whose checks are reported by
+// the /livez and /readyz endpoints, e.g. to share it with other servers of
+// the service. Defaults to a new registry.
+func WithHealthRegistry(registry *health.Registry) Option {
+	return func(opts *options) error {
+		opts.healthRegistry = registry
+		return nil
+	}
+}
+
+// WithHTTP2 enables serving HTTP/2 alongside HTTP/1.1 when TLS is disabled,
+// e.g. for gRPC-gateway downstreams and streaming clients behind a proxy.
+// HTTP/2 cleartext (h2c) is served to clients connecting with prior
+// knowledge. With TLS, HTTP/2 is negotiated via ALPN whether or not
+// WithHTTP2 is set.
+func WithHTTP2() Option {
+	return func(opts *options) error {
+		opts.http2 = true
+		return nil
+	}
+}
+
+// WithListener sets the listener the server serves on instead of listening
+// on its port, e.g. for socket activation. The server takes ownership of the
+// listener and closes it when stopped.
+func WithListener(l net.Listener) Option {
+	return func(opts *options) error {
+		opts.listener = l
+		return nil
+	}
+}
+
 type tlsConfig struct {
-	cert   string
-	key    string
-	caCert string // mTLS
+	cert           string
+	key            string
+	caCert         string // mTLS
+	getCertificate func(*tls.ClientHelloInfo) (*tls.Certificate, error)
 }
 
 // WithTLS configures the server to use TLS with the specified certificate, key,
 // and optional CA certificate for mTLS. If caCertFile is provided, the server
-// requires client certificates and validates them against the CA.
+// requires client certificates and validates them against the CA. The
+// certificate is reloaded when its files change, see WithTLSReloadInterval.
 func WithTLS(certFile string, keyFile string, caCertFile string) Option {
 	return func(opts *options) error {
 		opts.tlsConfig = &tlsConfig{
@@ -95,29 +160,87 @@ func WithTLS(certFile string, keyFile string, caCertFile string) Option {
 	}
 }
 
+// WithTLSReloadInterval sets how often the certificate files of WithTLS are
+// checked for changes, so rotated certificates are served without restarting
+// the server. Defaults to DefaultTLSReloadInterval. Zero disables reloading.
+func WithTLSReloadInterval(interval time.Duration) Option {
+	return func(opts *options) error {
+		opts.tlsReloadInterval = interval
+		return nil
+	}
+}
+
+// WithTLSGetCertificate configures the server to use TLS with certificates
+// returned by getCertificate, e.g. from a certificate manager, instead of the
+// certificate files of WithTLS. The CA certificate of WithTLS, if any, is
+// still used for mTLS.
+func WithTLSGetCertificate(getCertificate func(*tls.ClientHelloInfo) (*tls.Certificate, error)) Option {
+	return func(opts *options) error {
+		opts.tlsGetCertificate = getCertificate
+		return nil
+	}
+}
+
 type options struct {
-	logger           log.Logger
-	reqLogKeys       []string
-	reqLogSkipper    middleware.Skipper
-	timeout          *time.Duration
-	timeoutSkipPaths []string
-	corsOrigins      []string
-	middlewares      []echo.MiddlewareFunc
-	tlsConfig        *tlsConfig // nil to disable
+	logger            log.Logger
+	reqLogKeys        []string
+	reqLogSkipper     middleware.Skipper
+	timeout           *time.Duration
+	timeoutSkipPaths  []string
+	corsOrigins       []string
+	middlewares       []echo.MiddlewareFunc
+	tlsConfig         *tlsConfig // nil to disable
+	tlsReloadInterval time.Duration
+	tlsGetCertificate func(*tls.ClientHelloInfo) (*tls.Certificate, error)
+	shutdownTimeout   time.Duration
+	healthRegistry    *health.Registry
+	rateLimit         *RateLimitConfig // nil to disable
+	http2             bool
+	listener          net.Listener
+	maxRequestBody    int64 // 0 for no limit
+	gzip              bool
+	panicHandlers     []PanicHandler
+	responseCache     *responseCache // nil to disable
+	unixSocket        string
+	unixSocketMode    os.FileMode
+	adminPort         *int // nil to disable
 }
 
 // Server serves an API for managing NATS operators, accounts, and users.
 type Server struct {
-	port      int
-	echo      *echo.Echo
-	tlsConfig *tlsConfig
-	logger    log.Logger
+	port              int
+	echo              *echo.Echo
+	tlsConfig         *tlsConfig
+	logger            log.Logger
+	shutdownTimeout   time.Duration
+	shutdownHooks     []shutdownHook
+	healthRegistry    *health.Registry
+	customListener    net.Listener
+	unixSocket        string
+	unixSocketMode    os.FileMode
+	tlsReloadInterval time.Duration
+
+	mu            sync.RWMutex
+	listener      net.Listener // bound on start
+	stopTLSReload context.CancelFunc
+	routeTimeouts map[routeKey]time.Duration
+	responseCache *responseCache
+	admin         *adminServer // nil without an admin port
 }
 
-// NewServer creates a new Server with the given options.
+type shutdownHook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// NewServer creates a new Server listening on port with the given options.
+// Port 0 listens on a port assigned by the OS, see Port.
 func NewServer(port int, opts ...Option) (*Server, error) {
 	srvOpts := options{
-		logger: log.NewLogger(),
+		logger:            log.NewLogger(),
+		shutdownTimeout:   DefaultShutdownTimeout,
+		tlsReloadInterval: DefaultTLSReloadInterval,
+		unixSocketMode:    DefaultUnixSocketMode,
 	}
 
 	for _, opt := range opts {
@@ -125,20 +248,48 @@ func NewServer(port int, opts ...Option) (*Server, error) {
 			return nil, err
 		}
 	}
+	if srvOpts.tlsGetCertificate != nil {
+		if srvOpts.tlsConfig == nil {
+			srvOpts.tlsConfig = &tlsConfig{}
+		}
+		srvOpts.tlsConfig.getCertificate = srvOpts.tlsGetCertificate
+	}
 
 	srv := &Server{
-		port:      port,
-		echo:      echo.New(),
-		logger:    srvOpts.logger,
-		tlsConfig: srvOpts.tlsConfig,
+		port:              port,
+		echo:              echo.New(),
+		logger:            srvOpts.logger,
+		shutdownTimeout:   srvOpts.shutdownTimeout,
+		tlsConfig:         srvOpts.tlsConfig,
+		healthRegistry:    srvOpts.healthRegistry,
+		customListener:    srvOpts.listener,
+		unixSocket:        srvOpts.unixSocket,
+		unixSocketMode:    srvOpts.unixSocketMode,
+		tlsReloadInterval: srvOpts.tlsReloadInterval,
+		routeTimeouts:     map[routeKey]time.Duration{},
+		responseCache:     srvOpts.responseCache,
+	}
+	if srv.healthRegistry == nil {
+		srv.healthRegistry = health.NewRegistry()
+	}
+	if srvOpts.adminPort != nil {
+		srv.admin = srv.newAdminServer(*srvOpts.adminPort, srvOpts.panicHandlers)
 	}
 
 	srv.echo.HideBanner = true
 	srv.echo.HidePort = true
+	if srvOpts.http2 {
+		protocols := new(http.Protocols)
+		protocols.SetHTTP1(true)
+		protocols.SetHTTP2(true)
+		protocols.SetUnencryptedHTTP2(true)
+		srv.echo.Server.Protocols = protocols
+	}
 	srv.echo.Pre(middleware.RemoveTrailingSlash())
-	srv.echo.Use(middleware.Recover())
+	srv.echo.Use(requestIDMiddleware)
 	srv.echo.Use(middleware.RequestLoggerWithConfig(newRequestLoggerConfig(srv.logger, srvOpts.reqLogSkipper, srvOpts.reqLogKeys...)))
 	srv.echo.Use(errorTransformMiddleware)
+	srv.echo.Use(recoverMiddleware(srv.logger, srvOpts.panicHandlers))
 	srv.echo.HTTPErrorHandler = httpErrorHandlerFunc(srv.logger)
 	if len(srvOpts.corsOrigins) > 0 {
 		srv.echo.Use(middleware.CORSWithConfig(middleware.CORSConfig{
@@ -148,14 +299,30 @@ func NewServer(port int, opts ...Option) (*Server, error) {
 		}))
 	}
 
+	if srvOpts.rateLimit != nil {
+		srv.echo.Use(rateLimitMiddleware(*srvOpts.rateLimit))
+	}
+
+	if srvOpts.gzip {
+		srv.echo.Use(gzipMiddlewares()...)
+	}
+	if srvOpts.maxRequestBody > 0 {
+		srv.echo.Use(MaxRequestBody(srvOpts.maxRequestBody))
+	}
+
 	for _, m := range srvOpts.middlewares {
 		srv.echo.Use(m)
 	}
+	// Cache responses after custom middleware, e.g. authentication, so cached
+	// responses are only served to requests it accepts.
+	if srv.responseCache != nil {
+		srv.echo.Use(srv.responseCache.middleware)
+	}
 
 	timeoutCfg := middleware.TimeoutConfig{
 		Timeout: DefaultRequestTimeout,
 		Skipper: func(c echo.Context) bool {
-			if c.IsWebSocket() {
+			if isTimeoutExempt(c) || srv.hasRouteTimeout(c) {
 				return true
 			}
 			for _, p := range srvOpts.timeoutSkipPaths {
@@ -170,90 +337,311 @@ func NewServer(port int, opts ...Option) (*Server, error) {
 		timeoutCfg.Timeout = *srvOpts.timeout
 	}
 	srv.echo.Use(middleware.TimeoutWithConfig(timeoutCfg))
+	// Recover again after the timeout, which runs handlers in another
+	// goroutine and re-panics there, losing the stack of the panic.
+	srv.echo.Use(recoverMiddleware(srv.logger, srvOpts.panicHandlers))
 
-	srv.echo.GET("/healthz", func(c echo.Context) error {
-		return c.JSON(http.StatusOK, HealthResponse{
-			Status: http.StatusText(http.StatusOK),
-		})
-	})
+	srv.echo.GET(LivenessPath, health.Handler(srv.healthRegistry, health.Liveness))
+	srv.echo.GET(ReadinessPath, health.Handler(srv.healthRegistry, health.Readiness))
+	srv.echo.GET(HealthPath, health.Handler(srv.healthRegistry, health.Liveness))
 
 	return srv, nil
 }
 
-// Start begins serving on the configured host and port.
+// Start begins serving on the configured port, unix socket or listener, and
+// the admin port if set.
 func (s *Server) Start() error {
-	if s.tlsConfig == nil {
-		err := s.echo.Start(fmt.Sprintf(":%d", s.port))
-		if errors.Is(err, http.ErrServerClosed) {
-			return nil
-		}
+	if err := s.listen(); err != nil {
 		return err
 	}
+	if s.admin != nil {
+		go func() {
+			if err := s.admin.serve(); err != nil {
+				s.logger.Error("admin server failed", "error", err)
+			}
+		}()
+	}
+	return s.serve()
+}
 
-	tlsCfg := &tls.Config{
-		Certificates: []tls.Certificate{},
+// listen binds the listener of the server, so its port is known before it
+// starts serving.
+func (s *Server) listen() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.listener != nil {
+		return nil
 	}
+	l, err := s.newListener()
+	if err != nil {
+		return err
+	}
+	if s.admin != nil {
+		if err = s.admin.listen(); err != nil {
+			l.Close() //nolint:errcheck
+			return err
+		}
+	}
+	s.listener = l
+	return nil
+}
 
-	serverCert, err := tls.LoadX509KeyPair(s.tlsConfig.cert, s.tlsConfig.key)
+func (s *Server) newListener() (net.Listener, error) {
+	if s.customListener != nil {
+		return s.customListener, nil
+	}
+	if s.unixSocket != "" {
+		return listenUnix(s.unixSocket, s.unixSocketMode)
+	}
+	l, err := net.Listen("tcp", fmt.Sprintf(":%d", s.port))
 	if err != nil {
-		return fmt.Errorf("load server certificate: %w", err)
+		return nil, fmt.Errorf("listen: %w", err)
+	}
+	return l, nil
+}
+
+func (s *Server) serve() error {
+	s.mu.RLock()
+	l := s.listener
+	s.mu.RUnlock()
+
+	var err error
+	if s.tlsConfig == nil {
+		s.echo.Listener = l
+		err = s.echo.StartServer(s.echo.Server)
+	} else {
+		var tlsCfg *tls.Config
+		tlsCfg, err = s.newTLSConfig()
+		if err != nil {
+			l.Close() //nolint:errcheck
+			return err
+		}
+		if !s.echo.DisableHTTP2 {
+			tlsCfg.NextProtos = append(tlsCfg.NextProtos, "h2")
+		}
+		s.echo.TLSServer.TLSConfig = tlsCfg
+		s.echo.TLSListener = tls.NewListener(l, tlsCfg)
+		err = s.echo.StartServer(s.echo.TLSServer)
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
 	}
-	tlsCfg.Certificates = []tls.Certificate{serverCert}
+	return err
+}
 
-	if s.tlsConfig.caCert != "" {
-		caCertPool := x509.NewCertPool()
-		caCert, err := os.ReadFile(s.tlsConfig.caCert)
+// newTLSConfig returns the TLS config of the server, starting the reloading
+// of its certificate files if enabled.
+func (s *Server) newTLSConfig() (*tls.Config, error) {
+	tlsCfg := &tls.Config{}
+	if s.tlsConfig.getCertificate != nil {
+		tlsCfg.GetCertificate = s.tlsConfig.getCertificate
+	} else {
+		reloader, err := newCertReloader(s.tlsConfig.cert, s.tlsConfig.key, s.logger)
 		if err != nil {
-			return fmt.Errorf("read ca certificate: %w", err)
+			return nil, err
 		}
-		if !caCertPool.AppendCertsFromPEM(caCert) {
-			return fmt.Errorf("append ca certificate")
+		tlsCfg.GetCertificate = reloader.GetCertificate
+		if s.tlsReloadInterval > 0 {
+			ctx, cancel := context.WithCancel(context.Background())
+			s.mu.Lock()
+			s.stopTLSReload = cancel
+			s.mu.Unlock()
+			go reloader.run(ctx, s.tlsReloadInterval)
 		}
-		tlsCfg.ClientCAs = caCertPool
-		tlsCfg.ClientAuth = tls.RequireAndVerifyClientCert
 	}
+	if err := setClientCAs(tlsCfg, s.tlsConfig.caCert); err != nil {
+		return nil, err
+	}
+	return tlsCfg, nil
+}
 
-	s.echo.TLSServer.TLSConfig = tlsCfg
+// NewTLSConfig returns the TLS config of a server using the specified
+// certificate and key. If caCertFile is provided, the server requires client
+// certificates and validates them against the CA (mTLS).
+func NewTLSConfig(certFile string, keyFile string, caCertFile string) (*tls.Config, error) {
+	serverCert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("load server certificate: %w", err)
+	}
+	tlsCfg := &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+	}
+	if err = setClientCAs(tlsCfg, caCertFile); err != nil {
+		return nil, err
+	}
+	return tlsCfg, nil
+}
 
-	err = s.echo.StartTLS(fmt.Sprintf(":%d", s.port), s.tlsConfig.cert, s.tlsConfig.key)
-	if errors.Is(err, http.ErrServerClosed) {
+// setClientCAs requires client certificates validated against the CA in
+// caCertFile, if provided.
+func setClientCAs(tlsCfg *tls.Config, caCertFile string) error {
+	if caCertFile == "" {
 		return nil
 	}
-	return err
+	caCertPool := x509.NewCertPool()
+	caCert, err := os.ReadFile(caCertFile)
+	if err != nil {
+		return fmt.Errorf("read ca certificate: %w", err)
+	}
+	if !caCertPool.AppendCertsFromPEM(caCert) {
+		return fmt.Errorf("append ca certificate")
+	}
+	tlsCfg.ClientCAs = caCertPool
+	tlsCfg.ClientAuth = tls.RequireAndVerifyClientCert
+	return nil
 }
 
 // Stop gracefully shuts down the server.
 func (s *Server) Stop(ctx context.Context) error {
-	return s.echo.Shutdown(ctx)
+	s.mu.RLock()
+	if s.stopTLSReload != nil {
+		s.stopTLSReload()
+	}
+	s.mu.RUnlock()
+	var errs []error
+	if err := s.echo.Shutdown(ctx); err != nil {
+		errs = append(errs, err)
+	}
+	if s.admin != nil {
+		if err := s.admin.echo.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("stop admin server: %w", err))
+		}
+	}
+	return errors.Join(errs...)
 }
 
-func (s *Server) WaitHealthy(maxRetries int, interval time.Duration) error {
-	healthzURL := fmt.Sprintf("%s/healthz", s.Address())
+// OnShutdown registers a hook that Run calls once the server has stopped,
+// e.g. to stop background workers or close a database pool. Hooks are called
+// in the order they are registered and share the shutdown timeout.
+func (s *Server) OnShutdown(name string, hook func(ctx context.Context) error) {
+	s.shutdownHooks = append(s.shutdownHooks, shutdownHook{name: name, fn: hook})
+}
 
-	var res *http.Response
-	var err error
+// Run starts the server and blocks until ctx is done, an interrupt or
+// termination signal is received or the server fails. In-flight requests are
+// then given the shutdown timeout (see WithShutdownTimeout) to drain before
+// the hooks registered with OnShutdown are called. Run returns an error if
+// the server fails to start or become healthy, or if stopping it or any hook
+// fails.
+func (s *Server) Run(ctx context.Context) error {
+	a := app.New(app.WithLogger(s.logger), app.WithShutdownTimeout(s.shutdownTimeout))
+	a.Add("server", func(context.Context) error {
+		if err := s.listen(); err != nil {
+			return err
+		}
+		s.logger.Info("starting server", "address", s.Address())
+		return s.serve()
+	}, s.shutdown, app.WithReady(func(context.Context) error {
+		return s.WaitHealthy(15, time.Second)
+	}))
+	if s.admin != nil {
+		// The admin listener is bound by the server component, which is
+		// ready before the admin server starts.
+		a.Add("admin server", func(context.Context) error {
+			s.logger.Info("starting admin server", "address", s.AdminAddr().String())
+			return s.admin.serve()
+		}, s.admin.echo.Shutdown)
+	}
+	return a.Run(ctx)
+}
 
-	for i := 0; i < maxRetries; i++ {
-		res, err = http.Get(healthzURL)
-		if err == nil && res.StatusCode == http.StatusOK {
-			return nil
+func (s *Server) shutdown(ctx context.Context) error {
+	var errs []error
+	if err := s.Stop(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("stop server: %w", err))
+	}
+	for _, hook := range s.shutdownHooks {
+		s.logger.Info("running shutdown hook", "hook", hook.name)
+		if err := hook.fn(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("shutdown hook %s: %w", hook.name, err))
 		}
+	}
+	return errors.Join(errs...)
+}
+
+// AddHealthCheck registers a named check reported by the /readyz endpoint,
+// e.g. pinging a database. Use health.WithGroups(health.Liveness) to report it
+// by the /livez endpoint instead. It panics if a check with the same name is
+// already registered.
+func (s *Server) AddHealthCheck(name string, check func(ctx context.Context) error, opts ...health.CheckOption) {
+	s.healthRegistry.Register(name, health.CheckerFunc(check), opts...)
+}
 
-		time.Sleep(interval)
+// HealthRegistry returns the health.Registry whose checks are reported by the
+// /livez and /readyz endpoints.
+func (s *Server) HealthRegistry() *health.Registry {
+	return s.healthRegistry
+}
+
+// WaitHealthy waits until the server is live, retrying its /livez endpoint up
+// to maxRetries times.
+func (s *Server) WaitHealthy(maxRetries int, interval time.Duration) error {
+	policy := retry.Policy{
+		MaxAttempts:     max(maxRetries, 1),
+		InitialInterval: interval,
+		MaxInterval:     interval,
+		Jitter:          -1,
 	}
 
+	err := retry.DoErr(context.Background(), policy, func(ctx context.Context) error {
+		// The address is resolved on every attempt as the port is not known
+		// until the server has started if it was created with port 0.
+		res, err := s.client().Get(s.Address() + LivenessPath)
+		if err != nil {
+			return err
+		}
+		res.Body.Close() //nolint:errcheck
+		if res.StatusCode != http.StatusOK {
+			return errors.New(http.StatusText(res.StatusCode))
+		}
+		return nil
+	})
 	if err != nil {
 		return fmt.Errorf("server unhealthy: %w", err)
-	} else if res != nil {
-		return fmt.Errorf("server unhealthy: %s", http.StatusText(res.StatusCode))
 	}
+	return nil
+}
+
+// Addr returns the address the server is bound to, or nil if it has not
+// started yet.
+func (s *Server) Addr() net.Addr {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
 
-	return errors.New("server unhealthy")
+// Port returns the port the server is bound to once started, which is
+// assigned by the OS if the server was created with port 0, or otherwise the
+// configured port.
+func (s *Server) Port() int {
+	if addr, ok := s.Addr().(*net.TCPAddr); ok {
+		return addr.Port
+	}
+	return s.port
 }
 
-// Address returns the server address which clients can connect to.
+// client returns an HTTP client connecting to the server.
+func (s *Server) client() *http.Client {
+	if s.unixSocket != "" {
+		return unixSocketClient(s.unixSocket)
+	}
+	return http.DefaultClient
+}
+
+// Address returns the server address which clients can connect to. With
+// WithUnixSocket, clients must dial the socket and the address only sets the
+// scheme and host of requests.
 func (s *Server) Address() string {
-	hp := fmt.Sprintf("localhost:%d", s.port)
+	if s.unixSocket != "" {
+		if s.tlsConfig == nil {
+			return "http://localhost"
+		}
+		return "https://localhost"
+	}
+	hp := fmt.Sprintf("localhost:%d", s.Port())
 	if s.tlsConfig == nil {
 		return "http://" + hp
 	}
@@ -263,7 +651,7 @@ func (s *Server) Address() string {
 // WebsSocketAddress returns the server WebSocket address which clients can
 // connect to.
 func (s *Server) WebsSocketAddress() string {
-	hp := fmt.Sprintf("localhost:%d", s.port)
+	hp := fmt.Sprintf("localhost:%d", s.Port())
 	if s.tlsConfig == nil {
 		return "ws://" + hp
 	}
@@ -285,3 +673,15 @@ func (s *Server) Add(method string, path string, handler echo.HandlerFunc) {
 func (s *Server) Any(path string, handler echo.HandlerFunc) {
 	s.echo.Any(path, handler)
 }
+
+// AddRoute adds a route for method and path configured by opts, e.g.
+// WithTimeout.
+func (s *Server) AddRoute(method string, path string, handler echo.HandlerFunc, opts ...RouteOption) {
+	s.echo.Add(method, path, handler, s.routeMiddlewares(routeKey{method: method, path: path}, opts)...)
+}
+
+// AnyRoute adds a route for any method and path configured by opts, e.g.
+// WithTimeout.
+func (s *Server) AnyRoute(path string, handler echo.HandlerFunc, opts ...RouteOption) {
+	s.echo.Any(path, handler, s.routeMiddlewares(routeKey{path: path}, opts)...)
+}
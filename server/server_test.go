@@ -4,8 +4,11 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"net"
 	"net/http"
-	"os"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -14,15 +17,9 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/joshjon/kit/health"
 	"github.com/joshjon/kit/log"
-)
-
-const (
-	serverCertFile = "./testdata/server-cert.pem"
-	serverKeyFile  = "./testdata/server-key.pem"
-	clientCertFile = "./testdata/client-cert.pem"
-	clientKeyFile  = "./testdata/client-key.pem"
-	caCertFile     = "./testdata/ca-cert.pem"
+	"github.com/joshjon/kit/testutil"
 )
 
 func TestServer_NewServer(t *testing.T) {
@@ -40,7 +37,8 @@ func TestServer_NewServer(t *testing.T) {
 }
 
 func TestServer_TLS(t *testing.T) {
-	srv, err := NewServer(443, WithTLS(serverCertFile, serverKeyFile, ""))
+	certs := generateTestCerts(t)
+	srv, err := NewServer(443, WithTLS(certs.serverCertFile, certs.serverKeyFile, ""))
 	require.NoError(t, err)
 
 	go srv.Start()
@@ -65,21 +63,20 @@ func TestServer_TLS(t *testing.T) {
 }
 
 func TestServer_mTLS(t *testing.T) {
-	srv, err := NewServer(443, WithTLS(serverCertFile, serverKeyFile, caCertFile))
+	certs := generateTestCerts(t)
+	srv, err := NewServer(443, WithTLS(certs.serverCertFile, certs.serverKeyFile, certs.caCertFile))
 	require.NoError(t, err)
 
 	go srv.Start()
 	defer srv.Stop(context.Background())
 	time.Sleep(5 * time.Millisecond)
 
-	clientCert, caCertPool := loadClientCerts(t, err)
-
 	client := &http.Client{
 		Timeout: 5 * time.Second,
 		Transport: &http.Transport{
 			TLSClientConfig: &tls.Config{
-				Certificates:       []tls.Certificate{clientCert},
-				RootCAs:            caCertPool,
+				Certificates:       []tls.Certificate{certs.clientCert},
+				RootCAs:            certs.caCertPool,
 				InsecureSkipVerify: false,
 			},
 		},
@@ -93,7 +90,8 @@ func TestServer_mTLS(t *testing.T) {
 }
 
 func TestServer_TLSWebSocket(t *testing.T) {
-	srv, err := NewServer(443, WithTLS(serverCertFile, serverKeyFile, ""))
+	certs := generateTestCerts(t)
+	srv, err := NewServer(443, WithTLS(certs.serverCertFile, certs.serverKeyFile, ""))
 	require.NoError(t, err)
 
 	wantMsg := []byte("connected")
@@ -127,7 +125,8 @@ func TestServer_TLSWebSocket(t *testing.T) {
 }
 
 func TestServer_mTLSWebSocket(t *testing.T) {
-	srv, err := NewServer(443, WithTLS(serverCertFile, serverKeyFile, caCertFile))
+	certs := generateTestCerts(t)
+	srv, err := NewServer(443, WithTLS(certs.serverCertFile, certs.serverKeyFile, certs.caCertFile))
 	require.NoError(t, err)
 
 	wantMsg := []byte("connected")
@@ -141,15 +140,13 @@ func TestServer_mTLSWebSocket(t *testing.T) {
 	defer srv.Stop(ctx)
 	time.Sleep(5 * time.Millisecond)
 
-	clientCert, caCertPool := loadClientCerts(t, err)
-
 	conn, _, err := websocket.Dial(ctx, "wss://127.0.0.1:443", &websocket.DialOptions{
 		HTTPClient: &http.Client{
 			Timeout: 5 * time.Second,
 			Transport: &http.Transport{
 				TLSClientConfig: &tls.Config{
-					Certificates:       []tls.Certificate{clientCert},
-					RootCAs:            caCertPool,
+					Certificates:       []tls.Certificate{certs.clientCert},
+					RootCAs:            certs.caCertPool,
 					InsecureSkipVerify: false,
 				},
 			},
@@ -162,14 +159,26 @@ func TestServer_mTLSWebSocket(t *testing.T) {
 	assert.Equal(t, wantMsg, gotMsg)
 }
 
-func loadClientCerts(t *testing.T, err error) (tls.Certificate, *x509.CertPool) {
-	clientCert, err := tls.LoadX509KeyPair(clientCertFile, clientKeyFile)
-	require.NoError(t, err)
-	caCert, err := os.ReadFile(caCertFile)
-	require.NoError(t, err)
-	caCertPool := x509.NewCertPool()
-	require.True(t, caCertPool.AppendCertsFromPEM(caCert))
-	return clientCert, caCertPool
+type testCerts struct {
+	serverCertFile string
+	serverKeyFile  string
+	caCertFile     string
+	clientCert     tls.Certificate
+	caCertPool     *x509.CertPool
+}
+
+func generateTestCerts(t *testing.T) testCerts {
+	ca := testutil.GenerateCA(t)
+	caCertFile, _ := ca.WriteFiles(t)
+	serverCertFile, serverKeyFile := testutil.GenerateCert(t, ca).WriteFiles(t)
+	client := testutil.GenerateCert(t, ca, testutil.WithCommonName("client"), testutil.WithClientAuth())
+	return testCerts{
+		serverCertFile: serverCertFile,
+		serverKeyFile:  serverKeyFile,
+		caCertFile:     caCertFile,
+		clientCert:     client.TLSCertificate(t),
+		caCertPool:     ca.CertPool(),
+	}
 }
 
 func testWebSocketHandler(wantMsg []byte) func(c echo.Context) error {
@@ -188,3 +197,156 @@ func testWebSocketHandler(wantMsg []byte) func(c echo.Context) error {
 		return conn.Close(websocket.StatusNormalClosure, "success")
 	}
 }
+
+func TestServer_Run(t *testing.T) {
+	srv, err := NewServer(0, WithLogger(log.NewLogger(log.WithNop())), WithShutdownTimeout(time.Second))
+	require.NoError(t, err)
+
+	var hooks []string
+	for _, name := range []string{"workers", "db"} {
+		srv.OnShutdown(name, func(context.Context) error {
+			hooks = append(hooks, name)
+			return nil
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() { errs <- srv.Run(ctx) }()
+	require.NoError(t, srv.WaitHealthy(20, 50*time.Millisecond))
+
+	cancel()
+	require.NoError(t, testutil.AssertReceiveChan(t, errs, 5*time.Second))
+	assert.Equal(t, []string{"workers", "db"}, hooks)
+
+	_, err = http.Get(srv.Address() + "/healthz")
+	assert.Error(t, err)
+}
+
+func TestServer_AddHealthCheck(t *testing.T) {
+	srv, err := NewServer(0, WithLogger(log.NewLogger(log.WithNop())))
+	require.NoError(t, err)
+
+	dbErr := errors.New("connection refused")
+	srv.AddHealthCheck("postgres", func(context.Context) error { return dbErr })
+	srv.AddHealthCheck("nats", func(context.Context) error { return nil })
+	srv.AddHealthCheck("deadlock", func(context.Context) error { return nil }, health.WithGroups(health.Liveness))
+
+	tests := []struct {
+		path       string
+		wantStatus int
+		wantReport health.Report
+	}{
+		{
+			path:       LivenessPath,
+			wantStatus: http.StatusOK,
+			wantReport: health.Report{Status: health.StatusUp, Checks: map[string]health.Result{
+				"deadlock": {Status: health.StatusUp},
+			}},
+		},
+		{
+			path:       HealthPath,
+			wantStatus: http.StatusOK,
+			wantReport: health.Report{Status: health.StatusUp, Checks: map[string]health.Result{
+				"deadlock": {Status: health.StatusUp},
+			}},
+		},
+		{
+			path:       ReadinessPath,
+			wantStatus: http.StatusServiceUnavailable,
+			wantReport: health.Report{Status: health.StatusDown, Checks: map[string]health.Result{
+				"postgres": {Status: health.StatusDown, Error: dbErr.Error()},
+				"nats":     {Status: health.StatusUp},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			srv.echo.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			assert.Equal(t, tt.wantStatus, rec.Code)
+
+			var report health.Report
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+			for name, res := range report.Checks {
+				res.DurationMS, res.CheckedAt = 0, time.Time{}
+				report.Checks[name] = res
+			}
+			assert.Equal(t, tt.wantReport, report)
+		})
+	}
+}
+
+func TestServer_WithHTTP2(t *testing.T) {
+	srv, err := NewServer(0, WithLogger(log.NewLogger(log.WithNop())), WithHTTP2())
+	require.NoError(t, err)
+
+	go srv.Start()
+	defer srv.Stop(context.Background())
+	require.NoError(t, srv.WaitHealthy(20, 50*time.Millisecond))
+
+	protocols := new(http.Protocols)
+	protocols.SetUnencryptedHTTP2(true)
+	client := &http.Client{
+		Timeout:   5 * time.Second,
+		Transport: &http.Transport{Protocols: protocols},
+	}
+	httpRes, err := client.Get(srv.Address() + LivenessPath)
+	require.NoError(t, err)
+	defer httpRes.Body.Close()
+	assert.Equal(t, http.StatusOK, httpRes.StatusCode)
+	assert.Equal(t, 2, httpRes.ProtoMajor)
+}
+
+func TestServer_TLSHTTP2(t *testing.T) {
+	certs := generateTestCerts(t)
+	srv, err := NewServer(0, WithLogger(log.NewLogger(log.WithNop())), WithTLS(certs.serverCertFile, certs.serverKeyFile, ""))
+	require.NoError(t, err)
+
+	go srv.Start()
+	defer srv.Stop(context.Background())
+	time.Sleep(5 * time.Millisecond)
+
+	client := &http.Client{
+		Timeout: 5 * time.Second,
+		Transport: &http.Transport{
+			ForceAttemptHTTP2: true,
+			TLSClientConfig: &tls.Config{
+				// Using self signed certs so InsecureSkipVerify=true
+				InsecureSkipVerify: true,
+			},
+		},
+	}
+	httpRes, err := client.Get(srv.Address() + LivenessPath)
+	require.NoError(t, err)
+	defer httpRes.Body.Close()
+	assert.Equal(t, http.StatusOK, httpRes.StatusCode)
+	assert.Equal(t, 2, httpRes.ProtoMajor)
+}
+
+func TestServer_Port(t *testing.T) {
+	srv, err := NewServer(0, WithLogger(log.NewLogger(log.WithNop())))
+	require.NoError(t, err)
+	assert.Nil(t, srv.Addr())
+	assert.Equal(t, 0, srv.Port())
+
+	go srv.Start()
+	defer srv.Stop(context.Background())
+	require.NoError(t, srv.WaitHealthy(20, 50*time.Millisecond))
+
+	assert.NotZero(t, srv.Port())
+	assert.Equal(t, srv.Port(), srv.Addr().(*net.TCPAddr).Port)
+}
+
+func TestServer_WithListener(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv, err := NewServer(0, WithLogger(log.NewLogger(log.WithNop())), WithListener(l))
+	require.NoError(t, err)
+
+	go srv.Start()
+	defer srv.Stop(context.Background())
+	require.NoError(t, srv.WaitHealthy(20, 50*time.Millisecond))
+	assert.Equal(t, l.Addr().(*net.TCPAddr).Port, srv.Port())
+}
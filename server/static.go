@@ -0,0 +1,113 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// htmlCacheControl makes clients revalidate HTML documents, so they pick
+	// up new asset references as soon as they are deployed.
+	htmlCacheControl = "no-cache"
+	// assetCacheControl lets clients cache other files for a while before
+	// revalidating them using their ETag.
+	assetCacheControl = "public, max-age=3600"
+	defaultIndexFile  = "index.html"
+)
+
+// Static serves the files of fsys under prefix, e.g. an embed.FS of a front
+// end. Requests for directories serve their index.html. HTML files are served
+// with Cache-Control no-cache and other files are cached for an hour. All
+// files have an ETag so clients can revalidate them.
+func (s *Server) Static(prefix string, fsys fs.FS) {
+	s.registerStatic(prefix, newStaticFS(fsys, ""))
+}
+
+// SPA serves the single-page application in fsys under prefix as Static
+// does, except that requests for paths without a file extension that don't
+// match a file serve indexFile, so routes of the application can be loaded
+// directly. indexFile defaults to index.html. Routes registered on the server
+// take precedence over the application.
+func (s *Server) SPA(prefix string, fsys fs.FS, indexFile string) {
+	if indexFile == "" {
+		indexFile = defaultIndexFile
+	}
+	s.registerStatic(prefix, newStaticFS(fsys, indexFile))
+}
+
+func (s *Server) registerStatic(prefix string, sfs *staticFS) {
+	prefix = strings.TrimSuffix(prefix, "/")
+	methods := []string{http.MethodGet, http.MethodHead}
+	if prefix != "" {
+		s.echo.Match(methods, prefix, sfs.handle)
+	}
+	s.echo.Match(methods, prefix+"/*", sfs.handle)
+}
+
+type staticFS struct {
+	fsys     fs.FS
+	fallback string // empty to respond with 404 instead
+}
+
+func newStaticFS(fsys fs.FS, fallback string) *staticFS {
+	return &staticFS{fsys: fsys, fallback: fallback}
+}
+
+func (f *staticFS) handle(c echo.Context) error {
+	name := path.Clean("/" + c.Param("*"))[1:]
+	if name == "" {
+		name = "."
+	}
+
+	content, file, err := f.read(name)
+	if errors.Is(err, fs.ErrNotExist) && f.fallback != "" && path.Ext(name) == "" {
+		content, file, err = f.read(f.fallback)
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		return echo.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	header := c.Response().Header()
+	header.Set("ETag", etag(content))
+	if mediaType, _, _ := mime.ParseMediaType(mime.TypeByExtension(path.Ext(file))); mediaType == "text/html" {
+		header.Set("Cache-Control", htmlCacheControl)
+	} else {
+		header.Set("Cache-Control", assetCacheControl)
+	}
+	// Files of an embed.FS have no modification time, so clients revalidate
+	// using the ETag instead.
+	http.ServeContent(c.Response(), c.Request(), file, time.Time{}, bytes.NewReader(content))
+	return nil
+}
+
+// read reads the file name, or the index.html of the directory name, and
+// returns its content and name.
+func (f *staticFS) read(name string) ([]byte, string, error) {
+	info, err := fs.Stat(f.fsys, name)
+	if err != nil {
+		return nil, name, err
+	}
+	if info.IsDir() {
+		name = path.Join(name, defaultIndexFile)
+	}
+	content, err := fs.ReadFile(f.fsys, name)
+	return content, name, err
+}
+
+func etag(content []byte) string {
+	sum := sha256.Sum256(content)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/log"
+)
+
+var testFS = fstest.MapFS{
+	"index.html":           {Data: []byte("<html>index</html>")},
+	"assets/app-1a2b3c.js": {Data: []byte("console.log('app')")},
+	"docs/index.html":      {Data: []byte("<html>docs</html>")},
+}
+
+func TestServer_Static(t *testing.T) {
+	srv, err := NewServer(0, WithLogger(log.NewLogger(log.WithNop())))
+	require.NoError(t, err)
+	srv.Static("/static", testFS)
+
+	tests := []struct {
+		path             string
+		wantCode         int
+		wantBody         string
+		wantCacheControl string
+	}{
+		{path: "/static", wantCode: http.StatusOK, wantBody: "<html>index</html>", wantCacheControl: htmlCacheControl},
+		{path: "/static/assets/app-1a2b3c.js", wantCode: http.StatusOK, wantBody: "console.log('app')", wantCacheControl: assetCacheControl},
+		{path: "/static/docs", wantCode: http.StatusOK, wantBody: "<html>docs</html>", wantCacheControl: htmlCacheControl},
+		{path: "/static/missing", wantCode: http.StatusNotFound},
+		{path: "/static/../index.html", wantCode: http.StatusOK, wantBody: "<html>index</html>", wantCacheControl: htmlCacheControl},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			srv.echo.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			assert.Equal(t, tt.wantCode, rec.Code)
+			if tt.wantCode == http.StatusOK {
+				assert.Equal(t, tt.wantBody, rec.Body.String())
+				assert.Equal(t, tt.wantCacheControl, rec.Header().Get("Cache-Control"))
+				assert.NotEmpty(t, rec.Header().Get("ETag"))
+			}
+		})
+	}
+}
+
+func TestServer_Static_notModified(t *testing.T) {
+	srv, err := NewServer(0, WithLogger(log.NewLogger(log.WithNop())))
+	require.NoError(t, err)
+	srv.Static("/", testFS)
+
+	rec := httptest.NewRecorder()
+	srv.echo.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/assets/app-1a2b3c.js", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/assets/app-1a2b3c.js", nil)
+	req.Header.Set("If-None-Match", rec.Header().Get("ETag"))
+	rec = httptest.NewRecorder()
+	srv.echo.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+}
+
+func TestServer_SPA(t *testing.T) {
+	srv, err := NewServer(0, WithLogger(log.NewLogger(log.WithNop())))
+	require.NoError(t, err)
+	srv.Add(http.MethodGet, "/api/users", func(c echo.Context) error {
+		return c.String(http.StatusOK, "users")
+	})
+	srv.SPA("/", testFS, "")
+
+	tests := []struct {
+		path     string
+		wantCode int
+		wantBody string
+	}{
+		{path: "/", wantCode: http.StatusOK, wantBody: "<html>index</html>"},
+		{path: "/projects/1", wantCode: http.StatusOK, wantBody: "<html>index</html>"},
+		{path: "/assets/app-1a2b3c.js", wantCode: http.StatusOK, wantBody: "console.log('app')"},
+		{path: "/assets/missing.js", wantCode: http.StatusNotFound},
+		{path: "/api/users", wantCode: http.StatusOK, wantBody: "users"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			srv.echo.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			assert.Equal(t, tt.wantCode, rec.Code)
+			if tt.wantCode == http.StatusOK {
+				assert.Equal(t, tt.wantBody, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/log"
+	"github.com/joshjon/kit/testutil"
+)
+
+func TestCertReloader(t *testing.T) {
+	ca := testutil.GenerateCA(t)
+	oldCert := testutil.GenerateCert(t, ca)
+	certFile, keyFile := oldCert.WriteFiles(t)
+
+	reloader, err := newCertReloader(certFile, keyFile, log.NewLogger(log.WithNop()))
+	require.NoError(t, err)
+	assertServedCert(t, reloader, oldCert.Cert)
+
+	reloaded, err := reloader.reload()
+	require.NoError(t, err)
+	assert.False(t, reloaded, "expected unchanged files not to be reloaded")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go reloader.run(ctx, 10*time.Millisecond)
+
+	newCert := testutil.GenerateCert(t, ca)
+	writeCertFiles(t, newCert, certFile, keyFile)
+	require.Eventually(t, func() bool {
+		cert, err := reloader.GetCertificate(nil)
+		return err == nil && cert.Leaf.Equal(newCert.Cert)
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestCertReloader_invalidFiles(t *testing.T) {
+	ca := testutil.GenerateCA(t)
+	cert := testutil.GenerateCert(t, ca)
+	certFile, keyFile := cert.WriteFiles(t)
+
+	reloader, err := newCertReloader(certFile, keyFile, log.NewLogger(log.WithNop()))
+	require.NoError(t, err)
+
+	// A partially written certificate keeps the previous one served.
+	require.NoError(t, os.WriteFile(certFile, []byte("invalid"), 0o600))
+	require.NoError(t, os.Chtimes(certFile, time.Now(), time.Now().Add(time.Minute)))
+	_, err = reloader.reload()
+	assert.Error(t, err)
+	assertServedCert(t, reloader, cert.Cert)
+
+	_, err = newCertReloader(certFile, keyFile, log.NewLogger(log.WithNop()))
+	assert.Error(t, err)
+}
+
+func TestServer_WithTLSGetCertificate(t *testing.T) {
+	ca := testutil.GenerateCA(t)
+	cert := testutil.GenerateCert(t, ca).TLSCertificate(t)
+	srv, err := NewServer(0,
+		WithLogger(log.NewLogger(log.WithNop())),
+		WithTLSGetCertificate(func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return &cert, nil
+		}),
+	)
+	require.NoError(t, err)
+
+	go srv.Start()
+	defer srv.Stop(context.Background())
+
+	client := &http.Client{
+		Timeout: 5 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: ca.CertPool()},
+		},
+	}
+	require.Eventually(t, func() bool {
+		res, err := client.Get(srv.Address() + LivenessPath)
+		if err != nil {
+			return false
+		}
+		res.Body.Close()
+		return res.StatusCode == http.StatusOK
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func assertServedCert(t *testing.T, reloader *certReloader, want *x509.Certificate) {
+	t.Helper()
+	cert, err := reloader.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.True(t, cert.Leaf.Equal(want))
+}
+
+func writeCertFiles(t *testing.T, cert *testutil.Cert, certFile string, keyFile string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(certFile, cert.CertPEM, 0o600))
+	require.NoError(t, os.WriteFile(keyFile, cert.KeyPEM, 0o600))
+	// Ensure the modification times change on file systems with a coarse
+	// timestamp resolution.
+	modTime := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, modTime, modTime))
+	require.NoError(t, os.Chtimes(keyFile, modTime, modTime))
+}
@@ -3,6 +3,8 @@ package server
 import (
 	"fmt"
 	"strings"
+
+	"github.com/joshjon/kit/valgoutil"
 )
 
 type Response[T any] struct {
@@ -19,10 +21,15 @@ type ResponseError struct {
 }
 
 type HTTPError struct {
-	Code     int      `json:"-"`
-	Internal string   `json:"-"`
-	Message  string   `json:"message"`
-	Details  []string `json:"details,omitempty"`
+	Code        int                    `json:"-"`
+	Internal    string                 `json:"-"`
+	ErrorCode   string                 `json:"code,omitempty"`
+	Message     string                 `json:"message"`
+	Details     []string               `json:"details,omitempty"`
+	Fields      map[string]any         `json:"fields,omitempty"`
+	FieldErrors []valgoutil.FieldError `json:"field_errors,omitempty"`
+	RequestID   string                 `json:"request_id,omitempty"`
+	cause       error                  // original error for structured logging
 }
 
 func (e HTTPError) Error() string {
@@ -32,6 +39,7 @@ func (e HTTPError) Error() string {
 	return e.Message
 }
 
+// Deprecated: health endpoints respond with a health.Report.
 type HealthResponse struct {
 	Status string `json:"status"`
 }
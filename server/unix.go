@@ -0,0 +1,101 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"time"
+)
+
+const (
+	// DefaultUnixSocketMode is the file mode of the socket of WithUnixSocket,
+	// allowing the owner and group of the server process to connect.
+	DefaultUnixSocketMode os.FileMode = 0o660
+
+	// staleSocketDialTimeout is how long connecting to an existing socket
+	// file may take before it is considered stale.
+	staleSocketDialTimeout = time.Second
+)
+
+// WithUnixSocket configures the server to listen on a unix domain socket at
+// path instead of its port, e.g. for sidecar deployments where only a local
+// proxy should reach the service. A stale socket file left by a previous
+// process is removed on start, but starting fails if another process is
+// listening on it. The socket file is removed when the server stops. See
+// WithUnixSocketMode to configure who may connect.
+func WithUnixSocket(path string) Option {
+	return func(opts *options) error {
+		if path == "" {
+			return errors.New("unix socket path must not be empty")
+		}
+		opts.unixSocket = path
+		return nil
+	}
+}
+
+// WithUnixSocketMode sets the file mode of the socket of WithUnixSocket,
+// which controls which users may connect to it. Defaults to
+// DefaultUnixSocketMode.
+func WithUnixSocketMode(mode os.FileMode) Option {
+	return func(opts *options) error {
+		opts.unixSocketMode = mode
+		return nil
+	}
+}
+
+// listenUnix listens on the unix socket at path with the file mode mode,
+// removing a stale socket file first.
+func listenUnix(path string, mode os.FileMode) (net.Listener, error) {
+	if err := removeStaleSocket(path); err != nil {
+		return nil, err
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("listen: %w", err)
+	}
+	if err = os.Chmod(path, mode); err != nil {
+		l.Close() //nolint:errcheck
+		return nil, fmt.Errorf("chmod unix socket: %w", err)
+	}
+	return l, nil
+}
+
+// removeStaleSocket removes the socket file at path if no process is
+// listening on it. It fails if path exists but is not a socket, so files are
+// never removed by mistake.
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("stat unix socket: %w", err)
+	}
+	if info.Mode()&fs.ModeSocket == 0 {
+		return fmt.Errorf("unix socket path is not a socket: %s", path)
+	}
+	conn, err := net.DialTimeout("unix", path, staleSocketDialTimeout)
+	if err == nil {
+		conn.Close() //nolint:errcheck
+		return fmt.Errorf("unix socket already in use: %s", path)
+	}
+	if err = os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("remove stale unix socket: %w", err)
+	}
+	return nil
+}
+
+// unixSocketClient returns an HTTP client connecting to the unix socket at
+// path for any address.
+func unixSocketClient(path string) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, "unix", path)
+	}
+	return &http.Client{Transport: transport}
+}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/log"
+)
+
+func TestServer_WithUnixSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.sock")
+	srv, err := NewServer(0,
+		WithLogger(log.NewLogger(log.WithNop())),
+		WithUnixSocket(path),
+		WithUnixSocketMode(0o600),
+	)
+	require.NoError(t, err)
+
+	go srv.Start()
+	require.NoError(t, srv.WaitHealthy(20, 50*time.Millisecond))
+	assert.Equal(t, "unix", srv.Addr().Network())
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	res, err := unixSocketClient(path).Get(srv.Address() + LivenessPath)
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	require.NoError(t, srv.Stop(context.Background()))
+	assert.NoFileExists(t, path)
+}
+
+func TestServer_WithUnixSocket_staleSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.sock")
+	l, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	l.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, l.Close())
+	require.FileExists(t, path)
+
+	srv, err := NewServer(0, WithLogger(log.NewLogger(log.WithNop())), WithUnixSocket(path))
+	require.NoError(t, err)
+
+	go srv.Start()
+	defer srv.Stop(context.Background())
+	require.NoError(t, srv.WaitHealthy(20, 50*time.Millisecond))
+}
+
+func TestServer_WithUnixSocket_invalidPath(t *testing.T) {
+	dir := t.TempDir()
+
+	inUse := filepath.Join(dir, "in-use.sock")
+	l, err := net.Listen("unix", inUse)
+	require.NoError(t, err)
+	defer l.Close()
+
+	notSocket := filepath.Join(dir, "file")
+	require.NoError(t, os.WriteFile(notSocket, []byte("data"), 0o600))
+
+	for _, path := range []string{inUse, notSocket} {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			srv, err := NewServer(0, WithLogger(log.NewLogger(log.WithNop())), WithUnixSocket(path))
+			require.NoError(t, err)
+			require.Error(t, srv.Start())
+			assert.FileExists(t, path)
+		})
+	}
+}
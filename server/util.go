@@ -2,6 +2,9 @@ package server
 
 import (
 	"encoding/base64"
+	"mime"
+	"net/http"
+	"strings"
 
 	"github.com/labstack/echo/v4"
 )
@@ -46,3 +49,14 @@ func SetResponseError(c echo.Context, code int, err HTTPError) error {
 		Error: err,
 	})
 }
+
+// isEventStreamRequest reports whether the client accepts server-sent events,
+// as sent by browser EventSource connections.
+func isEventStreamRequest(req *http.Request) bool {
+	for _, accept := range strings.Split(req.Header.Get(echo.HeaderAccept), ",") {
+		if mediaType, _, _ := mime.ParseMediaType(strings.TrimSpace(accept)); mediaType == "text/event-stream" {
+			return true
+		}
+	}
+	return false
+}
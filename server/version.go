@@ -0,0 +1,129 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/joshjon/kit/log"
+)
+
+const (
+	HeaderDeprecation = "Deprecation"
+	HeaderSunset      = "Sunset"
+
+	// deprecatedLogInterval is how often the usage of a deprecated version is
+	// logged at most.
+	deprecatedLogInterval = time.Minute
+)
+
+// VersionOption optionally configures a version registered with
+// Server.RegisterVersioned.
+type VersionOption func(opts *versionOptions)
+
+// WithDeprecated marks the version as deprecated since at, so responses
+// include a Deprecation header (RFC 9745) and its usage is logged.
+func WithDeprecated(at time.Time) VersionOption {
+	return func(opts *versionOptions) {
+		opts.deprecatedAt = at
+	}
+}
+
+// WithSunset sets when the version will be removed, so responses include a
+// Sunset header (RFC 8594). A version with a sunset is deprecated.
+func WithSunset(at time.Time) VersionOption {
+	return func(opts *versionOptions) {
+		opts.sunsetAt = at
+	}
+}
+
+// WithVersionMiddleware adds middleware to the routes of the version.
+func WithVersionMiddleware(middlewares ...echo.MiddlewareFunc) VersionOption {
+	return func(opts *versionOptions) {
+		opts.middlewares = append(opts.middlewares, middlewares...)
+	}
+}
+
+type versionOptions struct {
+	deprecatedAt time.Time
+	sunsetAt     time.Time
+	middlewares  []echo.MiddlewareFunc
+}
+
+// RegisterVersioned registers the routes of h under /v{N} for version N,
+// e.g. "v1" or "1" registers them under /v1. Deprecated versions respond
+// with Deprecation and Sunset headers, and their usage is logged at most once
+// a minute with the number of requests since the server started, so operators
+// know when it's safe to remove them.
+func (s *Server) RegisterVersioned(version string, h Handler, opts ...VersionOption) {
+	var options versionOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	prefix := "/v" + strings.TrimPrefix(version, "v")
+	middlewares := options.middlewares
+	if !options.deprecatedAt.IsZero() || !options.sunsetAt.IsZero() {
+		usage := &deprecatedUsage{version: prefix[1:], logger: s.logger}
+		middlewares = append([]echo.MiddlewareFunc{deprecationMiddleware(options, usage)}, middlewares...)
+	}
+	s.Register(prefix, h, middlewares...)
+}
+
+func deprecationMiddleware(opts versionOptions, usage *deprecatedUsage) echo.MiddlewareFunc {
+	deprecation := "true"
+	if !opts.deprecatedAt.IsZero() {
+		deprecation = "@" + strconv.FormatInt(opts.deprecatedAt.Unix(), 10)
+	}
+	var sunset string
+	if !opts.sunsetAt.IsZero() {
+		sunset = opts.sunsetAt.UTC().Format(http.TimeFormat)
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			header := c.Response().Header()
+			header.Set(HeaderDeprecation, deprecation)
+			if sunset != "" {
+				header.Set(HeaderSunset, sunset)
+			}
+			usage.record(c)
+			return next(c)
+		}
+	}
+}
+
+// deprecatedUsage counts the requests to a deprecated version.
+type deprecatedUsage struct {
+	version string
+	logger  log.Logger
+	count   atomic.Int64
+
+	mu         sync.Mutex
+	lastLogged time.Time
+}
+
+func (u *deprecatedUsage) record(c echo.Context) {
+	count := u.count.Add(1)
+
+	u.mu.Lock()
+	now := time.Now()
+	if now.Sub(u.lastLogged) < deprecatedLogInterval {
+		u.mu.Unlock()
+		return
+	}
+	u.lastLogged = now
+	u.mu.Unlock()
+
+	u.logger.Warn("deprecated api version used",
+		"version", u.version,
+		"requests", count,
+		"method", c.Request().Method,
+		"path", c.Path(),
+		"user_agent", c.Request().UserAgent(),
+	)
+}
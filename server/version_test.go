@@ -0,0 +1,58 @@
+package server
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/testutil"
+)
+
+type versionHandler string
+
+func (h versionHandler) Register(g *echo.Group) {
+	g.GET("/users", func(c echo.Context) error {
+		return c.String(http.StatusOK, string(h))
+	})
+}
+
+func TestServer_RegisterVersioned(t *testing.T) {
+	logger := testutil.NewRecordingLogger()
+	srv, err := NewServer(0, WithLogger(logger))
+	require.NoError(t, err)
+
+	deprecatedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	sunsetAt := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	srv.RegisterVersioned("v1", versionHandler("v1"), WithDeprecated(deprecatedAt), WithSunset(sunsetAt))
+	srv.RegisterVersioned("2", versionHandler("v2"))
+
+	do := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		srv.echo.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	rec := do("/v1/users")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "v1", rec.Body.String())
+	assert.Equal(t, "@1767225600", rec.Header().Get(HeaderDeprecation))
+	assert.Equal(t, "Wed, 01 Jul 2026 00:00:00 GMT", rec.Header().Get(HeaderSunset))
+	logger.AssertLogged(t, slog.LevelWarn, "deprecated api version used", "version", "v1", "requests", int64(1))
+
+	// Usage is logged at most once a minute.
+	logger.Reset()
+	do("/v1/users")
+	logger.AssertNotLogged(t, slog.LevelWarn, "deprecated api version used")
+
+	rec = do("/v2/users")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "v2", rec.Body.String())
+	assert.Empty(t, rec.Header().Get(HeaderDeprecation))
+	assert.Empty(t, rec.Header().Get(HeaderSunset))
+}
@@ -8,15 +8,18 @@ import (
 	"strings"
 	"time"
 
-	"github.com/cenkalti/backoff/v4"
 	_ "modernc.org/sqlite"
-)
 
-const (
-	healthRetryInterval = time.Second
-	healthMaxRetries    = 5
+	"github.com/joshjon/kit/retry"
 )
 
+var healthRetryPolicy = retry.Policy{
+	MaxAttempts:     6,
+	InitialInterval: time.Second,
+	MaxInterval:     time.Second,
+	Jitter:          -1,
+}
+
 type OpenOption func(opts *openOpts)
 
 // WithDir sets the directory used to store the SQLite database file.
@@ -125,13 +128,12 @@ func Open(ctx context.Context, opts ...OpenOption) (*sql.DB, error) {
 }
 
 func waitHealthy(ctx context.Context, db *sql.DB) error {
-	pingFn := func() error {
+	pingFn := func(ctx context.Context) error {
 		pctx, cancel := context.WithTimeout(ctx, time.Second)
 		defer cancel()
 		return db.PingContext(pctx)
 	}
-	bo := backoff.WithMaxRetries(backoff.NewConstantBackOff(healthRetryInterval), healthMaxRetries)
-	if err := backoff.Retry(pingFn, bo); err != nil {
+	if err := retry.DoErr(ctx, healthRetryPolicy, pingFn); err != nil {
 		return fmt.Errorf("sqlite connection unhealthy: %w", err)
 	}
 	return nil
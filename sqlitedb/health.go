@@ -0,0 +1,12 @@
+package sqlitedb
+
+import (
+	"database/sql"
+
+	"github.com/joshjon/kit/health"
+)
+
+// HealthChecker returns a health.Checker pinging db.
+func HealthChecker(db *sql.DB) health.Checker {
+	return health.CheckerFunc(db.PingContext)
+}
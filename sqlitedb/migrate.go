@@ -13,7 +13,8 @@ import (
 )
 
 type migrationOptions struct {
-	version *uint
+	version         *uint
+	migrationsTable string
 }
 
 type MigrateOption func(opts *migrationOptions)
@@ -24,6 +25,15 @@ func WithVersion(version uint) MigrateOption {
 	}
 }
 
+// WithMigrationsTable sets the table recording the applied migrations, e.g. so
+// a package can apply its own migrations alongside those of the service.
+// Defaults to schema_migrations.
+func WithMigrationsTable(table string) MigrateOption {
+	return func(opts *migrationOptions) {
+		opts.migrationsTable = table
+	}
+}
+
 func Migrate(db *sql.DB, fsys fs.FS, opts ...MigrateOption) error {
 	var mopts migrationOptions
 	for _, opt := range opts {
@@ -36,7 +46,7 @@ func Migrate(db *sql.DB, fsys fs.FS, opts ...MigrateOption) error {
 	}
 	defer sd.Close() //nolint:errcheck
 
-	driver, err := sqlite.WithInstance(db, new(sqlite.Config))
+	driver, err := sqlite.WithInstance(db, &sqlite.Config{MigrationsTable: mopts.migrationsTable})
 	if err != nil {
 		return fmt.Errorf("create sqlite driver: %w", err)
 	}
@@ -0,0 +1,283 @@
+// Package sse streams server-sent events to clients, e.g. live updates to
+// dashboards, without WebSockets. A Broker fans out events published to a
+// topic to its subscribers and Handler serves them as an event stream.
+package sse
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/joshjon/kit/clock"
+	"github.com/joshjon/kit/log"
+)
+
+const (
+	defaultReplaySize        = 100
+	defaultBufferSize        = 32
+	defaultHeartbeatInterval = 15 * time.Second
+)
+
+// ErrBrokerClosed is returned when subscribing to a closed Broker.
+var ErrBrokerClosed = errors.New("sse: broker closed")
+
+// Event is a server-sent event.
+type Event struct {
+	// ID is set by the Broker when the event is published. Clients send the
+	// ID of the last event they received when reconnecting to replay the
+	// events they missed.
+	ID string
+	// Type is the type of the event, dispatched by EventSource to listeners
+	// of that type. Defaults to message.
+	Type string
+	Data []byte
+	// Retry tells the client how long to wait before reconnecting.
+	Retry time.Duration
+}
+
+// NewJSONEvent creates an Event of eventType with v encoded as JSON.
+func NewJSONEvent(eventType string, v any) (Event, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return Event{}, fmt.Errorf("marshal event data: %w", err)
+	}
+	return Event{Type: eventType, Data: b}, nil
+}
+
+// Option optionally configures a Broker.
+type Option func(opts *options)
+
+// WithReplaySize sets how many of the latest events of each topic are kept
+// to replay to reconnecting clients. Defaults to 100.
+func WithReplaySize(n int) Option {
+	return func(opts *options) {
+		opts.replaySize = n
+	}
+}
+
+// WithBufferSize sets how many events are buffered for each subscriber.
+// Subscribers that fall further behind are dropped, leaving clients to
+// reconnect and replay the events they missed. Defaults to 32.
+func WithBufferSize(n int) Option {
+	return func(opts *options) {
+		opts.bufferSize = n
+	}
+}
+
+// WithHeartbeatInterval sets how often Handler sends a comment to keep idle
+// connections open through proxies. Defaults to 15s.
+func WithHeartbeatInterval(d time.Duration) Option {
+	return func(opts *options) {
+		opts.heartbeatInterval = d
+	}
+}
+
+// WithClock sets the clock used for heartbeats. Defaults to clock.Real.
+func WithClock(clk clock.Clock) Option {
+	return func(opts *options) {
+		opts.clock = clk
+	}
+}
+
+// WithLogger sets the Logger used to log dropped subscribers. Defaults to
+// log.NewLogger.
+func WithLogger(logger log.Logger) Option {
+	return func(opts *options) {
+		opts.logger = logger
+	}
+}
+
+type options struct {
+	replaySize        int
+	bufferSize        int
+	heartbeatInterval time.Duration
+	clock             clock.Clock
+	logger            log.Logger
+}
+
+// Broker fans out events published to a topic to the subscribers of the
+// topic. It is safe for concurrent use.
+type Broker struct {
+	opts   options
+	mu     sync.Mutex
+	seq    uint64
+	topics map[string]*topic
+	closed bool
+}
+
+type topic struct {
+	subs    map[*Subscription]struct{}
+	history ring
+}
+
+// NewBroker creates a new Broker.
+func NewBroker(opts ...Option) *Broker {
+	options := options{
+		replaySize:        defaultReplaySize,
+		bufferSize:        defaultBufferSize,
+		heartbeatInterval: defaultHeartbeatInterval,
+		clock:             clock.Real,
+		logger:            log.NewLogger(),
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &Broker{
+		opts:   options,
+		topics: map[string]*topic{},
+	}
+}
+
+// Publish publishes event to the subscribers of topicName and keeps it for
+// replay. The ID of the event is set by the Broker.
+func (b *Broker) Publish(topicName string, event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+
+	b.seq++
+	event.ID = strconv.FormatUint(b.seq, 10)
+	t := b.topicLocked(topicName)
+	t.history.push(event, b.opts.replaySize)
+
+	for sub := range t.subs {
+		select {
+		case sub.events <- event:
+		default:
+			b.opts.logger.Warn("dropping slow sse subscriber", "topic", topicName)
+			b.removeLocked(sub)
+		}
+	}
+}
+
+// Subscribe subscribes to the events published to topicName. If lastEventID
+// is the ID of an event still kept for replay, the events published after it
+// are delivered first.
+func (b *Broker) Subscribe(topicName string, lastEventID string) (*Subscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil, ErrBrokerClosed
+	}
+
+	t := b.topicLocked(topicName)
+	var replay []Event
+	if lastEventID != "" {
+		if last, err := strconv.ParseUint(lastEventID, 10, 64); err == nil {
+			replay = t.history.after(last)
+		}
+	}
+
+	sub := &Subscription{
+		broker: b,
+		topic:  topicName,
+		events: make(chan Event, b.opts.bufferSize+len(replay)),
+		done:   make(chan struct{}),
+	}
+	for _, event := range replay {
+		sub.events <- event
+	}
+	t.subs[sub] = struct{}{}
+	return sub, nil
+}
+
+// Close closes the Broker and all of its subscriptions.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for _, t := range b.topics {
+		for sub := range t.subs {
+			b.removeLocked(sub)
+		}
+	}
+}
+
+func (b *Broker) topicLocked(name string) *topic {
+	t, ok := b.topics[name]
+	if !ok {
+		t = &topic{subs: map[*Subscription]struct{}{}}
+		b.topics[name] = t
+	}
+	return t
+}
+
+func (b *Broker) removeLocked(sub *Subscription) {
+	t, ok := b.topics[sub.topic]
+	if !ok {
+		return
+	}
+	if _, ok = t.subs[sub]; !ok {
+		return
+	}
+	delete(t.subs, sub)
+	close(sub.done)
+	if len(t.subs) == 0 && t.history.len() == 0 {
+		delete(b.topics, sub.topic)
+	}
+}
+
+// Subscription is a subscription to the events of a topic.
+type Subscription struct {
+	broker *Broker
+	topic  string
+	events chan Event
+	done   chan struct{}
+}
+
+// Events returns the events published to the topic.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Done is closed once the subscription is closed, dropped for falling
+// behind or the Broker is closed.
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+// Close closes the subscription.
+func (s *Subscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	s.broker.removeLocked(s)
+}
+
+// ring keeps the latest events of a topic.
+type ring struct {
+	events []Event
+	start  int
+}
+
+func (r *ring) len() int {
+	return len(r.events)
+}
+
+func (r *ring) push(event Event, size int) {
+	if size <= 0 {
+		return
+	}
+	if len(r.events) < size {
+		r.events = append(r.events, event)
+		return
+	}
+	r.events[r.start] = event
+	r.start = (r.start + 1) % len(r.events)
+}
+
+// after returns the events with a sequence greater than seq, oldest first.
+func (r *ring) after(seq uint64) []Event {
+	var events []Event
+	for i := range r.events {
+		event := r.events[(r.start+i)%len(r.events)]
+		if id, _ := strconv.ParseUint(event.ID, 10, 64); id > seq {
+			events = append(events, event)
+		}
+	}
+	return events
+}
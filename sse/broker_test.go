@@ -0,0 +1,94 @@
+package sse
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/log"
+	"github.com/joshjon/kit/testutil"
+)
+
+func newTestBroker(opts ...Option) *Broker {
+	return NewBroker(append([]Option{WithLogger(log.NewLogger(log.WithNop()))}, opts...)...)
+}
+
+func TestBroker_PublishSubscribe(t *testing.T) {
+	broker := newTestBroker()
+	defer broker.Close()
+
+	orders, err := broker.Subscribe("orders", "")
+	require.NoError(t, err)
+	users, err := broker.Subscribe("users", "")
+	require.NoError(t, err)
+
+	broker.Publish("orders", Event{Type: "created", Data: []byte("1")})
+
+	got := testutil.AssertReceiveChan(t, orders.Events(), time.Second)
+	assert.Equal(t, Event{ID: "1", Type: "created", Data: []byte("1")}, got)
+	assert.Empty(t, users.Events())
+}
+
+func TestBroker_Subscribe_replay(t *testing.T) {
+	broker := newTestBroker(WithReplaySize(2))
+	defer broker.Close()
+
+	for _, data := range []string{"a", "b", "c"} {
+		broker.Publish("orders", Event{Data: []byte(data)})
+	}
+	broker.Publish("users", Event{Data: []byte("d")})
+
+	tests := []struct {
+		name        string
+		lastEventID string
+		want        []string
+	}{
+		{name: "no last event id", lastEventID: "", want: nil},
+		{name: "replays after last event id", lastEventID: "2", want: []string{"c"}},
+		{name: "replays kept events when last event evicted", lastEventID: "1", want: []string{"b", "c"}},
+		{name: "latest event", lastEventID: "3", want: nil},
+		{name: "invalid last event id", lastEventID: "invalid", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, err := broker.Subscribe("orders", tt.lastEventID)
+			require.NoError(t, err)
+			defer sub.Close()
+
+			var got []string
+			for range len(sub.Events()) {
+				got = append(got, string((<-sub.Events()).Data))
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBroker_dropsSlowSubscriber(t *testing.T) {
+	broker := newTestBroker(WithBufferSize(1))
+	defer broker.Close()
+
+	sub, err := broker.Subscribe("orders", "")
+	require.NoError(t, err)
+
+	broker.Publish("orders", Event{Data: []byte("a")})
+	broker.Publish("orders", Event{Data: []byte("b")})
+
+	testutil.AssertReceiveChan(t, sub.Done(), time.Second)
+	assert.Equal(t, "a", string((<-sub.Events()).Data))
+}
+
+func TestBroker_Close(t *testing.T) {
+	broker := newTestBroker()
+	sub, err := broker.Subscribe("orders", "")
+	require.NoError(t, err)
+
+	broker.Close()
+	testutil.AssertReceiveChan(t, sub.Done(), time.Second)
+	sub.Close() // no-op
+
+	_, err = broker.Subscribe("orders", "")
+	assert.ErrorIs(t, err, ErrBrokerClosed)
+}
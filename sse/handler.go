@@ -0,0 +1,130 @@
+package sse
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	headerLastEventID = "Last-Event-ID"
+	queryLastEventID  = "lastEventId"
+)
+
+var errFlushNotSupported = errors.New("sse: response does not support flushing, exempt the route from the request timeout with server.WithRequestTimeout")
+
+// TopicFunc returns the topic a request subscribes to, e.g. from a path
+// param. Returning an error fails the request.
+type TopicFunc func(c echo.Context) (string, error)
+
+// Handler returns an echo.HandlerFunc streaming the events published to the
+// topic returned by topic until the client disconnects or the subscription
+// ends. Clients resume from the Last-Event-ID header sent by EventSource when
+// reconnecting, or the lastEventId query param.
+//
+// The server request timeout buffers responses until the handler returns, so
+// routes serving the handler must be exempt from it, see
+// server.WithRequestTimeout.
+//
+// Example:
+//
+//	broker := sse.NewBroker()
+//	srv.Add(http.MethodGet, "/events/:topic", sse.Handler(broker, func(c echo.Context) (string, error) {
+//		return c.Param("topic"), nil
+//	}))
+func Handler(broker *Broker, topic TopicFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if !canFlush(c.Response().Writer) {
+			return errFlushNotSupported
+		}
+
+		topicName, err := topic(c)
+		if err != nil {
+			return err
+		}
+
+		lastEventID := c.Request().Header.Get(headerLastEventID)
+		if lastEventID == "" {
+			lastEventID = c.QueryParam(queryLastEventID)
+		}
+		sub, err := broker.Subscribe(topicName, lastEventID)
+		if err != nil {
+			return err
+		}
+		defer sub.Close()
+
+		res := c.Response()
+		res.Header().Set(echo.HeaderContentType, "text/event-stream")
+		res.Header().Set(echo.HeaderCacheControl, "no-cache")
+		res.Header().Set(echo.HeaderConnection, "keep-alive")
+		res.Header().Set("X-Accel-Buffering", "no") // disable proxy buffering, e.g. nginx
+		res.WriteHeader(http.StatusOK)
+		res.Flush()
+
+		heartbeat := broker.opts.clock.NewTicker(broker.opts.heartbeatInterval)
+		defer heartbeat.Stop()
+
+		ctx := c.Request().Context()
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-sub.Done():
+				return nil
+			case event := <-sub.Events():
+				if err = writeEvent(res, event); err != nil {
+					return nil // client disconnected
+				}
+			case <-heartbeat.C():
+				if _, err = io.WriteString(res, ": heartbeat\n\n"); err != nil {
+					return nil
+				}
+			}
+			res.Flush()
+		}
+	}
+}
+
+// writeEvent writes event in the text/event-stream format.
+func writeEvent(w io.Writer, event Event) error {
+	var buf bytes.Buffer
+	if event.ID != "" {
+		fmt.Fprintf(&buf, "id: %s\n", stripNewlines(event.ID))
+	}
+	if event.Type != "" {
+		fmt.Fprintf(&buf, "event: %s\n", stripNewlines(event.Type))
+	}
+	if event.Retry > 0 {
+		fmt.Fprintf(&buf, "retry: %d\n", event.Retry.Milliseconds())
+	}
+	for line := range strings.SplitSeq(string(event.Data), "\n") {
+		fmt.Fprintf(&buf, "data: %s\n", strings.TrimSuffix(line, "\r"))
+	}
+	buf.WriteByte('\n')
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func stripNewlines(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
+
+// canFlush reports whether w, or a writer it wraps, can flush, which the
+// writer of the server request timeout can't.
+func canFlush(w http.ResponseWriter) bool {
+	for {
+		switch t := w.(type) {
+		case http.Flusher:
+			return true
+		case interface{ Unwrap() http.ResponseWriter }:
+			w = t.Unwrap()
+		default:
+			return false
+		}
+	}
+}
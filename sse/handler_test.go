@@ -0,0 +1,96 @@
+package sse
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/testutil"
+)
+
+func startHandler(t *testing.T, broker *Broker, middlewares ...echo.MiddlewareFunc) string {
+	e := echo.New()
+	e.Use(middlewares...)
+	e.GET("/events/:topic", Handler(broker, func(c echo.Context) (string, error) {
+		return c.Param("topic"), nil
+	}))
+	srv := httptest.NewServer(e)
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+// readEvent reads the lines of the next event or comment from r.
+func readEvent(t *testing.T, r *bufio.Reader) []string {
+	var lines []string
+	for {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return lines
+		}
+		lines = append(lines, line)
+	}
+}
+
+func openStream(t *testing.T, ctx context.Context, url string, lastEventID string) *bufio.Reader {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	require.NoError(t, err)
+	req.Header.Set(echo.HeaderAccept, "text/event-stream")
+	if lastEventID != "" {
+		req.Header.Set(headerLastEventID, lastEventID)
+	}
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { res.Body.Close() })
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "text/event-stream", res.Header.Get(echo.HeaderContentType))
+	return bufio.NewReader(res.Body)
+}
+
+func TestHandler(t *testing.T) {
+	clk := testutil.NewFakeClock(time.Now())
+	broker := newTestBroker(WithClock(clk), WithHeartbeatInterval(time.Second))
+	defer broker.Close()
+	url := startHandler(t, broker)
+
+	broker.Publish("orders", Event{Type: "created", Data: []byte("a\nb")})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream := openStream(t, ctx, url+"/events/orders", "")
+
+	broker.Publish("orders", Event{Type: "updated", Data: []byte("c"), Retry: time.Second})
+	assert.Equal(t, []string{"id: 2", "event: updated", "retry: 1000", "data: c"}, readEvent(t, stream))
+
+	require.Eventually(t, func() bool { return clk.Waiters() > 0 }, time.Second, time.Millisecond)
+	clk.Advance(time.Second)
+	assert.Equal(t, []string{": heartbeat"}, readEvent(t, stream))
+
+	// Reconnecting replays the missed events.
+	stream = openStream(t, ctx, url+"/events/orders", "0")
+	assert.Equal(t, []string{"id: 1", "event: created", "data: a", "data: b"}, readEvent(t, stream))
+	assert.Equal(t, []string{"id: 2", "event: updated", "retry: 1000", "data: c"}, readEvent(t, stream))
+}
+
+func TestHandler_requestTimeout(t *testing.T) {
+	broker := newTestBroker()
+	defer broker.Close()
+	url := startHandler(t, broker, middleware.TimeoutWithConfig(middleware.TimeoutConfig{
+		Timeout: time.Minute,
+	}))
+
+	res, err := http.Get(url + "/events/orders")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusInternalServerError, res.StatusCode)
+}
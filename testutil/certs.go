@@ -0,0 +1,183 @@
+package testutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const defaultCertValidity = 24 * time.Hour
+
+// Cert is an ephemeral certificate and its private key.
+type Cert struct {
+	Cert    *x509.Certificate
+	Key     *ecdsa.PrivateKey
+	CertPEM []byte
+	KeyPEM  []byte
+}
+
+// TLSCertificate returns the certificate as a tls.Certificate, e.g. for
+// tls.Config.Certificates.
+func (c *Cert) TLSCertificate(t *testing.T) tls.Certificate {
+	t.Helper()
+	cert, err := tls.X509KeyPair(c.CertPEM, c.KeyPEM)
+	require.NoError(t, err)
+	return cert
+}
+
+// CertPool returns a pool containing the certificate, e.g. a CA for
+// tls.Config.RootCAs or tls.Config.ClientCAs.
+func (c *Cert) CertPool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(c.Cert)
+	return pool
+}
+
+// WriteFiles writes the PEM encoded certificate and key to a temporary
+// directory removed when the test finishes, returning their paths.
+func (c *Cert) WriteFiles(t *testing.T) (certFile string, keyFile string) {
+	t.Helper()
+	dir := t.TempDir()
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, c.CertPEM, 0o600))
+	require.NoError(t, os.WriteFile(keyFile, c.KeyPEM, 0o600))
+	return certFile, keyFile
+}
+
+type CertOption func(opts *certOptions)
+
+// WithCommonName sets the subject common name of the certificate. Defaults to
+// localhost.
+func WithCommonName(name string) CertOption {
+	return func(opts *certOptions) {
+		opts.commonName = name
+	}
+}
+
+// WithHosts sets the DNS names and IP addresses the certificate is valid for.
+// Defaults to localhost, 127.0.0.1 and ::1.
+func WithHosts(hosts ...string) CertOption {
+	return func(opts *certOptions) {
+		opts.hosts = hosts
+	}
+}
+
+// WithClientAuth generates a client certificate rather than a server
+// certificate.
+func WithClientAuth() CertOption {
+	return func(opts *certOptions) {
+		opts.extKeyUsage = x509.ExtKeyUsageClientAuth
+	}
+}
+
+// WithValidity sets how long the certificate is valid for. Defaults to 24h. A
+// negative validity generates an expired certificate.
+func WithValidity(validity time.Duration) CertOption {
+	return func(opts *certOptions) {
+		opts.validity = validity
+	}
+}
+
+type certOptions struct {
+	commonName  string
+	hosts       []string
+	extKeyUsage x509.ExtKeyUsage
+	validity    time.Duration
+}
+
+// GenerateCA generates an ephemeral self-signed CA certificate for signing
+// certificates with GenerateCert.
+func GenerateCA(t *testing.T) *Cert {
+	t.Helper()
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serialNumber(t),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             now.Add(-time.Minute),
+		NotAfter:              now.Add(defaultCertValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	return generateCert(t, template, nil)
+}
+
+// GenerateCert generates an ephemeral server certificate signed by ca, or a
+// client certificate with WithClientAuth.
+func GenerateCert(t *testing.T, ca *Cert, opts ...CertOption) *Cert {
+	t.Helper()
+	options := certOptions{
+		commonName:  "localhost",
+		hosts:       []string{"localhost", "127.0.0.1", "::1"},
+		extKeyUsage: x509.ExtKeyUsageServerAuth,
+		validity:    defaultCertValidity,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serialNumber(t),
+		Subject:      pkix.Name{CommonName: options.commonName},
+		NotBefore:    now.Add(-time.Minute),
+		NotAfter:     now.Add(options.validity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{options.extKeyUsage},
+	}
+	if options.validity < 0 {
+		template.NotBefore = template.NotAfter.Add(-time.Hour)
+	}
+	for _, host := range options.hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+	return generateCert(t, template, ca)
+}
+
+func generateCert(t *testing.T, template *x509.Certificate, parent *Cert) *Cert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	parentCert, parentKey := template, key
+	if parent != nil {
+		parentCert, parentKey = parent.Cert, parent.Key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parentCert, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return &Cert{
+		Cert:    cert,
+		Key:     key,
+		CertPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		KeyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func serialNumber(t *testing.T) *big.Int {
+	t.Helper()
+	n, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	require.NoError(t, err)
+	return n
+}
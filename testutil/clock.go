@@ -0,0 +1,168 @@
+package testutil
+
+import (
+	"sync"
+	"time"
+
+	"github.com/joshjon/kit/clock"
+)
+
+// FakeClock is a clock.Clock whose time only changes when advanced with
+// Advance or Set, firing due timers and tickers. Sleep blocks until the clock
+// is advanced past the sleep duration.
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*fakeWaiter
+}
+
+var _ clock.Clock = (*FakeClock)(nil)
+
+// NewFakeClock creates a FakeClock set to now.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *FakeClock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+func (c *FakeClock) Sleep(d time.Duration) {
+	<-c.NewTimer(d).C()
+}
+
+func (c *FakeClock) NewTimer(d time.Duration) clock.Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	w := &fakeWaiter{clock: c, c: make(chan time.Time, 1)}
+	c.scheduleLocked(w, d)
+	return &fakeTimer{w}
+}
+
+func (c *FakeClock) NewTicker(d time.Duration) clock.Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	w := &fakeWaiter{clock: c, c: make(chan time.Time, 1), period: d}
+	c.scheduleLocked(w, d)
+	return &fakeTicker{w}
+}
+
+// Advance moves the clock forward by d, firing timers and tickers due by then.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
+
+// Set sets the clock to now, firing timers and tickers due by then.
+func (c *FakeClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+	for {
+		w := c.nextDueLocked()
+		if w == nil {
+			return
+		}
+		select {
+		case w.c <- w.deadline:
+		default: // drop ticks of slow receivers, like time.Ticker
+		}
+		if w.period > 0 {
+			w.deadline = w.deadline.Add(w.period)
+		} else {
+			c.removeLocked(w)
+		}
+	}
+}
+
+// Waiters returns the number of pending timers and tickers, including
+// sleeps, e.g. to wait until a goroutine is blocked before advancing.
+func (c *FakeClock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
+
+func (c *FakeClock) nextDueLocked() *fakeWaiter {
+	var next *fakeWaiter
+	for _, w := range c.waiters {
+		if !w.deadline.After(c.now) && (next == nil || w.deadline.Before(next.deadline)) {
+			next = w
+		}
+	}
+	return next
+}
+
+func (c *FakeClock) scheduleLocked(w *fakeWaiter, d time.Duration) {
+	w.deadline = c.now.Add(d)
+	if d <= 0 && w.period == 0 {
+		select {
+		case w.c <- c.now:
+		default:
+		}
+		return
+	}
+	c.waiters = append(c.waiters, w)
+}
+
+func (c *FakeClock) removeLocked(w *fakeWaiter) bool {
+	for i, cw := range c.waiters {
+		if cw == w {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+type fakeWaiter struct {
+	clock    *FakeClock
+	c        chan time.Time
+	deadline time.Time
+	period   time.Duration // zero for timers
+}
+
+func (w *fakeWaiter) stop() bool {
+	w.clock.mu.Lock()
+	defer w.clock.mu.Unlock()
+	return w.clock.removeLocked(w)
+}
+
+func (w *fakeWaiter) reset(d time.Duration, period time.Duration) bool {
+	w.clock.mu.Lock()
+	defer w.clock.mu.Unlock()
+	active := w.clock.removeLocked(w)
+	w.period = period
+	w.clock.scheduleLocked(w, d)
+	return active
+}
+
+type fakeTimer struct {
+	*fakeWaiter
+}
+
+func (t *fakeTimer) C() <-chan time.Time        { return t.c }
+func (t *fakeTimer) Stop() bool                 { return t.stop() }
+func (t *fakeTimer) Reset(d time.Duration) bool { return t.reset(d, 0) }
+
+type fakeTicker struct {
+	*fakeWaiter
+}
+
+func (t *fakeTicker) C() <-chan time.Time { return t.c }
+func (t *fakeTicker) Stop()               { t.stop() }
+
+func (t *fakeTicker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("non-positive interval for Ticker.Reset")
+	}
+	t.reset(d, d)
+}
@@ -0,0 +1,129 @@
+package testutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testClockStart = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestFakeClock_Now(t *testing.T) {
+	clk := NewFakeClock(testClockStart)
+	assert.Equal(t, testClockStart, clk.Now())
+
+	clk.Advance(time.Minute)
+	assert.Equal(t, testClockStart.Add(time.Minute), clk.Now())
+	assert.Equal(t, time.Minute, clk.Since(testClockStart))
+
+	clk.Set(testClockStart)
+	assert.Equal(t, testClockStart, clk.Now())
+}
+
+func TestFakeClock_NewTimer(t *testing.T) {
+	clk := NewFakeClock(testClockStart)
+	timer := clk.NewTimer(time.Minute)
+	assert.Equal(t, 1, clk.Waiters())
+
+	clk.Advance(59 * time.Second)
+	assertNotFired(t, timer.C())
+
+	clk.Advance(time.Second)
+	assertFired(t, timer.C(), testClockStart.Add(time.Minute))
+	assert.Zero(t, clk.Waiters())
+	assert.False(t, timer.Stop(), "stopped a fired timer")
+
+	t.Log("stopped")
+	timer = clk.NewTimer(time.Minute)
+	assert.True(t, timer.Stop())
+	clk.Advance(time.Minute)
+	assertNotFired(t, timer.C())
+
+	t.Log("reset")
+	timer = clk.NewTimer(time.Minute)
+	assert.True(t, timer.Reset(2*time.Minute))
+	clk.Advance(time.Minute)
+	assertNotFired(t, timer.C())
+	clk.Advance(time.Minute)
+	assertFired(t, timer.C(), clk.Now())
+
+	t.Log("non-positive duration")
+	timer = clk.NewTimer(0)
+	assertFired(t, timer.C(), clk.Now())
+	assert.Zero(t, clk.Waiters())
+}
+
+func TestFakeClock_NewTicker(t *testing.T) {
+	clk := NewFakeClock(testClockStart)
+	ticker := clk.NewTicker(time.Minute)
+
+	clk.Advance(time.Minute)
+	assertFired(t, ticker.C(), testClockStart.Add(time.Minute))
+	clk.Advance(time.Minute)
+	assertFired(t, ticker.C(), testClockStart.Add(2*time.Minute))
+
+	// ticks of slow receivers are dropped
+	clk.Advance(3 * time.Minute)
+	assertFired(t, ticker.C(), testClockStart.Add(3*time.Minute))
+	assertNotFired(t, ticker.C())
+
+	ticker.Reset(time.Hour)
+	clk.Advance(time.Minute)
+	assertNotFired(t, ticker.C())
+	clk.Advance(59 * time.Minute)
+	assertFired(t, ticker.C(), clk.Now())
+
+	ticker.Stop()
+	assert.Zero(t, clk.Waiters())
+	clk.Advance(time.Hour)
+	assertNotFired(t, ticker.C())
+
+	assert.Panics(t, func() { clk.NewTicker(0) })
+	assert.Panics(t, func() { ticker.Reset(0) })
+}
+
+func TestFakeClock_Sleep(t *testing.T) {
+	clk := NewFakeClock(testClockStart)
+	done := make(chan struct{})
+	go func() {
+		clk.Sleep(time.Minute)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool {
+		return clk.Waiters() == 1
+	}, time.Second, time.Millisecond)
+	select {
+	case <-done:
+		t.Fatal("sleep returned before the clock was advanced")
+	default:
+	}
+
+	clk.Advance(time.Minute)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("sleep did not return after the clock was advanced")
+	}
+}
+
+func assertFired(t *testing.T, c <-chan time.Time, want time.Time) {
+	t.Helper()
+	select {
+	case got := <-c:
+		assert.Equal(t, want, got)
+	default:
+		t.Fatal("expected timer to have fired")
+	}
+}
+
+func assertNotFired(t *testing.T, c <-chan time.Time) {
+	t.Helper()
+	select {
+	case got := <-c:
+		t.Fatalf("unexpected timer fire at %s", got)
+	default:
+	}
+}
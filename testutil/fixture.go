@@ -0,0 +1,96 @@
+package testutil
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+var fixtureVarPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+type FixtureOption func(opts *fixtureOptions)
+
+// WithFixtureVars sets variables substituted into fixtures, taking precedence
+// over environment variables of the same name.
+func WithFixtureVars(vars map[string]string) FixtureOption {
+	return func(opts *fixtureOptions) {
+		for k, v := range vars {
+			opts.vars[k] = v
+		}
+	}
+}
+
+type fixtureOptions struct {
+	vars map[string]string
+}
+
+// LoadFixture decodes the JSON or YAML fixture file at path (relative to the
+// package directory of the test) into a T, failing the test on error. The
+// format is detected by the .json, .yaml or .yml file extension.
+//
+// ${NAME} references are substituted with variables set by WithFixtureVars or
+// else environment variables, and unresolved references fail the test. YAML
+// fixtures are decoded via JSON, so the json struct tags of T apply to both
+// formats.
+func LoadFixture[T any](t *testing.T, path string, opts ...FixtureOption) T {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err, "read fixture")
+	return decodeFixture[T](t, path, data, opts)
+}
+
+// LoadFixtureFS is like LoadFixture but reads the fixture from fsys, e.g. an
+// embed.FS of testdata.
+func LoadFixtureFS[T any](t *testing.T, fsys fs.FS, path string, opts ...FixtureOption) T {
+	t.Helper()
+	data, err := fs.ReadFile(fsys, path)
+	require.NoError(t, err, "read fixture")
+	return decodeFixture[T](t, path, data, opts)
+}
+
+func decodeFixture[T any](t *testing.T, path string, data []byte, opts []FixtureOption) T {
+	t.Helper()
+	options := fixtureOptions{
+		vars: map[string]string{},
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	var unresolved []string
+	data = []byte(fixtureVarPattern.ReplaceAllStringFunc(string(data), func(ref string) string {
+		name := fixtureVarPattern.FindStringSubmatch(ref)[1]
+		if v, ok := options.vars[name]; ok {
+			return v
+		}
+		if v, ok := os.LookupEnv(name); ok {
+			return v
+		}
+		unresolved = append(unresolved, name)
+		return ref
+	}))
+	require.Empty(t, unresolved, "fixture %s: unresolved variables", path)
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+	case ".yaml", ".yml":
+		var v any
+		require.NoError(t, yaml.Unmarshal(data, &v), "decode fixture %s", path)
+		var err error
+		data, err = json.Marshal(v)
+		require.NoError(t, err, "decode fixture %s", path)
+	default:
+		require.FailNow(t, "unsupported fixture format", "fixture %s: extension %q", path, ext)
+	}
+
+	var out T
+	require.NoError(t, json.Unmarshal(data, &out), "decode fixture %s", path)
+	return out
+}
@@ -0,0 +1,37 @@
+package testutil
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testFixture struct {
+	Name string   `json:"name"`
+	URL  string   `json:"url"`
+	Tags []string `json:"tags"`
+}
+
+func TestLoadFixture(t *testing.T) {
+	t.Setenv("BASE_URL", "http://env.example.com")
+	want := testFixture{Name: "item", URL: "http://env.example.com/items", Tags: []string{"a", "b"}}
+
+	for _, path := range []string{"testdata/fixture.json", "testdata/fixture.yaml"} {
+		t.Run(path, func(t *testing.T) {
+			assert.Equal(t, want, LoadFixture[testFixture](t, path))
+
+			// Fixture vars take precedence over environment variables.
+			got := LoadFixture[testFixture](t, path, WithFixtureVars(map[string]string{"BASE_URL": "http://vars.example.com"}))
+			assert.Equal(t, "http://vars.example.com/items", got.URL)
+		})
+	}
+}
+
+func TestLoadFixtureFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"items.yml": {Data: []byte("- name: ${NAME}\n- name: two\n")},
+	}
+	got := LoadFixtureFS[[]testFixture](t, fsys, "items.yml", WithFixtureVars(map[string]string{"NAME": "one"}))
+	assert.Equal(t, []testFixture{{Name: "one"}, {Name: "two"}}, got)
+}
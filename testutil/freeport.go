@@ -12,8 +12,9 @@ import (
 
 const LocalIP = "127.0.0.1"
 
-// GetFreePort returns a TCP port that is available to listen on, for the
-// local host 127.0.0.1.
+// GetFreePortErr returns a TCP port that is available to listen on, for the
+// local host 127.0.0.1. Unlike GetFreePort it does not require a test, so it
+// can be used by tooling such as local dev runners.
 //
 // Copied from:
 // https://github.com/temporalio/cli/blob/main/temporalcli/devserver/freeport.go
@@ -30,11 +31,13 @@ const LocalIP = "127.0.0.1"
 // as "address already in use". Windows default behavior is already appropriate
 // in this regard; on that platform, `SO_REUSEADDR` has a different meaning and
 // should not be set (setting it may have unpredictable consequences).
-func GetFreePort(t *testing.T) int {
+func GetFreePortErr() (port int, err error) {
 	l, err := net.Listen("tcp", LocalIP+":0")
-	require.NoError(t, err)
+	if err != nil {
+		return 0, err
+	}
 	defer l.Close()
-	port := l.Addr().(*net.TCPAddr).Port
+	port = l.Addr().(*net.TCPAddr).Port
 
 	// On Linux and some BSD variants, ephemeral ports are randomized, and may
 	// consequently repeat within a short time frame after the listening end
@@ -56,21 +59,51 @@ func GetFreePort(t *testing.T) int {
 		// isn't fully configured (e.g. doesn't have a loopback interface bound
 		// to ::1). For safety, rebuild address form the original host instead.
 		tcpAddr, err := net.ResolveTCPAddr("tcp", fmt.Sprintf("%s:%d", LocalIP, port))
-		require.NoError(t, err, "error resolving address")
+		if err != nil {
+			return 0, fmt.Errorf("resolve address: %w", err)
+		}
 		r, err := net.DialTCP("tcp", nil, tcpAddr)
-		require.NoError(t, err, "failed to dial tcp")
+		if err != nil {
+			return 0, fmt.Errorf("dial tcp: %w", err)
+		}
 		c, err := l.Accept()
-		require.NoError(t, err, "failed to accept connection")
+		if err != nil {
+			r.Close()
+			return 0, fmt.Errorf("accept connection: %w", err)
+		}
 		// Closing the socket from the server side
-		require.NoError(t, c.Close())
-		defer func() {
-			require.NoError(t, r.Close())
-		}()
+		if err = c.Close(); err != nil {
+			r.Close()
+			return 0, fmt.Errorf("close server side connection: %w", err)
+		}
+		if err = r.Close(); err != nil {
+			return 0, fmt.Errorf("close client side connection: %w", err)
+		}
 	}
 
+	return port, nil
+}
+
+// GetFreePort is like GetFreePortErr but fails the test on error.
+func GetFreePort(tb testing.TB) int {
+	tb.Helper()
+	port, err := GetFreePortErr()
+	require.NoError(tb, err, "get free port")
 	return port
 }
 
-func GetFreeHostPort(t *testing.T) string {
-	return LocalIP + ":" + strconv.Itoa(GetFreePort(t))
+// GetFreeHostPortErr returns a free 127.0.0.1:port address to listen on. See
+// GetFreePortErr.
+func GetFreeHostPortErr() (string, error) {
+	port, err := GetFreePortErr()
+	if err != nil {
+		return "", err
+	}
+	return LocalIP + ":" + strconv.Itoa(port), nil
+}
+
+// GetFreeHostPort is like GetFreeHostPortErr but fails the test on error.
+func GetFreeHostPort(tb testing.TB) string {
+	tb.Helper()
+	return LocalIP + ":" + strconv.Itoa(GetFreePort(tb))
 }
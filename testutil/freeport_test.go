@@ -0,0 +1,40 @@
+package testutil
+
+import (
+	"net"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetFreePortErr(t *testing.T) {
+	port, err := GetFreePortErr()
+	require.NoError(t, err)
+	assert.Positive(t, port)
+
+	l, err := net.Listen("tcp", net.JoinHostPort(LocalIP, strconv.Itoa(port)))
+	require.NoError(t, err)
+	require.NoError(t, l.Close())
+}
+
+func TestGetFreeHostPortErr(t *testing.T) {
+	addr, err := GetFreeHostPortErr()
+	require.NoError(t, err)
+	host, _, err := net.SplitHostPort(addr)
+	require.NoError(t, err)
+	assert.Equal(t, LocalIP, host)
+
+	l, err := net.Listen("tcp", addr)
+	require.NoError(t, err)
+	require.NoError(t, l.Close())
+}
+
+func TestGetFreeHostPort(t *testing.T) {
+	var tb testing.TB = t
+	addr := GetFreeHostPort(tb)
+	l, err := net.Listen("tcp", addr)
+	require.NoError(t, err)
+	require.NoError(t, l.Close())
+}
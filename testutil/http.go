@@ -1,15 +1,9 @@
 package testutil
 
 import (
-	"bytes"
-	"encoding/json"
-	"io"
 	"net/http"
 	"testing"
 	"time"
-
-	"github.com/labstack/echo/v4"
-	"github.com/stretchr/testify/require"
 )
 
 var DefaultClient = &http.Client{
@@ -19,85 +13,25 @@ var DefaultClient = &http.Client{
 
 func Get[R any](t *testing.T, url string) R {
 	t.Helper()
-	httpRes, err := DefaultClient.Get(url)
-	require.NoError(t, err)
-	defer httpRes.Body.Close()
-
-	handleHTTPErr(t, httpRes, http.MethodGet, url)
-
-	var res R
-	err = json.NewDecoder(httpRes.Body).Decode(&res)
-	require.NoError(t, err)
-	return res
+	return DoJSON[R](NewRequest(t, http.MethodGet, url))
 }
 
 func GetText(t *testing.T, url string) string {
 	t.Helper()
-	httpRes, err := DefaultClient.Get(url)
-	require.NoError(t, err)
-	defer httpRes.Body.Close()
-
-	handleHTTPErr(t, httpRes, http.MethodGet, url)
-
-	body, err := io.ReadAll(httpRes.Body)
-	require.NoError(t, err)
-	return string(body)
+	return string(NewRequest(t, http.MethodGet, url).Do().Body)
 }
 
 func Post[R any](t *testing.T, url string, req any) R {
 	t.Helper()
-	reqJSON, err := json.Marshal(req)
-	require.NoError(t, err)
-
-	httpRes, err := DefaultClient.Post(url, echo.MIMEApplicationJSON, bytes.NewReader(reqJSON))
-	require.NoError(t, err)
-	defer httpRes.Body.Close()
-
-	handleHTTPErr(t, httpRes, http.MethodPost, url)
-
-	var res R
-	err = json.NewDecoder(httpRes.Body).Decode(&res)
-	require.NoError(t, err)
-	return res
+	return DoJSON[R](NewRequest(t, http.MethodPost, url).JSON(req))
 }
 
 func Put[R any](t *testing.T, url string, req any) R {
 	t.Helper()
-	reqJSON, err := json.Marshal(req)
-	require.NoError(t, err)
-
-	putReq, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(reqJSON))
-	require.NoError(t, err)
-	putReq.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
-
-	httpRes, err := DefaultClient.Do(putReq)
-	require.NoError(t, err)
-	defer httpRes.Body.Close()
-
-	handleHTTPErr(t, httpRes, http.MethodPut, url)
-
-	var res R
-	err = json.NewDecoder(httpRes.Body).Decode(&res)
-	require.NoError(t, err)
-	return res
+	return DoJSON[R](NewRequest(t, http.MethodPut, url).JSON(req))
 }
 
 func Delete(t *testing.T, url string) {
 	t.Helper()
-	deleteReq, err := http.NewRequest(http.MethodDelete, url, nil)
-	require.NoError(t, err)
-
-	httpRes, err := DefaultClient.Do(deleteReq)
-	require.NoError(t, err)
-	defer httpRes.Body.Close()
-
-	handleHTTPErr(t, httpRes, http.MethodDelete, url)
-}
-
-func handleHTTPErr(t *testing.T, httpRes *http.Response, method string, url string) {
-	t.Helper()
-	if httpRes.StatusCode < 200 || httpRes.StatusCode >= 300 {
-		body, _ := io.ReadAll(httpRes.Body)
-		require.Failf(t, "http error", "%s %s\nStatus: %s\nBody: %s", method, url, httpRes.Status, string(body))
-	}
+	NewRequest(t, http.MethodDelete, url).Do()
 }
@@ -0,0 +1,199 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/joshjon/kit/log"
+)
+
+// LogRecord is a record logged by a RecordingLogger. Attrs are keyed by their
+// group qualified key (e.g. request.method).
+type LogRecord struct {
+	Level   slog.Level
+	Message string
+	Attrs   map[string]any
+}
+
+func (r LogRecord) String() string {
+	return fmt.Sprintf("%s %q %v", r.Level, r.Message, r.Attrs)
+}
+
+// RecordingLogger is a log.Logger storing records of all levels so tests can
+// assert logging behavior. Loggers created with With share the records of
+// their parent. It is safe for concurrent use.
+type RecordingLogger struct {
+	*slog.Logger
+	store *logStore
+}
+
+var _ log.Logger = (*RecordingLogger)(nil)
+
+// NewRecordingLogger creates a RecordingLogger.
+func NewRecordingLogger() *RecordingLogger {
+	store := &logStore{}
+	return &RecordingLogger{
+		Logger: slog.New(&recordingHandler{store: store}),
+		store:  store,
+	}
+}
+
+func (l *RecordingLogger) With(args ...any) log.Logger {
+	return &RecordingLogger{
+		Logger: l.Logger.With(args...),
+		store:  l.store,
+	}
+}
+
+// Records returns the records logged so far.
+func (l *RecordingLogger) Records() []LogRecord {
+	l.store.mu.Lock()
+	defer l.store.mu.Unlock()
+	return append([]LogRecord(nil), l.store.records...)
+}
+
+// Reset discards the records logged so far.
+func (l *RecordingLogger) Reset() {
+	l.store.mu.Lock()
+	defer l.store.mu.Unlock()
+	l.store.records = nil
+}
+
+// AssertLogged asserts that a record was logged at level with a message
+// containing msgContains and the attrs given as key-value pairs.
+func (l *RecordingLogger) AssertLogged(t *testing.T, level slog.Level, msgContains string, attrs ...any) bool {
+	t.Helper()
+	want := attrMap(attrs)
+	records := l.Records()
+	for _, r := range records {
+		if r.matches(level, msgContains, want) {
+			return true
+		}
+	}
+	return assert.Fail(t, "log record not found",
+		"level: %s\nmessage containing: %q\nattrs: %v\nrecords:\n%s", level, msgContains, want, formatRecords(records))
+}
+
+// AssertNotLogged asserts that no record was logged at level with a message
+// containing msgContains.
+func (l *RecordingLogger) AssertNotLogged(t *testing.T, level slog.Level, msgContains string) bool {
+	t.Helper()
+	for _, r := range l.Records() {
+		if r.matches(level, msgContains, nil) {
+			return assert.Fail(t, "unexpected log record", "record: %s", r)
+		}
+	}
+	return true
+}
+
+func (r LogRecord) matches(level slog.Level, msgContains string, attrs map[string]any) bool {
+	if r.Level != level || !strings.Contains(r.Message, msgContains) {
+		return false
+	}
+	for key, want := range attrs {
+		got, ok := r.Attrs[key]
+		if !ok || !assert.ObjectsAreEqualValues(want, got) {
+			return false
+		}
+	}
+	return true
+}
+
+func attrMap(args []any) map[string]any {
+	attrs := map[string]any{}
+	r := slog.NewRecord(time.Time{}, 0, "", 0)
+	r.Add(args...)
+	r.Attrs(func(a slog.Attr) bool {
+		addAttr(attrs, "", a)
+		return true
+	})
+	return attrs
+}
+
+func formatRecords(records []LogRecord) string {
+	var sb strings.Builder
+	for _, r := range records {
+		sb.WriteString("  ")
+		sb.WriteString(r.String())
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+type logStore struct {
+	mu      sync.Mutex
+	records []LogRecord
+}
+
+type recordingHandler struct {
+	store  *logStore
+	attrs  []slog.Attr
+	prefix string // group qualifier of attrs added after WithGroup
+}
+
+func (h *recordingHandler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+func (h *recordingHandler) Handle(_ context.Context, r slog.Record) error {
+	attrs := map[string]any{}
+	for _, a := range h.attrs {
+		addAttr(attrs, "", a)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		addAttr(attrs, h.prefix, a)
+		return true
+	})
+
+	h.store.mu.Lock()
+	defer h.store.mu.Unlock()
+	h.store.records = append(h.store.records, LogRecord{
+		Level:   r.Level,
+		Message: r.Message,
+		Attrs:   attrs,
+	})
+	return nil
+}
+
+func (h *recordingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	cp := *h
+	cp.attrs = make([]slog.Attr, 0, len(h.attrs)+len(attrs))
+	cp.attrs = append(cp.attrs, h.attrs...)
+	for _, a := range attrs {
+		if h.prefix != "" {
+			a.Key = h.prefix + a.Key
+		}
+		cp.attrs = append(cp.attrs, a)
+	}
+	return &cp
+}
+
+func (h *recordingHandler) WithGroup(name string) slog.Handler {
+	cp := *h
+	cp.prefix = h.prefix + name + "."
+	return &cp
+}
+
+func addAttr(attrs map[string]any, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			addAttr(attrs, prefix, ga)
+		}
+		return
+	}
+	if a.Key == "" {
+		return
+	}
+	attrs[prefix+a.Key] = a.Value.Any()
+}
@@ -0,0 +1,59 @@
+package testutil
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordingLogger(t *testing.T) {
+	l := NewRecordingLogger()
+	l.Info("request handled", "method", "GET", "status", 200)
+	l.With("component", "worker").Warn("job failed", "attempt", 2)
+	l.Debug("debug message")
+
+	records := l.Records()
+	require.Len(t, records, 3)
+	assert.Equal(t, LogRecord{
+		Level:   slog.LevelInfo,
+		Message: "request handled",
+		Attrs:   map[string]any{"method": "GET", "status": int64(200)},
+	}, records[0])
+	assert.Equal(t, map[string]any{"component": "worker", "attempt": int64(2)}, records[1].Attrs)
+
+	l.AssertLogged(t, slog.LevelInfo, "handled", "status", 200)
+	l.AssertLogged(t, slog.LevelWarn, "job failed", "component", "worker")
+	l.AssertLogged(t, slog.LevelDebug, "debug")
+	l.AssertNotLogged(t, slog.LevelError, "")
+
+	l.Reset()
+	assert.Empty(t, l.Records())
+}
+
+func TestRecordingLogger_groups(t *testing.T) {
+	l := NewRecordingLogger()
+	l.Logger.WithGroup("request").With("id", "req-1").Info("handled",
+		"method", "GET",
+		slog.Group("user", "id", "user-1"),
+	)
+
+	records := l.Records()
+	require.Len(t, records, 1)
+	assert.Equal(t, map[string]any{
+		"request.id":      "req-1",
+		"request.method":  "GET",
+		"request.user.id": "user-1",
+	}, records[0].Attrs)
+}
+
+func TestLogRecord_matches(t *testing.T) {
+	r := LogRecord{Level: slog.LevelInfo, Message: "request handled", Attrs: map[string]any{"status": int64(200)}}
+
+	assert.True(t, r.matches(slog.LevelInfo, "handled", attrMap([]any{"status", 200})))
+	assert.False(t, r.matches(slog.LevelWarn, "handled", nil))
+	assert.False(t, r.matches(slog.LevelInfo, "failed", nil))
+	assert.False(t, r.matches(slog.LevelInfo, "handled", attrMap([]any{"status", 500})))
+	assert.False(t, r.matches(slog.LevelInfo, "handled", attrMap([]any{"method", "GET"})))
+}
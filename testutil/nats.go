@@ -0,0 +1,35 @@
+package testutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/stretchr/testify/require"
+)
+
+const natsStartupTimeout = 10 * time.Second
+
+// StartNATS starts an embedded NATS server with JetStream enabled on a random
+// port and returns its client URL. The server is shut down when the test
+// finishes.
+func StartNATS(t testing.TB) string {
+	t.Helper()
+	srv, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      server.RANDOM_PORT,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	require.NoError(t, err)
+
+	go srv.Start()
+	t.Cleanup(func() {
+		srv.Shutdown()
+		srv.WaitForShutdown()
+	})
+	require.True(t, srv.ReadyForConnections(natsStartupTimeout), "nats server not ready")
+	return srv.ClientURL()
+}
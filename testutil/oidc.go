@@ -0,0 +1,123 @@
+package testutil
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/auth0/go-jwt-middleware/v2/validator"
+	"github.com/go-jose/go-jose/v4"
+	josejwt "github.com/go-jose/go-jose/v4/jwt"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/jwt"
+)
+
+const (
+	oidcKeyID      = "test-key"
+	oidcTokenTTL   = time.Hour
+	oidcDiscovery  = "/.well-known/openid-configuration"
+	oidcJWKSPath   = "/jwks"
+	oidcRSAKeySize = 2048
+)
+
+// OIDCServer is a fake OIDC issuer serving discovery and JWKS endpoints, and
+// minting RS256 access tokens accepted by jwt.ValidateMiddleware.
+type OIDCServer struct {
+	t      *testing.T
+	srv    *httptest.Server
+	key    *rsa.PrivateKey
+	signer jose.Signer
+}
+
+// StartOIDCServer starts an OIDCServer which is closed when the test
+// finishes.
+func StartOIDCServer(t *testing.T) *OIDCServer {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, oidcRSAKeySize)
+	require.NoError(t, err)
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.RS256, Key: key},
+		(&jose.SignerOptions{}).WithType("JWT").WithHeader(jose.HeaderKey("kid"), oidcKeyID),
+	)
+	require.NoError(t, err)
+
+	s := &OIDCServer{
+		t:      t,
+		key:    key,
+		signer: signer,
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+oidcDiscovery, s.handleDiscovery)
+	mux.HandleFunc("GET "+oidcJWKSPath, s.handleJWKS)
+	s.srv = httptest.NewServer(mux)
+	t.Cleanup(s.srv.Close)
+	return s
+}
+
+// Issuer returns the issuer URL of the server.
+func (s *OIDCServer) Issuer() string {
+	return s.srv.URL
+}
+
+// JWTConfig returns a jwt.Config validating tokens minted by the server for
+// audiences.
+func (s *OIDCServer) JWTConfig(audiences ...jwt.AudienceConfig) jwt.Config {
+	return jwt.Config{
+		IssuerURL:            s.Issuer(),
+		Audiences:            audiences,
+		SignatureAlgorithm:   validator.RS256,
+		CacheDurationSeconds: int(time.Minute.Seconds()),
+	}
+}
+
+// MintToken returns a signed access token for subject and audience, granting
+// scopes. Claims are added to the token, overriding the defaults (e.g. exp to
+// mint an expired token). jwt.ValidateMiddleware requires an email claim.
+func (s *OIDCServer) MintToken(subject string, audience string, scopes []string, claims map[string]any) string {
+	s.t.Helper()
+	now := time.Now()
+	all := map[string]any{
+		"iss":   s.Issuer(),
+		"sub":   subject,
+		"aud":   audience,
+		"iat":   now.Unix(),
+		"nbf":   now.Unix(),
+		"exp":   now.Add(oidcTokenTTL).Unix(),
+		"scope": strings.Join(scopes, " "),
+	}
+	maps.Copy(all, claims)
+
+	token, err := josejwt.Signed(s.signer).Claims(all).Serialize()
+	require.NoError(s.t, err)
+	return token
+}
+
+func (s *OIDCServer) handleDiscovery(w http.ResponseWriter, _ *http.Request) {
+	writeOIDCJSON(w, map[string]any{
+		"issuer":                                s.Issuer(),
+		"jwks_uri":                              s.Issuer() + oidcJWKSPath,
+		"id_token_signing_alg_values_supported": []string{string(jose.RS256)},
+	})
+}
+
+func (s *OIDCServer) handleJWKS(w http.ResponseWriter, _ *http.Request) {
+	writeOIDCJSON(w, jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{
+		Key:       s.key.Public(),
+		KeyID:     oidcKeyID,
+		Algorithm: string(jose.RS256),
+		Use:       "sig",
+	}}})
+}
+
+func writeOIDCJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,75 @@
+package testutil
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	josejwt "github.com/go-jose/go-jose/v4/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getOIDCJSON(t *testing.T, url string, v any) {
+	t.Helper()
+	res, err := http.Get(url)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.NoError(t, json.NewDecoder(res.Body).Decode(v))
+}
+
+func TestOIDCServer_MintToken(t *testing.T) {
+	s := StartOIDCServer(t)
+
+	var discovery struct {
+		Issuer  string `json:"issuer"`
+		JWKSURI string `json:"jwks_uri"`
+	}
+	getOIDCJSON(t, s.Issuer()+"/.well-known/openid-configuration", &discovery)
+	assert.Equal(t, s.Issuer(), discovery.Issuer)
+
+	var jwks jose.JSONWebKeySet
+	getOIDCJSON(t, discovery.JWKSURI, &jwks)
+
+	token := s.MintToken("user-1", "https://api.example.com", []string{"read", "write"}, map[string]any{
+		"email": "user@example.com",
+	})
+	parsed, err := josejwt.ParseSigned(token, []jose.SignatureAlgorithm{jose.RS256})
+	require.NoError(t, err)
+	require.Len(t, parsed.Headers, 1)
+	keys := jwks.Key(parsed.Headers[0].KeyID)
+	require.Len(t, keys, 1)
+
+	var claims josejwt.Claims
+	var custom struct {
+		Scope string `json:"scope"`
+		Email string `json:"email"`
+	}
+	require.NoError(t, parsed.Claims(keys[0].Key, &claims, &custom))
+	require.NoError(t, claims.Validate(josejwt.Expected{
+		Issuer:      discovery.Issuer,
+		Subject:     "user-1",
+		AnyAudience: josejwt.Audience{"https://api.example.com"},
+		Time:        time.Now(),
+	}))
+	assert.Equal(t, "read write", custom.Scope)
+	assert.Equal(t, "user@example.com", custom.Email)
+}
+
+func TestOIDCServer_MintToken_overrideClaims(t *testing.T) {
+	s := StartOIDCServer(t)
+
+	token := s.MintToken("user-1", "https://api.example.com", nil, map[string]any{
+		"exp": time.Now().Add(-time.Minute).Unix(),
+	})
+	parsed, err := josejwt.ParseSigned(token, []jose.SignatureAlgorithm{jose.RS256})
+	require.NoError(t, err)
+
+	var claims josejwt.Claims
+	require.NoError(t, parsed.Claims(s.key.Public(), &claims))
+	err = claims.Validate(josejwt.Expected{Issuer: s.Issuer(), Time: time.Now()})
+	assert.ErrorIs(t, err, josejwt.ErrExpired)
+}
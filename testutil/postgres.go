@@ -0,0 +1,92 @@
+package testutil
+
+import (
+	"io/fs"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+
+	"github.com/joshjon/kit/pgdb"
+)
+
+const (
+	defaultPostgresImage   = "postgres:17-alpine"
+	postgresUser           = "postgres"
+	postgresPassword       = "postgres"
+	postgresDatabase       = "test"
+	postgresStartupTimeout = time.Minute
+)
+
+type PostgresOption func(opts *postgresOptions)
+
+// WithPostgresImage sets the Postgres container image. Defaults to
+// postgres:17-alpine.
+func WithPostgresImage(image string) PostgresOption {
+	return func(opts *postgresOptions) {
+		opts.image = image
+	}
+}
+
+// WithMigrateOptions sets the options used to apply migrations, e.g.
+// pgdb.WithVersion to migrate to a specific version.
+func WithMigrateOptions(opts ...pgdb.MigrateOption) PostgresOption {
+	return func(o *postgresOptions) {
+		o.migrateOpts = append(o.migrateOpts, opts...)
+	}
+}
+
+type postgresOptions struct {
+	image       string
+	migrateOpts []pgdb.MigrateOption
+}
+
+// StartPostgres starts a disposable Postgres container, applies migrations
+// with pgdb.Migrate and returns a pool connected to it. Migrations are skipped
+// when nil. The pool is closed and the container removed when the test
+// finishes. Tests are skipped when Docker is not available.
+func StartPostgres(t *testing.T, migrations fs.FS, opts ...PostgresOption) *pgxpool.Pool {
+	t.Helper()
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+
+	options := postgresOptions{
+		image: defaultPostgresImage,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	ctx := Context(t, WithTimeout(postgresStartupTimeout))
+	container, err := testcontainers.Run(ctx, options.image,
+		testcontainers.WithEnv(map[string]string{
+			"POSTGRES_USER":     postgresUser,
+			"POSTGRES_PASSWORD": postgresPassword,
+			"POSTGRES_DB":       postgresDatabase,
+		}),
+		testcontainers.WithExposedPorts("5432/tcp"),
+		// Postgres restarts once after initializing the database.
+		testcontainers.WithWaitStrategy(
+			wait.ForLog("database system is ready to accept connections").WithOccurrence(2),
+		),
+	)
+	testcontainers.CleanupContainer(t, container)
+	require.NoError(t, err, "start postgres container")
+
+	host, err := container.Host(ctx)
+	require.NoError(t, err)
+	port, err := container.MappedPort(ctx, "5432/tcp")
+	require.NoError(t, err)
+
+	pool, err := pgdb.Dial(ctx, postgresUser, postgresPassword, net.JoinHostPort(host, port.Port()), postgresDatabase)
+	require.NoError(t, err, "dial postgres")
+	t.Cleanup(pool.Close)
+
+	if migrations != nil {
+		require.NoError(t, pgdb.Migrate(pool, migrations, options.migrateOpts...), "migrate postgres")
+	}
+	return pool
+}
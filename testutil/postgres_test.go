@@ -0,0 +1,31 @@
+package testutil
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartPostgres(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping postgres container in short mode")
+	}
+
+	migrations := fstest.MapFS{
+		"1_create_items.up.sql":   {Data: []byte(`CREATE TABLE items (id BIGINT PRIMARY KEY, name TEXT NOT NULL);`)},
+		"1_create_items.down.sql": {Data: []byte(`DROP TABLE items;`)},
+	}
+
+	// Skipped when Docker is not available.
+	pool := StartPostgres(t, migrations)
+	ctx := Context(t)
+
+	_, err := pool.Exec(ctx, `INSERT INTO items (id, name) VALUES (1, 'one')`)
+	require.NoError(t, err)
+
+	var name string
+	require.NoError(t, pool.QueryRow(ctx, `SELECT name FROM items WHERE id = 1`).Scan(&name))
+	assert.Equal(t, "one", name)
+}
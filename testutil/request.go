@@ -0,0 +1,167 @@
+package testutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+// RequestBuilder builds and sends an HTTP request in a test, failing the test
+// on errors. Requests fail the test on non-2xx responses unless an expected
+// status is set with ExpectStatus.
+type RequestBuilder struct {
+	t          *testing.T
+	method     string
+	url        string
+	header     http.Header
+	query      url.Values
+	cookies    []*http.Cookie
+	body       []byte
+	client     *http.Client
+	wantStatus int
+	wantError  bool
+}
+
+// NewRequest returns a RequestBuilder for a request sent with DefaultClient.
+func NewRequest(t *testing.T, method string, rawURL string) *RequestBuilder {
+	return &RequestBuilder{
+		t:      t,
+		method: method,
+		url:    rawURL,
+		header: http.Header{},
+		query:  url.Values{},
+		client: DefaultClient,
+	}
+}
+
+// Header sets a request header.
+func (b *RequestBuilder) Header(key string, value string) *RequestBuilder {
+	b.header.Set(key, value)
+	return b
+}
+
+// BearerToken sets the Authorization header to a bearer token.
+func (b *RequestBuilder) BearerToken(token string) *RequestBuilder {
+	return b.Header(echo.HeaderAuthorization, "Bearer "+token)
+}
+
+// Cookie adds a cookie to the request.
+func (b *RequestBuilder) Cookie(cookie *http.Cookie) *RequestBuilder {
+	b.cookies = append(b.cookies, cookie)
+	return b
+}
+
+// Query adds a query parameter to the request URL.
+func (b *RequestBuilder) Query(key string, value string) *RequestBuilder {
+	b.query.Add(key, value)
+	return b
+}
+
+// JSON sets the request body to v encoded as JSON.
+func (b *RequestBuilder) JSON(v any) *RequestBuilder {
+	b.t.Helper()
+	body, err := json.Marshal(v)
+	require.NoError(b.t, err)
+	b.body = body
+	return b.Header(echo.HeaderContentType, echo.MIMEApplicationJSON)
+}
+
+// Body sets the request body with its content type.
+func (b *RequestBuilder) Body(contentType string, body []byte) *RequestBuilder {
+	b.body = body
+	return b.Header(echo.HeaderContentType, contentType)
+}
+
+// Client sets the client used to send the request.
+func (b *RequestBuilder) Client(client *http.Client) *RequestBuilder {
+	b.client = client
+	return b
+}
+
+// ExpectStatus fails the test unless the response has status.
+func (b *RequestBuilder) ExpectStatus(status int) *RequestBuilder {
+	b.wantStatus = status
+	return b
+}
+
+// Do sends the request and returns the response with its body read.
+func (b *RequestBuilder) Do() *Response {
+	b.t.Helper()
+	reqURL, err := url.Parse(b.url)
+	require.NoError(b.t, err)
+	if len(b.query) > 0 {
+		q := reqURL.Query()
+		for key, values := range b.query {
+			q[key] = append(q[key], values...)
+		}
+		reqURL.RawQuery = q.Encode()
+	}
+
+	var body io.Reader
+	if b.body != nil {
+		body = bytes.NewReader(b.body)
+	}
+	req, err := http.NewRequest(b.method, reqURL.String(), body)
+	require.NoError(b.t, err)
+	req.Header = b.header.Clone()
+	for _, cookie := range b.cookies {
+		req.AddCookie(cookie)
+	}
+
+	httpRes, err := b.client.Do(req)
+	require.NoError(b.t, err)
+	defer httpRes.Body.Close()
+	resBody, err := io.ReadAll(httpRes.Body)
+	require.NoError(b.t, err)
+
+	success := httpRes.StatusCode >= 200 && httpRes.StatusCode < 300
+	switch {
+	case b.wantStatus != 0:
+		require.Equalf(b.t, b.wantStatus, httpRes.StatusCode, "%s %s\nBody: %s", b.method, reqURL, string(resBody))
+	case b.wantError && success:
+		require.Failf(b.t, "expected http error", "%s %s\nStatus: %s\nBody: %s", b.method, reqURL, httpRes.Status, string(resBody))
+	case !b.wantError && !success:
+		require.Failf(b.t, "http error", "%s %s\nStatus: %s\nBody: %s", b.method, reqURL, httpRes.Status, string(resBody))
+	}
+	return &Response{Response: httpRes, Body: resBody, t: b.t}
+}
+
+// Response is a response of a request sent with a RequestBuilder.
+type Response struct {
+	*http.Response
+	Body []byte
+	t    *testing.T
+}
+
+// DecodeJSON decodes the JSON response body into v.
+func (r *Response) DecodeJSON(v any) {
+	r.t.Helper()
+	require.NoError(r.t, json.Unmarshal(r.Body, v), "decode response body: %s", string(r.Body))
+}
+
+// DoJSON sends the request built by b and decodes the JSON response body into
+// R.
+func DoJSON[R any](b *RequestBuilder) R {
+	b.t.Helper()
+	var res R
+	b.Do().DecodeJSON(&res)
+	return res
+}
+
+// DoError sends the request built by b, which must fail, and decodes the JSON
+// error response body into E (e.g. server.ResponseError). Fails the test on
+// 2xx responses unless an expected status is set with ExpectStatus.
+func DoError[E any](b *RequestBuilder) E {
+	b.t.Helper()
+	b.wantError = true
+	res := b.Do()
+	var e E
+	res.DecodeJSON(&e)
+	return e
+}
@@ -0,0 +1,105 @@
+package testutil
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type echoedRequest struct {
+	Method  string              `json:"method"`
+	Path    string              `json:"path"`
+	Query   map[string][]string `json:"query"`
+	Header  map[string][]string `json:"header"`
+	Cookies map[string]string   `json:"cookies"`
+	Body    string              `json:"body"`
+}
+
+func startEchoServer(t *testing.T) string {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/error" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"message":"invalid request"}`))
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		cookies := map[string]string{}
+		for _, cookie := range r.Cookies() {
+			cookies[cookie.Name] = cookie.Value
+		}
+		_ = json.NewEncoder(w).Encode(echoedRequest{
+			Method:  r.Method,
+			Path:    r.URL.Path,
+			Query:   r.URL.Query(),
+			Header:  r.Header,
+			Cookies: cookies,
+			Body:    string(body),
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func TestRequestBuilder(t *testing.T) {
+	url := startEchoServer(t)
+
+	got := DoJSON[echoedRequest](NewRequest(t, http.MethodPost, url+"/items?page_size=10").
+		Header("X-Request-ID", "req-1").
+		BearerToken("token").
+		Cookie(&http.Cookie{Name: "session", Value: "abc"}).
+		Query("status", "active").
+		Query("status", "pending").
+		JSON(map[string]string{"name": "item"}))
+
+	assert.Equal(t, http.MethodPost, got.Method)
+	assert.Equal(t, "/items", got.Path)
+	assert.Equal(t, map[string][]string{
+		"page_size": {"10"},
+		"status":    {"active", "pending"},
+	}, got.Query)
+	assert.Equal(t, []string{"req-1"}, got.Header["X-Request-Id"])
+	assert.Equal(t, []string{"Bearer token"}, got.Header["Authorization"])
+	assert.Equal(t, []string{"application/json"}, got.Header["Content-Type"])
+	assert.Equal(t, map[string]string{"session": "abc"}, got.Cookies)
+	assert.JSONEq(t, `{"name":"item"}`, got.Body)
+}
+
+func TestRequestBuilder_Body(t *testing.T) {
+	url := startEchoServer(t)
+
+	got := DoJSON[echoedRequest](NewRequest(t, http.MethodPut, url+"/items/1").
+		Body("text/plain", []byte("hello")))
+	assert.Equal(t, http.MethodPut, got.Method)
+	assert.Equal(t, []string{"text/plain"}, got.Header["Content-Type"])
+	assert.Equal(t, "hello", got.Body)
+
+	got = DoJSON[echoedRequest](NewRequest(t, http.MethodGet, url+"/items"))
+	assert.Empty(t, got.Body)
+	assert.Empty(t, got.Cookies)
+	assert.NotContains(t, got.Header, "Content-Type")
+}
+
+func TestRequestBuilder_errors(t *testing.T) {
+	url := startEchoServer(t)
+
+	type responseError struct {
+		Message string `json:"message"`
+	}
+	resErr := DoError[responseError](NewRequest(t, http.MethodGet, url+"/error"))
+	assert.Equal(t, "invalid request", resErr.Message)
+
+	res := NewRequest(t, http.MethodGet, url+"/error").ExpectStatus(http.StatusBadRequest).Do()
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	assert.JSONEq(t, `{"message":"invalid request"}`, string(res.Body))
+
+	res = NewRequest(t, http.MethodGet, url+"/items").ExpectStatus(http.StatusOK).Do()
+	var got echoedRequest
+	res.DecodeJSON(&got)
+	require.Equal(t, "/items", got.Path)
+}
@@ -0,0 +1,253 @@
+package testutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// StubServer is an HTTP server responding to requests with responses scripted
+// per route, and recording the requests it receives so tests can assert what
+// was sent to it, e.g. as a downstream of a proxy. Requests not matching a
+// route fail the test and receive a 404 response.
+type StubServer struct {
+	t   *testing.T
+	srv *httptest.Server
+	mux *http.ServeMux
+
+	mu       sync.Mutex
+	requests []StubRequest
+}
+
+// StartStubServer starts a StubServer which is closed when the test finishes.
+func StartStubServer(t *testing.T) *StubServer {
+	t.Helper()
+	s := &StubServer{
+		t:   t,
+		mux: http.NewServeMux(),
+	}
+	s.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		s.record(r)
+		t.Errorf("stub server: unexpected request %s %s", r.Method, r.URL.Path)
+		http.NotFound(w, r)
+	})
+	s.srv = httptest.NewServer(s.mux)
+	t.Cleanup(s.srv.Close)
+	return s
+}
+
+// URL returns the base URL of the server.
+func (s *StubServer) URL() string {
+	return s.srv.URL
+}
+
+// Handle registers a route for method and path, which may contain wildcards as
+// supported by http.ServeMux (e.g. /items/{id} or /static/). The route
+// responds with 200 and no body until configured otherwise.
+func (s *StubServer) Handle(method string, path string) *StubRoute {
+	route := &StubRoute{
+		t:         s.t,
+		responses: []*stubResponse{newStubResponse()},
+	}
+	s.mux.HandleFunc(method+" "+path, func(w http.ResponseWriter, r *http.Request) {
+		req := s.record(r)
+		route.serve(w, r, req)
+	})
+	return route
+}
+
+// Requests returns all requests received so far, including those not matching
+// a route.
+func (s *StubServer) Requests() []StubRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]StubRequest(nil), s.requests...)
+}
+
+func (s *StubServer) record(r *http.Request) StubRequest {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.t.Errorf("stub server: read request body: %v", err)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	req := StubRequest{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.Query(),
+		Header: r.Header.Clone(),
+		Body:   body,
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, req)
+	return req
+}
+
+// StubRequest is a request received by a StubServer.
+type StubRequest struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Header http.Header
+	Body   []byte
+}
+
+// DecodeJSON decodes the request body into v, failing the test on error.
+func (r StubRequest) DecodeJSON(t *testing.T, v any) {
+	t.Helper()
+	require.NoError(t, json.Unmarshal(r.Body, v), "decode request body: %s", r.Body)
+}
+
+// StubRoute is a route of a StubServer. Responses are configured with Status,
+// Header, JSON, Body and Handler, and further responses can be scripted with
+// Then. Responses are used in order for consecutive requests, with the last
+// one repeated once all have been used.
+type StubRoute struct {
+	t *testing.T
+
+	mu        sync.Mutex
+	responses []*stubResponse
+	requests  []StubRequest
+}
+
+type stubResponse struct {
+	status  int
+	header  http.Header
+	body    []byte
+	handler http.HandlerFunc
+}
+
+func newStubResponse() *stubResponse {
+	return &stubResponse{
+		status: http.StatusOK,
+		header: http.Header{},
+	}
+}
+
+// Status sets the status code of the response. Defaults to 200.
+func (r *StubRoute) Status(code int) *StubRoute {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.last().status = code
+	return r
+}
+
+// Header sets a header of the response.
+func (r *StubRoute) Header(key string, value string) *StubRoute {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.last().header.Set(key, value)
+	return r
+}
+
+// JSON sets the body of the response to v encoded as JSON.
+func (r *StubRoute) JSON(v any) *StubRoute {
+	r.t.Helper()
+	body, err := json.Marshal(v)
+	require.NoError(r.t, err)
+	return r.Body(echo.MIMEApplicationJSON, body)
+}
+
+// Body sets the body of the response with its content type.
+func (r *StubRoute) Body(contentType string, body []byte) *StubRoute {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	res := r.last()
+	res.header.Set(echo.HeaderContentType, contentType)
+	res.body = body
+	return r
+}
+
+// Handler responds with h rather than the configured status, headers and
+// body, e.g. to delay or stream the response.
+func (r *StubRoute) Handler(h http.HandlerFunc) *StubRoute {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.last().handler = h
+	return r
+}
+
+// Then starts the next scripted response, which responds with 200 and no body
+// until configured otherwise.
+func (r *StubRoute) Then() *StubRoute {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.responses = append(r.responses, newStubResponse())
+	return r
+}
+
+// Requests returns the requests received by the route so far.
+func (r *StubRoute) Requests() []StubRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]StubRequest(nil), r.requests...)
+}
+
+// Count returns the number of requests received by the route so far.
+func (r *StubRoute) Count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.requests)
+}
+
+// LastRequest returns the last request received by the route, failing the
+// test if there is none.
+func (r *StubRoute) LastRequest(t *testing.T) StubRequest {
+	t.Helper()
+	requests := r.Requests()
+	require.NotEmpty(t, requests, "stub route received no requests")
+	return requests[len(requests)-1]
+}
+
+// AssertCount asserts that the route received n requests.
+func (r *StubRoute) AssertCount(t *testing.T, n int) bool {
+	t.Helper()
+	return assert.Equal(t, n, r.Count(), "stub route request count")
+}
+
+// AssertHeader asserts that the last request received by the route had a
+// header with value.
+func (r *StubRoute) AssertHeader(t *testing.T, key string, value string) bool {
+	t.Helper()
+	return assert.Equal(t, value, r.LastRequest(t).Header.Get(key), "stub route request header %s", key)
+}
+
+// AssertJSONBody asserts that the body of the last request received by the
+// route is JSON equal to v encoded as JSON.
+func (r *StubRoute) AssertJSONBody(t *testing.T, v any) bool {
+	t.Helper()
+	want, err := json.Marshal(v)
+	require.NoError(t, err)
+	return assert.JSONEq(t, string(want), string(r.LastRequest(t).Body))
+}
+
+func (r *StubRoute) serve(w http.ResponseWriter, req *http.Request, stubReq StubRequest) {
+	r.mu.Lock()
+	res := r.responses[min(len(r.requests), len(r.responses)-1)]
+	r.requests = append(r.requests, stubReq)
+	r.mu.Unlock()
+
+	if res.handler != nil {
+		res.handler(w, req)
+		return
+	}
+	for k, v := range res.header {
+		w.Header()[k] = v
+	}
+	w.WriteHeader(res.status)
+	_, _ = w.Write(res.body)
+}
+
+// last returns the response currently being configured. r.mu must be held.
+func (r *StubRoute) last() *stubResponse {
+	return r.responses[len(r.responses)-1]
+}
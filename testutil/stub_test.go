@@ -0,0 +1,80 @@
+package testutil
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStubServer(t *testing.T) {
+	s := StartStubServer(t)
+	items := s.Handle(http.MethodPost, "/items").
+		Status(http.StatusCreated).
+		Header("X-Item-ID", "1").
+		JSON(map[string]int{"id": 1})
+	health := s.Handle(http.MethodGet, "/healthz")
+
+	res, err := http.Post(s.URL()+"/items?dry_run=true", "application/json", strings.NewReader(`{"name":"item"}`))
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+	assert.Equal(t, "1", res.Header.Get("X-Item-ID"))
+	assert.Equal(t, "application/json", res.Header.Get("Content-Type"))
+
+	res, err = http.Get(s.URL() + "/healthz")
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	items.AssertCount(t, 1)
+	items.AssertHeader(t, "Content-Type", "application/json")
+	items.AssertJSONBody(t, map[string]string{"name": "item"})
+	req := items.LastRequest(t)
+	assert.Equal(t, "/items", req.Path)
+	assert.Equal(t, "true", req.Query.Get("dry_run"))
+	var body struct {
+		Name string `json:"name"`
+	}
+	req.DecodeJSON(t, &body)
+	assert.Equal(t, "item", body.Name)
+
+	health.AssertCount(t, 1)
+	require.Len(t, s.Requests(), 2)
+	assert.Equal(t, http.MethodPost, s.Requests()[0].Method)
+	assert.Equal(t, http.MethodGet, s.Requests()[1].Method)
+}
+
+func TestStubRoute_Then(t *testing.T) {
+	s := StartStubServer(t)
+	route := s.Handle(http.MethodGet, "/items/{id}").
+		Status(http.StatusServiceUnavailable).
+		Then().
+		Body("text/plain", []byte("ok")).
+		Then().
+		Handler(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write([]byte(r.PathValue("id")))
+		})
+
+	var got []string
+	for range 4 {
+		got = append(got, stubResponseSummary(t, s.URL()+"/items/42"))
+	}
+	assert.Equal(t, []string{"503 ", "200 ok", "202 42", "202 42"}, got)
+	route.AssertCount(t, 4)
+}
+
+func stubResponseSummary(t *testing.T, url string) string {
+	t.Helper()
+	res, err := http.Get(url)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	return strconv.Itoa(res.StatusCode) + " " + string(body)
+}
@@ -0,0 +1,67 @@
+package tkn
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+const apiKeyHintLength = 4
+
+// APIKey is the persisted form of an API key. The plaintext key is only
+// available when created and is never stored.
+type APIKey struct {
+	Prefix    string    `json:"prefix"`
+	Hint      string    `json:"hint"` // last 4 characters of the key
+	Hash      string    `json:"hash"` // hex encoded SHA-256 of the key
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewAPIKey generates a new checksummed API key with the given prefix,
+// returning the APIKey to persist and the plaintext key to present once to
+// the user.
+//
+// Example:
+//
+//	key, plaintext, err := tkn.NewAPIKey("kit_")
+//	// store key, return plaintext
+//	...
+//	if !key.Match(presented) {
+//		return errtag.NewTagged[errtag.Unauthorized]("invalid api key")
+//	}
+func NewAPIKey(prefix string, opts ...GenerateOption) (APIKey, string, error) {
+	opts = append(opts, WithPrefix(prefix), WithChecksum())
+	plaintext, err := Generate(opts...)
+	if err != nil {
+		return APIKey{}, "", err
+	}
+	return APIKey{
+		Prefix:    prefix,
+		Hint:      plaintext[len(plaintext)-apiKeyHintLength:],
+		Hash:      HashToken(plaintext),
+		CreatedAt: time.Now().UTC(),
+	}, plaintext, nil
+}
+
+// Match reports whether the presented plaintext key matches the key, in
+// constant time.
+func (k APIKey) Match(presented string) bool {
+	if !strings.HasPrefix(presented, k.Prefix) {
+		return false
+	}
+	return Equal(HashToken(presented), k.Hash)
+}
+
+// Display returns a redacted form of the key for presentation, e.g.
+// kit_...a1B2.
+func (k APIKey) Display() string {
+	return k.Prefix + "..." + k.Hint
+}
+
+// HashToken returns the hex encoded SHA-256 of a token. A fast hash is
+// sufficient since generated tokens have high entropy.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,26 @@
+package tkn
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAPIKey(t *testing.T) {
+	key, plaintext, err := NewAPIKey("kit_")
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(plaintext, "kit_"))
+	assert.NoError(t, Validate(plaintext, WithPrefix("kit_")))
+	assert.Equal(t, "kit_", key.Prefix)
+	assert.Equal(t, plaintext[len(plaintext)-4:], key.Hint)
+	assert.Equal(t, "kit_..."+key.Hint, key.Display())
+	assert.NotContains(t, key.Hash, plaintext)
+	assert.False(t, key.CreatedAt.IsZero())
+
+	assert.True(t, key.Match(plaintext))
+	assert.False(t, key.Match(plaintext+"x"))
+	assert.False(t, key.Match("abc_"+strings.TrimPrefix(plaintext, "kit_")))
+}
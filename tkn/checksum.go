@@ -0,0 +1,60 @@
+package tkn
+
+import (
+	"errors"
+	"hash/crc32"
+	"strings"
+)
+
+const checksumLength = 6 // base62 encoded CRC32 (max 4294967295 fits in 6 chars)
+
+var (
+	ErrInvalidPrefix   = errors.New("invalid token prefix")
+	ErrInvalidLength   = errors.New("invalid token length")
+	ErrInvalidChars    = errors.New("invalid token characters")
+	ErrInvalidChecksum = errors.New("invalid token checksum")
+)
+
+// Validate verifies the format of a token generated with WithChecksum using
+// the same options (prefix, length and charset), without any storage lookup.
+func Validate(token string, opts ...GenerateOption) error {
+	options := newGenerateOptions(append(opts, WithChecksum())...)
+
+	body, ok := strings.CutPrefix(token, options.prefix)
+	if !ok {
+		return ErrInvalidPrefix
+	}
+	if len(body) != options.length+checksumLength {
+		return ErrInvalidLength
+	}
+	// The random part is chosen from the charset while the checksum is
+	// always base62.
+	if !onlyChars(body[:options.length], options.charset) || !onlyChars(body[options.length:], base62Chars) {
+		return ErrInvalidChars
+	}
+	split := len(token) - checksumLength
+	if checksum(token[:split]) != token[split:] {
+		return ErrInvalidChecksum
+	}
+	return nil
+}
+
+func onlyChars(s string, chars string) bool {
+	for i := range len(s) {
+		if strings.IndexByte(chars, s[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// checksum returns the CRC32 of s encoded as fixed length base62.
+func checksum(s string) string {
+	n := crc32.ChecksumIEEE([]byte(s))
+	out := make([]byte, checksumLength)
+	for i := checksumLength - 1; i >= 0; i-- {
+		out[i] = base62Chars[n%62]
+		n /= 62
+	}
+	return string(out)
+}
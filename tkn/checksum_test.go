@@ -0,0 +1,54 @@
+package tkn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	token, err := Generate(WithPrefix("kit_"), WithLength(30), WithChecksum())
+	require.NoError(t, err)
+	assert.Len(t, token, len("kit_")+30+checksumLength)
+	assert.NoError(t, Validate(token, WithPrefix("kit_"), WithLength(30)))
+
+	tampered := []byte(token)
+	tampered[5] = flipChar(tampered[5])
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{name: "wrong prefix", token: "abc_" + token[4:], wantErr: ErrInvalidPrefix},
+		{name: "truncated", token: token[:len(token)-1], wantErr: ErrInvalidLength},
+		{name: "invalid chars", token: token[:len(token)-1] + "-", wantErr: ErrInvalidChars},
+		{name: "tampered", token: string(tampered), wantErr: ErrInvalidChecksum},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, Validate(tt.token, WithPrefix("kit_"), WithLength(30)), tt.wantErr)
+		})
+	}
+}
+
+func TestValidate_customCharset(t *testing.T) {
+	opts := []GenerateOption{WithCharset("0123456789abcdef"), WithLength(16)}
+	tokens, err := GenerateN(50, append(opts, WithChecksum())...)
+	require.NoError(t, err)
+	for _, token := range tokens {
+		assert.NoError(t, Validate(token, opts...), token)
+	}
+
+	// The random part must use the charset.
+	token := tokens[0]
+	assert.ErrorIs(t, Validate("G"+token[1:], opts...), ErrInvalidChars)
+}
+
+func flipChar(c byte) byte {
+	if c == 'a' {
+		return 'b'
+	}
+	return 'a'
+}
@@ -0,0 +1,93 @@
+package tkn
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	token, err := Generate(WithPrefix("kit_"), WithLength(20))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(token, "kit_"))
+	assert.Len(t, token, 24)
+
+	token, err = Generate(WithCharset("01"), WithLength(64))
+	require.NoError(t, err)
+	assert.Empty(t, strings.Trim(token, "01"))
+
+	token, err = Generate(WithURLSafeBase64(), WithChecksum())
+	require.NoError(t, err)
+	assert.NoError(t, Validate(token, WithURLSafeBase64()))
+
+	_, err = Generate(WithCharset("a"))
+	assert.Error(t, err)
+	_, err = Generate(WithCharset("abca"))
+	assert.Error(t, err)
+	_, err = Generate(WithLength(-1))
+	assert.Error(t, err)
+	_, err = GenerateN(2, WithLength(-1))
+	assert.Error(t, err)
+}
+
+func TestGenerateBytes(t *testing.T) {
+	token, err := GenerateBytes(32, WithPrefix("kit_"))
+	require.NoError(t, err)
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(token, "kit_"))
+	require.NoError(t, err)
+	assert.Len(t, b, 32)
+
+	_, err = GenerateBytes(0)
+	assert.Error(t, err)
+}
+
+func TestRandString_uniform(t *testing.T) {
+	// With a 3 character charset, bytes 255 would map to index 0 without
+	// rejection. Feed every byte value once to check only unbiased bytes are
+	// used.
+	src := make([]byte, 256)
+	for i := range src {
+		src[i] = byte(i)
+	}
+	got, err := randString(bytes.NewReader(bytes.Repeat(src, 2)), "abc", 255)
+	require.NoError(t, err)
+	assert.Equal(t, 85, strings.Count(got, "a"))
+	assert.Equal(t, 85, strings.Count(got, "b"))
+	assert.Equal(t, 85, strings.Count(got, "c"))
+}
+
+func TestGenerateN(t *testing.T) {
+	tokens, err := GenerateN(100, WithPrefix("inv_"), WithLength(12), WithChecksum())
+	require.NoError(t, err)
+	require.Len(t, tokens, 100)
+
+	seen := map[string]bool{}
+	for _, token := range tokens {
+		assert.NoError(t, Validate(token, WithPrefix("inv_"), WithLength(12)))
+		assert.False(t, seen[token])
+		seen[token] = true
+	}
+
+	_, err = GenerateN(0)
+	assert.Error(t, err)
+}
+
+func BenchmarkGenerate(b *testing.B) {
+	b.ReportAllocs()
+	for b.Loop() {
+		for range 100 {
+			_, _ = Generate()
+		}
+	}
+}
+
+func BenchmarkGenerateN(b *testing.B) {
+	b.ReportAllocs()
+	for b.Loop() {
+		_, _ = GenerateN(100)
+	}
+}
@@ -0,0 +1,30 @@
+package tkn
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"fmt"
+)
+
+const (
+	digitChars   = "0123456789"
+	minOTPDigits = 4
+	maxOTPDigits = 12
+)
+
+// GenerateOTP generates a numeric one-time code of the given number of digits
+// (4 to 12) with each digit uniformly distributed, for email and SMS
+// verification flows. Leading zeros are preserved.
+func GenerateOTP(digits int) (string, error) {
+	if digits < minOTPDigits || digits > maxOTPDigits {
+		return "", fmt.Errorf("otp digits must be between %d and %d", minOTPDigits, maxOTPDigits)
+	}
+	return randString(rand.Reader, digitChars, digits)
+}
+
+// Equal reports whether two tokens or codes are equal in constant time, so
+// comparing a presented value to an expected one does not leak timing
+// information.
+func Equal(a string, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
@@ -0,0 +1,27 @@
+package tkn
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateOTP(t *testing.T) {
+	code, err := GenerateOTP(6)
+	require.NoError(t, err)
+	assert.Len(t, code, 6)
+	assert.Empty(t, strings.Trim(code, digitChars))
+
+	_, err = GenerateOTP(3)
+	assert.Error(t, err)
+	_, err = GenerateOTP(13)
+	assert.Error(t, err)
+}
+
+func TestEqual(t *testing.T) {
+	assert.True(t, Equal("123456", "123456"))
+	assert.False(t, Equal("123456", "123457"))
+	assert.False(t, Equal("123456", "12345"))
+}
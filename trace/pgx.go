@@ -0,0 +1,68 @@
+package trace
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// PgxTracer is a pgx.QueryTracer starting a client span for every query,
+// named by its SQL command (e.g. SELECT). Set it with pgdb.WithTracer.
+type PgxTracer struct {
+	tracer oteltrace.Tracer
+}
+
+var _ pgx.QueryTracer = (*PgxTracer)(nil)
+
+// NewPgxTracer creates a new PgxTracer.
+func NewPgxTracer() *PgxTracer {
+	return &PgxTracer{
+		tracer: otel.Tracer(tracerName),
+	}
+}
+
+func (t *PgxTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	attrs := []attribute.KeyValue{
+		attribute.String("db.system.name", "postgresql"),
+		attribute.String("db.query.text", data.SQL),
+	}
+	if conn != nil {
+		cfg := conn.Config()
+		attrs = append(attrs,
+			attribute.String("db.namespace", cfg.Database),
+			attribute.String("server.address", cfg.Host),
+		)
+	}
+	ctx, _ = t.tracer.Start(ctx, sqlCommand(data.SQL),
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+		oteltrace.WithAttributes(attrs...),
+	)
+	return ctx
+}
+
+func (t *PgxTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := oteltrace.SpanFromContext(ctx)
+	defer span.End()
+	// No rows is an expected outcome rather than a failed query.
+	if data.Err != nil && !errors.Is(data.Err, pgx.ErrNoRows) {
+		span.RecordError(data.Err)
+		span.SetStatus(codes.Error, data.Err.Error())
+		return
+	}
+	span.SetAttributes(attribute.Int64("db.response.affected_rows", data.CommandTag.RowsAffected()))
+}
+
+// sqlCommand returns the command of a SQL statement, e.g. SELECT.
+func sqlCommand(sql string) string {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return "query"
+	}
+	return strings.ToUpper(fields[0])
+}
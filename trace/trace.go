@@ -0,0 +1,128 @@
+// Package trace bootstraps OpenTelemetry tracing and instruments the server,
+// pgx and HTTP clients so W3C trace context propagates end-to-end, e.g. from
+// a BFF through its downstreams to their database.
+package trace
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cohesivestack/valgo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/joshjon/kit/valgoutil"
+)
+
+const (
+	tracerName         = "github.com/joshjon/kit/trace"
+	defaultSampleRatio = 1
+)
+
+// Config configures tracing. Spans are exported over OTLP HTTP to
+// EndpointURL (e.g. http://otel-collector:4318), or only propagated when no
+// endpoint is set. SampleRatio is the fraction of new traces that are
+// sampled, while traces started upstream follow the sampling decision of
+// their parent.
+//
+// Zero values use the defaults: a sample ratio of 1.
+type Config struct {
+	ServiceName string            `yaml:"serviceName" env:"SERVICE_NAME"`
+	EndpointURL string            `yaml:"endpointURL" env:"ENDPOINT_URL"`
+	SampleRatio float64           `yaml:"sampleRatio" env:"SAMPLE_RATIO"`
+	Headers     map[string]string `yaml:"headers" env:"HEADERS"` // e.g. for authentication
+}
+
+func (c *Config) Validation() *valgo.Validation {
+	v := valgo.Is(
+		valgo.String(c.ServiceName, "serviceName").Not().Blank(),
+		valgo.Float64(c.SampleRatio, "sampleRatio").Between(0, 1),
+	)
+	if c.EndpointURL != "" {
+		v.Is(valgoutil.URLValidator(c.EndpointURL, "endpointURL"))
+	}
+	return v
+}
+
+func (c Config) withDefaults() Config {
+	if c.SampleRatio == 0 {
+		c.SampleRatio = defaultSampleRatio
+	}
+	return c
+}
+
+// Option optionally configures Setup.
+type Option func(opts *options)
+
+// WithSpanExporter adds an exporter that spans are also exported to, e.g. a
+// tracetest.InMemoryExporter to assert spans in tests. Spans are exported
+// synchronously to it.
+func WithSpanExporter(exporter sdktrace.SpanExporter) Option {
+	return func(opts *options) {
+		opts.exporters = append(opts.exporters, exporter)
+	}
+}
+
+// WithResourceAttributes adds attributes describing the service to all
+// spans, e.g. its version or deployment environment.
+func WithResourceAttributes(attrs ...attribute.KeyValue) Option {
+	return func(opts *options) {
+		opts.attrs = append(opts.attrs, attrs...)
+	}
+}
+
+type options struct {
+	exporters []sdktrace.SpanExporter
+	attrs     []attribute.KeyValue
+}
+
+// Setup configures the global tracer provider and W3C trace context and
+// baggage propagation. The returned shutdown func flushes pending spans and
+// must be called before the service exits.
+func Setup(ctx context.Context, cfg Config, opts ...Option) (shutdown func(context.Context) error, err error) {
+	options := options{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	cfg = cfg.withDefaults()
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		append([]attribute.KeyValue{attribute.String("service.name", cfg.ServiceName)}, options.attrs...)...,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("create trace resource: %w", err)
+	}
+
+	providerOpts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	}
+	for _, exporter := range options.exporters {
+		providerOpts = append(providerOpts, sdktrace.WithSyncer(exporter))
+	}
+	if cfg.EndpointURL != "" {
+		exporter, err := otlptracehttp.New(ctx,
+			otlptracehttp.WithEndpointURL(cfg.EndpointURL),
+			otlptracehttp.WithHeaders(cfg.Headers),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("create otlp exporter: %w", err)
+		}
+		providerOpts = append(providerOpts, sdktrace.WithBatcher(exporter))
+	}
+
+	provider := sdktrace.NewTracerProvider(providerOpts...)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return func(ctx context.Context) error {
+		if err := provider.Shutdown(ctx); err != nil {
+			return fmt.Errorf("shutdown tracer provider: %w", err)
+		}
+		return nil
+	}, nil
+}
@@ -0,0 +1,127 @@
+package trace
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func setupTest(t *testing.T) *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	shutdown, err := Setup(context.Background(), Config{ServiceName: "test"}, WithSpanExporter(exporter))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, shutdown(context.Background()))
+	})
+	return exporter
+}
+
+func TestPropagation(t *testing.T) {
+	exporter := setupTest(t)
+
+	downstream := echo.New()
+	downstream.Use(Middleware())
+	downstream.GET("/items/:id", func(c echo.Context) error {
+		// child spans of the request, e.g. queries, continue the trace
+		tracer := NewPgxTracer()
+		ctx := tracer.TraceQueryStart(c.Request().Context(), nil, pgx.TraceQueryStartData{SQL: "select * from items"})
+		tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("SELECT 1")})
+		return c.NoContent(http.StatusNoContent)
+	})
+	srv := httptest.NewServer(downstream)
+	defer srv.Close()
+
+	client := &http.Client{Transport: NewTransport(nil)}
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/items/1", nil)
+	require.NoError(t, err)
+	res, err := client.Do(req)
+	require.NoError(t, err)
+	res.Body.Close()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 3)
+	querySpan, serverSpan, clientSpan := spans[0], spans[1], spans[2]
+
+	assert.Equal(t, "SELECT", querySpan.Name)
+	assert.Equal(t, oteltrace.SpanKindClient, querySpan.SpanKind)
+	assert.Contains(t, querySpan.Attributes, attribute.String("db.query.text", "select * from items"))
+	assert.Contains(t, querySpan.Attributes, attribute.Int64("db.response.affected_rows", 1))
+
+	assert.Equal(t, "GET /items/:id", serverSpan.Name)
+	assert.Equal(t, oteltrace.SpanKindServer, serverSpan.SpanKind)
+	assert.Contains(t, serverSpan.Attributes, attribute.Int("http.response.status_code", http.StatusNoContent))
+
+	assert.Equal(t, "GET", clientSpan.Name)
+	assert.Equal(t, oteltrace.SpanKindClient, clientSpan.SpanKind)
+
+	traceID := clientSpan.SpanContext.TraceID()
+	assert.Equal(t, traceID, serverSpan.SpanContext.TraceID())
+	assert.Equal(t, traceID, querySpan.SpanContext.TraceID())
+	assert.Equal(t, clientSpan.SpanContext.SpanID(), serverSpan.Parent.SpanID())
+	assert.Equal(t, serverSpan.SpanContext.SpanID(), querySpan.Parent.SpanID())
+}
+
+func TestMiddleware_errors(t *testing.T) {
+	exporter := setupTest(t)
+
+	e := echo.New()
+	e.Use(Middleware("/healthz"))
+	e.GET("/broken", func(c echo.Context) error {
+		return errors.New("broken")
+	})
+	e.GET("/bad", func(c echo.Context) error {
+		return echo.ErrBadRequest
+	})
+	e.GET("/healthz", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+	for _, path := range []string{"/broken", "/bad", "/healthz"} {
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+	assert.Contains(t, spans[0].Attributes, attribute.Int("http.response.status_code", http.StatusInternalServerError))
+	assert.Equal(t, codes.Unset, spans[1].Status.Code)
+	assert.Contains(t, spans[1].Attributes, attribute.Int("http.response.status_code", http.StatusBadRequest))
+}
+
+func TestPgxTracer_error(t *testing.T) {
+	exporter := setupTest(t)
+	tracer := NewPgxTracer()
+
+	ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "select 1"})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: pgx.ErrNoRows})
+	ctx = tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "  insert into items"})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: errors.New("duplicate key")})
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, codes.Unset, spans[0].Status.Code)
+	assert.Equal(t, "INSERT", spans[1].Name)
+	assert.Equal(t, codes.Error, spans[1].Status.Code)
+}
+
+func TestConfig_Validation(t *testing.T) {
+	valid := Config{ServiceName: "test", EndpointURL: "http://localhost:4318", SampleRatio: 0.5}
+	assert.True(t, valid.Validation().Valid())
+
+	invalid := Config{EndpointURL: "::", SampleRatio: 2}
+	errs := invalid.Validation().Errors()
+	assert.Contains(t, errs, "serviceName")
+	assert.Contains(t, errs, "endpointURL")
+	assert.Contains(t, errs, "sampleRatio")
+}
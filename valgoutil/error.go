@@ -2,6 +2,7 @@ package valgoutil
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/cohesivestack/valgo"
@@ -20,3 +21,30 @@ func GetDetails(err *valgo.Error) []string {
 
 	return details
 }
+
+// FieldError holds the validation messages of a single field. Nested fields
+// use their full path (e.g. "downstreams[0].url").
+type FieldError struct {
+	Field    string   `json:"field"`
+	Messages []string `json:"messages"`
+}
+
+// GetFieldErrors returns the errors of each invalid field sorted by field.
+func GetFieldErrors(err *valgo.Error) []FieldError {
+	if err == nil || err.Errors() == nil {
+		return []FieldError{}
+	}
+
+	fieldErrs := make([]FieldError, 0, len(err.Errors()))
+	for _, v := range err.Errors() {
+		fieldErrs = append(fieldErrs, FieldError{
+			Field:    v.Name(),
+			Messages: v.Messages(),
+		})
+	}
+	slices.SortFunc(fieldErrs, func(a, b FieldError) int {
+		return strings.Compare(a.Field, b.Field)
+	})
+
+	return fieldErrs
+}
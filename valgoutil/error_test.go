@@ -20,3 +20,18 @@ func TestGetDetails(t *testing.T) {
 
 	assert.Contains(t, wantOneOf, got[0])
 }
+
+func TestGetFieldErrors(t *testing.T) {
+	v := valgo.Is(valgo.String("", "name").Not().Blank("name is required"))
+	v.In("db", valgo.Is(valgo.Int(0, "port").GreaterThan(0, "port must be positive")))
+	v.InRow("hosts", 1, valgo.Is(valgo.String("", "host").Not().Blank("host is required")))
+
+	got := GetFieldErrors(v.ToError().(*valgo.Error))
+	assert.Equal(t, []FieldError{
+		{Field: "db.port", Messages: []string{"port must be positive"}},
+		{Field: "hosts[1].host", Messages: []string{"host is required"}},
+		{Field: "name", Messages: []string{"name is required"}},
+	}, got)
+
+	assert.Empty(t, GetFieldErrors(nil))
+}
@@ -5,8 +5,13 @@ import (
 	"fmt"
 	"net"
 	"net/url"
+	"os"
+	"slices"
+	"strings"
 
 	"github.com/cohesivestack/valgo"
+
+	"github.com/joshjon/kit/id"
 )
 
 func HostPortValidator(hostPort string, nameAndTitle ...string) valgo.Validator {
@@ -33,6 +38,42 @@ func NonEmptySliceValidator[T any](items []T, nameAndTitle ...string) valgo.Vali
 	}, "{{title}} must not be empty")
 }
 
+// RequiredIf validates that value is not the zero value when condition is
+// true, e.g. a key file required when a cert file is set.
+//
+// Example:
+//
+//	valgoutil.RequiredIf(c.KeyFile, c.CertFile != "", "keyFile")
+func RequiredIf[T comparable](value T, condition bool, nameAndTitle ...string) valgo.Validator {
+	return valgo.Any(value, nameAndTitle...).Passing(func(v any) bool {
+		var zero T
+		return !condition || v.(T) != zero
+	}, "{{title}} is required")
+}
+
+// RequiredUnless validates that value is not the zero value unless condition
+// is true.
+func RequiredUnless[T comparable](value T, condition bool, nameAndTitle ...string) valgo.Validator {
+	return RequiredIf(value, !condition, nameAndTitle...)
+}
+
+// OneOfValidator validates that value is one of the allowed values. The
+// allowed values are a slice rather than variadic so the field name and title
+// can still be provided.
+//
+// Example:
+//
+//	valgoutil.OneOfValidator(c.Format, []string{"json", "text"}, "format")
+func OneOfValidator[T comparable](value T, allowed []T, nameAndTitle ...string) valgo.Validator {
+	strs := make([]string, len(allowed))
+	for i, a := range allowed {
+		strs[i] = fmt.Sprintf("%v", a)
+	}
+	return valgo.Any(value, nameAndTitle...).Passing(func(v any) bool {
+		return slices.Contains(allowed, v.(T))
+	}, fmt.Sprintf("must be one of: %s", strings.Join(strs, ", ")))
+}
+
 // HexAESKeyValidator validates a hex-encoded AES key.
 // The value must be a valid hex and decode to 16, 24, or 32 bytes.
 func HexAESKeyValidator(hexKey string, nameAndTitle ...string) valgo.Validator {
@@ -53,6 +94,48 @@ func HexBytesLen(hexKey string, numBytes int, nameAndTitle ...string) valgo.Vali
 	}, fmt.Sprintf("must be a hex-encoded string that decodes to %d bytes", numBytes))
 }
 
+// IPValidator validates an IPv4 or IPv6 address.
+func IPValidator(ip string, nameAndTitle ...string) valgo.Validator {
+	return valgo.String(ip, nameAndTitle...).Passing(func(s string) bool {
+		return net.ParseIP(s) != nil
+	}, "must be a valid IP address")
+}
+
+// CIDRValidator validates an IPv4 or IPv6 CIDR block (e.g. 10.0.0.0/8).
+func CIDRValidator(cidr string, nameAndTitle ...string) valgo.Validator {
+	return valgo.String(cidr, nameAndTitle...).Passing(func(s string) bool {
+		_, _, err := net.ParseCIDR(s)
+		return err == nil
+	}, "must be a valid CIDR block of the form 'ip/prefix'")
+}
+
+// PortValidator validates a TCP/UDP port in the range 1-65535.
+func PortValidator(port int, nameAndTitle ...string) valgo.Validator {
+	return valgo.Int(port, nameAndTitle...).Between(1, 65535, "must be a valid port between 1 and 65535")
+}
+
+// FilePathValidator validates a file or directory path. When mustExist is
+// true the path must exist and be readable, so misconfigured paths fail
+// validation at startup rather than at first use.
+func FilePathValidator(path string, mustExist bool, nameAndTitle ...string) valgo.Validator {
+	if !mustExist {
+		return valgo.String(path, nameAndTitle...).Not().Blank().Passing(func(s string) bool {
+			return !strings.ContainsRune(s, 0)
+		}, "must be a valid path")
+	}
+	return valgo.String(path, nameAndTitle...).Passing(func(s string) bool {
+		return isReadablePath(s)
+	}, "must be a path to an existing readable file or directory")
+}
+
+// IDValidator validates a public ID of the specified type, including its
+// prefix, e.g. IDValidator[UserID](req.UserID, "user_id").
+func IDValidator[I id.ID, PI id.SubtypePtr[I]](value string, nameAndTitle ...string) valgo.Validator {
+	return valgo.String(value, nameAndTitle...).Passing(func(s string) bool {
+		return id.IsValid[I, PI](s)
+	}, "must be a valid ID")
+}
+
 func isValidHostPort(hostPort string) bool {
 	_, _, err := net.SplitHostPort(hostPort)
 	return err == nil
@@ -73,6 +156,18 @@ func isValidURL(rawURL string) bool {
 	return parsedURL.Host != ""
 }
 
+func isReadablePath(path string) bool {
+	if path == "" {
+		return false
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	return true
+}
+
 func isValidHexAESKey(s string) bool {
 	b, err := hex.DecodeString(s)
 	if err != nil {
@@ -1,10 +1,13 @@
 package valgoutil
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/cohesivestack/valgo"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestHostPortValidator(t *testing.T) {
@@ -21,3 +24,58 @@ func TestURLValidator(t *testing.T) {
 	ok := valgo.Is(URLValidator("invalid.com", "foo")).Valid()
 	assert.False(t, ok)
 }
+
+func TestIPValidator(t *testing.T) {
+	assert.True(t, valgo.Is(IPValidator("10.0.0.1", "foo")).Valid())
+	assert.True(t, valgo.Is(IPValidator("::1", "foo")).Valid())
+	assert.False(t, valgo.Is(IPValidator("10.0.0.256", "foo")).Valid())
+}
+
+func TestCIDRValidator(t *testing.T) {
+	assert.True(t, valgo.Is(CIDRValidator("10.0.0.0/8", "foo")).Valid())
+	assert.True(t, valgo.Is(CIDRValidator("fd00::/8", "foo")).Valid())
+	assert.False(t, valgo.Is(CIDRValidator("10.0.0.1", "foo")).Valid())
+}
+
+func TestPortValidator(t *testing.T) {
+	assert.True(t, valgo.Is(PortValidator(8080, "foo")).Valid())
+	assert.False(t, valgo.Is(PortValidator(0, "foo")).Valid())
+	assert.False(t, valgo.Is(PortValidator(65536, "foo")).Valid())
+}
+
+func TestFilePathValidator(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "cert.pem")
+	require.NoError(t, os.WriteFile(file, []byte("cert"), 0o600))
+
+	assert.True(t, valgo.Is(FilePathValidator(file, true, "foo")).Valid())
+	assert.True(t, valgo.Is(FilePathValidator(filepath.Dir(file), true, "foo")).Valid())
+	assert.False(t, valgo.Is(FilePathValidator(file+".missing", true, "foo")).Valid())
+	assert.False(t, valgo.Is(FilePathValidator("", true, "foo")).Valid())
+
+	assert.True(t, valgo.Is(FilePathValidator(file+".missing", false, "foo")).Valid())
+	assert.False(t, valgo.Is(FilePathValidator("", false, "foo")).Valid())
+}
+
+func TestRequiredIf(t *testing.T) {
+	assert.True(t, valgo.Is(RequiredIf("", false, "foo")).Valid())
+	assert.True(t, valgo.Is(RequiredIf("key.pem", true, "foo")).Valid())
+	assert.False(t, valgo.Is(RequiredIf("", true, "foo")).Valid())
+	assert.False(t, valgo.Is(RequiredIf(0, true, "foo")).Valid())
+}
+
+func TestRequiredUnless(t *testing.T) {
+	assert.True(t, valgo.Is(RequiredUnless("", true, "foo")).Valid())
+	assert.False(t, valgo.Is(RequiredUnless("", false, "foo")).Valid())
+	assert.True(t, valgo.Is(RequiredUnless(8080, false, "foo")).Valid())
+}
+
+func TestOneOfValidator(t *testing.T) {
+	assert.True(t, valgo.Is(OneOfValidator("json", []string{"json", "text"}, "foo")).Valid())
+
+	v := valgo.Is(OneOfValidator("xml", []string{"json", "text"}, "foo"))
+	require.False(t, v.Valid())
+	assert.Equal(t, []string{"must be one of: json, text"}, v.Errors()["foo"].Messages())
+
+	type level int
+	assert.False(t, valgo.Is(OneOfValidator(level(3), []level{0, 1, 2}, "foo")).Valid())
+}
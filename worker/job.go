@@ -0,0 +1,129 @@
+// Package worker provides a durable background job queue processed by a
+// Worker, with jobs stored in Postgres by a PostgresStore or in SQLite by a
+// SQLiteStore.
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const defaultMaxAttempts = 10
+
+// ErrLeaseLost is returned by a Store when a job could not be updated because
+// its lease expired and it was claimed again, or it was otherwise changed
+// since it was claimed.
+var ErrLeaseLost = errors.New("worker: job lease lost")
+
+// Job is a job claimed by a Worker.
+type Job struct {
+	ID      int64
+	Queue   string
+	Payload json.RawMessage
+	// Attempt is the number of the current attempt, starting at 1.
+	Attempt     int
+	MaxAttempts int
+	CreatedAt   time.Time
+}
+
+// Decode decodes the JSON payload of the job into v.
+func (j *Job) Decode(v any) error {
+	if err := json.Unmarshal(j.Payload, v); err != nil {
+		return fmt.Errorf("decode payload of job %d: %w", j.ID, err)
+	}
+	return nil
+}
+
+// NewJob is a job to enqueue.
+type NewJob struct {
+	Queue   string
+	Payload json.RawMessage
+	// Delay is how long after being enqueued the job is due.
+	Delay       time.Duration
+	MaxAttempts int
+}
+
+// Store stores jobs in a database. Jobs are pending until claimed by a worker,
+// and running until completed, retried or failed. Jobs failing all their
+// attempts are dead until requeued.
+type Store interface {
+	// Enqueue adds a pending job, returning its ID.
+	Enqueue(ctx context.Context, job NewJob) (int64, error)
+	// Claim claims the next due pending job of queue, leasing it for lease.
+	// Running jobs whose lease expired, e.g. because their worker crashed,
+	// are claimed again. Returns nil if there are no jobs to claim.
+	Claim(ctx context.Context, queue string, lease time.Duration) (*Job, error)
+	// Complete deletes a running job.
+	Complete(ctx context.Context, job *Job) error
+	// Retry makes a running job pending again, due after delay.
+	Retry(ctx context.Context, job *Job, delay time.Duration, reason string) error
+	// Fail makes a running job dead.
+	Fail(ctx context.Context, job *Job, reason string) error
+	// Release makes a running job pending again without counting the attempt,
+	// e.g. when its worker is shutting down.
+	Release(ctx context.Context, job *Job) error
+	// Requeue makes a dead job pending again with all its attempts, returning
+	// an errtag.NotFound error if there is no dead job with id.
+	Requeue(ctx context.Context, id int64) error
+}
+
+// EnqueueOption optionally configures an enqueued job.
+type EnqueueOption func(job *NewJob)
+
+// WithDelay sets how long after being enqueued the job is due. Defaults to 0,
+// which is due immediately.
+func WithDelay(d time.Duration) EnqueueOption {
+	return func(job *NewJob) {
+		job.Delay = d
+	}
+}
+
+// WithMaxAttempts sets the maximum number of attempts of the job before it is
+// dead. Defaults to 10.
+func WithMaxAttempts(n int) EnqueueOption {
+	return func(job *NewJob) {
+		job.MaxAttempts = n
+	}
+}
+
+// Enqueue adds a job to queue with payload encoded as JSON, returning its ID.
+// Use the WithTx method of a store to enqueue within a transaction.
+func Enqueue(ctx context.Context, store Store, queue string, payload any, opts ...EnqueueOption) (int64, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return 0, fmt.Errorf("encode job payload: %w", err)
+	}
+	job := NewJob{
+		Queue:       queue,
+		Payload:     data,
+		MaxAttempts: defaultMaxAttempts,
+	}
+	for _, opt := range opts {
+		opt(&job)
+	}
+	return store.Enqueue(ctx, job)
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err returned by a Handler so the job is failed without
+// retrying its remaining attempts.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+func isPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
@@ -0,0 +1,17 @@
+CREATE TABLE worker_jobs
+(
+    id           BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    queue        TEXT        NOT NULL,
+    payload      JSONB       NOT NULL,
+    status       TEXT        NOT NULL DEFAULT 'pending',
+    attempt      INTEGER     NOT NULL DEFAULT 0,
+    max_attempts INTEGER     NOT NULL,
+    run_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    locked_until TIMESTAMPTZ,
+    last_error   TEXT,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX worker_jobs_pending_idx ON worker_jobs (queue, run_at) WHERE status = 'pending';
+CREATE INDEX worker_jobs_running_idx ON worker_jobs (queue, locked_until) WHERE status = 'running';
@@ -0,0 +1,17 @@
+CREATE TABLE worker_jobs
+(
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    queue        TEXT    NOT NULL,
+    payload      TEXT    NOT NULL,
+    status       TEXT    NOT NULL DEFAULT 'pending',
+    attempt      INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL,
+    run_at       INTEGER NOT NULL, -- unix milliseconds
+    locked_until INTEGER,          -- unix milliseconds
+    last_error   TEXT,
+    created_at   INTEGER NOT NULL, -- unix milliseconds
+    updated_at   INTEGER NOT NULL  -- unix milliseconds
+);
+
+CREATE INDEX worker_jobs_pending_idx ON worker_jobs (queue, run_at) WHERE status = 'pending';
+CREATE INDEX worker_jobs_running_idx ON worker_jobs (queue, locked_until) WHERE status = 'running';
@@ -0,0 +1,180 @@
+package worker
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/joshjon/kit/errtag"
+	"github.com/joshjon/kit/pgdb"
+	"github.com/joshjon/kit/tx"
+)
+
+const postgresMigrationsTable = "worker_schema_migrations"
+
+//go:embed migrations/postgres/*.sql
+var postgresMigrations embed.FS
+
+// MigratePostgres applies the migrations of the tables used by a
+// PostgresStore, recording them separately from the migrations of the
+// service.
+func MigratePostgres(pool *pgxpool.Pool) error {
+	fsys, err := fs.Sub(postgresMigrations, "migrations/postgres")
+	if err != nil {
+		return err
+	}
+	return pgdb.Migrate(pool, fsys, pgdb.WithMigrationsTable(postgresMigrationsTable))
+}
+
+// pgxDB is implemented by both pgxpool.Pool and pgx.Tx.
+type pgxDB interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// PostgresStore is a Store of jobs in Postgres. Jobs are claimed with
+// FOR UPDATE SKIP LOCKED, so any number of workers can process the same
+// queues. Apply its migrations with MigratePostgres.
+type PostgresStore struct {
+	db pgxDB
+}
+
+var _ Store = (*PostgresStore)(nil)
+
+// NewPostgresStore creates a new PostgresStore.
+func NewPostgresStore(pool *pgxpool.Pool) *PostgresStore {
+	return &PostgresStore{db: pool}
+}
+
+// WithTx returns a copy of the store bound to the provided transaction, e.g.
+// to enqueue a job only if the transaction commits.
+//
+// Panics if tx does not implement pgx.Tx.
+func (s *PostgresStore) WithTx(txn tx.Tx) *PostgresStore {
+	pgxTx, ok := txn.(pgx.Tx)
+	if !ok {
+		panic("worker.PostgresStore.WithTx: expected pgx.Tx")
+	}
+	return &PostgresStore{db: pgxTx}
+}
+
+func (s *PostgresStore) Enqueue(ctx context.Context, job NewJob) (int64, error) {
+	var id int64
+	err := s.db.QueryRow(ctx, `
+		INSERT INTO worker_jobs (queue, payload, max_attempts, run_at)
+		VALUES ($1, $2, $3, now() + make_interval(secs => $4))
+		RETURNING id`,
+		job.Queue, []byte(job.Payload), job.MaxAttempts, job.Delay.Seconds(),
+	).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("enqueue job: %w", err)
+	}
+	return id, nil
+}
+
+func (s *PostgresStore) Claim(ctx context.Context, queue string, lease time.Duration) (*Job, error) {
+	var job Job
+	var payload []byte
+	err := s.db.QueryRow(ctx, `
+		UPDATE worker_jobs
+		SET status       = 'running',
+		    attempt      = attempt + 1,
+		    locked_until = now() + make_interval(secs => $2),
+		    updated_at   = now()
+		WHERE id = (SELECT id
+		            FROM worker_jobs
+		            WHERE queue = $1
+		              AND ((status = 'pending' AND run_at <= now()) OR
+		                   (status = 'running' AND locked_until <= now()))
+		            ORDER BY run_at, id
+		            LIMIT 1 FOR UPDATE SKIP LOCKED)
+		RETURNING id, queue, payload, attempt, max_attempts, created_at`,
+		queue, lease.Seconds(),
+	).Scan(&job.ID, &job.Queue, &payload, &job.Attempt, &job.MaxAttempts, &job.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("claim job: %w", err)
+	}
+	job.Payload = payload
+	return &job, nil
+}
+
+func (s *PostgresStore) Complete(ctx context.Context, job *Job) error {
+	return s.execRunning(ctx, job, "complete", `DELETE FROM worker_jobs WHERE id = $1 AND attempt = $2 AND status = 'running'`)
+}
+
+func (s *PostgresStore) Retry(ctx context.Context, job *Job, delay time.Duration, reason string) error {
+	return s.execRunning(ctx, job, "retry", `
+		UPDATE worker_jobs
+		SET status       = 'pending',
+		    run_at       = now() + make_interval(secs => $3),
+		    locked_until = NULL,
+		    last_error   = $4,
+		    updated_at   = now()
+		WHERE id = $1 AND attempt = $2 AND status = 'running'`,
+		delay.Seconds(), reason,
+	)
+}
+
+func (s *PostgresStore) Fail(ctx context.Context, job *Job, reason string) error {
+	return s.execRunning(ctx, job, "fail", `
+		UPDATE worker_jobs
+		SET status       = 'dead',
+		    locked_until = NULL,
+		    last_error   = $3,
+		    updated_at   = now()
+		WHERE id = $1 AND attempt = $2 AND status = 'running'`,
+		reason,
+	)
+}
+
+func (s *PostgresStore) Release(ctx context.Context, job *Job) error {
+	return s.execRunning(ctx, job, "release", `
+		UPDATE worker_jobs
+		SET status       = 'pending',
+		    attempt      = attempt - 1,
+		    run_at       = now(),
+		    locked_until = NULL,
+		    updated_at   = now()
+		WHERE id = $1 AND attempt = $2 AND status = 'running'`)
+}
+
+func (s *PostgresStore) Requeue(ctx context.Context, id int64) error {
+	tag, err := s.db.Exec(ctx, `
+		UPDATE worker_jobs
+		SET status     = 'pending',
+		    attempt    = 0,
+		    run_at     = now(),
+		    updated_at = now()
+		WHERE id = $1 AND status = 'dead'`,
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("requeue job %d: %w", id, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return errtag.Errorf[errtag.NotFound]("dead job %d not found", id)
+	}
+	return nil
+}
+
+// execRunning executes sql updating a running job, using its attempt to
+// detect whether it was claimed again since.
+func (s *PostgresStore) execRunning(ctx context.Context, job *Job, action string, sql string, args ...any) error {
+	tag, err := s.db.Exec(ctx, sql, append([]any{job.ID, job.Attempt}, args...)...)
+	if err != nil {
+		return fmt.Errorf("%s job %d: %w", action, job.ID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s job %d: %w", action, job.ID, ErrLeaseLost)
+	}
+	return nil
+}
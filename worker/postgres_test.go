@@ -0,0 +1,64 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/testutil"
+	"github.com/joshjon/kit/tx"
+)
+
+func newTestPostgresStore(t *testing.T) (*PostgresStore, *pgxpool.Pool) {
+	t.Helper()
+	pool := testutil.StartPostgres(t, nil)
+	require.NoError(t, MigratePostgres(pool))
+	return NewPostgresStore(pool), pool
+}
+
+func TestPostgresStore(t *testing.T) {
+	store, _ := newTestPostgresStore(t)
+	testStore(t, store)
+}
+
+func TestPostgresStore_expiredLease(t *testing.T) {
+	store, _ := newTestPostgresStore(t)
+	testStoreExpiredLease(t, store)
+}
+
+func TestPostgresStore_WithTx(t *testing.T) {
+	ctx := context.Background()
+	store, pool := newTestPostgresStore(t)
+
+	enqueueTx := func(ctx context.Context, fail bool) error {
+		txn, err := pool.BeginTx(ctx, pgx.TxOptions{})
+		require.NoError(t, err)
+		return tx.Do(ctx, txn, func(ctx context.Context) error {
+			if _, err := Enqueue(ctx, store.WithTx(txn), "emails", emailPayload{}); err != nil {
+				return err
+			}
+			if fail {
+				return errors.New("failed")
+			}
+			return nil
+		})
+	}
+
+	// rolled back
+	require.Error(t, enqueueTx(ctx, true))
+	job, err := store.Claim(ctx, "emails", time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, job)
+
+	// committed
+	require.NoError(t, enqueueTx(ctx, false))
+	job, err = store.Claim(ctx, "emails", time.Minute)
+	require.NoError(t, err)
+	assert.NotNil(t, job)
+}
@@ -0,0 +1,212 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"time"
+
+	"github.com/joshjon/kit/clock"
+	"github.com/joshjon/kit/errtag"
+	"github.com/joshjon/kit/sqlitedb"
+	"github.com/joshjon/kit/tx"
+)
+
+const sqliteMigrationsTable = "worker_schema_migrations"
+
+//go:embed migrations/sqlite/*.sql
+var sqliteMigrations embed.FS
+
+// MigrateSQLite applies the migrations of the tables used by a SQLiteStore,
+// recording them separately from the migrations of the service.
+func MigrateSQLite(db *sql.DB) error {
+	fsys, err := fs.Sub(sqliteMigrations, "migrations/sqlite")
+	if err != nil {
+		return err
+	}
+	return sqlitedb.Migrate(db, fsys, sqlitedb.WithMigrationsTable(sqliteMigrationsTable))
+}
+
+// sqlDB is implemented by both sql.DB and sql.Tx.
+type sqlDB interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// SQLiteStore is a Store of jobs in SQLite, e.g. opened with sqlitedb.Open,
+// for durable jobs of a single instance without Postgres. Times are those of
+// the instance rather than the database. Apply its migrations with
+// MigrateSQLite.
+type SQLiteStore struct {
+	db    sqlDB
+	clock clock.Clock
+}
+
+var _ Store = (*SQLiteStore)(nil)
+
+// SQLiteStoreOption optionally configures a SQLiteStore.
+type SQLiteStoreOption func(opts *sqliteStoreOptions)
+
+// WithSQLiteClock sets the clock used for the times of jobs, e.g. to test
+// delays and leases with a testutil.FakeClock. Defaults to clock.Real.
+func WithSQLiteClock(clk clock.Clock) SQLiteStoreOption {
+	return func(opts *sqliteStoreOptions) {
+		opts.clock = clk
+	}
+}
+
+type sqliteStoreOptions struct {
+	clock clock.Clock
+}
+
+// NewSQLiteStore creates a new SQLiteStore.
+func NewSQLiteStore(db *sql.DB, opts ...SQLiteStoreOption) *SQLiteStore {
+	options := sqliteStoreOptions{
+		clock: clock.Real,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &SQLiteStore{db: db, clock: options.clock}
+}
+
+// WithTx returns a copy of the store bound to the provided transaction, e.g.
+// to enqueue a job only if the transaction commits.
+//
+// Panics if tx is not a *tx.SQLTxWrapper.
+func (s *SQLiteStore) WithTx(txn tx.Tx) *SQLiteStore {
+	sqlw, ok := txn.(*tx.SQLTxWrapper)
+	if !ok {
+		panic("worker.SQLiteStore.WithTx: expected *tx.SQLTxWrapper")
+	}
+	return &SQLiteStore{db: sqlw.GetSQLTx(), clock: s.clock}
+}
+
+func (s *SQLiteStore) Enqueue(ctx context.Context, job NewJob) (int64, error) {
+	now := s.clock.Now()
+	var id int64
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO worker_jobs (queue, payload, max_attempts, run_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		RETURNING id`,
+		job.Queue, string(job.Payload), job.MaxAttempts, now.Add(job.Delay).UnixMilli(), now.UnixMilli(), now.UnixMilli(),
+	).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("enqueue job: %w", err)
+	}
+	return id, nil
+}
+
+func (s *SQLiteStore) Claim(ctx context.Context, queue string, lease time.Duration) (*Job, error) {
+	now := s.clock.Now()
+	var job Job
+	var payload string
+	var createdAt int64
+	// A single statement claims atomically since SQLite serializes writes.
+	err := s.db.QueryRowContext(ctx, `
+		UPDATE worker_jobs
+		SET status       = 'running',
+		    attempt      = attempt + 1,
+		    locked_until = ?2,
+		    updated_at   = ?3
+		WHERE id = (SELECT id
+		            FROM worker_jobs
+		            WHERE queue = ?1
+		              AND ((status = 'pending' AND run_at <= ?3) OR
+		                   (status = 'running' AND locked_until <= ?3))
+		            ORDER BY run_at, id
+		            LIMIT 1)
+		RETURNING id, queue, payload, attempt, max_attempts, created_at`,
+		queue, now.Add(lease).UnixMilli(), now.UnixMilli(),
+	).Scan(&job.ID, &job.Queue, &payload, &job.Attempt, &job.MaxAttempts, &createdAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("claim job: %w", err)
+	}
+	job.Payload = []byte(payload)
+	job.CreatedAt = time.UnixMilli(createdAt)
+	return &job, nil
+}
+
+func (s *SQLiteStore) Complete(ctx context.Context, job *Job) error {
+	return s.execRunning(ctx, job, "complete", `DELETE FROM worker_jobs WHERE id = ?1 AND attempt = ?2 AND status = 'running'`)
+}
+
+func (s *SQLiteStore) Retry(ctx context.Context, job *Job, delay time.Duration, reason string) error {
+	now := s.clock.Now()
+	return s.execRunning(ctx, job, "retry", `
+		UPDATE worker_jobs
+		SET status       = 'pending',
+		    run_at       = ?3,
+		    locked_until = NULL,
+		    last_error   = ?4,
+		    updated_at   = ?5
+		WHERE id = ?1 AND attempt = ?2 AND status = 'running'`,
+		now.Add(delay).UnixMilli(), reason, now.UnixMilli(),
+	)
+}
+
+func (s *SQLiteStore) Fail(ctx context.Context, job *Job, reason string) error {
+	return s.execRunning(ctx, job, "fail", `
+		UPDATE worker_jobs
+		SET status       = 'dead',
+		    locked_until = NULL,
+		    last_error   = ?3,
+		    updated_at   = ?4
+		WHERE id = ?1 AND attempt = ?2 AND status = 'running'`,
+		reason, s.clock.Now().UnixMilli(),
+	)
+}
+
+func (s *SQLiteStore) Release(ctx context.Context, job *Job) error {
+	return s.execRunning(ctx, job, "release", `
+		UPDATE worker_jobs
+		SET status       = 'pending',
+		    attempt      = attempt - 1,
+		    run_at       = ?3,
+		    locked_until = NULL,
+		    updated_at   = ?3
+		WHERE id = ?1 AND attempt = ?2 AND status = 'running'`,
+		s.clock.Now().UnixMilli(),
+	)
+}
+
+func (s *SQLiteStore) Requeue(ctx context.Context, id int64) error {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE worker_jobs
+		SET status     = 'pending',
+		    attempt    = 0,
+		    run_at     = ?2,
+		    updated_at = ?2
+		WHERE id = ?1 AND status = 'dead'`,
+		id, s.clock.Now().UnixMilli(),
+	)
+	if err != nil {
+		return fmt.Errorf("requeue job %d: %w", id, err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("requeue job %d: %w", id, err)
+	} else if n == 0 {
+		return errtag.Errorf[errtag.NotFound]("dead job %d not found", id)
+	}
+	return nil
+}
+
+// execRunning executes query updating a running job, using its attempt to
+// detect whether it was claimed again since.
+func (s *SQLiteStore) execRunning(ctx context.Context, job *Job, action string, query string, args ...any) error {
+	res, err := s.db.ExecContext(ctx, query, append([]any{job.ID, job.Attempt}, args...)...)
+	if err != nil {
+		return fmt.Errorf("%s job %d: %w", action, job.ID, err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("%s job %d: %w", action, job.ID, err)
+	} else if n == 0 {
+		return fmt.Errorf("%s job %d: %w", action, job.ID, ErrLeaseLost)
+	}
+	return nil
+}
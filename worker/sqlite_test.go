@@ -0,0 +1,119 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/sqlitedb"
+	"github.com/joshjon/kit/testutil"
+	"github.com/joshjon/kit/tx"
+)
+
+func newTestSQLiteStore(t *testing.T, opts ...SQLiteStoreOption) (*SQLiteStore, *sql.DB) {
+	t.Helper()
+	db, err := sqlitedb.Open(context.Background(), sqlitedb.WithInMemory())
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	require.NoError(t, MigrateSQLite(db))
+	return NewSQLiteStore(db, opts...), db
+}
+
+func TestSQLiteStore(t *testing.T) {
+	store, _ := newTestSQLiteStore(t)
+	testStore(t, store)
+}
+
+func TestSQLiteStore_expiredLease(t *testing.T) {
+	store, _ := newTestSQLiteStore(t)
+	testStoreExpiredLease(t, store)
+}
+
+func TestSQLiteStore_clock(t *testing.T) {
+	ctx := context.Background()
+	clk := testutil.NewFakeClock(time.Now())
+	store, _ := newTestSQLiteStore(t, WithSQLiteClock(clk))
+
+	_, err := Enqueue(ctx, store, "emails", emailPayload{}, WithDelay(time.Minute))
+	require.NoError(t, err)
+	job, err := store.Claim(ctx, "emails", time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, job, "job claimed before its delay")
+
+	clk.Advance(time.Minute)
+	stale, err := store.Claim(ctx, "emails", time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, stale)
+
+	// the lease of the claimed job expires with the clock
+	job, err = store.Claim(ctx, "emails", time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, job, "job claimed before its lease expired")
+
+	clk.Advance(time.Minute)
+	job, err = store.Claim(ctx, "emails", time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	assert.Equal(t, stale.ID, job.ID)
+	assert.Equal(t, 2, job.Attempt)
+}
+
+func TestSQLiteStore_WithTx(t *testing.T) {
+	ctx := context.Background()
+	store, db := newTestSQLiteStore(t)
+
+	enqueueTx := func(ctx context.Context, fail bool) error {
+		sqlTx, err := db.BeginTx(ctx, nil)
+		require.NoError(t, err)
+		txn := tx.NewSQLTxWrapper(sqlTx)
+		return tx.Do(ctx, txn, func(ctx context.Context) error {
+			if _, err := Enqueue(ctx, store.WithTx(txn), "emails", emailPayload{}); err != nil {
+				return err
+			}
+			if fail {
+				return errors.New("failed")
+			}
+			return nil
+		})
+	}
+
+	// rolled back
+	require.Error(t, enqueueTx(ctx, true))
+	job, err := store.Claim(ctx, "emails", time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, job)
+
+	// committed
+	require.NoError(t, enqueueTx(ctx, false))
+	job, err = store.Claim(ctx, "emails", time.Minute)
+	require.NoError(t, err)
+	assert.NotNil(t, job)
+}
+
+func TestSQLiteStore_worker(t *testing.T) {
+	store, _ := newTestSQLiteStore(t)
+
+	done := make(chan int64, 1)
+	w := newTestWorker(store)
+	w.Handle("emails", func(ctx context.Context, job *Job) error {
+		done <- job.ID
+		return nil
+	})
+	runWorker(t, w)
+
+	id, err := Enqueue(context.Background(), store, "emails", emailPayload{})
+	require.NoError(t, err)
+	select {
+	case got := <-done:
+		assert.Equal(t, id, got)
+	case <-time.After(time.Second):
+		t.Fatal("job not processed")
+	}
+}
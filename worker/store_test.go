@@ -0,0 +1,85 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/errtag"
+)
+
+// testStore tests the lifecycle of jobs in store, which must be empty.
+func testStore(t *testing.T, store Store) {
+	ctx := context.Background()
+
+	id, err := Enqueue(ctx, store, "emails", emailPayload{To: "a@example.com"}, WithMaxAttempts(2))
+	require.NoError(t, err)
+	_, err = Enqueue(ctx, store, "emails", emailPayload{}, WithDelay(time.Hour))
+	require.NoError(t, err)
+
+	job, err := store.Claim(ctx, "emails", time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	assert.Equal(t, id, job.ID)
+	assert.Equal(t, 1, job.Attempt)
+	assert.Equal(t, 2, job.MaxAttempts)
+	var payload emailPayload
+	require.NoError(t, job.Decode(&payload))
+	assert.Equal(t, "a@example.com", payload.To)
+
+	// the other job is not due and the claimed job is leased
+	none, err := store.Claim(ctx, "emails", time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, none)
+
+	require.NoError(t, store.Retry(ctx, job, 0, "unavailable"))
+	assert.ErrorIs(t, store.Complete(ctx, job), ErrLeaseLost)
+
+	job, err = store.Claim(ctx, "emails", time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	assert.Equal(t, 2, job.Attempt)
+	require.NoError(t, store.Fail(ctx, job, "unavailable"))
+
+	require.NoError(t, store.Requeue(ctx, id))
+	assert.True(t, errtag.HasTag[errtag.NotFound](store.Requeue(ctx, id)))
+
+	job, err = store.Claim(ctx, "emails", time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	assert.Equal(t, 1, job.Attempt)
+	require.NoError(t, store.Release(ctx, job))
+
+	job, err = store.Claim(ctx, "emails", time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	assert.Equal(t, 1, job.Attempt)
+	require.NoError(t, store.Complete(ctx, job))
+}
+
+// testStoreExpiredLease tests claiming a job whose lease expired in store,
+// which must be empty.
+func testStoreExpiredLease(t *testing.T, store Store) {
+	ctx := context.Background()
+
+	_, err := Enqueue(ctx, store, "emails", emailPayload{})
+	require.NoError(t, err)
+
+	stale, err := store.Claim(ctx, "emails", time.Millisecond)
+	require.NoError(t, err)
+	require.NotNil(t, stale)
+	time.Sleep(10 * time.Millisecond)
+
+	job, err := store.Claim(ctx, "emails", time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	assert.Equal(t, stale.ID, job.ID)
+	assert.Equal(t, 2, job.Attempt)
+
+	// the worker of the expired lease can no longer update the job
+	assert.ErrorIs(t, store.Complete(ctx, stale), ErrLeaseLost)
+	require.NoError(t, store.Complete(ctx, job))
+}
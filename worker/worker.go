@@ -0,0 +1,242 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/joshjon/kit/clock"
+	"github.com/joshjon/kit/log"
+)
+
+const (
+	defaultPollInterval   = time.Second
+	defaultLease          = 5 * time.Minute
+	defaultInitialBackoff = 10 * time.Second
+	defaultMaxBackoff     = time.Hour
+	storeTimeout          = 10 * time.Second
+)
+
+// Handler processes a job. Returning an error retries the job after a backoff
+// until it runs out of attempts, unless wrapped with Permanent.
+type Handler func(ctx context.Context, job *Job) error
+
+// Option optionally configures a Worker.
+type Option func(opts *options)
+
+// WithPollInterval sets how often queues are polled for jobs while they are
+// empty. Defaults to 1s.
+func WithPollInterval(d time.Duration) Option {
+	return func(opts *options) {
+		opts.pollInterval = d
+	}
+}
+
+// WithLease sets how long a job is leased to the worker that claimed it,
+// which is also the timeout of its Handler. Jobs still running when their
+// lease expires, e.g. because their worker crashed, are claimed again.
+// Defaults to 5m.
+func WithLease(d time.Duration) Option {
+	return func(opts *options) {
+		opts.lease = d
+	}
+}
+
+// WithBackoff sets the backoff before retrying a failed job, doubling for
+// every attempt up to maxBackoff with 20% jitter. Defaults to 10s and 1h.
+func WithBackoff(initial time.Duration, maxBackoff time.Duration) Option {
+	return func(opts *options) {
+		opts.initialBackoff = initial
+		opts.maxBackoff = maxBackoff
+	}
+}
+
+// WithLogger sets the Logger used to log failed jobs.
+func WithLogger(logger log.Logger) Option {
+	return func(opts *options) {
+		opts.logger = logger
+	}
+}
+
+// WithOnDead sets a func called with every job that failed its last attempt
+// or a permanent error, e.g. to alert on dead jobs.
+func WithOnDead(fn func(ctx context.Context, job *Job, err error)) Option {
+	return func(opts *options) {
+		opts.onDead = fn
+	}
+}
+
+// WithClock sets the clock used to wait between polls of empty queues, e.g. to
+// test polling with a testutil.FakeClock. Handler timeouts always use real
+// time. Defaults to clock.Real.
+func WithClock(clk clock.Clock) Option {
+	return func(opts *options) {
+		opts.clock = clk
+	}
+}
+
+type options struct {
+	pollInterval   time.Duration
+	lease          time.Duration
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	logger         log.Logger
+	onDead         func(ctx context.Context, job *Job, err error)
+	clock          clock.Clock
+}
+
+// HandleOption optionally configures the processing of a queue.
+type HandleOption func(opts *handleOptions)
+
+// WithConcurrency sets the maximum number of jobs of the queue processed
+// concurrently by the worker. Defaults to 1.
+func WithConcurrency(n int) HandleOption {
+	return func(opts *handleOptions) {
+		opts.concurrency = n
+	}
+}
+
+type handleOptions struct {
+	concurrency int
+}
+
+type queue struct {
+	name        string
+	handler     Handler
+	concurrency int
+}
+
+// Worker processes the jobs of queues with their Handler.
+type Worker struct {
+	store  Store
+	opts   options
+	queues []queue
+}
+
+// New creates a new Worker processing jobs of store.
+func New(store Store, opts ...Option) *Worker {
+	options := options{
+		pollInterval:   defaultPollInterval,
+		lease:          defaultLease,
+		initialBackoff: defaultInitialBackoff,
+		maxBackoff:     defaultMaxBackoff,
+		logger:         log.NewLogger(),
+		clock:          clock.Real,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &Worker{
+		store: store,
+		opts:  options,
+	}
+}
+
+// Handle processes the jobs of queue with handler. It must be called before
+// Run.
+func (w *Worker) Handle(queueName string, handler Handler, opts ...HandleOption) {
+	options := handleOptions{
+		concurrency: 1,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	w.queues = append(w.queues, queue{
+		name:        queueName,
+		handler:     handler,
+		concurrency: max(options.concurrency, 1),
+	})
+}
+
+// Run processes jobs until ctx is done, then waits for the jobs being
+// processed to return. Handlers of jobs being processed have their context
+// canceled, and jobs failing because of it are released without counting the
+// attempt.
+func (w *Worker) Run(ctx context.Context) error {
+	if len(w.queues) == 0 {
+		return fmt.Errorf("worker: no queues to process")
+	}
+	var wg sync.WaitGroup
+	for _, q := range w.queues {
+		for range q.concurrency {
+			wg.Go(func() {
+				w.poll(ctx, q)
+			})
+		}
+	}
+	wg.Wait()
+	return nil
+}
+
+func (w *Worker) poll(ctx context.Context, q queue) {
+	for ctx.Err() == nil {
+		job, err := w.store.Claim(ctx, q.name, w.opts.lease)
+		if err != nil && ctx.Err() == nil {
+			w.opts.logger.Error("failed to claim job", "queue", q.name, "error", err)
+		}
+		if job != nil {
+			w.process(ctx, q, job)
+			continue
+		}
+		timer := w.opts.clock.NewTimer(w.opts.pollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+		case <-timer.C():
+		}
+	}
+}
+
+func (w *Worker) process(ctx context.Context, q queue, job *Job) {
+	jobCtx, cancel := context.WithTimeout(ctx, w.opts.lease)
+	err := runHandler(jobCtx, q.handler, job)
+	cancel()
+
+	// Record the outcome even if the worker is shutting down.
+	storeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), storeTimeout)
+	defer cancel()
+	logger := w.opts.logger.With("queue", job.Queue, "job_id", job.ID, "attempt", job.Attempt)
+
+	var storeErr error
+	switch {
+	case err == nil:
+		storeErr = w.store.Complete(storeCtx, job)
+	case ctx.Err() != nil:
+		storeErr = w.store.Release(storeCtx, job)
+	case isPermanent(err) || job.Attempt >= job.MaxAttempts:
+		logger.Error("job failed and is dead", "error", err)
+		if storeErr = w.store.Fail(storeCtx, job, err.Error()); storeErr == nil && w.opts.onDead != nil {
+			w.opts.onDead(storeCtx, job, err)
+		}
+	default:
+		delay := w.backoff(job.Attempt)
+		logger.Warn("job failed and will be retried", "error", err, "delay", delay)
+		storeErr = w.store.Retry(storeCtx, job, delay, err.Error())
+	}
+	if storeErr != nil {
+		logger.Error("failed to record job outcome", "error", storeErr)
+	}
+}
+
+func runHandler(ctx context.Context, handler Handler, job *Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panicked: %v", r)
+		}
+	}()
+	return handler(ctx, job)
+}
+
+// backoff returns the backoff before retrying after attempt.
+func (w *Worker) backoff(attempt int) time.Duration {
+	d := w.opts.maxBackoff
+	if shift := attempt - 1; shift < 32 {
+		if b := w.opts.initialBackoff << shift; b > 0 && b < d {
+			d = b
+		}
+	}
+	jitter := d / 5
+	return d - jitter + rand.N(2*jitter+1)
+}
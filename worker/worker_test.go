@@ -0,0 +1,362 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshjon/kit/errtag"
+	"github.com/joshjon/kit/log"
+	"github.com/joshjon/kit/testutil"
+)
+
+type memJob struct {
+	Job
+	status    string
+	runAt     time.Time
+	lastError string
+}
+
+// memStore is an in-memory Store for testing a Worker.
+type memStore struct {
+	mu     sync.Mutex
+	nextID int64
+	jobs   map[int64]*memJob
+}
+
+func newMemStore() *memStore {
+	return &memStore{jobs: map[int64]*memJob{}}
+}
+
+func (s *memStore) Enqueue(_ context.Context, job NewJob) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	s.jobs[s.nextID] = &memJob{
+		Job: Job{
+			ID:          s.nextID,
+			Queue:       job.Queue,
+			Payload:     job.Payload,
+			MaxAttempts: job.MaxAttempts,
+			CreatedAt:   time.Now(),
+		},
+		status: "pending",
+		runAt:  time.Now().Add(job.Delay),
+	}
+	return s.nextID, nil
+}
+
+func (s *memStore) Claim(_ context.Context, queue string, _ time.Duration) (*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id := int64(1); id <= s.nextID; id++ {
+		j, ok := s.jobs[id]
+		if ok && j.Queue == queue && j.status == "pending" && !j.runAt.After(time.Now()) {
+			j.status = "running"
+			j.Attempt++
+			claimed := j.Job
+			return &claimed, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *memStore) update(job *Job, fn func(j *memJob)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[job.ID]
+	if !ok || j.status != "running" || j.Attempt != job.Attempt {
+		return ErrLeaseLost
+	}
+	fn(j)
+	return nil
+}
+
+func (s *memStore) Complete(_ context.Context, job *Job) error {
+	return s.update(job, func(j *memJob) {
+		delete(s.jobs, j.ID)
+	})
+}
+
+func (s *memStore) Retry(_ context.Context, job *Job, delay time.Duration, reason string) error {
+	return s.update(job, func(j *memJob) {
+		j.status, j.runAt, j.lastError = "pending", time.Now().Add(delay), reason
+	})
+}
+
+func (s *memStore) Fail(_ context.Context, job *Job, reason string) error {
+	return s.update(job, func(j *memJob) {
+		j.status, j.lastError = "dead", reason
+	})
+}
+
+func (s *memStore) Release(_ context.Context, job *Job) error {
+	return s.update(job, func(j *memJob) {
+		j.status, j.runAt = "pending", time.Now()
+		j.Attempt--
+	})
+}
+
+func (s *memStore) Requeue(_ context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[id]
+	if !ok || j.status != "dead" {
+		return errtag.Errorf[errtag.NotFound]("dead job %d not found", id)
+	}
+	j.status, j.runAt, j.Attempt = "pending", time.Now(), 0
+	return nil
+}
+
+func (s *memStore) get(id int64) (memJob, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[id]
+	if !ok {
+		return memJob{}, false
+	}
+	return *j, true
+}
+
+func newTestWorker(store Store, opts ...Option) *Worker {
+	opts = append([]Option{
+		WithPollInterval(time.Millisecond),
+		WithBackoff(time.Millisecond, time.Millisecond),
+		WithLogger(log.NewLogger(log.WithNop())),
+	}, opts...)
+	return New(store, opts...)
+}
+
+// runWorker runs w until the returned func is called.
+func runWorker(t *testing.T, w *Worker) (stop func()) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- w.Run(ctx)
+	}()
+	var once sync.Once
+	stop = func() {
+		once.Do(func() {
+			cancel()
+			require.NoError(t, <-done)
+		})
+	}
+	t.Cleanup(stop)
+	return stop
+}
+
+type emailPayload struct {
+	To string `json:"to"`
+}
+
+func TestWorker(t *testing.T) {
+	ctx := context.Background()
+	store := newMemStore()
+
+	got := make(chan emailPayload, 1)
+	w := newTestWorker(store)
+	w.Handle("emails", func(ctx context.Context, job *Job) error {
+		var payload emailPayload
+		if err := job.Decode(&payload); err != nil {
+			return err
+		}
+		got <- payload
+		return nil
+	})
+	runWorker(t, w)
+
+	id, err := Enqueue(ctx, store, "emails", emailPayload{To: "a@example.com"})
+	require.NoError(t, err)
+
+	select {
+	case payload := <-got:
+		assert.Equal(t, "a@example.com", payload.To)
+	case <-time.After(time.Second):
+		t.Fatal("job not processed")
+	}
+	assert.Eventually(t, func() bool {
+		_, ok := store.get(id)
+		return !ok
+	}, time.Second, time.Millisecond)
+}
+
+func TestWorker_WithClock(t *testing.T) {
+	ctx := context.Background()
+	store := newMemStore()
+	clk := testutil.NewFakeClock(time.Now())
+
+	got := make(chan int64, 1)
+	w := newTestWorker(store, WithPollInterval(time.Minute), WithClock(clk))
+	w.Handle("emails", func(ctx context.Context, job *Job) error {
+		got <- job.ID
+		return nil
+	})
+	runWorker(t, w)
+
+	// wait until the worker polled the empty queue
+	require.Eventually(t, func() bool {
+		return clk.Waiters() == 1
+	}, time.Second, time.Millisecond)
+
+	id, err := Enqueue(ctx, store, "emails", emailPayload{})
+	require.NoError(t, err)
+	select {
+	case <-got:
+		t.Fatal("job processed before the poll interval elapsed")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	clk.Advance(time.Minute)
+	select {
+	case gotID := <-got:
+		assert.Equal(t, id, gotID)
+	case <-time.After(time.Second):
+		t.Fatal("job not processed")
+	}
+}
+
+func TestWorker_retriesThenDead(t *testing.T) {
+	ctx := context.Background()
+	store := newMemStore()
+
+	var attempts atomic.Int32
+	dead := make(chan *Job, 1)
+	w := newTestWorker(store, WithOnDead(func(_ context.Context, job *Job, err error) {
+		assert.EqualError(t, err, "handler panicked: boom")
+		dead <- job
+	}))
+	w.Handle("emails", func(ctx context.Context, job *Job) error {
+		switch attempts.Add(1) {
+		case 1, 2:
+			return errors.New("unavailable")
+		case 3:
+			panic("boom")
+		}
+		return nil
+	})
+	runWorker(t, w)
+
+	id, err := Enqueue(ctx, store, "emails", emailPayload{}, WithMaxAttempts(3))
+	require.NoError(t, err)
+
+	select {
+	case job := <-dead:
+		assert.Equal(t, id, job.ID)
+		assert.Equal(t, 3, job.Attempt)
+	case <-time.After(time.Second):
+		t.Fatal("job not dead")
+	}
+	j, ok := store.get(id)
+	require.True(t, ok)
+	assert.Equal(t, "dead", j.status)
+	assert.Equal(t, "handler panicked: boom", j.lastError)
+
+	require.NoError(t, store.Requeue(ctx, id))
+	assert.Eventually(t, func() bool {
+		_, ok := store.get(id)
+		return !ok
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, int32(4), attempts.Load())
+}
+
+func TestWorker_permanent(t *testing.T) {
+	store := newMemStore()
+
+	var attempts atomic.Int32
+	w := newTestWorker(store)
+	w.Handle("emails", func(ctx context.Context, job *Job) error {
+		attempts.Add(1)
+		return Permanent(errors.New("invalid address"))
+	})
+	runWorker(t, w)
+
+	id, err := Enqueue(context.Background(), store, "emails", emailPayload{})
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		j, _ := store.get(id)
+		return j.status == "dead"
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, int32(1), attempts.Load())
+}
+
+func TestWorker_concurrency(t *testing.T) {
+	ctx := context.Background()
+	store := newMemStore()
+
+	var running, peak atomic.Int32
+	release := make(chan struct{})
+	w := newTestWorker(store)
+	w.Handle("emails", func(ctx context.Context, job *Job) error {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+		}
+		<-release
+		return nil
+	}, WithConcurrency(2))
+	runWorker(t, w)
+
+	for range 3 {
+		_, err := Enqueue(ctx, store, "emails", emailPayload{})
+		require.NoError(t, err)
+	}
+	assert.Eventually(t, func() bool {
+		return running.Load() == 2
+	}, time.Second, time.Millisecond)
+	close(release)
+	assert.Eventually(t, func() bool {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		return len(store.jobs) == 0
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, int32(2), peak.Load())
+}
+
+func TestWorker_releasesOnShutdown(t *testing.T) {
+	store := newMemStore()
+
+	started := make(chan struct{})
+	w := newTestWorker(store)
+	w.Handle("emails", func(ctx context.Context, job *Job) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	stop := runWorker(t, w)
+
+	id, err := Enqueue(context.Background(), store, "emails", emailPayload{})
+	require.NoError(t, err)
+	<-started
+	stop()
+
+	j, ok := store.get(id)
+	require.True(t, ok)
+	assert.Equal(t, "pending", j.status)
+	assert.Zero(t, j.Attempt)
+}
+
+func TestWorker_Run_noQueues(t *testing.T) {
+	assert.Error(t, newTestWorker(newMemStore()).Run(context.Background()))
+}
+
+func TestWorker_backoff(t *testing.T) {
+	w := New(newMemStore(), WithBackoff(time.Second, time.Minute))
+	for attempt, want := range map[int]time.Duration{
+		1:   time.Second,
+		2:   2 * time.Second,
+		6:   32 * time.Second,
+		7:   time.Minute,
+		100: time.Minute,
+	} {
+		got := w.backoff(attempt)
+		assert.InDelta(t, want, got, float64(want)/5, "attempt %d", attempt)
+	}
+}